@@ -0,0 +1,90 @@
+package cacheinvalidation
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+// connectTimeout délai de connexion à NATS au démarrage du service
+const connectTimeout = 5 * time.Second
+
+// Publisher publie les invalidations d'un service
+// Sans connexion NATS, les invalidations ne sont que journalisées : les entrées du gateway
+// expirent alors après leur TTL.
+type Publisher struct {
+	natsConn *nats.Conn
+	prefix   string
+	publish  func(subject string) error
+}
+
+// NewPublisher se connecte à NATS et crée le publieur (natsURL vide : pas de publication)
+// Une connexion impossible n'est pas bloquante : le service démarre sans publication.
+func NewPublisher(natsURL, service string) *Publisher {
+	p := &Publisher{prefix: DefaultPrefix}
+	if natsURL == "" {
+		logrus.Info("NATS not configured, cache invalidations will not be published")
+		return p
+	}
+
+	natsConn, err := nats.Connect(natsURL,
+		nats.Name(service+"-service"),
+		nats.Timeout(connectTimeout),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to connect to NATS, cache invalidations will not be published")
+		return p
+	}
+
+	logrus.WithField("url", natsURL).Info("Connected to NATS for cache invalidations")
+	p.natsConn = natsConn
+	p.publish = func(subject string) error {
+		return natsConn.Publish(subject, nil)
+	}
+	return p
+}
+
+// Invalidate publie l'invalidation des tags ; un échec est journalisé sans faire échouer l'écriture
+func (p *Publisher) Invalidate(ctx context.Context, tags ...string) {
+	for _, tag := range tags {
+		if p.publish == nil {
+			logrus.WithContext(ctx).WithField("tag", tag).Debug("Cache invalidation not published")
+			continue
+		}
+		if err := p.publish(p.prefix + "." + tag); err != nil {
+			logrus.WithContext(ctx).WithError(err).WithField("tag", tag).Warn("Failed to publish cache invalidation")
+		}
+	}
+}
+
+// Close ferme la connexion NATS après avoir envoyé les dernières invalidations
+func (p *Publisher) Close() {
+	if p.natsConn == nil {
+		return
+	}
+	if err := p.natsConn.Drain(); err != nil {
+		logrus.WithError(err).Debug("Failed to drain NATS connection")
+	}
+}
+
+// OnWrite invalide les tags après chaque écriture réussie d'une route
+// Les tags acceptent les paramètres de la route ({characterId}) ; les lectures n'invalident rien.
+func OnWrite(publisher *Publisher, templates ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return
+		}
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		publisher.Invalidate(c.Request.Context(), ResolveTags(templates, c.Param)...)
+	}
+}
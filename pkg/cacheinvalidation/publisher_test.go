@@ -0,0 +1,68 @@
+package cacheinvalidation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordingPublisher publieur qui garde les sujets publiés au lieu de les envoyer à NATS
+func recordingPublisher() (*Publisher, *[]string) {
+	subjects := []string{}
+	return &Publisher{
+		prefix: DefaultPrefix,
+		publish: func(subject string) error {
+			subjects = append(subjects, subject)
+			return nil
+		},
+	}, &subjects
+}
+
+func TestResolveTags(t *testing.T) {
+	params := map[string]string{"id": "42", "characterId": "a.b*c"}
+	param := func(name string) string { return params[name] }
+
+	got := ResolveTags([]string{"guild.list", "guild.{id}", "inventory.{characterId}", "zone.{zoneId}", "broken.{id"}, param)
+	want := []string{"guild.list", "guild.42", "inventory.a_b_c", "broken.{id"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolveTags = %v, want %v", got, want)
+	}
+}
+
+func TestOnWrite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		method string
+		status int
+		want   []string
+	}{
+		{"successful write", http.MethodPost, http.StatusCreated, []string{"cache.invalidate.inventory.42"}},
+		{"failed write", http.MethodDelete, http.StatusNotFound, []string{}},
+		{"read", http.MethodGet, http.StatusOK, []string{}},
+	}
+
+	for _, tt := range tests {
+		publisher, subjects := recordingPublisher()
+		router := gin.New()
+		router.Handle(tt.method, "/inventory/:characterId/items", OnWrite(publisher, "inventory.{characterId}"), func(c *gin.Context) {
+			c.Status(tt.status)
+		})
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, "/inventory/42/items", http.NoBody))
+		if !reflect.DeepEqual(*subjects, tt.want) {
+			t.Errorf("%s: published %v, want %v", tt.name, *subjects, tt.want)
+		}
+	}
+}
+
+// TestInvalidateWithoutNATS sans connexion, l'invalidation est ignorée sans erreur
+func TestInvalidateWithoutNATS(t *testing.T) {
+	publisher := NewPublisher("", "inventory")
+	publisher.Invalidate(t.Context(), "inventory.42")
+	publisher.Close()
+}
@@ -0,0 +1,58 @@
+// Package cacheinvalidation invalidation du cache de réponses du gateway : un message NATS sur
+// <prefix>.<tag> supprime les entrées du tag sur tous les réplicas du gateway. Les services
+// publient les tags des données qu'ils modifient ; le gateway les écoute.
+package cacheinvalidation
+
+import "strings"
+
+// DefaultPrefix préfixe des sujets d'invalidation écoutés par le gateway
+const DefaultPrefix = "cache.invalidate"
+
+// ResolveTags remplace les paramètres {name} des tags ; un tag dont un paramètre est vide est ignoré
+func ResolveTags(templates []string, param func(string) string) []string {
+	tags := make([]string, 0, len(templates))
+	for _, template := range templates {
+		tag, ok := resolveTag(template, param)
+		if ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// resolveTag remplace les paramètres d'un tag par leur valeur
+func resolveTag(template string, param func(string) string) (string, bool) {
+	var tag strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			tag.WriteString(template)
+			return tag.String(), true
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			tag.WriteString(template)
+			return tag.String(), true
+		}
+
+		value := subjectToken(param(template[start+1 : start+end]))
+		if value == "" {
+			return "", false
+		}
+		tag.WriteString(template[:start])
+		tag.WriteString(value)
+		template = template[start+end+1:]
+	}
+}
+
+// subjectToken rend une valeur utilisable comme élément d'un sujet NATS
+func subjectToken(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		default:
+			return r
+		}
+	}, value)
+}
@@ -0,0 +1,247 @@
+// Package jwks vérification des tokens émis par le service auth avec ses clés publiques
+// (/.well-known/jwks.json) : le service ne détient aucune clé de signature.
+package jwks
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Constantes du cache des clés
+const (
+	// DefaultRefreshInterval intervalle de rechargement du JWKS (max-age publié par le service auth)
+	DefaultRefreshInterval = 5 * time.Minute
+
+	// minFetchInterval délai minimal entre deux chargements déclenchés par un kid inconnu
+	minFetchInterval = 10 * time.Second
+	fetchTimeout     = 5 * time.Second
+	maxDocumentSize  = 1 << 20
+)
+
+// ErrUnknownKey token signé par une clé absente du JWKS
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// jwk clé publique au format JSON Web Key
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// publicKey clé publique et algorithme avec lequel elle signe
+type publicKey struct {
+	algorithm string
+	key       crypto.PublicKey
+}
+
+// KeySet cache des clés publiques du service auth
+// Le JWKS est rechargé périodiquement, et à la demande quand un token porte un kid inconnu
+// (clé créée depuis le dernier chargement). En cas d'échec, les clés connues restent utilisées.
+type KeySet struct {
+	url     string
+	client  *http.Client
+	refresh time.Duration
+
+	mu   sync.RWMutex
+	keys map[string]publicKey
+
+	fetchMu   sync.Mutex
+	lastFetch time.Time
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewKeySet crée le cache des clés publiées à l'URL du JWKS
+func NewKeySet(url string, refresh time.Duration) *KeySet {
+	if refresh <= 0 {
+		refresh = DefaultRefreshInterval
+	}
+	return &KeySet{
+		url:     url,
+		client:  &http.Client{Timeout: fetchTimeout},
+		refresh: refresh,
+		keys:    make(map[string]publicKey),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start charge les clés puis les recharge périodiquement
+// Un service auth injoignable au démarrage n'est pas bloquant : les tokens sont refusés
+// jusqu'au premier chargement réussi.
+func (s *KeySet) Start() {
+	if err := s.fetch(); err != nil {
+		logrus.WithError(err).WithField("url", s.url).Warn("Failed to load JWKS, retrying in background")
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.refresh)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if err := s.fetch(); err != nil {
+					logrus.WithError(err).WithField("url", s.url).Warn("Failed to refresh JWKS, keeping cached keys")
+				}
+			}
+		}
+	}()
+}
+
+// Close arrête le rechargement périodique
+func (s *KeySet) Close() {
+	s.closeOnce.Do(func() { close(s.stop) })
+}
+
+// Keyfunc retourne la clé publique désignée par l'en-tête kid d'un token (jwt.Keyfunc)
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, fmt.Errorf("%w: missing kid", ErrUnknownKey)
+	}
+
+	key, found := s.lookup(kid)
+	if !found && s.fetchUnknown() {
+		key, found = s.lookup(kid)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	if key.algorithm != token.Method.Alg() {
+		return nil, fmt.Errorf("%w: algorithm mismatch for key %s", ErrUnknownKey, kid)
+	}
+	return key.key, nil
+}
+
+// Algorithms algorithmes de signature acceptés (jwt.WithValidMethods)
+func Algorithms() []string {
+	return []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+}
+
+// lookup cherche une clé dans le cache
+func (s *KeySet) lookup(kid string) (publicKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, found := s.keys[kid]
+	return key, found
+}
+
+// fetchUnknown recharge le JWKS après un kid inconnu, au plus une fois par minFetchInterval
+// pour qu'un token forgé ne déclenche pas un appel au service auth à chaque requête ;
+// retourne true si le cache a pu changer depuis la recherche
+func (s *KeySet) fetchUnknown() bool {
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	if time.Since(s.lastFetch) < minFetchInterval {
+		return true // chargé entre-temps par une autre requête
+	}
+	if err := s.fetchLocked(); err != nil {
+		logrus.WithError(err).WithField("url", s.url).Warn("Failed to refresh JWKS for unknown key")
+		return false
+	}
+	return true
+}
+
+// fetch charge le JWKS et remplace les clés du cache
+func (s *KeySet) fetch() error {
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+	return s.fetchLocked()
+}
+
+// fetchLocked charge le JWKS, fetchMu verrouillé
+func (s *KeySet) fetchLocked() error {
+	s.lastFetch = time.Now()
+
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxDocumentSize)).Decode(&document); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]publicKey, len(document.Keys))
+	for i := range document.Keys {
+		entry := &document.Keys[i]
+		if entry.Use != "" && entry.Use != "sig" {
+			continue
+		}
+		key, parseErr := parseKey(entry)
+		if parseErr != nil {
+			logrus.WithError(parseErr).WithField("kid", entry.KeyID).Warn("Ignoring invalid JWKS key")
+			continue
+		}
+		keys[entry.KeyID] = publicKey{algorithm: entry.Algorithm, key: key}
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+
+	logrus.WithField("keys", len(keys)).Debug("JWKS loaded")
+	return nil
+}
+
+// parseKey décode une clé publique RSA (RS256) ou Ed25519 (EdDSA)
+func parseKey(entry *jwk) (crypto.PublicKey, error) {
+	switch {
+	case entry.KeyType == "RSA" && entry.Algorithm == jwt.SigningMethodRS256.Alg():
+		n, err := base64.RawURLEncoding.DecodeString(entry.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(entry.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > math.MaxInt32 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+
+	case entry.KeyType == "OKP" && entry.Curve == "Ed25519" && entry.Algorithm == jwt.SigningMethodEdDSA.Alg():
+		x, err := base64.RawURLEncoding.DecodeString(entry.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %s (%s)", entry.KeyType, entry.Algorithm)
+	}
+}
@@ -0,0 +1,211 @@
+// Package protocodec encodage protobuf écrit à la main des messages du chemin critique
+// (schéma partagé services/gateway/api/proto/game.proto), commun au gateway et aux services
+package protocodec
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Marshaler message encodable selon le schéma protobuf partagé
+type Marshaler interface {
+	MarshalProto() []byte
+}
+
+// Unmarshaler message décodable selon le schéma protobuf partagé
+type Unmarshaler interface {
+	UnmarshalProto(data []byte) error
+}
+
+// Field champ lu d'un message protobuf
+// Les accesseurs suivent le type déclaré dans le schéma ; un champ inconnu est ignoré par
+// l'appelant, ce qui garde la compatibilité avec les versions plus récentes du schéma.
+type Field struct {
+	Number protowire.Number
+	Type   protowire.Type
+	scalar uint64
+	bytes  []byte
+}
+
+// Uint64 valeur d'un champ uint64
+func (f *Field) Uint64() uint64 { return f.scalar }
+
+// Int64 valeur d'un champ int64 ou int32
+func (f *Field) Int64() int64 { return int64(f.scalar) }
+
+// Int valeur d'un champ int32
+func (f *Field) Int() int { return int(int32(f.scalar)) }
+
+// Sint valeur d'un champ sint32
+func (f *Field) Sint() int { return int(protowire.DecodeZigZag(f.scalar)) }
+
+// Bool valeur d'un champ bool
+func (f *Field) Bool() bool { return f.scalar != 0 }
+
+// Double valeur d'un champ double
+func (f *Field) Double() float64 { return math.Float64frombits(f.scalar) }
+
+// String valeur d'un champ string
+func (f *Field) String() string { return string(f.bytes) }
+
+// Bytes valeur d'un champ bytes ou d'un message imbriqué
+func (f *Field) Bytes() []byte { return f.bytes }
+
+// UUID valeur d'un champ bytes contenant un UUID
+func (f *Field) UUID() (uuid.UUID, error) {
+	id, err := uuid.FromBytes(f.bytes)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid uuid in protobuf field %d: %w", f.Number, err)
+	}
+	return id, nil
+}
+
+// Time valeur d'un champ int64 en millisecondes Unix
+func (f *Field) Time() time.Time { return time.UnixMilli(f.Int64()).UTC() }
+
+// Read parcourt les champs d'un message protobuf
+// Le même Field est réutilisé d'un champ à l'autre : field ne doit pas le garder.
+func Read(data []byte, field func(f *Field) error) error {
+	var f Field
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("invalid protobuf message: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		f = Field{Number: number, Type: wireType}
+		switch wireType {
+		case protowire.VarintType:
+			f.scalar, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed64Type:
+			f.scalar, n = protowire.ConsumeFixed64(data)
+		case protowire.Fixed32Type:
+			var value uint32
+			value, n = protowire.ConsumeFixed32(data)
+			f.scalar = uint64(value)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(number, wireType, data)
+		}
+		if n < 0 {
+			return fmt.Errorf("invalid protobuf field %d: %w", number, protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		if err := field(&f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Les fonctions Append* ajoutent un champ en omettant la valeur par défaut (proto3)
+
+// AppendString ajoute un champ string
+func AppendString(b []byte, number protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+// AppendOptionalString ajoute un champ optional string, présent même vide
+func AppendOptionalString(b []byte, number protowire.Number, value *string) []byte {
+	if value == nil {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+	return protowire.AppendString(b, *value)
+}
+
+// AppendRepeatedString ajoute un champ repeated string, chaque élément présent même vide
+func AppendRepeatedString(b []byte, number protowire.Number, values []string) []byte {
+	for _, value := range values {
+		b = protowire.AppendTag(b, number, protowire.BytesType)
+		b = protowire.AppendString(b, value)
+	}
+	return b
+}
+
+// AppendBytes ajoute un champ bytes
+func AppendBytes(b []byte, number protowire.Number, value []byte) []byte {
+	if len(value) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+	return protowire.AppendBytes(b, value)
+}
+
+// AppendUUID ajoute un UUID (bytes de 16 octets)
+func AppendUUID(b []byte, number protowire.Number, value uuid.UUID) []byte {
+	if value == uuid.Nil {
+		return b
+	}
+	return AppendBytes(b, number, value[:])
+}
+
+// AppendMessage ajoute un message imbriqué
+func AppendMessage(b []byte, number protowire.Number, message Marshaler) []byte {
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+	return protowire.AppendBytes(b, message.MarshalProto())
+}
+
+// AppendDouble ajoute un champ double
+func AppendDouble(b []byte, number protowire.Number, value float64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(value))
+}
+
+// AppendBool ajoute un champ bool
+func AppendBool(b []byte, number protowire.Number, value bool) []byte {
+	if !value {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.VarintType)
+	return protowire.AppendVarint(b, 1)
+}
+
+// AppendInt64 ajoute un champ int64 ou int32
+func AppendInt64(b []byte, number protowire.Number, value int64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(value))
+}
+
+// AppendOptionalInt ajoute un champ optional int32, présent même nul
+func AppendOptionalInt(b []byte, number protowire.Number, value *int) []byte {
+	if value == nil {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(int64(*value)))
+}
+
+// AppendSint ajoute un champ sint32
+func AppendSint(b []byte, number protowire.Number, value int) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.VarintType)
+	return protowire.AppendVarint(b, protowire.EncodeZigZag(int64(value)))
+}
+
+// AppendTime ajoute un horodatage (int64 en millisecondes Unix)
+func AppendTime(b []byte, number protowire.Number, value time.Time) []byte {
+	if value.IsZero() {
+		return b
+	}
+	return AppendInt64(b, number, value.UnixMilli())
+}
@@ -0,0 +1,212 @@
+package protocodec
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// testMessage message couvrant chaque type de champ du schéma
+type testMessage struct {
+	Name     string
+	Nickname *string
+	Tags     []string
+	Payload  []byte
+	ID       uuid.UUID
+	Ratio    float64
+	Active   bool
+	Gold     int64
+	Level    *int
+	Offset   int
+	At       time.Time
+	Child    *testMessage
+}
+
+// Numéros de champs de testMessage
+const (
+	fieldName protowire.Number = iota + 1
+	fieldNickname
+	fieldTags
+	fieldPayload
+	fieldID
+	fieldRatio
+	fieldActive
+	fieldGold
+	fieldLevel
+	fieldOffset
+	fieldAt
+	fieldChild
+)
+
+func (m *testMessage) MarshalProto() []byte {
+	var b []byte
+	b = AppendString(b, fieldName, m.Name)
+	b = AppendOptionalString(b, fieldNickname, m.Nickname)
+	b = AppendRepeatedString(b, fieldTags, m.Tags)
+	b = AppendBytes(b, fieldPayload, m.Payload)
+	b = AppendUUID(b, fieldID, m.ID)
+	b = AppendDouble(b, fieldRatio, m.Ratio)
+	b = AppendBool(b, fieldActive, m.Active)
+	b = AppendInt64(b, fieldGold, m.Gold)
+	b = AppendOptionalInt(b, fieldLevel, m.Level)
+	b = AppendSint(b, fieldOffset, m.Offset)
+	b = AppendTime(b, fieldAt, m.At)
+	if m.Child != nil {
+		b = AppendMessage(b, fieldChild, m.Child)
+	}
+	return b
+}
+
+func (m *testMessage) UnmarshalProto(data []byte) error {
+	return Read(data, func(f *Field) error {
+		var err error
+		switch f.Number {
+		case fieldName:
+			m.Name = f.String()
+		case fieldNickname:
+			nickname := f.String()
+			m.Nickname = &nickname
+		case fieldTags:
+			m.Tags = append(m.Tags, f.String())
+		case fieldPayload:
+			m.Payload = append([]byte(nil), f.Bytes()...)
+		case fieldID:
+			m.ID, err = f.UUID()
+		case fieldRatio:
+			m.Ratio = f.Double()
+		case fieldActive:
+			m.Active = f.Bool()
+		case fieldGold:
+			m.Gold = f.Int64()
+		case fieldLevel:
+			level := f.Int()
+			m.Level = &level
+		case fieldOffset:
+			m.Offset = f.Sint()
+		case fieldAt:
+			m.At = f.Time()
+		case fieldChild:
+			m.Child = &testMessage{}
+			err = m.Child.UnmarshalProto(f.Bytes())
+		}
+		return err
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	nickname := ""
+	level := -3
+	want := &testMessage{
+		Name:     "Aldric",
+		Nickname: &nickname,
+		Tags:     []string{"tank", ""},
+		Payload:  []byte{0, 1, 2},
+		ID:       uuid.MustParse("0a1b2c3d-4e5f-4a6b-8c7d-8e9f0a1b2c3d"),
+		Ratio:    -0.25,
+		Active:   true,
+		Gold:     -1 << 40,
+		Level:    &level,
+		Offset:   -42,
+		At:       time.UnixMilli(1700000000123).UTC(),
+		Child:    &testMessage{Name: "pet", Gold: 7},
+	}
+
+	var got testMessage
+	if err := got.UnmarshalProto(want.MarshalProto()); err != nil {
+		t.Fatalf("UnmarshalProto: %v", err)
+	}
+
+	if got.Name != want.Name || got.Ratio != want.Ratio || !got.Active || got.Gold != want.Gold ||
+		got.Offset != want.Offset || !got.At.Equal(want.At) || got.ID != want.ID {
+		t.Errorf("scalars = %+v, want %+v", got, want)
+	}
+	if got.Nickname == nil || *got.Nickname != "" {
+		t.Errorf("empty optional string lost: %v", got.Nickname)
+	}
+	if got.Level == nil || *got.Level != level {
+		t.Errorf("optional int = %v, want %d", got.Level, level)
+	}
+	if len(got.Tags) != 2 || got.Tags[0] != "tank" || got.Tags[1] != "" {
+		t.Errorf("repeated string = %q", got.Tags)
+	}
+	if string(got.Payload) != string(want.Payload) {
+		t.Errorf("bytes = %v, want %v", got.Payload, want.Payload)
+	}
+	if got.Child == nil || got.Child.Name != "pet" || got.Child.Gold != 7 {
+		t.Errorf("nested message = %+v", got.Child)
+	}
+}
+
+// TestDefaultsOmitted les valeurs par défaut ne sont pas encodées (proto3)
+func TestDefaultsOmitted(t *testing.T) {
+	if data := (&testMessage{}).MarshalProto(); len(data) != 0 {
+		t.Errorf("empty message encoded to %d bytes", len(data))
+	}
+
+	zero := 0
+	data := (&testMessage{Level: &zero}).MarshalProto()
+	var got testMessage
+	if err := got.UnmarshalProto(data); err != nil {
+		t.Fatalf("UnmarshalProto: %v", err)
+	}
+	if got.Level == nil || *got.Level != 0 {
+		t.Errorf("optional zero int lost: %v", got.Level)
+	}
+}
+
+// TestReadSkipsUnknownFields les champs ajoutés par une version plus récente du schéma sont ignorés
+func TestReadSkipsUnknownFields(t *testing.T) {
+	data := (&testMessage{Name: "Aldric"}).MarshalProto()
+	data = protowire.AppendTag(data, 90, protowire.Fixed32Type)
+	data = protowire.AppendFixed32(data, math.Float32bits(1.5))
+	data = protowire.AppendTag(data, 91, protowire.StartGroupType)
+	data = protowire.AppendTag(data, 1, protowire.VarintType)
+	data = protowire.AppendVarint(data, 1)
+	data = protowire.AppendTag(data, 91, protowire.EndGroupType)
+	data = AppendInt64(data, fieldGold, 12)
+
+	var got testMessage
+	if err := got.UnmarshalProto(data); err != nil {
+		t.Fatalf("UnmarshalProto: %v", err)
+	}
+	if got.Name != "Aldric" || got.Gold != 12 {
+		t.Errorf("message = %+v, want fields around unknown ones kept", got)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	valid := (&testMessage{Name: "Aldric"}).MarshalProto()
+
+	tests := map[string][]byte{
+		"truncated tag":   {0x80},
+		"truncated value": valid[:len(valid)-1],
+		"invalid uuid":    AppendBytes(nil, fieldID, []byte{1, 2, 3}),
+	}
+	for name, data := range tests {
+		var got testMessage
+		if err := got.UnmarshalProto(data); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func BenchmarkMarshalProto(b *testing.B) {
+	level := 12
+	message := &testMessage{
+		Name:  "Aldric",
+		Tags:  []string{"tank", "healer"},
+		ID:    uuid.New(),
+		Ratio: 0.5,
+		Gold:  1500,
+		Level: &level,
+		At:    time.Now(),
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = message.MarshalProto()
+	}
+}
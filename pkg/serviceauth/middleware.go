@@ -0,0 +1,81 @@
+package serviceauth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// callerKey clé du contexte gin portant l'identité du service appelant
+const callerKey = "service_caller"
+
+// Middleware authentifie le service appelant par son token (Authorization: Bearer)
+// À placer sur le groupe /services : ces routes ne sont jamais ouvertes aux joueurs.
+func Middleware(verifier *Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || tokenString == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":      "Service token required",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+
+		caller, err := verifier.Verify(tokenString)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error":      err.Error(),
+				"path":       c.Request.URL.Path,
+				"ip_address": c.ClientIP(),
+				"request_id": c.GetHeader("X-Request-ID"),
+			}).Warn("Rejected internal call with invalid service token")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":      "Invalid service token",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+
+		c.Set(callerKey, caller)
+		c.Next()
+	}
+}
+
+// RequireScope réserve une route aux services qui ont reçu le scope
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller, ok := CallerFromContext(c)
+		if !ok || !caller.HasScope(scope) {
+			fields := logrus.Fields{
+				"required_scope": scope,
+				"path":           c.Request.URL.Path,
+				"request_id":     c.GetHeader("X-Request-ID"),
+			}
+			if ok {
+				fields["service"] = caller.Service
+			}
+			logrus.WithFields(fields).Warn("Internal call denied: missing scope")
+
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":      "Insufficient scope",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CallerFromContext retourne le service appelant authentifié par Middleware
+func CallerFromContext(c *gin.Context) (*Caller, bool) {
+	value, exists := c.Get(callerKey)
+	if !exists {
+		return nil, false
+	}
+	caller, ok := value.(*Caller)
+	return caller, ok
+}
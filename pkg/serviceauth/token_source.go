@@ -0,0 +1,108 @@
+package serviceauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Renouvellement des tokens de service
+const (
+	tokenPath         = "/api/v1/services/token"
+	tokenRefreshEarly = 30 * time.Second // marge avant expiration à laquelle le token est renouvelé
+)
+
+// tokenResponse réponse du service auth à une demande de token de service
+type tokenResponse struct {
+	Data struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	} `json:"data"`
+}
+
+// TokenSource obtient et met en cache le token de service d'un microservice
+// Le token est demandé au service auth (client credentials) puis réutilisé par tous les
+// appels internes jusqu'à peu avant son expiration.
+type TokenSource struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewTokenSource crée une source de tokens à partir de l'URL du service auth
+func NewTokenSource(authURL, clientID, clientSecret string, timeout time.Duration) *TokenSource {
+	return &TokenSource{
+		tokenURL:     strings.TrimRight(authURL, "/") + tokenPath,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		client:       &http.Client{Timeout: timeout},
+	}
+}
+
+// Token retourne un token de service valide, renouvelé si nécessaire
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Add(tokenRefreshEarly).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	token, expiresIn, err := s.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+	s.expiresAt = time.Now().Add(expiresIn)
+	return s.token, nil
+}
+
+// Invalidate oublie le token en cache (refusé par le service appelé)
+func (s *TokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = ""
+}
+
+// fetch demande un nouveau token au service auth
+func (s *TokenSource) fetch(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{"client_id": {s.clientID}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create service token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.clientID, s.clientSecret)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("service token request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("auth service returned status %d for service token", resp.StatusCode)
+	}
+
+	var body tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", 0, fmt.Errorf("failed to decode service token: %w", err)
+	}
+	if body.Data.AccessToken == "" {
+		return "", 0, fmt.Errorf("auth service returned an empty service token")
+	}
+
+	return body.Data.AccessToken, time.Duration(body.Data.ExpiresIn) * time.Second, nil
+}
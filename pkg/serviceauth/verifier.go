@@ -0,0 +1,88 @@
+// Package serviceauth identité des microservices entre eux : tokens de service émis par le
+// service auth (client credentials), vérifiés avec ses clés publiques et limités par scopes.
+package serviceauth
+
+import (
+	"errors"
+	"fmt"
+	"mmorpg/pkg/jwks"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenTypeService type des tokens émis par le service auth aux autres microservices
+const TokenTypeService = "service"
+
+// Scopes des routes internes des services
+const (
+	ScopePlayerRead     = "player.read"
+	ScopePlayerValidate = "player.validate"
+	ScopeCombatRead     = "combat.read"
+	ScopeCombatValidate = "combat.validate"
+	ScopeWorldRead      = "world.read"
+	ScopeInventoryRead  = "inventory.read"
+	ScopeGuildRead      = "guild.read"
+	ScopeChatRead       = "chat.read"
+)
+
+// ErrInvalidToken token de service absent, invalide ou expiré
+var ErrInvalidToken = errors.New("invalid service token")
+
+// Claims claims d'un token de service : le sujet est l'identifiant du service appelant
+type Claims struct {
+	Scope     string `json:"scope"` // scopes séparés par des espaces
+	TokenType string `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// Caller identité du service qui appelle une route interne
+type Caller struct {
+	Service string
+	Scopes  []string
+}
+
+// HasScope indique si le service appelant a reçu le scope
+func (c *Caller) HasScope(scope string) bool {
+	for _, granted := range c.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifier vérifie les tokens de service signés par le service auth
+type Verifier struct {
+	keys   *jwks.KeySet
+	issuer string
+}
+
+// NewVerifier crée un vérificateur de tokens de service avec les clés publiques du service auth
+func NewVerifier(keys *jwks.KeySet, issuer string) *Verifier {
+	return &Verifier{
+		keys:   keys,
+		issuer: issuer,
+	}
+}
+
+// Verify valide un token de service et retourne l'identité de l'appelant
+func (v *Verifier) Verify(tokenString string) (*Caller, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, v.keys.Keyfunc,
+		jwt.WithValidMethods(jwks.Algorithms()),
+		jwt.WithIssuer(v.issuer),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if claims.TokenType != TokenTypeService || claims.Subject == "" || claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("%w: not a service token", ErrInvalidToken)
+	}
+
+	return &Caller{
+		Service: claims.Subject,
+		Scopes:  strings.Fields(claims.Scope),
+	}, nil
+}
@@ -0,0 +1,30 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/XSAM/otelsql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OpenDB ouvre une base PostgreSQL instrumentée
+// Seules les requêtes faites avec le contexte d'une requête HTTP (QueryContext, ExecContext...)
+// produisent un span, enfant de celui de la requête ; les autres ne sont pas tracées.
+func OpenDB(driverName, dsn string) (*sql.DB, error) {
+	return otelsql.Open(driverName, dsn,
+		otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter:           hasParentSpan,
+		}),
+	)
+}
+
+// hasParentSpan indique si l'opération SQL s'inscrit dans une trace en cours
+func hasParentSpan(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+	return trace.SpanContextFromContext(ctx).IsValid()
+}
@@ -0,0 +1,151 @@
+// Package tracing tracing distribué commun aux services : propagation W3C depuis le gateway,
+// span serveur de chaque requête HTTP, spans SQL et IDs de trace dans les logs.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporteurs de traces
+const (
+	ExporterOTLP   = "otlp"   // collecteur OTLP/HTTP (Jaeger, Tempo, collector)
+	ExporterStdout = "stdout" // développement local
+	ExporterFile   = "file"   // JSON, un span par ligne
+)
+
+// instrumentationName nom des tracers ; le service est identifié par la ressource (service.name)
+const instrumentationName = "mmorpg/pkg/tracing"
+
+// Permissions du fichier de traces
+const traceFileMode = 0o644
+
+// Config configuration du tracing distribué (OpenTelemetry)
+type Config struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter"` // otlp, stdout ou file
+	Endpoint    string  `mapstructure:"endpoint"` // host:port du collecteur OTLP/HTTP
+	Insecure    bool    `mapstructure:"insecure"`
+	File        string  `mapstructure:"file"`
+	SampleRatio float64 `mapstructure:"sample_ratio"` // part des nouvelles traces conservées
+}
+
+// Init configure la propagation W3C (traceparent, baggage), l'ajout des IDs de trace
+// aux logs et, si le tracing est activé, l'export des spans.
+// La fonction retournée vide les spans en attente ; elle est appelée à l'arrêt.
+func Init(cfg *Config, service, version, environment string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	logrus.AddHook(LogHook{})
+
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, fmt.Errorf("tracing sample ratio must be between 0 and 1")
+	}
+
+	exporter, closeOutput, err := newExporter(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(service),
+			semconv.ServiceVersion(version),
+			semconv.DeploymentEnvironmentName(environment),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	logrus.WithFields(logrus.Fields{
+		"exporter":     cfg.Exporter,
+		"sample_ratio": cfg.SampleRatio,
+	}).Info("Tracing enabled")
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeErr := closeOutput(); err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
+
+// newExporter crée l'exporteur configuré et la fonction qui ferme sa sortie
+func newExporter(cfg *Config) (sdktrace.SpanExporter, func() error, error) {
+	noClose := func() error { return nil }
+
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		if cfg.Endpoint == "" {
+			return nil, nil, fmt.Errorf("endpoint is required")
+		}
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(context.Background(), options...)
+		return exporter, noClose, err
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		return exporter, noClose, err
+	case ExporterFile:
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, traceFileMode)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		return exporter, file.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown exporter")
+	}
+}
+
+// Tracer retourne le tracer du service
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// LogHook ajoute trace_id et span_id aux logs émis avec logrus.WithContext
+type LogHook struct{}
+
+// Levels niveaux concernés par le hook
+func (LogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire complète l'entrée avec le span du contexte
+func (LogHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	spanContext := trace.SpanContextFromContext(entry.Context)
+	if !spanContext.IsValid() {
+		return nil
+	}
+	entry.Data["trace_id"] = spanContext.TraceID().String()
+	entry.Data["span_id"] = spanContext.SpanID().String()
+	return nil
+}
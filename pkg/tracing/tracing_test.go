@@ -0,0 +1,159 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans enregistre les spans du test en mémoire
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestInitDisabled(t *testing.T) {
+	shutdown, err := Init(&Config{Enabled: false, SampleRatio: 2}, "test", "1.0.0", "test")
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
+
+func TestInitInvalid(t *testing.T) {
+	configs := []*Config{
+		{Enabled: true, Exporter: ExporterStdout, SampleRatio: 1.5},
+		{Enabled: true, Exporter: "zipkin", SampleRatio: 1},
+		{Enabled: true, Exporter: ExporterOTLP, SampleRatio: 1},
+		{Enabled: true, Exporter: ExporterFile, File: filepath.Join(t.TempDir(), "missing", "traces.json"), SampleRatio: 1},
+	}
+	for _, cfg := range configs {
+		if _, err := Init(cfg, "test", "1.0.0", "test"); err == nil {
+			t.Errorf("Init(%+v) accepted", cfg)
+		}
+	}
+}
+
+// TestInitFile les spans sont écrits dans le fichier à l'arrêt
+func TestInitFile(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	file := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Init(&Config{Enabled: true, Exporter: ExporterFile, File: file, SampleRatio: 1}, "inventory", "1.0.0", "test")
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	_, span := Tracer().Start(context.Background(), "work")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Contains(content, []byte(`"Name":"work"`)) || !bytes.Contains(content, []byte("inventory")) {
+		t.Errorf("trace file = %s", content)
+	}
+}
+
+// TestMiddleware le span serveur reprend le traceparent du gateway et porte la route
+func TestMiddleware(t *testing.T) {
+	recorder := recordSpans(t)
+	gin.SetMode(gin.TestMode)
+
+	var handlerSpan trace.SpanContext
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/api/v1/items/:id", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusBadGateway)
+	})
+
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/items/42", http.NoBody)
+	req.Header.Set("traceparent", parent)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("%d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /api/v1/items/:id" || span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("span = %s (%s)", span.Name(), span.SpanKind())
+	}
+	if span.Parent().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || !span.Parent().IsRemote() {
+		t.Errorf("parent = %v, want the gateway span", span.Parent())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("status = %v, want error on 502", span.Status())
+	}
+	if handlerSpan.SpanID() != span.SpanContext().SpanID() {
+		t.Error("handler context does not carry the server span")
+	}
+}
+
+func TestInjectHeaders(t *testing.T) {
+	recordSpans(t)
+
+	ctx, span := Tracer().Start(context.Background(), "call")
+	defer span.End()
+
+	header := http.Header{}
+	InjectHeaders(ctx, header)
+	if !strings.Contains(header.Get("traceparent"), span.SpanContext().TraceID().String()) {
+		t.Errorf("traceparent = %q", header.Get("traceparent"))
+	}
+}
+
+func TestLogHook(t *testing.T) {
+	recordSpans(t)
+
+	var output bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&output)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(LogHook{})
+
+	ctx, span := Tracer().Start(context.Background(), "request")
+	logger.WithContext(ctx).Info("traced")
+	span.End()
+	logger.WithContext(context.Background()).Info("untraced")
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("log output = %q", output.String())
+	}
+	if !strings.Contains(lines[0], `"trace_id":"`+span.SpanContext().TraceID().String()) {
+		t.Errorf("traced log = %s", lines[0])
+	}
+	if strings.Contains(lines[1], "trace_id") {
+		t.Errorf("untraced log = %s", lines[1])
+	}
+}
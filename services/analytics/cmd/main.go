@@ -20,6 +20,7 @@ import (
 	"github.com/dan-2/github_mmorpg/services/analytics/internal/handlers"
 	"github.com/dan-2/github_mmorpg/services/analytics/internal/repository"
 	"github.com/dan-2/github_mmorpg/services/analytics/internal/service"
+	"mmorpg/pkg/tracing"
 )
 
 // Metrics
@@ -53,7 +54,8 @@ func setupDatabase(cfg *config.Config) (*sql.DB, error) {
 		cfg.Database.Host, cfg.Database.Port, cfg.Database.User,
 		cfg.Database.Password, cfg.Database.DBName, cfg.Database.SSLMode)
 
-	db, err := sql.Open("postgres", dsn)
+	// Requêtes SQL tracées dans le span de la requête HTTP
+	db, err := tracing.OpenDB("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("erreur lors de l'ouverture de la base de données: %w", err)
 	}
@@ -82,6 +84,9 @@ func setupRouter(
 ) *gin.Engine {
 	router := gin.Default()
 
+	// Span de chaque requête, enfant de celui du gateway
+	router.Use(tracing.Middleware())
+
 	// Middleware de métriques
 	router.Use(func(c *gin.Context) {
 		start := time.Now()
@@ -188,6 +193,13 @@ func main() {
 	logger.SetOutput(os.Stdout)
 	logger.SetLevel(logrus.InfoLevel)
 
+	// Tracing distribué (contexte reçu du gateway, export OTLP ou local)
+	shutdownTracing, err := tracing.Init(&cfg.Tracing, "analytics", "1.0.0", "")
+	if err != nil {
+		log.Fatalf("Erreur lors de l'initialisation du tracing: %v", err)
+	}
+	logger.AddHook(tracing.LogHook{})
+
 	// Créer l'application FX
 	app := fx.New(
 		fx.Provide(
@@ -208,6 +220,9 @@ func main() {
 			setupRouter,
 		),
 		fx.Invoke(startServer),
+		fx.Invoke(func(lifecycle fx.Lifecycle) {
+			lifecycle.Append(fx.Hook{OnStop: shutdownTracing})
+		}),
 	)
 
 	// Démarrer l'application
@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+
+	"mmorpg/pkg/tracing"
 )
 
 const (
@@ -20,12 +23,16 @@ const (
 
 	// JSON constants
 	EmptyJSON = "{}"
+
+	// Tracing constants
+	DefaultTracingSampleRatio = 1.0
 )
 
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	Auth     AuthConfig
+	Tracing  TracingConfig
 }
 
 type ServerConfig struct {
@@ -46,6 +53,9 @@ type AuthConfig struct {
 	JWTSecret string
 }
 
+// TracingConfig configuration du tracing distribué, commune à tous les services
+type TracingConfig = tracing.Config
+
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
@@ -63,6 +73,14 @@ func Load() *Config {
 		Auth: AuthConfig{
 			JWTSecret: getEnvString("JWT_SECRET", "your-secret-key"),
 		},
+		Tracing: TracingConfig{
+			Enabled:     getEnvBool("TRACING_ENABLED", false),
+			Exporter:    getEnvString("TRACING_EXPORTER", "otlp"),
+			Endpoint:    getEnvString("TRACING_ENDPOINT", "localhost:4318"),
+			Insecure:    getEnvBool("TRACING_INSECURE", true),
+			File:        getEnvString("TRACING_FILE", "traces.json"),
+			SampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", DefaultTracingSampleRatio),
+		},
 	}
 }
 
@@ -72,3 +90,17 @@ func getEnvString(key string, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
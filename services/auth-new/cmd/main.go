@@ -7,9 +7,12 @@ import (
 	"auth/internal/handlers"
 	"auth/internal/middleware"
 	"auth/internal/repository"
+	"auth/internal/revocation"
 	"auth/internal/service"
+	"auth/internal/signing"
 	"context"
 	"fmt"
+	"mmorpg/pkg/tracing"
 	"net/http"
 	"os"
 	"os/signal"
@@ -50,6 +53,12 @@ func main() {
 		logrus.Fatal("Failed to load config: ", err)
 	}
 
+	// Tracing distribué (contexte reçu du gateway, export OTLP ou local)
+	shutdownTracing, err := tracing.Init(&cfg.Tracing, "auth", Version, cfg.Server.Environment)
+	if err != nil {
+		logrus.Fatal("Failed to initialize tracing: ", err)
+	}
+
 	// Connection à la base de données
 	db, err := database.NewConnection(&cfg.Database)
 	if err != nil {
@@ -65,16 +74,30 @@ func main() {
 	// Initialisation des repositories
 	userRepo := repository.NewUserRepository(db)
 	sessionRepo := repository.NewSessionRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
 
 	// Démarriage du nettoyage périodique des sessions
 	sessionRepo.ScheduleCleanup(DefaultSessionCleanup * time.Minute)
 
+	// Publication des révocations de sessions (NATS optionnel)
+	revocations := revocation.NewPublisher(&cfg.Revocation)
+
+	// Clés de signature des tokens, renouvelées périodiquement
+	signingKeys, err := signing.NewManager(repository.NewSigningKeyRepository(db), &cfg.JWT)
+	if err != nil {
+		logrus.Fatal("Failed to load signing keys: ", err)
+	}
+	signingKeys.Start()
+
 	// Initialisation des services
-	authService := service.NewAuthService(userRepo, sessionRepo, cfg)
+	authService := service.NewAuthService(userRepo, sessionRepo, twoFactorRepo, cfg, revocations, signingKeys)
 
 	// Initialisation des handlers
 	authHandler := handlers.NewAuthHandler(authService, cfg)
 	healthHandler := handlers.NewHealthHandler(cfg, db) // ← CORRECTION ICI
+	serviceTokenHandler := handlers.NewServiceTokenHandler(service.NewServiceTokenIssuer(&cfg.Services, cfg.JWT.Issuer, signingKeys))
+	introspectionHandler := handlers.NewIntrospectionHandler(authService)
+	jwksHandler := handlers.NewJWKSHandler(signingKeys, cfg.JWT.JWKSMaxAge)
 
 	// Configuration du mode Gin
 	if cfg.Server.Environment == "production" {
@@ -82,7 +105,7 @@ func main() {
 	}
 
 	// Configuration des routes
-	router := setupRoutes(authHandler, healthHandler, cfg)
+	router := setupRoutes(authHandler, healthHandler, serviceTokenHandler, introspectionHandler, jwksHandler, signingKeys, cfg)
 
 	// Configuration du serveur HTTP
 	server := &http.Server{
@@ -113,13 +136,17 @@ func main() {
 	}()
 
 	// Gestion gracieuse de l'arrêt
-	gracefulShutdown(server)
+	gracefulShutdown(server, shutdownTracing, authService, signingKeys)
 }
 
 // setupRoutes configure toutes les routes du service Auth
 func setupRoutes(
 	authHandler *handlers.AuthHandler,
 	healthHandler *handlers.HealthHandler,
+	serviceTokenHandler *handlers.ServiceTokenHandler,
+	introspectionHandler *handlers.IntrospectionHandler,
+	jwksHandler *handlers.JWKSHandler,
+	signingKeys *signing.Manager,
 	cfg *config.Config,
 ) *gin.Engine {
 	router := gin.New()
@@ -129,6 +156,7 @@ func setupRoutes(
 	router.Use(middleware.Recovery())
 	router.Use(middleware.CORS())
 	router.Use(middleware.RequestID())
+	router.Use(tracing.Middleware())
 	router.Use(middleware.SecurityHeaders())
 
 	// Rate limiting si configuré
@@ -143,6 +171,9 @@ func setupRoutes(
 	router.GET(cfg.Monitoring.MetricsPath, healthHandler.Metrics)
 	router.GET("/stats", healthHandler.Stats)
 
+	// Clés publiques de vérification des tokens (autres services)
+	router.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
 	// Routes de debug (seulement en développement)
 	if cfg.Server.Debug {
 		debug := router.Group("/debug")
@@ -164,6 +195,7 @@ func setupRoutes(
 			// Inscription et connection
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/login/2fa", authHandler.LoginTwoFactor)
 			auth.POST("/refresh", authHandler.RefreshToken)
 
 			// Récupération de mot de passe
@@ -184,9 +216,22 @@ func setupRoutes(
 			}
 		}
 
+		// Routes internes pour les autres services (bloquées par le gateway)
+		services := v1.Group("/services")
+		{
+			// Tokens de service (client credentials)
+			services.POST("/token", serviceTokenHandler.IssueToken)
+
+			// État des tokens d'accès (sessions révoquées), pour le gateway
+			services.POST("/introspect",
+				middleware.ServiceTokenAuth(signingKeys, cfg.JWT.Issuer, "auth.introspect"),
+				introspectionHandler.Introspect,
+			)
+		}
+
 		// Routes protégées (authentification JWT requise)
 		protected := v1.Group("/")
-		protected.Use(middleware.JWTAuth(cfg.JWT.Secret))
+		protected.Use(middleware.JWTAuth(signingKeys))
 		{
 			// Gestion du profil utilisateur
 			user := protected.Group("/user")
@@ -200,22 +245,21 @@ func setupRoutes(
 				user.DELETE("/sessions/:id", authHandler.RevokeSession)
 			}
 
-			// Two-Factor Authentication (si activé)
-			if cfg.Security.TwoFactorRequired {
-				twofa := protected.Group("/2fa")
-				{
-					twofa.POST("/enable", authHandler.EnableTwoFactor)
-					twofa.POST("/disable", authHandler.DisableTwoFactor)
-					twofa.GET("/qr", authHandler.GetTwoFactorQR)
-					twofa.POST("/verify", authHandler.VerifyTwoFactor)
-					twofa.GET("/backup-codes", authHandler.GetBackupCodes)
-					twofa.POST("/regenerate-codes", authHandler.RegenerateBackupCodes)
-				}
+			// Two-Factor Authentication (TOTP)
+			twofa := protected.Group("/2fa")
+			{
+				twofa.POST("/enable", authHandler.EnableTwoFactor)
+				twofa.POST("/disable", authHandler.DisableTwoFactor)
+				twofa.GET("/qr", authHandler.GetTwoFactorQR)
+				twofa.POST("/verify", authHandler.VerifyTwoFactor)
+				twofa.GET("/backup-codes", authHandler.GetBackupCodes)
+				twofa.POST("/regenerate-codes", authHandler.RegenerateBackupCodes)
 			}
 
-			// Routes admin (réservées aux admins et superusers)
+			// Routes admin (réservées aux admins et superusers, 2FA exigée si configuré)
 			admin := protected.Group("/admin")
 			admin.Use(middleware.RequireRole("admin", "superuser"))
+			admin.Use(middleware.RequireTwoFactor(cfg.Security.TwoFactorRequired))
 			{
 				// Gestion des utilisateurs
 				admin.GET("/users", authHandler.ListUsers)
@@ -253,7 +297,12 @@ func initLogger() {
 }
 
 // gracefulShutdown gère l'arrêt propre du serveur
-func gracefulShutdown(server *http.Server) {
+func gracefulShutdown(
+	server *http.Server,
+	shutdownTracing func(context.Context) error,
+	authService *service.AuthService,
+	signingKeys *signing.Manager,
+) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -267,5 +316,18 @@ func gracefulShutdown(server *http.Server) {
 		logrus.Fatal("Server forced to shutdown: ", err)
 	}
 
+	// Arrêter la rotation des clés
+	signingKeys.Close()
+
+	// Envoyer les dernières révocations et fermer NATS
+	if err := authService.Close(); err != nil {
+		logrus.Error("Error closing auth service: ", err)
+	}
+
+	// Exporter les derniers spans
+	if err := shutdownTracing(ctx); err != nil {
+		logrus.Error("Error flushing traces: ", err)
+	}
+
 	logrus.Info("🔐 Auth Service stopped gracefully")
 }
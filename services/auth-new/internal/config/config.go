@@ -3,8 +3,10 @@ package config
 
 import (
 	"fmt"
+	"mmorpg/pkg/tracing"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -39,19 +41,53 @@ const (
 	DefaultPasswordMin       = 6
 	DefaultConnectionTO      = 10
 	DefaultShutdownTO        = 30
+
+	// Tracing distribué
+	DefaultTracingSampleRatio = 1.0
+
+	// Tokens de service (appels entre services)
+	DefaultServiceTokenMin        = 5
+	DefaultServiceClientSecretMin = 16
+
+	// Publication des révocations de sessions
+	DefaultNATSConnectTimeout = 2 // secondes
+
+	// Clés de signature des tokens (rotation et JWKS)
+	DefaultKeyRotationDays = 30
+	DefaultKeyOverlapDays  = 8 // au moins la durée de vie d'un refresh token
+	DefaultJWKSMaxAgeMin   = 5
+
+	// Authentification à deux facteurs (TOTP)
+	DefaultTwoFactorIssuer       = "MMORPG"
+	DefaultTwoFactorChallengeMin = 5
+)
+
+// Algorithmes de signature des tokens
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Format de AUTH_SERVICE_CLIENTS : "id:secret:scope1,scope2;id2:secret2:scope3"
+const (
+	serviceClientSeparator = ";"
+	serviceClientFields    = 3
 )
 
 // Config représente la configuration complète du service Auth
 type Config struct {
-	Server     ServerConfig     `mapstructure:"server"`
-	Database   DatabaseConfig   `mapstructure:"database"`
-	JWT        JWTConfig        `mapstructure:"jwt"`
-	Security   SecurityConfig   `mapstructure:"security"`
-	Email      EmailConfig      `mapstructure:"email"`
-	OAuth      OAuthConfig      `mapstructure:"oauth"`
-	RateLimit  RateLimitConfig  `mapstructure:"rate_limit"`
-	Monitoring MonitoringConfig `mapstructure:"monitoring"`
-	Redis      RedisConfig      `mapstructure:"redis"`
+	Server     ServerConfig      `mapstructure:"server"`
+	Database   DatabaseConfig    `mapstructure:"database"`
+	JWT        JWTConfig         `mapstructure:"jwt"`
+	Security   SecurityConfig    `mapstructure:"security"`
+	Email      EmailConfig       `mapstructure:"email"`
+	OAuth      OAuthConfig       `mapstructure:"oauth"`
+	RateLimit  RateLimitConfig   `mapstructure:"rate_limit"`
+	Monitoring MonitoringConfig  `mapstructure:"monitoring"`
+	Redis      RedisConfig       `mapstructure:"redis"`
+	Tracing    TracingConfig     `mapstructure:"tracing"`
+	Services   ServiceAuthConfig `mapstructure:"service_auth"`
+	Revocation RevocationConfig  `mapstructure:"revocation"`
 }
 
 // ServerConfig configuration du serveur HTTP
@@ -80,8 +116,16 @@ type DatabaseConfig struct {
 }
 
 // JWTConfig configuration JWT
+// Les tokens sont signés avec une clé asymétrique (RS256 ou EdDSA) renouvelée tous les
+// KeyRotationInterval. Une clé retirée reste publiée dans le JWKS pendant KeyOverlap pour
+// vérifier les tokens qu'elle a signés ; les autres services ne détiennent que les clés publiques.
+// Les clés privées sont stockées en base, chiffrées avec KeyEncryptionKey.
 type JWTConfig struct {
-	Secret                      string        `mapstructure:"secret"`
+	Algorithm                   string        `mapstructure:"algorithm"` // RS256 ou EdDSA
+	KeyEncryptionKey            string        `mapstructure:"key_encryption_key"`
+	KeyRotationInterval         time.Duration `mapstructure:"key_rotation_interval"`
+	KeyOverlap                  time.Duration `mapstructure:"key_overlap"`
+	JWKSMaxAge                  time.Duration `mapstructure:"jwks_max_age"` // Cache-Control du JWKS
 	Issuer                      string        `mapstructure:"issuer"`
 	AccessTokenExpiration       time.Duration `mapstructure:"access_token_expiration"`
 	RefreshTokenExpiration      time.Duration `mapstructure:"refresh_token_expiration"`
@@ -102,7 +146,9 @@ type SecurityConfig struct {
 	PasswordRequireSymbol  bool          `mapstructure:"password_require_symbol"`
 	PasswordRequireSpecial bool          `mapstructure:"password_require_special"` // Alias pour Symbol
 	SessionTimeout         time.Duration `mapstructure:"session_timeout"`
-	TwoFactorRequired      bool          `mapstructure:"two_factor_required"`
+	TwoFactorRequired      bool          `mapstructure:"two_factor_required"`      // 2FA exigée des comptes staff pour les routes admin
+	TwoFactorIssuer        string        `mapstructure:"two_factor_issuer"`        // nom affiché par les applications TOTP
+	TwoFactorChallengeTTL  time.Duration `mapstructure:"two_factor_challenge_ttl"` // délai pour saisir le code après le mot de passe
 }
 
 // EmailConfig configuration email
@@ -159,6 +205,34 @@ type MonitoringConfig struct {
 	LogLevel       string `mapstructure:"log_level"`
 }
 
+// TracingConfig configuration du tracing distribué, commune à tous les services
+type TracingConfig = tracing.Config
+
+// ServiceAuthConfig tokens de service émis aux autres microservices (client credentials)
+// Ils sont signés avec les mêmes clés que les tokens utilisateurs ; leur token_type
+// (service) empêche qu'un token joueur soit accepté sur une route interne, et inversement.
+type ServiceAuthConfig struct {
+	TokenExpiration time.Duration   `mapstructure:"token_expiration"`
+	Clients         []ServiceClient `mapstructure:"clients"`
+}
+
+// ServiceClient identité d'un service appelant et scopes qu'il peut demander
+type ServiceClient struct {
+	ID     string   `mapstructure:"id"`
+	Secret string   `mapstructure:"secret"`
+	Scopes []string `mapstructure:"scopes"`
+}
+
+// RevocationConfig publication des sessions révoquées (logout, révocation, suspension, ban)
+// Le gateway s'y abonne pour refuser les tokens d'accès révoqués avant leur expiration.
+// Sans URL NATS, ou si la connexion échoue, les révocations ne sont pas publiées : le gateway
+// s'appuie alors sur l'introspection (POST /api/v1/services/introspect).
+type RevocationConfig struct {
+	NATSURL        string        `mapstructure:"nats_url"`
+	Subject        string        `mapstructure:"subject"`
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+}
+
 // RedisConfig configuration Redis (pour le cache et sessions)
 type RedisConfig struct {
 	Host     string `mapstructure:"host"`
@@ -205,7 +279,11 @@ func LoadConfig() (*Config, error) {
 			MaxLifetime:  DefaultMaxLifetimeMin * time.Minute,
 		},
 		JWT: JWTConfig{
-			Secret:                      "your-super-secret-jwt-key-change-in-production-minimum-64-characters",
+			Algorithm:                   AlgorithmEdDSA,
+			KeyEncryptionKey:            "dev-signing-key-encryption-key-change-in-production-minimum-32-characters",
+			KeyRotationInterval:         DefaultKeyRotationDays * DefaultEmailVerifHours * time.Hour,
+			KeyOverlap:                  DefaultKeyOverlapDays * DefaultEmailVerifHours * time.Hour,
+			JWKSMaxAge:                  DefaultJWKSMaxAgeMin * time.Minute,
 			Issuer:                      "mmo-auth-service",
 			AccessTokenExpiration:       DefaultAccessTokenMin * time.Minute,
 			RefreshTokenExpiration:      7 * DefaultEmailVerifHours * time.Hour, // 7 jours
@@ -225,6 +303,8 @@ func LoadConfig() (*Config, error) {
 			PasswordRequireSpecial: true, // Même valeur que Symbol
 			SessionTimeout:         DefaultSessionHours * time.Hour,
 			TwoFactorRequired:      false,
+			TwoFactorIssuer:        DefaultTwoFactorIssuer,
+			TwoFactorChallengeTTL:  DefaultTwoFactorChallengeMin * time.Minute,
 		},
 		Email: EmailConfig{
 			SMTPHost: "localhost",
@@ -286,6 +366,37 @@ func LoadConfig() (*Config, error) {
 			Database: 0,
 			Enabled:  false,
 		},
+		Tracing: TracingConfig{
+			Enabled:     false,
+			Exporter:    "otlp",
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			File:        "traces.json",
+			SampleRatio: DefaultTracingSampleRatio,
+		},
+		Services: ServiceAuthConfig{
+			TokenExpiration: DefaultServiceTokenMin * time.Minute,
+			Clients: []ServiceClient{
+				{
+					// Calcul des stats de combat (player, inventory, world)
+					ID:     "combat",
+					Secret: "combat-dev-client-secret",
+					Scopes: []string{"player.read", "inventory.read", "world.read"},
+				},
+				{
+					// Vérification des sessions révoquées quand son cache est froid
+					// et de l'appartenance aux topics avant un abonnement temps réel
+					ID:     "gateway",
+					Secret: "gateway-dev-client-secret",
+					Scopes: []string{"auth.introspect", "combat.read", "player.read", "guild.read", "chat.read", "world.read"},
+				},
+			},
+		},
+		Revocation: RevocationConfig{
+			NATSURL:        "nats://localhost:4222",
+			Subject:        "auth.sessions.revoked",
+			ConnectTimeout: DefaultNATSConnectTimeout * time.Second,
+		},
 	}
 
 	// Configurer Viper
@@ -329,6 +440,10 @@ func loadFromEnv(config *Config) {
 	loadEmailEnv(config)
 	loadOAuthEnv(config)
 	loadRedisEnv(config)
+	loadSecurityEnv(config)
+	loadTracingEnv(config)
+	loadServiceAuthEnv(config)
+	loadRevocationEnv(config)
 }
 
 // loadServerEnv charge la configuration serveur depuis les variables d'environnement
@@ -372,8 +487,21 @@ func loadDatabaseEnv(config *Config) {
 
 // loadJWTEnv charge la configuration JWT depuis les variables d'environnement
 func loadJWTEnv(config *Config) {
-	if jwtSecret := os.Getenv("AUTH_JWT_SECRET"); jwtSecret != "" {
-		config.JWT.Secret = jwtSecret
+	if algorithm := os.Getenv("AUTH_JWT_ALGORITHM"); algorithm != "" {
+		config.JWT.Algorithm = algorithm
+	}
+	if key := os.Getenv("AUTH_JWT_KEY_ENCRYPTION_KEY"); key != "" {
+		config.JWT.KeyEncryptionKey = key
+	}
+	if interval := os.Getenv("AUTH_JWT_KEY_ROTATION_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil {
+			config.JWT.KeyRotationInterval = d
+		}
+	}
+	if overlap := os.Getenv("AUTH_JWT_KEY_OVERLAP"); overlap != "" {
+		if d, err := time.ParseDuration(overlap); err == nil {
+			config.JWT.KeyOverlap = d
+		}
 	}
 }
 
@@ -409,6 +537,99 @@ func loadRedisEnv(config *Config) {
 	}
 }
 
+// loadSecurityEnv charge la configuration de la double authentification
+func loadSecurityEnv(config *Config) {
+	if required := os.Getenv("AUTH_TWO_FACTOR_REQUIRED"); required != "" {
+		if b, err := strconv.ParseBool(required); err == nil {
+			config.Security.TwoFactorRequired = b
+		}
+	}
+	if issuer := os.Getenv("AUTH_TWO_FACTOR_ISSUER"); issuer != "" {
+		config.Security.TwoFactorIssuer = issuer
+	}
+}
+
+// loadTracingEnv charge la configuration du tracing (variables communes aux services)
+func loadTracingEnv(config *Config) {
+	if enabled := os.Getenv("TRACING_ENABLED"); enabled != "" {
+		if b, err := strconv.ParseBool(enabled); err == nil {
+			config.Tracing.Enabled = b
+		}
+	}
+	if exporter := os.Getenv("TRACING_EXPORTER"); exporter != "" {
+		config.Tracing.Exporter = exporter
+	}
+	if endpoint := os.Getenv("TRACING_ENDPOINT"); endpoint != "" {
+		config.Tracing.Endpoint = endpoint
+	}
+	if file := os.Getenv("TRACING_FILE"); file != "" {
+		config.Tracing.File = file
+	}
+	if ratio := os.Getenv("TRACING_SAMPLE_RATIO"); ratio != "" {
+		if r, err := strconv.ParseFloat(ratio, 64); err == nil {
+			config.Tracing.SampleRatio = r
+		}
+	}
+}
+
+// loadServiceAuthEnv charge la configuration des tokens de service
+func loadServiceAuthEnv(config *Config) {
+	if clients := os.Getenv("AUTH_SERVICE_CLIENTS"); clients != "" {
+		config.Services.Clients = parseServiceClients(clients)
+	}
+}
+
+// loadRevocationEnv charge la configuration de publication des révocations
+// NATS_URL est commune aux services ; une valeur vide désactive la publication.
+func loadRevocationEnv(config *Config) {
+	if natsURL, ok := os.LookupEnv("NATS_URL"); ok {
+		config.Revocation.NATSURL = natsURL
+	}
+	if subject := os.Getenv("AUTH_REVOCATION_SUBJECT"); subject != "" {
+		config.Revocation.Subject = subject
+	}
+}
+
+// parseServiceClients lit la liste des clients "id:secret:scope1,scope2;..."
+func parseServiceClients(value string) []ServiceClient {
+	var clients []ServiceClient
+	for _, entry := range strings.Split(value, serviceClientSeparator) {
+		fields := strings.SplitN(strings.TrimSpace(entry), ":", serviceClientFields)
+		if len(fields) != serviceClientFields {
+			continue
+		}
+		clients = append(clients, ServiceClient{
+			ID:     fields[0],
+			Secret: fields[1],
+			Scopes: strings.Split(fields[2], ","),
+		})
+	}
+	return clients
+}
+
+// validateServiceAuthConfig valide les clients des tokens de service
+func validateServiceAuthConfig(sa *ServiceAuthConfig) error {
+	if sa.TokenExpiration <= 0 {
+		return fmt.Errorf("service token expiration must be positive")
+	}
+
+	seen := make(map[string]bool)
+	for _, client := range sa.Clients {
+		if client.ID == "" || seen[client.ID] {
+			return fmt.Errorf("service client IDs must be unique and non-empty")
+		}
+		seen[client.ID] = true
+		if len(client.Secret) < DefaultServiceClientSecretMin {
+			return fmt.Errorf("service client %s secret must be at least %d characters long", client.ID, DefaultServiceClientSecretMin)
+		}
+		if len(client.Scopes) == 0 {
+			return fmt.Errorf("service client %s requires at least one scope", client.ID)
+		}
+	}
+
+	return nil
+}
+
 // validateConfig valide la configuration
 func validateConfig(config *Config) error {
 	// Validation du serveur
@@ -417,16 +638,8 @@ func validateConfig(config *Config) error {
 	}
 
 	// Validation JWT
-	if len(config.JWT.Secret) < DefaultJWTSecretMin {
-		return fmt.Errorf("JWT secret must be at least 32 characters long")
-	}
-
-	if config.JWT.AccessTokenExpiration <= 0 {
-		return fmt.Errorf("access token expiration must be positive")
-	}
-
-	if config.JWT.RefreshTokenExpiration <= 0 {
-		return fmt.Errorf("refresh token expiration must be positive")
+	if err := validateJWTConfig(&config.JWT); err != nil {
+		return err
 	}
 
 	// Validation Database
@@ -451,5 +664,43 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("password minimum length must be at least 6")
 	}
 
+	if config.Security.TwoFactorIssuer == "" || config.Security.TwoFactorChallengeTTL <= 0 {
+		return fmt.Errorf("two-factor issuer and a positive challenge TTL are required")
+	}
+
+	if config.Revocation.NATSURL != "" && config.Revocation.Subject == "" {
+		return fmt.Errorf("revocation subject is required when NATS is configured")
+	}
+
+	return validateServiceAuthConfig(&config.Services)
+}
+
+// validateJWTConfig valide la durée des tokens et la rotation des clés de signature
+func validateJWTConfig(jwt *JWTConfig) error {
+	if jwt.Algorithm != AlgorithmRS256 && jwt.Algorithm != AlgorithmEdDSA {
+		return fmt.Errorf("JWT algorithm must be %s or %s", AlgorithmRS256, AlgorithmEdDSA)
+	}
+
+	if len(jwt.KeyEncryptionKey) < DefaultJWTSecretMin {
+		return fmt.Errorf("JWT key encryption key must be at least %d characters long", DefaultJWTSecretMin)
+	}
+
+	if jwt.AccessTokenExpiration <= 0 {
+		return fmt.Errorf("access token expiration must be positive")
+	}
+
+	if jwt.RefreshTokenExpiration <= 0 {
+		return fmt.Errorf("refresh token expiration must be positive")
+	}
+
+	if jwt.JWKSMaxAge <= 0 || jwt.KeyRotationInterval <= jwt.JWKSMaxAge {
+		return fmt.Errorf("JWT key rotation interval must be longer than the JWKS max age")
+	}
+
+	// Une clé retirée doit rester publiée tant que des tokens qu'elle a signés sont valides
+	if jwt.KeyOverlap < jwt.RefreshTokenExpiration || jwt.KeyOverlap < jwt.AccessTokenExpiration {
+		return fmt.Errorf("JWT key overlap must be at least the token lifetime")
+	}
+
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"auth/internal/config"
 	"context"
 	"fmt"
+	"mmorpg/pkg/tracing"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -25,11 +26,12 @@ func NewConnection(cfg *config.DatabaseConfig) (*sqlx.DB, error) {
 		"username": cfg.Username,
 	}).Info("Connecting to PostgreSQL database...")
 
-	// Connection à la base de données
-	db, err := sqlx.Connect("postgres", dsn)
+	// Connection à la base de données (requêtes tracées dans le span de la requête HTTP)
+	sqlDB, err := tracing.OpenDB("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	db := sqlx.NewDb(sqlDB, "postgres")
 
 	// Configuration de la pool de connections
 	db.SetMaxOpenConns(cfg.MaxOpenConns)
@@ -74,6 +76,8 @@ func RunMigrations(db *sqlx.DB) error {
 		createIndexes,
 		createTriggers,
 		createConstraintsAndViews,
+		createSigningKeysTable,
+		createTwoFactorTOTP,
 	}
 
 	// Exécuter chaque migration
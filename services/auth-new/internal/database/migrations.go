@@ -241,6 +241,28 @@ SELECT
     COUNT(*) FILTER (WHERE created_at >= CURRENT_DATE AND success = false) as failures_today
 FROM login_attempts;`
 
+// Migration 12: Clés de signature des tokens (clés privées chiffrées)
+const createSigningKeysTable = `
+CREATE TABLE IF NOT EXISTS signing_keys (
+    kid VARCHAR(64) PRIMARY KEY,
+    algorithm VARCHAR(10) NOT NULL CHECK (algorithm IN ('RS256', 'EdDSA')),
+    private_key BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    activates_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    retires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_signing_keys_activates_at ON signing_keys(activates_at);
+CREATE INDEX IF NOT EXISTS idx_signing_keys_expires_at ON signing_keys(expires_at);`
+
+// Migration 13: Authentification à deux facteurs (TOTP)
+// Les codes de secours sont stockés hachés (SHA-256) ; two_factor_last_step est le dernier
+// pas de temps TOTP accepté, pour refuser la réutilisation d'un code.
+const createTwoFactorTOTP = `
+ALTER TABLE users ADD COLUMN IF NOT EXISTS two_factor_last_step BIGINT;
+ALTER TABLE two_factor_backup_codes ALTER COLUMN code TYPE VARCHAR(64);`
+
 // GetAllMigrations retourne toutes les migrations dans l'ordre
 func GetAllMigrations() []string {
 	return []string{
@@ -255,6 +277,8 @@ func GetAllMigrations() []string {
 		createIndexes,
 		createTriggers,
 		createConstraintsAndViews,
+		createSigningKeysTable,
+		createTwoFactorTOTP,
 		// insertTestData, // Décommentez pour créer un admin par défaut en dev
 	}
 }
@@ -273,5 +297,7 @@ func GetProductionMigrations() []string {
 		createIndexes,
 		createTriggers,
 		createConstraintsAndViews,
+		createSigningKeysTable,
+		createTwoFactorTOTP,
 	}
 }
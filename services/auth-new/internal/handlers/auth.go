@@ -90,15 +90,18 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		case "email not verified":
 			status = http.StatusUnauthorized
 			message = "Please verify your email before logging in"
-		case "two_factor_required":
-			status = http.StatusUnauthorized
-			message = "Two-factor authentication required"
 		}
 
 		h.respondError(c, status, message, err.Error())
 		return
 	}
 
+	// Mot de passe valide, la connection se termine avec le code 2FA (POST /auth/login/2fa)
+	if loginResp.TwoFactorRequired {
+		c.JSON(http.StatusOK, loginResp)
+		return
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"user_id":    loginResp.User.ID,
 		"username":   loginResp.User.Username,
@@ -441,34 +444,6 @@ func (h *AuthHandler) GetUserInfo(c *gin.Context) {
 	})
 }
 
-// Two-Factor Authentication handlers
-func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
-	h.respondError(c, http.StatusNotImplemented, "Two-factor authentication not implemented yet", "")
-}
-
-func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
-	h.respondError(c, http.StatusNotImplemented, "Two-factor authentication not implemented yet", "")
-}
-
-func (h *AuthHandler) GetTwoFactorQR(c *gin.Context) {
-	h.respondError(c, http.StatusNotImplemented, "Two-factor authentication not implemented yet", "")
-}
-
-// VerifyTwoFactor vérifie un code 2FA (stub)
-func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
-	h.respondError(c, http.StatusNotImplemented, "Two-factor authentication not implemented yet", "")
-}
-
-// GetBackupCodes récupère les codes de sauvegarde 2FA (stub)
-func (h *AuthHandler) GetBackupCodes(c *gin.Context) {
-	h.respondError(c, http.StatusNotImplemented, "Two-factor authentication not implemented yet", "")
-}
-
-// RegenerateBackupCodes régénère les codes de sauvegarde 2FA (stub)
-func (h *AuthHandler) RegenerateBackupCodes(c *gin.Context) {
-	h.respondError(c, http.StatusNotImplemented, "Two-factor authentication not implemented yet", "")
-}
-
 // OAuth handlers (stubs)
 func (h *AuthHandler) OAuthRedirect(c *gin.Context) {
 	h.respondError(c, http.StatusNotImplemented, "OAuth not implemented yet", "")
@@ -530,14 +505,45 @@ func (h *AuthHandler) DeleteUser(c *gin.Context) {
 	h.respondError(c, http.StatusNotImplemented, "User deletion not implemented yet", "")
 }
 
-// BanUser bannit un utilisateur (admin) (stub)
+// BanUser bannit un utilisateur et révoque ses sessions (admin)
 func (h *AuthHandler) BanUser(c *gin.Context) {
-	h.respondError(c, http.StatusNotImplemented, "User banning not implemented yet", "")
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return
+	}
+
+	reason := c.PostForm("reason")
+	if reason == "" {
+		reason = "Administrative action"
+	}
+
+	err = h.authService.BanUser(userID, reason)
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "Failed to ban user", err.Error())
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "User banned successfully", nil)
 }
 
-// UnbanUser débannit un utilisateur (admin) (stub)
+// UnbanUser débannit un utilisateur (admin)
 func (h *AuthHandler) UnbanUser(c *gin.Context) {
-	h.respondError(c, http.StatusNotImplemented, "User unbanning not implemented yet", "")
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return
+	}
+
+	err = h.authService.UnbanUser(userID)
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "Failed to unban user", err.Error())
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "User unbanned successfully", nil)
 }
 
 func (h *AuthHandler) SuspendUser(c *gin.Context) {
@@ -597,14 +603,40 @@ func (h *AuthHandler) GetAllSessions(c *gin.Context) {
 	h.respondError(c, http.StatusNotImplemented, "All sessions admin view not implemented yet", "")
 }
 
-// AdminRevokeSession révoque une session (admin) (stub)
+// AdminRevokeSession révoque une session (admin)
 func (h *AuthHandler) AdminRevokeSession(c *gin.Context) {
-	h.respondError(c, http.StatusNotImplemented, "Admin session revocation not implemented yet", "")
+	sessionIDStr := c.Param("id")
+	sessionID, err := uuid.Parse(sessionIDStr)
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "Invalid session ID", err.Error())
+		return
+	}
+
+	err = h.authService.AdminRevokeSession(sessionID)
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "Failed to revoke session", err.Error())
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "Session revoked successfully", nil)
 }
 
-// AdminLogoutUser déconnecte un utilisateur (admin) (stub)
+// AdminLogoutUser déconnecte un utilisateur de tous ses appareils (admin)
 func (h *AuthHandler) AdminLogoutUser(c *gin.Context) {
-	h.respondError(c, http.StatusNotImplemented, "Admin user logout not implemented yet", "")
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return
+	}
+
+	err = h.authService.LogoutAllDevices(userID)
+	if err != nil {
+		h.respondError(c, http.StatusInternalServerError, "Logout failed", err.Error())
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "User logged out from all devices", nil)
 }
 
 // SearchUsers recherche des utilisateurs (admin) (stub)
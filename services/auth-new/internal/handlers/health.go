@@ -289,7 +289,10 @@ func (h *HealthHandler) ConfigHandler(c *gin.Context) {
 			"max_idle_conns": h.config.Database.MaxIdleConns,
 		},
 		"jwt": gin.H{
-			"issuer": h.config.JWT.Issuer,
+			"issuer":       h.config.JWT.Issuer,
+			"algorithm":    h.config.JWT.Algorithm,
+			"key_rotation": h.config.JWT.KeyRotationInterval.String(),
+			"key_overlap":  h.config.JWT.KeyOverlap.String(),
 		},
 	}
 
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"auth/internal/models"
+	"auth/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IntrospectionHandler état des tokens d'accès pour les autres services (gateway)
+type IntrospectionHandler struct {
+	authService service.AuthServiceInterface
+}
+
+// NewIntrospectionHandler crée le handler d'introspection
+func NewIntrospectionHandler(authService service.AuthServiceInterface) *IntrospectionHandler {
+	return &IntrospectionHandler{authService: authService}
+}
+
+// Introspect indique si un token d'accès est encore actif (session non révoquée, compte actif)
+// POST /api/v1/services/introspect (route interne, scope auth.introspect)
+func (h *IntrospectionHandler) Introspect(c *gin.Context) {
+	var req models.IntrospectionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success:   false,
+			Error:     "Invalid request data",
+			Details:   err.Error(),
+			RequestID: c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success:   true,
+		Message:   "Token introspected",
+		Data:      h.authService.Introspect(req.Token),
+		RequestID: c.GetHeader("X-Request-ID"),
+	})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"auth/internal/signing"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler publie les clés publiques de vérification des tokens
+type JWKSHandler struct {
+	keys   *signing.Manager
+	maxAge time.Duration
+}
+
+// NewJWKSHandler crée le handler du JWKS
+func NewJWKSHandler(keys *signing.Manager, maxAge time.Duration) *JWKSHandler {
+	return &JWKSHandler{
+		keys:   keys,
+		maxAge: maxAge,
+	}
+}
+
+// GetJWKS retourne les clés publiques actives et retirées depuis moins de KeyOverlap
+// GET /.well-known/jwks.json (public)
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.maxAge.Seconds())))
+	c.Data(http.StatusOK, "application/jwk-set+json", h.keys.JWKS())
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"auth/internal/models"
+	"auth/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ServiceTokenHandler émission des tokens de service (appels entre microservices)
+type ServiceTokenHandler struct {
+	issuer *service.ServiceTokenIssuer
+}
+
+// NewServiceTokenHandler crée le handler des tokens de service
+func NewServiceTokenHandler(issuer *service.ServiceTokenIssuer) *ServiceTokenHandler {
+	return &ServiceTokenHandler{issuer: issuer}
+}
+
+// IssueToken émet un token à un service authentifié par ses identifiants client
+// POST /api/v1/services/token (route interne, bloquée par le gateway)
+func (h *ServiceTokenHandler) IssueToken(c *gin.Context) {
+	var req models.ServiceTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		return
+	}
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID, req.ClientSecret = clientID, clientSecret
+	}
+
+	token, err := h.issuer.Issue(req.ClientID, req.ClientSecret, req.Scope)
+	switch {
+	case errors.Is(err, service.ErrInvalidClient):
+		logrus.WithFields(logrus.Fields{
+			"client_id":  req.ClientID,
+			"ip_address": c.ClientIP(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		}).Warn("Service token request with invalid credentials")
+		h.respondError(c, http.StatusUnauthorized, "Invalid client credentials", "")
+		return
+	case errors.Is(err, service.ErrInvalidScope):
+		h.respondError(c, http.StatusBadRequest, "Invalid scope", err.Error())
+		return
+	case err != nil:
+		logrus.WithError(err).Error("Failed to issue service token")
+		h.respondError(c, http.StatusInternalServerError, "Failed to issue service token", "")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"client_id":  req.ClientID,
+		"scope":      token.Scope,
+		"request_id": c.GetHeader("X-Request-ID"),
+	}).Debug("Service token issued")
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success:   true,
+		Message:   "Service token issued",
+		Data:      token,
+		RequestID: c.GetHeader("X-Request-ID"),
+	})
+}
+
+// respondError envoie une réponse d'erreur standardisée
+func (h *ServiceTokenHandler) respondError(c *gin.Context, status int, message, details string) {
+	c.JSON(status, models.ErrorResponse{
+		Success:   false,
+		Error:     message,
+		Details:   details,
+		RequestID: c.GetHeader("X-Request-ID"),
+	})
+}
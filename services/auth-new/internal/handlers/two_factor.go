@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"auth/internal/models"
+	"auth/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// LoginTwoFactor termine une connection 2FA : échange le challenge et le code contre les tokens
+// POST /api/v1/auth/login/2fa (public)
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		return
+	}
+
+	ipAddress := c.ClientIP()
+	loginResp, err := h.authService.CompleteTwoFactorLogin(req, ipAddress, c.GetHeader("User-Agent"))
+	if err != nil {
+		h.respondError(c, http.StatusUnauthorized, "Two-factor authentication failed", err.Error())
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":    loginResp.User.ID,
+		"username":   loginResp.User.Username,
+		"ip_address": ipAddress,
+		"request_id": c.GetHeader("X-Request-ID"),
+	}).Info("User logged in successfully with two-factor authentication")
+
+	c.JSON(http.StatusOK, loginResp)
+}
+
+// EnableTwoFactor démarre l'enrôlement 2FA et retourne le secret à ajouter dans l'application
+// POST /api/v1/2fa/enable
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	userID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		h.respondError(c, http.StatusUnauthorized, "User not authenticated", err.Error())
+		return
+	}
+
+	setup, err := h.authService.EnableTwoFactor(userID)
+	if err != nil {
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// GetTwoFactorQR retourne l'URI otpauth:// de l'enrôlement en cours, à rendre en QR code
+// GET /api/v1/2fa/qr
+func (h *AuthHandler) GetTwoFactorQR(c *gin.Context) {
+	userID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		h.respondError(c, http.StatusUnauthorized, "User not authenticated", err.Error())
+		return
+	}
+
+	uri, err := h.authService.GetTwoFactorQR(userID)
+	if err != nil {
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"otpauth_uri": uri,
+	})
+}
+
+// VerifyTwoFactor confirme l'enrôlement avec un premier code et retourne les codes de secours
+// POST /api/v1/2fa/verify
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	userID, req, ok := h.bindTwoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.authService.ConfirmTwoFactor(userID, req.Code)
+	if err != nil {
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.BackupCodesResponse{BackupCodes: codes})
+}
+
+// DisableTwoFactor désactive la 2FA avec un code TOTP ou un code de secours
+// POST /api/v1/2fa/disable
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	userID, req, ok := h.bindTwoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.DisableTwoFactor(userID, req.Code); err != nil {
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+// GetBackupCodes retourne le nombre de codes de secours restants (les codes ne sont pas relisibles)
+// GET /api/v1/2fa/backup-codes
+func (h *AuthHandler) GetBackupCodes(c *gin.Context) {
+	userID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		h.respondError(c, http.StatusUnauthorized, "User not authenticated", err.Error())
+		return
+	}
+
+	remaining, err := h.authService.CountBackupCodes(userID)
+	if err != nil {
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"remaining": remaining,
+	})
+}
+
+// RegenerateBackupCodes remplace les codes de secours, sur présentation d'un code TOTP
+// POST /api/v1/2fa/regenerate-codes
+func (h *AuthHandler) RegenerateBackupCodes(c *gin.Context) {
+	userID, req, ok := h.bindTwoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.authService.RegenerateBackupCodes(userID, req.Code)
+	if err != nil {
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.BackupCodesResponse{BackupCodes: codes})
+}
+
+// bindTwoFactorCode extrait l'utilisateur et le code d'une requête 2FA
+func (h *AuthHandler) bindTwoFactorCode(c *gin.Context) (userID uuid.UUID, req models.TwoFactorCodeRequest, ok bool) {
+	userID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		h.respondError(c, http.StatusUnauthorized, "User not authenticated", err.Error())
+		return userID, req, false
+	}
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		return userID, req, false
+	}
+
+	return userID, req, true
+}
+
+// respondTwoFactorError traduit les erreurs 2FA du service en status HTTP
+func (h *AuthHandler) respondTwoFactorError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	message := "Two-factor authentication failed"
+
+	switch {
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+		status = http.StatusConflict
+		message = "Two-factor authentication already enabled"
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		status = http.StatusBadRequest
+		message = "Invalid two-factor code"
+	case errors.Is(err, service.ErrTwoFactorNotEnrolled), errors.Is(err, service.ErrTwoFactorNotEnabled):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrTwoFactorRequired):
+		status = http.StatusForbidden
+		message = "Two-factor authentication is mandatory for this account"
+	}
+
+	h.respondError(c, status, message, err.Error())
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"auth/internal/models"
+	"auth/internal/signing"
 	"errors"
 	"net/http"
 	"strings"
@@ -13,7 +14,7 @@ import (
 )
 
 // JWTAuth middleware d'authentification JWT
-func JWTAuth(jwtSecret string) gin.HandlerFunc {
+func JWTAuth(keys *signing.Manager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -39,12 +40,7 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 
 		tokenString := tokenParts[1]
 
-		token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(jwtSecret), nil
-		})
+		token, err := parseAccessToken(tokenString, keys)
 		if err != nil {
 			logrus.WithError(err).WithField("request_id", c.GetHeader("X-Request-ID")).Warn("JWT validation failed")
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -63,6 +59,7 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 			c.Set("user_role", claims.Role)
 			c.Set("session_id", claims.SessionID)
 			c.Set("permissions", claims.Permissions)
+			c.Set("two_factor", claims.MFA)
 
 			// Ajouter des en-têtes pour les services downstream
 			c.Header("X-User-ID", claims.UserID.String())
@@ -92,7 +89,7 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 }
 
 // OptionalJWTAuth middleware d'authentification JWT optionnelle
-func OptionalJWTAuth(jwtSecret string) gin.HandlerFunc {
+func OptionalJWTAuth(keys *signing.Manager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -117,12 +114,7 @@ func OptionalJWTAuth(jwtSecret string) gin.HandlerFunc {
 
 		tokenString := tokenParts[1]
 
-		token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(jwtSecret), nil
-		})
+		token, err := parseAccessToken(tokenString, keys)
 
 		if err == nil {
 			if claims, ok := token.Claims.(*models.JWTClaims); ok && token.Valid {
@@ -153,6 +145,21 @@ func OptionalJWTAuth(jwtSecret string) gin.HandlerFunc {
 	}
 }
 
+// parseAccessToken valide un token d'accès signé par une clé publiée
+// Les refresh tokens et les tokens de service, signés par les mêmes clés, sont refusés.
+func parseAccessToken(tokenString string, keys *signing.Manager) (*jwt.Token, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{}, keys.Keyfunc,
+		jwt.WithValidMethods(signing.Algorithms()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims, ok := token.Claims.(*models.JWTClaims); !ok || claims.TokenType != "access" {
+		return nil, errors.New("not an access token")
+	}
+	return token, nil
+}
+
 // RequireRole middleware pour vérifier les rôles
 func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -207,6 +214,32 @@ func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 	}
 }
 
+// RequireTwoFactor middleware refusant aux comptes staff connectés sans 2FA l'accès aux routes
+// protégées, quand la 2FA leur est exigée (AUTH_TWO_FACTOR_REQUIRED)
+func RequireTwoFactor(required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("user_role")
+		if !required || !models.IsStaffRole(role) || c.GetBool("two_factor") {
+			c.Next()
+			return
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"user_id":    c.Value("user_id"),
+			"user_role":  role,
+			"path":       c.Request.URL.Path,
+			"request_id": c.GetHeader("X-Request-ID"),
+		}).Warn("Access denied: two-factor authentication required")
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":      "Two-factor authentication required",
+			"message":    "Enable two-factor authentication and log in again to access this resource",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		c.Abort()
+	}
+}
+
 // RequirePermission middleware pour vérifier les permissions spécifiques
 func RequirePermission(requiredPermissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -17,7 +17,8 @@ import (
 // Logger middleware de logging
 func Logger() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		logrus.WithFields(logrus.Fields{
+		// trace_id et span_id ajoutés depuis le contexte de la requête
+		logrus.WithContext(param.Request.Context()).WithFields(logrus.Fields{
 			"client_ip":   param.ClientIP,
 			"method":      param.Method,
 			"path":        param.Path,
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"auth/internal/models"
+	"auth/internal/signing"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// ServiceTokenAuth réserve une route interne aux services porteurs d'un token de service
+// (émis par POST /api/v1/services/token) qui contient le scope demandé
+func ServiceTokenAuth(keys *signing.Manager, issuer, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || tokenString == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":      "Service token required",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+
+		claims := &models.ServiceClaims{}
+		_, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc,
+			jwt.WithValidMethods(signing.Algorithms()),
+			jwt.WithIssuer(issuer),
+			jwt.WithExpirationRequired(),
+		)
+		if err != nil || claims.TokenType != models.TokenTypeService || claims.Subject == "" {
+			logrus.WithFields(logrus.Fields{
+				"path":       c.Request.URL.Path,
+				"ip_address": c.ClientIP(),
+				"request_id": c.GetHeader("X-Request-ID"),
+			}).Warn("Rejected internal call with invalid service token")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":      "Invalid service token",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+
+		if !hasScope(strings.Fields(claims.Scope), scope) {
+			logrus.WithFields(logrus.Fields{
+				"service":        claims.Subject,
+				"required_scope": scope,
+				"path":           c.Request.URL.Path,
+				"request_id":     c.GetHeader("X-Request-ID"),
+			}).Warn("Internal call denied: missing scope")
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":      "Insufficient scope",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+
+		c.Set("service_name", claims.Subject)
+		c.Next()
+	}
+}
+
+// hasScope indique si le scope fait partie de ceux accordés
+func hasScope(granted []string, scope string) bool {
+	for _, name := range granted {
+		if name == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -74,10 +74,30 @@ type OAuthLoginRequest struct {
 	State    string `json:"state"`
 }
 
+// TokenTypeTwoFactorChallenge type du token retourné par une connection qui attend le code 2FA
+const TokenTypeTwoFactorChallenge = "2fa_challenge"
+
 // TwoFactorSetup contient les informations de configuration 2FA
+// OTPAuthURI est l'URI otpauth:// que le client affiche en QR code.
 type TwoFactorSetup struct {
-	Secret string `json:"secret"`
-	QRCode string `json:"qr_code"`
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorCodeRequest code TOTP (ou code de secours) qui confirme une opération 2FA
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorLoginRequest seconde étape d'une connection avec 2FA
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// BackupCodesResponse codes de secours, affichés une seule fois
+type BackupCodesResponse struct {
+	BackupCodes []string `json:"backup_codes"`
 }
 
 // LoginAttempt représente une tentative de connection
@@ -107,14 +127,20 @@ type AuditLog struct {
 // Réponses
 
 // LoginResponse représente la réponse d'une connection réussie
+// Quand la 2FA est activée, la première étape ne retourne que ChallengeToken (avec son
+// expiration), à échanger contre les tokens avec le code.
 type LoginResponse struct {
-	AccessToken  string    `json:"access_token"`
-	RefreshToken string    `json:"refresh_token"`
-	TokenType    string    `json:"token_type"`
+	AccessToken  string    `json:"access_token,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
 	ExpiresIn    int       `json:"expires_in"`
 	ExpiresAt    time.Time `json:"expires_at"`
-	User         *User     `json:"user"`
-	Permissions  []string  `json:"permissions"`
+	User         *User     `json:"user,omitempty"`
+	Permissions  []string  `json:"permissions,omitempty"`
+
+	TwoFactorRequired      bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken         string `json:"challenge_token,omitempty"`
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"` // compte staff sans 2FA alors qu'elle est exigée
 }
 
 // TokenValidationResponse représente la réponse de validation de token
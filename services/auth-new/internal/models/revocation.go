@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Motifs de révocation publiés avec les événements
+const (
+	RevocationReasonLogout       = "logout"
+	RevocationReasonLogoutAll    = "logout_all"
+	RevocationReasonRevoked      = "session_revoked"
+	RevocationReasonSuspended    = "suspended"
+	RevocationReasonBanned       = "banned"
+	RevocationReasonRefreshReuse = "refresh_token_reuse" // refresh token déjà remplacé présenté
+)
+
+// RevocationEvent révocation publiée aux autres services (gateway)
+// Avec SessionID, seule cette session est révoquée ; sans, tous les tokens de l'utilisateur
+// émis au plus tard à NotBefore le sont.
+type RevocationEvent struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	SessionID *uuid.UUID `json:"session_id,omitempty"`
+	NotBefore time.Time  `json:"not_before"`
+	Reason    string     `json:"reason"`
+}
+
+// IntrospectionRequest demande d'introspection d'un token d'accès
+type IntrospectionRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+// IntrospectionResponse état d'un token d'accès (inspiré de la RFC 7662)
+// Un token inactif ne porte aucune autre information.
+type IntrospectionResponse struct {
+	Active    bool       `json:"active"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	SessionID *uuid.UUID `json:"session_id,omitempty"`
+	ExpiresAt int64      `json:"exp,omitempty"`
+	IssuedAt  int64      `json:"iat,omitempty"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "github.com/golang-jwt/jwt/v5"
+
+// TokenTypeService type des tokens émis aux autres microservices
+const TokenTypeService = "service"
+
+// ServiceClaims claims d'un token de service : le sujet est l'identifiant du service appelant
+type ServiceClaims struct {
+	Scope     string `json:"scope"` // scopes séparés par des espaces
+	TokenType string `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// ServiceTokenRequest demande de token d'un service (client credentials)
+// Les identifiants peuvent aussi être envoyés en HTTP Basic.
+type ServiceTokenRequest struct {
+	ClientID     string `json:"client_id" form:"client_id"`
+	ClientSecret string `json:"client_secret" form:"client_secret"`
+	Scope        string `json:"scope" form:"scope"` // vide : tous les scopes du client
+}
+
+// ServiceTokenResponse token de service émis
+type ServiceTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// SigningKey clé de signature des tokens telle que stockée en base
+// La clé privée (PKCS#8) est chiffrée : seul le service auth peut la lire.
+type SigningKey struct {
+	ID          string    `db:"kid"`
+	Algorithm   string    `db:"algorithm"` // RS256 ou EdDSA
+	PrivateKey  []byte    `db:"private_key"`
+	CreatedAt   time.Time `db:"created_at"`
+	ActivatesAt time.Time `db:"activates_at"` // début de la signature
+	RetiresAt   time.Time `db:"retires_at"`   // fin de la signature
+	ExpiresAt   time.Time `db:"expires_at"`   // fin de la publication dans le JWKS
+}
+
+// JWK clé publique au format JSON Web Key (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`           // RSA ou OKP
+	KeyID     string `json:"kid"`           // identifiant repris dans l'en-tête des tokens
+	Use       string `json:"use"`           // sig
+	Algorithm string `json:"alg"`           // RS256 ou EdDSA
+	Curve     string `json:"crv,omitempty"` // OKP : Ed25519
+	X         string `json:"x,omitempty"`   // OKP : clé publique
+	N         string `json:"n,omitempty"`   // RSA : module
+	E         string `json:"e,omitempty"`   // RSA : exposant
+}
+
+// JWKSet document publié sur /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
@@ -26,7 +26,7 @@ type User struct {
 	LastLoginIP      string     `json:"last_login_ip" db:"last_login_ip"`
 	LoginAttempts    int        `json:"-" db:"login_attempts"`
 	LockedUntil      *time.Time `json:"-" db:"locked_until"`
-	TwoFactorSecret  string     `json:"-" db:"two_factor_secret"`
+	TwoFactorSecret  string     `json:"-" db:"two_factor_secret"` // chiffré (AES-GCM, AUTH_JWT_KEY_ENCRYPTION_KEY)
 	TwoFactorEnabled bool       `json:"two_factor_enabled" db:"two_factor_enabled"`
 }
 
@@ -69,7 +69,8 @@ type JWTClaims struct {
 	Role        string    `json:"role"`
 	Permissions []string  `json:"permissions"`
 	SessionID   uuid.UUID `json:"session_id"`
-	TokenType   string    `json:"token_type"` // access, refresh
+	TokenType   string    `json:"token_type"`    // access, refresh, 2fa_challenge
+	MFA         bool      `json:"mfa,omitempty"` // session ouverte avec un code 2FA
 	jwt.RegisteredClaims
 }
 
@@ -159,6 +160,16 @@ func (u *User) HasRole(role string) bool {
 	return u.Role == role
 }
 
+// IsStaff vérifie si l'utilisateur a un rôle staff (2FA exigée si configurée)
+func (u *User) IsStaff() bool {
+	return IsStaffRole(u.Role)
+}
+
+// IsStaffRole vérifie si un rôle est un rôle staff
+func IsStaffRole(role string) bool {
+	return role == RoleModerator || role == RoleAdmin || role == RoleSuperUser
+}
+
 // IsAdmin vérifie si l'utilisateur est admin ou superuser
 func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin || u.Role == RoleSuperUser
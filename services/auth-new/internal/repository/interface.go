@@ -2,6 +2,7 @@ package repository
 
 import (
 	"auth/internal/models"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -34,6 +35,7 @@ type SessionRepositoryInterface interface {
 	Delete(id uuid.UUID) error
 
 	// Méthodes spécifiques aux sessions
+	RotateTokens(session *models.UserSession, previousRefreshHash string) (bool, error)
 	GetUserSessions(userID uuid.UUID) ([]*models.UserSession, error)
 	GetActiveUserSessions(userID uuid.UUID) ([]*models.UserSession, error)
 	RevokeSession(sessionID uuid.UUID) error
@@ -48,3 +50,19 @@ type SessionRepositoryInterface interface {
 	CountActiveSessions() (int64, error)
 	CountUserSessions(userID uuid.UUID) (int64, error)
 }
+
+// SigningKeyRepositoryInterface définit les méthodes pour la gestion des clés de signature
+type SigningKeyRepositoryInterface interface {
+	ListUnexpired(now time.Time) ([]*models.SigningKey, error)
+	CreateIfAbsent(key *models.SigningKey) (bool, error)
+	DeleteExpired(now time.Time) error
+}
+
+// TwoFactorRepositoryInterface définit les méthodes pour la double authentification
+type TwoFactorRepositoryInterface interface {
+	ClaimStep(userID uuid.UUID, step int64) (bool, error)
+	ReplaceBackupCodes(userID uuid.UUID, codeHashes []string) error
+	UseBackupCode(userID uuid.UUID, codeHash string) (bool, error)
+	CountBackupCodes(userID uuid.UUID) (int, error)
+	Reset(userID uuid.UUID) error
+}
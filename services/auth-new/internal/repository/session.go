@@ -106,6 +106,38 @@ func (r *SessionRepository) Update(session *models.UserSession) error {
 	return nil
 }
 
+// RotateTokens remplace les tokens d'une session active si son refresh token est encore
+// previousRefreshHash ; retourne false si un autre renouvellement l'a déjà remplacé
+func (r *SessionRepository) RotateTokens(session *models.UserSession, previousRefreshHash string) (bool, error) {
+	query := `
+		UPDATE user_sessions SET 
+			access_token_hash = $2,
+			refresh_token_hash = $3,
+			expires_at = $4,
+			last_activity = $5
+		WHERE id = $1 AND refresh_token_hash = $6 AND is_active = true
+	`
+
+	result, err := r.db.Exec(query,
+		session.ID,
+		session.AccessToken,
+		session.RefreshToken,
+		session.ExpiresAt,
+		session.LastActivity,
+		previousRefreshHash,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate session tokens: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
 // Delete supprime une session
 func (r *SessionRepository) Delete(id uuid.UUID) error {
 	query := `DELETE FROM user_sessions WHERE id = $1`
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"auth/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// signingKeyLock verrou consultatif qui sérialise la création des clés entre les instances
+const signingKeyLock = 0x6a776b73 // "jwks"
+
+// SigningKeyRepository implémente SigningKeyRepositoryInterface
+type SigningKeyRepository struct {
+	db *sqlx.DB
+}
+
+// NewSigningKeyRepository crée un nouveau repository de clés de signature
+func NewSigningKeyRepository(db *sqlx.DB) *SigningKeyRepository {
+	return &SigningKeyRepository{db: db}
+}
+
+// ListUnexpired récupère les clés encore publiées, de la plus ancienne à la plus récente
+func (r *SigningKeyRepository) ListUnexpired(now time.Time) ([]*models.SigningKey, error) {
+	query := `
+		SELECT kid, algorithm, private_key, created_at, activates_at, retires_at, expires_at
+		FROM signing_keys
+		WHERE expires_at > $1
+		ORDER BY activates_at ASC
+	`
+
+	var keys []*models.SigningKey
+	if err := r.db.Select(&keys, query, now); err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// CreateIfAbsent enregistre la clé sauf si une clé enregistrée signe encore après
+// key.ActivatesAt (créée entre-temps par une autre instance) ; retourne true si la clé a
+// été enregistrée
+func (r *SigningKeyRepository) CreateIfAbsent(key *models.SigningKey) (created bool, err error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Exec(`SELECT pg_advisory_xact_lock($1)`, signingKeyLock); err != nil {
+		return false, fmt.Errorf("failed to lock signing keys: %w", err)
+	}
+
+	var exists bool
+	if err = tx.Get(&exists, `SELECT EXISTS(SELECT 1 FROM signing_keys WHERE retires_at > $1)`, key.ActivatesAt); err != nil {
+		return false, fmt.Errorf("failed to check signing keys: %w", err)
+	}
+	if exists {
+		return false, tx.Commit()
+	}
+
+	query := `
+		INSERT INTO signing_keys (kid, algorithm, private_key, created_at, activates_at, retires_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	if _, err = tx.Exec(query,
+		key.ID,
+		key.Algorithm,
+		key.PrivateKey,
+		key.CreatedAt,
+		key.ActivatesAt,
+		key.RetiresAt,
+		key.ExpiresAt,
+	); err != nil {
+		return false, fmt.Errorf("failed to create signing key: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit signing key: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteExpired supprime les clés qui ne sont plus publiées
+func (r *SigningKeyRepository) DeleteExpired(now time.Time) error {
+	if _, err := r.db.Exec(`DELETE FROM signing_keys WHERE expires_at <= $1`, now); err != nil {
+		return fmt.Errorf("failed to delete expired signing keys: %w", err)
+	}
+	return nil
+}
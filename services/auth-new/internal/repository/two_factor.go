@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// TwoFactorRepository implémente TwoFactorRepositoryInterface
+type TwoFactorRepository struct {
+	db *sqlx.DB
+}
+
+// NewTwoFactorRepository crée un nouveau repository de double authentification
+func NewTwoFactorRepository(db *sqlx.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// ClaimStep enregistre le pas de temps TOTP d'un code accepté ; retourne false si un code
+// de ce pas (ou d'un pas plus récent) a déjà été utilisé, y compris par une autre instance
+func (r *TwoFactorRepository) ClaimStep(userID uuid.UUID, step int64) (bool, error) {
+	query := `
+		UPDATE users
+		SET two_factor_last_step = $2
+		WHERE id = $1 AND (two_factor_last_step IS NULL OR two_factor_last_step < $2)
+	`
+
+	result, err := r.db.Exec(query, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim two-factor step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// ReplaceBackupCodes remplace les codes de secours d'un utilisateur (hachés)
+func (r *TwoFactorRepository) ReplaceBackupCodes(userID uuid.UUID, codeHashes []string) (err error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Exec(`DELETE FROM two_factor_backup_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete backup codes: %w", err)
+	}
+
+	for _, codeHash := range codeHashes {
+		if _, err = tx.Exec(`INSERT INTO two_factor_backup_codes (user_id, code) VALUES ($1, $2)`, userID, codeHash); err != nil {
+			return fmt.Errorf("failed to create backup code: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit backup codes: %w", err)
+	}
+	return nil
+}
+
+// UseBackupCode consomme un code de secours ; retourne false s'il n'existe pas ou a déjà servi
+func (r *TwoFactorRepository) UseBackupCode(userID uuid.UUID, codeHash string) (bool, error) {
+	query := `
+		UPDATE two_factor_backup_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code = $2 AND used_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use backup code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// CountBackupCodes compte les codes de secours encore utilisables
+func (r *TwoFactorRepository) CountBackupCodes(userID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM two_factor_backup_codes WHERE user_id = $1 AND used_at IS NULL`
+	if err := r.db.Get(&count, query, userID); err != nil {
+		return 0, fmt.Errorf("failed to count backup codes: %w", err)
+	}
+	return count, nil
+}
+
+// Reset efface les codes de secours et le dernier pas TOTP (désactivation de la 2FA)
+func (r *TwoFactorRepository) Reset(userID uuid.UUID) error {
+	if _, err := r.db.Exec(`DELETE FROM two_factor_backup_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete backup codes: %w", err)
+	}
+	if _, err := r.db.Exec(`UPDATE users SET two_factor_last_step = NULL WHERE id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to reset two-factor step: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package revocation
+
+import (
+	"auth/internal/config"
+	"auth/internal/models"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+// Publisher publie les révocations de sessions sur NATS
+// Sans connexion NATS, les révocations ne sont que journalisées : le gateway les
+// découvre alors par introspection.
+type Publisher struct {
+	natsConn *nats.Conn
+	subject  string
+}
+
+// NewPublisher se connecte à NATS et crée le publieur
+// Une connexion impossible n'est pas bloquante : le service démarre sans publication.
+func NewPublisher(cfg *config.RevocationConfig) *Publisher {
+	p := &Publisher{subject: cfg.Subject}
+	if cfg.NATSURL == "" {
+		logrus.Info("NATS not configured, session revocations will not be published")
+		return p
+	}
+
+	natsConn, err := nats.Connect(cfg.NATSURL,
+		nats.Name("auth-service"),
+		nats.Timeout(cfg.ConnectTimeout),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to connect to NATS, session revocations will not be published")
+		return p
+	}
+
+	logrus.WithField("url", cfg.NATSURL).Info("Connected to NATS for session revocations")
+	p.natsConn = natsConn
+	return p
+}
+
+// SessionRevoked publie la révocation d'une session
+func (p *Publisher) SessionRevoked(userID, sessionID uuid.UUID, reason string) {
+	p.publish(&models.RevocationEvent{
+		UserID:    userID,
+		SessionID: &sessionID,
+		NotBefore: time.Now().UTC(),
+		Reason:    reason,
+	})
+}
+
+// UserRevoked publie la révocation de toutes les sessions d'un utilisateur
+func (p *Publisher) UserRevoked(userID uuid.UUID, reason string) {
+	p.publish(&models.RevocationEvent{
+		UserID:    userID,
+		NotBefore: time.Now().UTC(),
+		Reason:    reason,
+	})
+}
+
+// Close ferme la connexion NATS après avoir envoyé les derniers événements
+func (p *Publisher) Close() {
+	if p.natsConn == nil {
+		return
+	}
+	if err := p.natsConn.Drain(); err != nil {
+		logrus.WithError(err).Debug("Failed to drain NATS connection")
+	}
+}
+
+// publish envoie l'événement ; un échec est journalisé sans faire échouer la révocation
+func (p *Publisher) publish(event *models.RevocationEvent) {
+	fields := logrus.Fields{
+		"user_id": event.UserID,
+		"reason":  event.Reason,
+	}
+	if event.SessionID != nil {
+		fields["session_id"] = *event.SessionID
+	}
+
+	if p.natsConn == nil {
+		logrus.WithFields(fields).Debug("Session revoked (not published)")
+		return
+	}
+
+	if err := p.send(event); err != nil {
+		logrus.WithFields(fields).WithError(err).Error("Failed to publish session revocation")
+		return
+	}
+	logrus.WithFields(fields).Info("Session revocation published")
+}
+
+// send encode et publie l'événement
+func (p *Publisher) send(event *models.RevocationEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode revocation event: %w", err)
+	}
+	if err := p.natsConn.Publish(p.subject, data); err != nil {
+		return fmt.Errorf("failed to publish revocation event: %w", err)
+	}
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"auth/internal/config"
 	"auth/internal/models"
 	"auth/internal/repository"
+	"auth/internal/revocation"
+	"auth/internal/signing"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
 	"regexp"
@@ -19,21 +22,30 @@ import (
 
 // AuthService gère l'authentification des utilisateurs
 type AuthService struct {
-	userRepo    repository.UserRepositoryInterface
-	sessionRepo repository.SessionRepositoryInterface
-	config      *config.Config
+	userRepo      repository.UserRepositoryInterface
+	sessionRepo   repository.SessionRepositoryInterface
+	twoFactorRepo repository.TwoFactorRepositoryInterface
+	config        *config.Config
+	revocations   *revocation.Publisher
+	keys          *signing.Manager
 }
 
 // NewAuthService crée un nouveau service d'authentification
 func NewAuthService(
 	userRepo repository.UserRepositoryInterface,
 	sessionRepo repository.SessionRepositoryInterface,
+	twoFactorRepo repository.TwoFactorRepositoryInterface,
 	config *config.Config,
+	revocations *revocation.Publisher,
+	keys *signing.Manager,
 ) *AuthService {
 	return &AuthService{
-		userRepo:    userRepo,
-		sessionRepo: sessionRepo,
-		config:      config,
+		userRepo:      userRepo,
+		sessionRepo:   sessionRepo,
+		twoFactorRepo: twoFactorRepo,
+		config:        config,
+		revocations:   revocations,
+		keys:          keys,
 	}
 }
 
@@ -125,27 +137,33 @@ func (s *AuthService) Login(req models.LoginRequest, ipAddress, userAgent string
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
-	// Vérifier 2FA si activé
-	if user.TwoFactorEnabled && req.TwoFactorCode == "" {
-		return nil, fmt.Errorf("two_factor_required")
-	}
-
-	if user.TwoFactorEnabled && req.TwoFactorCode != "" {
-		if !s.verifyTwoFactorCode(user.TwoFactorSecret, req.TwoFactorCode) {
+	// Vérifier 2FA si activé : sans code, la connection se termine via /login/2fa
+	if user.TwoFactorEnabled {
+		if req.TwoFactorCode == "" {
+			return s.twoFactorChallenge(user)
+		}
+		if !s.verifyTwoFactorCode(user, req.TwoFactorCode) {
+			s.incrementFailedAttempts(user)
 			s.logLoginAttempt(&user.ID, req.Username, ipAddress, userAgent, false, "invalid_2fa")
-			return nil, fmt.Errorf("invalid two-factor code")
+			return nil, ErrInvalidTwoFactorCode
 		}
 	}
 
-	// Générer les tokens
-	accessToken, refreshToken, expiresAt, err := s.generateTokens(user)
+	return s.completeLogin(user, user.TwoFactorEnabled, ipAddress, userAgent)
+}
+
+// completeLogin crée la session et les tokens d'un utilisateur authentifié
+func (s *AuthService) completeLogin(user *models.User, mfa bool, ipAddress, userAgent string) (*models.LoginResponse, error) {
+	// Générer les tokens (ils portent l'ID de la session, utilisé pour la révoquer)
+	sessionID := uuid.New()
+	accessToken, refreshToken, expiresAt, err := s.generateTokens(user, sessionID, mfa)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
 
-	// Créer la session
+	// Créer la session (valide aussi longtemps que son refresh token)
 	session := &models.UserSession{
-		ID:           uuid.New(),
+		ID:           sessionID,
 		UserID:       user.ID,
 		AccessToken:  s.hashToken(accessToken),
 		RefreshToken: s.hashToken(refreshToken),
@@ -153,7 +171,7 @@ func (s *AuthService) Login(req models.LoginRequest, ipAddress, userAgent string
 		IPAddress:    ipAddress,
 		UserAgent:    userAgent,
 		CreatedAt:    time.Now(),
-		ExpiresAt:    expiresAt,
+		ExpiresAt:    time.Now().Add(s.config.JWT.RefreshTokenExpiration),
 		LastActivity: time.Now(),
 		IsActive:     true,
 	}
@@ -173,27 +191,30 @@ func (s *AuthService) Login(req models.LoginRequest, ipAddress, userAgent string
 	}
 
 	// Log de la connection réussie
-	s.logLoginAttempt(&user.ID, req.Username, ipAddress, userAgent, true, "success")
+	s.logLoginAttempt(&user.ID, user.Username, ipAddress, userAgent, true, "success")
 
 	logrus.WithFields(logrus.Fields{
 		"user_id":    user.ID,
 		"username":   user.Username,
 		"ip_address": ipAddress,
 		"session_id": session.ID,
+		"mfa":        mfa,
 	}).Info("User logged in successfully")
 
 	// Préparer la réponse
+	setupRequired := s.twoFactorSetupRequired(user)
 	user.PasswordHash = ""
 	user.TwoFactorSecret = ""
 
 	return &models.LoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    int(s.config.JWT.AccessTokenExpiration.Seconds()),
-		ExpiresAt:    expiresAt,
-		User:         user,
-		Permissions:  models.GetUserPermissions(user.Role),
+		AccessToken:            accessToken,
+		RefreshToken:           refreshToken,
+		TokenType:              "Bearer",
+		ExpiresIn:              int(s.config.JWT.AccessTokenExpiration.Seconds()),
+		ExpiresAt:              expiresAt,
+		User:                   user,
+		Permissions:            models.GetUserPermissions(user.Role),
+		TwoFactorSetupRequired: setupRequired,
 	}, nil
 }
 
@@ -219,12 +240,40 @@ func (s *AuthService) RefreshToken(req models.RefreshTokenRequest) (*models.Logi
 		return nil, fmt.Errorf("user cannot login")
 	}
 
-	// Générer de nouveaux tokens
-	newAccessToken, newRefreshToken, expiresAt, err := s.generateTokens(user)
+	// Une session révoquée ne peut plus être prolongée
+	session, err := s.sessionRepo.GetByID(claims.SessionID)
+	if err != nil || !session.IsActive || session.UserID != user.ID {
+		return nil, fmt.Errorf("session has been revoked")
+	}
+
+	// Seul le dernier refresh token émis pour la session est accepté : un token déjà remplacé
+	// qui revient a été copié, la session est révoquée
+	presentedHash := s.hashToken(req.RefreshToken)
+	if subtle.ConstantTimeCompare([]byte(presentedHash), []byte(session.RefreshToken)) != 1 {
+		s.revokeReusedSession(session)
+		return nil, fmt.Errorf("session has been revoked")
+	}
+
+	// Générer de nouveaux tokens pour la même session
+	newAccessToken, newRefreshToken, expiresAt, err := s.generateTokens(user, session.ID, claims.MFA)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate new tokens: %w", err)
 	}
 
+	session.AccessToken = s.hashToken(newAccessToken)
+	session.RefreshToken = s.hashToken(newRefreshToken)
+	session.ExpiresAt = time.Now().Add(s.config.JWT.RefreshTokenExpiration)
+	session.LastActivity = time.Now()
+	rotated, err := s.sessionRepo.RotateTokens(session, presentedHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+	if !rotated {
+		// Le même refresh token a été utilisé en parallèle
+		s.revokeReusedSession(session)
+		return nil, fmt.Errorf("session has been revoked")
+	}
+
 	// Préparer la réponse
 	user.PasswordHash = ""
 	user.TwoFactorSecret = ""
@@ -240,6 +289,20 @@ func (s *AuthService) RefreshToken(req models.RefreshTokenRequest) (*models.Logi
 	}, nil
 }
 
+// revokeReusedSession révoque une session dont un refresh token remplacé a été présenté
+func (s *AuthService) revokeReusedSession(session *models.UserSession) {
+	logrus.WithFields(logrus.Fields{
+		"user_id":    session.UserID,
+		"session_id": session.ID,
+	}).Warn("Reused refresh token, revoking session")
+
+	if err := s.sessionRepo.RevokeSession(session.ID); err != nil {
+		logrus.WithError(err).WithField("session_id", session.ID).Error("Failed to revoke session after refresh token reuse")
+		return
+	}
+	s.revocations.SessionRevoked(session.UserID, session.ID, models.RevocationReasonRefreshReuse)
+}
+
 // ValidateToken valide un token JWT
 func (s *AuthService) ValidateToken(tokenString string) (*models.JWTClaims, error) {
 	return s.validateToken(tokenString)
@@ -247,12 +310,22 @@ func (s *AuthService) ValidateToken(tokenString string) (*models.JWTClaims, erro
 
 // Logout déconnecte un utilisateur
 func (s *AuthService) Logout(userID uuid.UUID, sessionID uuid.UUID) error {
-	return s.sessionRepo.RevokeSession(sessionID)
+	if err := s.sessionRepo.RevokeSession(sessionID); err != nil {
+		return err
+	}
+
+	s.revocations.SessionRevoked(userID, sessionID, models.RevocationReasonLogout)
+	return nil
 }
 
 // LogoutAllDevices déconnecte un utilisateur de tous ses appareils
 func (s *AuthService) LogoutAllDevices(userID uuid.UUID) error {
-	return s.sessionRepo.RevokeAllUserSessions(userID)
+	if err := s.sessionRepo.RevokeAllUserSessions(userID); err != nil {
+		return err
+	}
+
+	s.revocations.UserRevoked(userID, models.RevocationReasonLogoutAll)
+	return nil
 }
 
 // ChangePassword change le mot de passe d'un utilisateur
@@ -422,6 +495,12 @@ func (s *AuthService) SuspendUser(userID uuid.UUID, reason string) error {
 		return fmt.Errorf("failed to suspend user: %w", err)
 	}
 
+	// Les sessions ouvertes ne doivent pas survivre à la suspension
+	if err := s.sessionRepo.RevokeAllUserSessions(userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions of suspended user: %w", err)
+	}
+	s.revocations.UserRevoked(userID, models.RevocationReasonSuspended)
+
 	logrus.WithFields(logrus.Fields{
 		"user_id":  userID,
 		"username": user.Username,
@@ -470,22 +549,103 @@ func (s *AuthService) RevokeSession(userID, sessionID uuid.UUID) error {
 		return fmt.Errorf("session does not belong to user")
 	}
 
-	return s.sessionRepo.RevokeSession(sessionID)
+	if err := s.sessionRepo.RevokeSession(sessionID); err != nil {
+		return err
+	}
+
+	s.revocations.SessionRevoked(userID, sessionID, models.RevocationReasonRevoked)
+	return nil
+}
+
+// AdminRevokeSession révoque une session quel que soit son propriétaire (admin)
+func (s *AuthService) AdminRevokeSession(sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		return fmt.Errorf("session not found: %w", err)
+	}
+
+	if err := s.sessionRepo.RevokeSession(sessionID); err != nil {
+		return err
+	}
+
+	s.revocations.SessionRevoked(session.UserID, sessionID, models.RevocationReasonRevoked)
+	return nil
 }
 
-// EnableTwoFactor active l'authentification à deux facteurs
-func (s *AuthService) EnableTwoFactor(userID uuid.UUID) (*models.TwoFactorSetup, error) {
-	return nil, fmt.Errorf("two-factor authentication not implemented yet")
+// BanUser bannit un utilisateur et révoque toutes ses sessions
+func (s *AuthService) BanUser(userID uuid.UUID, reason string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	user.Status = models.StatusBanned
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to ban user: %w", err)
+	}
+
+	if err := s.sessionRepo.RevokeAllUserSessions(userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions of banned user: %w", err)
+	}
+	s.revocations.UserRevoked(userID, models.RevocationReasonBanned)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":  userID,
+		"username": user.Username,
+		"reason":   reason,
+	}).Info("User banned")
+
+	return nil
 }
 
-// DisableTwoFactor désactive l'authentification à deux facteurs
-func (s *AuthService) DisableTwoFactor(userID uuid.UUID, code string) error {
-	return fmt.Errorf("two-factor authentication not implemented yet")
+// UnbanUser lève le bannissement d'un utilisateur
+func (s *AuthService) UnbanUser(userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	if !user.IsBanned() {
+		return fmt.Errorf("user is not banned")
+	}
+
+	return s.ActivateUser(userID)
 }
 
-// GetTwoFactorQR génère un QR code pour l'authentification à deux facteurs
-func (s *AuthService) GetTwoFactorQR(userID uuid.UUID) (string, error) {
-	return "", fmt.Errorf("two-factor authentication not implemented yet")
+// Introspect indique si un token d'accès est encore valide : signature, expiration,
+// session active et compte actif
+func (s *AuthService) Introspect(tokenString string) *models.IntrospectionResponse {
+	inactive := &models.IntrospectionResponse{Active: false}
+
+	claims, err := s.validateToken(tokenString)
+	if err != nil || claims.TokenType != "access" {
+		return inactive
+	}
+
+	session, err := s.sessionRepo.GetByID(claims.SessionID)
+	if err != nil || !session.IsActive || session.UserID != claims.UserID || time.Now().After(session.ExpiresAt) {
+		return inactive
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil || !user.IsActive() {
+		return inactive
+	}
+
+	response := &models.IntrospectionResponse{
+		Active:    true,
+		UserID:    &claims.UserID,
+		SessionID: &claims.SessionID,
+	}
+	if claims.ExpiresAt != nil {
+		response.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		response.IssuedAt = claims.IssuedAt.Unix()
+	}
+	return response
 }
 
 // GetLoginAttempts récupère les tentatives de connection
@@ -530,6 +690,7 @@ func (s *AuthService) GetUserInfo(userID uuid.UUID) (*models.User, error) {
 
 // Close ferme le service d'authentification
 func (s *AuthService) Close() error {
+	s.revocations.Close()
 	logrus.Info("Auth service closed")
 	return nil
 }
@@ -632,8 +793,8 @@ func (s *AuthService) verifyPassword(password, hash string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
 
-// generateTokens génère les tokens JWT (access et refresh)
-func (s *AuthService) generateTokens(user *models.User) (accessTokenStr, refreshTokenStr string, expiresAt time.Time, err error) {
+// generateTokens génère les tokens JWT (access et refresh) ; mfa indique une connection avec 2FA
+func (s *AuthService) generateTokens(user *models.User, sessionID uuid.UUID, mfa bool) (accessTokenStr, refreshTokenStr string, expiresAt time.Time, err error) {
 	now := time.Now()
 	accessExpiry := now.Add(s.config.JWT.AccessTokenExpiration)
 	refreshExpiry := now.Add(s.config.JWT.RefreshTokenExpiration)
@@ -645,8 +806,9 @@ func (s *AuthService) generateTokens(user *models.User) (accessTokenStr, refresh
 		Email:       user.Email,
 		Role:        user.Role,
 		Permissions: models.GetUserPermissions(user.Role),
-		SessionID:   uuid.New(),
+		SessionID:   sessionID,
 		TokenType:   "access",
+		MFA:         mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(accessExpiry),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -657,8 +819,7 @@ func (s *AuthService) generateTokens(user *models.User) (accessTokenStr, refresh
 	}
 
 	// Générer le token d'accès
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenString, err := accessToken.SignedString([]byte(s.config.JWT.Secret))
+	accessTokenString, err := s.keys.Sign(accessClaims)
 	if err != nil {
 		return "", "", time.Time{}, err
 	}
@@ -671,18 +832,20 @@ func (s *AuthService) generateTokens(user *models.User) (accessTokenStr, refresh
 		Role:      user.Role,
 		SessionID: accessClaims.SessionID,
 		TokenType: "refresh",
+		MFA:       mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(refreshExpiry),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    s.config.JWT.Issuer,
 			Subject:   user.ID.String(),
+			// Identifiant unique : deux renouvellements dans la même seconde donnent des tokens distincts
+			ID: uuid.NewString(),
 		},
 	}
 
 	// Générer le refresh token
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString([]byte(s.config.JWT.Secret))
+	refreshTokenString, err := s.keys.Sign(refreshClaims)
 	if err != nil {
 		return "", "", time.Time{}, err
 	}
@@ -692,12 +855,10 @@ func (s *AuthService) generateTokens(user *models.User) (accessTokenStr, refresh
 
 // validateToken valide un token JWT
 func (s *AuthService) validateToken(tokenString string) (*models.JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.config.JWT.Secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{}, s.keys.Keyfunc,
+		jwt.WithValidMethods(signing.Algorithms()),
+		jwt.WithIssuer(s.config.JWT.Issuer),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -787,9 +948,3 @@ func (s *AuthService) sendEmailVerification(user *models.User) {
 		"email":   user.Email,
 	}).Info("Email verification would be sent")
 }
-
-// verifyTwoFactorCode vérifie un code 2FA (stub)
-func (s *AuthService) verifyTwoFactorCode(secret, code string) bool {
-	// TODO: Implémenter la vérification TOTP
-	return false
-}
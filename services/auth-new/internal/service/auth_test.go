@@ -0,0 +1,257 @@
+package service
+
+import (
+	"auth/internal/config"
+	"auth/internal/models"
+	"auth/internal/repository"
+	"auth/internal/revocation"
+	"auth/internal/signing"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memorySigningKeys clés de signature en mémoire
+type memorySigningKeys struct {
+	keys []*models.SigningKey
+}
+
+func (r *memorySigningKeys) ListUnexpired(time.Time) ([]*models.SigningKey, error) {
+	return r.keys, nil
+}
+
+func (r *memorySigningKeys) CreateIfAbsent(key *models.SigningKey) (bool, error) {
+	r.keys = append(r.keys, key)
+	return true, nil
+}
+
+func (r *memorySigningKeys) DeleteExpired(time.Time) error { return nil }
+
+// memoryUsers utilisateurs en mémoire
+type memoryUsers struct {
+	repository.UserRepositoryInterface
+
+	users map[uuid.UUID]*models.User
+}
+
+func (r *memoryUsers) GetByID(id uuid.UUID) (*models.User, error) {
+	user, exists := r.users[id]
+	if !exists {
+		return nil, errors.New("user not found")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *memoryUsers) Update(user *models.User) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+// memorySessions sessions en mémoire, avec la condition de RotateTokens en base
+type memorySessions struct {
+	repository.SessionRepositoryInterface
+
+	mu       sync.Mutex
+	sessions map[uuid.UUID]*models.UserSession
+}
+
+func (r *memorySessions) Create(session *models.UserSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *session
+	r.sessions[session.ID] = &copied
+	return nil
+}
+
+func (r *memorySessions) GetByID(id uuid.UUID) (*models.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, exists := r.sessions[id]
+	if !exists {
+		return nil, errors.New("session not found")
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (r *memorySessions) RotateTokens(session *models.UserSession, previousRefreshHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.sessions[session.ID]
+	if !exists || !stored.IsActive || stored.RefreshToken != previousRefreshHash {
+		return false, nil
+	}
+	stored.AccessToken = session.AccessToken
+	stored.RefreshToken = session.RefreshToken
+	stored.ExpiresAt = session.ExpiresAt
+	stored.LastActivity = session.LastActivity
+	return true, nil
+}
+
+func (r *memorySessions) RevokeSession(sessionID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, exists := r.sessions[sessionID]; exists {
+		session.IsActive = false
+	}
+	return nil
+}
+
+// newTestAuthService service sur des repositories en mémoire, sans NATS
+func newTestAuthService(t *testing.T) *AuthService {
+	t.Helper()
+
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			Algorithm:              config.AlgorithmEdDSA,
+			KeyEncryptionKey:       "test-key-encryption-key",
+			KeyRotationInterval:    time.Hour,
+			KeyOverlap:             time.Hour,
+			Issuer:                 "auth-test",
+			AccessTokenExpiration:  time.Minute,
+			RefreshTokenExpiration: time.Hour,
+		},
+	}
+	keys, err := signing.NewManager(&memorySigningKeys{}, &cfg.JWT)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	return NewAuthService(
+		&memoryUsers{users: make(map[uuid.UUID]*models.User)},
+		&memorySessions{sessions: make(map[uuid.UUID]*models.UserSession)},
+		&memoryTwoFactor{lastSteps: make(map[uuid.UUID]int64)},
+		cfg,
+		revocation.NewPublisher(&config.RevocationConfig{}),
+		keys,
+	)
+}
+
+// newTestSession ouvre une session pour un nouvel utilisateur actif et retourne son refresh token
+func newTestSession(t *testing.T, s *AuthService) (*models.User, uuid.UUID, string) {
+	t.Helper()
+
+	user := &models.User{ID: uuid.New(), Username: "aldric", Email: "aldric@example.com", Role: "player", Status: models.StatusActive}
+	if err := s.userRepo.Update(user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	sessionID := uuid.New()
+	accessToken, refreshToken, _, err := s.generateTokens(user, sessionID, false)
+	if err != nil {
+		t.Fatalf("generateTokens: %v", err)
+	}
+	err = s.sessionRepo.Create(&models.UserSession{
+		ID:           sessionID,
+		UserID:       user.ID,
+		AccessToken:  s.hashToken(accessToken),
+		RefreshToken: s.hashToken(refreshToken),
+		ExpiresAt:    time.Now().Add(time.Hour),
+		IsActive:     true,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return user, sessionID, refreshToken
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	s := newTestAuthService(t)
+	_, sessionID, refreshToken := newTestSession(t, s)
+
+	resp, err := s.RefreshToken(models.RefreshTokenRequest{RefreshToken: refreshToken})
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if resp.RefreshToken == "" || resp.RefreshToken == refreshToken {
+		t.Fatal("refresh did not issue a new refresh token")
+	}
+
+	session, _ := s.sessionRepo.GetByID(sessionID)
+	if session.RefreshToken != s.hashToken(resp.RefreshToken) {
+		t.Error("session does not store the new refresh token hash")
+	}
+
+	// Le nouveau token permet à son tour de renouveler la session
+	if _, err = s.RefreshToken(models.RefreshTokenRequest{RefreshToken: resp.RefreshToken}); err != nil {
+		t.Fatalf("RefreshToken with the rotated token: %v", err)
+	}
+}
+
+// TestRefreshTokenReuseRevokesSession un refresh token déjà remplacé révoque la session,
+// y compris pour le détenteur du token courant
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	s := newTestAuthService(t)
+	_, sessionID, refreshToken := newTestSession(t, s)
+
+	resp, err := s.RefreshToken(models.RefreshTokenRequest{RefreshToken: refreshToken})
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+
+	if _, err = s.RefreshToken(models.RefreshTokenRequest{RefreshToken: refreshToken}); err == nil {
+		t.Fatal("rotated-out refresh token accepted")
+	}
+	session, _ := s.sessionRepo.GetByID(sessionID)
+	if session.IsActive {
+		t.Fatal("session still active after refresh token reuse")
+	}
+
+	if _, err = s.RefreshToken(models.RefreshTokenRequest{RefreshToken: resp.RefreshToken}); err == nil {
+		t.Fatal("current refresh token accepted after the session was revoked")
+	}
+}
+
+// TestRefreshTokenConcurrentReuse deux renouvellements simultanés du même token : un seul
+// réussit et la session est révoquée
+func TestRefreshTokenConcurrentReuse(t *testing.T) {
+	s := newTestAuthService(t)
+	_, sessionID, refreshToken := newTestSession(t, s)
+
+	const attempts = 8
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.RefreshToken(models.RefreshTokenRequest{RefreshToken: refreshToken}); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Fatalf("%d concurrent refreshes succeeded, want 1", succeeded)
+	}
+	session, _ := s.sessionRepo.GetByID(sessionID)
+	if session.IsActive {
+		t.Fatal("session still active after concurrent refresh token reuse")
+	}
+}
+
+func TestRefreshTokenRejectsAccessToken(t *testing.T) {
+	s := newTestAuthService(t)
+	user, sessionID, _ := newTestSession(t, s)
+
+	accessToken, _, _, err := s.generateTokens(user, sessionID, false)
+	if err != nil {
+		t.Fatalf("generateTokens: %v", err)
+	}
+	if _, err = s.RefreshToken(models.RefreshTokenRequest{RefreshToken: accessToken}); err == nil {
+		t.Fatal("access token accepted as a refresh token")
+	}
+}
@@ -26,15 +26,22 @@ type AuthServiceInterface interface {
 	UpdateUser(userID uuid.UUID, req *models.UpdateUserRequest) (*models.User, error)
 	SuspendUser(userID uuid.UUID, reason string) error
 	ActivateUser(userID uuid.UUID) error
+	BanUser(userID uuid.UUID, reason string) error
+	UnbanUser(userID uuid.UUID) error
 
 	// Two-Factor Authentication
 	EnableTwoFactor(userID uuid.UUID) (*models.TwoFactorSetup, error)
 	DisableTwoFactor(userID uuid.UUID, code string) error
 	GetTwoFactorQR(userID uuid.UUID) (string, error)
+	ConfirmTwoFactor(userID uuid.UUID, code string) ([]string, error)
+	RegenerateBackupCodes(userID uuid.UUID, code string) ([]string, error)
+	CountBackupCodes(userID uuid.UUID) (int, error)
+	CompleteTwoFactorLogin(req models.TwoFactorLoginRequest, ipAddress, userAgent string) (*models.LoginResponse, error)
 
 	// Gestion des sessions
 	GetSessions(userID uuid.UUID) ([]*models.UserSession, error)
 	RevokeSession(userID, sessionID uuid.UUID) error
+	AdminRevokeSession(sessionID uuid.UUID) error
 
 	// Audit et logs
 	GetLoginAttempts(limit, offset int, filters map[string]interface{}) ([]*models.LoginAttempt, int64, error)
@@ -54,5 +61,6 @@ type AuthServiceInterface interface {
 	// Utilitaires
 	GetUserInfo(userID uuid.UUID) (*models.User, error)
 	ValidateToken(token string) (*models.JWTClaims, error)
+	Introspect(token string) *models.IntrospectionResponse
 	Close() error
 }
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"auth/internal/config"
+	"auth/internal/models"
+	"auth/internal/signing"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Erreurs de l'émission des tokens de service
+var (
+	ErrInvalidClient = errors.New("invalid client credentials")
+	ErrInvalidScope  = errors.New("scope not allowed for this client")
+)
+
+// ServiceTokenIssuer émet des tokens courts aux services qui appellent les routes internes
+type ServiceTokenIssuer struct {
+	config  *config.ServiceAuthConfig
+	issuer  string
+	clients map[string]config.ServiceClient
+	keys    *signing.Manager
+}
+
+// NewServiceTokenIssuer crée l'émetteur à partir des clients configurés
+func NewServiceTokenIssuer(cfg *config.ServiceAuthConfig, issuer string, keys *signing.Manager) *ServiceTokenIssuer {
+	clients := make(map[string]config.ServiceClient, len(cfg.Clients))
+	for _, client := range cfg.Clients {
+		clients[client.ID] = client
+	}
+
+	return &ServiceTokenIssuer{
+		config:  cfg,
+		issuer:  issuer,
+		clients: clients,
+		keys:    keys,
+	}
+}
+
+// Issue authentifie un service et lui émet un token limité aux scopes demandés
+func (s *ServiceTokenIssuer) Issue(clientID, clientSecret, scope string) (*models.ServiceTokenResponse, error) {
+	client, exists := s.clients[clientID]
+	if !exists || subtle.ConstantTimeCompare([]byte(client.Secret), []byte(clientSecret)) != 1 {
+		return nil, ErrInvalidClient
+	}
+
+	scopes := client.Scopes
+	if requested := strings.Fields(scope); len(requested) > 0 {
+		for _, name := range requested {
+			if !containsScope(client.Scopes, name) {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidScope, name)
+			}
+		}
+		scopes = requested
+	}
+
+	now := time.Now()
+	claims := &models.ServiceClaims{
+		Scope:     strings.Join(scopes, " "),
+		TokenType: models.TokenTypeService,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.config.TokenExpiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    s.issuer,
+			Subject:   client.ID,
+			ID:        uuid.New().String(),
+		},
+	}
+
+	token, err := s.keys.Sign(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign service token: %w", err)
+	}
+
+	return &models.ServiceTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.config.TokenExpiration.Seconds()),
+		Scope:       claims.Scope,
+	}, nil
+}
+
+// containsScope indique si un scope fait partie de la liste
+func containsScope(scopes []string, scope string) bool {
+	for _, allowed := range scopes {
+		if allowed == scope {
+			return true
+		}
+	}
+	return false
+}
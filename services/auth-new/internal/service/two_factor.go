@@ -0,0 +1,351 @@
+package service
+
+import (
+	"auth/internal/models"
+	"auth/internal/totp"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Codes de secours : 10 codes de 10 caractères, affichés en deux groupes (XXXXX-XXXXX)
+const (
+	backupCodeCount    = 10
+	backupCodeLength   = 10
+	backupCodeGroup    = 5
+	backupCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // sans 0/O ni 1/I
+)
+
+// Erreurs de la double authentification
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor enrollment not started")
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is required for staff accounts")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrInvalidChallenge        = errors.New("invalid or expired two-factor challenge")
+)
+
+// EnableTwoFactor démarre l'enrôlement : un nouveau secret est enregistré, mais la 2FA
+// n'est activée qu'après confirmation d'un premier code (ConfirmTwoFactor)
+func (s *AuthService) EnableTwoFactor(userID uuid.UUID) (*models.TwoFactorSetup, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	// Le secret est stocké chiffré, lié au compte
+	sealed, err := s.keys.Seal(secret, user.ID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt two-factor secret: %w", err)
+	}
+
+	user.TwoFactorSecret = sealed
+	if err = s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to save two-factor secret: %w", err)
+	}
+
+	return &models.TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.config.Security.TwoFactorIssuer, user.Username, secret),
+	}, nil
+}
+
+// GetTwoFactorQR retourne l'URI otpauth:// de l'enrôlement en cours, à afficher en QR code
+func (s *AuthService) GetTwoFactorQR(userID uuid.UUID) (string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "", fmt.Errorf("user not found")
+	}
+	if user.TwoFactorEnabled {
+		return "", ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == "" {
+		return "", ErrTwoFactorNotEnrolled
+	}
+
+	secret, err := s.twoFactorSecret(user)
+	if err != nil {
+		return "", err
+	}
+	return totp.URI(s.config.Security.TwoFactorIssuer, user.Username, secret), nil
+}
+
+// ConfirmTwoFactor active la 2FA après un premier code valide et retourne les codes de secours
+func (s *AuthService) ConfirmTwoFactor(userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	if !s.verifyTOTP(user, code) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, err := s.replaceBackupCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorEnabled = true
+	if err = s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":  user.ID,
+		"username": user.Username,
+	}).Info("Two-factor authentication enabled")
+
+	return codes, nil
+}
+
+// DisableTwoFactor désactive la 2FA avec un code TOTP ou un code de secours
+// Refusé aux comptes staff quand la 2FA leur est exigée.
+func (s *AuthService) DisableTwoFactor(userID uuid.UUID, code string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if s.config.Security.TwoFactorRequired && user.IsStaff() {
+		return ErrTwoFactorRequired
+	}
+
+	if !s.verifyTwoFactorCode(user, code) {
+		return ErrInvalidTwoFactorCode
+	}
+
+	user.TwoFactorEnabled = false
+	user.TwoFactorSecret = ""
+	if err = s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+	if err = s.twoFactorRepo.Reset(user.ID); err != nil {
+		logrus.WithError(err).WithField("user_id", user.ID).Error("Failed to delete backup codes")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":  user.ID,
+		"username": user.Username,
+	}).Info("Two-factor authentication disabled")
+
+	return nil
+}
+
+// RegenerateBackupCodes remplace les codes de secours, sur présentation d'un code TOTP
+func (s *AuthService) RegenerateBackupCodes(userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if !user.TwoFactorEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	if !s.verifyTOTP(user, code) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	return s.replaceBackupCodes(user.ID)
+}
+
+// CountBackupCodes compte les codes de secours encore utilisables
+func (s *AuthService) CountBackupCodes(userID uuid.UUID) (int, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return 0, fmt.Errorf("user not found")
+	}
+	if !user.TwoFactorEnabled {
+		return 0, ErrTwoFactorNotEnabled
+	}
+
+	return s.twoFactorRepo.CountBackupCodes(user.ID)
+}
+
+// CompleteTwoFactorLogin seconde étape de la connection : le challenge retourné par Login
+// est échangé contre les tokens avec un code TOTP ou un code de secours
+func (s *AuthService) CompleteTwoFactorLogin(req models.TwoFactorLoginRequest, ipAddress, userAgent string) (*models.LoginResponse, error) {
+	claims, err := s.validateToken(req.ChallengeToken)
+	if err != nil || claims.TokenType != models.TokenTypeTwoFactorChallenge {
+		return nil, ErrInvalidChallenge
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil || !user.TwoFactorEnabled {
+		return nil, ErrInvalidChallenge
+	}
+
+	if user.IsAccountLocked() {
+		s.logLoginAttempt(&user.ID, user.Username, ipAddress, userAgent, false, "account_locked")
+		return nil, fmt.Errorf("account is temporarily locked")
+	}
+	if !user.CanLogin() {
+		s.logLoginAttempt(&user.ID, user.Username, ipAddress, userAgent, false, "account_disabled")
+		return nil, fmt.Errorf("account is disabled")
+	}
+
+	// Les essais de code comptent dans le verrouillage du compte, comme les mots de passe
+	if !s.verifyTwoFactorCode(user, req.Code) {
+		s.incrementFailedAttempts(user)
+		s.logLoginAttempt(&user.ID, user.Username, ipAddress, userAgent, false, "invalid_2fa")
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	return s.completeLogin(user, true, ipAddress, userAgent)
+}
+
+// twoFactorChallenge première étape d'une connection avec 2FA : le mot de passe est vérifié,
+// un token court (inutilisable comme token d'accès) attend le code
+func (s *AuthService) twoFactorChallenge(user *models.User) (*models.LoginResponse, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.config.Security.TwoFactorChallengeTTL)
+
+	claims := &models.JWTClaims{
+		UserID:    user.ID,
+		Username:  user.Username,
+		TokenType: models.TokenTypeTwoFactorChallenge,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    s.config.JWT.Issuer,
+			Subject:   user.ID.String(),
+		},
+	}
+
+	challenge, err := s.keys.Sign(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate two-factor challenge: %w", err)
+	}
+
+	return &models.LoginResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge,
+		ExpiresIn:         int(s.config.Security.TwoFactorChallengeTTL.Seconds()),
+		ExpiresAt:         expiresAt,
+	}, nil
+}
+
+// twoFactorSetupRequired indique un compte staff sans 2FA alors qu'elle est exigée
+func (s *AuthService) twoFactorSetupRequired(user *models.User) bool {
+	return s.config.Security.TwoFactorRequired && user.IsStaff() && !user.TwoFactorEnabled
+}
+
+// verifyTwoFactorCode vérifie un code TOTP, ou à défaut un code de secours (usage unique)
+func (s *AuthService) verifyTwoFactorCode(user *models.User, code string) bool {
+	if s.verifyTOTP(user, code) {
+		return true
+	}
+
+	normalized := normalizeBackupCode(code)
+	if len(normalized) != backupCodeLength {
+		return false
+	}
+
+	used, err := s.twoFactorRepo.UseBackupCode(user.ID, s.hashToken(normalized))
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", user.ID).Error("Failed to check backup code")
+		return false
+	}
+	if used {
+		logrus.WithField("user_id", user.ID).Info("Two-factor backup code used")
+	}
+	return used
+}
+
+// verifyTOTP vérifie un code TOTP ; un code n'est accepté qu'une fois, son pas de temps
+// doit être postérieur au dernier pas accepté pour ce compte
+func (s *AuthService) verifyTOTP(user *models.User, code string) bool {
+	secret, err := s.twoFactorSecret(user)
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", user.ID).Error("Failed to read two-factor secret")
+		return false
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return false
+	}
+
+	claimed, err := s.twoFactorRepo.ClaimStep(user.ID, step)
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", user.ID).Error("Failed to record two-factor step")
+		return false
+	}
+	if !claimed {
+		logrus.WithField("user_id", user.ID).Warn("Rejected reused two-factor code")
+	}
+	return claimed
+}
+
+// twoFactorSecret déchiffre le secret TOTP du compte
+func (s *AuthService) twoFactorSecret(user *models.User) (string, error) {
+	secret, err := s.keys.Open(user.TwoFactorSecret, user.ID.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt two-factor secret: %w", err)
+	}
+	return secret, nil
+}
+
+// replaceBackupCodes génère de nouveaux codes de secours, stockés hachés
+func (s *AuthService) replaceBackupCodes(userID uuid.UUID) ([]string, error) {
+	codes := make([]string, backupCodeCount)
+	hashes := make([]string, backupCodeCount)
+	for i := range codes {
+		code, err := generateBackupCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code[:backupCodeGroup] + "-" + code[backupCodeGroup:]
+		hashes[i] = s.hashToken(code)
+	}
+
+	if err := s.twoFactorRepo.ReplaceBackupCodes(userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to save backup codes: %w", err)
+	}
+	return codes, nil
+}
+
+// generateBackupCode tire un code de secours aléatoire
+func generateBackupCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(backupCodeAlphabet)))
+
+	var code strings.Builder
+	for range backupCodeLength {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate backup code: %w", err)
+		}
+		code.WriteByte(backupCodeAlphabet[n.Int64()])
+	}
+	return code.String(), nil
+}
+
+// normalizeBackupCode retire tirets et espaces d'un code saisi et le met en majuscules
+func normalizeBackupCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"auth/internal/models"
+	"auth/internal/repository"
+	"auth/internal/totp"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryTwoFactor dernier pas TOTP accepté par compte, avec la condition de ClaimStep en base
+type memoryTwoFactor struct {
+	repository.TwoFactorRepositoryInterface
+
+	mu        sync.Mutex
+	lastSteps map[uuid.UUID]int64
+}
+
+func (r *memoryTwoFactor) ClaimStep(userID uuid.UUID, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if last, exists := r.lastSteps[userID]; exists && last >= step {
+		return false, nil
+	}
+	r.lastSteps[userID] = step
+	return true, nil
+}
+
+// newTwoFactorTestService service avec un compte dont la 2FA est activée
+func newTwoFactorTestService(t *testing.T) (*AuthService, *models.User, string) {
+	t.Helper()
+
+	s := newTestAuthService(t)
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	user := &models.User{ID: uuid.New(), Username: "aldric", TwoFactorEnabled: true}
+	if user.TwoFactorSecret, err = s.keys.Seal(secret, user.ID.String()); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	return s, user, secret
+}
+
+// totpCode code TOTP d'un instant (RFC 6238), calculé indépendamment du package totp
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("invalid secret: %v", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(totp.Step(at)))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+// TestVerifyTOTPRejectsReuse un code accepté ne l'est plus dans le même pas de temps
+func TestVerifyTOTPRejectsReuse(t *testing.T) {
+	s, user, secret := newTwoFactorTestService(t)
+	code := totpCode(t, secret, time.Now())
+
+	if !s.verifyTOTP(user, code) {
+		t.Fatal("first use of a valid code rejected")
+	}
+	if s.verifyTOTP(user, code) {
+		t.Fatal("reused code accepted within the same time step")
+	}
+}
+
+// TestVerifyTOTPRejectsOlderStep après un code accepté, le code du pas précédent (encore dans
+// la tolérance d'horloge) est refusé
+func TestVerifyTOTPRejectsOlderStep(t *testing.T) {
+	s, user, secret := newTwoFactorTestService(t)
+	now := time.Now()
+
+	if !s.verifyTOTP(user, totpCode(t, secret, now)) {
+		t.Fatal("valid code rejected")
+	}
+	if s.verifyTOTP(user, totpCode(t, secret, now.Add(-totp.Period))) {
+		t.Fatal("code of an earlier time step accepted after a newer one")
+	}
+}
+
+// TestVerifyTOTPSecretBoundToAccount le secret chiffré d'un compte ne sert pas à un autre
+func TestVerifyTOTPSecretBoundToAccount(t *testing.T) {
+	s, user, secret := newTwoFactorTestService(t)
+	other := &models.User{ID: uuid.New(), TwoFactorEnabled: true, TwoFactorSecret: user.TwoFactorSecret}
+
+	if s.verifyTOTP(other, totpCode(t, secret, time.Now())) {
+		t.Fatal("code accepted with a secret sealed for another account")
+	}
+}
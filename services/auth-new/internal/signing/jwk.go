@@ -0,0 +1,59 @@
+package signing
+
+import (
+	"auth/internal/models"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// Types de clés JWK (RFC 7518, RFC 8037)
+const (
+	keyTypeRSA      = "RSA"
+	keyTypeOKP      = "OKP"
+	curveEd25519    = "Ed25519"
+	keyUseSignature = "sig"
+)
+
+// publicJWK représentation JWK d'une clé publique
+func publicJWK(public crypto.PublicKey) (models.JWK, error) {
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		return models.JWK{
+			KeyType: keyTypeRSA,
+			N:       base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return models.JWK{
+			KeyType: keyTypeOKP,
+			Curve:   curveEd25519,
+			X:       base64.RawURLEncoding.EncodeToString(public),
+		}, nil
+	default:
+		return models.JWK{}, fmt.Errorf("unsupported public key type %T", public)
+	}
+}
+
+// thumbprint identifiant d'une clé : empreinte SHA-256 de sa représentation JWK (RFC 7638)
+func thumbprint(public crypto.PublicKey) (string, error) {
+	jwk, err := publicJWK(public)
+	if err != nil {
+		return "", err
+	}
+
+	// Membres obligatoires dans l'ordre lexicographique, sans espaces
+	var canonical string
+	if jwk.KeyType == keyTypeRSA {
+		canonical = fmt.Sprintf(`{"e":%q,"kty":%q,"n":%q}`, jwk.E, jwk.KeyType, jwk.N)
+	} else {
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, jwk.Curve, jwk.KeyType, jwk.X)
+	}
+
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
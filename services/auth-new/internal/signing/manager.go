@@ -0,0 +1,431 @@
+// Package signing clés asymétriques de signature des tokens : génération, rotation et
+// publication des clés publiques (JWKS)
+package signing
+
+import (
+	"auth/internal/config"
+	"auth/internal/models"
+	"auth/internal/repository"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Constantes de la gestion des clés
+const (
+	// CheckInterval intervalle de rechargement des clés et de rotation
+	CheckInterval = time.Minute
+
+	rsaKeyBits = 2048
+)
+
+// Erreurs de la signature et de la vérification des tokens
+var (
+	ErrNoSigningKey = errors.New("no signing key available")
+	ErrUnknownKey   = errors.New("unknown signing key")
+)
+
+// key clé de signature déchiffrée
+type key struct {
+	id          string
+	method      jwt.SigningMethod
+	private     crypto.Signer
+	activatesAt time.Time
+	retiresAt   time.Time
+}
+
+// Manager signe les tokens avec la clé active et publie les clés publiques
+// Les clés sont partagées par les instances du service auth à travers la base : chacune
+// les recharge toutes les CheckInterval et la première qui constate l'échéance crée la
+// suivante. Une clé est publiée avant de signer son premier token et reste publiée
+// KeyOverlap après avoir signé le dernier.
+type Manager struct {
+	repo   repository.SigningKeyRepositoryInterface
+	config *config.JWTConfig
+	aead   cipher.AEAD
+
+	mu   sync.RWMutex
+	keys []*key // par date d'activation croissante
+	jwks []byte
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewManager charge les clés de signature, en crée une si aucune n'est active
+func NewManager(repo repository.SigningKeyRepositoryInterface, cfg *config.JWTConfig) (*Manager, error) {
+	// Clé AES-256 dérivée de la clé de chiffrement configurée
+	sum := sha256.Sum256([]byte(cfg.KeyEncryptionKey))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key cipher: %w", err)
+	}
+
+	m := &Manager{
+		repo:   repo,
+		config: cfg,
+		aead:   aead,
+		stop:   make(chan struct{}),
+	}
+	if err := m.Rotate(time.Now()); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Start démarre la rotation périodique des clés
+func (m *Manager) Start() {
+	go func() {
+		ticker := time.NewTicker(CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stop:
+				return
+			case now := <-ticker.C:
+				if err := m.Rotate(now); err != nil {
+					logrus.WithError(err).Error("Failed to rotate signing keys")
+				}
+			}
+		}
+	}()
+
+	logrus.WithFields(logrus.Fields{
+		"algorithm":         m.config.Algorithm,
+		"rotation_interval": m.config.KeyRotationInterval,
+		"overlap":           m.config.KeyOverlap,
+	}).Info("Signing key rotation started")
+}
+
+// Close arrête la rotation périodique
+func (m *Manager) Close() {
+	m.closeOnce.Do(func() { close(m.stop) })
+}
+
+// Rotate recharge les clés, crée la suivante à l'approche de l'échéance de la clé active
+// et supprime celles qui ne sont plus publiées
+func (m *Manager) Rotate(now time.Time) error {
+	keys, err := m.load(now)
+	if err != nil {
+		return err
+	}
+
+	if activatesAt, due := m.nextActivation(keys, now); due {
+		created, err := m.create(now, activatesAt)
+		if err != nil {
+			return err
+		}
+		if created {
+			if keys, err = m.load(now); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := m.repo.DeleteExpired(now); err != nil {
+		logrus.WithError(err).Warn("Failed to delete expired signing keys")
+	}
+
+	jwks, err := encodeJWKS(keys)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.keys = keys
+	m.jwks = jwks
+	m.mu.Unlock()
+
+	if signingKey(keys, now) == nil {
+		return ErrNoSigningKey
+	}
+	return nil
+}
+
+// Sign signe les claims avec la clé active ; son identifiant est placé dans l'en-tête kid
+func (m *Manager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	k := signingKey(m.keys, time.Now())
+	m.mu.RUnlock()
+	if k == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.id
+	signed, err := token.SignedString(k.private)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// Keyfunc retourne la clé publique désignée par l'en-tête kid d'un token (jwt.Keyfunc)
+func (m *Manager) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, k := range m.keys {
+		if k.id != kid {
+			continue
+		}
+		if k.method.Alg() != token.Method.Alg() {
+			return nil, fmt.Errorf("%w: algorithm mismatch for key %s", ErrUnknownKey, kid)
+		}
+		return k.private.Public(), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+// JWKS document JSON des clés publiées
+func (m *Manager) JWKS() []byte {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.jwks
+}
+
+// Seal chiffre une donnée sensible stockée en base (secret 2FA) avec la clé de chiffrement
+// des clés privées ; associated lie le chiffré à son propriétaire
+func (m *Manager) Seal(plaintext, associated string) (string, error) {
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(m.aead.Seal(nonce, nonce, []byte(plaintext), []byte(associated))), nil
+}
+
+// Open déchiffre une donnée chiffrée par Seal
+func (m *Manager) Open(sealed, associated string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode sealed value: %w", err)
+	}
+
+	nonceSize := m.aead.NonceSize()
+	if len(data) < nonceSize {
+		return "", errors.New("sealed value too short")
+	}
+	plaintext, err := m.aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(associated))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt sealed value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// Algorithms algorithmes de signature acceptés à la vérification
+func Algorithms() []string {
+	return []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+}
+
+// nextActivation date d'activation de la clé à créer, si une création est due
+// La clé suivante est créée prepublish avant l'échéance de la clé active pour que les
+// caches JWKS des autres services la connaissent avant son premier token.
+func (m *Manager) nextActivation(keys []*key, now time.Time) (time.Time, bool) {
+	current := signingKey(keys, now)
+	if current == nil {
+		return now, true
+	}
+	if keys[len(keys)-1] != current || now.Before(current.retiresAt.Add(-m.prepublish())) {
+		return time.Time{}, false // successeur déjà créé, ou échéance lointaine
+	}
+	if current.retiresAt.Before(now) {
+		return now, true
+	}
+	return current.retiresAt, true
+}
+
+// prepublish délai de publication d'une clé avant sa première signature : cache JWKS des
+// services (max-age, deux fois par prudence) et rechargement des autres instances
+func (m *Manager) prepublish() time.Duration {
+	return 2*m.config.JWKSMaxAge + CheckInterval
+}
+
+// create génère et enregistre une clé activée à activatesAt
+// Retourne false si une autre instance l'a créée entre-temps.
+func (m *Manager) create(now, activatesAt time.Time) (bool, error) {
+	private, method, err := generate(m.config.Algorithm)
+	if err != nil {
+		return false, err
+	}
+	kid, err := thumbprint(private.Public())
+	if err != nil {
+		return false, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode signing key: %w", err)
+	}
+
+	// Le kid est lié au chiffré comme donnée authentifiée
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return false, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	retiresAt := activatesAt.Add(m.config.KeyRotationInterval)
+	record := &models.SigningKey{
+		ID:          kid,
+		Algorithm:   method.Alg(),
+		PrivateKey:  m.aead.Seal(nonce, nonce, der, []byte(kid)),
+		CreatedAt:   now,
+		ActivatesAt: activatesAt,
+		RetiresAt:   retiresAt,
+		ExpiresAt:   retiresAt.Add(m.config.KeyOverlap),
+	}
+	created, err := m.repo.CreateIfAbsent(record)
+	if err != nil {
+		return false, err
+	}
+
+	if created {
+		logrus.WithFields(logrus.Fields{
+			"kid":          kid,
+			"algorithm":    record.Algorithm,
+			"activates_at": activatesAt,
+			"retires_at":   retiresAt,
+		}).Info("Signing key created")
+	}
+	return created, nil
+}
+
+// load lit les clés publiées ; les clés déjà connues ne sont pas déchiffrées à nouveau
+func (m *Manager) load(now time.Time) ([]*key, error) {
+	records, err := m.repo.ListUnexpired(now)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	known := make(map[string]*key, len(m.keys))
+	for _, k := range m.keys {
+		known[k.id] = k
+	}
+	m.mu.RUnlock()
+
+	keys := make([]*key, 0, len(records))
+	for _, record := range records {
+		if k, ok := known[record.ID]; ok {
+			keys = append(keys, k)
+			continue
+		}
+
+		k, err := m.decrypt(record)
+		if err != nil {
+			// Clé illisible (clé de chiffrement changée) : ignorée, une nouvelle sera créée
+			logrus.WithError(err).WithField("kid", record.ID).Error("Failed to load signing key")
+			continue
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// decrypt déchiffre la clé privée d'un enregistrement
+func (m *Manager) decrypt(record *models.SigningKey) (*key, error) {
+	nonceSize := m.aead.NonceSize()
+	if len(record.PrivateKey) < nonceSize {
+		return nil, errors.New("encrypted signing key too short")
+	}
+	der, err := m.aead.Open(nil, record.PrivateKey[:nonceSize], record.PrivateKey[nonceSize:], []byte(record.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt signing key: %w", err)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported signing key type")
+	}
+
+	method := jwt.GetSigningMethod(record.Algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", record.Algorithm)
+	}
+
+	return &key{
+		id:          record.ID,
+		method:      method,
+		private:     private,
+		activatesAt: record.ActivatesAt,
+		retiresAt:   record.RetiresAt,
+	}, nil
+}
+
+// signingKey clé active : la dernière activée
+// Si la rotation a échoué, la clé échue continue de signer jusqu'à la création de la suivante.
+// Une clé pas encore activée n'est utilisée que s'il n'y en a pas d'autre (horloges des
+// instances décalées lors de la création de la première clé).
+func signingKey(keys []*key, now time.Time) *key {
+	if len(keys) == 0 {
+		return nil
+	}
+	current := keys[0]
+	for _, k := range keys[1:] {
+		if !k.activatesAt.After(now) {
+			current = k
+		}
+	}
+	return current
+}
+
+// generate génère une clé privée pour l'algorithme configuré
+func generate(algorithm string) (crypto.Signer, jwt.SigningMethod, error) {
+	if algorithm == config.AlgorithmRS256 {
+		private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		}
+		return private, jwt.SigningMethodRS256, nil
+	}
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate Ed25519 key: %w", err)
+	}
+	return private, jwt.SigningMethodEdDSA, nil
+}
+
+// encodeJWKS encode les clés publiques au format JWKS
+func encodeJWKS(keys []*key) ([]byte, error) {
+	set := models.JWKSet{Keys: make([]models.JWK, 0, len(keys))}
+	for _, k := range keys {
+		jwk, err := publicJWK(k.private.Public())
+		if err != nil {
+			return nil, err
+		}
+		jwk.KeyID = k.id
+		jwk.Use = keyUseSignature
+		jwk.Algorithm = k.method.Alg()
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JWKS: %w", err)
+	}
+	return data, nil
+}
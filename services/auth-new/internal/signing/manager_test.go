@@ -0,0 +1,295 @@
+package signing
+
+import (
+	"auth/internal/config"
+	"auth/internal/models"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"mmorpg/pkg/jwks"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// memorySigningKeys clés de signature en mémoire, avec les conditions des requêtes en base
+type memorySigningKeys struct {
+	mu   sync.Mutex
+	keys []*models.SigningKey
+}
+
+func (r *memorySigningKeys) ListUnexpired(now time.Time) ([]*models.SigningKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var keys []*models.SigningKey
+	for _, k := range r.keys {
+		if k.ExpiresAt.After(now) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ActivatesAt.Before(keys[j].ActivatesAt) })
+	return keys, nil
+}
+
+func (r *memorySigningKeys) CreateIfAbsent(key *models.SigningKey) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, k := range r.keys {
+		if k.RetiresAt.After(key.ActivatesAt) {
+			return false, nil
+		}
+	}
+	r.keys = append(r.keys, key)
+	return true, nil
+}
+
+func (r *memorySigningKeys) DeleteExpired(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.keys[:0]
+	for _, k := range r.keys {
+		if k.ExpiresAt.After(now) {
+			kept = append(kept, k)
+		}
+	}
+	r.keys = kept
+	return nil
+}
+
+// count nombre de clés enregistrées
+func (r *memorySigningKeys) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.keys)
+}
+
+// newTestConfig configuration de rotation : une clé par jour, publiée une heure après
+func newTestConfig(algorithm string) *config.JWTConfig {
+	return &config.JWTConfig{
+		Algorithm:           algorithm,
+		KeyEncryptionKey:    "test-key-encryption-key",
+		KeyRotationInterval: 24 * time.Hour,
+		KeyOverlap:          time.Hour,
+		JWKSMaxAge:          5 * time.Minute,
+	}
+}
+
+func newTestManager(t *testing.T, repo *memorySigningKeys, algorithm string) *Manager {
+	t.Helper()
+
+	m, err := NewManager(repo, newTestConfig(algorithm))
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	return m
+}
+
+// jwksKeyIDs identifiants des clés publiées
+func jwksKeyIDs(t *testing.T, m *Manager) []string {
+	t.Helper()
+
+	var set models.JWKSet
+	if err := json.Unmarshal(m.JWKS(), &set); err != nil {
+		t.Fatalf("invalid JWKS: %v", err)
+	}
+	ids := make([]string, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		ids = append(ids, k.KeyID)
+	}
+	return ids
+}
+
+func TestSignAndVerify(t *testing.T) {
+	for _, algorithm := range []string{config.AlgorithmEdDSA, config.AlgorithmRS256} {
+		m := newTestManager(t, &memorySigningKeys{}, algorithm)
+
+		signed, err := m.Sign(jwt.MapClaims{"sub": "aldric"})
+		if err != nil {
+			t.Fatalf("%s: Sign: %v", algorithm, err)
+		}
+		token, err := jwt.Parse(signed, m.Keyfunc, jwt.WithValidMethods(Algorithms()))
+		if err != nil || !token.Valid {
+			t.Fatalf("%s: Parse: %v", algorithm, err)
+		}
+		if token.Method.Alg() != algorithm {
+			t.Errorf("alg = %s, want %s", token.Method.Alg(), algorithm)
+		}
+		if kid := token.Header["kid"]; kid != jwksKeyIDs(t, m)[0] {
+			t.Errorf("%s: kid = %v, not the published key", algorithm, kid)
+		}
+	}
+}
+
+// TestVerifyWithJWKS les autres services vérifient les tokens avec le JWKS publié seul
+func TestVerifyWithJWKS(t *testing.T) {
+	m := newTestManager(t, &memorySigningKeys{}, config.AlgorithmRS256)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(m.JWKS())
+	}))
+	defer server.Close()
+
+	signed, err := m.Sign(jwt.MapClaims{"sub": "aldric"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	keys := jwks.NewKeySet(server.URL, time.Hour)
+	if _, err := jwt.Parse(signed, keys.Keyfunc, jwt.WithValidMethods(jwks.Algorithms())); err != nil {
+		t.Fatalf("token rejected with the published JWKS: %v", err)
+	}
+}
+
+func TestKeyfuncRejects(t *testing.T) {
+	m := newTestManager(t, &memorySigningKeys{}, config.AlgorithmEdDSA)
+	kid := jwksKeyIDs(t, m)[0]
+
+	unknown := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{})
+	unknown.Header["kid"] = "forged"
+	if _, err := m.Keyfunc(unknown); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("unknown kid error = %v, want ErrUnknownKey", err)
+	}
+
+	// Un token HS256 portant le kid d'une clé publique ne doit pas être vérifié avec elle
+	mismatch := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{})
+	mismatch.Header["kid"] = kid
+	if _, err := m.Keyfunc(mismatch); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("algorithm mismatch error = %v, want ErrUnknownKey", err)
+	}
+}
+
+// TestRotation la clé suivante est publiée avant l'échéance et la précédente reste publiée
+// pendant KeyOverlap
+func TestRotation(t *testing.T) {
+	repo := &memorySigningKeys{}
+	m := newTestManager(t, repo, config.AlgorithmEdDSA)
+	cfg := newTestConfig(config.AlgorithmEdDSA)
+	start := repo.keys[0].ActivatesAt
+	first := repo.keys[0].ID
+
+	// Échéance lointaine : pas de nouvelle clé
+	if err := m.Rotate(start.Add(12 * time.Hour)); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if repo.count() != 1 {
+		t.Fatalf("keys = %d, want 1", repo.count())
+	}
+
+	// Dans le délai de prépublication : la suivante est créée, activée à l'échéance
+	retiresAt := start.Add(cfg.KeyRotationInterval)
+	if err := m.Rotate(retiresAt.Add(-m.prepublish())); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if repo.count() != 2 || !repo.keys[1].ActivatesAt.Equal(retiresAt) {
+		t.Fatalf("next key not prepublished at the retirement date: %+v", repo.keys)
+	}
+	if ids := jwksKeyIDs(t, m); len(ids) != 2 {
+		t.Errorf("published keys = %v, want both", ids)
+	}
+	if current := signingKey(m.keys, retiresAt.Add(-time.Second)); current.id != first {
+		t.Error("next key signs before its activation")
+	}
+
+	// Rotation répétée par une autre instance : pas de troisième clé
+	other := newTestManager(t, repo, config.AlgorithmEdDSA)
+	if err := other.Rotate(retiresAt.Add(-time.Minute)); err != nil || repo.count() != 2 {
+		t.Fatalf("second instance created a key: count=%d err=%v", repo.count(), err)
+	}
+
+	if err := m.Rotate(retiresAt.Add(time.Minute)); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if current := signingKey(m.keys, retiresAt.Add(time.Minute)); current.id == first {
+		t.Error("retired key still signs")
+	}
+
+	// Après le recouvrement, l'ancienne clé n'est plus publiée
+	if err := m.Rotate(retiresAt.Add(cfg.KeyOverlap)); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if ids := jwksKeyIDs(t, m); len(ids) != 1 || ids[0] == first {
+		t.Errorf("published keys after overlap = %v", ids)
+	}
+}
+
+// TestPrivateKeysEncrypted les clés privées ne sont lisibles qu'avec la clé de chiffrement
+func TestPrivateKeysEncrypted(t *testing.T) {
+	repo := &memorySigningKeys{}
+	m := newTestManager(t, repo, config.AlgorithmEdDSA)
+
+	record := *repo.keys[0]
+	if _, err := m.decrypt(&record); err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+
+	// Le chiffré est lié au kid : il ne peut pas être recopié sous un autre identifiant
+	record.ID = "other"
+	if _, err := m.decrypt(&record); err == nil {
+		t.Error("key decrypted under another kid")
+	}
+
+	cfg := newTestConfig(config.AlgorithmEdDSA)
+	cfg.KeyEncryptionKey = "another-key"
+	other, err := NewManager(&memorySigningKeys{}, cfg)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if _, err := other.decrypt(repo.keys[0]); err == nil {
+		t.Error("key decrypted with another encryption key")
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	m := newTestManager(t, &memorySigningKeys{}, config.AlgorithmEdDSA)
+
+	sealed, err := m.Seal("JBSWY3DPEHPK3PXP", "user-1")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if opened, err := m.Open(sealed, "user-1"); err != nil || opened != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("Open = %q, %v", opened, err)
+	}
+	if _, err := m.Open(sealed, "user-2"); err == nil {
+		t.Error("secret opened for another user")
+	}
+	if _, err := m.Open("c2hvcnQ=", "user-1"); err == nil {
+		t.Error("short value opened")
+	}
+}
+
+// TestThumbprint exemples des RFC 7638 (RSA) et RFC 8037 (Ed25519)
+func TestThumbprint(t *testing.T) {
+	n, err := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	if err != nil {
+		t.Fatalf("invalid modulus: %v", err)
+	}
+	x, err := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	if err != nil {
+		t.Fatalf("invalid public key: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		public crypto.PublicKey
+		want   string
+	}{
+		{"RSA", &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"},
+		{"Ed25519", ed25519.PublicKey(x), "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"},
+	}
+	for _, tt := range tests {
+		if got, err := thumbprint(tt.public); err != nil || got != tt.want {
+			t.Errorf("%s: thumbprint = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
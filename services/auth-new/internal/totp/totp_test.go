@@ -0,0 +1,109 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret secret SHA-1 des vecteurs de test de RFC 6238 (annexe B), en base32
+var rfc6238Secret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+// rfc6238Vectors vecteurs SHA-1 de RFC 6238 (annexe B) ; les codes y ont 8 chiffres, les 6
+// derniers sont ceux d'un code à Digits chiffres
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "94287082"},
+	{1111111109, "07081804"},
+	{1111111111, "14050471"},
+	{1234567890, "89005924"},
+	{2000000000, "69279037"},
+	{20000000000, "65353130"},
+}
+
+func TestGenerateRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	for _, v := range rfc6238Vectors {
+		want := v.code[len(v.code)-Digits:]
+		if got := generate(key, Step(time.Unix(v.unix, 0))); got != want {
+			t.Errorf("T=%d: generate = %s, want %s", v.unix, got, want)
+		}
+	}
+}
+
+func TestValidateRFC6238Vectors(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		now := time.Unix(v.unix, 0)
+		code := v.code[len(v.code)-Digits:]
+
+		step, ok := Validate(rfc6238Secret, code, now)
+		if !ok {
+			t.Errorf("T=%d: code %s rejected", v.unix, code)
+			continue
+		}
+		if step != Step(now) {
+			t.Errorf("T=%d: step = %d, want %d", v.unix, step, Step(now))
+		}
+
+		// Secret saisi en minuscules et code entouré d'espaces
+		if _, ok = Validate(strings.ToLower(rfc6238Secret), " "+code+" ", now); !ok {
+			t.Errorf("T=%d: code %s rejected with a lowercase secret", v.unix, code)
+		}
+	}
+}
+
+// TestValidateSkew un code reste accepté un pas avant et après le sien, pas au-delà
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code := "005924"
+
+	for _, tc := range []struct {
+		offset time.Duration
+		ok     bool
+	}{
+		{-Period, true},
+		{Period, true},
+		{-2 * Period, false},
+		{2 * Period, false},
+	} {
+		step, ok := Validate(rfc6238Secret, code, now.Add(tc.offset))
+		if ok != tc.ok {
+			t.Errorf("offset %s: ok = %v, want %v", tc.offset, ok, tc.ok)
+		}
+		if ok && step != Step(now) {
+			t.Errorf("offset %s: step = %d, want %d", tc.offset, step, Step(now))
+		}
+	}
+}
+
+func TestValidateRejectsMalformed(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, tc := range []struct {
+		secret, code string
+	}{
+		{rfc6238Secret, "94287082"}, // 8 chiffres
+		{rfc6238Secret, "28708"},
+		{rfc6238Secret, "287083"},
+		{"not base32!", "287082"},
+	} {
+		if _, ok := Validate(tc.secret, tc.code, now); ok {
+			t.Errorf("Validate(%q, %q) accepted", tc.secret, tc.code)
+		}
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not base32: %v", secret, err)
+	}
+	if len(key) != secretSize {
+		t.Errorf("secret size = %d bytes, want %d", len(key), secretSize)
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"chat/internal/service"
 	"context"
 	"fmt"
+	"mmorpg/pkg/jwks"
+	"mmorpg/pkg/serviceauth"
+	"mmorpg/pkg/tracing"
 	"net/http"
 	"os"
 	"os/signal"
@@ -46,6 +49,12 @@ func main() {
 		logrus.Fatal("Failed to load config: ", err)
 	}
 
+	// Tracing distribué (contexte reçu du gateway, export OTLP ou local)
+	shutdownTracing, err := tracing.Init(&cfg.Tracing, "chat", Version, cfg.Server.Environment)
+	if err != nil {
+		logrus.Fatal("Failed to initialize tracing: ", err)
+	}
+
 	// connection à la base de données
 	db, err := database.NewConnection(&cfg.Database)
 	if err != nil {
@@ -75,8 +84,13 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Clés publiques du service auth, pour vérifier les tokens de service
+	keys := jwks.NewKeySet(cfg.JWT.JWKSURL, cfg.JWT.JWKSRefresh)
+	keys.Start()
+	defer keys.Close()
+
 	// Configuration des routes
-	router := setupRoutes(chatHandler, cfg)
+	router := setupRoutes(chatHandler, keys, cfg)
 
 	// Configuration du serveur HTTP
 	server := &http.Server{
@@ -100,16 +114,17 @@ func main() {
 	}()
 
 	// Gestion gracieuse de l'arrêt
-	gracefulShutdown(server)
+	gracefulShutdown(server, shutdownTracing)
 }
 
 // setupRoutes configure toutes les routes du service chat
-func setupRoutes(chatHandler *handlers.ChatHandler, cfg *config.Config) *gin.Engine {
+func setupRoutes(chatHandler *handlers.ChatHandler, keys *jwks.KeySet, cfg *config.Config) *gin.Engine {
 	router := gin.New()
 
 	// Middlewares globaux
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(tracing.Middleware())
 
 	// CORS pour le développement
 	router.Use(func(c *gin.Context) {
@@ -153,6 +168,15 @@ func setupRoutes(chatHandler *handlers.ChatHandler, cfg *config.Config) *gin.Eng
 			// Routes des membres
 			channels.GET("/:id/members", chatHandler.GetChannelMembers)
 		}
+
+		// Routes pour les autres services (appels internes)
+		services := v1.Group("/services")
+		services.Use(serviceauth.Middleware(serviceauth.NewVerifier(keys, cfg.JWT.ServiceTokenIssuer)))
+		{
+			read := serviceauth.RequireScope(serviceauth.ScopeChatRead)
+			services.GET("/channels/:id/members/:userId", read, chatHandler.CheckChannelMember)
+			services.GET("/parties/:id/members/:userId", read, chatHandler.CheckPartyMember)
+		}
 	}
 
 	// TODO: Routes WebSocket
@@ -182,7 +206,7 @@ func initLogger() {
 }
 
 // gracefulShutdown gère l'arrêt gracieux du serveur
-func gracefulShutdown(server *http.Server) {
+func gracefulShutdown(server *http.Server, shutdownTracing func(context.Context) error) {
 	// Canal pour capturer les signaux système
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -202,5 +226,10 @@ func gracefulShutdown(server *http.Server) {
 		return
 	}
 
+	// Exporter les derniers spans
+	if err := shutdownTracing(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to flush traces")
+	}
+
 	logrus.Info("💬 Chat Service stopped gracefully")
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"mmorpg/pkg/tracing"
 	"os"
 	"strconv"
 	"time"
@@ -35,8 +36,12 @@ const (
 	DefaultDBTimeout      = 5
 	DefaultLogContentLen  = 50
 	DefaultShutdownTO     = 30
+	DefaultJWKSRefresh    = 5
 )
 
+// Tracing distribué
+const DefaultTracingSampleRatio = 1.0
+
 // Config représente la configuration complète du service Chat
 type Config struct {
 	Server     ServerConfig     `mapstructure:"server"`
@@ -46,6 +51,7 @@ type Config struct {
 	WebSocket  WebSocketConfig  `mapstructure:"websocket"`
 	RateLimit  RateLimitConfig  `mapstructure:"rate_limit"`
 	Monitoring MonitoringConfig `mapstructure:"monitoring"`
+	Tracing    TracingConfig    `mapstructure:"tracing"`
 }
 
 // ServerConfig configuration du serveur HTTP
@@ -74,6 +80,10 @@ type DatabaseConfig struct {
 // JWTConfig configuration JWT
 type JWTConfig struct {
 	Secret string `mapstructure:"secret"`
+	// Clés publiques du service auth, pour vérifier les tokens de service des routes /services
+	JWKSURL            string        `mapstructure:"jwks_url"`
+	JWKSRefresh        time.Duration `mapstructure:"jwks_refresh"`
+	ServiceTokenIssuer string        `mapstructure:"service_token_issuer"`
 }
 
 // ChatConfig configuration spécifique au chat
@@ -112,6 +122,9 @@ type MonitoringConfig struct {
 	HealthPath     string `mapstructure:"health_path"`
 }
 
+// TracingConfig configuration du tracing distribué, commune à tous les services
+type TracingConfig = tracing.Config
+
 // GetDatabaseURL construit l'URL de connection PostgreSQL
 func (d *DatabaseConfig) GetDatabaseURL() string {
 	return fmt.Sprintf(
@@ -144,7 +157,10 @@ func LoadConfig() (*Config, error) {
 			MaxLifetime:  DefaultDBMaxLifetime * time.Minute,
 		},
 		JWT: JWTConfig{
-			Secret: "your-super-secret-jwt-key-change-in-production-minimum-64-characters",
+			Secret:             "your-super-secret-jwt-key-change-in-production-minimum-64-characters",
+			JWKSURL:            "http://localhost:8081/.well-known/jwks.json",
+			JWKSRefresh:        DefaultJWKSRefresh * time.Minute,
+			ServiceTokenIssuer: "mmo-auth-service",
 		},
 		Chat: ChatConfig{
 			MaxMessageLength:       DefaultMaxMessageLen,
@@ -174,6 +190,14 @@ func LoadConfig() (*Config, error) {
 			MetricsPath:    "/metrics",
 			HealthPath:     "/health",
 		},
+		Tracing: TracingConfig{
+			Enabled:     false,
+			Exporter:    "otlp",
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			File:        "traces.json",
+			SampleRatio: DefaultTracingSampleRatio,
+		},
 	}
 
 	// Configurer Viper
@@ -247,6 +271,43 @@ func loadFromEnv(config *Config) {
 	if jwtSecret := os.Getenv("CHAT_JWT_SECRET"); jwtSecret != "" {
 		config.JWT.Secret = jwtSecret
 	}
+	if jwksURL := os.Getenv("AUTH_JWKS_URL"); jwksURL != "" {
+		config.JWT.JWKSURL = jwksURL
+	}
+	if refresh := os.Getenv("AUTH_JWKS_REFRESH"); refresh != "" {
+		if d, err := time.ParseDuration(refresh); err == nil {
+			config.JWT.JWKSRefresh = d
+		}
+	}
+	if issuer := os.Getenv("SERVICE_TOKEN_ISSUER"); issuer != "" {
+		config.JWT.ServiceTokenIssuer = issuer
+	}
+
+	// Tracing
+	loadTracingEnv(config)
+}
+
+// loadTracingEnv charge la configuration du tracing (variables communes aux services)
+func loadTracingEnv(config *Config) {
+	if enabled := os.Getenv("TRACING_ENABLED"); enabled != "" {
+		if b, err := strconv.ParseBool(enabled); err == nil {
+			config.Tracing.Enabled = b
+		}
+	}
+	if exporter := os.Getenv("TRACING_EXPORTER"); exporter != "" {
+		config.Tracing.Exporter = exporter
+	}
+	if endpoint := os.Getenv("TRACING_ENDPOINT"); endpoint != "" {
+		config.Tracing.Endpoint = endpoint
+	}
+	if file := os.Getenv("TRACING_FILE"); file != "" {
+		config.Tracing.File = file
+	}
+	if ratio := os.Getenv("TRACING_SAMPLE_RATIO"); ratio != "" {
+		if r, err := strconv.ParseFloat(ratio, 64); err == nil {
+			config.Tracing.SampleRatio = r
+		}
+	}
 }
 
 // validateConfig valide la configuration
@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"mmorpg/pkg/tracing"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -24,7 +25,8 @@ type DB struct {
 func NewConnection(cfg *config.DatabaseConfig) (*DB, error) {
 	logrus.Info("Connecting to PostgreSQL database...")
 
-	db, err := sql.Open("postgres", cfg.GetDatabaseURL())
+	// Requêtes SQL tracées dans le span de la requête HTTP
+	db, err := tracing.OpenDB("postgres", cfg.GetDatabaseURL())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"chat/internal/models"
 	"chat/internal/service"
+	"context"
 	"net/http"
 	"strconv"
 
@@ -289,3 +290,45 @@ func (h *ChatHandler) GetChannelMembers(c *gin.Context) {
 		"offset":  offset,
 	})
 }
+
+// CheckChannelMember indique si un utilisateur est membre d'un channel (appel interne)
+// GET /api/v1/services/channels/:id/members/:userId
+func (h *ChatHandler) CheckChannelMember(c *gin.Context) {
+	h.checkMembership(c, h.chatService.IsChannelMember)
+}
+
+// CheckPartyMember indique si un utilisateur est membre d'une party (appel interne)
+// GET /api/v1/services/parties/:id/members/:userId
+func (h *ChatHandler) CheckPartyMember(c *gin.Context) {
+	h.checkMembership(c, h.chatService.IsPartyMember)
+}
+
+// checkMembership répond 200 si l'utilisateur est membre du groupe, 404 sinon
+func (h *ChatHandler) checkMembership(
+	c *gin.Context,
+	isMember func(ctx context.Context, groupID, userID uuid.UUID) (bool, error),
+) {
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	member, err := isMember(c.Request.Context(), groupID, userID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to check membership")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check membership"})
+		return
+	}
+	if !member {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not a member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"member": true})
+}
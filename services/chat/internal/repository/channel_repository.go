@@ -450,6 +450,25 @@ func (r *channelRepository) IsMember(ctx context.Context, channelID, userID uuid
 	return exists, nil
 }
 
+// IsPartyMember vérifie si un utilisateur est membre du channel d'une party
+func (r *channelRepository) IsPartyMember(ctx context.Context, partyID, userID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM channels c
+			INNER JOIN channel_members cm ON c.id = cm.channel_id
+			WHERE c.type = $1 AND c.party_id = $2 AND c.is_active = true
+			AND cm.user_id = $3 AND cm.is_active = true
+		)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, models.ChannelTypeParty, partyID, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check party membership: %w", err)
+	}
+
+	return exists, nil
+}
+
 // GetMemberCount retourne le nombre de membres d'un channel
 func (r *channelRepository) GetMemberCount(ctx context.Context, channelID uuid.UUID) (int, error) {
 	query := `SELECT COUNT(*) FROM channel_members WHERE channel_id = $1 AND is_active = true`
@@ -30,6 +30,7 @@ type ChannelRepository interface {
 	GetMember(ctx context.Context, channelID, userID uuid.UUID) (*models.ChannelMember, error)
 	UpdateMember(ctx context.Context, member *models.ChannelMember) error
 	IsMember(ctx context.Context, channelID, userID uuid.UUID) (bool, error)
+	IsPartyMember(ctx context.Context, partyID, userID uuid.UUID) (bool, error)
 	GetMemberCount(ctx context.Context, channelID uuid.UUID) (int, error)
 
 	// Statistiques
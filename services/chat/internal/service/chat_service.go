@@ -359,6 +359,16 @@ func (s *chatService) KickUser(ctx context.Context, channelID, kickerID, targetI
 	return fmt.Errorf("not implemented")
 }
 
+// IsChannelMember indique si un utilisateur est membre actif d'un channel (appel interne)
+func (s *chatService) IsChannelMember(ctx context.Context, channelID, userID uuid.UUID) (bool, error) {
+	return s.channelRepo.IsMember(ctx, channelID, userID)
+}
+
+// IsPartyMember indique si un utilisateur est membre du channel d'une party (appel interne)
+func (s *chatService) IsPartyMember(ctx context.Context, partyID, userID uuid.UUID) (bool, error) {
+	return s.channelRepo.IsPartyMember(ctx, partyID, userID)
+}
+
 func (s *chatService) GetChannelMembers(
 	ctx context.Context,
 	channelID uuid.UUID,
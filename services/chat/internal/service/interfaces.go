@@ -25,6 +25,8 @@ type ChatService interface {
 	KickUser(ctx context.Context, channelID, kickerID, targetID uuid.UUID, reason string) error
 	GetChannelMembers(ctx context.Context, channelID uuid.UUID, limit, offset int, requesterID uuid.UUID) ([]*models.ChannelMember, error)
 	UpdateMember(ctx context.Context, channelID, userID uuid.UUID, req *models.UpdateMemberRequest, requesterID uuid.UUID) error
+	IsChannelMember(ctx context.Context, channelID, userID uuid.UUID) (bool, error)
+	IsPartyMember(ctx context.Context, partyID, userID uuid.UUID) (bool, error)
 
 	// Gestion des messages
 	SendMessage(ctx context.Context, channelID, userID uuid.UUID, req *models.SendMessageRequest) (*models.Message, error)
@@ -0,0 +1,196 @@
+// Commande de benchmark des encodages du chemin critique : taille sur le réseau et coût CPU
+// des actions de combat en JSON, MessagePack et Protobuf
+//
+// Usage (depuis services/combat) :
+//
+//	go run ./cmd/codecbench
+package main
+
+import (
+	"combat/internal/encoding"
+	"combat/internal/models"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Valeurs d'exemple : une compétence offensive lancée au tour 7
+const (
+	sampleX          = 1523.75
+	sampleY          = -842.5
+	sampleZ          = 64.125
+	sampleDamage     = 187
+	sampleManaUsed   = 25
+	sampleTurn       = 7
+	sampleOrder      = 2
+	sampleProcessing = 3
+	percent          = 100
+)
+
+// codec encodage mesuré d'un message
+type codec struct {
+	format encoding.Format
+	encode func() ([]byte, error)
+	decode func(data []byte) error // nil : message seulement encodé par le service
+}
+
+// result mesure d'un encodage
+type result struct {
+	size     int
+	encodeNs int64
+	decodeNs int64
+	allocs   int64
+}
+
+func main() {
+	now := time.Now().UTC()
+	targetID := uuid.New()
+	skillID := "fireball"
+	request := &models.ActionRequest{
+		ActionType:      models.ActionTypeSkill,
+		TargetID:        &targetID,
+		SkillID:         &skillID,
+		ClientTimestamp: now,
+		Position:        &models.Position{X: sampleX, Y: sampleY, Z: sampleZ},
+	}
+
+	processingTime := sampleProcessing
+	actionResult := &models.ActionResult{
+		Success: true,
+		Action: &models.CombatAction{
+			ID:               uuid.New(),
+			CombatID:         uuid.New(),
+			ActorID:          uuid.New(),
+			TargetID:         &targetID,
+			ActionType:       models.ActionTypeSkill,
+			SkillID:          &skillID,
+			DamageDealt:      sampleDamage,
+			ManaUsed:         sampleManaUsed,
+			IsCritical:       true,
+			TurnNumber:       sampleTurn,
+			ActionOrder:      sampleOrder,
+			ProcessingTimeMs: &processingTime,
+			ClientTimestamp:  &now,
+			ServerTimestamp:  now,
+			IsValidated:      true,
+			CreatedAt:        now,
+		},
+		StateChanges: &models.StateChanges{
+			ParticipantChanges: map[uuid.UUID]*models.ParticipantChange{
+				targetID: {HealthChange: -sampleDamage},
+			},
+			CombatChange: &models.CombatChange{TurnAdvanced: true},
+		},
+		Message: "Fireball hits for 187 damage",
+	}
+	// Réponse telle qu'écrite par le handler (le protobuf ne porte que le résultat)
+	response := gin.H{"success": actionResult.Success, "result": actionResult}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "message\tformat\tbytes\tvs json\tencode ns/op\tdecode ns/op\tcpu vs json\tallocs/op\t")
+
+	report(writer, "ActionRequest", []codec{
+		{
+			format: encoding.FormatJSON,
+			encode: func() ([]byte, error) { return json.Marshal(request) },
+			decode: func(data []byte) error { return json.Unmarshal(data, &models.ActionRequest{}) },
+		},
+		{
+			format: encoding.FormatMsgPack,
+			encode: func() ([]byte, error) { return encoding.MarshalMsgPack(request) },
+			decode: func(data []byte) error { return encoding.UnmarshalMsgPack(data, &models.ActionRequest{}) },
+		},
+		{
+			format: encoding.FormatProtobuf,
+			encode: func() ([]byte, error) { return request.MarshalProto(), nil },
+			decode: func(data []byte) error { return (&models.ActionRequest{}).UnmarshalProto(data) },
+		},
+	})
+	report(writer, "ActionResult (réponse)", []codec{
+		{format: encoding.FormatJSON, encode: func() ([]byte, error) { return json.Marshal(response) }},
+		{format: encoding.FormatMsgPack, encode: func() ([]byte, error) { return encoding.MarshalMsgPack(response) }},
+		{format: encoding.FormatProtobuf, encode: func() ([]byte, error) { return actionResult.MarshalProto(), nil }},
+	})
+
+	if err := writer.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// report mesure les encodages d'un message et écrit une ligne par encodage (JSON en premier)
+func report(writer *tabwriter.Writer, name string, codecs []codec) {
+	var baseline result
+	for i, c := range codecs {
+		measured, err := measure(c)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s: %v\n", name, c.format, err)
+			os.Exit(1)
+		}
+		if i == 0 {
+			baseline = measured
+		}
+
+		decode := "-"
+		if c.decode != nil {
+			decode = fmt.Sprint(measured.decodeNs)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%s\t%d\t%s\t%s\t%d\t\n",
+			name, c.format, measured.size,
+			saving(int64(measured.size), int64(baseline.size)),
+			measured.encodeNs, decode,
+			saving(measured.encodeNs+measured.decodeNs, baseline.encodeNs+baseline.decodeNs),
+			measured.allocs)
+	}
+}
+
+// measure benchmarke l'encodage puis le décodage d'un message
+func measure(c codec) (result, error) {
+	data, err := c.encode()
+	if err != nil {
+		return result{}, err
+	}
+	if c.decode != nil {
+		if err = c.decode(data); err != nil {
+			return result{}, err
+		}
+	}
+
+	encoded := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, encodeErr := c.encode(); encodeErr != nil {
+				b.Fatal(encodeErr)
+			}
+		}
+	})
+	measured := result{size: len(data), encodeNs: encoded.NsPerOp(), allocs: encoded.AllocsPerOp()}
+
+	if c.decode != nil {
+		decoded := testing.Benchmark(func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				if decodeErr := c.decode(data); decodeErr != nil {
+					b.Fatal(decodeErr)
+				}
+			}
+		})
+		measured.decodeNs = decoded.NsPerOp()
+		measured.allocs += decoded.AllocsPerOp()
+	}
+	return measured, nil
+}
+
+// saving écart relatif à JSON
+func saving(value, baseline int64) string {
+	if baseline == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.0f%%", float64(value-baseline)*percent/float64(baseline))
+}
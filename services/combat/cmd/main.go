@@ -1,6 +1,7 @@
 package main
 
 import (
+	"combat/internal/clients"
 	"combat/internal/config"
 	"combat/internal/database"
 	"combat/internal/handlers"
@@ -9,6 +10,9 @@ import (
 	"combat/internal/service"
 	"context"
 	"fmt"
+	"mmorpg/pkg/jwks"
+	"mmorpg/pkg/serviceauth"
+	"mmorpg/pkg/tracing"
 	"net/http"
 	"os"
 	"os/signal"
@@ -43,6 +47,12 @@ func main() {
 		logrus.Fatal("Failed to load config: ", err)
 	}
 
+	// Tracing distribué (contexte reçu du gateway, export OTLP ou local)
+	shutdownTracing, err := tracing.Init(&cfg.Tracing, "combat", Version, cfg.Server.Environment)
+	if err != nil {
+		logrus.Fatal("Failed to initialize tracing: ", err)
+	}
+
 	// connection à la base de données
 	db, err := database.NewConnection(&cfg.Database)
 	if err != nil {
@@ -60,16 +70,38 @@ func main() {
 	actionRepo := repository.NewActionRepository(db)
 	effectRepo := repository.NewEffectRepository(db)
 	pvpRepo := repository.NewPvPRepository(db)
+	leaderboardRepo := repository.NewLeaderboardRepository(db)
+
+	// Clients des services player et inventory pour le calcul des stats
+	// Les appels internes portent un token de service obtenu auprès du service auth
+	serviceTokens := serviceauth.NewTokenSource(
+		cfg.Services.AuthService.URL,
+		cfg.Services.Identity.ClientID,
+		cfg.Services.Identity.ClientSecret,
+		cfg.Services.AuthService.Timeout,
+	)
+	playerClient, inventoryClient := clients.NewStatsClients(&cfg.Services, serviceTokens)
+	statHydrator := service.NewStatHydrator(playerClient, inventoryClient, cfg)
+	worldClient := clients.NewWorldClientFromConfig(&cfg.Services, serviceTokens)
 
 	// Initialisation des services utilitaires
-	damageCalc := service.NewDamageCalculator(cfg)
+	damageCalc := service.NewDamageCalculator(statHydrator, cfg)
 	antiCheat := service.NewAntiCheatService(actionRepo, cfg)
 
 	// Initialisation des services principaux
-	effectService := service.NewEffectService(effectRepo, combatRepo, cfg)
+	effectService := service.NewEffectService(effectRepo, combatRepo, worldClient, cfg)
 	actionService := service.NewActionService(actionRepo, combatRepo, effectRepo, damageCalc, cfg)
-	combatService := service.NewCombatService(combatRepo, actionRepo, effectRepo, actionService, effectService, antiCheat, cfg)
-	pvpService := service.NewPvPService(pvpRepo, combatRepo, cfg)
+	combatService := service.NewCombatService(combatRepo, actionRepo, effectRepo, actionService, effectService, antiCheat, statHydrator, cfg)
+
+	// Classements PvP en mémoire, reconstruits depuis Postgres
+	leaderboard := service.NewLeaderboard(leaderboardRepo, cfg)
+	if err := leaderboard.Load(); err != nil {
+		logrus.Fatal("Failed to load leaderboards: ", err)
+	}
+	leaderboard.StartRoutines()
+
+	pvpService := service.NewPvPService(pvpRepo, combatRepo, combatService, worldClient, leaderboard, cfg)
+	pvpService.StartDuelBoundaryRoutine()
 
 	// Demarrage des routines de nettoyage
 	// combatService.StartCombatCleanupRoutine()
@@ -77,6 +109,10 @@ func main() {
 	// actionService.StartCooldownCleanupRoutine()
 	// antiCheat.StartCleanupRoutine()
 
+	// Clés publiques du service auth pour vérifier les tokens des joueurs et des services
+	keys := jwks.NewKeySet(cfg.JWT.JWKSURL, cfg.JWT.JWKSRefresh)
+	keys.Start()
+
 	// Initialisation des handlers
 	combatHandler := handlers.NewCombatHandler(combatService, cfg)
 	pvpHandler := handlers.NewPvPHandler(pvpService, cfg)
@@ -88,7 +124,7 @@ func main() {
 	}
 
 	// Configuration des routes
-	router := setupRoutes(combatHandler, pvpHandler, healthHandler, cfg)
+	router := setupRoutes(combatHandler, pvpHandler, healthHandler, keys, cfg)
 
 	// Configuration du serveur HTTP
 	server := &http.Server{
@@ -112,7 +148,7 @@ func main() {
 	}()
 
 	// Gestion gracieuse de l'arrêt
-	gracefulShutdown(server, combatService, effectService, actionService, antiCheat)
+	gracefulShutdown(server, shutdownTracing, keys, combatService, effectService, actionService, antiCheat)
 }
 
 // setupRoutes configure toutes les routes du service Combat
@@ -120,6 +156,7 @@ func setupRoutes(
 	combatHandler *handlers.CombatHandler,
 	pvpHandler *handlers.PvPHandler,
 	healthHandler *handlers.HealthHandler,
+	keys *jwks.KeySet,
 	cfg *config.Config,
 ) *gin.Engine {
 	router := gin.New()
@@ -130,6 +167,7 @@ func setupRoutes(
 	// router.Use(middleware.CORS())
 	// router.Use(middleware.RequestID())
 	// router.Use(middleware.SecurityHeaders())
+	router.Use(tracing.Middleware())
 
 	// Rate limiting global si configuré
 	if cfg.RateLimit.RequestsPerMinute > 0 {
@@ -158,6 +196,7 @@ func setupRoutes(
 	{
 		// Routes protégées (authentification JWT requise)
 		protected := v1.Group("/")
+		protected.Use(middleware.AuthMiddleware(keys))
 
 		{
 			// Routes de combat
@@ -206,6 +245,7 @@ func setupRoutes(
 
 				// Classements et statistiques
 				pvp.GET("/rankings", pvpHandler.GetRankings)
+				pvp.GET("/rankings/snapshots", pvpHandler.GetRankingSnapshots)
 				pvp.GET("/statistics/:characterId", pvpHandler.GetPvPStatistics)
 				pvp.GET("/season", pvpHandler.GetSeasonInfo)
 
@@ -237,10 +277,12 @@ func setupRoutes(
 
 		// Routes pour les autres services (validation interne)
 		services := v1.Group("/services")
+		services.Use(serviceauth.Middleware(serviceauth.NewVerifier(keys, cfg.Services.Identity.TokenIssuer)))
 		{
-			services.GET("/combat/:combatId/status", combatHandler.GetCombatStatusForService)
-			services.POST("/validate/character-stats", combatHandler.ValidateCharacterStats)
-			services.GET("/active-combats-count", combatHandler.GetActiveCombatCount)
+			read := serviceauth.RequireScope(serviceauth.ScopeCombatRead)
+			services.GET("/combat/:combatId/status", read, combatHandler.GetCombatStatusForService)
+			services.POST("/validate/character-stats", serviceauth.RequireScope(serviceauth.ScopeCombatValidate), combatHandler.ValidateCharacterStats)
+			services.GET("/active-combats-count", read, combatHandler.GetActiveCombatCount)
 		}
 	}
 
@@ -267,6 +309,8 @@ func initLogger() {
 // gracefulShutdown gère l'arrêt gracieux du service
 func gracefulShutdown(
 	server *http.Server,
+	shutdownTracing func(context.Context) error,
+	keys *jwks.KeySet,
 	combatService service.CombatServiceInterface,
 	_ service.EffectServiceInterface,
 	_ service.ActionServiceInterface,
@@ -299,9 +343,16 @@ func gracefulShutdown(
 		// TODO: Sauvegarder l'état des combats actifs ou les mettre en pause
 	}
 
+	keys.Close()
+
 	// Nettoyer les données temporaires de l'anti-cheat
 	// antiCheat.CleanupOldData()
 
+	// Exporter les derniers spans
+	if err := shutdownTracing(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to flush traces")
+	}
+
 	logrus.Info("⚔️  Combat Service stopped gracefully")
 }
 
@@ -0,0 +1,50 @@
+package clients
+
+import (
+	"combat/internal/config"
+	"combat/internal/models"
+	"context"
+	"errors"
+	"mmorpg/pkg/serviceauth"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound est renvoyée quand le service distant ne connaît pas la ressource demandée
+var ErrNotFound = errors.New("resource not found")
+
+// PlayerClientInterface définit les appels au service player
+type PlayerClientInterface interface {
+	GetCharacterCombatProfile(ctx context.Context, characterID uuid.UUID) (*models.CharacterCombatProfile, error)
+}
+
+// InventoryClientInterface définit les appels au service inventory
+type InventoryClientInterface interface {
+	GetEquipmentStats(ctx context.Context, characterID uuid.UUID) (*models.EquipmentStats, error)
+}
+
+// WorldClientInterface définit les appels au service world
+type WorldClientInterface interface {
+	GetCharacterLocation(ctx context.Context, characterID uuid.UUID) (*models.CharacterLocation, error)
+	GetCCImmunities(ctx context.Context, npcID uuid.UUID) ([]string, error)
+}
+
+// NewStatsClients crée les clients utilisés pour calculer les stats des participants
+// selon le backend configuré (services distants ou fake local)
+func NewStatsClients(cfg *config.ServicesConfig, tokens *serviceauth.TokenSource) (PlayerClientInterface, InventoryClientInterface) {
+	if cfg.StatsBackend == config.StatsBackendLocal {
+		backend := NewLocalStatsBackend()
+		return backend, backend
+	}
+
+	return NewPlayerClient(cfg.PlayerService, tokens), NewInventoryClient(cfg.InventoryService, tokens)
+}
+
+// NewWorldClientFromConfig crée le client du service world selon le backend configuré
+func NewWorldClientFromConfig(cfg *config.ServicesConfig, tokens *serviceauth.TokenSource) WorldClientInterface {
+	if cfg.StatsBackend == config.StatsBackendLocal {
+		return NewLocalWorldBackend()
+	}
+
+	return NewWorldClient(cfg.WorldService, tokens)
+}
@@ -0,0 +1,100 @@
+package clients
+
+import (
+	"combat/internal/config"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mmorpg/pkg/serviceauth"
+	"mmorpg/pkg/tracing"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// retryBackoff délai entre deux tentatives vers un service distant
+const retryBackoff = 100 * time.Millisecond
+
+// httpServiceClient encapsule les appels HTTP JSON vers un autre microservice
+type httpServiceClient struct {
+	baseURL string
+	retries int
+	client  *http.Client
+	tokens  *serviceauth.TokenSource
+}
+
+func newHTTPServiceClient(endpoint config.ServiceEndpoint, tokens *serviceauth.TokenSource) *httpServiceClient {
+	return &httpServiceClient{
+		baseURL: strings.TrimRight(endpoint.URL, "/"),
+		retries: endpoint.Retries,
+		client:  &http.Client{Timeout: endpoint.Timeout},
+		tokens:  tokens,
+	}
+}
+
+// getJSON effectue un GET et décode la réponse dans out, avec retries sur les erreurs réseau et 5xx
+func (c *httpServiceClient) getJSON(ctx context.Context, path string, out interface{}) error {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryBackoff * time.Duration(attempt)):
+			}
+		}
+
+		retry, err := c.doGet(ctx, path, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return lastErr
+}
+
+func (c *httpServiceClient) doGet(ctx context.Context, path string, out interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, http.NoBody)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Service-Name", "combat")
+	tracing.InjectHeaders(ctx, req.Header)
+
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return true, fmt.Errorf("failed to get service token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("request to %s failed: %w", c.baseURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, ErrNotFound
+	case resp.StatusCode == http.StatusUnauthorized:
+		// Token refusé (secret renouvelé, expiré en route) : un nouveau sera demandé à la tentative suivante
+		c.tokens.Invalidate()
+		return true, fmt.Errorf("%s rejected the service token", c.baseURL+path)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return true, fmt.Errorf("%s returned status %d", c.baseURL+path, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("%s returned status %d", c.baseURL+path, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,55 @@
+package clients
+
+import (
+	"combat/internal/config"
+	"combat/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"mmorpg/pkg/serviceauth"
+
+	"github.com/google/uuid"
+)
+
+// InventoryClient client HTTP typé du service inventory
+type InventoryClient struct {
+	http *httpServiceClient
+}
+
+// NewInventoryClient crée un nouveau client du service inventory
+func NewInventoryClient(endpoint config.ServiceEndpoint, tokens *serviceauth.TokenSource) InventoryClientInterface {
+	return &InventoryClient{
+		http: newHTTPServiceClient(endpoint, tokens),
+	}
+}
+
+// GetEquipmentStats récupère les bonus accordés par l'équipement porté
+func (c *InventoryClient) GetEquipmentStats(ctx context.Context, characterID uuid.UUID) (*models.EquipmentStats, error) {
+	var response struct {
+		Success bool                   `json:"success"`
+		Data    *models.EquipmentStats `json:"data"`
+	}
+
+	path := fmt.Sprintf("/api/v1/services/equipment/%s/stats", characterID)
+	if err := c.http.getJSON(ctx, path, &response); err != nil {
+		// Un personnage sans équipement n'est pas une erreur
+		if errors.Is(err, ErrNotFound) {
+			return emptyEquipmentStats(characterID), nil
+		}
+		return nil, fmt.Errorf("inventory service: %w", err)
+	}
+
+	if response.Data == nil {
+		return emptyEquipmentStats(characterID), nil
+	}
+
+	return response.Data, nil
+}
+
+func emptyEquipmentStats(characterID uuid.UUID) *models.EquipmentStats {
+	return &models.EquipmentStats{
+		CharacterID: characterID,
+		TotalStats:  make(map[string]int),
+		Items:       []models.EquippedItem{},
+	}
+}
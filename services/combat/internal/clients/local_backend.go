@@ -0,0 +1,145 @@
+package clients
+
+import (
+	"combat/internal/models"
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// LocalStatsBackend fake en mémoire des services player et inventory,
+// utilisé pour les tests et le développement sans les autres services
+type LocalStatsBackend struct {
+	mu        sync.RWMutex
+	profiles  map[uuid.UUID]*models.CharacterCombatProfile
+	equipment map[uuid.UUID]*models.EquipmentStats
+}
+
+// NewLocalStatsBackend crée un backend local vide
+func NewLocalStatsBackend() *LocalStatsBackend {
+	return &LocalStatsBackend{
+		profiles:  make(map[uuid.UUID]*models.CharacterCombatProfile),
+		equipment: make(map[uuid.UUID]*models.EquipmentStats),
+	}
+}
+
+// SetCharacterProfile enregistre le profil renvoyé pour un personnage
+func (b *LocalStatsBackend) SetCharacterProfile(profile *models.CharacterCombatProfile) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.profiles[profile.CharacterID] = profile
+}
+
+// SetEquipmentStats enregistre les bonus d'équipement renvoyés pour un personnage
+func (b *LocalStatsBackend) SetEquipmentStats(stats *models.EquipmentStats) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.equipment[stats.CharacterID] = stats
+}
+
+// GetCharacterCombatProfile implémente PlayerClientInterface
+func (b *LocalStatsBackend) GetCharacterCombatProfile(_ context.Context, characterID uuid.UUID) (*models.CharacterCombatProfile, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if profile, exists := b.profiles[characterID]; exists {
+		return profile, nil
+	}
+
+	return defaultLocalProfile(characterID), nil
+}
+
+// GetEquipmentStats implémente InventoryClientInterface
+func (b *LocalStatsBackend) GetEquipmentStats(_ context.Context, characterID uuid.UUID) (*models.EquipmentStats, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if stats, exists := b.equipment[characterID]; exists {
+		return stats, nil
+	}
+
+	return emptyEquipmentStats(characterID), nil
+}
+
+// defaultLocalProfile renvoie un personnage niveau 1 avec 10 points dans chaque attribut
+// (mêmes formules que le service player)
+func defaultLocalProfile(characterID uuid.UUID) *models.CharacterCombatProfile {
+	return &models.CharacterCombatProfile{
+		CharacterID: characterID,
+		Level:       1,
+		Class:       "warrior",
+		Race:        "human",
+		BaseStats: &models.CharacterBaseStats{
+			Health:          200,
+			MaxHealth:       200,
+			Mana:            100,
+			MaxMana:         100,
+			Strength:        10,
+			Agility:         10,
+			Intelligence:    10,
+			Vitality:        10,
+			PhysicalDamage:  25,
+			MagicalDamage:   25,
+			PhysicalDefense: 6,
+			MagicalDefense:  6,
+			CriticalChance:  6,
+			AttackSpeed:     102,
+		},
+		Modifiers: []*models.CharacterStatModifier{},
+	}
+}
+
+// LocalWorldBackend fake en mémoire du service world: par défaut, tous les personnages
+// sont en ligne à l'origine d'une zone sans restriction
+type LocalWorldBackend struct {
+	mu           sync.RWMutex
+	locations    map[uuid.UUID]*models.CharacterLocation
+	ccImmunities map[uuid.UUID][]string
+}
+
+// NewLocalWorldBackend crée un backend world local vide
+func NewLocalWorldBackend() *LocalWorldBackend {
+	return &LocalWorldBackend{
+		locations:    make(map[uuid.UUID]*models.CharacterLocation),
+		ccImmunities: make(map[uuid.UUID][]string),
+	}
+}
+
+// SetCharacterLocation enregistre la position renvoyée pour un personnage
+func (b *LocalWorldBackend) SetCharacterLocation(location *models.CharacterLocation) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.locations[location.CharacterID] = location
+}
+
+// GetCharacterLocation implémente WorldClientInterface
+func (b *LocalWorldBackend) GetCharacterLocation(_ context.Context, characterID uuid.UUID) (*models.CharacterLocation, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if location, exists := b.locations[characterID]; exists {
+		return location, nil
+	}
+
+	return &models.CharacterLocation{
+		CharacterID: characterID,
+		ZoneID:      "local",
+		IsOnline:    true,
+	}, nil
+}
+
+// SetCCImmunities enregistre les immunités aux contrôles renvoyées pour un NPC
+func (b *LocalWorldBackend) SetCCImmunities(npcID uuid.UUID, immunities []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ccImmunities[npcID] = immunities
+}
+
+// GetCCImmunities implémente WorldClientInterface
+func (b *LocalWorldBackend) GetCCImmunities(_ context.Context, npcID uuid.UUID) ([]string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.ccImmunities[npcID], nil
+}
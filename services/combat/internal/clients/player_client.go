@@ -0,0 +1,41 @@
+package clients
+
+import (
+	"combat/internal/config"
+	"combat/internal/models"
+	"context"
+	"fmt"
+	"mmorpg/pkg/serviceauth"
+
+	"github.com/google/uuid"
+)
+
+// PlayerClient client HTTP typé du service player
+type PlayerClient struct {
+	http *httpServiceClient
+}
+
+// NewPlayerClient crée un nouveau client du service player
+func NewPlayerClient(endpoint config.ServiceEndpoint, tokens *serviceauth.TokenSource) PlayerClientInterface {
+	return &PlayerClient{
+		http: newHTTPServiceClient(endpoint, tokens),
+	}
+}
+
+// GetCharacterCombatProfile récupère les stats de base et les modificateurs actifs d'un personnage
+func (c *PlayerClient) GetCharacterCombatProfile(ctx context.Context, characterID uuid.UUID) (*models.CharacterCombatProfile, error) {
+	var response struct {
+		Profile *models.CharacterCombatProfile `json:"profile"`
+	}
+
+	path := fmt.Sprintf("/api/v1/services/character/%s/combat-profile", characterID)
+	if err := c.http.getJSON(ctx, path, &response); err != nil {
+		return nil, fmt.Errorf("player service: %w", err)
+	}
+
+	if response.Profile == nil || response.Profile.BaseStats == nil {
+		return nil, fmt.Errorf("player service: empty combat profile for character %s", characterID)
+	}
+
+	return response.Profile, nil
+}
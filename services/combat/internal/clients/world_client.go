@@ -0,0 +1,60 @@
+package clients
+
+import (
+	"combat/internal/config"
+	"combat/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"mmorpg/pkg/serviceauth"
+
+	"github.com/google/uuid"
+)
+
+// WorldClient client HTTP typé du service world
+type WorldClient struct {
+	http *httpServiceClient
+}
+
+// NewWorldClient crée un nouveau client du service world
+func NewWorldClient(endpoint config.ServiceEndpoint, tokens *serviceauth.TokenSource) WorldClientInterface {
+	return &WorldClient{
+		http: newHTTPServiceClient(endpoint, tokens),
+	}
+}
+
+// GetCharacterLocation récupère la position d'un personnage et les règles de sa zone
+func (c *WorldClient) GetCharacterLocation(ctx context.Context, characterID uuid.UUID) (*models.CharacterLocation, error) {
+	var response struct {
+		Location *models.CharacterLocation `json:"location"`
+	}
+
+	path := fmt.Sprintf("/api/v1/services/character/%s/location", characterID)
+	if err := c.http.getJSON(ctx, path, &response); err != nil {
+		return nil, fmt.Errorf("world service: %w", err)
+	}
+
+	if response.Location == nil {
+		return nil, fmt.Errorf("world service: empty location for character %s", characterID)
+	}
+
+	return response.Location, nil
+}
+
+// GetCCImmunities récupère les immunités aux contrôles du modèle d'un NPC. Un identifiant inconnu
+// du service world (un personnage joueur) n'a aucune immunité
+func (c *WorldClient) GetCCImmunities(ctx context.Context, npcID uuid.UUID) ([]string, error) {
+	var response struct {
+		CCImmunities []string `json:"cc_immunities"`
+	}
+
+	path := fmt.Sprintf("/api/v1/services/npcs/%s/cc-immunities", npcID)
+	if err := c.http.getJSON(ctx, path, &response); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("world service: %w", err)
+	}
+
+	return response.CCImmunities, nil
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"mmorpg/pkg/tracing"
 	"time"
 
 	"github.com/spf13/viper"
@@ -111,6 +112,9 @@ const (
 	DefaultMinKnockback      = 0.1
 	DefaultMaxMultiplier     = 3.0
 
+	// Vitesse d'attaque minimale (10 %), quels que soient les malus
+	MinAttackSpeed = 0.1
+
 	// Constantes de combat avancé
 	DefaultDamageReduction          = 0.3
 	DefaultArmorDivisor             = 2
@@ -159,6 +163,41 @@ const (
 	DefaultElementalPowerWind      = 0.75
 	DefaultElementalPowerDark      = 0.85
 	DefaultElementalPowerLight     = 0.8
+	DefaultElementalPowerWater     = 0.7
+
+	// Constantes du système élémentaire
+	DefaultMaxElementalResistance    = 0.75
+	DefaultMaxElementalVulnerability = 1.0
+	DefaultMaxElementalPenetration   = 1.0
+	DefaultReactionMultiplierMajor   = 1.5
+	DefaultReactionMultiplierMinor   = 1.3
+	DefaultReactionMultiplierWeak    = 1.15
+	DefaultRaceAffinity              = 0.1
+	DefaultRaceResistance            = 0.15
+	DefaultRaceResistanceMinor       = 0.05
+	DefaultRaceVulnerability         = 0.1
+
+	// Constantes des rendements décroissants des contrôles (CC)
+	DefaultDRWindow            = 18
+	DefaultDRMultiplierFull    = 1.0
+	DefaultDRMultiplierReduced = 0.75
+	DefaultDRMultiplierHalf    = 0.5
+	DefaultDRMultiplierQuarter = 0.25
+	DefaultCCImmunityCacheTTL  = 300
+
+	// Constantes des classements PvP
+	DefaultLeaderboardSeason           = "season-1"
+	DefaultLeaderboardSnapshotInterval = 60
+	DefaultLeaderboardSnapshotSize     = 1000
+	DefaultLeaderboardResyncInterval   = 15
+	DefaultLeaderboardLoadBatchSize    = 5000
+	DefaultLeaderboardAroundWindow     = 5
+	DefaultLeaderboardMaxAroundWindow  = 50
+
+	// Constantes des duels (distances en unités du monde)
+	DefaultDuelMaxDistance      = 30.0
+	DefaultDuelBoundaryRadius   = 60.0
+	DefaultDuelBoundaryInterval = 2
 
 	// Constantes de timing
 	DefaultChallengeExpiration = 24
@@ -272,21 +311,31 @@ const (
 	DefaultRateLimitRequestsPerMinute   = 100
 	DefaultRateLimitBurstSize           = 20
 	DefaultRateLimitCleanupInterval     = 5
-	DefaultJWTMinSecretLength           = 32
+	DefaultJWKSRefresh                  = 5 // minutes
+
+	// Backends de récupération des stats des personnages
+	StatsBackendRemote = "remote"
+	StatsBackendLocal  = "local"
 )
 
+// Tracing distribué
+const DefaultTracingSampleRatio = 1.0
+
 // Config structure principale de configuration
 type Config struct {
-	Server     ServerConfig     `mapstructure:"server"`
-	Database   DatabaseConfig   `mapstructure:"database"`
-	JWT        JWTConfig        `mapstructure:"jwt"`
-	Redis      RedisConfig      `mapstructure:"redis"`
-	Services   ServicesConfig   `mapstructure:"services"`
-	Combat     CombatConfig     `mapstructure:"combat"`
-	AntiCheat  AntiCheatConfig  `mapstructure:"anticheat"`
-	RateLimit  RateLimitConfig  `mapstructure:"rate_limit"`
-	Monitoring MonitoringConfig `mapstructure:"monitoring"`
-	Logging    LoggingConfig    `mapstructure:"logging"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	JWT         JWTConfig         `mapstructure:"jwt"`
+	Redis       RedisConfig       `mapstructure:"redis"`
+	Services    ServicesConfig    `mapstructure:"services"`
+	Combat      CombatConfig      `mapstructure:"combat"`
+	Leaderboard LeaderboardConfig `mapstructure:"leaderboard"`
+	Duel        DuelConfig        `mapstructure:"duel"`
+	AntiCheat   AntiCheatConfig   `mapstructure:"anticheat"`
+	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`
+	Monitoring  MonitoringConfig  `mapstructure:"monitoring"`
+	Logging     LoggingConfig     `mapstructure:"logging"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
 }
 
 // ServerConfig configuration du serveur HTTP
@@ -313,8 +362,11 @@ type DatabaseConfig struct {
 }
 
 // JWTConfig configuration JWT
+// Les tokens sont vérifiés avec les clés publiques du service auth (JWKS), gardées en cache
+// et rechargées toutes les JWKSRefresh : le service ne détient aucune clé de signature.
 type JWTConfig struct {
-	Secret         string        `mapstructure:"secret"`
+	JWKSURL        string        `mapstructure:"jwks_url"`
+	JWKSRefresh    time.Duration `mapstructure:"jwks_refresh"`
 	ExpirationTime time.Duration `mapstructure:"expiration_time"`
 }
 
@@ -330,9 +382,21 @@ type RedisConfig struct {
 
 // ServicesConfig configuration des services externes
 type ServicesConfig struct {
-	AuthService   ServiceEndpoint `mapstructure:"auth_service"`
-	PlayerService ServiceEndpoint `mapstructure:"player_service"`
-	WorldService  ServiceEndpoint `mapstructure:"world_service"`
+	AuthService      ServiceEndpoint `mapstructure:"auth_service"`
+	PlayerService    ServiceEndpoint `mapstructure:"player_service"`
+	WorldService     ServiceEndpoint `mapstructure:"world_service"`
+	InventoryService ServiceEndpoint `mapstructure:"inventory_service"`
+	StatsBackend     string          `mapstructure:"stats_backend"` // "remote" ou "local" (fake en mémoire)
+	Identity         ServiceIdentity `mapstructure:"identity"`
+}
+
+// ServiceIdentity identité du service combat pour les appels internes (routes /services)
+// Le service obtient ses tokens auprès du service auth avec ses identifiants client
+// et vérifie avec le JWKS les tokens des services qui l'appellent.
+type ServiceIdentity struct {
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+	TokenIssuer  string `mapstructure:"token_issuer"`
 }
 
 // ServiceEndpoint configuration d'un service externe
@@ -351,6 +415,56 @@ type CombatConfig struct {
 	EnablePvP       bool          `mapstructure:"enable_pvp"`
 	EnablePvE       bool          `mapstructure:"enable_pve"`
 	MaxPartySize    int           `mapstructure:"max_party_size"`
+
+	DiminishingReturns DiminishingReturnsConfig `mapstructure:"diminishing_returns"`
+	// Durée de cache des immunités aux contrôles lues dans les modèles de NPC du service world
+	CCImmunityCacheTTL time.Duration `mapstructure:"cc_immunity_cache_ttl"`
+}
+
+// DiminishingReturnsRule règles de rendements décroissants des contrôles pour un type de combat
+type DiminishingReturnsRule struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Window  time.Duration `mapstructure:"window"` // remise à zéro après la dernière application
+	// Multiplicateur de durée pour chaque application successive; au-delà, la cible est immunisée
+	Multipliers []float64 `mapstructure:"multipliers"`
+}
+
+// DiminishingReturnsConfig règles de rendements décroissants par type de combat
+type DiminishingReturnsConfig struct {
+	PvP     DiminishingReturnsRule `mapstructure:"pvp"`
+	PvE     DiminishingReturnsRule `mapstructure:"pve"`
+	Dungeon DiminishingReturnsRule `mapstructure:"dungeon"`
+	Raid    DiminishingReturnsRule `mapstructure:"raid"`
+}
+
+// RuleFor retourne les règles d'un type de combat (PvE par défaut)
+func (c *DiminishingReturnsConfig) RuleFor(combatType string) DiminishingReturnsRule {
+	switch combatType {
+	case "pvp":
+		return c.PvP
+	case "dungeon":
+		return c.Dungeon
+	case "raid":
+		return c.Raid
+	default:
+		return c.PvE
+	}
+}
+
+// LeaderboardConfig configuration des classements PvP
+type LeaderboardConfig struct {
+	CurrentSeason    string        `mapstructure:"current_season"`
+	SnapshotInterval time.Duration `mapstructure:"snapshot_interval"`
+	SnapshotSize     int           `mapstructure:"snapshot_size"`   // nombre de rangs conservés par snapshot
+	ResyncInterval   time.Duration `mapstructure:"resync_interval"` // rechargement depuis Postgres (multi-instances)
+	LoadBatchSize    int           `mapstructure:"load_batch_size"`
+}
+
+// DuelConfig configuration des duels dans le monde
+type DuelConfig struct {
+	MaxDistance      float64       `mapstructure:"max_distance"`    // distance maximale entre les joueurs pour défier
+	BoundaryRadius   float64       `mapstructure:"boundary_radius"` // rayon de la zone de duel autour du point de départ
+	BoundaryInterval time.Duration `mapstructure:"boundary_interval"`
 }
 
 // AntiCheatConfig configuration anti-triche
@@ -382,6 +496,9 @@ type LoggingConfig struct {
 	Format string `mapstructure:"format"`
 }
 
+// TracingConfig configuration du tracing distribué, commune à tous les services
+type TracingConfig = tracing.Config
+
 // bindEnvironmentVariables configure les mappings des variables d'environnement avec Viper
 func bindEnvironmentVariables() error {
 	envBindings := map[string]string{
@@ -405,7 +522,8 @@ func bindEnvironmentVariables() error {
 		"database.conn_max_lifetime": "DATABASE_CONN_MAX_LIFETIME",
 
 		// JWT configuration
-		"jwt.secret":          "JWT_SECRET",
+		"jwt.jwks_url":        "AUTH_JWKS_URL",
+		"jwt.jwks_refresh":    "AUTH_JWKS_REFRESH",
 		"jwt.expiration_time": "JWT_EXPIRATION_TIME",
 
 		// Redis configuration
@@ -417,15 +535,47 @@ func bindEnvironmentVariables() error {
 		"redis.pool_size":   "REDIS_POOL_SIZE",
 
 		// Services configuration
-		"services.auth_service.url":   "AUTH_SERVICE_URL",
-		"services.player_service.url": "PLAYER_SERVICE_URL",
-		"services.world_service.url":  "WORLD_SERVICE_URL",
+		"services.auth_service.url":       "AUTH_SERVICE_URL",
+		"services.player_service.url":     "PLAYER_SERVICE_URL",
+		"services.world_service.url":      "WORLD_SERVICE_URL",
+		"services.inventory_service.url":  "INVENTORY_SERVICE_URL",
+		"services.stats_backend":          "SERVICES_STATS_BACKEND",
+		"services.identity.client_id":     "SERVICE_CLIENT_ID",
+		"services.identity.client_secret": "SERVICE_CLIENT_SECRET",
+		"services.identity.token_issuer":  "SERVICE_TOKEN_ISSUER",
 
 		// Combat configuration
-		"combat.max_duration":     "COMBAT_MAX_DURATION",
-		"combat.turn_timeout":     "COMBAT_TURN_TIMEOUT",
-		"combat.max_concurrent":   "COMBAT_MAX_CONCURRENT",
-		"combat.cleanup_interval": "COMBAT_CLEANUP_INTERVAL",
+		"combat.max_duration":          "COMBAT_MAX_DURATION",
+		"combat.turn_timeout":          "COMBAT_TURN_TIMEOUT",
+		"combat.max_concurrent":        "COMBAT_MAX_CONCURRENT",
+		"combat.cleanup_interval":      "COMBAT_CLEANUP_INTERVAL",
+		"combat.cc_immunity_cache_ttl": "COMBAT_CC_IMMUNITY_CACHE_TTL",
+
+		// Diminishing returns configuration
+		"combat.diminishing_returns.pvp.enabled":         "COMBAT_DR_PVP_ENABLED",
+		"combat.diminishing_returns.pvp.window":          "COMBAT_DR_PVP_WINDOW",
+		"combat.diminishing_returns.pvp.multipliers":     "COMBAT_DR_PVP_MULTIPLIERS",
+		"combat.diminishing_returns.pve.enabled":         "COMBAT_DR_PVE_ENABLED",
+		"combat.diminishing_returns.pve.window":          "COMBAT_DR_PVE_WINDOW",
+		"combat.diminishing_returns.pve.multipliers":     "COMBAT_DR_PVE_MULTIPLIERS",
+		"combat.diminishing_returns.dungeon.enabled":     "COMBAT_DR_DUNGEON_ENABLED",
+		"combat.diminishing_returns.dungeon.window":      "COMBAT_DR_DUNGEON_WINDOW",
+		"combat.diminishing_returns.dungeon.multipliers": "COMBAT_DR_DUNGEON_MULTIPLIERS",
+		"combat.diminishing_returns.raid.enabled":        "COMBAT_DR_RAID_ENABLED",
+		"combat.diminishing_returns.raid.window":         "COMBAT_DR_RAID_WINDOW",
+		"combat.diminishing_returns.raid.multipliers":    "COMBAT_DR_RAID_MULTIPLIERS",
+
+		// Leaderboard configuration
+		"leaderboard.current_season":    "LEADERBOARD_CURRENT_SEASON",
+		"leaderboard.snapshot_interval": "LEADERBOARD_SNAPSHOT_INTERVAL",
+		"leaderboard.snapshot_size":     "LEADERBOARD_SNAPSHOT_SIZE",
+		"leaderboard.resync_interval":   "LEADERBOARD_RESYNC_INTERVAL",
+		"leaderboard.load_batch_size":   "LEADERBOARD_LOAD_BATCH_SIZE",
+
+		// Duel configuration
+		"duel.max_distance":      "DUEL_MAX_DISTANCE",
+		"duel.boundary_radius":   "DUEL_BOUNDARY_RADIUS",
+		"duel.boundary_interval": "DUEL_BOUNDARY_INTERVAL",
 
 		// Anti-cheat configuration
 		"anticheat.max_actions_per_second": "ANTICHEAT_MAX_ACTIONS_PER_SECOND",
@@ -444,6 +594,13 @@ func bindEnvironmentVariables() error {
 		// Logging configuration
 		"logging.level":  "LOG_LEVEL",
 		"logging.format": "LOG_FORMAT",
+
+		// Tracing configuration
+		"tracing.enabled":      "TRACING_ENABLED",
+		"tracing.exporter":     "TRACING_EXPORTER",
+		"tracing.endpoint":     "TRACING_ENDPOINT",
+		"tracing.file":         "TRACING_FILE",
+		"tracing.sample_ratio": "TRACING_SAMPLE_RATIO",
 	}
 
 	for viperKey, envVar := range envBindings {
@@ -479,7 +636,8 @@ func LoadConfig() (*Config, error) {
 			ConnMaxLifetime: time.Duration(DefaultConnMaxLifetime) * time.Second,
 		},
 		JWT: JWTConfig{
-			Secret:         "your-super-secret-jwt-key-change-in-production-minimum-64-characters",
+			JWKSURL:        "http://localhost:8081/.well-known/jwks.json",
+			JWKSRefresh:    time.Duration(DefaultJWKSRefresh) * time.Minute,
 			ExpirationTime: time.Duration(DefaultJWTExpiration) * time.Hour,
 		},
 		Redis: RedisConfig{
@@ -506,6 +664,17 @@ func LoadConfig() (*Config, error) {
 				Timeout: time.Duration(DefaultServiceTimeout) * time.Second,
 				Retries: DefaultServiceRetries,
 			},
+			InventoryService: ServiceEndpoint{
+				URL:     "http://localhost:8084",
+				Timeout: time.Duration(DefaultServiceTimeout) * time.Second,
+				Retries: DefaultServiceRetries,
+			},
+			StatsBackend: StatsBackendRemote,
+			Identity: ServiceIdentity{
+				ClientID:     "combat",
+				ClientSecret: "combat-dev-client-secret",
+				TokenIssuer:  "mmo-auth-service",
+			},
 		},
 		Combat: CombatConfig{
 			MaxDuration:     time.Duration(DefaultCombatMaxDuration) * time.Second,
@@ -515,6 +684,29 @@ func LoadConfig() (*Config, error) {
 			EnablePvP:       true,
 			EnablePvE:       true,
 			MaxPartySize:    DefaultCombatMaxPartySize,
+			DiminishingReturns: DiminishingReturnsConfig{
+				PvP: DiminishingReturnsRule{
+					Enabled:     true,
+					Window:      time.Duration(DefaultDRWindow) * time.Second,
+					Multipliers: []float64{DefaultDRMultiplierFull, DefaultDRMultiplierHalf, DefaultDRMultiplierQuarter},
+				},
+				PvE:     defaultPvEDiminishingReturns(),
+				Dungeon: defaultPvEDiminishingReturns(),
+				Raid:    defaultPvEDiminishingReturns(),
+			},
+			CCImmunityCacheTTL: time.Duration(DefaultCCImmunityCacheTTL) * time.Second,
+		},
+		Leaderboard: LeaderboardConfig{
+			CurrentSeason:    DefaultLeaderboardSeason,
+			SnapshotInterval: time.Duration(DefaultLeaderboardSnapshotInterval) * time.Minute,
+			SnapshotSize:     DefaultLeaderboardSnapshotSize,
+			ResyncInterval:   time.Duration(DefaultLeaderboardResyncInterval) * time.Minute,
+			LoadBatchSize:    DefaultLeaderboardLoadBatchSize,
+		},
+		Duel: DuelConfig{
+			MaxDistance:      DefaultDuelMaxDistance,
+			BoundaryRadius:   DefaultDuelBoundaryRadius,
+			BoundaryInterval: time.Duration(DefaultDuelBoundaryInterval) * time.Second,
 		},
 		AntiCheat: AntiCheatConfig{
 			MaxActionsPerSecond:    DefaultAntiCheatMaxActionsPerSecond,
@@ -537,6 +729,14 @@ func LoadConfig() (*Config, error) {
 			Level:  "info",
 			Format: "json",
 		},
+		Tracing: TracingConfig{
+			Enabled:     false,
+			Exporter:    "otlp",
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			File:        "traces.json",
+			SampleRatio: DefaultTracingSampleRatio,
+		},
 	}
 
 	// Configuration Viper
@@ -557,6 +757,18 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	// Les listes surchargées remplacent celles par défaut au lieu d'être fusionnées
+	for combatType, rule := range map[string]*DiminishingReturnsRule{
+		"pvp":     &config.Combat.DiminishingReturns.PvP,
+		"pve":     &config.Combat.DiminishingReturns.PvE,
+		"dungeon": &config.Combat.DiminishingReturns.Dungeon,
+		"raid":    &config.Combat.DiminishingReturns.Raid,
+	} {
+		if viper.IsSet("combat.diminishing_returns." + combatType + ".multipliers") {
+			rule.Multipliers = nil
+		}
+	}
+
 	// Merger avec la configuration par défaut
 	if err := viper.Unmarshal(config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
@@ -578,8 +790,8 @@ func (c *Config) Validate() error {
 	}
 
 	// Validation JWT
-	if len(c.JWT.Secret) < DefaultJWTMinSecretLength {
-		return fmt.Errorf("JWT secret must be at least 32 characters long")
+	if c.JWT.JWKSURL == "" || c.JWT.JWKSRefresh <= 0 {
+		return fmt.Errorf("JWKS URL and a positive refresh interval are required")
 	}
 
 	// Validation database
@@ -594,6 +806,63 @@ func (c *Config) Validate() error {
 	if c.Services.PlayerService.URL == "" {
 		return fmt.Errorf("player service URL is required")
 	}
+	switch c.Services.StatsBackend {
+	case StatsBackendRemote:
+		if c.Services.InventoryService.URL == "" {
+			return fmt.Errorf("inventory service URL is required")
+		}
+	case StatsBackendLocal:
+	default:
+		return fmt.Errorf("invalid stats backend: %s", c.Services.StatsBackend)
+	}
+	if c.Services.Identity.ClientID == "" || c.Services.Identity.ClientSecret == "" {
+		return fmt.Errorf("service client ID and secret are required")
+	}
+
+	// Validation des rendements décroissants
+	for combatType, rule := range map[string]DiminishingReturnsRule{
+		"pvp":     c.Combat.DiminishingReturns.PvP,
+		"pve":     c.Combat.DiminishingReturns.PvE,
+		"dungeon": c.Combat.DiminishingReturns.Dungeon,
+		"raid":    c.Combat.DiminishingReturns.Raid,
+	} {
+		if !rule.Enabled {
+			continue
+		}
+		if rule.Window <= 0 {
+			return fmt.Errorf("diminishing returns window must be positive for %s", combatType)
+		}
+		if len(rule.Multipliers) == 0 {
+			return fmt.Errorf("diminishing returns multipliers are required for %s", combatType)
+		}
+		for _, multiplier := range rule.Multipliers {
+			if multiplier <= 0 || multiplier > 1 {
+				return fmt.Errorf("invalid diminishing returns multiplier for %s: %v", combatType, multiplier)
+			}
+		}
+	}
+
+	// Validation des classements
+	if c.Leaderboard.CurrentSeason == "" {
+		return fmt.Errorf("leaderboard current season is required")
+	}
+	if c.Leaderboard.SnapshotSize <= 0 || c.Leaderboard.LoadBatchSize <= 0 {
+		return fmt.Errorf("leaderboard snapshot size and load batch size must be positive")
+	}
+	if c.Leaderboard.SnapshotInterval <= 0 || c.Leaderboard.ResyncInterval <= 0 {
+		return fmt.Errorf("leaderboard snapshot and resync intervals must be positive")
+	}
+
+	// Validation des duels
+	if c.Duel.MaxDistance <= 0 || c.Duel.BoundaryRadius <= 0 {
+		return fmt.Errorf("duel max distance and boundary radius must be positive")
+	}
+	if c.Duel.BoundaryRadius < c.Duel.MaxDistance {
+		return fmt.Errorf("duel boundary radius must be at least the max challenge distance")
+	}
+	if c.Duel.BoundaryInterval <= 0 {
+		return fmt.Errorf("duel boundary interval must be positive")
+	}
 
 	// Validation anti-cheat
 	if c.AntiCheat.MaxActionsPerSecond <= 0 {
@@ -606,6 +875,31 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// MaxWindow retourne la plus longue fenêtre de rendements décroissants configurée
+func (c *DiminishingReturnsConfig) MaxWindow() time.Duration {
+	window := c.PvP.Window
+	for _, rule := range []DiminishingReturnsRule{c.PvE, c.Dungeon, c.Raid} {
+		if rule.Window > window {
+			window = rule.Window
+		}
+	}
+	return window
+}
+
+// defaultPvEDiminishingReturns règles plus clémentes pour le PvE (plus d'applications avant immunité)
+func defaultPvEDiminishingReturns() DiminishingReturnsRule {
+	return DiminishingReturnsRule{
+		Enabled: true,
+		Window:  time.Duration(DefaultDRWindow) * time.Second,
+		Multipliers: []float64{
+			DefaultDRMultiplierFull,
+			DefaultDRMultiplierReduced,
+			DefaultDRMultiplierHalf,
+			DefaultDRMultiplierQuarter,
+		},
+	}
+}
+
 // GetDSN retourne la chaîne de connection PostgreSQL
 func (c *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf(
@@ -4,6 +4,7 @@ import (
 	"combat/internal/config"
 	"context"
 	"fmt"
+	"mmorpg/pkg/tracing"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -17,11 +18,12 @@ type DB struct {
 
 // NewConnection crée une nouvelle connection à la base de données
 func NewConnection(cfg *config.DatabaseConfig) (*DB, error) {
-	// connection à PostgreSQL
-	db, err := sqlx.Connect("postgres", cfg.GetDSN())
+	// connection à PostgreSQL, requêtes tracées dans le span de la requête HTTP
+	sqlDB, err := tracing.OpenDB("postgres", cfg.GetDSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	db := sqlx.NewDb(sqlDB, "postgres")
 
 	// Configuration de la pool de connections
 	db.SetMaxOpenConns(cfg.MaxOpenConns)
@@ -89,6 +91,8 @@ func RunMigrations(db *DB) error {
 		createCombatLogsTable,         // 6
 		createCombatStatsTable,        // 7
 		createIndexes,                 // 8
+		createPvPLadderTables,         // 9
+		addPvPDuelBoundary,            // 10
 	}
 
 	for i, migration := range migrations {
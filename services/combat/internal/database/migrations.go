@@ -256,3 +256,52 @@ CREATE INDEX IF NOT EXISTS idx_combat_logs_timestamp ON combat_logs(combat_id, t
 CREATE INDEX IF NOT EXISTS idx_combat_statistics_character_id ON combat_statistics(character_id);
 CREATE INDEX IF NOT EXISTS idx_combat_statistics_user_id ON combat_statistics(user_id);
 CREATE INDEX IF NOT EXISTS idx_combat_statistics_pvp_rating ON combat_statistics(pvp_rating DESC);`
+
+// Migration 9: Classements PvP par saison et file (source de vérité du leaderboard)
+const createPvPLadderTables = `
+CREATE TABLE IF NOT EXISTS pvp_ladder_entries (
+    season VARCHAR(50) NOT NULL,
+    queue_type VARCHAR(20) NOT NULL,
+    character_id UUID NOT NULL,
+    class VARCHAR(50) NOT NULL DEFAULT '',
+    region VARCHAR(50) NOT NULL DEFAULT '',
+    rating INTEGER NOT NULL DEFAULT 1000,
+    wins INTEGER NOT NULL DEFAULT 0,
+    losses INTEGER NOT NULL DEFAULT 0,
+    draws INTEGER NOT NULL DEFAULT 0,
+    streak INTEGER NOT NULL DEFAULT 0,
+    last_match_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    
+    PRIMARY KEY (season, queue_type, character_id)
+);
+
+CREATE TABLE IF NOT EXISTS pvp_leaderboard_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    season VARCHAR(50) NOT NULL,
+    queue_type VARCHAR(20) NOT NULL,
+    class VARCHAR(50) NOT NULL DEFAULT '',
+    region VARCHAR(50) NOT NULL DEFAULT '',
+    total_players INTEGER NOT NULL DEFAULT 0,
+    taken_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS pvp_leaderboard_snapshot_entries (
+    snapshot_id UUID NOT NULL REFERENCES pvp_leaderboard_snapshots(id) ON DELETE CASCADE,
+    rank INTEGER NOT NULL,
+    character_id UUID NOT NULL,
+    rating INTEGER NOT NULL,
+    wins INTEGER NOT NULL DEFAULT 0,
+    losses INTEGER NOT NULL DEFAULT 0,
+    draws INTEGER NOT NULL DEFAULT 0,
+    streak INTEGER NOT NULL DEFAULT 0,
+    
+    PRIMARY KEY (snapshot_id, rank)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pvp_ladder_entries_season ON pvp_ladder_entries(season, character_id);
+CREATE INDEX IF NOT EXISTS idx_pvp_leaderboard_snapshots_ladder ON pvp_leaderboard_snapshots(season, queue_type, class, region, taken_at DESC);`
+
+// Migration 10: Zone des duels en cours, relue au démarrage du service
+const addPvPDuelBoundary = `
+ALTER TABLE pvp_challenges ADD COLUMN IF NOT EXISTS duel_boundary JSONB;`
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"combat/internal/config"
+	"combat/internal/encoding"
 	"combat/internal/models"
 	"combat/internal/service"
 	"net/http"
@@ -261,8 +262,8 @@ func (h *CombatHandler) ExecuteAction(c *gin.Context) {
 	}
 
 	var req models.ActionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+	if err := encoding.Bind(c, &req); err != nil {
+		c.JSON(encoding.StatusOf(err), gin.H{
 			"error":   "Invalid request format",
 			"details": err.Error(),
 		})
@@ -300,10 +301,10 @@ func (h *CombatHandler) ExecuteAction(c *gin.Context) {
 		statusCode = http.StatusBadRequest
 	}
 
-	c.JSON(statusCode, gin.H{
+	encoding.Render(c, statusCode, gin.H{
 		"success": result.Success,
 		"result":  result,
-	})
+	}, result)
 }
 
 // ValidateAction valide une action sans l'exécuter
@@ -728,9 +729,34 @@ func (h *CombatHandler) BanUser(c *gin.Context) {
 	})
 }
 
+// GetCombatStatusForService retourne l'état d'un combat et les utilisateurs qui y participent
+// Utilisé par le gateway pour n'abonner aux événements d'un combat que ses participants.
+// GET /api/v1/services/combat/:combatId/status (scope combat.read)
 func (h *CombatHandler) GetCombatStatusForService(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error": "Get combat status for service not implemented yet",
+	combatID, err := uuid.Parse(c.Param("combatId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid combat ID"})
+		return
+	}
+
+	combat, err := h.combatService.GetCombat(combatID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Combat not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(combat.Participants))
+	for _, participant := range combat.Participants {
+		userIDs = append(userIDs, participant.UserID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"combat_id": combat.ID,
+		"status":    combat.Status,
+		"user_ids":  userIDs,
 	})
 }
 
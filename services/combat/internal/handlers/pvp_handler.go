@@ -6,6 +6,7 @@ import (
 	"combat/internal/service"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -277,36 +278,75 @@ func (h *PvPHandler) CancelChallenge(c *gin.Context) {
 
 // GetRankings récupère les classements PvP
 // @Summary Classements PvP
-// @Description Récupère les classements PvP par saison
+// @Description Récupère les classements PvP par saison, file, classe et région
 // @Tags pvp
 // @Produce json
 // @Param season query string false "Saison (défaut: current)"
+// @Param type query string false "File de classement (défaut: arena)"
+// @Param class query string false "Classe"
+// @Param region query string false "Région"
 // @Param limit query int false "Nombre de résultats (défaut: 50, max: 100)"
 // @Param offset query int false "Décalage pour la pagination (défaut: 0)"
+// @Param player_id query string false "Joueur dont on veut le rang"
+// @Param around query int false "Nombre de joueurs autour de player_id (max: 50)"
+// @Param snapshot_id query string false "Snapshot historique"
 // @Success 200 {object} models.RankingsResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Router /api/v1/pvp/rankings [get]
 func (h *PvPHandler) GetRankings(c *gin.Context) {
-	req := models.GetRankingsRequest{
-		Season: c.DefaultQuery("season", "current"),
-		Limit:  config.DefaultImprovementScore,
-		Offset: 0,
+	req, ok := h.parseRankingsRequest(c)
+	if !ok {
+		return
 	}
 
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
-			req.Limit = limit
+	if playerIDStr := c.Query("player_id"); playerIDStr != "" {
+		playerID, err := uuid.Parse(playerIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "Invalid player ID",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
 		}
+		req.PlayerID = &playerID
 	}
 
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
-			req.Offset = offset
+	if aroundStr := c.Query("around"); aroundStr != "" {
+		around, err := strconv.Atoi(aroundStr)
+		if err != nil || around < 0 || around > config.DefaultLeaderboardMaxAroundWindow {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "Invalid around window",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+		req.Around = around
+	} else if req.PlayerID != nil {
+		req.Around = config.DefaultLeaderboardAroundWindow
+	}
+
+	if snapshotIDStr := c.Query("snapshot_id"); snapshotIDStr != "" {
+		snapshotID, err := uuid.Parse(snapshotIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "Invalid snapshot ID",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
 		}
+		req.SnapshotID = &snapshotID
 	}
 
-	rankings, err := h.pvpService.GetRankings(&req)
+	rankings, err := h.pvpService.GetRankings(req)
 	if err != nil {
+		if req.SnapshotID != nil && strings.Contains(err.Error(), "snapshot not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":      "Snapshot not found",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+
 		logrus.WithError(err).Error("Failed to get rankings")
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":      "Failed to retrieve rankings",
@@ -318,6 +358,74 @@ func (h *PvPHandler) GetRankings(c *gin.Context) {
 	c.JSON(http.StatusOK, rankings)
 }
 
+// GetRankingSnapshots liste les snapshots historiques d'un classement
+// @Summary Snapshots des classements PvP
+// @Description Liste les derniers snapshots d'un classement
+// @Tags pvp
+// @Produce json
+// @Param season query string false "Saison (défaut: current)"
+// @Param type query string false "File de classement (défaut: arena)"
+// @Param class query string false "Classe"
+// @Param region query string false "Région"
+// @Param limit query int false "Nombre de snapshots (défaut: 50, max: 100)"
+// @Success 200 {object} models.LeaderboardSnapshotsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/pvp/rankings/snapshots [get]
+func (h *PvPHandler) GetRankingSnapshots(c *gin.Context) {
+	req, ok := h.parseRankingsRequest(c)
+	if !ok {
+		return
+	}
+
+	snapshots, err := h.pvpService.GetRankingSnapshots(req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get ranking snapshots")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "Failed to retrieve ranking snapshots",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshots)
+}
+
+// parseRankingsRequest lit les paramètres communs des classements (saison, file, classe, région, pagination)
+func (h *PvPHandler) parseRankingsRequest(c *gin.Context) (*models.GetRankingsRequest, bool) {
+	req := &models.GetRankingsRequest{
+		Season: c.DefaultQuery("season", "current"),
+		Type:   models.ChallengeType(c.Query("type")),
+		Class:  c.Query("class"),
+		Region: c.Query("region"),
+		Limit:  config.DefaultImprovementScore,
+		Offset: 0,
+	}
+
+	switch req.Type {
+	case "", models.ChallengeTypeDuel, models.ChallengeTypeArena, models.ChallengeTypeTournament:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Invalid ranking type",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return nil, false
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			req.Limit = limit
+		}
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			req.Offset = offset
+		}
+	}
+
+	return req, true
+}
+
 // GetPvPStatistics récupère les statistiques PvP d'un joueur
 // @Summary Statistiques PvP
 // @Description Récupère les statistiques PvP d'un joueur
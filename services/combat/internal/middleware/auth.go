@@ -4,6 +4,7 @@ import (
 	"combat/internal/config"
 	"combat/internal/constants"
 	"fmt"
+	"mmorpg/pkg/jwks"
 	"net/http"
 	"strings"
 	"time"
@@ -14,6 +15,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// tokenTypeAccess type des tokens d'accès des joueurs (les tokens de service sont refusés)
+const tokenTypeAccess = "access"
+
 // JWTClaims représente les claims du JWT
 type JWTClaims struct {
 	UserID      string   `json:"user_id"`
@@ -21,11 +25,12 @@ type JWTClaims struct {
 	Username    string   `json:"username"`
 	Role        string   `json:"role"`
 	Permissions []string `json:"permissions"`
+	TokenType   string   `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
-// AuthMiddleware crée le middleware d'authentification JWT
-func AuthMiddleware(config *config.Config) gin.HandlerFunc {
+// AuthMiddleware crée le middleware d'authentification JWT (clés publiques du service auth)
+func AuthMiddleware(keys *jwks.KeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Récupérer le token depuis l'en-tête Authorization
 		authHeader := c.GetHeader("Authorization")
@@ -52,7 +57,7 @@ func AuthMiddleware(config *config.Config) gin.HandlerFunc {
 		tokenString := parts[1]
 
 		// Valider et parser le JWT
-		claims, err := validateJWT(tokenString, config.JWT.Secret)
+		claims, err := validateJWT(tokenString, keys)
 		if err != nil {
 			logrus.WithFields(logrus.Fields{
 				"error":      err.Error(),
@@ -104,7 +109,7 @@ func AuthMiddleware(config *config.Config) gin.HandlerFunc {
 }
 
 // OptionalAuthMiddleware permet l'authentification optionnelle
-func OptionalAuthMiddleware(config *config.Config) gin.HandlerFunc {
+func OptionalAuthMiddleware(keys *jwks.KeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -117,7 +122,7 @@ func OptionalAuthMiddleware(config *config.Config) gin.HandlerFunc {
 		parts := strings.SplitN(authHeader, " ", constants.AuthHeaderSplitParts)
 		if len(parts) == constants.AuthHeaderSplitParts && parts[0] == "Bearer" {
 			tokenString := parts[1]
-			if claims, err := validateJWT(tokenString, config.JWT.Secret); err == nil {
+			if claims, err := validateJWT(tokenString, keys); err == nil {
 				// Authentification réussie
 				c.Set("user_id", claims.UserID)
 				c.Set("character_id", claims.CharacterID)
@@ -332,14 +337,8 @@ func RateLimitByUser(requestsPerMinute int) gin.HandlerFunc {
 }
 
 // validateJWT valide et parse un token JWT
-func validateJWT(tokenString, secret string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Vérifier la méthode de signature
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
-	})
+func validateJWT(tokenString string, keys *jwks.KeySet) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, keys.Keyfunc, jwt.WithValidMethods(jwks.Algorithms()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
@@ -354,6 +353,10 @@ func validateJWT(tokenString, secret string) (*JWTClaims, error) {
 	}
 
 	// Validation supplémentaire des claims
+	if claims.TokenType != tokenTypeAccess {
+		return nil, fmt.Errorf("not an access token")
+	}
+
 	if claims.UserID == "" {
 		return nil, fmt.Errorf("missing user_id in token")
 	}
@@ -88,7 +88,8 @@ type SkillInfo struct {
 	ID           string             `json:"id"`
 	Name         string             `json:"name"`
 	Description  string             `json:"description"`
-	Type         string             `json:"type"` // "physical", "magical", "hybrid"
+	Type         string             `json:"type"`              // "physical", "magical", "hybrid"
+	Element      string             `json:"element,omitempty"` // "fire", "ice", "lightning"... (vide = arme)
 	ManaCost     int                `json:"mana_cost"`
 	Cooldown     int                `json:"cooldown"`
 	Range        int                `json:"range"`
@@ -320,6 +321,7 @@ func GetSkillTemplates() map[string]*SkillInfo {
 			Name:         "Boule de feu",
 			Description:  "Lance une boule de feu qui inflige des dégâts magiques",
 			Type:         DamageTypeMagical,
+			Element:      ElementFire,
 			ManaCost:     config.DefaultManaCostBasic,
 			Cooldown:     config.DefaultCooldownBasic,
 			Range:        config.DefaultRangeBasic,
@@ -361,6 +363,7 @@ func GetSkillTemplates() map[string]*SkillInfo {
 			Name:         "Éclair",
 			Description:  "Frappe l'ennemi avec un éclair rapide",
 			Type:         DamageTypeMagical,
+			Element:      ElementLightning,
 			ManaCost:     config.DefaultManaCostAdvanced,
 			Cooldown:     config.DefaultCooldownAdvanced,
 			Range:        config.DefaultRangeAdvanced,
@@ -424,6 +427,32 @@ func GetSkillTemplates() map[string]*SkillInfo {
 				"critical_multiplier":   config.DefaultCriticalMultiplier2,
 			},
 		},
+		"water_jet": {
+			ID:           "water_jet",
+			Name:         "Jet d'eau",
+			Description:  "Trempe l'ennemi, qui devient sensible à la foudre et au gel",
+			Type:         DamageTypeMagical,
+			Element:      ElementWater,
+			ManaCost:     config.DefaultManaCostBasic,
+			Cooldown:     config.DefaultCooldownBasic,
+			Range:        config.DefaultRangeBasic,
+			AreaOfEffect: false,
+			TargetType:   "enemy",
+			BaseDamage:   config.DefaultBaseDamageSpecial,
+			BaseHealing:  0,
+			Effects: []SkillEffect{
+				{
+					Type:         "debuff",
+					Duration:     config.DefaultEffectDuration,
+					Probability:  1,
+					Target:       "target",
+					StatAffected: ElementalStatStatus + ElementalStatusWet,
+				},
+			},
+			Icon:        "droplet",
+			Animation:   "water_jet_cast",
+			SoundEffect: "water_splash",
+		},
 	}
 }
 
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CharacterBaseStats représente les statistiques de base renvoyées par le service player
+type CharacterBaseStats struct {
+	Health    int `json:"health"`
+	MaxHealth int `json:"max_health"`
+	Mana      int `json:"mana"`
+	MaxMana   int `json:"max_mana"`
+
+	Strength     int `json:"strength"`
+	Agility      int `json:"agility"`
+	Intelligence int `json:"intelligence"`
+	Vitality     int `json:"vitality"`
+
+	PhysicalDamage  int `json:"physical_damage"`
+	MagicalDamage   int `json:"magical_damage"`
+	PhysicalDefense int `json:"physical_defense"`
+	MagicalDefense  int `json:"magical_defense"`
+	CriticalChance  int `json:"critical_chance"` // en pourcentage
+	AttackSpeed     int `json:"attack_speed"`    // en pourcentage
+}
+
+// CharacterStatModifier représente un modificateur actif (buff, debuff, équipement)
+type CharacterStatModifier struct {
+	ID        uuid.UUID  `json:"id"`
+	Type      string     `json:"type"`
+	Source    string     `json:"source"`
+	StatName  string     `json:"stat_name"`
+	Value     int        `json:"value"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// IsExpired vérifie si un modificateur est expiré
+func (m *CharacterStatModifier) IsExpired(now time.Time) bool {
+	return m.ExpiresAt != nil && now.After(*m.ExpiresAt)
+}
+
+// CharacterCombatProfile représente le profil d'un personnage côté service player
+type CharacterCombatProfile struct {
+	CharacterID uuid.UUID                `json:"character_id"`
+	PlayerID    uuid.UUID                `json:"player_id"`
+	Level       int                      `json:"level"`
+	Class       string                   `json:"class"`
+	Race        string                   `json:"race"`
+	BaseStats   *CharacterBaseStats      `json:"base_stats"`
+	Modifiers   []*CharacterStatModifier `json:"modifiers"`
+}
+
+// EquippedItem représente un objet équipé renvoyé par le service inventory
+type EquippedItem struct {
+	Slot     string                 `json:"slot"`
+	ItemID   uuid.UUID              `json:"item_id"`
+	Name     string                 `json:"name"`
+	ItemType string                 `json:"item_type"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// EquipmentStats représente les bonus d'équipement renvoyés par le service inventory
+type EquipmentStats struct {
+	CharacterID    uuid.UUID      `json:"character_id"`
+	TotalStats     map[string]int `json:"total_stats"`
+	CriticalChance float64        `json:"critical_chance"`
+	Items          []EquippedItem `json:"items"`
+}
+
+// HydratedStats représente les stats de combat calculées côté serveur pour un personnage
+type HydratedStats struct {
+	CharacterID uuid.UUID `json:"character_id"`
+	PlayerID    uuid.UUID `json:"player_id"`
+	Level       int       `json:"level"`
+	Class       string    `json:"class"`
+	Race        string    `json:"race"`
+
+	Health          int     `json:"health"`
+	MaxHealth       int     `json:"max_health"`
+	Mana            int     `json:"mana"`
+	MaxMana         int     `json:"max_mana"`
+	PhysicalDamage  int     `json:"physical_damage"`
+	MagicalDamage   int     `json:"magical_damage"`
+	PhysicalDefense int     `json:"physical_defense"`
+	MagicalDefense  int     `json:"magical_defense"`
+	CriticalChance  float64 `json:"critical_chance"`
+	AttackSpeed     float64 `json:"attack_speed"`
+
+	// Détail des sources pour le débogage et l'anti-triche
+	EquipmentBonuses map[string]int `json:"equipment_bonuses,omitempty"`
+	ModifierBonuses  map[string]int `json:"modifier_bonuses,omitempty"`
+	Equipment        []EquippedItem `json:"equipment,omitempty"`
+
+	// Profil élémentaire (race, équipement et modificateurs)
+	Elements *ElementalProfile `json:"elements,omitempty"`
+
+	HydratedAt time.Time `json:"hydrated_at"`
+}
+
+// ApplyTo copie les stats calculées sur un participant
+func (h *HydratedStats) ApplyTo(p *CombatParticipant) {
+	p.Health = h.Health
+	p.MaxHealth = h.MaxHealth
+	p.Mana = h.Mana
+	p.MaxMana = h.MaxMana
+	p.PhysicalDamage = h.PhysicalDamage
+	p.MagicalDamage = h.MagicalDamage
+	p.PhysicalDefense = h.PhysicalDefense
+	p.MagicalDefense = h.MagicalDefense
+	p.CriticalChance = h.CriticalChance
+	p.AttackSpeed = h.AttackSpeed
+	p.Elements = h.Elements.Clone()
+}
@@ -63,6 +63,16 @@ type CombatSettings struct {
 	CustomRules    map[string]interface{} `json:"custom_rules,omitempty"`
 }
 
+// HasCCImmunity vérifie si des immunités (modèle de NPC, ex: boss) couvrent une catégorie de contrôle
+func HasCCImmunity(immunities []string, category string) bool {
+	for _, immunity := range immunities {
+		if immunity == category || immunity == "all" {
+			return true
+		}
+	}
+	return false
+}
+
 // CombatParticipant représente un participant dans un combat
 type CombatParticipant struct {
 	ID          uuid.UUID `json:"id" db:"id"`
@@ -102,6 +112,7 @@ type CombatParticipant struct {
 	// Relations (chargées séparément)
 	Character     *CharacterSummary `json:"character,omitempty" db:"-"`
 	ActiveEffects []*CombatEffect   `json:"active_effects,omitempty" db:"-"`
+	Elements      *ElementalProfile `json:"elements,omitempty" db:"-"`
 }
 
 // CharacterSummary représente un résumé des informations d'un personnage
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// Catégories de contrôle (CC) soumises aux rendements décroissants
+const (
+	CCCategoryStun    = "stun"
+	CCCategorySilence = "silence"
+	CCCategoryRoot    = "root"
+	CCCategoryFear    = "fear"
+)
+
+// Sources d'immunité aux contrôles
+const (
+	ImmunitySourceDiminishingReturns = "diminishing_returns"
+	ImmunitySourceBoss               = "boss"
+)
+
+// GetCCCategory retourne la catégorie de contrôle d'un effet (vide si l'effet n'est pas un contrôle)
+func GetCCCategory(template *EffectTemplate) string {
+	if template.CCCategory != "" {
+		return template.CCCategory
+	}
+
+	switch template.EffectType {
+	case EffectTypeStun:
+		return CCCategoryStun
+	case EffectTypeSilence:
+		return CCCategorySilence
+	}
+
+	return ""
+}
+
+// DiminishingReturnsState représente l'état des rendements décroissants d'une cible pour une catégorie
+type DiminishingReturnsState struct {
+	Category           string     `json:"category"`
+	Level              int        `json:"level"` // nombre d'applications dans la fenêtre, celle-ci comprise
+	DurationMultiplier float64    `json:"duration_multiplier"`
+	Immune             bool       `json:"immune"`
+	ImmunitySource     string     `json:"immunity_source,omitempty"`
+	ResetsAt           *time.Time `json:"resets_at,omitempty"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CharacterLocation position d'un personnage et règles de sa zone, fournies par le service world
+type CharacterLocation struct {
+	CharacterID uuid.UUID `json:"character_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	ZoneID      string    `json:"zone_id"`
+	X           float64   `json:"x"`
+	Y           float64   `json:"y"`
+	Z           float64   `json:"z"`
+	IsOnline    bool      `json:"is_online"`
+	LastUpdate  time.Time `json:"last_update"`
+	IsPvPZone   bool      `json:"is_pvp_zone"`
+	IsSafeZone  bool      `json:"is_safe_zone"`
+}
+
+// DistanceTo retourne la distance entre deux positions
+func (l *CharacterLocation) DistanceTo(x, y, z float64) float64 {
+	dx, dy, dz := l.X-x, l.Y-y, l.Z-z
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// DuelBoundary zone dans laquelle doivent rester les participants d'un duel
+// Centrée sur le milieu des deux joueurs au moment où le duel est accepté
+type DuelBoundary struct {
+	ChallengeID  uuid.UUID `json:"challenge_id"`
+	CombatID     uuid.UUID `json:"combat_id"`
+	ChallengerID uuid.UUID `json:"challenger_id"`
+	ChallengedID uuid.UUID `json:"challenged_id"`
+	ZoneID       string    `json:"zone_id"`
+	CenterX      float64   `json:"center_x"`
+	CenterY      float64   `json:"center_y"`
+	CenterZ      float64   `json:"center_z"`
+	Radius       float64   `json:"radius"`
+	StartedAt    time.Time `json:"started_at"`
+}
+
+// NewDuelBoundary crée la zone de duel entre deux joueurs
+func NewDuelBoundary(challenge *PvPChallenge, combatID uuid.UUID, challenger, challenged *CharacterLocation, radius float64) *DuelBoundary {
+	return &DuelBoundary{
+		ChallengeID:  challenge.ID,
+		CombatID:     combatID,
+		ChallengerID: challenge.ChallengerID,
+		ChallengedID: challenge.ChallengedID,
+		ZoneID:       challenger.ZoneID,
+		CenterX:      (challenger.X + challenged.X) / 2,
+		CenterY:      (challenger.Y + challenged.Y) / 2,
+		CenterZ:      (challenger.Z + challenged.Z) / 2,
+		Radius:       radius,
+		StartedAt:    time.Now(),
+	}
+}
+
+// Contains vérifie qu'un joueur est toujours dans la zone de duel
+func (b *DuelBoundary) Contains(location *CharacterLocation) bool {
+	return location.IsOnline &&
+		location.ZoneID == b.ZoneID &&
+		location.DistanceTo(b.CenterX, b.CenterY, b.CenterZ) <= b.Radius
+}
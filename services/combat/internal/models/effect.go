@@ -82,6 +82,7 @@ type EffectTemplate struct {
 	IsBeneficial  bool                   `json:"is_beneficial"`
 	Tags          []string               `json:"tags,omitempty"`
 	Conditions    map[string]interface{} `json:"conditions,omitempty"`
+	CCCategory    string                 `json:"cc_category,omitempty"` // catégorie de rendements décroissants
 }
 
 // EffectApplication représente l'application d'un effet
@@ -100,9 +101,12 @@ type EffectResult struct {
 	Success        bool          `json:"success"`
 	Effect         *CombatEffect `json:"effect,omitempty"`
 	ExistingEffect *CombatEffect `json:"existing_effect,omitempty"`
-	Action         string        `json:"action"` // "applied", "stacked", "refreshed", "resisted"
+	Action         string        `json:"action"` // "applied", "stacked", "refreshed", "resisted", "immune"
 	Message        string        `json:"message,omitempty"`
 	Error          string        `json:"error,omitempty"`
+
+	// État des rendements décroissants pour les contrôles (stun, silence...)
+	DiminishingReturns *DiminishingReturnsState `json:"diminishing_returns,omitempty"`
 }
 
 // GetEffectTemplates retourne les modèles d'effets prédéfinis
@@ -176,6 +180,7 @@ func GetEffectTemplates() map[string]*EffectTemplate {
 			IsDispellable: true,
 			IsBeneficial:  false,
 			Tags:          []string{"debuff", "control"},
+			CCCategory:    CCCategoryStun,
 		},
 		"silence": {
 			ID:            "silence",
@@ -188,6 +193,7 @@ func GetEffectTemplates() map[string]*EffectTemplate {
 			IsDispellable: true,
 			IsBeneficial:  false,
 			Tags:          []string{"debuff", "control"},
+			CCCategory:    CCCategorySilence,
 		},
 		"weakness": {
 			ID:            "weakness",
@@ -219,6 +225,73 @@ func GetEffectTemplates() map[string]*EffectTemplate {
 			IsBeneficial:  true,
 			Tags:          []string{"buff", "speed"},
 		},
+		"wet": {
+			ID:            "wet",
+			Name:          "Trempé",
+			Description:   "La cible est trempée et conduit la foudre",
+			Icon:          "droplet",
+			EffectType:    EffectTypeDebuff,
+			StatAffected:  ElementalStatStatus + ElementalStatusWet,
+			BaseDuration:  config.DefaultBaseDuration2,
+			MaxStacks:     1,
+			IsDispellable: true,
+			IsBeneficial:  false,
+			Tags:          []string{"debuff", "elemental", ElementWater},
+		},
+		"chilled": {
+			ID:            "chilled",
+			Name:          "Transi",
+			Description:   "La cible est glacée et fragile face au feu",
+			Icon:          "snowflake",
+			EffectType:    EffectTypeDebuff,
+			StatAffected:  ElementalStatStatus + ElementalStatusChilled,
+			BaseDuration:  config.DefaultBaseDuration2,
+			MaxStacks:     1,
+			IsDispellable: true,
+			IsBeneficial:  false,
+			Tags:          []string{"debuff", "elemental", ElementIce},
+		},
+		"burning": {
+			ID:            "burning",
+			Name:          "Embrasé",
+			Description:   "La cible est en feu, le vent attise les flammes",
+			Icon:          "flame",
+			EffectType:    EffectTypeDebuff,
+			StatAffected:  ElementalStatStatus + ElementalStatusBurning,
+			BaseDuration:  config.DefaultBaseDuration2,
+			MaxStacks:     1,
+			IsDispellable: true,
+			IsBeneficial:  false,
+			Tags:          []string{"debuff", "elemental", ElementFire},
+		},
+		"shocked": {
+			ID:            "shocked",
+			Name:          "Électrisé",
+			Description:   "La cible est chargée et entre en surcharge au contact du feu",
+			Icon:          "zap",
+			EffectType:    EffectTypeDebuff,
+			StatAffected:  ElementalStatStatus + ElementalStatusShocked,
+			BaseDuration:  config.DefaultBaseDuration2,
+			MaxStacks:     1,
+			IsDispellable: true,
+			IsBeneficial:  false,
+			Tags:          []string{"debuff", "elemental", ElementLightning},
+		},
+		"fire_ward": {
+			ID:            "fire_ward",
+			Name:          "Protection ignifuge",
+			Description:   "Augmente la résistance au feu",
+			Icon:          "fire-shield",
+			EffectType:    EffectTypeBuff,
+			StatAffected:  ElementalStatResistance + ElementFire,
+			ModifierValue: config.DefaultModifierValue5,
+			ModifierType:  ModifierTypePercentage,
+			BaseDuration:  config.DefaultBaseDuration,
+			MaxStacks:     config.DefaultMaxStacks4,
+			IsDispellable: true,
+			IsBeneficial:  true,
+			Tags:          []string{"buff", "elemental", ElementFire},
+		},
 	}
 }
 
@@ -0,0 +1,376 @@
+package models
+
+import (
+	"combat/internal/config"
+	"strings"
+)
+
+// Éléments disponibles pour les compétences, les armes et les résistances
+const (
+	ElementNone      = ""
+	ElementFire      = "fire"
+	ElementIce       = "ice"
+	ElementLightning = "lightning"
+	ElementEarth     = "earth"
+	ElementWind      = "wind"
+	ElementWater     = "water"
+	ElementDark      = "dark"
+	ElementLight     = "light"
+)
+
+// Préfixes des stats élémentaires portées par les effets actifs
+// (ex: StatAffected = "resistance_fire", ModifierValue = 20 pour +20%)
+const (
+	ElementalStatAffinity      = "affinity_"
+	ElementalStatResistance    = "resistance_"
+	ElementalStatVulnerability = "vulnerability_"
+	ElementalStatPenetration   = "penetration_"
+	ElementalStatStatus        = "status_"
+)
+
+// Statuts élémentaires pouvant déclencher une réaction
+const (
+	ElementalStatusWet      = "wet"
+	ElementalStatusBurning  = "burning"
+	ElementalStatusChilled  = "chilled"
+	ElementalStatusShocked  = "shocked"
+	ElementalStatusGrounded = "grounded"
+)
+
+// ElementAll clé s'appliquant à tous les éléments (ex: pénétration universelle)
+const ElementAll = "all"
+
+// IsValidElement vérifie si un élément est connu
+func IsValidElement(element string) bool {
+	switch element {
+	case ElementFire, ElementIce, ElementLightning, ElementEarth,
+		ElementWind, ElementWater, ElementDark, ElementLight:
+		return true
+	}
+	return false
+}
+
+// GetElementalPower retourne la puissance de base d'un élément
+func GetElementalPower(element string) float64 {
+	switch element {
+	case ElementFire:
+		return config.DefaultElementalPowerFire
+	case ElementIce:
+		return config.DefaultElementalPowerIce
+	case ElementLightning:
+		return config.DefaultElementalPowerLightning
+	case ElementEarth:
+		return config.DefaultElementalPowerEarth
+	case ElementWind:
+		return config.DefaultElementalPowerWind
+	case ElementWater:
+		return config.DefaultElementalPowerWater
+	case ElementDark:
+		return config.DefaultElementalPowerDark
+	case ElementLight:
+		return config.DefaultElementalPowerLight
+	}
+	return 0
+}
+
+// ElementalProfile représente les affinités et résistances élémentaires d'un participant
+// Toutes les valeurs sont des fractions (0.2 = 20%)
+type ElementalProfile struct {
+	WeaponElement   string             `json:"weapon_element,omitempty"`
+	Affinities      map[string]float64 `json:"affinities,omitempty"`
+	Resistances     map[string]float64 `json:"resistances,omitempty"`
+	Vulnerabilities map[string]float64 `json:"vulnerabilities,omitempty"`
+	Penetration     map[string]float64 `json:"penetration,omitempty"`
+	Statuses        map[string]bool    `json:"statuses,omitempty"`
+}
+
+// NewElementalProfile crée un profil élémentaire vide
+func NewElementalProfile() *ElementalProfile {
+	return &ElementalProfile{
+		Affinities:      make(map[string]float64),
+		Resistances:     make(map[string]float64),
+		Vulnerabilities: make(map[string]float64),
+		Penetration:     make(map[string]float64),
+		Statuses:        make(map[string]bool),
+	}
+}
+
+// Clone retourne une copie indépendante du profil
+func (p *ElementalProfile) Clone() *ElementalProfile {
+	clone := NewElementalProfile()
+	if p == nil {
+		return clone
+	}
+	clone.Merge(p)
+	clone.WeaponElement = p.WeaponElement
+	return clone
+}
+
+// Merge additionne les valeurs d'un autre profil à celui-ci
+func (p *ElementalProfile) Merge(other *ElementalProfile) {
+	if other == nil {
+		return
+	}
+	if p.WeaponElement == ElementNone {
+		p.WeaponElement = other.WeaponElement
+	}
+	for element, value := range other.Affinities {
+		p.Affinities[element] += value
+	}
+	for element, value := range other.Resistances {
+		p.Resistances[element] += value
+	}
+	for element, value := range other.Vulnerabilities {
+		p.Vulnerabilities[element] += value
+	}
+	for element, value := range other.Penetration {
+		p.Penetration[element] += value
+	}
+	for status, active := range other.Statuses {
+		if active {
+			p.Statuses[status] = true
+		}
+	}
+}
+
+// ApplyStat applique une stat élémentaire ("resistance_fire", "status_wet"...) au profil
+// Retourne false si la stat n'est pas élémentaire
+func (p *ElementalProfile) ApplyStat(stat string, value float64) bool {
+	switch {
+	case strings.HasPrefix(stat, ElementalStatAffinity):
+		p.Affinities[strings.TrimPrefix(stat, ElementalStatAffinity)] += value
+	case strings.HasPrefix(stat, ElementalStatResistance):
+		p.Resistances[strings.TrimPrefix(stat, ElementalStatResistance)] += value
+	case strings.HasPrefix(stat, ElementalStatVulnerability):
+		p.Vulnerabilities[strings.TrimPrefix(stat, ElementalStatVulnerability)] += value
+	case strings.HasPrefix(stat, ElementalStatPenetration):
+		p.Penetration[strings.TrimPrefix(stat, ElementalStatPenetration)] += value
+	case strings.HasPrefix(stat, ElementalStatStatus):
+		p.Statuses[strings.TrimPrefix(stat, ElementalStatStatus)] = true
+	default:
+		return false
+	}
+	return true
+}
+
+// Affinity retourne le bonus de puissance d'un élément
+func (p *ElementalProfile) Affinity(element string) float64 {
+	if p == nil {
+		return 0
+	}
+	return p.Affinities[element] + p.Affinities[ElementAll]
+}
+
+// Resistance retourne la résistance à un élément
+func (p *ElementalProfile) Resistance(element string) float64 {
+	if p == nil {
+		return 0
+	}
+	return p.Resistances[element] + p.Resistances[ElementAll]
+}
+
+// Vulnerability retourne la vulnérabilité à un élément
+func (p *ElementalProfile) Vulnerability(element string) float64 {
+	if p == nil {
+		return 0
+	}
+	return p.Vulnerabilities[element] + p.Vulnerabilities[ElementAll]
+}
+
+// PenetrationFor retourne la pénétration de résistance pour un élément
+func (p *ElementalProfile) PenetrationFor(element string) float64 {
+	if p == nil {
+		return 0
+	}
+	return p.Penetration[element] + p.Penetration[ElementAll]
+}
+
+// HasStatus vérifie si un statut élémentaire est actif
+func (p *ElementalProfile) HasStatus(status string) bool {
+	return p != nil && p.Statuses[status]
+}
+
+// ElementalReaction représente une réaction entre un statut de la cible et l'élément reçu
+type ElementalReaction struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	Status         string  `json:"status"`
+	Element        string  `json:"element"`
+	Multiplier     float64 `json:"multiplier"`
+	ConsumesStatus bool    `json:"consumes_status"`
+}
+
+// GetElementalReactions retourne les réactions élémentaires prédéfinies
+func GetElementalReactions() []*ElementalReaction {
+	return []*ElementalReaction{
+		{
+			ID:             "electrocute",
+			Name:           "Électrocution",
+			Status:         ElementalStatusWet,
+			Element:        ElementLightning,
+			Multiplier:     config.DefaultReactionMultiplierMajor,
+			ConsumesStatus: true,
+		},
+		{
+			ID:             "freeze",
+			Name:           "Gel",
+			Status:         ElementalStatusWet,
+			Element:        ElementIce,
+			Multiplier:     config.DefaultReactionMultiplierMinor,
+			ConsumesStatus: true,
+		},
+		{
+			ID:             "steam",
+			Name:           "Vapeur",
+			Status:         ElementalStatusWet,
+			Element:        ElementFire,
+			Multiplier:     config.DefaultReactionMultiplierWeak,
+			ConsumesStatus: true,
+		},
+		{
+			ID:             "melt",
+			Name:           "Fonte",
+			Status:         ElementalStatusChilled,
+			Element:        ElementFire,
+			Multiplier:     config.DefaultReactionMultiplierMajor,
+			ConsumesStatus: true,
+		},
+		{
+			ID:             "wildfire",
+			Name:           "Embrasement",
+			Status:         ElementalStatusBurning,
+			Element:        ElementWind,
+			Multiplier:     config.DefaultReactionMultiplierMinor,
+			ConsumesStatus: false,
+		},
+		{
+			ID:             "overload",
+			Name:           "Surcharge",
+			Status:         ElementalStatusShocked,
+			Element:        ElementFire,
+			Multiplier:     config.DefaultReactionMultiplierMinor,
+			ConsumesStatus: true,
+		},
+		{
+			ID:             "shatter",
+			Name:           "Fracture",
+			Status:         ElementalStatusChilled,
+			Element:        ElementEarth,
+			Multiplier:     config.DefaultReactionMultiplierMinor,
+			ConsumesStatus: true,
+		},
+	}
+}
+
+// FindElementalReaction retourne la réaction déclenchée par un élément sur un profil
+func FindElementalReaction(target *ElementalProfile, element string) *ElementalReaction {
+	if target == nil || element == ElementNone {
+		return nil
+	}
+	for _, reaction := range GetElementalReactions() {
+		if reaction.Element == element && target.HasStatus(reaction.Status) {
+			return reaction
+		}
+	}
+	return nil
+}
+
+// GetRaceElementalProfiles retourne les affinités élémentaires innées par race
+func GetRaceElementalProfiles() map[string]*ElementalProfile {
+	return map[string]*ElementalProfile{
+		"human": {
+			Resistances: map[string]float64{ElementAll: config.DefaultRaceResistanceMinor},
+		},
+		"elf": {
+			Affinities:      map[string]float64{ElementLight: config.DefaultRaceAffinity},
+			Resistances:     map[string]float64{ElementLight: config.DefaultRaceResistance, ElementWind: config.DefaultRaceResistance},
+			Vulnerabilities: map[string]float64{ElementDark: config.DefaultRaceVulnerability},
+		},
+		"dwarf": {
+			Affinities:      map[string]float64{ElementEarth: config.DefaultRaceAffinity},
+			Resistances:     map[string]float64{ElementEarth: config.DefaultRaceResistance, ElementFire: config.DefaultRaceResistance},
+			Vulnerabilities: map[string]float64{ElementWind: config.DefaultRaceVulnerability},
+		},
+		"orc": {
+			Affinities:      map[string]float64{ElementFire: config.DefaultRaceAffinity},
+			Resistances:     map[string]float64{ElementFire: config.DefaultRaceResistance, ElementDark: config.DefaultRaceResistance},
+			Vulnerabilities: map[string]float64{ElementIce: config.DefaultRaceVulnerability},
+		},
+	}
+}
+
+// BuildElementalProfileFromEquipment construit le profil élémentaire apporté par l'équipement
+// Métadonnées lues sur chaque objet: "element" (arme), "affinities", "resistances",
+// "vulnerabilities" et "penetration" (map élément -> pourcentage)
+func BuildElementalProfileFromEquipment(items []EquippedItem) *ElementalProfile {
+	profile := NewElementalProfile()
+
+	for i := range items {
+		item := &items[i]
+		if item.Metadata == nil {
+			continue
+		}
+
+		if element, ok := item.Metadata["element"].(string); ok && IsValidElement(element) {
+			if item.Slot == "main_hand" || profile.WeaponElement == ElementNone {
+				profile.WeaponElement = element
+			}
+		}
+
+		mergePercentMap(profile.Affinities, item.Metadata["affinities"])
+		mergePercentMap(profile.Resistances, item.Metadata["resistances"])
+		mergePercentMap(profile.Vulnerabilities, item.Metadata["vulnerabilities"])
+		mergePercentMap(profile.Penetration, item.Metadata["penetration"])
+	}
+
+	return profile
+}
+
+// BuildElementalProfileFromEffects construit le profil élémentaire apporté par les effets actifs
+func BuildElementalProfileFromEffects(effects []*CombatEffect) *ElementalProfile {
+	profile := NewElementalProfile()
+
+	for _, effect := range effects {
+		if effect == nil || !effect.IsActive || effect.StatAffected == nil {
+			continue
+		}
+		stat, value, _ := effect.GetStatModifier()
+		profile.ApplyStat(stat, float64(value)/config.DefaultPercentDivisor)
+	}
+
+	return profile
+}
+
+// ConsumeElementalStatus désactive les effets actifs portant un statut élémentaire
+// (consommé par une réaction) et retourne les effets retirés
+func ConsumeElementalStatus(effects []*CombatEffect, status string) []*CombatEffect {
+	stat := ElementalStatStatus + status
+	var consumed []*CombatEffect
+
+	for _, effect := range effects {
+		if effect == nil || !effect.IsActive || effect.StatAffected == nil || *effect.StatAffected != stat {
+			continue
+		}
+		effect.IsActive = false
+		effect.RemainingTurns = 0
+		consumed = append(consumed, effect)
+	}
+
+	return consumed
+}
+
+// mergePercentMap ajoute une map JSON élément -> pourcentage à une map de fractions
+func mergePercentMap(target map[string]float64, raw interface{}) {
+	values, ok := raw.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for element, value := range values {
+		if element != ElementAll && !IsValidElement(element) {
+			continue
+		}
+		if percent, ok := value.(float64); ok {
+			target[element] += percent / config.DefaultPercentDivisor
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"combat/internal/config"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LadderKey identifie un classement: saison, file, et éventuellement classe et région
+// (Class et Region vides = classement global de la file)
+type LadderKey struct {
+	Season    string        `json:"season"`
+	QueueType ChallengeType `json:"queue_type"`
+	Class     string        `json:"class,omitempty"`
+	Region    string        `json:"region,omitempty"`
+}
+
+// String retourne l'identifiant lisible du classement
+func (k LadderKey) String() string {
+	class := k.Class
+	if class == "" {
+		class = "all"
+	}
+	region := k.Region
+	if region == "" {
+		region = "all"
+	}
+	return fmt.Sprintf("%s:%s:%s:%s", k.Season, k.QueueType, class, region)
+}
+
+// LadderEntry représente la ligne d'un joueur dans les classements d'une saison et d'une file
+// (table pvp_ladder_entries, source de vérité des classements)
+type LadderEntry struct {
+	CharacterID uuid.UUID     `json:"character_id" db:"character_id"`
+	Season      string        `json:"season" db:"season"`
+	QueueType   ChallengeType `json:"queue_type" db:"queue_type"`
+	Class       string        `json:"class" db:"class"`
+	Region      string        `json:"region" db:"region"`
+	Rating      int           `json:"rating" db:"rating"`
+	Wins        int           `json:"wins" db:"wins"`
+	Losses      int           `json:"losses" db:"losses"`
+	Draws       int           `json:"draws" db:"draws"`
+	Streak      int           `json:"streak" db:"streak"`
+	LastMatchAt *time.Time    `json:"last_match_at" db:"last_match_at"`
+	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// Keys retourne les classements auxquels appartient l'entrée (global, classe, région, classe+région)
+func (e *LadderEntry) Keys() []LadderKey {
+	keys := []LadderKey{{Season: e.Season, QueueType: e.QueueType}}
+	if e.Class != "" {
+		keys = append(keys, LadderKey{Season: e.Season, QueueType: e.QueueType, Class: e.Class})
+	}
+	if e.Region != "" {
+		keys = append(keys, LadderKey{Season: e.Season, QueueType: e.QueueType, Region: e.Region})
+	}
+	if e.Class != "" && e.Region != "" {
+		keys = append(keys, LadderKey{Season: e.Season, QueueType: e.QueueType, Class: e.Class, Region: e.Region})
+	}
+	return keys
+}
+
+// ToRanking convertit l'entrée en ligne de classement
+func (e *LadderEntry) ToRanking(rank int) *PvPRanking {
+	ranking := &PvPRanking{
+		Rank:      rank,
+		PlayerID:  e.CharacterID,
+		Rating:    e.Rating,
+		Wins:      e.Wins,
+		Losses:    e.Losses,
+		Draws:     e.Draws,
+		Streak:    e.Streak,
+		LastMatch: e.LastMatchAt,
+	}
+
+	if total := e.Wins + e.Losses + e.Draws; total > 0 {
+		ranking.WinRate = float64(e.Wins) / float64(total) * config.DefaultPercentageMultiplier
+	}
+
+	return ranking
+}
+
+// LeaderboardSnapshot représente une photo d'un classement à un instant donné
+type LeaderboardSnapshot struct {
+	ID           uuid.UUID     `json:"id" db:"id"`
+	Season       string        `json:"season" db:"season"`
+	QueueType    ChallengeType `json:"queue_type" db:"queue_type"`
+	Class        string        `json:"class" db:"class"`
+	Region       string        `json:"region" db:"region"`
+	TotalPlayers int           `json:"total_players" db:"total_players"`
+	TakenAt      time.Time     `json:"taken_at" db:"taken_at"`
+}
+
+// LeaderboardSnapshotsResponse représente la liste des snapshots d'un classement
+type LeaderboardSnapshotsResponse struct {
+	Ladder    string                 `json:"ladder"`
+	Snapshots []*LeaderboardSnapshot `json:"snapshots"`
+}
@@ -0,0 +1,218 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"mmorpg/pkg/protocodec"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Numéros de champs protobuf de Vector3 (api/proto/game.proto du gateway)
+const (
+	vectorX protowire.Number = iota + 1
+	vectorY
+	vectorZ
+)
+
+// Numéros de champs protobuf de ActionRequest
+const (
+	actionRequestActionType protowire.Number = iota + 1
+	actionRequestTargetID
+	actionRequestSkillID
+	actionRequestItemID
+	actionRequestClientTimestamp
+	actionRequestPosition
+	actionRequestMetadata
+)
+
+// Numéros de champs protobuf de ActionResult
+const (
+	actionResultSuccess protowire.Number = iota + 1
+	actionResultError
+	actionResultMessage
+	actionResultWarnings
+	actionResultAction
+	actionResultParticipantChanges
+	actionResultCombatChange
+)
+
+// Numéros de champs protobuf de CombatAction
+const (
+	combatActionID protowire.Number = iota + 1
+	combatActionCombatID
+	combatActionActorID
+	combatActionTargetID
+	combatActionActionType
+	combatActionSkillID
+	combatActionItemID
+	combatActionDamageDealt
+	combatActionHealingDone
+	combatActionManaUsed
+	combatActionIsCritical
+	combatActionIsMiss
+	combatActionIsBlocked
+	combatActionTurnNumber
+	combatActionActionOrder
+	combatActionServerTimestamp
+)
+
+// Numéros de champs protobuf de ParticipantChange
+const (
+	participantChangeParticipantID protowire.Number = iota + 1
+	participantChangeHealthChange
+	participantChangeManaChange
+	participantChangeStatusChange
+)
+
+// Numéros de champs protobuf de CombatChange
+const (
+	combatChangeTurnAdvanced protowire.Number = iota + 1
+	combatChangeStatus
+	combatChangeWinner
+	combatChangeNextActionTime
+)
+
+// MarshalProto encode la position en protobuf (Vector3)
+func (p *Position) MarshalProto() []byte {
+	var b []byte
+	b = protocodec.AppendDouble(b, vectorX, p.X)
+	b = protocodec.AppendDouble(b, vectorY, p.Y)
+	return protocodec.AppendDouble(b, vectorZ, p.Z)
+}
+
+// UnmarshalProto décode la position depuis le protobuf (Vector3)
+func (p *Position) UnmarshalProto(data []byte) error {
+	return protocodec.Read(data, func(f *protocodec.Field) error {
+		switch f.Number {
+		case vectorX:
+			p.X = f.Double()
+		case vectorY:
+			p.Y = f.Double()
+		case vectorZ:
+			p.Z = f.Double()
+		}
+		return nil
+	})
+}
+
+// MarshalProto encode la demande d'action en protobuf
+func (r *ActionRequest) MarshalProto() []byte {
+	var b []byte
+	b = protocodec.AppendString(b, actionRequestActionType, string(r.ActionType))
+	if r.TargetID != nil {
+		b = protocodec.AppendUUID(b, actionRequestTargetID, *r.TargetID)
+	}
+	b = protocodec.AppendOptionalString(b, actionRequestSkillID, r.SkillID)
+	b = protocodec.AppendOptionalString(b, actionRequestItemID, r.ItemID)
+	b = protocodec.AppendTime(b, actionRequestClientTimestamp, r.ClientTimestamp)
+	if r.Position != nil {
+		b = protocodec.AppendMessage(b, actionRequestPosition, r.Position)
+	}
+	if len(r.Metadata) > 0 {
+		if metadata, err := json.Marshal(r.Metadata); err == nil {
+			b = protocodec.AppendBytes(b, actionRequestMetadata, metadata)
+		}
+	}
+	return b
+}
+
+// UnmarshalProto décode la demande d'action depuis le protobuf
+func (r *ActionRequest) UnmarshalProto(data []byte) error {
+	return protocodec.Read(data, func(f *protocodec.Field) error {
+		switch f.Number {
+		case actionRequestActionType:
+			r.ActionType = ActionType(f.String())
+		case actionRequestTargetID:
+			targetID, err := f.UUID()
+			if err != nil {
+				return err
+			}
+			r.TargetID = &targetID
+		case actionRequestSkillID:
+			skillID := f.String()
+			r.SkillID = &skillID
+		case actionRequestItemID:
+			itemID := f.String()
+			r.ItemID = &itemID
+		case actionRequestClientTimestamp:
+			r.ClientTimestamp = f.Time()
+		case actionRequestPosition:
+			r.Position = &Position{}
+			return r.Position.UnmarshalProto(f.Bytes())
+		case actionRequestMetadata:
+			if err := json.Unmarshal(f.Bytes(), &r.Metadata); err != nil {
+				return fmt.Errorf("invalid action metadata: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// MarshalProto encode le résultat de l'action en protobuf
+// Les effets détaillés et le journal du tour ne font pas partie du schéma protobuf.
+func (r *ActionResult) MarshalProto() []byte {
+	var b []byte
+	b = protocodec.AppendBool(b, actionResultSuccess, r.Success)
+	b = protocodec.AppendString(b, actionResultError, r.Error)
+	b = protocodec.AppendString(b, actionResultMessage, r.Message)
+	b = protocodec.AppendRepeatedString(b, actionResultWarnings, r.Warnings)
+	if r.Action != nil {
+		b = protocodec.AppendMessage(b, actionResultAction, r.Action)
+	}
+	if r.StateChanges != nil {
+		for participantID, change := range r.StateChanges.ParticipantChanges {
+			if change == nil {
+				continue
+			}
+			var message []byte
+			message = protocodec.AppendUUID(message, participantChangeParticipantID, participantID)
+			message = protocodec.AppendSint(message, participantChangeHealthChange, change.HealthChange)
+			message = protocodec.AppendSint(message, participantChangeManaChange, change.ManaChange)
+			message = protocodec.AppendString(message, participantChangeStatusChange, change.StatusChange)
+			b = protowire.AppendTag(b, actionResultParticipantChanges, protowire.BytesType)
+			b = protowire.AppendBytes(b, message)
+		}
+		if r.StateChanges.CombatChange != nil {
+			b = protocodec.AppendMessage(b, actionResultCombatChange, r.StateChanges.CombatChange)
+		}
+	}
+	return b
+}
+
+// MarshalProto encode l'action exécutée en protobuf
+func (a *CombatAction) MarshalProto() []byte {
+	var b []byte
+	b = protocodec.AppendUUID(b, combatActionID, a.ID)
+	b = protocodec.AppendUUID(b, combatActionCombatID, a.CombatID)
+	b = protocodec.AppendUUID(b, combatActionActorID, a.ActorID)
+	if a.TargetID != nil {
+		b = protocodec.AppendUUID(b, combatActionTargetID, *a.TargetID)
+	}
+	b = protocodec.AppendString(b, combatActionActionType, string(a.ActionType))
+	b = protocodec.AppendOptionalString(b, combatActionSkillID, a.SkillID)
+	b = protocodec.AppendOptionalString(b, combatActionItemID, a.ItemID)
+	b = protocodec.AppendInt64(b, combatActionDamageDealt, int64(a.DamageDealt))
+	b = protocodec.AppendInt64(b, combatActionHealingDone, int64(a.HealingDone))
+	b = protocodec.AppendInt64(b, combatActionManaUsed, int64(a.ManaUsed))
+	b = protocodec.AppendBool(b, combatActionIsCritical, a.IsCritical)
+	b = protocodec.AppendBool(b, combatActionIsMiss, a.IsMiss)
+	b = protocodec.AppendBool(b, combatActionIsBlocked, a.IsBlocked)
+	b = protocodec.AppendInt64(b, combatActionTurnNumber, int64(a.TurnNumber))
+	b = protocodec.AppendInt64(b, combatActionActionOrder, int64(a.ActionOrder))
+	return protocodec.AppendTime(b, combatActionServerTimestamp, a.ServerTimestamp)
+}
+
+// MarshalProto encode les changements du combat en protobuf
+func (c *CombatChange) MarshalProto() []byte {
+	var b []byte
+	b = protocodec.AppendBool(b, combatChangeTurnAdvanced, c.TurnAdvanced)
+	if c.StatusChanged != nil {
+		b = protocodec.AppendString(b, combatChangeStatus, string(*c.StatusChanged))
+	}
+	b = protocodec.AppendOptionalInt(b, combatChangeWinner, c.Winner)
+	if c.NextActionTime != nil {
+		b = protocodec.AppendTime(b, combatChangeNextActionTime, *c.NextActionTime)
+	}
+	return b
+}
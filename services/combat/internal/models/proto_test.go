@@ -0,0 +1,294 @@
+package models
+
+import (
+	"mmorpg/pkg/protocodec"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// newTestActionRequest demande d'action avec tous les champs du schéma renseignés
+func newTestActionRequest() *ActionRequest {
+	targetID := uuid.MustParse("6f1c2d3e-4b5a-4c6d-8e7f-9a0b1c2d3e4f")
+	skillID := "fireball"
+	itemID := "potion_small"
+	return &ActionRequest{
+		ActionType:      ActionTypeSkill,
+		TargetID:        &targetID,
+		SkillID:         &skillID,
+		ItemID:          &itemID,
+		ClientTimestamp: time.UnixMilli(1700000000123).UTC(),
+		Position:        &Position{X: 12.5, Y: -3.25, Z: 0.75},
+		Metadata:        map[string]interface{}{"combo": "fire", "charge": 0.5},
+	}
+}
+
+// newTestActionResult résultat d'action avec tous les champs du schéma renseignés
+func newTestActionResult() *ActionResult {
+	targetID := uuid.MustParse("6f1c2d3e-4b5a-4c6d-8e7f-9a0b1c2d3e4f")
+	skillID := "fireball"
+	status := CombatStatusFinished
+	winner := 0
+	next := time.UnixMilli(1700000001000).UTC()
+	return &ActionResult{
+		Success:  true,
+		Message:  "Action executed",
+		Warnings: []string{"low mana", "cooldown"},
+		Action: &CombatAction{
+			ID:              uuid.MustParse("0a1b2c3d-4e5f-4a6b-8c7d-8e9f0a1b2c3d"),
+			CombatID:        uuid.MustParse("1b2c3d4e-5f6a-4b7c-9d8e-9f0a1b2c3d4e"),
+			ActorID:         uuid.MustParse("2c3d4e5f-6a7b-4c8d-8e9f-0a1b2c3d4e5f"),
+			TargetID:        &targetID,
+			ActionType:      ActionTypeSkill,
+			SkillID:         &skillID,
+			DamageDealt:     42,
+			ManaUsed:        15,
+			IsCritical:      true,
+			TurnNumber:      3,
+			ActionOrder:     2,
+			ServerTimestamp: time.UnixMilli(1700000000456).UTC(),
+		},
+		StateChanges: &StateChanges{
+			ParticipantChanges: map[uuid.UUID]*ParticipantChange{
+				targetID: {HealthChange: -42, ManaChange: 5, StatusChange: "dead"},
+			},
+			CombatChange: &CombatChange{
+				TurnAdvanced:   true,
+				StatusChanged:  &status,
+				Winner:         &winner,
+				NextActionTime: &next,
+			},
+		},
+	}
+}
+
+func TestActionRequestProtoRoundTrip(t *testing.T) {
+	request := newTestActionRequest()
+
+	var decoded ActionRequest
+	if err := decoded.UnmarshalProto(request.MarshalProto()); err != nil {
+		t.Fatalf("UnmarshalProto: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, request) {
+		t.Fatalf("round trip mismatch:\n got  %+v\n want %+v", decoded, *request)
+	}
+}
+
+func TestActionRequestProtoOmitsDefaults(t *testing.T) {
+	request := &ActionRequest{ActionType: ActionTypeWait}
+
+	var decoded ActionRequest
+	if err := decoded.UnmarshalProto(request.MarshalProto()); err != nil {
+		t.Fatalf("UnmarshalProto: %v", err)
+	}
+	if decoded.TargetID != nil || decoded.SkillID != nil || decoded.ItemID != nil || decoded.Position != nil || decoded.Metadata != nil {
+		t.Fatalf("unset fields decoded as present: %+v", decoded)
+	}
+	if decoded.ActionType != ActionTypeWait {
+		t.Fatalf("action type = %q, want %q", decoded.ActionType, ActionTypeWait)
+	}
+}
+
+// TestActionRequestProtoSchema décode un message construit avec les numéros de game.proto
+func TestActionRequestProtoSchema(t *testing.T) {
+	want := newTestActionRequest()
+	targetID := *want.TargetID
+
+	var position []byte
+	position = protowire.AppendTag(position, 1, protowire.Fixed64Type)
+	position = protowire.AppendFixed64(position, 0x4029000000000000) // 12.5
+	position = protowire.AppendTag(position, 2, protowire.Fixed64Type)
+	position = protowire.AppendFixed64(position, 0xc00a000000000000) // -3.25
+	position = protowire.AppendTag(position, 3, protowire.Fixed64Type)
+	position = protowire.AppendFixed64(position, 0x3fe8000000000000) // 0.75
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, "skill")
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, targetID[:])
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendString(b, "fireball")
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	b = protowire.AppendString(b, "potion_small")
+	b = protowire.AppendTag(b, 5, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1700000000123)
+	b = protowire.AppendTag(b, 6, protowire.BytesType)
+	b = protowire.AppendBytes(b, position)
+	b = protowire.AppendTag(b, 7, protowire.BytesType)
+	b = protowire.AppendString(b, `{"charge":0.5,"combo":"fire"}`)
+	// Champ inconnu d'une version plus récente du schéma : ignoré
+	b = protowire.AppendTag(b, 99, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1)
+
+	var decoded ActionRequest
+	if err := decoded.UnmarshalProto(b); err != nil {
+		t.Fatalf("UnmarshalProto: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, want) {
+		t.Fatalf("schema mismatch:\n got  %+v\n want %+v", decoded, *want)
+	}
+}
+
+func TestActionRequestProtoInvalid(t *testing.T) {
+	var b []byte
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, []byte{1, 2, 3})
+
+	var decoded ActionRequest
+	if err := decoded.UnmarshalProto(b); err == nil {
+		t.Fatal("expected an error for a truncated target_id")
+	}
+	if err := decoded.UnmarshalProto([]byte{0x0a, 0x05, 'a'}); err == nil {
+		t.Fatal("expected an error for a truncated message")
+	}
+}
+
+// TestActionResultProtoSchema relit le résultat encodé avec les numéros de game.proto
+func TestActionResultProtoSchema(t *testing.T) {
+	result := newTestActionResult()
+	targetID := *result.Action.TargetID
+
+	var warnings []string
+	var action, participant, combatChange []byte
+	err := protocodec.Read(result.MarshalProto(), func(f *protocodec.Field) error {
+		switch f.Number {
+		case 1:
+			if !f.Bool() {
+				t.Error("success = false, want true")
+			}
+		case 2:
+			t.Errorf("error field present: %q", f.String())
+		case 3:
+			if f.String() != result.Message {
+				t.Errorf("message = %q, want %q", f.String(), result.Message)
+			}
+		case 4:
+			warnings = append(warnings, f.String())
+		case 5:
+			action = append([]byte(nil), f.Bytes()...)
+		case 6:
+			participant = append([]byte(nil), f.Bytes()...)
+		case 7:
+			combatChange = append([]byte(nil), f.Bytes()...)
+		default:
+			t.Errorf("unexpected ActionResult field %d", f.Number)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadProto: %v", err)
+	}
+	if !reflect.DeepEqual(warnings, result.Warnings) {
+		t.Errorf("warnings = %v, want %v", warnings, result.Warnings)
+	}
+
+	fields := map[protowire.Number]interface{}{}
+	err = protocodec.Read(action, func(f *protocodec.Field) error {
+		switch f.Number {
+		case 1, 2, 3, 4:
+			id, idErr := f.UUID()
+			fields[f.Number] = id
+			return idErr
+		case 5, 6:
+			fields[f.Number] = f.String()
+		case 8, 10, 14, 15:
+			fields[f.Number] = f.Int64()
+		case 11:
+			fields[f.Number] = f.Bool()
+		case 16:
+			fields[f.Number] = f.Time()
+		default:
+			t.Errorf("unexpected CombatAction field %d", f.Number)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadProto(action): %v", err)
+	}
+	wantAction := map[protowire.Number]interface{}{
+		1: result.Action.ID, 2: result.Action.CombatID, 3: result.Action.ActorID, 4: targetID,
+		5: "skill", 6: "fireball", 8: int64(42), 10: int64(15), 11: true,
+		14: int64(3), 15: int64(2), 16: result.Action.ServerTimestamp,
+	}
+	if !reflect.DeepEqual(fields, wantAction) {
+		t.Errorf("action fields = %v, want %v", fields, wantAction)
+	}
+
+	fields = map[protowire.Number]interface{}{}
+	err = protocodec.Read(participant, func(f *protocodec.Field) error {
+		switch f.Number {
+		case 1:
+			id, idErr := f.UUID()
+			fields[f.Number] = id
+			return idErr
+		case 2, 3:
+			fields[f.Number] = f.Sint()
+		case 4:
+			fields[f.Number] = f.String()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadProto(participant_changes): %v", err)
+	}
+	wantParticipant := map[protowire.Number]interface{}{1: targetID, 2: -42, 3: 5, 4: "dead"}
+	if !reflect.DeepEqual(fields, wantParticipant) {
+		t.Errorf("participant change fields = %v, want %v", fields, wantParticipant)
+	}
+
+	fields = map[protowire.Number]interface{}{}
+	err = protocodec.Read(combatChange, func(f *protocodec.Field) error {
+		switch f.Number {
+		case 1:
+			fields[f.Number] = f.Bool()
+		case 2:
+			fields[f.Number] = f.String()
+		case 3:
+			fields[f.Number] = f.Int()
+		case 4:
+			fields[f.Number] = f.Time()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadProto(combat_change): %v", err)
+	}
+	// winner est optional : le vainqueur 0 (équipe 0) doit être transmis
+	wantCombatChange := map[protowire.Number]interface{}{
+		1: true, 2: "finished", 3: 0, 4: *result.StateChanges.CombatChange.NextActionTime,
+	}
+	if !reflect.DeepEqual(fields, wantCombatChange) {
+		t.Errorf("combat change fields = %v, want %v", fields, wantCombatChange)
+	}
+}
+
+func BenchmarkActionRequestMarshalProto(b *testing.B) {
+	request := newTestActionRequest()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = request.MarshalProto()
+	}
+}
+
+func BenchmarkActionRequestUnmarshalProto(b *testing.B) {
+	data := newTestActionRequest().MarshalProto()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var request ActionRequest
+		if err := request.UnmarshalProto(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkActionResultMarshalProto(b *testing.B) {
+	result := newTestActionResult()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = result.MarshalProto()
+	}
+}
@@ -108,21 +108,28 @@ type ChallengeResponse struct {
 
 // GetRankingsRequest représente une demande de classements
 type GetRankingsRequest struct {
-	Season   string        `json:"season,omitempty"`
-	Type     ChallengeType `json:"type,omitempty"`
-	Limit    int           `json:"limit,omitempty"`
-	Offset   int           `json:"offset,omitempty"`
-	PlayerID *uuid.UUID    `json:"player_id,omitempty"` // Pour obtenir le rang du joueur
+	Season     string        `json:"season,omitempty"`
+	Type       ChallengeType `json:"type,omitempty"`
+	Class      string        `json:"class,omitempty"`
+	Region     string        `json:"region,omitempty"`
+	Limit      int           `json:"limit,omitempty"`
+	Offset     int           `json:"offset,omitempty"`
+	PlayerID   *uuid.UUID    `json:"player_id,omitempty"`   // Pour obtenir le rang du joueur
+	Around     int           `json:"around,omitempty"`      // Nombre de joueurs autour de PlayerID
+	SnapshotID *uuid.UUID    `json:"snapshot_id,omitempty"` // Classement historique
 }
 
 // RankingsResponse représente la réponse de classements
 type RankingsResponse struct {
-	Rankings   []*PvPRanking `json:"rankings"`
-	PlayerRank *PvPRanking   `json:"player_rank,omitempty"`
-	Total      int           `json:"total"`
-	Page       int           `json:"page"`
-	PageSize   int           `json:"page_size"`
-	Season     string        `json:"season"`
+	Rankings     []*PvPRanking `json:"rankings"`
+	PlayerRank   *PvPRanking   `json:"player_rank,omitempty"`
+	AroundPlayer []*PvPRanking `json:"around_player,omitempty"`
+	Total        int           `json:"total"`
+	Page         int           `json:"page"`
+	PageSize     int           `json:"page_size"`
+	Season       string        `json:"season"`
+	Ladder       string        `json:"ladder,omitempty"`
+	SnapshotAt   *time.Time    `json:"snapshot_at,omitempty"`
 }
 
 // MatchResult représente le résultat d'un match
@@ -135,6 +142,14 @@ type MatchResult struct {
 	WinnerRating int           `json:"winner_rating"`
 	LoserRating  int           `json:"loser_rating"`
 	RatingChange int           `json:"rating_change"`
+
+	// Contexte des classements (optionnel)
+	Season       string        `json:"season,omitempty"`
+	QueueType    ChallengeType `json:"queue_type,omitempty"`
+	WinnerClass  string        `json:"winner_class,omitempty"`
+	LoserClass   string        `json:"loser_class,omitempty"`
+	WinnerRegion string        `json:"winner_region,omitempty"`
+	LoserRegion  string        `json:"loser_region,omitempty"`
 }
 
 // SeasonInfo représente les informations de saison
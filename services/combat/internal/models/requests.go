@@ -107,6 +107,7 @@ type GetStatisticsRequest struct {
 // ApplyEffectRequest représente une demande d'application d'effet
 type ApplyEffectRequest struct {
 	EffectID    string                 `json:"effect_id" binding:"required"`
+	CombatID    *uuid.UUID             `json:"combat_id,omitempty"`
 	TargetID    uuid.UUID              `json:"target_id" binding:"required"`
 	CasterID    *uuid.UUID             `json:"caster_id,omitempty"`
 	Duration    *int                   `json:"duration,omitempty"`
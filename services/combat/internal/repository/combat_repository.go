@@ -41,7 +41,7 @@ type CombatRepositoryInterface interface {
 	GetCombatHistory(req *models.GetCombatHistoryRequest) ([]*models.CombatHistoryEntry, int, error)
 
 	// Nettoyage et maintenance
-	CleanupExpiredCombats() error
+	CleanupExpiredCombats() ([]uuid.UUID, error)
 	GetExpiredCombats() ([]*models.CombatInstance, error)
 }
 
@@ -710,26 +710,28 @@ func (r *CombatRepository) GetCombatHistory(req *models.GetCombatHistoryRequest)
 	return history, total, nil
 }
 
-// CleanupExpiredCombats nettoie les combats expirés
-func (r *CombatRepository) CleanupExpiredCombats() error {
+// CleanupExpiredCombats annule les combats expirés et retourne leurs identifiants
+func (r *CombatRepository) CleanupExpiredCombats() ([]uuid.UUID, error) {
 	query := `
 		UPDATE combat_instances 
 		SET status = $1, ended_at = $2, updated_at = $2
 		WHERE status IN ($3, $4) 
-		AND created_at < $5`
+		AND created_at < $5
+		RETURNING id`
 
 	expiredTime := time.Now().Add(-24 * time.Hour) // Expire après 24h
 	now := time.Now()
 
-	_, err := r.db.Exec(query,
+	var expired []uuid.UUID
+	err := r.db.Select(&expired, query,
 		models.CombatStatusCancelled, now,
 		models.CombatStatusWaiting, models.CombatStatusActive,
 		expiredTime)
 	if err != nil {
-		return fmt.Errorf("failed to cleanup expired combats: %w", err)
+		return nil, fmt.Errorf("failed to cleanup expired combats: %w", err)
 	}
 
-	return nil
+	return expired, nil
 }
 
 // GetExpiredCombats récupère les combats expirés
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"combat/internal/config"
+	"combat/internal/database"
+	"combat/internal/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// snapshotInsertBatchSize nombre de lignes par INSERT multi-valeurs lors d'un snapshot
+const snapshotInsertBatchSize = 500
+
+// ErrLadderEntryNotFound le joueur n'a pas encore d'entrée pour cette saison et cette file
+var ErrLadderEntryNotFound = fmt.Errorf("ladder entry not found: %w", sql.ErrNoRows)
+
+// LeaderboardRepositoryInterface définit les méthodes du repository des classements PvP
+type LeaderboardRepositoryInterface interface {
+	// Entrées de classement (source de vérité)
+	RecordLadderResult(entry *models.LadderEntry, result models.ResultType) (*models.LadderEntry, error)
+	GetLadderEntry(season string, queueType models.ChallengeType, characterID uuid.UUID) (*models.LadderEntry, error)
+	GetLadderEntriesAfter(season string, afterQueue models.ChallengeType, afterCharacter uuid.UUID, limit int) ([]*models.LadderEntry, error)
+
+	// Snapshots historiques
+	CreateSnapshot(snapshot *models.LeaderboardSnapshot, rankings []*models.PvPRanking) error
+	GetSnapshot(id uuid.UUID) (*models.LeaderboardSnapshot, error)
+	GetSnapshots(key models.LadderKey, limit int) ([]*models.LeaderboardSnapshot, error)
+	GetSnapshotEntries(snapshotID uuid.UUID, offset, limit int) ([]*models.PvPRanking, error)
+}
+
+// LeaderboardRepository implémente l'interface LeaderboardRepositoryInterface
+type LeaderboardRepository struct {
+	db *database.DB
+}
+
+// NewLeaderboardRepository crée une nouvelle instance du repository des classements
+func NewLeaderboardRepository(db *database.DB) LeaderboardRepositoryInterface {
+	return &LeaderboardRepository{db: db}
+}
+
+// RecordLadderResult applique le résultat d'un match à l'entrée d'un joueur en une seule requête
+// L'entrée est créée au premier match. Les compteurs et la série sont calculés par la base à
+// partir de la ligne existante : deux matchs enregistrés en même temps ne s'écrasent pas.
+// entry porte la clé, la cote, la classe et la région (vides : inchangées) et la date du match.
+func (r *LeaderboardRepository) RecordLadderResult(entry *models.LadderEntry, result models.ResultType) (*models.LadderEntry, error) {
+	var wins, losses, draws, streakStep int
+	resetStreak := false
+	switch result {
+	case models.ResultTypeVictory:
+		wins, streakStep = 1, 1
+	case models.ResultTypeDefeat:
+		losses, streakStep = 1, -1
+	case models.ResultTypeDraw:
+		draws = 1
+		resetStreak = true
+	}
+
+	query := `
+		INSERT INTO pvp_ladder_entries (
+			season, queue_type, character_id, class, region, rating,
+			wins, losses, draws, streak, last_match_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $12, $13)
+		ON CONFLICT (season, queue_type, character_id) DO UPDATE SET
+			class = COALESCE(NULLIF(EXCLUDED.class, ''), pvp_ladder_entries.class),
+			region = COALESCE(NULLIF(EXCLUDED.region, ''), pvp_ladder_entries.region),
+			rating = EXCLUDED.rating,
+			wins = pvp_ladder_entries.wins + EXCLUDED.wins,
+			losses = pvp_ladder_entries.losses + EXCLUDED.losses,
+			draws = pvp_ladder_entries.draws + EXCLUDED.draws,
+			streak = CASE
+				WHEN $10 > 0 THEN GREATEST(pvp_ladder_entries.streak, 0) + 1
+				WHEN $10 < 0 THEN LEAST(pvp_ladder_entries.streak, 0) - 1
+				WHEN $11 THEN 0
+				ELSE pvp_ladder_entries.streak
+			END,
+			last_match_at = EXCLUDED.last_match_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING season, queue_type, character_id, class, region, rating,
+		          wins, losses, draws, streak, last_match_at, updated_at`
+
+	var recorded models.LadderEntry
+	err := r.db.Get(&recorded, query,
+		entry.Season, entry.QueueType, entry.CharacterID, entry.Class, entry.Region, entry.Rating,
+		wins, losses, draws, streakStep, resetStreak, entry.LastMatchAt, time.Now(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record ladder result: %w", err)
+	}
+
+	return &recorded, nil
+}
+
+// GetLadderEntry récupère l'entrée d'un joueur pour une saison et une file
+func (r *LeaderboardRepository) GetLadderEntry(
+	season string,
+	queueType models.ChallengeType,
+	characterID uuid.UUID,
+) (*models.LadderEntry, error) {
+	var entry models.LadderEntry
+
+	query := `
+		SELECT season, queue_type, character_id, class, region, rating,
+		       wins, losses, draws, streak, last_match_at, updated_at
+		FROM pvp_ladder_entries
+		WHERE season = $1 AND queue_type = $2 AND character_id = $3`
+
+	if err := r.db.Get(&entry, query, season, queueType, characterID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLadderEntryNotFound
+		}
+		return nil, fmt.Errorf("failed to get ladder entry: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// GetLadderEntriesAfter parcourt les entrées d'une saison par pagination keyset
+// (ordre de la clé primaire, sans OFFSET pour rester efficace sur de gros volumes)
+func (r *LeaderboardRepository) GetLadderEntriesAfter(
+	season string,
+	afterQueue models.ChallengeType,
+	afterCharacter uuid.UUID,
+	limit int,
+) ([]*models.LadderEntry, error) {
+	var entries []*models.LadderEntry
+
+	query := `
+		SELECT season, queue_type, character_id, class, region, rating,
+		       wins, losses, draws, streak, last_match_at, updated_at
+		FROM pvp_ladder_entries
+		WHERE season = $1 AND (queue_type, character_id) > ($2, $3)
+		ORDER BY queue_type, character_id
+		LIMIT $4`
+
+	if err := r.db.Select(&entries, query, season, afterQueue, afterCharacter, limit); err != nil {
+		return nil, fmt.Errorf("failed to list ladder entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// CreateSnapshot enregistre un snapshot et ses lignes dans une transaction
+func (r *LeaderboardRepository) CreateSnapshot(snapshot *models.LeaderboardSnapshot, rankings []*models.PvPRanking) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logrus.WithError(err).Warn("Erreur lors du rollback")
+		}
+	}()
+
+	query := `
+		INSERT INTO pvp_leaderboard_snapshots (
+			id, season, queue_type, class, region, total_players, taken_at
+		) VALUES (
+			:id, :season, :queue_type, :class, :region, :total_players, :taken_at
+		)`
+
+	if _, err := tx.NamedExec(query, snapshot); err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	// INSERT multi-valeurs par lots pour limiter les allers-retours
+	for start := 0; start < len(rankings); start += snapshotInsertBatchSize {
+		end := start + snapshotInsertBatchSize
+		if end > len(rankings) {
+			end = len(rankings)
+		}
+
+		const columns = 8
+		batch := rankings[start:end]
+		placeholders := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch)*columns)
+		for i, ranking := range batch {
+			base := i * columns
+			placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+				base+1, base+2, base+3, base+4, base+5, base+6, base+7, base+8))
+			args = append(args, snapshot.ID, ranking.Rank, ranking.PlayerID, ranking.Rating,
+				ranking.Wins, ranking.Losses, ranking.Draws, ranking.Streak)
+		}
+
+		entriesQuery := `
+			INSERT INTO pvp_leaderboard_snapshot_entries (
+				snapshot_id, rank, character_id, rating, wins, losses, draws, streak
+			) VALUES ` + strings.Join(placeholders, ", ")
+
+		if _, err := tx.Exec(entriesQuery, args...); err != nil {
+			return fmt.Errorf("failed to insert snapshot entries: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GetSnapshot récupère un snapshot par son ID
+func (r *LeaderboardRepository) GetSnapshot(id uuid.UUID) (*models.LeaderboardSnapshot, error) {
+	var snapshot models.LeaderboardSnapshot
+
+	query := `
+		SELECT id, season, queue_type, class, region, total_players, taken_at
+		FROM pvp_leaderboard_snapshots
+		WHERE id = $1`
+
+	if err := r.db.Get(&snapshot, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("snapshot not found")
+		}
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// GetSnapshots liste les derniers snapshots d'un classement
+func (r *LeaderboardRepository) GetSnapshots(key models.LadderKey, limit int) ([]*models.LeaderboardSnapshot, error) {
+	var snapshots []*models.LeaderboardSnapshot
+
+	query := `
+		SELECT id, season, queue_type, class, region, total_players, taken_at
+		FROM pvp_leaderboard_snapshots
+		WHERE season = $1 AND queue_type = $2 AND class = $3 AND region = $4
+		ORDER BY taken_at DESC
+		LIMIT $5`
+
+	if err := r.db.Select(&snapshots, query, key.Season, key.QueueType, key.Class, key.Region, limit); err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// GetSnapshotEntries récupère une page d'un snapshot
+func (r *LeaderboardRepository) GetSnapshotEntries(snapshotID uuid.UUID, offset, limit int) ([]*models.PvPRanking, error) {
+	query := `
+		SELECT rank, character_id, rating, wins, losses, draws, streak
+		FROM pvp_leaderboard_snapshot_entries
+		WHERE snapshot_id = $1 AND rank > $2
+		ORDER BY rank
+		LIMIT $3`
+
+	rows, err := r.db.Query(query, snapshotID, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot entries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	rankings := []*models.PvPRanking{}
+	for rows.Next() {
+		var ranking models.PvPRanking
+		if err := rows.Scan(
+			&ranking.Rank, &ranking.PlayerID, &ranking.Rating,
+			&ranking.Wins, &ranking.Losses, &ranking.Draws, &ranking.Streak,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot entry: %w", err)
+		}
+
+		if total := ranking.Wins + ranking.Losses + ranking.Draws; total > 0 {
+			ranking.WinRate = float64(ranking.Wins) / float64(total) * config.DefaultPercentageMultiplier
+		}
+
+		rankings = append(rankings, &ranking)
+	}
+
+	return rankings, rows.Err()
+}
@@ -26,6 +26,10 @@ type PvPRepositoryInterface interface {
 	GetChallengesByStatus(status models.ChallengeStatus) ([]*models.PvPChallenge, error)
 	GetExpiredChallenges() ([]*models.PvPChallenge, error)
 
+	// Zones des duels en cours
+	SaveDuelBoundary(boundary *models.DuelBoundary) error
+	GetActiveDuelBoundaries() ([]*models.DuelBoundary, error)
+
 	// Statistiques PvP
 	GetPvPStatistics(playerID uuid.UUID) (*models.PvPStatistics, error)
 	UpdatePvPStatistics(stats *models.PvPStatistics) error
@@ -349,6 +353,60 @@ func (r *PvPRepository) GetExpiredChallenges() ([]*models.PvPChallenge, error) {
 	return challenges, nil
 }
 
+// SaveDuelBoundary enregistre la zone d'un duel avec son défi
+func (r *PvPRepository) SaveDuelBoundary(boundary *models.DuelBoundary) error {
+	boundaryJSON, err := json.Marshal(boundary)
+	if err != nil {
+		return fmt.Errorf("failed to marshal duel boundary: %w", err)
+	}
+
+	result, err := r.db.Exec(`UPDATE pvp_challenges SET duel_boundary = $2 WHERE id = $1`, boundary.ChallengeID, boundaryJSON)
+	if err != nil {
+		return fmt.Errorf("failed to save duel boundary: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("challenge not found")
+	}
+
+	return nil
+}
+
+// GetActiveDuelBoundaries récupère les zones des duels acceptés et pas encore terminés
+func (r *PvPRepository) GetActiveDuelBoundaries() ([]*models.DuelBoundary, error) {
+	var boundaries []*models.DuelBoundary
+
+	query := `
+		SELECT duel_boundary
+		FROM pvp_challenges
+		WHERE status = 'accepted' AND duel_boundary IS NOT NULL`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get duel boundaries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var boundaryJSON []byte
+		if err := rows.Scan(&boundaryJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan duel boundary: %w", err)
+		}
+
+		var boundary models.DuelBoundary
+		if err := json.Unmarshal(boundaryJSON, &boundary); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal duel boundary: %w", err)
+		}
+		boundaries = append(boundaries, &boundary)
+	}
+
+	return boundaries, rows.Err()
+}
+
 // GetPvPStatistics récupère les statistiques PvP d'un joueur
 func (r *PvPRepository) GetPvPStatistics(playerID uuid.UUID) (*models.PvPStatistics, error) {
 	query := `
@@ -83,6 +83,9 @@ func (s *ActionService) ExecuteAction(combat *models.CombatInstance, actor *mode
 	// Déterminer l'ordre d'action (basé sur la vitesse d'attaque)
 	action.ActionOrder = s.calculateActionOrder(actor)
 
+	// Les buffs et statuts élémentaires de l'acteur modifient ses dégâts
+	s.loadActiveEffects(combat.ID, actor)
+
 	result := &models.ActionResult{
 		Success: true,
 		Action:  action,
@@ -175,28 +178,19 @@ func (s *ActionService) executeAttack(action *models.CombatAction, combat *model
 		return fmt.Errorf("cannot attack ally")
 	}
 
-	// Calculer la chance de toucher
-	hitChance := models.CalculateHitChance(actor, target, nil)
-	hit := utils.SecureRandFloat64() < hitChance
-
-	if !hit {
-		action.IsMiss = true
+	// Calculer les dégâts (toucher, blocage, critique, éléments)
+	s.loadActiveEffects(combat.ID, target)
+	damage := s.rollDamage(action, actor, target, nil)
+	if damage.IsMiss {
 		result.Logs = append(result.Logs, &models.CombatLog{
 			Message: fmt.Sprintf("%s rate son attaque sur %s", actor.Character.Name, target.Character.Name),
 		})
 		return nil
 	}
 
-	// Calculer la chance de critique
-	critChance := models.CalculateCriticalChance(actor, nil)
-	action.IsCritical = utils.SecureRandFloat64() < critChance
-
-	// Calculer les dégâts
-	damage := action.CalculateDamage(actor, target, nil)
-	action.DamageDealt = damage
-
 	// Appliquer les dégâts
-	s.applyDamage(target, damage, result)
+	s.applyDamage(target, damage.FinalDamage, result)
+	s.removeConsumedEffects(target, damage.ConsumedEffects, result)
 
 	// Mettre à jour les statistiques de l'acteur
 	change := result.StateChanges.ParticipantChanges[actor.CharacterID]
@@ -295,17 +289,28 @@ func (s *ActionService) processSkillHitAndCrit(actor, target *models.CombatParti
 	return true, nil
 }
 
-// applySkillEffectsAndDamage applique les effets de la compétence
-func (s *ActionService) applySkillEffectsAndDamage(actor, target *models.CombatParticipant,
+// applySkillDamage calcule et applique les dégâts d'une compétence offensive. Retourne false
+// si elle a manqué sa cible
+func (s *ActionService) applySkillDamage(actor, target *models.CombatParticipant,
 	skill *models.SkillInfo, action *models.CombatAction, result *models.ActionResult,
-) {
-	// Appliquer les dégâts
-	if skill.BaseDamage > 0 {
-		damage := action.CalculateDamage(actor, target, skill)
-		action.DamageDealt = damage
-		s.applyDamage(target, damage, result)
+) bool {
+	damage := s.rollDamage(action, actor, target, skill)
+	if damage.IsMiss {
+		action.ManaUsed = skill.ManaCost / config.DefaultArmorDivisor // Coût réduit en cas d'échec
+		return false
 	}
 
+	action.ManaUsed = skill.ManaCost
+	s.applyDamage(target, damage.FinalDamage, result)
+	s.removeConsumedEffects(target, damage.ConsumedEffects, result)
+	return true
+}
+
+// applySkillEffectsAndDamage applique les soins et les effets de la compétence, une fois ses
+// dégâts appliqués par applySkillDamage
+func (s *ActionService) applySkillEffectsAndDamage(actor, target *models.CombatParticipant,
+	skill *models.SkillInfo, action *models.CombatAction, result *models.ActionResult,
+) {
 	// Appliquer les soins
 	if skill.BaseHealing > 0 {
 		healing := action.CalculateHealing(actor, skill)
@@ -373,9 +378,15 @@ func (s *ActionService) executeSkill(action *models.CombatAction, combat *models
 		return err
 	}
 
-	// Traiter les chances de toucher et de critique
-	hit, err := s.processSkillHitAndCrit(actor, target, skill, action)
-	if err != nil {
+	// Traiter les chances de toucher et de critique ; celles des compétences offensives sont
+	// tirées par le calculateur de dégâts
+	hit := true
+	if skill.BaseDamage > 0 {
+		if target != actor {
+			s.loadActiveEffects(combat.ID, target)
+		}
+		hit = s.applySkillDamage(actor, target, skill, action, result)
+	} else if hit, err = s.processSkillHitAndCrit(actor, target, skill, action); err != nil {
 		return err
 	}
 
@@ -544,6 +555,66 @@ func (s *ActionService) applyDamage(target *models.CombatParticipant, damage int
 	}
 }
 
+// rollDamage calcule les dégâts d'une attaque ou d'une compétence avec le calculateur
+// (toucher, blocage, critique, affinités et réactions élémentaires) et en reporte l'issue sur l'action
+func (s *ActionService) rollDamage(action *models.CombatAction, actor, target *models.CombatParticipant,
+	skill *models.SkillInfo,
+) *DamageResult {
+	damage := s.damageCalc.CalculateDamage(actor, target, skill, nil)
+	action.IsMiss = damage.IsMiss
+	action.IsBlocked = damage.IsBlocked
+	action.IsCritical = damage.IsCritical
+	action.DamageDealt = damage.FinalDamage
+	return damage
+}
+
+// loadActiveEffects charge les effets actifs d'un participant dans ce combat : buffs et statuts
+// élémentaires entrent dans le calcul des dégâts
+func (s *ActionService) loadActiveEffects(combatID uuid.UUID, participant *models.CombatParticipant) {
+	effects, err := s.effectRepo.GetActiveByTarget(participant.CharacterID)
+	if err != nil {
+		logrus.WithError(err).WithField("character_id", participant.CharacterID).Warn("Failed to load active effects")
+		return
+	}
+
+	participant.ActiveEffects = make([]*models.CombatEffect, 0, len(effects))
+	for _, effect := range effects {
+		if effect.CombatID == combatID {
+			participant.ActiveEffects = append(participant.ActiveEffects, effect)
+		}
+	}
+}
+
+// consumeEffects désactive en base les statuts consommés par une réaction élémentaire et
+// retourne ceux qui l'ont été
+func (s *ActionService) consumeEffects(effectIDs []uuid.UUID) []uuid.UUID {
+	consumed := make([]uuid.UUID, 0, len(effectIDs))
+	for _, effectID := range effectIDs {
+		if err := s.effectRepo.DeactivateEffect(effectID); err != nil {
+			logrus.WithError(err).WithField("effect_id", effectID).Error("Failed to consume effect")
+			continue
+		}
+		consumed = append(consumed, effectID)
+	}
+	return consumed
+}
+
+// removeConsumedEffects retire de la cible les statuts consommés et les signale dans le résultat
+func (s *ActionService) removeConsumedEffects(target *models.CombatParticipant, effectIDs []uuid.UUID,
+	result *models.ActionResult,
+) {
+	if len(effectIDs) == 0 {
+		return
+	}
+
+	change := result.StateChanges.ParticipantChanges[target.CharacterID]
+	if change == nil {
+		change = &models.ParticipantChange{}
+		result.StateChanges.ParticipantChanges[target.CharacterID] = change
+	}
+	change.EffectsRemoved = append(change.EffectsRemoved, s.consumeEffects(effectIDs)...)
+}
+
 func (s *ActionService) applyHealing(target *models.CombatParticipant, healing int, result *models.ActionResult) {
 	if healing <= 0 {
 		return
@@ -862,8 +933,8 @@ func (s *ActionService) CalculateActionResult(action *models.CombatAction, actor
 ) error {
 	if action.ActionType == models.ActionTypeAttack || (action.ActionType == models.ActionTypeSkill && skill != nil && skill.BaseDamage > 0) {
 		// Calculer les dégâts
-		damage := action.CalculateDamage(actor, target, skill)
-		action.DamageDealt = damage
+		damage := s.rollDamage(action, actor, target, skill)
+		s.consumeEffects(damage.ConsumedEffects)
 	}
 
 	if action.ActionType == models.ActionTypeSkill && skill != nil && skill.BaseHealing > 0 {
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"combat/internal/config"
+	"combat/internal/models"
+	"combat/internal/repository"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// memoryEffects effets en mémoire, avec les désactivations enregistrées
+type memoryEffects struct {
+	repository.EffectRepositoryInterface
+
+	effects     []*models.CombatEffect
+	deactivated []uuid.UUID
+}
+
+func (r *memoryEffects) GetActiveByTarget(targetID uuid.UUID) ([]*models.CombatEffect, error) {
+	var active []*models.CombatEffect
+	for _, effect := range r.effects {
+		if effect.TargetID == targetID && effect.IsActive {
+			active = append(active, effect)
+		}
+	}
+	return active, nil
+}
+
+func (r *memoryEffects) DeactivateEffect(effectID uuid.UUID) error {
+	r.deactivated = append(r.deactivated, effectID)
+	return nil
+}
+
+// memoryParticipants participants d'un combat en mémoire
+type memoryParticipants struct {
+	repository.CombatRepositoryInterface
+
+	participants map[uuid.UUID]*models.CombatParticipant
+}
+
+func (r *memoryParticipants) GetParticipant(_, characterID uuid.UUID) (*models.CombatParticipant, error) {
+	return r.participants[characterID], nil
+}
+
+// recordingDamageCalculator résultat de dégâts fixe, avec les effets actifs vus par le calcul
+type recordingDamageCalculator struct {
+	DamageCalculatorInterface
+
+	result        *DamageResult
+	targetEffects []*models.CombatEffect
+}
+
+func (c *recordingDamageCalculator) CalculateDamage(
+	_, defender *models.CombatParticipant,
+	_ *models.SkillInfo,
+	_ map[string]float64,
+) *DamageResult {
+	c.targetEffects = defender.ActiveEffects
+	return c.result
+}
+
+func soakedEffect(combatID, targetID uuid.UUID) *models.CombatEffect {
+	stat := models.ElementalStatStatus + "soaked"
+	return &models.CombatEffect{ID: uuid.New(), CombatID: combatID, TargetID: targetID, StatAffected: &stat, IsActive: true}
+}
+
+// TestExecuteAttackUsesDamageCalculator les dégâts viennent du calculateur, qui voit les effets
+// de la cible dans ce combat, et les statuts consommés par une réaction sont désactivés en base
+func TestExecuteAttackUsesDamageCalculator(t *testing.T) {
+	combat := &models.CombatInstance{ID: uuid.New(), Settings: models.GetDefaultCombatSettings()}
+	actor := &models.CombatParticipant{CharacterID: uuid.New(), Team: 1, IsAlive: true, Character: &models.CharacterSummary{Name: "aldric"}}
+	target := &models.CombatParticipant{
+		CharacterID: uuid.New(), Team: 2, IsAlive: true, Health: 100, MaxHealth: 100,
+		Character: &models.CharacterSummary{Name: "morwen"},
+	}
+
+	soaked := soakedEffect(combat.ID, target.CharacterID)
+	otherCombat := soakedEffect(uuid.New(), target.CharacterID)
+	effects := &memoryEffects{effects: []*models.CombatEffect{soaked, otherCombat}}
+	calculator := &recordingDamageCalculator{result: &DamageResult{
+		FinalDamage:     42,
+		IsCritical:      true,
+		Element:         models.ElementLightning,
+		ConsumedEffects: []uuid.UUID{soaked.ID},
+	}}
+	s := NewActionService(nil, &memoryParticipants{participants: map[uuid.UUID]*models.CombatParticipant{
+		target.CharacterID: target,
+	}}, effects, calculator, &config.Config{}).(*ActionService)
+
+	action := &models.CombatAction{TargetID: &target.CharacterID}
+	result := &models.ActionResult{StateChanges: &models.StateChanges{ParticipantChanges: map[uuid.UUID]*models.ParticipantChange{}}}
+	if err := s.executeAttack(action, combat, actor, result); err != nil {
+		t.Fatalf("executeAttack: %v", err)
+	}
+
+	if len(calculator.targetEffects) != 1 || calculator.targetEffects[0] != soaked {
+		t.Errorf("calculator saw target effects %v, want only the effect of this combat", calculator.targetEffects)
+	}
+	if action.DamageDealt != 42 || !action.IsCritical {
+		t.Errorf("action damage %d, critical %v; want 42, true", action.DamageDealt, action.IsCritical)
+	}
+
+	change := result.StateChanges.ParticipantChanges[target.CharacterID]
+	if change == nil || change.HealthChange != -42 {
+		t.Fatalf("target change = %+v, want -42 health", change)
+	}
+	if len(effects.deactivated) != 1 || effects.deactivated[0] != soaked.ID {
+		t.Errorf("deactivated effects = %v, want [%s]", effects.deactivated, soaked.ID)
+	}
+	if len(change.EffectsRemoved) != 1 || change.EffectsRemoved[0] != soaked.ID {
+		t.Errorf("EffectsRemoved = %v, want [%s]", change.EffectsRemoved, soaked.ID)
+	}
+}
+
+// TestExecuteAttackMiss une attaque manquée n'inflige rien et ne consomme aucun statut
+func TestExecuteAttackMiss(t *testing.T) {
+	combat := &models.CombatInstance{ID: uuid.New(), Settings: models.GetDefaultCombatSettings()}
+	actor := &models.CombatParticipant{CharacterID: uuid.New(), Team: 1, IsAlive: true, Character: &models.CharacterSummary{Name: "aldric"}}
+	target := &models.CombatParticipant{CharacterID: uuid.New(), Team: 2, IsAlive: true, Health: 100, Character: &models.CharacterSummary{Name: "morwen"}}
+
+	effects := &memoryEffects{}
+	s := NewActionService(nil, &memoryParticipants{participants: map[uuid.UUID]*models.CombatParticipant{
+		target.CharacterID: target,
+	}}, effects, &recordingDamageCalculator{result: &DamageResult{IsMiss: true}}, &config.Config{}).(*ActionService)
+
+	action := &models.CombatAction{TargetID: &target.CharacterID}
+	result := &models.ActionResult{StateChanges: &models.StateChanges{ParticipantChanges: map[uuid.UUID]*models.ParticipantChange{}}}
+	if err := s.executeAttack(action, combat, actor, result); err != nil {
+		t.Fatalf("executeAttack: %v", err)
+	}
+
+	if !action.IsMiss || action.DamageDealt != 0 {
+		t.Errorf("action miss %v, damage %d; want a miss without damage", action.IsMiss, action.DamageDealt)
+	}
+	if _, exists := result.StateChanges.ParticipantChanges[target.CharacterID]; exists {
+		t.Error("missed attack changed the target")
+	}
+	if len(effects.deactivated) != 0 {
+		t.Errorf("missed attack consumed effects %v", effects.deactivated)
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"combat/internal/clients"
+	"combat/internal/config"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ccImmunityEntry immunités d'une cible lues auprès du service world
+type ccImmunityEntry struct {
+	immunities []string
+	expiresAt  time.Time
+}
+
+// CCImmunityResolver fournit les immunités aux contrôles d'une cible d'après le modèle de NPC
+// du service world (boss...). Les réponses, y compris l'absence d'immunité, sont mises en cache
+type CCImmunityResolver struct {
+	worldClient clients.WorldClientInterface
+	config      *config.Config
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]*ccImmunityEntry
+}
+
+// NewCCImmunityResolver crée un nouveau résolveur d'immunités aux contrôles
+func NewCCImmunityResolver(worldClient clients.WorldClientInterface, config *config.Config) *CCImmunityResolver {
+	return &CCImmunityResolver{
+		worldClient: worldClient,
+		config:      config,
+		entries:     make(map[uuid.UUID]*ccImmunityEntry),
+	}
+}
+
+// Immunities retourne les catégories de contrôle auxquelles la cible est insensible
+func (r *CCImmunityResolver) Immunities(targetID uuid.UUID, now time.Time) ([]string, error) {
+	r.mu.Lock()
+	entry, exists := r.entries[targetID]
+	r.mu.Unlock()
+	if exists && now.Before(entry.expiresAt) {
+		return entry.immunities, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.Services.WorldService.Timeout)
+	defer cancel()
+
+	immunities, err := r.worldClient.GetCCImmunities(ctx, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get crowd control immunities: %w", err)
+	}
+
+	r.mu.Lock()
+	r.entries[targetID] = &ccImmunityEntry{
+		immunities: immunities,
+		expiresAt:  now.Add(r.config.Combat.CCImmunityCacheTTL),
+	}
+	r.mu.Unlock()
+
+	return immunities, nil
+}
+
+// CleanupExpired supprime les immunités dont la durée de cache est écoulée
+func (r *CCImmunityResolver) CleanupExpired(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for targetID, entry := range r.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(r.entries, targetID)
+		}
+	}
+}
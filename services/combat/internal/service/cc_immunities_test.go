@@ -0,0 +1,148 @@
+package service
+
+import (
+	"combat/internal/clients"
+	"combat/internal/config"
+	"combat/internal/models"
+	"combat/internal/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// countingWorldClient immunités de NPC en mémoire, avec le nombre d'appels au service world
+type countingWorldClient struct {
+	clients.WorldClientInterface
+
+	immunities map[uuid.UUID][]string
+	err        error
+	calls      int
+}
+
+func (c *countingWorldClient) GetCCImmunities(_ context.Context, npcID uuid.UUID) ([]string, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.immunities[npcID], nil
+}
+
+// idleCombatRepository cible sans combat en cours
+type idleCombatRepository struct {
+	repository.CombatRepositoryInterface
+}
+
+func (idleCombatRepository) GetByParticipant(uuid.UUID) ([]*models.CombatInstance, error) {
+	return nil, nil
+}
+
+func newImmunityTestConfig() *config.Config {
+	return &config.Config{
+		Services: config.ServicesConfig{WorldService: config.ServiceEndpoint{Timeout: time.Second}},
+		Combat: config.CombatConfig{
+			CCImmunityCacheTTL: time.Minute,
+			DiminishingReturns: config.DiminishingReturnsConfig{PvE: testDRRule()},
+		},
+	}
+}
+
+func TestCCImmunityResolverCachesResponses(t *testing.T) {
+	boss, player := uuid.New(), uuid.New()
+	world := &countingWorldClient{immunities: map[uuid.UUID][]string{boss: {"stun"}}}
+	resolver := NewCCImmunityResolver(world, newImmunityTestConfig())
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		immunities, err := resolver.Immunities(boss, now)
+		if err != nil || !models.HasCCImmunity(immunities, "stun") {
+			t.Fatalf("Immunities(boss) = %v, %v", immunities, err)
+		}
+		// L'absence d'immunité est mise en cache elle aussi
+		if immunities, err = resolver.Immunities(player, now); err != nil || len(immunities) != 0 {
+			t.Fatalf("Immunities(player) = %v, %v", immunities, err)
+		}
+	}
+	if world.calls != 2 {
+		t.Fatalf("%d calls to the world service, want 2", world.calls)
+	}
+
+	if _, err := resolver.Immunities(boss, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Immunities: %v", err)
+	}
+	if world.calls != 3 {
+		t.Fatalf("expired entry not refreshed: %d calls, want 3", world.calls)
+	}
+
+	resolver.CleanupExpired(now.Add(2 * time.Minute))
+	if len(resolver.entries) != 0 {
+		t.Fatalf("%d entries left after cleanup, want 0", len(resolver.entries))
+	}
+}
+
+// TestCCImmunityResolverDoesNotCacheErrors une erreur du service world n'est pas mise en cache
+func TestCCImmunityResolverDoesNotCacheErrors(t *testing.T) {
+	boss := uuid.New()
+	world := &countingWorldClient{err: errors.New("unavailable")}
+	resolver := NewCCImmunityResolver(world, newImmunityTestConfig())
+
+	if _, err := resolver.Immunities(boss, time.Now()); err == nil {
+		t.Fatal("world service error not reported")
+	}
+
+	world.err = nil
+	world.immunities = map[uuid.UUID][]string{boss: {"all"}}
+	immunities, err := resolver.Immunities(boss, time.Now())
+	if err != nil || !models.HasCCImmunity(immunities, "silence") {
+		t.Fatalf("Immunities after recovery = %v, %v", immunities, err)
+	}
+}
+
+// TestCrowdControlBossImmunity l'immunité vient du modèle de NPC et prime sur les rendements décroissants
+func TestCrowdControlBossImmunity(t *testing.T) {
+	boss, player := uuid.New(), uuid.New()
+	world := &countingWorldClient{immunities: map[uuid.UUID][]string{boss: {"stun"}}}
+	s := NewEffectService(nil, idleCombatRepository{}, world, newImmunityTestConfig()).(*EffectService)
+	template := &models.EffectTemplate{Name: "stun", BaseDuration: 4}
+
+	state, result := s.applyCrowdControlRules(&models.ApplyEffectRequest{TargetID: boss}, template, &models.EffectApplication{}, "stun")
+	if state != nil || result == nil || result.DiminishingReturns.ImmunitySource != models.ImmunitySourceBoss {
+		t.Fatalf("stun on boss: state %v, result %+v", state, result)
+	}
+	if _, exists := s.drTracker.entries[drKey{targetID: boss, category: "stun"}]; exists {
+		t.Error("blocked control recorded for diminishing returns")
+	}
+
+	// Catégorie non couverte par le modèle : rendements décroissants habituels
+	application := &models.EffectApplication{}
+	state, result = s.applyCrowdControlRules(&models.ApplyEffectRequest{TargetID: boss}, template, application, "root")
+	if result != nil || state == nil || application.Duration != 4 {
+		t.Fatalf("root on boss: state %v, result %+v, duration %d", state, result, application.Duration)
+	}
+
+	application = &models.EffectApplication{}
+	state, result = s.applyCrowdControlRules(&models.ApplyEffectRequest{TargetID: player}, template, application, "stun")
+	if result != nil || state == nil || state.Level != 1 {
+		t.Fatalf("stun on player: state %v, result %+v", state, result)
+	}
+}
+
+func TestHasCCImmunity(t *testing.T) {
+	tests := []struct {
+		immunities []string
+		category   string
+		want       bool
+	}{
+		{nil, "stun", false},
+		{[]string{"root"}, "stun", false},
+		{[]string{"root", "stun"}, "stun", true},
+		{[]string{"all"}, "fear", true},
+	}
+	for _, tt := range tests {
+		if got := models.HasCCImmunity(tt.immunities, tt.category); got != tt.want {
+			t.Errorf("HasCCImmunity(%v, %q) = %v, want %v", tt.immunities, tt.category, got, tt.want)
+		}
+	}
+}
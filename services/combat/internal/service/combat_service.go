@@ -45,6 +45,7 @@ type CombatServiceInterface interface {
 	// Maintenance
 	CleanupExpiredCombats() error
 	GetActiveCombatCount() (int, error)
+	IsParticipantInCombat(characterID uuid.UUID) (bool, *models.CombatInstance, error)
 }
 
 // CombatService implémente l'interface CombatServiceInterface
@@ -55,6 +56,7 @@ type CombatService struct {
 	actionService ActionServiceInterface
 	effectService EffectServiceInterface
 	antiCheat     AntiCheatServiceInterface
+	statHydrator  StatHydratorInterface
 	config        *config.Config
 }
 
@@ -66,6 +68,7 @@ func NewCombatService(
 	actionService ActionServiceInterface,
 	effectService EffectServiceInterface,
 	antiCheat AntiCheatServiceInterface,
+	statHydrator StatHydratorInterface,
 	config *config.Config,
 ) CombatServiceInterface {
 	return &CombatService{
@@ -75,6 +78,7 @@ func NewCombatService(
 		actionService: actionService,
 		effectService: effectService,
 		antiCheat:     antiCheat,
+		statHydrator:  statHydrator,
 		config:        config,
 	}
 }
@@ -86,6 +90,13 @@ func (s *CombatService) CreateCombat(req *models.CreateCombatRequest) (*models.C
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
+	// Un personnage ne peut participer qu'à un seul combat à la fois
+	for _, participantReq := range req.Participants {
+		if err := s.ensureNotInCombat(participantReq.CharacterID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Vérifier la limite de combats actifs
 	activeCount, err := s.combatRepo.GetActiveCombatCount()
 	if err != nil {
@@ -106,7 +117,7 @@ func (s *CombatService) CreateCombat(req *models.CreateCombatRequest) (*models.C
 		CurrentTurn:     0,
 		TurnTimeLimit:   req.TurnTimeLimit,
 		MaxDuration:     req.MaxDuration,
-		Settings:        *req.Settings,
+		Settings:        models.GetDefaultCombatSettings(),
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
@@ -121,17 +132,13 @@ func (s *CombatService) CreateCombat(req *models.CreateCombatRequest) (*models.C
 	if combat.MaxDuration == 0 {
 		combat.MaxDuration = int(s.config.Combat.MaxDuration.Seconds())
 	}
-	if req.Settings == nil {
-		defaultSettings := models.GetDefaultCombatSettings()
-		combat.Settings = defaultSettings
-	}
-
-	// Sauvegarder en base
-	if err := s.combatRepo.Create(combat); err != nil {
-		return nil, fmt.Errorf("failed to create combat: %w", err)
+	if req.Settings != nil {
+		combat.Settings = *req.Settings
 	}
 
-	// Ajouter les participants initiaux
+	// Les stats sont toujours calculées côté serveur, pour tous les participants avant
+	// d'enregistrer quoi que ce soit : un personnage introuvable ne laisse pas de combat à moitié créé
+	participants := make([]*models.CombatParticipant, 0, len(req.Participants))
 	for _, participantReq := range req.Participants {
 		participant := &models.CombatParticipant{
 			ID:          uuid.New(),
@@ -146,20 +153,23 @@ func (s *CombatService) CreateCombat(req *models.CreateCombatRequest) (*models.C
 			UpdatedAt:   time.Now(),
 		}
 
-		// TODO: Récupérer les stats du personnage depuis le service player
-		// Pour l'instant, utiliser des valeurs par défaut
-		participant.Health = 100
-		participant.MaxHealth = 100
-		participant.Mana = 50
-		participant.MaxMana = 50
-		participant.PhysicalDamage = 20
-		participant.MagicalDamage = 15
-		participant.PhysicalDefense = 10
-		participant.MagicalDefense = 8
-		participant.CriticalChance = 0.05
-		participant.AttackSpeed = 1.0
+		if _, err := s.statHydrator.Hydrate(combat.ID, participant); err != nil {
+			s.statHydrator.Invalidate(combat.ID)
+			return nil, fmt.Errorf("failed to load stats for character %s: %w", participant.CharacterID, err)
+		}
+		participants = append(participants, participant)
+	}
 
+	// Sauvegarder en base
+	if err := s.combatRepo.Create(combat); err != nil {
+		s.statHydrator.Invalidate(combat.ID)
+		return nil, fmt.Errorf("failed to create combat: %w", err)
+	}
+
+	// Ajouter les participants initiaux
+	for _, participant := range participants {
 		if err := s.combatRepo.AddParticipant(participant); err != nil {
+			s.statHydrator.Invalidate(combat.ID)
 			return nil, fmt.Errorf("failed to add participant: %w", err)
 		}
 	}
@@ -318,6 +328,9 @@ func (s *CombatService) EndCombat(id uuid.UUID, req *models.EndCombatRequest) (*
 		return nil, fmt.Errorf("failed to end combat: %w", err)
 	}
 
+	// Les stats calculées ne sont valables que pour la durée du combat
+	s.statHydrator.Invalidate(combat.ID)
+
 	// Calculer les résultats
 	result := s.calculateCombatResult(combat, participants, req)
 
@@ -364,6 +377,11 @@ func (s *CombatService) JoinCombat(combatID uuid.UUID, req *models.JoinCombatReq
 		}
 	}
 
+	// Ni dans un autre combat
+	if err := s.ensureNotInCombat(req.CharacterID); err != nil {
+		return err
+	}
+
 	// Créer le participant
 	participant := &models.CombatParticipant{
 		ID:          uuid.New(),
@@ -377,20 +395,13 @@ func (s *CombatService) JoinCombat(combatID uuid.UUID, req *models.JoinCombatReq
 		UpdatedAt:   time.Now(),
 	}
 
-	// TODO: Récupérer les stats du personnage depuis le service player
-	// Pour l'instant, utiliser des valeurs par défaut
-	participant.Health = 100
-	participant.MaxHealth = 100
-	participant.Mana = 50
-	participant.MaxMana = 50
-	participant.PhysicalDamage = 20
-	participant.MagicalDamage = 15
-	participant.PhysicalDefense = 10
-	participant.MagicalDefense = 8
-	participant.CriticalChance = 0.05
-	participant.AttackSpeed = 1.0
+	// Les stats sont toujours calculées côté serveur, jamais fournies par le client
+	if _, err := s.statHydrator.Hydrate(combatID, participant); err != nil {
+		return fmt.Errorf("failed to load character stats: %w", err)
+	}
 
 	if err := s.combatRepo.AddParticipant(participant); err != nil {
+		s.statHydrator.InvalidateParticipant(combatID, participant.CharacterID)
 		return fmt.Errorf("failed to add participant: %w", err)
 	}
 
@@ -418,6 +429,7 @@ func (s *CombatService) LeaveCombat(combatID, characterID uuid.UUID, req *models
 	if err := s.combatRepo.RemoveParticipant(combatID, characterID); err != nil {
 		return fmt.Errorf("failed to remove participant: %w", err)
 	}
+	s.statHydrator.InvalidateParticipant(combatID, characterID)
 
 	logrus.WithFields(logrus.Fields{
 		"combat_id":    combatID,
@@ -664,9 +676,17 @@ func (s *CombatService) GetStatistics(req *models.GetStatisticsRequest) (*models
 	return s.formatStatisticsResponse(stats, req), nil
 }
 
-// CleanupExpiredCombats nettoie les combats expirés
+// CleanupExpiredCombats nettoie les combats expirés et oublie leurs stats en cache
 func (s *CombatService) CleanupExpiredCombats() error {
-	return s.combatRepo.CleanupExpiredCombats()
+	expired, err := s.combatRepo.CleanupExpiredCombats()
+	if err != nil {
+		return err
+	}
+
+	for _, combatID := range expired {
+		s.statHydrator.Invalidate(combatID)
+	}
+	return nil
 }
 
 // GetActiveCombatCount retourne le nombre de combats actifs
@@ -1082,6 +1102,18 @@ func (s *CombatService) IsParticipantInCombat(characterID uuid.UUID) (bool, *mod
 	return false, nil, nil
 }
 
+// ensureNotInCombat retourne une erreur si le personnage participe déjà à un combat actif
+func (s *CombatService) ensureNotInCombat(characterID uuid.UUID) error {
+	inCombat, combat, err := s.IsParticipantInCombat(characterID)
+	if err != nil {
+		return fmt.Errorf("failed to check combat status: %w", err)
+	}
+	if inCombat {
+		return fmt.Errorf("character %s is already in combat %s", characterID, combat.ID)
+	}
+	return nil
+}
+
 // GetCombatMetrics récupère les métriques du service combat
 func (s *CombatService) GetCombatMetrics() (*models.CombatMetrics, error) {
 	activeCount, err := s.GetActiveCombatCount()
@@ -5,6 +5,9 @@ import (
 	"combat/internal/models"
 	"combat/internal/utils"
 	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
 )
 
 // Constantes pour les types de dégâts
@@ -46,21 +49,27 @@ type DamageCalculatorInterface interface {
 
 // DamageCalculator implémente l'interface DamageCalculatorInterface
 type DamageCalculator struct {
-	config *config.Config
+	statHydrator StatHydratorInterface
+	config       *config.Config
 }
 
 // DamageResult représente le résultat d'un calcul de dégâts
 type DamageResult struct {
-	FinalDamage    int                `json:"final_damage"`
-	BaseDamage     int                `json:"base_damage"`
-	DamageType     string             `json:"damage_type"`
-	IsCritical     bool               `json:"is_critical"`
-	IsBlocked      bool               `json:"is_blocked"`
-	IsMiss         bool               `json:"is_miss"`
-	ArmorReduction int                `json:"armor_reduction"`
-	Modifiers      map[string]float64 `json:"modifiers"`
-	Elements       map[string]int     `json:"elements,omitempty"`
-	Breakdown      []DamageComponent  `json:"breakdown"`
+	FinalDamage    int                       `json:"final_damage"`
+	BaseDamage     int                       `json:"base_damage"`
+	DamageType     string                    `json:"damage_type"`
+	IsCritical     bool                      `json:"is_critical"`
+	IsBlocked      bool                      `json:"is_blocked"`
+	IsMiss         bool                      `json:"is_miss"`
+	ArmorReduction int                       `json:"armor_reduction"`
+	Modifiers      map[string]float64        `json:"modifiers"`
+	Elements       map[string]int            `json:"elements,omitempty"`
+	Element        string                    `json:"element,omitempty"`
+	Reaction       *models.ElementalReaction `json:"reaction,omitempty"`
+	Breakdown      []DamageComponent         `json:"breakdown"`
+
+	// Effets de statut consommés par la réaction, à retirer de la cible
+	ConsumedEffects []uuid.UUID `json:"consumed_effects,omitempty"`
 }
 
 // HealingResult représente le résultat d'un calcul de soins
@@ -81,9 +90,10 @@ type DamageComponent struct {
 }
 
 // NewDamageCalculator crée un nouveau calculateur de dégâts
-func NewDamageCalculator(config *config.Config) DamageCalculatorInterface {
+func NewDamageCalculator(statHydrator StatHydratorInterface, config *config.Config) DamageCalculatorInterface {
 	return &DamageCalculator{
-		config: config,
+		statHydrator: statHydrator,
+		config:       config,
 	}
 }
 
@@ -115,6 +125,7 @@ func (dc *DamageCalculator) CalculateDamage(
 	}
 
 	result.BaseDamage = baseDamage
+	result.Element = dc.resolveElement(attacker, skill)
 
 	// Vérifier si l'attaque touche
 	hitChance := dc.CalculateHitChance(attacker, defender, skill, modifiers)
@@ -138,7 +149,11 @@ func (dc *DamageCalculator) CalculateDamage(
 	case "physical":
 		rawDamage = dc.CalculatePhysicalDamage(attacker, defender, baseDamage, modifiers)
 	case DamageTypeMagical:
-		rawDamage = dc.CalculateMagicalDamage(attacker, defender, baseDamage, modifiers)
+		if result.Element != models.ElementNone {
+			rawDamage = dc.calculateElementalMagicalDamage(attacker, defender, result.Element, baseDamage, modifiers)
+		} else {
+			rawDamage = dc.CalculateMagicalDamage(attacker, defender, baseDamage, modifiers)
+		}
 	case "true":
 		rawDamage = dc.CalculateTrueDamage(baseDamage, modifiers)
 	default:
@@ -184,11 +199,19 @@ func (dc *DamageCalculator) CalculateDamage(
 		})
 	}
 
+	// Appliquer les affinités, résistances, vulnérabilités et réactions élémentaires
+	if result.Element != models.ElementNone {
+		rawDamage = dc.applyElementalModifiers(attacker, defender, result, rawDamage)
+	}
+
 	// Appliquer la variance (±10%)
 	variance := varianceMin + (utils.SecureRandFloat64() * varianceMax)
 	rawDamage = int(float64(rawDamage) * variance)
 
 	result.FinalDamage = rawDamage
+	if result.Element != models.ElementNone {
+		result.Elements[result.Element] = rawDamage
+	}
 
 	// S'assurer que les dégâts ne sont jamais négatifs
 	if result.FinalDamage < 0 {
@@ -449,7 +472,8 @@ func (dc *DamageCalculator) ApplyVulnerabilities(damage int, vulnerabilities map
 func (dc *DamageCalculator) CalculateElementalDamage(attacker *models.CombatParticipant, element string, baseDamage int) int {
 	elementalPower := dc.getElementalPower(attacker, element)
 
-	// Les dégâts élémentaires sont basés sur la puissance magique et l'affinité élémentaire
+	// Les dégâts élémentaires sont basés sur la puissance magique et la puissance de l'élément
+	// (l'affinité du lanceur est appliquée séparément dans applyElementalModifiers)
 	elementalDamage := float64(baseDamage) + (float64(attacker.MagicalDamage) * elementalPower)
 
 	return int(elementalDamage)
@@ -496,23 +520,144 @@ func (dc *DamageCalculator) CalculateStatusEffectChance(
 // Helper methods
 
 func (dc *DamageCalculator) getElementalPower(_ *models.CombatParticipant, element string) float64 {
-	// Pour l'instant, retourner une valeur par défaut
-	// Dans un vrai jeu, cela serait basé sur l'équipement et les stats du personnage
-	elementalPowers := map[string]float64{
-		"fire":      config.DefaultElementalPowerFire,
-		"ice":       config.DefaultElementalPowerIce,
-		"lightning": config.DefaultElementalPowerLightning,
-		"earth":     config.DefaultElementalPowerEarth,
-		"wind":      config.DefaultElementalPowerWind,
-		"dark":      config.DefaultElementalPowerDark,
-		"light":     config.DefaultElementalPowerLight,
+	if !models.IsValidElement(element) {
+		// Élément inconnu: seuls les dégâts de base s'appliquent
+		return 0
+	}
+	return models.GetElementalPower(element)
+}
+
+// resolveElement détermine l'élément d'une attaque: celui de la compétence, sinon celui de l'arme
+func (dc *DamageCalculator) resolveElement(attacker *models.CombatParticipant, skill *models.SkillInfo) string {
+	if skill != nil && models.IsValidElement(skill.Element) {
+		return skill.Element
+	}
+	if skill != nil && skill.Type == DamageTypeMagical {
+		// Les sorts sans élément ne prennent pas celui de l'arme
+		return models.ElementNone
+	}
+
+	profile := dc.getElementalProfile(attacker)
+	if models.IsValidElement(profile.WeaponElement) {
+		return profile.WeaponElement
+	}
+
+	return models.ElementNone
+}
+
+// getElementalProfile retourne le profil élémentaire complet d'un participant:
+// profil hydraté (race, équipement, modificateurs) et effets actifs (buffs, statuts)
+func (dc *DamageCalculator) getElementalProfile(participant *models.CombatParticipant) *models.ElementalProfile {
+	base := participant.Elements
+	if base == nil && dc.statHydrator != nil {
+		// Les participants rechargés depuis la base n'ont pas de profil: utiliser le cache du combat
+		if stats, exists := dc.statHydrator.GetCached(participant.CombatID, participant.CharacterID); exists {
+			base = stats.Elements
+		}
 	}
 
-	if power, exists := elementalPowers[element]; exists {
-		return power
+	profile := base.Clone()
+	profile.Merge(models.BuildElementalProfileFromEffects(participant.ActiveEffects))
+
+	return profile
+}
+
+// calculateElementalMagicalDamage calcule les dégâts d'un sort élémentaire avant résistances
+func (dc *DamageCalculator) calculateElementalMagicalDamage(
+	attacker, defender *models.CombatParticipant,
+	element string,
+	baseDamage int,
+	modifiers map[string]float64,
+) int {
+	damage := float64(dc.CalculateElementalDamage(attacker, element, baseDamage))
+
+	// Appliquer la résistance magique
+	damage = float64(dc.ApplyArmorReduction(int(damage), defender.MagicalDefense, DamageTypeMagical))
+
+	if magicalMod, exists := modifiers["magical_damage_modifier"]; exists {
+		damage *= (1.0 + magicalMod)
 	}
 
-	return config.DefaultFleeChanceBase // Valeur par défaut
+	return int(damage)
+}
+
+// applyElementalModifiers applique affinité, résistance (moins la pénétration), vulnérabilité
+// et réaction élémentaire, en détaillant chaque étape dans le breakdown
+func (dc *DamageCalculator) applyElementalModifiers(
+	attacker, defender *models.CombatParticipant,
+	result *DamageResult,
+	damage int,
+) int {
+	element := result.Element
+	attackerProfile := dc.getElementalProfile(attacker)
+	defenderProfile := dc.getElementalProfile(defender)
+
+	// Affinité de l'attaquant
+	if affinity := attackerProfile.Affinity(element); affinity != 0 {
+		multiplier := 1.0 + affinity
+		boosted := int(float64(damage) * multiplier)
+		result.Breakdown = append(result.Breakdown, DamageComponent{
+			Type:       "elemental_affinity",
+			Value:      boosted - damage,
+			Source:     element,
+			Multiplier: multiplier,
+		})
+		damage = boosted
+	}
+
+	// Résistance du défenseur, réduite par la pénétration de l'attaquant
+	penetration := clampFloat(attackerProfile.PenetrationFor(element), 0, config.DefaultMaxElementalPenetration)
+	resistance := defenderProfile.Resistance(element)
+	if resistance > 0 {
+		resistance = clampFloat(resistance-penetration, 0, config.DefaultMaxElementalResistance)
+	}
+	if resistance != 0 || penetration > 0 {
+		resisted := dc.ApplyResistances(damage, map[string]float64{element: resistance}, element)
+		result.Breakdown = append(result.Breakdown, DamageComponent{
+			Type:       "elemental_resistance",
+			Value:      resisted - damage,
+			Source:     element,
+			Multiplier: 1.0 - resistance,
+		})
+		if penetration > 0 {
+			result.Modifiers["elemental_penetration"] = penetration
+		}
+		damage = resisted
+	}
+
+	// Vulnérabilité du défenseur
+	if vulnerability := clampFloat(defenderProfile.Vulnerability(element), 0, config.DefaultMaxElementalVulnerability); vulnerability > 0 {
+		amplified := dc.ApplyVulnerabilities(damage, map[string]float64{element: vulnerability}, element)
+		result.Breakdown = append(result.Breakdown, DamageComponent{
+			Type:       "elemental_vulnerability",
+			Value:      amplified - damage,
+			Source:     element,
+			Multiplier: 1.0 + vulnerability,
+		})
+		damage = amplified
+	}
+
+	// Réaction avec un statut élémentaire de la cible (ex: trempé + foudre)
+	if reaction := models.FindElementalReaction(defenderProfile, element); reaction != nil {
+		reacted := int(float64(damage) * reaction.Multiplier)
+		result.Breakdown = append(result.Breakdown, DamageComponent{
+			Type:       "elemental_reaction",
+			Value:      reacted - damage,
+			Source:     reaction.ID,
+			Multiplier: reaction.Multiplier,
+		})
+		result.Reaction = reaction
+		damage = reacted
+
+		// Le statut consommé ne déclenche plus la réaction aux coups suivants
+		if reaction.ConsumesStatus {
+			for _, effect := range models.ConsumeElementalStatus(defender.ActiveEffects, reaction.Status) {
+				result.ConsumedEffects = append(result.ConsumedEffects, effect.ID)
+			}
+		}
+	}
+
+	return damage
 }
 
 // CalculateLifesteal calcule le vol de vie
@@ -699,15 +844,30 @@ func (dc *DamageCalculator) GetDamageBreakdown(result *DamageResult) string {
 	}
 
 	breakdown := fmt.Sprintf("Base damage: %d\n", result.BaseDamage)
+	if result.Element != models.ElementNone {
+		breakdown += fmt.Sprintf("Element: %s\n", result.Element)
+	}
 
 	for _, component := range result.Breakdown {
+		label := component.Type
+		if strings.HasPrefix(component.Type, "elemental_") && component.Source != "" {
+			label = fmt.Sprintf("%s [%s]", component.Type, component.Source)
+		}
+
 		if component.Multiplier > 0 {
-			breakdown += fmt.Sprintf("%s: %d (x%.2f)\n", component.Type, component.Value, component.Multiplier)
+			breakdown += fmt.Sprintf("%s: %d (x%.2f)\n", label, component.Value, component.Multiplier)
 		} else {
-			breakdown += fmt.Sprintf("%s: %d\n", component.Type, component.Value)
+			breakdown += fmt.Sprintf("%s: %d\n", label, component.Value)
 		}
 	}
 
+	if penetration, exists := result.Modifiers["elemental_penetration"]; exists {
+		breakdown += fmt.Sprintf("Resistance penetration: %.0f%%\n", penetration*config.DefaultPercentDivisor)
+	}
+	if result.Reaction != nil {
+		breakdown += fmt.Sprintf("Reaction: %s (%s + %s)\n", result.Reaction.Name, result.Reaction.Status, result.Reaction.Element)
+	}
+
 	breakdown += fmt.Sprintf("Final damage: %d", result.FinalDamage)
 
 	return breakdown
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"combat/internal/config"
+	"combat/internal/models"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// drKey identifie une catégorie de contrôle sur une cible
+type drKey struct {
+	targetID uuid.UUID
+	category string
+}
+
+// drEntry historique des applications d'une catégorie dans la fenêtre courante
+type drEntry struct {
+	applications  int
+	lastAppliedAt time.Time
+}
+
+// DiminishingReturnsTracker suit les contrôles appliqués à chaque cible pour réduire
+// leur durée lors d'applications répétées, puis immuniser la cible
+type DiminishingReturnsTracker struct {
+	mu      sync.Mutex
+	entries map[drKey]*drEntry
+}
+
+// NewDiminishingReturnsTracker crée un nouveau suivi des rendements décroissants
+func NewDiminishingReturnsTracker() *DiminishingReturnsTracker {
+	return &DiminishingReturnsTracker{
+		entries: make(map[drKey]*drEntry),
+	}
+}
+
+// Apply vérifie et enregistre une application sous un même verrou, pour que deux contrôles
+// simultanés sur la même cible ne lisent pas le même niveau. Une cible immunisée n'est pas
+// enregistrée ; si l'effet n'est finalement pas appliqué, Revert annule l'enregistrement.
+func (t *DiminishingReturnsTracker) Apply(
+	targetID uuid.UUID,
+	category string,
+	rule config.DiminishingReturnsRule,
+	now time.Time,
+) *models.DiminishingReturnsState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := drKey{targetID: targetID, category: category}
+	state := t.stateLocked(key, rule, now)
+	if state.Immune {
+		return state
+	}
+
+	entry, exists := t.entries[key]
+	if !exists {
+		entry = &drEntry{}
+		t.entries[key] = entry
+	}
+	entry.applications++
+	entry.lastAppliedAt = now
+
+	resetsAt := now.Add(rule.Window)
+	state.ResetsAt = &resetsAt
+
+	return state
+}
+
+// Revert annule une application enregistrée par Apply pour un effet qui n'a pas été appliqué
+func (t *DiminishingReturnsTracker) Revert(targetID uuid.UUID, category string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := drKey{targetID: targetID, category: category}
+	entry, exists := t.entries[key]
+	if !exists {
+		return
+	}
+	entry.applications--
+	if entry.applications <= 0 {
+		delete(t.entries, key)
+	}
+}
+
+// Reset supprime l'historique d'une cible (fin de combat, mort...)
+func (t *DiminishingReturnsTracker) Reset(targetID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key := range t.entries {
+		if key.targetID == targetID {
+			delete(t.entries, key)
+		}
+	}
+}
+
+// CleanupExpired supprime les entrées dont la fenêtre est écoulée
+func (t *DiminishingReturnsTracker) CleanupExpired(window time.Duration, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, entry := range t.entries {
+		if now.Sub(entry.lastAppliedAt) > window {
+			delete(t.entries, key)
+		}
+	}
+}
+
+func (t *DiminishingReturnsTracker) stateLocked(
+	key drKey,
+	rule config.DiminishingReturnsRule,
+	now time.Time,
+) *models.DiminishingReturnsState {
+	entry, exists := t.entries[key]
+	if exists && now.Sub(entry.lastAppliedAt) > rule.Window {
+		// Fenêtre écoulée: les rendements décroissants repartent de zéro
+		delete(t.entries, key)
+		exists = false
+	}
+
+	applications := 0
+	if exists {
+		applications = entry.applications
+	}
+
+	state := &models.DiminishingReturnsState{
+		Category:           key.category,
+		Level:              applications + 1,
+		DurationMultiplier: 1.0,
+	}
+
+	if applications >= len(rule.Multipliers) {
+		state.Immune = true
+		state.ImmunitySource = models.ImmunitySourceDiminishingReturns
+		state.DurationMultiplier = 0
+		if exists {
+			resetsAt := entry.lastAppliedAt.Add(rule.Window)
+			state.ResetsAt = &resetsAt
+		}
+		return state
+	}
+
+	state.DurationMultiplier = rule.Multipliers[applications]
+	return state
+}
+
+// scaleDuration applique un multiplicateur de rendements décroissants à une durée en tours
+func scaleDuration(turns int, multiplier float64) int {
+	scaled := int(math.Round(float64(turns) * multiplier))
+	if scaled < 1 {
+		return 1
+	}
+	return scaled
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"combat/internal/config"
+	"combat/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testDRRule() config.DiminishingReturnsRule {
+	return config.DiminishingReturnsRule{
+		Enabled:     true,
+		Window:      18 * time.Second,
+		Multipliers: []float64{1, 0.5, 0.25},
+	}
+}
+
+func TestDiminishingReturnsApply(t *testing.T) {
+	tracker := NewDiminishingReturnsTracker()
+	target := uuid.New()
+	rule := testDRRule()
+	now := time.Now()
+
+	for i, want := range []float64{1, 0.5, 0.25} {
+		state := tracker.Apply(target, "stun", rule, now.Add(time.Duration(i)*time.Second))
+		if state.Immune || state.DurationMultiplier != want || state.Level != i+1 {
+			t.Fatalf("application %d: level %d, multiplier %v, immune %v; want level %d, multiplier %v",
+				i+1, state.Level, state.DurationMultiplier, state.Immune, i+1, want)
+		}
+	}
+
+	state := tracker.Apply(target, "stun", rule, now.Add(3*time.Second))
+	if !state.Immune || state.ImmunitySource != models.ImmunitySourceDiminishingReturns {
+		t.Fatalf("fourth application: immune %v, source %q", state.Immune, state.ImmunitySource)
+	}
+	if state.ResetsAt == nil || !state.ResetsAt.Equal(now.Add(2*time.Second+rule.Window)) {
+		t.Errorf("ResetsAt = %v, want last application + window", state.ResetsAt)
+	}
+
+	// Une autre catégorie ou une autre cible ont leur propre historique
+	if state = tracker.Apply(target, "root", rule, now); state.Level != 1 {
+		t.Errorf("other category level = %d, want 1", state.Level)
+	}
+	if state = tracker.Apply(uuid.New(), "stun", rule, now); state.Level != 1 {
+		t.Errorf("other target level = %d, want 1", state.Level)
+	}
+}
+
+// TestDiminishingReturnsWindowReset la fenêtre écoulée depuis la dernière application remet le niveau à zéro
+func TestDiminishingReturnsWindowReset(t *testing.T) {
+	tracker := NewDiminishingReturnsTracker()
+	target := uuid.New()
+	rule := testDRRule()
+	now := time.Now()
+
+	for i := 0; i < len(rule.Multipliers); i++ {
+		tracker.Apply(target, "stun", rule, now)
+	}
+	if state := tracker.Apply(target, "stun", rule, now.Add(rule.Window)); !state.Immune {
+		t.Fatal("target not immune before the end of the window")
+	}
+	if state := tracker.Apply(target, "stun", rule, now.Add(rule.Window+time.Second)); state.Immune || state.Level != 1 {
+		t.Fatalf("after the window: level %d, immune %v; want level 1", state.Level, state.Immune)
+	}
+}
+
+// TestDiminishingReturnsRevert une application annulée ne compte pas pour la suivante
+func TestDiminishingReturnsRevert(t *testing.T) {
+	tracker := NewDiminishingReturnsTracker()
+	target := uuid.New()
+	rule := testDRRule()
+	now := time.Now()
+
+	tracker.Apply(target, "stun", rule, now)
+	tracker.Apply(target, "stun", rule, now)
+	tracker.Revert(target, "stun")
+	if state := tracker.Apply(target, "stun", rule, now); state.Level != 2 {
+		t.Fatalf("level after revert = %d, want 2", state.Level)
+	}
+
+	tracker.Revert(target, "stun")
+	tracker.Revert(target, "stun")
+	tracker.Revert(target, "stun") // sans historique : sans effet
+	if state := tracker.Apply(target, "stun", rule, now); state.Level != 1 {
+		t.Fatalf("level after reverting everything = %d, want 1", state.Level)
+	}
+}
+
+func TestDiminishingReturnsResetAndCleanup(t *testing.T) {
+	tracker := NewDiminishingReturnsTracker()
+	reset, expired, recent := uuid.New(), uuid.New(), uuid.New()
+	rule := testDRRule()
+	now := time.Now()
+
+	tracker.Apply(reset, "stun", rule, now)
+	tracker.Apply(reset, "root", rule, now)
+	tracker.Apply(expired, "stun", rule, now.Add(-time.Minute))
+	tracker.Apply(recent, "stun", rule, now)
+
+	tracker.Reset(reset)
+	tracker.CleanupExpired(rule.Window, now)
+
+	if len(tracker.entries) != 1 {
+		t.Fatalf("%d entries left, want 1", len(tracker.entries))
+	}
+	if _, exists := tracker.entries[drKey{targetID: recent, category: "stun"}]; !exists {
+		t.Fatal("recent entry removed by cleanup")
+	}
+}
+
+func TestScaleDuration(t *testing.T) {
+	tests := []struct {
+		turns      int
+		multiplier float64
+		want       int
+	}{
+		{4, 1, 4},
+		{4, 0.5, 2},
+		{3, 0.5, 2},
+		{4, 0.25, 1},
+		{1, 0.25, 1},
+	}
+	for _, tt := range tests {
+		if got := scaleDuration(tt.turns, tt.multiplier); got != tt.want {
+			t.Errorf("scaleDuration(%d, %v) = %d, want %d", tt.turns, tt.multiplier, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"combat/internal/models"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// DuelMonitor garde en mémoire les zones des duels en cours, indexées par combat
+// Les zones sont aussi enregistrées avec leur défi PvP, relues par StartDuelBoundaryRoutine au démarrage.
+type DuelMonitor struct {
+	mu    sync.RWMutex
+	duels map[uuid.UUID]*models.DuelBoundary
+}
+
+// NewDuelMonitor crée un nouveau suivi des zones de duel
+func NewDuelMonitor() *DuelMonitor {
+	return &DuelMonitor{
+		duels: make(map[uuid.UUID]*models.DuelBoundary),
+	}
+}
+
+// Track commence le suivi d'un duel
+func (m *DuelMonitor) Track(boundary *models.DuelBoundary) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.duels[boundary.CombatID] = boundary
+}
+
+// Untrack arrête le suivi d'un duel
+func (m *DuelMonitor) Untrack(combatID uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.duels, combatID)
+}
+
+// Active retourne une copie de la liste des duels suivis
+func (m *DuelMonitor) Active() []*models.DuelBoundary {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	duels := make([]*models.DuelBoundary, 0, len(m.duels))
+	for _, boundary := range m.duels {
+		duels = append(duels, boundary)
+	}
+	return duels
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"combat/internal/clients"
 	"combat/internal/config"
 	"combat/internal/models"
 	"combat/internal/repository"
@@ -36,6 +37,8 @@ type EffectServiceInterface interface {
 type EffectService struct {
 	effectRepo repository.EffectRepositoryInterface
 	combatRepo repository.CombatRepositoryInterface
+	drTracker  *DiminishingReturnsTracker
+	immunities *CCImmunityResolver
 	config     *config.Config
 }
 
@@ -43,11 +46,14 @@ type EffectService struct {
 func NewEffectService(
 	effectRepo repository.EffectRepositoryInterface,
 	combatRepo repository.CombatRepositoryInterface,
+	worldClient clients.WorldClientInterface,
 	config *config.Config,
 ) EffectServiceInterface {
 	return &EffectService{
 		effectRepo: effectRepo,
 		combatRepo: combatRepo,
+		drTracker:  NewDiminishingReturnsTracker(),
+		immunities: NewCCImmunityResolver(worldClient, config),
 		config:     config,
 	}
 }
@@ -201,22 +207,139 @@ func (s *EffectService) ApplyEffect(req *models.ApplyEffectRequest) (*models.Eff
 		Metadata:       req.Metadata,
 	}
 
+	// Appliquer les immunités et rendements décroissants des contrôles
+	category := models.GetCCCategory(template)
+	var drState *models.DiminishingReturnsState
+	if category != "" {
+		var immuneResult *models.EffectResult
+		drState, immuneResult = s.applyCrowdControlRules(req, template, application, category)
+		if immuneResult != nil {
+			return immuneResult, nil
+		}
+	}
+
 	// Vérifier si un effet similaire existe déjà
 	existingEffect, err := s.findExistingEffect(req.TargetID, template)
 	if err != nil {
+		if drState != nil {
+			s.drTracker.Revert(req.TargetID, category)
+		}
 		return &models.EffectResult{
 			Success: false,
 			Error:   err.Error(),
 		}, nil
 	}
 
-	// Si l'effet existe déjà, le gérer
+	var result *models.EffectResult
 	if existingEffect != nil {
-		return s.handleExistingEffect(existingEffect, template, application)
+		// Si l'effet existe déjà, le gérer
+		result, err = s.handleExistingEffect(existingEffect, template, application)
+	} else {
+		// Créer un nouvel effet
+		result, err = s.createNewEffect(template, application)
 	}
 
-	// Créer un nouvel effet
-	return s.createNewEffect(template, application)
+	// L'application réservée par applyCrowdControlRules est annulée si l'effet n'a pas pris
+	if drState != nil {
+		if err == nil && result.Success {
+			result.DiminishingReturns = drState
+		} else {
+			s.drTracker.Revert(req.TargetID, category)
+		}
+	}
+
+	return result, err
+}
+
+// applyCrowdControlRules vérifie les immunités de la cible et réduit la durée d'un contrôle
+// selon les rendements décroissants du type de combat. Retourne un résultat si la cible est immunisée,
+// sinon l'état des rendements décroissants, dont l'application est déjà enregistrée (nil s'ils
+// sont désactivés pour ce type de combat)
+func (s *EffectService) applyCrowdControlRules(
+	req *models.ApplyEffectRequest,
+	template *models.EffectTemplate,
+	application *models.EffectApplication,
+	category string,
+) (*models.DiminishingReturnsState, *models.EffectResult) {
+	// Immunités du modèle de NPC (boss), connues du seul service world : sans réponse de
+	// celui-ci, le contrôle suit les règles habituelles
+	immunities, err := s.immunities.Immunities(req.TargetID, time.Now())
+	if err != nil {
+		logrus.WithError(err).WithField("target_id", req.TargetID).Warn("Failed to resolve crowd control immunities")
+	}
+	if models.HasCCImmunity(immunities, category) {
+		return nil, &models.EffectResult{
+			Success: false,
+			Action:  "immune",
+			Message: fmt.Sprintf("Target is immune to %s effects", category),
+			DiminishingReturns: &models.DiminishingReturnsState{
+				Category:       category,
+				Immune:         true,
+				ImmunitySource: models.ImmunitySourceBoss,
+			},
+		}
+	}
+
+	combatType := models.CombatTypePvE
+	if combat := s.resolveEffectCombat(req); combat != nil {
+		combatType = combat.CombatType
+	}
+
+	rule := s.config.Combat.DiminishingReturns.RuleFor(string(combatType))
+	if !rule.Enabled {
+		return nil, nil
+	}
+
+	state := s.drTracker.Apply(req.TargetID, category, rule, time.Now())
+	if state.Immune {
+		logrus.WithFields(logrus.Fields{
+			"target_id":   req.TargetID,
+			"effect_name": template.Name,
+			"category":    category,
+			"combat_type": combatType,
+		}).Debug("Crowd control blocked by diminishing returns")
+
+		return nil, &models.EffectResult{
+			Success:            false,
+			Action:             "immune",
+			Message:            fmt.Sprintf("Target is immune to %s effects (diminishing returns)", category),
+			DiminishingReturns: state,
+		}
+	}
+
+	baseDuration := template.BaseDuration
+	if application.Duration > 0 {
+		baseDuration = application.Duration
+	}
+	application.Duration = scaleDuration(baseDuration, state.DurationMultiplier)
+
+	return state, nil
+}
+
+// resolveEffectCombat retrouve le combat dans lequel l'effet est appliqué
+func (s *EffectService) resolveEffectCombat(req *models.ApplyEffectRequest) *models.CombatInstance {
+	if req.CombatID != nil {
+		combat, err := s.combatRepo.GetByID(*req.CombatID)
+		if err != nil {
+			logrus.WithError(err).WithField("combat_id", *req.CombatID).Warn("Failed to load combat for effect")
+			return nil
+		}
+		return combat
+	}
+
+	combats, err := s.combatRepo.GetByParticipant(req.TargetID)
+	if err != nil {
+		logrus.WithError(err).WithField("target_id", req.TargetID).Warn("Failed to find target combat for effect")
+		return nil
+	}
+
+	for _, combat := range combats {
+		if combat.Status == models.CombatStatusActive {
+			return combat
+		}
+	}
+
+	return nil
 }
 
 // RemoveEffect supprime un effet
@@ -445,6 +568,10 @@ func (s *EffectService) CleanupExpiredEffects(combatID uuid.UUID) error {
 		}).Debug("Cleaned up expired effects")
 	}
 
+	// Oublier les rendements décroissants dont la fenêtre est écoulée
+	s.drTracker.CleanupExpired(s.config.Combat.DiminishingReturns.MaxWindow(), time.Now())
+	s.immunities.CleanupExpired(time.Now())
+
 	return nil
 }
 
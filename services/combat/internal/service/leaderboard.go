@@ -0,0 +1,403 @@
+package service
+
+import (
+	"combat/internal/config"
+	"combat/internal/models"
+	"combat/internal/repository"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// LeaderboardInterface définit les méthodes des classements PvP en mémoire
+type LeaderboardInterface interface {
+	// Chargement et synchronisation
+	Load() error
+	StartRoutines()
+
+	// Mise à jour
+	RecordMatch(update *LadderUpdate) (*models.LadderEntry, error)
+
+	// Lecture
+	ResolveKey(season string, queueType models.ChallengeType, class, region string) models.LadderKey
+	GetPage(key models.LadderKey, offset, limit int) ([]*models.PvPRanking, int)
+	GetPlayerRank(key models.LadderKey, characterID uuid.UUID) *models.PvPRanking
+	GetAround(key models.LadderKey, characterID uuid.UUID, window int) []*models.PvPRanking
+
+	// Snapshots historiques
+	TakeSnapshots() error
+	GetSnapshots(key models.LadderKey, limit int) ([]*models.LeaderboardSnapshot, error)
+	GetSnapshotPage(snapshotID uuid.UUID, offset, limit int) (*models.LeaderboardSnapshot, []*models.PvPRanking, error)
+}
+
+// LadderUpdate représente le résultat d'un match pour un joueur
+type LadderUpdate struct {
+	CharacterID uuid.UUID
+	Season      string
+	QueueType   models.ChallengeType
+	Class       string
+	Region      string
+	Rating      int
+	Result      models.ResultType
+}
+
+// Leaderboard maintient en mémoire un classement trié par saison, file, classe et région.
+// Postgres (pvp_ladder_entries) reste la source de vérité: chaque mise à jour y est
+// écrite avant d'être appliquée en mémoire, et les classements sont rechargés
+// périodiquement pour intégrer les écritures des autres instances.
+type Leaderboard struct {
+	repo    repository.LeaderboardRepositoryInterface
+	config  *config.Config
+	mu      sync.RWMutex
+	ladders map[models.LadderKey]*rankedLadder
+
+	// Pendant un rechargement, les résultats enregistrés sont aussi gardés pour être
+	// rejoués sur les classements reconstruits avant l'échange
+	loadMu  sync.Mutex
+	loading bool
+	replay  []ladderChange
+}
+
+// ladderChange mise à jour d'un joueur appliquée aux classements en mémoire
+type ladderChange struct {
+	previous *models.LadderEntry
+	updated  *models.LadderEntry
+}
+
+// NewLeaderboard crée un nouveau service de classements
+func NewLeaderboard(repo repository.LeaderboardRepositoryInterface, config *config.Config) LeaderboardInterface {
+	return &Leaderboard{
+		repo:    repo,
+		config:  config,
+		ladders: make(map[models.LadderKey]*rankedLadder),
+	}
+}
+
+// Load reconstruit les classements de la saison courante depuis Postgres
+// Les classements sont construits à part puis échangés pour ne pas bloquer les lectures ;
+// les résultats enregistrés pendant la lecture sont rejoués avant l'échange.
+func (l *Leaderboard) Load() error {
+	l.loadMu.Lock()
+	defer l.loadMu.Unlock()
+
+	l.mu.Lock()
+	l.loading = true
+	l.replay = nil
+	l.mu.Unlock()
+
+	ladders, loaded, err := l.scan()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	replayed := len(l.replay)
+	if err == nil {
+		for _, change := range l.replay {
+			applyLadderChange(ladders, change)
+		}
+		l.ladders = ladders
+	}
+	l.loading = false
+	l.replay = nil
+
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"season":   l.config.Leaderboard.CurrentSeason,
+		"entries":  loaded,
+		"ladders":  len(ladders),
+		"replayed": replayed,
+	}).Info("PvP leaderboards loaded")
+
+	return nil
+}
+
+// scan lit les entrées de la saison courante par lots et construit les classements
+func (l *Leaderboard) scan() (map[models.LadderKey]*rankedLadder, int, error) {
+	season := l.config.Leaderboard.CurrentSeason
+	ladders := make(map[models.LadderKey]*rankedLadder)
+
+	var afterQueue models.ChallengeType
+	afterCharacter := uuid.Nil
+	loaded := 0
+
+	for {
+		entries, err := l.repo.GetLadderEntriesAfter(season, afterQueue, afterCharacter, l.config.Leaderboard.LoadBatchSize)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to load ladder entries: %w", err)
+		}
+
+		for _, entry := range entries {
+			for _, key := range entry.Keys() {
+				ladder, exists := ladders[key]
+				if !exists {
+					ladder = newRankedLadder()
+					ladders[key] = ladder
+				}
+				ladder.Upsert(entry)
+			}
+		}
+		loaded += len(entries)
+
+		if len(entries) < l.config.Leaderboard.LoadBatchSize {
+			break
+		}
+		last := entries[len(entries)-1]
+		afterQueue, afterCharacter = last.QueueType, last.CharacterID
+	}
+
+	return ladders, loaded, nil
+}
+
+// StartRoutines démarre la resynchronisation et les snapshots périodiques
+func (l *Leaderboard) StartRoutines() {
+	resyncTicker := time.NewTicker(l.config.Leaderboard.ResyncInterval)
+	go func() {
+		defer resyncTicker.Stop()
+		for range resyncTicker.C {
+			if err := l.Load(); err != nil {
+				logrus.WithError(err).Error("Failed to resync leaderboards")
+			}
+		}
+	}()
+
+	snapshotTicker := time.NewTicker(l.config.Leaderboard.SnapshotInterval)
+	go func() {
+		defer snapshotTicker.Stop()
+		for range snapshotTicker.C {
+			if err := l.TakeSnapshots(); err != nil {
+				logrus.WithError(err).Error("Failed to take leaderboard snapshots")
+			}
+		}
+	}()
+}
+
+// RecordMatch enregistre le résultat d'un match pour un joueur
+func (l *Leaderboard) RecordMatch(update *LadderUpdate) (*models.LadderEntry, error) {
+	key := l.ResolveKey(update.Season, update.QueueType, "", "")
+
+	// Ancienne entrée, pour retirer le joueur des classements qu'il quitte
+	previous, err := l.repo.GetLadderEntry(key.Season, key.QueueType, update.CharacterID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrLadderEntryNotFound) {
+			return nil, fmt.Errorf("failed to load ladder entry: %w", err)
+		}
+		previous = &models.LadderEntry{
+			CharacterID: update.CharacterID,
+			Season:      key.Season,
+			QueueType:   key.QueueType,
+		}
+	}
+
+	// Compteurs et série incrémentés par la base, à partir de la ligne courante
+	now := time.Now()
+	updated, err := l.repo.RecordLadderResult(&models.LadderEntry{
+		CharacterID: update.CharacterID,
+		Season:      key.Season,
+		QueueType:   key.QueueType,
+		Class:       update.Class,
+		Region:      update.Region,
+		Rating:      update.Rating,
+		LastMatchAt: &now,
+	}, update.Result)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Season != l.config.Leaderboard.CurrentSeason {
+		// Seule la saison courante est gardée en mémoire
+		return updated, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	change := ladderChange{previous: previous, updated: updated}
+	applyLadderChange(l.ladders, change)
+	if l.loading {
+		l.replay = append(l.replay, change)
+	}
+
+	return updated, nil
+}
+
+// applyLadderChange place le joueur dans ses classements, en le retirant de ceux qu'il quitte
+func applyLadderChange(ladders map[models.LadderKey]*rankedLadder, change ladderChange) {
+	previous, updated := change.previous, change.updated
+	if previous.Class != updated.Class || previous.Region != updated.Region {
+		for _, oldKey := range previous.Keys() {
+			if ladder, exists := ladders[oldKey]; exists {
+				ladder.Remove(updated.CharacterID)
+			}
+		}
+	}
+
+	for _, ladderKey := range updated.Keys() {
+		ladder, exists := ladders[ladderKey]
+		if !exists {
+			ladder = newRankedLadder()
+			ladders[ladderKey] = ladder
+		}
+		ladder.Upsert(updated)
+	}
+}
+
+// ResolveKey normalise la clé d'un classement (saison "current" et file par défaut)
+func (l *Leaderboard) ResolveKey(season string, queueType models.ChallengeType, class, region string) models.LadderKey {
+	if season == "" || season == "current" {
+		season = l.config.Leaderboard.CurrentSeason
+	}
+	if queueType == "" {
+		queueType = models.ChallengeTypeArena
+	}
+
+	return models.LadderKey{
+		Season:    season,
+		QueueType: queueType,
+		Class:     class,
+		Region:    region,
+	}
+}
+
+// GetPage retourne une page du classement et le nombre total de joueurs classés
+func (l *Leaderboard) GetPage(key models.LadderKey, offset, limit int) ([]*models.PvPRanking, int) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	ladder, exists := l.ladders[key]
+	if !exists {
+		return []*models.PvPRanking{}, 0
+	}
+
+	return toRankings(ladder.Range(offset+1, limit), offset+1), ladder.Len()
+}
+
+// GetPlayerRank retourne le rang d'un joueur, nil s'il n'est pas classé
+func (l *Leaderboard) GetPlayerRank(key models.LadderKey, characterID uuid.UUID) *models.PvPRanking {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	ladder, exists := l.ladders[key]
+	if !exists {
+		return nil
+	}
+
+	rank, entry := ladder.Rank(characterID)
+	if entry == nil {
+		return nil
+	}
+
+	return entry.ToRanking(rank)
+}
+
+// GetAround retourne les joueurs classés autour d'un joueur (window rangs de chaque côté)
+func (l *Leaderboard) GetAround(key models.LadderKey, characterID uuid.UUID, window int) []*models.PvPRanking {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	ladder, exists := l.ladders[key]
+	if !exists {
+		return []*models.PvPRanking{}
+	}
+
+	rank, entry := ladder.Rank(characterID)
+	if entry == nil {
+		return []*models.PvPRanking{}
+	}
+
+	start := rank - window
+	if start < 1 {
+		start = 1
+	}
+
+	return toRankings(ladder.Range(start, rank-start+window+1), start)
+}
+
+// TakeSnapshots enregistre le haut de chaque classement de la saison courante
+func (l *Leaderboard) TakeSnapshots() error {
+	type pendingSnapshot struct {
+		snapshot *models.LeaderboardSnapshot
+		rankings []*models.PvPRanking
+	}
+
+	// Copie sous verrou de lecture, écriture en base hors verrou
+	now := time.Now()
+	l.mu.RLock()
+	pending := make([]pendingSnapshot, 0, len(l.ladders))
+	for key, ladder := range l.ladders {
+		if ladder.Len() == 0 {
+			continue
+		}
+		pending = append(pending, pendingSnapshot{
+			snapshot: &models.LeaderboardSnapshot{
+				ID:           uuid.New(),
+				Season:       key.Season,
+				QueueType:    key.QueueType,
+				Class:        key.Class,
+				Region:       key.Region,
+				TotalPlayers: ladder.Len(),
+				TakenAt:      now,
+			},
+			rankings: toRankings(ladder.Range(1, l.config.Leaderboard.SnapshotSize), 1),
+		})
+	}
+	l.mu.RUnlock()
+
+	var failed int
+	for _, p := range pending {
+		if err := l.repo.CreateSnapshot(p.snapshot, p.rankings); err != nil {
+			failed++
+			logrus.WithError(err).WithField("ladder", models.LadderKey{
+				Season:    p.snapshot.Season,
+				QueueType: p.snapshot.QueueType,
+				Class:     p.snapshot.Class,
+				Region:    p.snapshot.Region,
+			}.String()).Error("Failed to snapshot leaderboard")
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to snapshot %d of %d leaderboards", failed, len(pending))
+	}
+
+	logrus.WithField("ladders", len(pending)).Info("Leaderboard snapshots taken")
+	return nil
+}
+
+// GetSnapshots liste les derniers snapshots d'un classement
+func (l *Leaderboard) GetSnapshots(key models.LadderKey, limit int) ([]*models.LeaderboardSnapshot, error) {
+	return l.repo.GetSnapshots(key, limit)
+}
+
+// GetSnapshotPage retourne un snapshot et une page de ses lignes
+func (l *Leaderboard) GetSnapshotPage(
+	snapshotID uuid.UUID,
+	offset, limit int,
+) (*models.LeaderboardSnapshot, []*models.PvPRanking, error) {
+	snapshot, err := l.repo.GetSnapshot(snapshotID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rankings, err := l.repo.GetSnapshotEntries(snapshotID, offset, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return snapshot, rankings, nil
+}
+
+// toRankings convertit des entrées consécutives en lignes de classement à partir de firstRank
+func toRankings(entries []*models.LadderEntry, firstRank int) []*models.PvPRanking {
+	rankings := make([]*models.PvPRanking, len(entries))
+	for i, entry := range entries {
+		rankings[i] = entry.ToRanking(firstRank + i)
+	}
+	return rankings
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"bytes"
+	"combat/internal/config"
+	"combat/internal/models"
+	"combat/internal/repository"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// memoryLadderRepository entrées de classement en mémoire
+// onScan est appelé une fois, après la lecture du premier lot et avant son retour : il
+// simule un résultat enregistré par une autre requête pendant le rechargement.
+type memoryLadderRepository struct {
+	repository.LeaderboardRepositoryInterface
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]*models.LadderEntry
+	onScan  func()
+}
+
+func (r *memoryLadderRepository) GetLadderEntry(_ string, _ models.ChallengeType, characterID uuid.UUID) (*models.LadderEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, exists := r.entries[characterID]
+	if !exists {
+		return nil, repository.ErrLadderEntryNotFound
+	}
+	copied := *entry
+	return &copied, nil
+}
+
+func (r *memoryLadderRepository) RecordLadderResult(entry *models.LadderEntry, _ models.ResultType) (*models.LadderEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *entry
+	r.entries[entry.CharacterID] = &stored
+	copied := stored
+	return &copied, nil
+}
+
+func (r *memoryLadderRepository) GetLadderEntriesAfter(
+	_ string, afterQueue models.ChallengeType, afterCharacter uuid.UUID, limit int,
+) ([]*models.LadderEntry, error) {
+	r.mu.Lock()
+	batch := make([]*models.LadderEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		if entry.QueueType > afterQueue ||
+			(entry.QueueType == afterQueue && bytes.Compare(entry.CharacterID[:], afterCharacter[:]) > 0) {
+			copied := *entry
+			batch = append(batch, &copied)
+		}
+	}
+	onScan := r.onScan
+	r.onScan = nil
+	r.mu.Unlock()
+
+	sort.Slice(batch, func(i, j int) bool {
+		if batch[i].QueueType != batch[j].QueueType {
+			return batch[i].QueueType < batch[j].QueueType
+		}
+		return bytes.Compare(batch[i].CharacterID[:], batch[j].CharacterID[:]) < 0
+	})
+	if len(batch) > limit {
+		batch = batch[:limit]
+	}
+
+	if onScan != nil {
+		onScan()
+	}
+	return batch, nil
+}
+
+func newTestLeaderboard(repo *memoryLadderRepository) *Leaderboard {
+	return NewLeaderboard(repo, &config.Config{
+		Leaderboard: config.LeaderboardConfig{CurrentSeason: "s1", LoadBatchSize: 2},
+	}).(*Leaderboard)
+}
+
+func arenaEntry(id uuid.UUID, rating int, class string) *models.LadderEntry {
+	return &models.LadderEntry{CharacterID: id, Season: "s1", QueueType: models.ChallengeTypeArena, Class: class, Rating: rating}
+}
+
+// TestLeaderboardLoadReplaysConcurrentMatches un résultat enregistré pendant la lecture
+// d'un rechargement n'est pas perdu à l'échange des classements
+func TestLeaderboardLoadReplaysConcurrentMatches(t *testing.T) {
+	existing, moved, newcomer := seqID(1), seqID(2), seqID(3)
+	repo := &memoryLadderRepository{entries: map[uuid.UUID]*models.LadderEntry{
+		existing: arenaEntry(existing, 1500, "mage"),
+		moved:    arenaEntry(moved, 1400, "mage"),
+	}}
+	leaderboard := newTestLeaderboard(repo)
+	if err := leaderboard.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	repo.onScan = func() {
+		for _, update := range []*LadderUpdate{
+			{CharacterID: moved, QueueType: models.ChallengeTypeArena, Class: "warrior", Rating: 1700, Result: models.ResultTypeVictory},
+			{CharacterID: newcomer, QueueType: models.ChallengeTypeArena, Class: "mage", Rating: 1450, Result: models.ResultTypeVictory},
+		} {
+			if _, err := leaderboard.RecordMatch(update); err != nil {
+				t.Errorf("RecordMatch: %v", err)
+			}
+		}
+	}
+	if err := leaderboard.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	global := leaderboard.ResolveKey("s1", models.ChallengeTypeArena, "", "")
+	for id, want := range map[uuid.UUID]int{moved: 1, existing: 2, newcomer: 3} {
+		ranking := leaderboard.GetPlayerRank(global, id)
+		if ranking == nil || ranking.Rank != want {
+			t.Errorf("global rank of %s = %v, want %d", id, ranking, want)
+		}
+	}
+
+	mage := leaderboard.ResolveKey("s1", models.ChallengeTypeArena, "mage", "")
+	if ranking := leaderboard.GetPlayerRank(mage, moved); ranking != nil {
+		t.Errorf("player still ranked in the class ladder they left: %v", ranking)
+	}
+	warrior := leaderboard.ResolveKey("s1", models.ChallengeTypeArena, "warrior", "")
+	if ranking := leaderboard.GetPlayerRank(warrior, moved); ranking == nil || ranking.Rank != 1 {
+		t.Errorf("warrior rank = %v, want 1", ranking)
+	}
+	if _, total := leaderboard.GetPage(global, 0, 10); total != 3 {
+		t.Errorf("global ladder size = %d, want 3", total)
+	}
+}
+
+func TestLeaderboardGetAround(t *testing.T) {
+	repo := &memoryLadderRepository{entries: map[uuid.UUID]*models.LadderEntry{}}
+	for i := byte(1); i <= 9; i++ {
+		repo.entries[seqID(i)] = arenaEntry(seqID(i), 2000-int(i)*10, "")
+	}
+	leaderboard := newTestLeaderboard(repo)
+	if err := leaderboard.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	key := leaderboard.ResolveKey("current", "", "", "")
+	tests := []struct {
+		id     uuid.UUID
+		window int
+		first  int
+		count  int
+	}{
+		{seqID(5), 2, 3, 5},
+		{seqID(1), 2, 1, 3},
+		{seqID(9), 3, 6, 4},
+		{seqID(42), 2, 0, 0},
+	}
+	for _, tt := range tests {
+		around := leaderboard.GetAround(key, tt.id, tt.window)
+		if len(around) != tt.count {
+			t.Errorf("GetAround(%s, %d) returned %d rows, want %d", tt.id, tt.window, len(around), tt.count)
+			continue
+		}
+		for i, row := range around {
+			if row.Rank != tt.first+i {
+				t.Errorf("GetAround(%s, %d)[%d].Rank = %d, want %d", tt.id, tt.window, i, row.Rank, tt.first+i)
+			}
+		}
+	}
+}
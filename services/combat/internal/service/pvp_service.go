@@ -1,9 +1,11 @@
 package service
 
 import (
+	"combat/internal/clients"
 	"combat/internal/config"
 	"combat/internal/models"
 	"combat/internal/repository"
+	"context"
 	"fmt"
 	"time"
 
@@ -22,6 +24,7 @@ type PvPServiceInterface interface {
 
 	// Classements et statistiques
 	GetRankings(req *models.GetRankingsRequest) (*models.RankingsResponse, error)
+	GetRankingSnapshots(req *models.GetRankingsRequest) (*models.LeaderboardSnapshotsResponse, error)
 	GetPlayerStatistics(playerID uuid.UUID, season string) (*models.PvPStatistics, error)
 	UpdatePlayerStatistics(playerID uuid.UUID, result *models.MatchResult) error
 	GetCurrentSeasonInfo() (*models.SeasonInfo, error)
@@ -40,26 +43,38 @@ type PvPServiceInterface interface {
 	CleanupExpiredChallenges() error
 	CleanupOldQueue() error
 	StartCleanupRoutine()
+	StartDuelBoundaryRoutine()
 }
 
 // PvPService implémente l'interface PvPServiceInterface
 type PvPService struct {
-	pvpRepo     repository.PvPRepositoryInterface
-	combatRepo  repository.CombatRepositoryInterface
-	config      *config.Config
-	queueTicker *time.Ticker
+	pvpRepo       repository.PvPRepositoryInterface
+	combatRepo    repository.CombatRepositoryInterface
+	combatService CombatServiceInterface
+	worldClient   clients.WorldClientInterface
+	leaderboard   LeaderboardInterface
+	duels         *DuelMonitor
+	config        *config.Config
+	queueTicker   *time.Ticker
 }
 
 // NewPvPService crée un nouveau service PvP
 func NewPvPService(
 	pvpRepo repository.PvPRepositoryInterface,
 	combatRepo repository.CombatRepositoryInterface,
+	combatService CombatServiceInterface,
+	worldClient clients.WorldClientInterface,
+	leaderboard LeaderboardInterface,
 	config *config.Config,
 ) PvPServiceInterface {
 	service := &PvPService{
-		pvpRepo:    pvpRepo,
-		combatRepo: combatRepo,
-		config:     config,
+		pvpRepo:       pvpRepo,
+		combatRepo:    combatRepo,
+		combatService: combatService,
+		worldClient:   worldClient,
+		leaderboard:   leaderboard,
+		duels:         NewDuelMonitor(),
+		config:        config,
 	}
 
 	// Démarrer le matchmaking automatique
@@ -75,6 +90,11 @@ func (s *PvPService) CreateChallenge(challengerID uuid.UUID, req *models.CreateC
 		return nil, fmt.Errorf("cannot challenge yourself")
 	}
 
+	// Les deux joueurs doivent être en ligne, hors combat et proches dans une zone autorisant les duels
+	if _, _, err := s.validateDuelConditions(challengerID, req.ChallengedID); err != nil {
+		return nil, err
+	}
+
 	// Vérifier que le joueur n'a pas déjà un défi en attente avec cette personne
 	existingChallenges, err := s.pvpRepo.GetChallengesByPlayer(challengerID)
 	if err != nil {
@@ -176,19 +196,27 @@ func (s *PvPService) RespondToChallenge(challengeID uuid.UUID, req *models.Respo
 		Message:   req.Message,
 	}
 
+	var boundary *models.DuelBoundary
 	if req.Accept {
+		// Les conditions ont pu changer depuis la création du défi
+		challengerLocation, challengedLocation, err := s.validateDuelConditions(challenge.ChallengerID, challenge.ChallengedID)
+		if err != nil {
+			return nil, err
+		}
+
 		// Accepter le défi - créer un combat
 		challenge.Status = models.ChallengeStatusAccepted
 		now := time.Now()
 		challenge.RespondedAt = &now
 
 		// Créer un combat PvP
-		combat, err := s.createPvPCombat(challenge)
+		combat, err := s.createPvPCombat(challenge, challengerLocation, challengedLocation)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create PvP combat: %w", err)
 		}
 
 		challenge.CombatID = &combat.ID
+		boundary = models.NewDuelBoundary(challenge, combat.ID, challengerLocation, challengedLocation, s.config.Duel.BoundaryRadius)
 		response.Match = nil // ou response.Match = ... si tu veux retourner le match
 
 		logrus.WithFields(logrus.Fields{
@@ -214,6 +242,14 @@ func (s *PvPService) RespondToChallenge(challengeID uuid.UUID, req *models.Respo
 		return nil, fmt.Errorf("failed to update challenge: %w", err)
 	}
 
+	// Surveiller que les joueurs restent dans la zone du duel, y compris après un redémarrage
+	if boundary != nil {
+		if err := s.pvpRepo.SaveDuelBoundary(boundary); err != nil {
+			logrus.WithError(err).WithField("challenge_id", challengeID).Error("Failed to save duel boundary")
+		}
+		s.duels.Track(boundary)
+	}
+
 	return response, nil
 }
 
@@ -247,27 +283,72 @@ func (s *PvPService) CancelChallenge(challengeID, playerID uuid.UUID) error {
 	return nil
 }
 
-// GetRankings récupère les classements PvP
+// GetRankings récupère les classements PvP depuis les classements en mémoire
+// (ou depuis un snapshot historique si SnapshotID est fourni)
 func (s *PvPService) GetRankings(req *models.GetRankingsRequest) (*models.RankingsResponse, error) {
-	rankings, err := s.pvpRepo.GetTopPlayers(req.Limit)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get rankings: %w", err)
+	key := s.leaderboard.ResolveKey(req.Season, req.Type, req.Class, req.Region)
+
+	response := &models.RankingsResponse{
+		Season:   key.Season,
+		Ladder:   key.String(),
+		PageSize: req.Limit,
+	}
+	if req.Limit > 0 {
+		response.Page = req.Offset/req.Limit + 1
 	}
 
-	// TODO: Enrichir avec les noms des joueurs depuis le service player
-	for _, ranking := range rankings {
-		ranking.PlayerName = fmt.Sprintf("Player-%s", ranking.PlayerID.String()[:8])
+	if req.SnapshotID != nil {
+		snapshot, rankings, err := s.leaderboard.GetSnapshotPage(*req.SnapshotID, req.Offset, req.Limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get snapshot rankings: %w", err)
+		}
+		response.Season = snapshot.Season
+		response.Ladder = models.LadderKey{
+			Season:    snapshot.Season,
+			QueueType: snapshot.QueueType,
+			Class:     snapshot.Class,
+			Region:    snapshot.Region,
+		}.String()
+		response.Rankings = rankings
+		response.Total = snapshot.TotalPlayers
+		response.SnapshotAt = &snapshot.TakenAt
+		s.setPlayerNames(response.Rankings)
+		return response, nil
+	}
+
+	response.Rankings, response.Total = s.leaderboard.GetPage(key, req.Offset, req.Limit)
+
+	if req.PlayerID != nil {
+		response.PlayerRank = s.leaderboard.GetPlayerRank(key, *req.PlayerID)
+		if req.Around > 0 {
+			response.AroundPlayer = s.leaderboard.GetAround(key, *req.PlayerID, req.Around)
+		}
 	}
 
-	response := &models.RankingsResponse{
-		Season:   req.Season,
-		Rankings: rankings,
-		Total:    len(rankings),
+	s.setPlayerNames(response.Rankings)
+	s.setPlayerNames(response.AroundPlayer)
+	if response.PlayerRank != nil {
+		s.setPlayerNames([]*models.PvPRanking{response.PlayerRank})
 	}
 
 	return response, nil
 }
 
+// GetRankingSnapshots liste les snapshots historiques d'un classement
+func (s *PvPService) GetRankingSnapshots(req *models.GetRankingsRequest) (*models.LeaderboardSnapshotsResponse, error) {
+	key := s.leaderboard.ResolveKey(req.Season, req.Type, req.Class, req.Region)
+
+	snapshots, err := s.leaderboard.GetSnapshots(key, req.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ranking snapshots: %w", err)
+	}
+
+	return &models.LeaderboardSnapshotsResponse{
+		Ladder:    key.String(),
+		Snapshots: snapshots,
+	}, nil
+}
+
 // GetPlayerStatistics récupère les statistiques PvP d'un joueur
 func (s *PvPService) GetPlayerStatistics(playerID uuid.UUID, season string) (*models.PvPStatistics, error) {
 	stats, err := s.pvpRepo.GetPvPStatistics(playerID)
@@ -329,6 +410,23 @@ func (s *PvPService) UpdatePlayerStatistics(playerID uuid.UUID, result *models.M
 		return fmt.Errorf("failed to update PvP statistics: %w", err)
 	}
 
+	// Mettre à jour les classements (saison, file, classe et région)
+	update := &LadderUpdate{
+		CharacterID: playerID,
+		Season:      result.Season,
+		QueueType:   result.QueueType,
+		Rating:      stats.CurrentRating,
+		Result:      result.ResultType,
+	}
+	if playerID == result.WinnerID {
+		update.Class, update.Region = result.WinnerClass, result.WinnerRegion
+	} else {
+		update.Class, update.Region = result.LoserClass, result.LoserRegion
+	}
+	if _, err := s.leaderboard.RecordMatch(update); err != nil {
+		return fmt.Errorf("failed to update leaderboard: %w", err)
+	}
+
 	return nil
 }
 
@@ -351,6 +449,15 @@ func (s *PvPService) JoinQueue(req *models.JoinQueueRequest) (*models.QueueRespo
 		return nil, fmt.Errorf("already in queue")
 	}
 
+	// Un joueur déjà en combat ne peut pas rejoindre la file
+	inCombat, _, err := s.combatService.IsParticipantInCombat(req.PlayerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check combat status: %w", err)
+	}
+	if inCombat {
+		return nil, fmt.Errorf("player is already in combat")
+	}
+
 	// Créer l'entrée de file d'attente
 	entry := &models.PvPQueueEntry{
 		PlayerID:    req.PlayerID,
@@ -499,16 +606,25 @@ func (s *PvPService) EndMatch(matchID uuid.UUID, result *models.MatchResult) err
 		LoserID:      result.LoserID,
 		WinnerRating: result.WinnerRating,
 		LoserRating:  result.LoserRating,
+		Season:       result.Season,
+		QueueType:    result.QueueType,
+		WinnerClass:  result.WinnerClass,
+		WinnerRegion: result.WinnerRegion,
 	}); err != nil {
 		logrus.WithError(err).Error("Failed to update player 1 statistics")
 	}
 
+	// Le second joueur reçoit le résultat inverse (une victoire devient une défaite)
 	if err := s.UpdatePlayerStatistics(result.LoserID, &models.MatchResult{
-		ResultType:   result.ResultType,
+		ResultType:   inverseResultType(result.ResultType),
 		WinnerID:     result.LoserID,
 		LoserID:      result.WinnerID,
 		WinnerRating: result.LoserRating,
 		LoserRating:  result.WinnerRating,
+		Season:       result.Season,
+		QueueType:    result.QueueType,
+		WinnerClass:  result.LoserClass,
+		WinnerRegion: result.LoserRegion,
 	}); err != nil {
 		logrus.WithError(err).Error("Failed to update player 2 statistics")
 	}
@@ -516,7 +632,7 @@ func (s *PvPService) EndMatch(matchID uuid.UUID, result *models.MatchResult) err
 	logrus.WithFields(logrus.Fields{
 		"match_id":       matchID,
 		"player1_result": result.ResultType,
-		"player2_result": result.ResultType,
+		"player2_result": inverseResultType(result.ResultType),
 		"duration":       result.Duration,
 	}).Info("PvP match ended")
 
@@ -564,26 +680,233 @@ func (s *PvPService) StartMatchmakingRoutine() {
 	}()
 }
 
+// StartDuelBoundaryRoutine démarre la surveillance des zones de duel
+// Les duels en cours avant un redémarrage sont relus depuis leurs défis.
+func (s *PvPService) StartDuelBoundaryRoutine() {
+	boundaries, err := s.pvpRepo.GetActiveDuelBoundaries()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to restore duel boundaries")
+	}
+	for _, boundary := range boundaries {
+		s.duels.Track(boundary)
+	}
+	if len(boundaries) > 0 {
+		logrus.WithField("duels", len(boundaries)).Info("Duel boundaries restored")
+	}
+
+	ticker := time.NewTicker(s.config.Duel.BoundaryInterval)
+	go func() {
+		defer ticker.Stop()
+		for range ticker.C {
+			s.checkDuelBoundaries()
+		}
+	}()
+}
+
 // Méthodes utilitaires privées
 
-func (s *PvPService) createPvPCombat(_ *models.PvPChallenge) (*models.CombatInstance, error) {
-	combat := &models.CombatInstance{
-		ID:              uuid.New(),
-		CombatType:      models.CombatTypePvP,
-		Status:          "pending",
-		MaxParticipants: config.DefaultMaxParticipantsPvP,
-		CurrentTurn:     0,
-		TurnTimeLimit:   config.DefaultTurnTimeLimitPvP,
-		MaxDuration:     config.DefaultMaxDurationPvP,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
+// validateDuelConditions vérifie que deux joueurs peuvent s'affronter et retourne leurs positions
+func (s *PvPService) validateDuelConditions(
+	challengerID, challengedID uuid.UUID,
+) (*models.CharacterLocation, *models.CharacterLocation, error) {
+	for _, characterID := range []uuid.UUID{challengerID, challengedID} {
+		inCombat, _, err := s.combatService.IsParticipantInCombat(characterID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check combat status: %w", err)
+		}
+		if inCombat {
+			if characterID == challengerID {
+				return nil, nil, fmt.Errorf("you are already in combat")
+			}
+			return nil, nil, fmt.Errorf("challenged player is already in combat")
+		}
 	}
 
-	if err := s.combatRepo.Create(combat); err != nil {
-		return nil, err
+	challenger, err := s.getCharacterLocation(challengerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	challenged, err := s.getCharacterLocation(challengedID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !challenger.IsOnline {
+		return nil, nil, fmt.Errorf("you must be online to duel")
+	}
+	if !challenged.IsOnline {
+		return nil, nil, fmt.Errorf("challenged player is offline")
 	}
 
-	return combat, nil
+	if challenger.IsSafeZone || challenged.IsSafeZone {
+		return nil, nil, fmt.Errorf("duels are not allowed in safe zones")
+	}
+
+	if challenger.ZoneID != challenged.ZoneID {
+		return nil, nil, fmt.Errorf("challenged player is not in the same zone")
+	}
+	if distance := challenger.DistanceTo(challenged.X, challenged.Y, challenged.Z); distance > s.config.Duel.MaxDistance {
+		return nil, nil, fmt.Errorf("challenged player is too far away (%.1f > %.1f)", distance, s.config.Duel.MaxDistance)
+	}
+
+	return challenger, challenged, nil
+}
+
+// getCharacterLocation récupère la position d'un personnage auprès du service world
+func (s *PvPService) getCharacterLocation(characterID uuid.UUID) (*models.CharacterLocation, error) {
+	timeout := s.config.Services.WorldService.Timeout
+	if timeout <= 0 {
+		timeout = time.Duration(config.DefaultServiceTimeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	location, err := s.worldClient.GetCharacterLocation(ctx, characterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get location of character %s: %w", characterID, err)
+	}
+
+	return location, nil
+}
+
+// checkDuelBoundaries annule les duels dont un participant a quitté la zone
+func (s *PvPService) checkDuelBoundaries() {
+	for _, boundary := range s.duels.Active() {
+		combat, err := s.combatRepo.GetByID(boundary.CombatID)
+		if err != nil {
+			logrus.WithError(err).WithField("combat_id", boundary.CombatID).Warn("Failed to load duel combat")
+			continue
+		}
+		if combat.IsFinished() {
+			s.completeDuel(boundary, combat)
+			continue
+		}
+
+		for _, characterID := range []uuid.UUID{boundary.ChallengerID, boundary.ChallengedID} {
+			location, err := s.getCharacterLocation(characterID)
+			if err != nil {
+				// Service world indisponible: on réessaiera au prochain passage
+				logrus.WithError(err).WithField("combat_id", boundary.CombatID).Warn("Failed to check duel boundary")
+				break
+			}
+
+			if !boundary.Contains(location) {
+				s.cancelDuel(boundary, characterID)
+				break
+			}
+		}
+	}
+}
+
+// cancelDuel termine le combat et annule le défi d'un duel dont un joueur est sorti
+// Si le combat n'a pas pu être terminé, le duel reste suivi et sera réessayé au prochain passage.
+func (s *PvPService) cancelDuel(boundary *models.DuelBoundary, leaverID uuid.UUID) {
+	if _, err := s.combatService.EndCombat(boundary.CombatID, &models.EndCombatRequest{
+		Reason:   "duel_boundary_exit",
+		ForceEnd: true,
+	}); err != nil {
+		logrus.WithError(err).WithField("combat_id", boundary.CombatID).Error("Failed to end duel combat")
+		return
+	}
+	s.duels.Untrack(boundary.CombatID)
+
+	challenge, err := s.pvpRepo.GetChallengeByID(boundary.ChallengeID)
+	if err != nil {
+		logrus.WithError(err).WithField("challenge_id", boundary.ChallengeID).Error("Failed to load duel challenge")
+		return
+	}
+
+	now := time.Now()
+	challenge.Status = models.ChallengeStatusCancelled
+	challenge.CompletedAt = &now
+	if err := s.pvpRepo.UpdateChallenge(challenge); err != nil {
+		logrus.WithError(err).WithField("challenge_id", boundary.ChallengeID).Error("Failed to cancel duel challenge")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"challenge_id": boundary.ChallengeID,
+		"combat_id":    boundary.CombatID,
+		"player_id":    leaverID,
+	}).Info("Duel canceled: player left the duel boundary")
+}
+
+// completeDuel clôt le défi d'un duel dont le combat s'est terminé normalement
+// Le défi sort ainsi des duels relus au démarrage ; en cas d'échec, le duel reste suivi.
+func (s *PvPService) completeDuel(boundary *models.DuelBoundary, combat *models.CombatInstance) {
+	challenge, err := s.pvpRepo.GetChallengeByID(boundary.ChallengeID)
+	if err != nil {
+		logrus.WithError(err).WithField("challenge_id", boundary.ChallengeID).Error("Failed to load duel challenge")
+		return
+	}
+
+	if challenge.Status == models.ChallengeStatusAccepted {
+		participants, err := s.combatRepo.GetParticipants(combat.ID)
+		if err != nil {
+			logrus.WithError(err).WithField("combat_id", combat.ID).Error("Failed to load duel participants")
+			return
+		}
+
+		completedAt := time.Now()
+		if combat.EndedAt != nil {
+			completedAt = *combat.EndedAt
+		}
+		challenge.Status = models.ChallengeStatusCompleted
+		challenge.CompletedAt = &completedAt
+		challenge.WinnerID, challenge.LoserID, challenge.ResultType = duelOutcome(boundary, participants)
+		if err := s.pvpRepo.UpdateChallenge(challenge); err != nil {
+			logrus.WithError(err).WithField("challenge_id", challenge.ID).Error("Failed to complete duel challenge")
+			return
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"challenge_id": challenge.ID,
+			"combat_id":    combat.ID,
+			"result":       *challenge.ResultType,
+		}).Info("Duel completed")
+	}
+
+	s.duels.Untrack(boundary.CombatID)
+}
+
+// duelOutcome vainqueur et perdant d'un duel terminé : seul survivant des deux joueurs,
+// sinon match nul
+func duelOutcome(boundary *models.DuelBoundary, participants []*models.CombatParticipant) (winnerID, loserID *uuid.UUID, result *models.ResultType) {
+	alive := make(map[uuid.UUID]bool, len(participants))
+	for _, participant := range participants {
+		alive[participant.CharacterID] = participant.IsAlive
+	}
+
+	challenger, challenged := boundary.ChallengerID, boundary.ChallengedID
+	resultType := models.ResultTypeVictory
+	switch {
+	case alive[challenger] && !alive[challenged]:
+		return &challenger, &challenged, &resultType
+	case alive[challenged] && !alive[challenger]:
+		return &challenged, &challenger, &resultType
+	default:
+		resultType = models.ResultTypeDraw
+		return nil, nil, &resultType
+	}
+}
+
+func (s *PvPService) createPvPCombat(
+	challenge *models.PvPChallenge,
+	challenger, challenged *models.CharacterLocation,
+) (*models.CombatInstance, error) {
+	settings := models.GetDefaultCombatSettings()
+
+	return s.combatService.CreateCombat(&models.CreateCombatRequest{
+		CombatType:      models.CombatTypePvP,
+		ZoneID:          challenger.ZoneID,
+		MaxParticipants: config.DefaultMaxParticipantsPvP,
+		TurnTimeLimit:   config.DefaultTurnTimeLimitPvP,
+		MaxDuration:     config.DefaultMaxDurationPvP,
+		Settings:        &settings,
+		Participants: []models.ParticipantRequest{
+			{CharacterID: challenge.ChallengerID, UserID: challenger.UserID, Team: 1},
+			{CharacterID: challenge.ChallengedID, UserID: challenged.UserID, Team: 2},
+		},
+	})
 }
 
 func (s *PvPService) deduplicateChallenges(challenges []*models.PvPChallenge) []*models.PvPChallenge {
@@ -662,6 +985,25 @@ func (s *PvPService) arePlayersCompatible(p1, p2 *models.PvPQueueEntry) bool {
 	return ratingDiff <= maxRatingDiff
 }
 
+// setPlayerNames renseigne le nom affiché des joueurs classés
+func (s *PvPService) setPlayerNames(rankings []*models.PvPRanking) {
+	// TODO: Enrichir avec les noms des joueurs depuis le service player
+	for _, ranking := range rankings {
+		ranking.PlayerName = fmt.Sprintf("Player-%s", ranking.PlayerID.String()[:8])
+	}
+}
+
+// inverseResultType retourne le résultat vu par l'adversaire
+func inverseResultType(resultType models.ResultType) models.ResultType {
+	switch resultType {
+	case models.ResultTypeVictory:
+		return models.ResultTypeDefeat
+	case models.ResultTypeDefeat:
+		return models.ResultTypeVictory
+	}
+	return resultType
+}
+
 func abs(x int) int {
 	if x < 0 {
 		return -x
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"combat/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDuelOutcome(t *testing.T) {
+	challenger, challenged := uuid.New(), uuid.New()
+	boundary := &models.DuelBoundary{ChallengerID: challenger, ChallengedID: challenged}
+
+	tests := []struct {
+		name                             string
+		challengerAlive, challengedAlive bool
+		winner, loser                    *uuid.UUID
+		result                           models.ResultType
+	}{
+		{"challenger wins", true, false, &challenger, &challenged, models.ResultTypeVictory},
+		{"challenged wins", false, true, &challenged, &challenger, models.ResultTypeVictory},
+		{"both standing", true, true, nil, nil, models.ResultTypeDraw},
+		{"both down", false, false, nil, nil, models.ResultTypeDraw},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			participants := []*models.CombatParticipant{
+				{CharacterID: challenger, IsAlive: tt.challengerAlive},
+				{CharacterID: challenged, IsAlive: tt.challengedAlive},
+			}
+
+			winner, loser, result := duelOutcome(boundary, participants)
+			if *result != tt.result {
+				t.Errorf("result = %s, want %s", *result, tt.result)
+			}
+			if !sameID(winner, tt.winner) || !sameID(loser, tt.loser) {
+				t.Errorf("winner/loser = %v/%v, want %v/%v", winner, loser, tt.winner, tt.loser)
+			}
+		})
+	}
+}
+
+// sameID compare deux identifiants optionnels
+func sameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"bytes"
+	"combat/internal/models"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Paramètres de la skip list (mêmes valeurs que les sorted sets Redis)
+	ladderMaxLevel    = 32
+	ladderLevelFactor = 0.25
+)
+
+// ladderLink pointeur vers le nœud suivant d'un niveau, avec le nombre de nœuds sautés
+type ladderLink struct {
+	node *ladderNode
+	span int
+}
+
+// ladderNode nœud de la skip list. La clé de tri est copiée à l'insertion,
+// l'entrée pouvant être modifiée ensuite
+type ladderNode struct {
+	rating      int
+	characterID uuid.UUID
+	entry       *models.LadderEntry
+	next        []ladderLink
+}
+
+// rankedLadder classement trié (rating décroissant) indexé par rang.
+// Skip list avec largeur des liens: insertion, suppression, rang et accès par rang en O(log n).
+// Non thread-safe: l'accès est protégé par le Leaderboard
+type rankedLadder struct {
+	head   *ladderNode
+	level  int
+	length int
+	nodes  map[uuid.UUID]*ladderNode
+	rand   *rand.Rand
+}
+
+func newRankedLadder() *rankedLadder {
+	return &rankedLadder{
+		head:  &ladderNode{next: make([]ladderLink, ladderMaxLevel)},
+		level: 1,
+		nodes: make(map[uuid.UUID]*ladderNode),
+		// Le niveau des nœuds n'a pas besoin d'un aléa cryptographique
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// ladderBefore indique si le nœud est classé avant la clé (rating, characterID)
+// Les égalités de rating sont départagées par l'ID pour un ordre stable
+func ladderBefore(node *ladderNode, rating int, characterID uuid.UUID) bool {
+	if node.rating != rating {
+		return node.rating > rating
+	}
+	return bytes.Compare(node.characterID[:], characterID[:]) < 0
+}
+
+func (l *rankedLadder) randomLevel() int {
+	level := 1
+	for level < ladderMaxLevel && l.rand.Float64() < ladderLevelFactor {
+		level++
+	}
+	return level
+}
+
+// Upsert insère l'entrée ou la repositionne si son rating a changé
+func (l *rankedLadder) Upsert(entry *models.LadderEntry) {
+	if node, exists := l.nodes[entry.CharacterID]; exists {
+		if node.rating == entry.Rating {
+			node.entry = entry
+			return
+		}
+		l.remove(node.rating, node.characterID)
+	}
+	l.insert(entry)
+}
+
+// Remove retire un joueur du classement
+func (l *rankedLadder) Remove(characterID uuid.UUID) {
+	if node, exists := l.nodes[characterID]; exists {
+		l.remove(node.rating, node.characterID)
+	}
+}
+
+// Len retourne le nombre de joueurs classés
+func (l *rankedLadder) Len() int {
+	return l.length
+}
+
+// Rank retourne le rang (1-indexé) d'un joueur, 0 s'il n'est pas classé
+func (l *rankedLadder) Rank(characterID uuid.UUID) (int, *models.LadderEntry) {
+	target, exists := l.nodes[characterID]
+	if !exists {
+		return 0, nil
+	}
+
+	rank := 0
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil &&
+			(ladderBefore(x.next[i].node, target.rating, target.characterID) || x.next[i].node == target) {
+			rank += x.next[i].span
+			x = x.next[i].node
+		}
+		if x == target {
+			return rank, target.entry
+		}
+	}
+
+	return 0, nil
+}
+
+// Range retourne les entrées à partir du rang start (1-indexé), au plus limit entrées
+func (l *rankedLadder) Range(start, limit int) []*models.LadderEntry {
+	if start < 1 || start > l.length || limit <= 0 {
+		return []*models.LadderEntry{}
+	}
+
+	entries := make([]*models.LadderEntry, 0, minInt(limit, l.length-start+1))
+	for x := l.byRank(start); x != nil && len(entries) < limit; x = x.next[0].node {
+		entries = append(entries, x.entry)
+	}
+
+	return entries
+}
+
+// byRank retourne le nœud au rang donné (1-indexé)
+func (l *rankedLadder) byRank(rank int) *ladderNode {
+	traversed := 0
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && traversed+x.next[i].span <= rank {
+			traversed += x.next[i].span
+			x = x.next[i].node
+		}
+		if traversed == rank {
+			return x
+		}
+	}
+	return nil
+}
+
+func (l *rankedLadder) insert(entry *models.LadderEntry) {
+	var update [ladderMaxLevel]*ladderNode
+	var rank [ladderMaxLevel]int
+
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		if i < l.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.next[i].node != nil && ladderBefore(x.next[i].node, entry.Rating, entry.CharacterID) {
+			rank[i] += x.next[i].span
+			x = x.next[i].node
+		}
+		update[i] = x
+	}
+
+	level := l.randomLevel()
+	if level > l.level {
+		for i := l.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = l.head
+			update[i].next[i].span = l.length
+		}
+		l.level = level
+	}
+
+	node := &ladderNode{
+		rating:      entry.Rating,
+		characterID: entry.CharacterID,
+		entry:       entry,
+		next:        make([]ladderLink, level),
+	}
+	for i := 0; i < level; i++ {
+		node.next[i].node = update[i].next[i].node
+		update[i].next[i].node = node
+
+		node.next[i].span = update[i].next[i].span - (rank[0] - rank[i])
+		update[i].next[i].span = (rank[0] - rank[i]) + 1
+	}
+
+	// Les niveaux supérieurs sautent désormais un nœud de plus
+	for i := level; i < l.level; i++ {
+		update[i].next[i].span++
+	}
+
+	l.nodes[entry.CharacterID] = node
+	l.length++
+}
+
+func (l *rankedLadder) remove(rating int, characterID uuid.UUID) {
+	var update [ladderMaxLevel]*ladderNode
+
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && ladderBefore(x.next[i].node, rating, characterID) {
+			x = x.next[i].node
+		}
+		update[i] = x
+	}
+
+	x = x.next[0].node
+	if x == nil || x.characterID != characterID {
+		return
+	}
+
+	for i := 0; i < l.level; i++ {
+		if update[i].next[i].node == x {
+			update[i].next[i].span += x.next[i].span - 1
+			update[i].next[i].node = x.next[i].node
+		} else {
+			update[i].next[i].span--
+		}
+	}
+
+	for l.level > 1 && l.head.next[l.level-1].node == nil {
+		l.level--
+	}
+
+	delete(l.nodes, characterID)
+	l.length--
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"bytes"
+	"combat/internal/models"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// referenceLadder classement de référence : tranche triée par rating décroissant puis par ID
+type referenceLadder struct {
+	entries []*models.LadderEntry
+}
+
+func (r *referenceLadder) upsert(entry *models.LadderEntry) {
+	r.remove(entry.CharacterID)
+	r.entries = append(r.entries, entry)
+	sort.Slice(r.entries, func(i, j int) bool {
+		a, b := r.entries[i], r.entries[j]
+		if a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+		return bytes.Compare(a.CharacterID[:], b.CharacterID[:]) < 0
+	})
+}
+
+func (r *referenceLadder) remove(characterID uuid.UUID) {
+	for i, entry := range r.entries {
+		if entry.CharacterID == characterID {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+func (r *referenceLadder) rank(characterID uuid.UUID) int {
+	for i, entry := range r.entries {
+		if entry.CharacterID == characterID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (r *referenceLadder) rangeFrom(start, limit int) []*models.LadderEntry {
+	if start < 1 || start > len(r.entries) || limit <= 0 {
+		return []*models.LadderEntry{}
+	}
+	end := minInt(start-1+limit, len(r.entries))
+	return r.entries[start-1 : end]
+}
+
+// seqID identifiant déterministe, pour des égalités de rating départagées de façon prévisible
+func seqID(n byte) uuid.UUID {
+	var id uuid.UUID
+	id[15] = n
+	return id
+}
+
+func ladderEntry(id uuid.UUID, rating int) *models.LadderEntry {
+	return &models.LadderEntry{CharacterID: id, Rating: rating}
+}
+
+// ladderIDs identifiants des entrées, dans l'ordre
+func ladderIDs(entries []*models.LadderEntry) []uuid.UUID {
+	ids := make([]uuid.UUID, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.CharacterID
+	}
+	return ids
+}
+
+// checkLadder compare le classement à la référence : taille, ordre, rangs et largeur des liens
+func checkLadder(t *testing.T, ladder *rankedLadder, ref *referenceLadder) {
+	t.Helper()
+
+	if ladder.Len() != len(ref.entries) {
+		t.Fatalf("Len = %d, want %d", ladder.Len(), len(ref.entries))
+	}
+
+	got := ladderIDs(ladder.Range(1, ladder.Len()+1))
+	want := ladderIDs(ref.entries)
+	if len(got) != len(want) {
+		t.Fatalf("Range(1, all) returned %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("rank %d: got %s, want %s", i+1, got[i], want[i])
+		}
+	}
+
+	// Rang de chaque nœud d'après le niveau 0, puis largeur de chaque lien des niveaux supérieurs
+	positions := map[*ladderNode]int{ladder.head: 0}
+	position := 0
+	for x := ladder.head.next[0].node; x != nil; x = x.next[0].node {
+		position++
+		positions[x] = position
+	}
+	for level := 0; level < ladder.level; level++ {
+		for x := ladder.head; x.next[level].node != nil; x = x.next[level].node {
+			next := x.next[level].node
+			if span := positions[next] - positions[x]; x.next[level].span != span {
+				t.Fatalf("level %d: span after rank %d = %d, want %d", level, positions[x], x.next[level].span, span)
+			}
+		}
+	}
+}
+
+func TestRankedLadderOperations(t *testing.T) {
+	a, b, c, d := seqID(1), seqID(2), seqID(3), seqID(4)
+
+	tests := []struct {
+		name   string
+		ops    func(l *rankedLadder)
+		order  []uuid.UUID
+		ranks  map[uuid.UUID]int
+		ranges [][3]int // start, limit, nombre d'entrées attendu
+	}{
+		{
+			name:  "empty",
+			ops:   func(*rankedLadder) {},
+			order: []uuid.UUID{},
+			ranks: map[uuid.UUID]int{a: 0},
+			ranges: [][3]int{
+				{1, 10, 0},
+				{0, 10, 0},
+			},
+		},
+		{
+			name: "sorted by rating",
+			ops: func(l *rankedLadder) {
+				l.Upsert(ladderEntry(a, 1500))
+				l.Upsert(ladderEntry(b, 1800))
+				l.Upsert(ladderEntry(c, 1200))
+			},
+			order: []uuid.UUID{b, a, c},
+			ranks: map[uuid.UUID]int{b: 1, a: 2, c: 3, d: 0},
+			ranges: [][3]int{
+				{1, 2, 2},
+				{2, 10, 2},
+				{3, 1, 1},
+				{4, 1, 0},
+				{1, 0, 0},
+			},
+		},
+		{
+			name: "ties broken by id",
+			ops: func(l *rankedLadder) {
+				l.Upsert(ladderEntry(c, 1500))
+				l.Upsert(ladderEntry(a, 1500))
+				l.Upsert(ladderEntry(b, 1500))
+			},
+			order: []uuid.UUID{a, b, c},
+			ranks: map[uuid.UUID]int{a: 1, b: 2, c: 3},
+		},
+		{
+			name: "upsert moves an entry",
+			ops: func(l *rankedLadder) {
+				l.Upsert(ladderEntry(a, 1500))
+				l.Upsert(ladderEntry(b, 1400))
+				l.Upsert(ladderEntry(c, 1300))
+				l.Upsert(ladderEntry(c, 1600))
+				l.Upsert(ladderEntry(a, 1000))
+			},
+			order: []uuid.UUID{c, b, a},
+			ranks: map[uuid.UUID]int{c: 1, b: 2, a: 3},
+		},
+		{
+			name: "upsert with same rating keeps the rank",
+			ops: func(l *rankedLadder) {
+				l.Upsert(ladderEntry(a, 1500))
+				l.Upsert(ladderEntry(b, 1400))
+				l.Upsert(&models.LadderEntry{CharacterID: b, Rating: 1400, Wins: 3})
+			},
+			order: []uuid.UUID{a, b},
+			ranks: map[uuid.UUID]int{a: 1, b: 2},
+		},
+		{
+			name: "remove",
+			ops: func(l *rankedLadder) {
+				l.Upsert(ladderEntry(a, 1500))
+				l.Upsert(ladderEntry(b, 1400))
+				l.Upsert(ladderEntry(c, 1300))
+				l.Remove(b)
+				l.Remove(d) // absent : sans effet
+			},
+			order: []uuid.UUID{a, c},
+			ranks: map[uuid.UUID]int{a: 1, b: 0, c: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ladder := newRankedLadder()
+			tt.ops(ladder)
+
+			if got := ladderIDs(ladder.Range(1, len(tt.order)+1)); len(got) != len(tt.order) {
+				t.Fatalf("order = %v, want %v", got, tt.order)
+			} else {
+				for i := range got {
+					if got[i] != tt.order[i] {
+						t.Fatalf("order = %v, want %v", got, tt.order)
+					}
+				}
+			}
+
+			for id, want := range tt.ranks {
+				rank, entry := ladder.Rank(id)
+				if rank != want {
+					t.Errorf("Rank(%s) = %d, want %d", id, rank, want)
+				}
+				if (entry != nil) != (want != 0) {
+					t.Errorf("Rank(%s) entry = %v", id, entry)
+				}
+			}
+
+			for _, r := range tt.ranges {
+				if got := ladder.Range(r[0], r[1]); len(got) != r[2] {
+					t.Errorf("Range(%d, %d) returned %d entries, want %d", r[0], r[1], len(got), r[2])
+				}
+			}
+		})
+	}
+}
+
+// TestRankedLadderRandomized applique des opérations aléatoires au classement et à la
+// référence, avec peu de ratings distincts pour multiplier les égalités
+func TestRankedLadderRandomized(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3, 42} {
+		rng := rand.New(rand.NewSource(seed))
+		ladder := newRankedLadder()
+		ladder.rand = rand.New(rand.NewSource(seed))
+		ref := &referenceLadder{}
+
+		ids := make([]uuid.UUID, 300)
+		for i := range ids {
+			ids[i] = uuid.New()
+		}
+
+		for op := 0; op < 3000; op++ {
+			id := ids[rng.Intn(len(ids))]
+			if rng.Intn(4) == 0 {
+				ladder.Remove(id)
+				ref.remove(id)
+			} else {
+				entry := ladderEntry(id, 1000+rng.Intn(50)*10)
+				ladder.Upsert(entry)
+				ref.upsert(entry)
+			}
+
+			if op%100 == 0 {
+				checkLadder(t, ladder, ref)
+			}
+
+			probe := ids[rng.Intn(len(ids))]
+			if rank, _ := ladder.Rank(probe); rank != ref.rank(probe) {
+				t.Fatalf("seed %d op %d: Rank = %d, want %d", seed, op, rank, ref.rank(probe))
+			}
+
+			start, limit := rng.Intn(ladder.Len()+2), rng.Intn(20)
+			got, want := ladderIDs(ladder.Range(start, limit)), ladderIDs(ref.rangeFrom(start, limit))
+			if len(got) != len(want) {
+				t.Fatalf("seed %d op %d: Range(%d, %d) returned %d entries, want %d", seed, op, start, limit, len(got), len(want))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("seed %d op %d: Range(%d, %d)[%d] = %s, want %s", seed, op, start, limit, i, got[i], want[i])
+				}
+			}
+		}
+		checkLadder(t, ladder, ref)
+	}
+}
+
+func BenchmarkRankedLadderUpsert(b *testing.B) {
+	ladder := newRankedLadder()
+	ids := make([]uuid.UUID, 10000)
+	for i := range ids {
+		ids[i] = uuid.New()
+		ladder.Upsert(ladderEntry(ids[i], 1000+i%2000))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ladder.Upsert(ladderEntry(ids[i%len(ids)], 1000+(i*7)%2000))
+	}
+}
+
+func BenchmarkRankedLadderRank(b *testing.B) {
+	ladder := newRankedLadder()
+	ids := make([]uuid.UUID, 10000)
+	for i := range ids {
+		ids[i] = uuid.New()
+		ladder.Upsert(ladderEntry(ids[i], 1000+i%2000))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ladder.Rank(ids[i%len(ids)])
+	}
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"combat/internal/clients"
+	"combat/internal/config"
+	"combat/internal/models"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Correspondance entre les clés de stats de l'équipement (service inventory)
+// et les noms de stats du service player
+var equipmentStatNames = map[string]string{
+	"attack":        "physical_damage",
+	"magic_attack":  "magical_damage",
+	"defense":       "physical_defense",
+	"magic_defense": "magical_defense",
+	"health":        "max_health",
+	"mana":          "max_mana",
+	"speed":         "attack_speed",
+	"strength":      "strength",
+	"agility":       "agility",
+	"intelligence":  "intelligence",
+	"vitality":      "vitality",
+}
+
+// StatHydratorInterface définit le calcul serveur des stats des participants
+type StatHydratorInterface interface {
+	Hydrate(combatID uuid.UUID, participant *models.CombatParticipant) (*models.HydratedStats, error)
+	GetCached(combatID, characterID uuid.UUID) (*models.HydratedStats, bool)
+	Invalidate(combatID uuid.UUID)
+	InvalidateParticipant(combatID, characterID uuid.UUID)
+}
+
+// StatHydrator récupère les stats, l'équipement et les modificateurs d'un personnage
+// auprès des services player et inventory, et les met en cache pour la durée du combat
+type StatHydrator struct {
+	playerClient    clients.PlayerClientInterface
+	inventoryClient clients.InventoryClientInterface
+	config          *config.Config
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]map[uuid.UUID]*models.HydratedStats
+}
+
+// NewStatHydrator crée un nouveau service de calcul des stats
+func NewStatHydrator(
+	playerClient clients.PlayerClientInterface,
+	inventoryClient clients.InventoryClientInterface,
+	config *config.Config,
+) StatHydratorInterface {
+	return &StatHydrator{
+		playerClient:    playerClient,
+		inventoryClient: inventoryClient,
+		config:          config,
+		cache:           make(map[uuid.UUID]map[uuid.UUID]*models.HydratedStats),
+	}
+}
+
+// Hydrate calcule les stats finales d'un participant et les applique
+func (h *StatHydrator) Hydrate(combatID uuid.UUID, participant *models.CombatParticipant) (*models.HydratedStats, error) {
+	if stats, exists := h.GetCached(combatID, participant.CharacterID); exists {
+		stats.ApplyTo(participant)
+		return stats, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.fetchTimeout())
+	defer cancel()
+
+	profile, equipment, err := h.fetch(ctx, participant.CharacterID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := h.compute(profile, equipment, time.Now())
+	stats.ApplyTo(participant)
+
+	h.mu.Lock()
+	if h.cache[combatID] == nil {
+		h.cache[combatID] = make(map[uuid.UUID]*models.HydratedStats)
+	}
+	h.cache[combatID][participant.CharacterID] = stats
+	h.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"combat_id":       combatID,
+		"character_id":    participant.CharacterID,
+		"max_health":      stats.MaxHealth,
+		"physical_damage": stats.PhysicalDamage,
+		"magical_damage":  stats.MagicalDamage,
+	}).Debug("Participant stats hydrated")
+
+	return stats, nil
+}
+
+// GetCached retourne les stats calculées d'un personnage pour un combat
+func (h *StatHydrator) GetCached(combatID, characterID uuid.UUID) (*models.HydratedStats, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	stats, exists := h.cache[combatID][characterID]
+	return stats, exists
+}
+
+// Invalidate supprime les stats en cache d'un combat
+func (h *StatHydrator) Invalidate(combatID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.cache, combatID)
+}
+
+// InvalidateParticipant oublie les stats d'un participant qui quitte le combat
+func (h *StatHydrator) InvalidateParticipant(combatID, characterID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if participants, exists := h.cache[combatID]; exists {
+		delete(participants, characterID)
+		if len(participants) == 0 {
+			delete(h.cache, combatID)
+		}
+	}
+}
+
+// fetch interroge les services player et inventory en parallèle
+func (h *StatHydrator) fetch(
+	ctx context.Context,
+	characterID uuid.UUID,
+) (*models.CharacterCombatProfile, *models.EquipmentStats, error) {
+	var (
+		wg           sync.WaitGroup
+		profile      *models.CharacterCombatProfile
+		equipment    *models.EquipmentStats
+		profileErr   error
+		equipmentErr error
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		profile, profileErr = h.playerClient.GetCharacterCombatProfile(ctx, characterID)
+	}()
+	go func() {
+		defer wg.Done()
+		equipment, equipmentErr = h.inventoryClient.GetEquipmentStats(ctx, characterID)
+	}()
+	wg.Wait()
+
+	if profileErr != nil {
+		return nil, nil, fmt.Errorf("failed to fetch character stats: %w", profileErr)
+	}
+	if equipmentErr != nil {
+		return nil, nil, fmt.Errorf("failed to fetch character equipment: %w", equipmentErr)
+	}
+
+	return profile, equipment, nil
+}
+
+// compute combine stats de base, bonus d'équipement et modificateurs actifs
+func (h *StatHydrator) compute(
+	profile *models.CharacterCombatProfile,
+	equipment *models.EquipmentStats,
+	now time.Time,
+) *models.HydratedStats {
+	base := profile.BaseStats
+
+	equipmentBonuses := make(map[string]int)
+	for key, value := range equipment.TotalStats {
+		if statName, exists := equipmentStatNames[key]; exists {
+			equipmentBonuses[statName] += value
+		}
+	}
+
+	modifierBonuses := make(map[string]int)
+	for _, modifier := range profile.Modifiers {
+		if modifier == nil || modifier.IsExpired(now) {
+			continue
+		}
+		modifierBonuses[modifier.StatName] += modifier.Value
+	}
+
+	bonus := func(stat string) int {
+		return equipmentBonuses[stat] + modifierBonuses[stat]
+	}
+
+	// Les bonus d'attributs modifient les stats dérivées avec les formules du service player
+	derivedBase := deriveFromAttributes(base.Strength, base.Agility, base.Intelligence, base.Vitality)
+	derivedBonus := deriveFromAttributes(
+		base.Strength+bonus("strength"),
+		base.Agility+bonus("agility"),
+		base.Intelligence+bonus("intelligence"),
+		base.Vitality+bonus("vitality"),
+	)
+
+	maxHealth := base.MaxHealth + (derivedBonus.maxHealth - derivedBase.maxHealth) + bonus("max_health")
+	maxMana := base.MaxMana + (derivedBonus.maxMana - derivedBase.maxMana) + bonus("max_mana")
+
+	stats := &models.HydratedStats{
+		CharacterID:      profile.CharacterID,
+		PlayerID:         profile.PlayerID,
+		Level:            profile.Level,
+		Class:            profile.Class,
+		Race:             profile.Race,
+		MaxHealth:        atLeast(maxHealth, 1),
+		MaxMana:          atLeast(maxMana, 0),
+		PhysicalDamage:   atLeast(base.PhysicalDamage+(derivedBonus.physicalDamage-derivedBase.physicalDamage)+bonus("physical_damage"), 0),
+		MagicalDamage:    atLeast(base.MagicalDamage+(derivedBonus.magicalDamage-derivedBase.magicalDamage)+bonus("magical_damage"), 0),
+		PhysicalDefense:  atLeast(base.PhysicalDefense+(derivedBonus.physicalDefense-derivedBase.physicalDefense)+bonus("physical_defense"), 0),
+		MagicalDefense:   atLeast(base.MagicalDefense+(derivedBonus.magicalDefense-derivedBase.magicalDefense)+bonus("magical_defense"), 0),
+		EquipmentBonuses: equipmentBonuses,
+		ModifierBonuses:  modifierBonuses,
+		Equipment:        equipment.Items,
+		HydratedAt:       now,
+	}
+
+	// La vie et le mana actuels suivent les bonus de maximum, sans jamais les dépasser
+	stats.Health = clampInt(base.Health+(stats.MaxHealth-base.MaxHealth), 1, stats.MaxHealth)
+	stats.Mana = clampInt(base.Mana+(stats.MaxMana-base.MaxMana), 0, stats.MaxMana)
+
+	// Critique et vitesse d'attaque sont exprimés en pourcentage côté player
+	critPercent := float64(base.CriticalChance+(derivedBonus.criticalChance-derivedBase.criticalChance)+bonus("critical_chance")) +
+		equipment.CriticalChance
+	stats.CriticalChance = clampFloat(critPercent/config.DefaultPercentDivisor, 0, config.DefaultMaxCriticalChance)
+
+	stats.Elements = buildElementalProfile(profile, equipment, now)
+
+	attackSpeedPercent := base.AttackSpeed + (derivedBonus.attackSpeed - derivedBase.attackSpeed) + bonus("attack_speed")
+	stats.AttackSpeed = clampFloat(
+		float64(attackSpeedPercent)/config.DefaultPercentDivisor, config.MinAttackSpeed, config.DefaultMaxMultiplier,
+	)
+
+	return stats
+}
+
+// buildElementalProfile combine les affinités de race, l'équipement et les modificateurs élémentaires
+func buildElementalProfile(
+	profile *models.CharacterCombatProfile,
+	equipment *models.EquipmentStats,
+	now time.Time,
+) *models.ElementalProfile {
+	elements := models.NewElementalProfile()
+	elements.Merge(models.BuildElementalProfileFromEquipment(equipment.Items))
+	elements.Merge(models.GetRaceElementalProfiles()[profile.Race])
+
+	for _, modifier := range profile.Modifiers {
+		if modifier == nil || modifier.IsExpired(now) {
+			continue
+		}
+		elements.ApplyStat(modifier.StatName, float64(modifier.Value)/config.DefaultPercentDivisor)
+	}
+
+	return elements
+}
+
+func (h *StatHydrator) fetchTimeout() time.Duration {
+	timeout := h.config.Services.PlayerService.Timeout
+	if h.config.Services.InventoryService.Timeout > timeout {
+		timeout = h.config.Services.InventoryService.Timeout
+	}
+	if timeout <= 0 {
+		timeout = time.Duration(config.DefaultServiceTimeout) * time.Second
+	}
+
+	retries := h.config.Services.PlayerService.Retries
+	if h.config.Services.InventoryService.Retries > retries {
+		retries = h.config.Services.InventoryService.Retries
+	}
+
+	return timeout * time.Duration(retries+1)
+}
+
+// derivedStats stats dérivées des attributs principaux
+type derivedStats struct {
+	maxHealth       int
+	maxMana         int
+	physicalDamage  int
+	magicalDamage   int
+	physicalDefense int
+	magicalDefense  int
+	criticalChance  int
+	attackSpeed     int
+}
+
+// deriveFromAttributes reproduit les formules de CharacterStats du service player
+func deriveFromAttributes(strength, agility, intelligence, vitality int) derivedStats {
+	return derivedStats{
+		maxHealth:       100 + vitality*10,
+		maxMana:         50 + intelligence*5,
+		physicalDamage:  strength*2 + agility/2,
+		magicalDamage:   int(float64(intelligence) * 2.5),
+		physicalDefense: (strength + vitality) / 3,
+		magicalDefense:  (intelligence + vitality) / 3,
+		criticalChance:  minInt(agility/10+5, 50),
+		attackSpeed:     minInt(100+agility/5, 200),
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func atLeast(value, minimum int) int {
+	if value < minimum {
+		return minimum
+	}
+	return value
+}
+
+func clampInt(value, minimum, maximum int) int {
+	if value < minimum {
+		return minimum
+	}
+	if value > maximum {
+		return maximum
+	}
+	return value
+}
+
+func clampFloat(value, minimum, maximum float64) float64 {
+	if value < minimum {
+		return minimum
+	}
+	if value > maximum {
+		return maximum
+	}
+	return value
+}
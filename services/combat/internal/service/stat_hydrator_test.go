@@ -0,0 +1,194 @@
+package service
+
+import (
+	"combat/internal/clients"
+	"combat/internal/config"
+	"combat/internal/models"
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeStatsBackend profils et équipements en mémoire, avec le nombre d'appels
+type fakeStatsBackend struct {
+	clients.PlayerClientInterface
+	clients.InventoryClientInterface
+
+	profile        *models.CharacterCombatProfile
+	equipment      *models.EquipmentStats
+	err            error
+	profileCalls   int
+	equipmentCalls int
+}
+
+func (f *fakeStatsBackend) GetCharacterCombatProfile(context.Context, uuid.UUID) (*models.CharacterCombatProfile, error) {
+	f.profileCalls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.profile, nil
+}
+
+func (f *fakeStatsBackend) GetEquipmentStats(context.Context, uuid.UUID) (*models.EquipmentStats, error) {
+	f.equipmentCalls++
+	return f.equipment, nil
+}
+
+// newTestStatsBackend personnage aux attributs à 10, équipé d'une arme et d'une armure de vitalité,
+// avec un buff de force actif et un buff magique expiré
+func newTestStatsBackend(characterID uuid.UUID) *fakeStatsBackend {
+	expired := time.Now().Add(-time.Minute)
+	return &fakeStatsBackend{
+		profile: &models.CharacterCombatProfile{
+			CharacterID: characterID,
+			PlayerID:    uuid.New(),
+			Level:       10,
+			Class:       "warrior",
+			Race:        "human",
+			BaseStats: &models.CharacterBaseStats{
+				Health: 150, MaxHealth: 200, Mana: 100, MaxMana: 100,
+				Strength: 10, Agility: 10, Intelligence: 10, Vitality: 10,
+				PhysicalDamage: 25, MagicalDamage: 25, PhysicalDefense: 6, MagicalDefense: 6,
+				CriticalChance: 6, AttackSpeed: 102,
+			},
+			Modifiers: []*models.CharacterStatModifier{
+				{StatName: "strength", Value: 5},
+				{StatName: "magical_damage", Value: 100, ExpiresAt: &expired},
+				nil,
+			},
+		},
+		equipment: &models.EquipmentStats{
+			CharacterID:    characterID,
+			TotalStats:     map[string]int{"attack": 10, "vitality": 5, "luck": 3},
+			CriticalChance: 4,
+		},
+	}
+}
+
+func newTestHydrator(backend *fakeStatsBackend) *StatHydrator {
+	cfg := &config.Config{Services: config.ServicesConfig{
+		PlayerService:    config.ServiceEndpoint{Timeout: time.Second},
+		InventoryService: config.ServiceEndpoint{Timeout: time.Second},
+	}}
+	return NewStatHydrator(backend, backend, cfg).(*StatHydrator)
+}
+
+func TestStatHydratorCompute(t *testing.T) {
+	characterID := uuid.New()
+	hydrator := newTestHydrator(newTestStatsBackend(characterID))
+	participant := &models.CombatParticipant{CharacterID: characterID}
+
+	stats, err := hydrator.Hydrate(uuid.New(), participant)
+	if err != nil {
+		t.Fatalf("Hydrate: %v", err)
+	}
+
+	// Force +5 (buff) et vitalité +5 (armure) repassent par les formules du service player ;
+	// l'attaque de l'arme s'ajoute aux dégâts physiques, le buff expiré et "luck" sont ignorés
+	ints := []struct {
+		name      string
+		got, want int
+	}{
+		{"max health", stats.MaxHealth, 250},
+		{"health", stats.Health, 200},
+		{"max mana", stats.MaxMana, 100},
+		{"physical damage", stats.PhysicalDamage, 45},
+		{"magical damage", stats.MagicalDamage, 25},
+		{"physical defense", stats.PhysicalDefense, 10},
+		{"magical defense", stats.MagicalDefense, 8},
+	}
+	for _, tt := range ints {
+		if tt.got != tt.want {
+			t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+	if math.Abs(stats.CriticalChance-0.10) > 1e-9 {
+		t.Errorf("critical chance = %v, want 0.10", stats.CriticalChance)
+	}
+	if math.Abs(stats.AttackSpeed-1.02) > 1e-9 {
+		t.Errorf("attack speed = %v, want 1.02", stats.AttackSpeed)
+	}
+	if _, found := stats.EquipmentBonuses["luck"]; found {
+		t.Error("unknown equipment stat kept")
+	}
+
+	if participant.MaxHealth != stats.MaxHealth || participant.PhysicalDamage != stats.PhysicalDamage {
+		t.Errorf("stats not applied to participant: %+v", participant)
+	}
+}
+
+func TestStatHydratorClamps(t *testing.T) {
+	characterID := uuid.New()
+	backend := newTestStatsBackend(characterID)
+	backend.profile.Modifiers = []*models.CharacterStatModifier{
+		{StatName: "max_health", Value: -1000},
+		{StatName: "critical_chance", Value: 500},
+	}
+	hydrator := newTestHydrator(backend)
+
+	stats, err := hydrator.Hydrate(uuid.New(), &models.CombatParticipant{CharacterID: characterID})
+	if err != nil {
+		t.Fatalf("Hydrate: %v", err)
+	}
+	if stats.MaxHealth != 1 || stats.Health != 1 {
+		t.Errorf("health = %d/%d, want 1/1", stats.Health, stats.MaxHealth)
+	}
+	if stats.CriticalChance != config.DefaultMaxCriticalChance {
+		t.Errorf("critical chance = %v, want %v", stats.CriticalChance, config.DefaultMaxCriticalChance)
+	}
+}
+
+func TestStatHydratorCache(t *testing.T) {
+	characterID := uuid.New()
+	backend := newTestStatsBackend(characterID)
+	hydrator := newTestHydrator(backend)
+	combatID := uuid.New()
+
+	for range 3 {
+		if _, err := hydrator.Hydrate(combatID, &models.CombatParticipant{CharacterID: characterID}); err != nil {
+			t.Fatalf("Hydrate: %v", err)
+		}
+	}
+	if backend.profileCalls != 1 || backend.equipmentCalls != 1 {
+		t.Fatalf("services called %d/%d times, want 1/1", backend.profileCalls, backend.equipmentCalls)
+	}
+
+	// Un autre combat recalcule les stats
+	if _, err := hydrator.Hydrate(uuid.New(), &models.CombatParticipant{CharacterID: characterID}); err != nil {
+		t.Fatalf("Hydrate: %v", err)
+	}
+	if backend.profileCalls != 2 {
+		t.Errorf("profile calls = %d, want 2", backend.profileCalls)
+	}
+
+	hydrator.InvalidateParticipant(combatID, characterID)
+	if _, found := hydrator.GetCached(combatID, characterID); found {
+		t.Error("participant stats still cached")
+	}
+	if _, found := hydrator.cache[combatID]; found {
+		t.Error("empty combat entry kept")
+	}
+}
+
+func TestStatHydratorError(t *testing.T) {
+	characterID := uuid.New()
+	backend := newTestStatsBackend(characterID)
+	backend.err = clients.ErrNotFound
+	hydrator := newTestHydrator(backend)
+	combatID := uuid.New()
+
+	participant := &models.CombatParticipant{CharacterID: characterID, MaxHealth: 42}
+	if _, err := hydrator.Hydrate(combatID, participant); !errors.Is(err, clients.ErrNotFound) {
+		t.Fatalf("error = %v, want ErrNotFound", err)
+	}
+	if participant.MaxHealth != 42 {
+		t.Error("participant modified after failed hydration")
+	}
+	if _, found := hydrator.GetCached(combatID, characterID); found {
+		t.Error("failed hydration cached")
+	}
+}
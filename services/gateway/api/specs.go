@@ -0,0 +1,9 @@
+// Package api contient les contrats OpenAPI des services, embarqués dans le gateway
+package api
+
+import "embed"
+
+// Specs documents OpenAPI 3 des services (openapi/<service>.yaml)
+//
+//go:embed openapi/*.yaml
+var Specs embed.FS
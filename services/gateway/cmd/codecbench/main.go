@@ -0,0 +1,123 @@
+// Commande de benchmark des encodages du hub WebSocket : taille des frames et coût CPU d'un
+// événement en JSON, MessagePack et Protobuf
+// Un événement est préparé une fois puis numéroté pour chaque abonné : la préparation est
+// mesurée avec un abonné, le coût par abonné supplémentaire avec fanOut abonnés.
+//
+// Usage (depuis services/gateway) :
+//
+//	go run ./cmd/codecbench
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"gateway/internal/encoding"
+	"gateway/internal/realtime"
+	"os"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Valeurs d'exemple
+const (
+	sampleSeq   = 4217
+	fanOut      = 100
+	sampleX     = 1523.75
+	sampleY     = -842.5
+	sampleZ     = 64.125
+	sampleSpeed = 3.2
+	percent     = 100
+)
+
+func main() {
+	channelID := uuid.New()
+	chatEvent, err := json.Marshal(map[string]interface{}{
+		"type":       "message",
+		"channel_id": channelID,
+		"user_id":    uuid.New(),
+		"data": map[string]interface{}{
+			"message_id": uuid.New(),
+			"username":   "Aldric",
+			"content":    "Rendez-vous devant la forge dans 5 minutes",
+		},
+		"timestamp": time.Now().UTC(),
+	})
+	if err != nil {
+		fail(err)
+	}
+	zoneEvent, err := json.Marshal(map[string]interface{}{
+		"type":         "position",
+		"character_id": uuid.New(),
+		"x":            sampleX,
+		"y":            sampleY,
+		"z":            sampleZ,
+		"velocity_x":   sampleSpeed,
+		"is_moving":    true,
+	})
+	if err != nil {
+		fail(err)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "event\tformat\tbytes\tvs json\tprepare ns/op\tcpu vs json\tper subscriber ns\tvs json\tallocs/op\t")
+	report(writer, "ChatEvent", "chat:"+channelID.String(), chatEvent)
+	report(writer, "zone (payload JSON)", "zone:starter_zone", zoneEvent)
+	if err := writer.Flush(); err != nil {
+		fail(err)
+	}
+}
+
+// report mesure un événement dans chaque encodage (JSON en premier)
+func report(writer *tabwriter.Writer, name, topic string, data []byte) {
+	seqs := make([]uint64, fanOut)
+	for i := range seqs {
+		seqs[i] = sampleSeq + uint64(i)
+	}
+
+	var baselineSize int
+	var baselineNs, baselineFanOutNs int64
+	for i, format := range encoding.Formats {
+		frames, err := realtime.EncodeEvent(format, topic, data, sampleSeq)
+		if err != nil {
+			fail(err)
+		}
+		single := benchmark(format, topic, data, seqs[:1])
+		perSubscriber := (benchmark(format, topic, data, seqs).NsPerOp() - single.NsPerOp()) / (fanOut - 1)
+		if i == 0 {
+			baselineSize, baselineNs, baselineFanOutNs = len(frames[0]), single.NsPerOp(), perSubscriber
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%s\t%d\t%s\t%d\t%s\t%d\t\n",
+			name, format, len(frames[0]), saving(int64(len(frames[0])), int64(baselineSize)),
+			single.NsPerOp(), saving(single.NsPerOp(), baselineNs),
+			perSubscriber, saving(perSubscriber, baselineFanOutNs), single.AllocsPerOp())
+	}
+}
+
+// benchmark mesure l'encodage d'un événement pour des abonnés
+func benchmark(format encoding.Format, topic string, data []byte, seqs []uint64) testing.BenchmarkResult {
+	return testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := realtime.EncodeEvent(format, topic, data, seqs...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// saving écart relatif à JSON
+func saving(value, baseline int64) string {
+	if baseline == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.0f%%", float64(value-baseline)*percent/float64(baseline))
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
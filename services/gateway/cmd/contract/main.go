@@ -0,0 +1,291 @@
+// Commande de contract test : compare les routes Gin de chaque service à son document OpenAPI
+//
+// Usage (depuis services/gateway) :
+//
+//	go run ./cmd/contract                 # échoue si une route diverge de la spécification
+//	go run ./cmd/contract -list player    # affiche les routes déclarées par un service
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gateway/api"
+	"gateway/internal/openapi"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Méthodes d'enregistrement de routes Gin
+var routeMethods = map[string]bool{
+	"GET":    true,
+	"POST":   true,
+	"PUT":    true,
+	"DELETE": true,
+	"PATCH":  true,
+}
+
+// routeSet routes d'un service ("METHOD /chemin/{param}")
+type routeSet map[string]bool
+
+func main() {
+	root := flag.String("root", "..", "dossier contenant les services")
+	list := flag.String("list", "", "service dont les routes sont affichées (sans comparaison)")
+	flag.Parse()
+
+	docs, err := loadSpecs()
+	if err != nil {
+		fail("failed to load specs: %v", err)
+	}
+
+	if *list != "" {
+		doc, exists := docs[*list]
+		if !exists {
+			fail("no spec for service %s", *list)
+		}
+		routes, skipped, err := routerRoutes(filepath.Join(*root, doc.Router))
+		if err != nil {
+			fail("%v", err)
+		}
+		for _, route := range sorted(routes) {
+			fmt.Println(route)
+		}
+		printSkipped(*list, skipped)
+		return
+	}
+
+	drift := 0
+	for _, service := range sortedServices(docs) {
+		doc := docs[service]
+		if doc.Router == "" {
+			fmt.Printf("%s: x-router is missing\n", service)
+			drift++
+			continue
+		}
+
+		routes, skipped, err := routerRoutes(filepath.Join(*root, doc.Router))
+		if err != nil {
+			fail("%s: %v", service, err)
+		}
+		documented := specRoutes(doc)
+
+		var undocumented, removed []string
+		for route := range routes {
+			if !documented[route] {
+				undocumented = append(undocumented, route)
+			}
+		}
+		for route := range documented {
+			if !routes[route] {
+				removed = append(removed, route)
+			}
+		}
+		sort.Strings(undocumented)
+		sort.Strings(removed)
+
+		for _, route := range undocumented {
+			fmt.Printf("%s: route not in spec: %s\n", service, route)
+		}
+		for _, route := range removed {
+			fmt.Printf("%s: spec operation not in router: %s\n", service, route)
+		}
+		printSkipped(service, skipped)
+
+		drift += len(undocumented) + len(removed)
+		if len(undocumented) == 0 && len(removed) == 0 {
+			fmt.Printf("%s: ok (%d routes)\n", service, len(routes))
+		}
+	}
+
+	if drift > 0 {
+		fail("contract drift: %d difference(s)", drift)
+	}
+}
+
+// loadSpecs charge les documents embarqués dans le gateway
+func loadSpecs() (map[string]*openapi.Document, error) {
+	specs, err := fs.Sub(api.Specs, "openapi")
+	if err != nil {
+		return nil, err
+	}
+	return openapi.LoadAll(specs)
+}
+
+// specRoutes opérations décrites par un document
+func specRoutes(doc *openapi.Document) routeSet {
+	routes := make(routeSet)
+	for route, item := range doc.Paths {
+		for method := range item.Operations() {
+			routes[method+" "+route] = true
+		}
+	}
+	return routes
+}
+
+// routerRoutes lit les routes enregistrées dans un fichier Go
+// Les groupes (x := y.Group("/prefix")) sont suivis par fonction ; les routes dont le
+// chemin n'est pas une chaîne littérale sont ignorées et retournées à part.
+func routerRoutes(file string) (routeSet, []string, error) {
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, file, nil, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse router: %w", err)
+	}
+
+	routes := make(routeSet)
+	var skipped []string
+
+	for _, decl := range parsed.Decls {
+		function, ok := decl.(*ast.FuncDecl)
+		if !ok || function.Body == nil {
+			continue
+		}
+
+		prefixes := make(map[string]string)
+		ast.Inspect(function.Body, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.AssignStmt:
+				for i, rhs := range n.Rhs {
+					if i >= len(n.Lhs) {
+						break
+					}
+					ident, isIdent := n.Lhs[i].(*ast.Ident)
+					prefix, isGroup := groupPrefix(rhs, prefixes)
+					if isIdent && isGroup {
+						prefixes[ident.Name] = prefix
+					}
+				}
+			case *ast.CallExpr:
+				selector, isSelector := n.Fun.(*ast.SelectorExpr)
+				if !isSelector || !routeMethods[selector.Sel.Name] || len(n.Args) == 0 {
+					return true
+				}
+				base, known := receiverPrefix(selector.X, prefixes)
+				relative, literal := stringLiteral(n.Args[0])
+				if !known || !literal {
+					skipped = append(skipped, fmt.Sprintf("%s (%s)", selector.Sel.Name, fset.Position(n.Pos())))
+					return true
+				}
+				routes[selector.Sel.Name+" "+templatePath(joinPaths(base, relative))] = true
+			}
+			return true
+		})
+	}
+
+	return routes, skipped, nil
+}
+
+// groupPrefix préfixe d'un appel x.Group("/lit")
+func groupPrefix(expr ast.Expr, prefixes map[string]string) (string, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return "", false
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "Group" || len(call.Args) == 0 {
+		return "", false
+	}
+	base, known := receiverPrefix(selector.X, prefixes)
+	relative, literal := stringLiteral(call.Args[0])
+	if !known || !literal {
+		return "", false
+	}
+	return joinPaths(base, relative), true
+}
+
+// receiverPrefix préfixe du receveur d'un appel : un groupe connu, un groupe créé
+// dans l'expression, ou le routeur (préfixe vide)
+func receiverPrefix(expr ast.Expr, prefixes map[string]string) (string, bool) {
+	switch receiver := expr.(type) {
+	case *ast.Ident:
+		if prefix, exists := prefixes[receiver.Name]; exists {
+			return prefix, true
+		}
+		return "", true
+	case *ast.CallExpr:
+		return groupPrefix(receiver, prefixes)
+	default:
+		return "", false
+	}
+}
+
+// stringLiteral valeur d'une chaîne littérale
+func stringLiteral(expr ast.Expr) (string, bool) {
+	literal, ok := expr.(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(literal.Value)
+	return value, err == nil
+}
+
+// joinPaths concatène un préfixe de groupe et un chemin comme Gin
+// (le slash final du chemin relatif est conservé)
+func joinPaths(absolute, relative string) string {
+	if relative == "" {
+		return absolute
+	}
+	joined := path.Join("/", absolute, relative)
+	if strings.HasSuffix(relative, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+	return joined
+}
+
+// templatePath convertit les paramètres Gin (:id, *path) en paramètres OpenAPI ({id})
+func templatePath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// printSkipped signale les routes non vérifiables
+func printSkipped(service string, skipped []string) {
+	for _, route := range skipped {
+		fmt.Printf("%s: skipped non-literal route %s\n", service, route)
+	}
+}
+
+// sorted routes triées par chemin puis méthode
+func sorted(routes routeSet) []string {
+	list := make([]string, 0, len(routes))
+	for route := range routes {
+		list = append(list, route)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		methodI, pathI, _ := strings.Cut(list[i], " ")
+		methodJ, pathJ, _ := strings.Cut(list[j], " ")
+		if pathI != pathJ {
+			return pathI < pathJ
+		}
+		return methodI < methodJ
+	})
+	return list
+}
+
+// sortedServices noms des services triés
+func sortedServices(docs map[string]*openapi.Document) []string {
+	services := make([]string, 0, len(docs))
+	for service := range docs {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services
+}
+
+// fail affiche une erreur et termine avec un code non nul
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -2,12 +2,31 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"gateway/api"
+	"gateway/internal/abuse"
+	"gateway/internal/aggregate"
+	"gateway/internal/audit"
+	"gateway/internal/balancer"
+	"gateway/internal/cache"
 	"gateway/internal/config"
 	"gateway/internal/gateway"
 	"gateway/internal/handlers"
+	"gateway/internal/idempotency"
+	"gateway/internal/maintenance"
 	"gateway/internal/middleware"
 	"gateway/internal/monitoring"
+	"gateway/internal/openapi"
+	"gateway/internal/proxy"
+	"gateway/internal/ratelimit"
+	"gateway/internal/realtime"
+	"gateway/internal/registry"
+	"gateway/internal/revocation"
+	"gateway/internal/tracing"
+	"gateway/internal/traffic"
+	"io/fs"
+	"mmorpg/pkg/jwks"
 	"net/http"
 	"os"
 	"os/signal"
@@ -39,37 +58,170 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	version := "1.0.0"
+	commit := "dev"
+	build := time.Now().Format(time.RFC3339)
+
+	// Tracing distribué (propagation vers les services, export OTLP ou local)
+	shutdownTracing, err := tracing.Init(&cfg.Tracing, "gateway", version, cfg.Server.Environment)
+	if err != nil {
+		logrus.Fatal("Failed to initialize tracing: ", err)
+	}
+
+	// Registre dynamique des services (config, fichier surveillé, API d'enregistrement)
+	serviceRegistry, err := registry.NewRegistry(cfg)
+	if err != nil {
+		logrus.Fatal("Failed to create service registry: ", err)
+	}
+	serviceRegistry.Start()
+
+	// Proxy vers les services (circuit breakers par instance)
+	serviceProxy, err := proxy.NewServiceProxy(cfg)
+	if err != nil {
+		logrus.Fatal("Failed to create service proxy: ", err)
+	}
+
+	// Répartition de charge et santé des instances
+	loadBalancer := balancer.NewBalancer(cfg, serviceRegistry, serviceProxy.Available)
+	loadBalancer.Start()
+
+	// Connexion NATS (hub temps réel, rate limiting partagé)
+	natsConn, err := gateway.ConnectNATS(cfg.NATS)
+	if err != nil {
+		logrus.Warn("Failed to connect to NATS, continuing without messaging: ", err)
+	}
+
+	// Rate limiting par utilisateur, rôle et route
+	rateLimiter := ratelimit.NewLimiter(&cfg.RateLimit, natsConn)
+	rateLimiter.Start()
+
+	// Cache des réponses GET, invalidé par les événements NATS
+	responseCache := cache.NewCache(&cfg.Cache, natsConn)
+	if err := responseCache.Start(); err != nil {
+		logrus.Warn("Failed to subscribe to cache invalidations: ", err)
+	}
+
+	// Clés d'idempotence des écritures retentées par les clients
+	idempotencyGuard := idempotency.NewGuard(&cfg.Idempotency, natsConn)
+	idempotencyGuard.Start()
+
+	// Répartition du trafic entre les versions des services (canary)
+	splitter := traffic.NewSplitter(&cfg.Traffic, natsConn)
+	if err := splitter.Start(); err != nil {
+		logrus.Warn("Failed to subscribe to traffic rule updates: ", err)
+	}
+
+	// Clés publiques de vérification des tokens, publiées par le service auth
+	keys := jwks.NewKeySet(cfg.JWT.JWKSURL, cfg.JWT.JWKSRefresh)
+	keys.Start()
+
+	// Refus des tokens révoqués (événements du service auth, introspection si cache froid)
+	var revocations *revocation.Checker
+	if cfg.Revocation.Enabled {
+		revocations = revocation.NewChecker(&cfg.Revocation, cfg.Services.Auth.URL, natsConn)
+		if err := revocations.Start(); err != nil {
+			logrus.Warn("Failed to subscribe to token revocations: ", err)
+		}
+	}
+
+	// Détection des abus : score des clients, blocages temporaires partagés entre réplicas
+	var abuseDetector *abuse.Detector
+	if cfg.Abuse.Enabled {
+		abuseDetector, err = abuse.NewDetector(&cfg.Abuse, natsConn)
+		if err != nil {
+			logrus.Fatal("Failed to create abuse detector: ", err)
+		}
+		if err := abuseDetector.Start(); err != nil {
+			logrus.Warn("Failed to subscribe to abuse decisions: ", err)
+		}
+	}
+
+	// Journal d'audit des écritures (fichier, NATS ou service analytics)
+	var auditRecorder *audit.Recorder
+	if cfg.Audit.Enabled {
+		auditSink, sinkErr := audit.NewSink(&cfg.Audit, natsConn, cfg.Services.Analytics.URL)
+		if errors.Is(sinkErr, audit.ErrNATSUnavailable) {
+			logrus.Warn("NATS not available, audit records are written to ", cfg.Audit.File)
+			auditSink, sinkErr = audit.NewFileSink(cfg.Audit.File, cfg.Audit.MaxFileSize, cfg.Audit.MaxBackups)
+		}
+		if sinkErr != nil {
+			logrus.Fatal("Failed to create audit sink: ", sinkErr)
+		}
+		auditRecorder = audit.NewRecorder(&cfg.Audit, auditSink)
+		auditRecorder.Start()
+	}
+
+	// Fenêtres de maintenance planifiées (démarré après la création du hub temps réel)
+	maintenanceScheduler := maintenance.NewScheduler(&cfg.Maintenance, natsConn)
+
+	// Contrats OpenAPI des services (api/openapi), embarqués dans le binaire
+	contracts, err := loadContracts()
+	if err != nil {
+		logrus.Fatal("Failed to load OpenAPI contracts: ", err)
+	}
+	var validator *openapi.Validator
+	if cfg.OpenAPI.Validate {
+		validator = openapi.NewValidator(contracts)
+	}
+
 	// Création du serveur gateway
-	gatewayServer, err := gateway.NewServer(cfg)
+	gatewayServer, err := gateway.NewServer(cfg, natsConn, serviceProxy, serviceRegistry, loadBalancer, validator, splitter, maintenanceScheduler, keys)
 	if err != nil {
 		logrus.Fatal("Failed to create gateway server: ", err)
 	}
+	// Drains décidés sur un autre réplica : l'instance quitte aussi la répartition de celui-ci
+	maintenanceScheduler.OnDrain(func(service, instance string, draining bool) error {
+		if draining {
+			_, drainErr := loadBalancer.Drain(service, instance)
+			return drainErr
+		}
+		return loadBalancer.Resume(service, instance)
+	})
+	if err := maintenanceScheduler.Start(); err != nil {
+		logrus.Warn("Failed to subscribe to maintenance window updates: ", err)
+	}
 
 	// Initialisation du monitoring
 	monitoring.Init(cfg.Monitoring.PrometheusPort)
 
 	// Initialisation des métriques middleware
 	middleware.InitMetrics()
+	proxy.InitMetrics()
+	realtime.InitMetrics()
+	ratelimit.InitMetrics()
+	cache.InitMetrics()
+	aggregate.InitMetrics()
+	traffic.InitMetrics()
+	idempotency.InitMetrics()
+	revocation.InitMetrics()
+	maintenance.InitMetrics()
+	abuse.InitMetrics()
+	audit.InitMetrics()
+
+	gatewayHandler := handlers.NewGatewayHandler(serviceRegistry, loadBalancer, serviceProxy, version, commit, build)
+
+	// Agrégation des données de connexion du client (appels parallèles aux services)
+	aggregator := aggregate.NewAggregator(&cfg.Bootstrap, loadBalancer, serviceProxy, splitter, maintenanceScheduler)
+	bootstrapHandler := handlers.NewBootstrapHandler(aggregator, splitter, maintenanceScheduler)
+
+	openAPIHandler := handlers.NewOpenAPIHandler(contracts, version)
+
+	trafficHandler := handlers.NewTrafficHandler(splitter, &cfg.Traffic)
+
+	maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceScheduler, serviceRegistry, loadBalancer, &cfg.Maintenance)
 
-	// Définition des services connus (nom -> URL)
-	services := map[string]string{
-		"auth":      "http://localhost:8081",
-		"player":    "http://localhost:8082",
-		"world":     "http://localhost:8083",
-		"inventory": "http://localhost:8084",
-		"guild":     "http://localhost:8086",
-		"chat":      "http://localhost:8087",
-		"analytics": "http://localhost:8088",
-		"combat":    "http://localhost:8085",
+	var abuseHandler *handlers.AbuseHandler
+	if abuseDetector != nil {
+		abuseHandler = handlers.NewAbuseHandler(abuseDetector)
 	}
-	version := "1.0.0"
-	commit := "dev"
-	build := time.Now().Format(time.RFC3339)
 
-	gatewayHandler := handlers.NewGatewayHandler(services, version, commit, build)
+	var auditHandler *handlers.AuditHandler
+	if auditRecorder != nil {
+		auditHandler = handlers.NewAuditHandler(auditRecorder, &cfg.Audit)
+	}
 
 	// Configuration des routes
-	router := setupRoutes(gatewayServer, cfg, gatewayHandler)
+	router := setupRoutes(gatewayServer, cfg, gatewayHandler, bootstrapHandler, openAPIHandler, trafficHandler, maintenanceHandler, abuseHandler, auditHandler, rateLimiter, responseCache, idempotencyGuard, keys, revocations)
 
 	// Configuration du serveur HTTP
 	server := &http.Server{
@@ -93,19 +245,49 @@ func main() {
 	}()
 
 	// Gestion gracieuse de l'arrêt
-	gracefulShutdown(server, gatewayServer)
+	gracefulShutdown(server, gatewayServer, serviceRegistry, loadBalancer, rateLimiter, responseCache, splitter, idempotencyGuard, keys, revocations, maintenanceScheduler, abuseDetector, auditRecorder, shutdownTracing)
 }
 
 // setupRoutes configure toutes les routes du gateway
-func setupRoutes(gatewayServer *gateway.Server, cfg *config.Config, gatewayHandler *handlers.GatewayHandler) *gin.Engine {
+func setupRoutes(
+	gatewayServer *gateway.Server,
+	cfg *config.Config,
+	gatewayHandler *handlers.GatewayHandler,
+	bootstrapHandler *handlers.BootstrapHandler,
+	openAPIHandler *handlers.OpenAPIHandler,
+	trafficHandler *handlers.TrafficHandler,
+	maintenanceHandler *handlers.MaintenanceHandler,
+	abuseHandler *handlers.AbuseHandler,
+	auditHandler *handlers.AuditHandler,
+	rateLimiter *ratelimit.Limiter,
+	responseCache *cache.Cache,
+	idempotencyGuard *idempotency.Guard,
+	keys *jwks.KeySet,
+	revocations *revocation.Checker,
+) *gin.Engine {
 	router := gin.New()
 
+	// Adresse du client (blocages, listes CIDR, rate limiting anonyme) : X-Forwarded-For
+	// n'est lu que derrière un proxy de confiance, sinon n'importe quel client pourrait la choisir
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logrus.Fatal("Invalid trusted proxies: ", err)
+	}
+
 	// Middleware globaux
 	router.Use(middleware.Logger())
 	router.Use(middleware.Recovery())
 	router.Use(middleware.CORS())
 	router.Use(middleware.RequestID())
-	router.Use(middleware.RateLimit(cfg.RateLimit))
+	if auditHandler != nil {
+		router.Use(middleware.Audit(auditHandler.Recorder, keys))
+	}
+	if abuseHandler != nil {
+		router.Use(middleware.Abuse(abuseHandler.Detector, keys, cfg.Idempotency.Header, cfg.Abuse.MaxBodySize))
+	}
+	router.Use(middleware.BlockInternalRoutes(cfg.InternalRoutes.BlockedPrefixes))
+	router.Use(tracing.Middleware())
+	router.Use(middleware.RateLimit(rateLimiter, keys))
+	router.Use(middleware.Maintenance(maintenanceHandler.Maintenance, cfg.Maintenance.ExemptPaths, keys, cfg.JWT.StaffTwoFactor))
 	router.Use(middleware.Metrics())
 
 	// Routes de santé et monitoring
@@ -120,6 +302,7 @@ func setupRoutes(gatewayServer *gateway.Server, cfg *config.Config, gatewayHandl
 		authDirect.GET("/metrics", gatewayServer.ProxyTo("auth"))
 		authDirect.POST("/register", gatewayServer.ProxyTo("auth"))
 		authDirect.POST("/login", gatewayServer.ProxyTo("auth"))
+		authDirect.POST("/login/2fa", gatewayServer.ProxyTo("auth"))
 		authDirect.POST("/refresh", gatewayServer.ProxyTo("auth"))
 		authDirect.POST("/forgot-password", gatewayServer.ProxyTo("auth"))
 		authDirect.POST("/reset-password", gatewayServer.ProxyTo("auth"))
@@ -138,7 +321,59 @@ func setupRoutes(gatewayServer *gateway.Server, cfg *config.Config, gatewayHandl
 		gw.GET("/version", gatewayHandler.VersionInfo)
 		gw.GET("/info", gatewayHandler.Info)
 		gw.GET("/health/all", gatewayHandler.HealthAll)
-		gw.POST("/reload", gatewayHandler.Reload)
+
+		// Fenêtres de maintenance (bannières des clients)
+		gw.GET("/maintenance", maintenanceHandler.List)
+
+		// Contrats OpenAPI
+		if cfg.OpenAPI.Enabled {
+			gw.GET("/openapi.json", openAPIHandler.Spec)
+			gw.GET("/openapi/:service", openAPIHandler.ServiceSpec)
+		}
+
+		// Enregistrement des instances par les services eux-mêmes (token partagé)
+		registry := gw.Group("/registry")
+		registry.Use(middleware.RegistryAuth(cfg.Registry.Token))
+		{
+			registry.POST("/instances", gatewayHandler.RegisterInstance)
+			registry.PUT("/instances/:service/:id/heartbeat", gatewayHandler.HeartbeatInstance)
+			registry.DELETE("/instances/:service/:id", gatewayHandler.DeregisterInstance)
+		}
+
+		// Administration du gateway (comptes admin authentifiés)
+		adminAPI := gw.Group("/")
+		adminAPI.Use(middleware.JWTAuth(keys, revocations))
+		adminAPI.Use(middleware.RequireRole(middleware.RoleAdmin, middleware.RoleSuperUser))
+		adminAPI.Use(middleware.RequireTwoFactor(cfg.JWT.StaffTwoFactor))
+		{
+			adminAPI.POST("/reload", gatewayHandler.Reload)
+
+			// Releases canary : répartition du trafic entre les versions d'un service
+			adminAPI.GET("/traffic", trafficHandler.Status)
+			adminAPI.PUT("/traffic/:service", trafficHandler.SetRule)
+			adminAPI.DELETE("/traffic/:service", trafficHandler.DeleteRule)
+
+			// Maintenance planifiée : 503 avec Retry-After pendant la fenêtre, sauf pour le staff
+			adminAPI.POST("/maintenance", maintenanceHandler.Schedule)
+			adminAPI.GET("/maintenance/:id", maintenanceHandler.Get)
+			adminAPI.DELETE("/maintenance/:id", maintenanceHandler.Cancel)
+
+			// Drain d'une instance avant son redémarrage
+			adminAPI.POST("/registry/instances/:service/:id/drain", maintenanceHandler.DrainInstance)
+			adminAPI.DELETE("/registry/instances/:service/:id/drain", maintenanceHandler.ResumeInstance)
+
+			// Détection des abus : inspection et levée des blocages temporaires
+			if abuseHandler != nil {
+				adminAPI.GET("/abuse/blocks", abuseHandler.Blocks)
+				adminAPI.GET("/abuse/clients/:kind/:value", abuseHandler.Client)
+				adminAPI.DELETE("/abuse/blocks/:kind/:value", abuseHandler.Unblock)
+			}
+
+			// Journal d'audit des écritures
+			if auditHandler != nil {
+				adminAPI.GET("/audit", auditHandler.Query)
+			}
+		}
 	}
 
 	// API Gateway routes (structure complète)
@@ -149,13 +384,22 @@ func setupRoutes(gatewayServer *gateway.Server, cfg *config.Config, gatewayHandl
 		{
 			auth.POST("/register", gatewayServer.ProxyTo("auth"))
 			auth.POST("/login", gatewayServer.ProxyTo("auth"))
+			auth.POST("/login/2fa", gatewayServer.ProxyTo("auth"))
 			auth.POST("/refresh", gatewayServer.ProxyTo("auth"))
 		}
 
 		// Routes protégées (JWT requis)
 		protected := api.Group("/")
-		protected.Use(middleware.JWTAuth(cfg.JWT.Secret))
+		protected.Use(middleware.JWTAuth(keys, revocations))
+		protected.Use(middleware.ResponseCache(responseCache))
+		protected.Use(middleware.Idempotency(idempotencyGuard))
 		{
+			// Données de connexion agrégées depuis plusieurs services
+			me := protected.Group("/me")
+			{
+				me.GET("/bootstrap", bootstrapHandler.Bootstrap)
+			}
+
 			// Player Service
 			player := protected.Group("/player")
 			{
@@ -180,6 +424,17 @@ func setupRoutes(gatewayServer *gateway.Server, cfg *config.Config, gatewayHandl
 				user.DELETE("/sessions/:id", gatewayServer.ProxyTo("auth"))
 			}
 
+			// Two-Factor Authentication via Auth service
+			twofa := protected.Group("/2fa")
+			{
+				twofa.POST("/enable", gatewayServer.ProxyTo("auth"))
+				twofa.POST("/disable", gatewayServer.ProxyTo("auth"))
+				twofa.GET("/qr", gatewayServer.ProxyTo("auth"))
+				twofa.POST("/verify", gatewayServer.ProxyTo("auth"))
+				twofa.GET("/backup-codes", gatewayServer.ProxyTo("auth"))
+				twofa.POST("/regenerate-codes", gatewayServer.ProxyTo("auth"))
+			}
+
 			// World Service
 			world := protected.Group("/world")
 			{
@@ -197,6 +452,7 @@ func setupRoutes(gatewayServer *gateway.Server, cfg *config.Config, gatewayHandl
 				combat.POST("/action", gatewayServer.ProxyTo("combat"))
 				combat.GET("/status/:characterId", gatewayServer.ProxyTo("combat"))
 				combat.POST("/pvp/challenge", gatewayServer.ProxyTo("combat"))
+				combat.GET("/ws", gatewayServer.ProxyTo("combat"))
 			}
 
 			// Inventory Service
@@ -227,11 +483,13 @@ func setupRoutes(gatewayServer *gateway.Server, cfg *config.Config, gatewayHandl
 				chat.POST("/channels", gatewayServer.ProxyTo("chat"))
 				chat.GET("/messages/:channelId", gatewayServer.ProxyTo("chat"))
 				chat.POST("/messages", gatewayServer.ProxyTo("chat"))
+				chat.GET("/ws", gatewayServer.ProxyTo("chat"))
 			}
 
 			// Analytics Service (admin seulement)
 			analytics := protected.Group("/analytics")
 			analytics.Use(middleware.RequireRole("admin"))
+			analytics.Use(middleware.RequireTwoFactor(cfg.JWT.StaffTwoFactor))
 			{
 				analytics.GET("/dashboard", gatewayServer.ProxyTo("analytics"))
 				analytics.GET("/metrics", gatewayServer.ProxyTo("analytics"))
@@ -247,8 +505,8 @@ func setupRoutes(gatewayServer *gateway.Server, cfg *config.Config, gatewayHandl
 		}
 	}
 
-	// WebSocket endpoint pour le chat temps réel
-	router.GET("/ws", gatewayServer.HandleWebSocket)
+	// Canal WebSocket temps réel (authentifié à l'upgrade)
+	router.GET("/ws", middleware.JWTAuth(keys, revocations), gatewayServer.HandleWebSocket)
 
 	// Routes de debug (développement seulement)
 	if cfg.Server.Debug {
@@ -263,6 +521,15 @@ func setupRoutes(gatewayServer *gateway.Server, cfg *config.Config, gatewayHandl
 	return router
 }
 
+// loadContracts lit les contrats OpenAPI embarqués
+func loadContracts() (map[string]*openapi.Document, error) {
+	specs, err := fs.Sub(api.Specs, "openapi")
+	if err != nil {
+		return nil, err
+	}
+	return openapi.LoadAll(specs)
+}
+
 // initLogger initialize le logger global
 func initLogger() {
 	logrus.SetFormatter(&logrus.JSONFormatter{
@@ -280,7 +547,22 @@ func initLogger() {
 }
 
 // gracefulShutdown gère l'arrêt gracieux du serveur
-func gracefulShutdown(server *http.Server, gatewayServer *gateway.Server) {
+func gracefulShutdown(
+	server *http.Server,
+	gatewayServer *gateway.Server,
+	serviceRegistry *registry.Registry,
+	loadBalancer *balancer.Balancer,
+	rateLimiter *ratelimit.Limiter,
+	responseCache *cache.Cache,
+	splitter *traffic.Splitter,
+	idempotencyGuard *idempotency.Guard,
+	keys *jwks.KeySet,
+	revocations *revocation.Checker,
+	maintenanceScheduler *maintenance.Scheduler,
+	abuseDetector *abuse.Detector,
+	auditRecorder *audit.Recorder,
+	shutdownTracing func(context.Context) error,
+) {
 	// Canal pour capturer les signaux système
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -303,5 +585,29 @@ func gracefulShutdown(server *http.Server, gatewayServer *gateway.Server) {
 		logrus.Error("Error closing gateway server:", err)
 	}
 
+	// Arrêter les health checks et la surveillance du registre
+	loadBalancer.Close()
+	serviceRegistry.Close()
+	rateLimiter.Close()
+	responseCache.Close()
+	splitter.Close()
+	idempotencyGuard.Close()
+	keys.Close()
+	maintenanceScheduler.Close()
+	if revocations != nil {
+		revocations.Close()
+	}
+	if abuseDetector != nil {
+		abuseDetector.Close()
+	}
+	if auditRecorder != nil {
+		auditRecorder.Close()
+	}
+
+	// Exporter les derniers spans
+	if err := shutdownTracing(ctx); err != nil {
+		logrus.Error("Error flushing traces:", err)
+	}
+
 	logrus.Info("✅ Gateway Service stopped")
 }
@@ -0,0 +1,109 @@
+package abuse
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"time"
+)
+
+// baselineSmoothing poids de la dernière fenêtre dans le débit habituel d'un client
+const baselineSmoothing = 0.2
+
+// client activité récente d'une IP ou d'un utilisateur
+type client struct {
+	score   float64
+	updated time.Time // dernière mise à jour du score
+	signals map[string]int
+
+	windowStart time.Time
+	requests    int
+	baseline    float64 // moyenne glissante des requêtes par fenêtre, hors anomalies
+	rateFlagged bool    // anomalie de débit déjà comptée dans la fenêtre
+	notFound    map[string]struct{}
+	signatures  map[string]time.Time // écritures récentes
+
+	strikes   int
+	lastBlock time.Time // fin du dernier blocage
+}
+
+func newClient(now time.Time) *client {
+	return &client{
+		updated:     now,
+		signals:     make(map[string]int),
+		windowStart: now,
+		notFound:    make(map[string]struct{}),
+		signatures:  make(map[string]time.Time),
+	}
+}
+
+// decayedScore score du client à un instant, divisé par deux à chaque demi-vie
+func (cl *client) decayedScore(now time.Time, halfLife time.Duration) float64 {
+	elapsed := now.Sub(cl.updated)
+	if elapsed <= 0 {
+		return cl.score
+	}
+	return cl.score * math.Pow(0.5, float64(elapsed)/float64(halfLife))
+}
+
+// decay applique la décroissance du score
+func (cl *client) decay(now time.Time, halfLife time.Duration) {
+	cl.score = cl.decayedScore(now, halfLife)
+	cl.updated = now
+}
+
+// rateLimit nombre de requêtes par fenêtre au-delà duquel le débit du client est anormal
+func (cl *client) rateLimit(minRequests int, factor float64) float64 {
+	return math.Max(float64(minRequests), factor*cl.baseline)
+}
+
+// roll passe aux fenêtres suivantes si la fenêtre en cours est terminée
+// Le débit habituel apprend des fenêtres écoulées (plafonnées à la limite, pour qu'un
+// client abusif ne relève pas sa propre limite), les fenêtres sans requête comptant pour zéro.
+func (cl *client) roll(now time.Time, window time.Duration, minRequests int, factor float64) {
+	elapsed := now.Sub(cl.windowStart)
+	if elapsed < window {
+		return
+	}
+
+	windows := int(elapsed / window)
+	limit := cl.rateLimit(minRequests, factor)
+	cl.baseline = baselineSmoothing*math.Min(float64(cl.requests), limit) + (1-baselineSmoothing)*cl.baseline
+	if windows > 1 {
+		cl.baseline *= math.Pow(1-baselineSmoothing, float64(windows-1))
+	}
+
+	cl.windowStart = cl.windowStart.Add(time.Duration(windows) * window)
+	cl.requests = 0
+	cl.rateFlagged = false
+	cl.notFound = make(map[string]struct{})
+}
+
+// replayed enregistre une écriture et indique si elle a déjà été vue dans la fenêtre de rejeu
+func (cl *client) replayed(signature string, now time.Time, replayWindow time.Duration) bool {
+	for seen, at := range cl.signatures {
+		if now.Sub(at) >= replayWindow {
+			delete(cl.signatures, seen)
+		}
+	}
+
+	_, seen := cl.signatures[signature]
+	cl.signatures[signature] = now
+	return seen
+}
+
+// idle indique si le client peut être oublié : score négligeable, inactif, sans blocage récent
+func (cl *client) idle(now time.Time, halfLife, window, maxBlockDuration time.Duration) bool {
+	if cl.decayedScore(now, halfLife) >= 1 || now.Sub(cl.updated) < window {
+		return false
+	}
+	return cl.strikes == 0 || now.Sub(cl.lastBlock) >= maxBlockDuration
+}
+
+// Signature empreinte d'une écriture (méthode, chemin avec paramètres, corps) pour détecter les rejeux
+func Signature(method, requestURI string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + requestURI + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,102 @@
+package abuse
+
+import (
+	"math"
+	"time"
+)
+
+// Clients suivis : IP des requêtes anonymes, utilisateur des requêtes authentifiées
+const (
+	KindIP   = "ip"
+	KindUser = "user"
+)
+
+// Signaux d'abus (label des métriques)
+const (
+	SignalRateAnomaly = "rate_anomaly"
+	SignalAuthFailure = "auth_failure"
+	SignalEnumeration = "enumeration"
+	SignalReplay      = "replay"
+)
+
+// Motifs de refus d'une requête
+const (
+	ReasonBlocked = "blocked" // blocage temporaire
+	ReasonDenied  = "denied"  // liste CIDR deny
+)
+
+// Types des décisions publiées (type d'événement analytics)
+const (
+	DecisionBlock   = "abuse_block"
+	DecisionUnblock = "abuse_unblock"
+)
+
+// ValidKind vérifie qu'un type de client est connu
+func ValidKind(kind string) bool {
+	return kind == KindIP || kind == KindUser
+}
+
+// Block blocage temporaire d'une IP ou d'un utilisateur
+type Block struct {
+	Kind      string         `json:"kind"`
+	Value     string         `json:"value"`
+	Reason    string         `json:"reason"` // signal qui a le plus contribué au score
+	Score     float64        `json:"score"`
+	Signals   map[string]int `json:"signals"`
+	Strike    int            `json:"strike"` // blocages successifs du client, la durée double à chaque fois
+	CreatedAt time.Time      `json:"created_at"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+// Active indique si le blocage est en cours
+func (b *Block) Active(now time.Time) bool {
+	return now.Before(b.ExpiresAt)
+}
+
+// RetryAfter secondes restantes avant la fin du blocage (au moins 1), pour l'en-tête Retry-After
+func (b *Block) RetryAfter(now time.Time) int {
+	seconds := int(math.Ceil(b.ExpiresAt.Sub(now).Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// Verdict refus d'une requête
+type Verdict struct {
+	Reason string
+	Block  *Block // nil pour une IP de la liste deny
+}
+
+// Decision blocage ou levée de blocage, publiée pour l'analytics et les autres réplicas
+type Decision struct {
+	Type      string    `json:"type"`
+	Origin    string    `json:"origin"`
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"`
+	PlayerID  string    `json:"player_id,omitempty"` // blocage d'un utilisateur
+	Block     *Block    `json:"block,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Observation requête terminée, analysée après la réponse du service
+type Observation struct {
+	IP        string
+	UserID    string // vide pour une requête anonyme
+	Path      string
+	Status    int
+	Signature string // empreinte d'une écriture sans clé d'idempotence, vide sinon
+}
+
+// ClientState état d'un client, pour l'API d'administration
+type ClientState struct {
+	Kind     string         `json:"kind"`
+	Value    string         `json:"value"`
+	Score    float64        `json:"score"`
+	Requests int            `json:"window_requests"` // requêtes dans la fenêtre en cours
+	Baseline float64        `json:"baseline"`        // débit habituel par fenêtre
+	Signals  map[string]int `json:"signals"`         // signaux depuis le dernier blocage
+	Strikes  int            `json:"strikes"`
+	LastSeen time.Time      `json:"last_seen,omitempty"`
+	Block    *Block         `json:"block,omitempty"`
+}
@@ -0,0 +1,513 @@
+package abuse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gateway/internal/config"
+	"gateway/internal/tracing"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+// Origine des blocages (label des métriques)
+const (
+	originLocal = "local"
+	originPeer  = "peer"
+)
+
+// ErrBlockNotFound aucun blocage en cours pour ce client
+var ErrBlockNotFound = errors.New("abuse block not found")
+
+// Detector score les clients et les bloque temporairement
+// Les signaux (débit anormal, échecs d'authentification, énumération, rejeux) augmentent le
+// score du client, qui décroît de moitié à chaque demi-vie. Les requêtes authentifiées sont
+// comptées par utilisateur, les autres par IP. Les décisions sont publiées sur NATS : les
+// autres réplicas appliquent les blocages et l'analytics les enregistre.
+type Detector struct {
+	config   *config.AbuseConfig
+	natsConn *nats.Conn
+	origin   string
+	allow    []*net.IPNet
+	deny     []*net.IPNet
+	exempt   []string
+
+	mu      sync.Mutex
+	clients map[string]*client
+	blocks  map[string]*Block
+
+	subscription *nats.Subscription
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+// NewDetector crée le détecteur d'abus
+// natsConn est optionnel : sans NATS, les blocages restent locaux au réplica.
+func NewDetector(cfg *config.AbuseConfig, natsConn *nats.Conn) (*Detector, error) {
+	allow, err := parseCIDRs(cfg.AllowCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid abuse allow list: %w", err)
+	}
+	deny, err := parseCIDRs(cfg.DenyCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid abuse deny list: %w", err)
+	}
+
+	exempt := make([]string, 0, len(cfg.ExemptPaths))
+	for _, path := range cfg.ExemptPaths {
+		if path = strings.TrimRight(strings.TrimSpace(path), "/"); path != "" {
+			exempt = append(exempt, path)
+		}
+	}
+
+	return &Detector{
+		config:   cfg,
+		natsConn: natsConn,
+		origin:   uuid.New().String(),
+		allow:    allow,
+		deny:     deny,
+		exempt:   exempt,
+		clients:  make(map[string]*client),
+		blocks:   make(map[string]*Block),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Start s'abonne aux décisions des autres réplicas et lance le nettoyage périodique
+func (d *Detector) Start() error {
+	go d.cleanupLoop()
+
+	if d.natsConn == nil || d.config.Subject == "" {
+		return nil
+	}
+
+	subscription, err := d.natsConn.Subscribe(d.config.Subject, d.handleDecision)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.subscription = subscription
+	d.mu.Unlock()
+	return nil
+}
+
+// Close arrête le nettoyage et la réception des décisions
+func (d *Detector) Close() {
+	d.closeOnce.Do(func() {
+		close(d.done)
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		if d.subscription != nil {
+			if err := d.subscription.Unsubscribe(); err != nil {
+				logrus.WithError(err).Debug("Failed to unsubscribe from abuse decisions")
+			}
+			d.subscription = nil
+		}
+	})
+}
+
+// Exempt indique une route jamais observée (santé, métriques)
+func (d *Detector) Exempt(path string) bool {
+	for _, prefix := range d.exempt {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Check retourne le refus d'une requête, ou nil si elle peut passer
+func (d *Detector) Check(ip, userID string) *Verdict {
+	address := net.ParseIP(ip)
+	if contains(d.allow, address) {
+		return nil
+	}
+	if contains(d.deny, address) {
+		rejectionsTotal.WithLabelValues(ReasonDenied).Inc()
+		return &Verdict{Reason: ReasonDenied}
+	}
+
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, key := range []string{clientKey(KindIP, ip), clientKey(KindUser, userID)} {
+		if block, exists := d.blocks[key]; exists && block.Active(now) {
+			rejectionsTotal.WithLabelValues(ReasonBlocked).Inc()
+			return &Verdict{Reason: ReasonBlocked, Block: block}
+		}
+	}
+	return nil
+}
+
+// Observe analyse une requête terminée et bloque le client si son score dépasse le seuil
+func (d *Detector) Observe(ctx context.Context, observation *Observation) {
+	if contains(d.allow, net.ParseIP(observation.IP)) {
+		return
+	}
+
+	kind, value := KindIP, observation.IP
+	if observation.UserID != "" {
+		kind, value = KindUser, observation.UserID
+	}
+
+	now := time.Now()
+	d.mu.Lock()
+	cl := d.client(clientKey(kind, value), now)
+	if cl == nil {
+		d.mu.Unlock()
+		return
+	}
+
+	cl.roll(now, d.config.Window, d.config.RateMinRequests, d.config.RateFactor)
+	cl.decay(now, d.config.ScoreHalfLife)
+	cl.requests++
+
+	var signals []string
+	if !cl.rateFlagged && float64(cl.requests) > cl.rateLimit(d.config.RateMinRequests, d.config.RateFactor) {
+		cl.rateFlagged = true
+		signals = append(signals, SignalRateAnomaly)
+	}
+	switch observation.Status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		signals = append(signals, SignalAuthFailure)
+	case http.StatusNotFound:
+		if _, seen := cl.notFound[observation.Path]; !seen {
+			cl.notFound[observation.Path] = struct{}{}
+			signals = append(signals, SignalEnumeration)
+		}
+	}
+	if observation.Signature != "" && cl.replayed(observation.Signature, now, d.config.ReplayWindow) {
+		signals = append(signals, SignalReplay)
+	}
+
+	for _, signal := range signals {
+		cl.score += d.weight(signal)
+		cl.signals[signal]++
+		signalsTotal.WithLabelValues(signal).Inc()
+	}
+
+	var block *Block
+	if len(signals) > 0 && cl.score >= d.config.BlockThreshold {
+		if existing, exists := d.blocks[clientKey(kind, value)]; !exists || !existing.Active(now) {
+			block = d.block(kind, value, cl, now)
+		}
+	}
+	d.mu.Unlock()
+
+	if block == nil {
+		return
+	}
+
+	blocksTotal.WithLabelValues(kind, originLocal).Inc()
+	logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"kind":       kind,
+		"value":      value,
+		"reason":     block.Reason,
+		"score":      block.Score,
+		"strike":     block.Strike,
+		"expires_at": block.ExpiresAt,
+	}).Warn("Client temporarily blocked for abuse")
+
+	d.publish(ctx, d.decision(DecisionBlock, kind, value, block, now))
+}
+
+// Blocks retourne les blocages en cours, les plus récents d'abord
+func (d *Detector) Blocks() []*Block {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	blocks := make([]*Block, 0, len(d.blocks))
+	for _, block := range d.blocks {
+		if block.Active(now) {
+			blocks = append(blocks, block)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].CreatedAt.After(blocks[j].CreatedAt) })
+	return blocks
+}
+
+// Client retourne l'état d'une IP ou d'un utilisateur
+func (d *Detector) Client(kind, value string) (*ClientState, bool) {
+	now := time.Now()
+	key := clientKey(kind, value)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cl, tracked := d.clients[key]
+	block, blocked := d.blocks[key]
+	if blocked && !block.Active(now) {
+		blocked = false
+	}
+	if !tracked && !blocked {
+		return nil, false
+	}
+
+	state := &ClientState{Kind: kind, Value: value, Signals: map[string]int{}}
+	if tracked {
+		state.Score = cl.decayedScore(now, d.config.ScoreHalfLife)
+		state.Requests = cl.requests
+		state.Baseline = cl.baseline
+		state.Strikes = cl.strikes
+		state.LastSeen = cl.updated
+		for signal, count := range cl.signals {
+			state.Signals[signal] = count
+		}
+	}
+	if blocked {
+		state.Block = block
+	}
+	return state, true
+}
+
+// Unblock lève le blocage d'une IP ou d'un utilisateur, sur tous les réplicas
+func (d *Detector) Unblock(ctx context.Context, kind, value string) error {
+	now := time.Now()
+	if !d.unblock(clientKey(kind, value), now) {
+		return ErrBlockNotFound
+	}
+
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"kind": kind, "value": value}).Info("Abuse block lifted")
+	d.publish(ctx, d.decision(DecisionUnblock, kind, value, nil, now))
+	return nil
+}
+
+// client retourne l'état d'un client, créé au besoin (nil si trop de clients sont suivis)
+func (d *Detector) client(key string, now time.Time) *client {
+	if cl, exists := d.clients[key]; exists {
+		return cl
+	}
+	if len(d.clients) >= d.config.MaxClients {
+		return nil
+	}
+
+	cl := newClient(now)
+	d.clients[key] = cl
+	trackedClients.Set(float64(len(d.clients)))
+	return cl
+}
+
+// block bloque un client ; la durée double à chaque récidive, jusqu'au maximum configuré
+func (d *Detector) block(kind, value string, cl *client, now time.Time) *Block {
+	duration := d.config.BlockDuration
+	for range cl.strikes {
+		if duration >= d.config.MaxBlockDuration {
+			break
+		}
+		duration *= 2
+	}
+	duration = min(duration, d.config.MaxBlockDuration)
+
+	signals := make(map[string]int, len(cl.signals))
+	reason, reasonScore := "", 0.0
+	for signal, count := range cl.signals {
+		signals[signal] = count
+		if contribution := float64(count) * d.weight(signal); contribution > reasonScore {
+			reason, reasonScore = signal, contribution
+		}
+	}
+
+	cl.strikes++
+	block := &Block{
+		Kind:      kind,
+		Value:     value,
+		Reason:    reason,
+		Score:     cl.score,
+		Signals:   signals,
+		Strike:    cl.strikes,
+		CreatedAt: now,
+		ExpiresAt: now.Add(duration),
+	}
+
+	cl.score = 0
+	cl.signals = make(map[string]int)
+	cl.lastBlock = block.ExpiresAt
+
+	d.blocks[clientKey(kind, value)] = block
+	activeBlocks.Set(float64(len(d.blocks)))
+	return block
+}
+
+// unblock supprime un blocage en cours et remet le score du client à zéro
+func (d *Detector) unblock(key string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	block, exists := d.blocks[key]
+	if !exists {
+		return false
+	}
+	delete(d.blocks, key)
+	activeBlocks.Set(float64(len(d.blocks)))
+
+	if cl, tracked := d.clients[key]; tracked {
+		cl.score = 0
+		cl.updated = now
+		cl.signals = make(map[string]int)
+	}
+	return block.Active(now)
+}
+
+// weight poids d'un signal dans le score
+func (d *Detector) weight(signal string) float64 {
+	switch signal {
+	case SignalRateAnomaly:
+		return d.config.RateWeight
+	case SignalAuthFailure:
+		return d.config.AuthFailureWeight
+	case SignalEnumeration:
+		return d.config.EnumerationWeight
+	case SignalReplay:
+		return d.config.ReplayWeight
+	default:
+		return 0
+	}
+}
+
+// decision prépare la publication d'un blocage ou d'une levée de blocage
+func (d *Detector) decision(decisionType, kind, value string, block *Block, now time.Time) *Decision {
+	decision := &Decision{
+		Type:      decisionType,
+		Origin:    d.origin,
+		Kind:      kind,
+		Value:     value,
+		Block:     block,
+		Timestamp: now,
+	}
+	if kind == KindUser {
+		decision.PlayerID = value
+	}
+	return decision
+}
+
+// publish diffuse une décision (sans NATS, elle reste locale au réplica)
+func (d *Detector) publish(ctx context.Context, decision *Decision) {
+	if d.natsConn == nil || d.config.Subject == "" {
+		return
+	}
+
+	data, err := json.Marshal(decision)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode abuse decision")
+		return
+	}
+	if err := tracing.Publish(ctx, d.natsConn, d.config.Subject, data); err != nil {
+		logrus.WithContext(ctx).WithError(err).WithField("subject", d.config.Subject).Warn("Failed to publish abuse decision")
+	}
+}
+
+// handleDecision applique un blocage ou une levée de blocage décidé par un autre réplica
+func (d *Detector) handleDecision(msg *nats.Msg) {
+	ctx, span := tracing.StartConsumerSpan(msg)
+	defer span.End()
+
+	var decision Decision
+	if err := json.Unmarshal(msg.Data, &decision); err != nil {
+		logrus.WithContext(ctx).WithError(err).Warn("Invalid abuse decision")
+		return
+	}
+	if decision.Origin == d.origin || !ValidKind(decision.Kind) {
+		return
+	}
+
+	key := clientKey(decision.Kind, decision.Value)
+	now := time.Now()
+
+	switch decision.Type {
+	case DecisionBlock:
+		if decision.Block == nil || !decision.Block.Active(now) {
+			return
+		}
+		d.mu.Lock()
+		if existing, exists := d.blocks[key]; !exists || existing.ExpiresAt.Before(decision.Block.ExpiresAt) {
+			d.blocks[key] = decision.Block
+			activeBlocks.Set(float64(len(d.blocks)))
+			blocksTotal.WithLabelValues(decision.Kind, originPeer).Inc()
+		}
+		d.mu.Unlock()
+	case DecisionUnblock:
+		d.unblock(key, now)
+	}
+}
+
+// cleanupLoop oublie périodiquement les blocages expirés et les clients inactifs
+func (d *Detector) cleanupLoop() {
+	ticker := time.NewTicker(d.config.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case now := <-ticker.C:
+			d.cleanup(now)
+		}
+	}
+}
+
+func (d *Detector) cleanup(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key, block := range d.blocks {
+		if !block.Active(now) {
+			delete(d.blocks, key)
+		}
+	}
+	for key, cl := range d.clients {
+		if cl.idle(now, d.config.ScoreHalfLife, d.config.Window, d.config.MaxBlockDuration) {
+			delete(d.clients, key)
+		}
+	}
+
+	activeBlocks.Set(float64(len(d.blocks)))
+	trackedClients.Set(float64(len(d.clients)))
+}
+
+// clientKey clé d'un client dans les tables du détecteur
+func clientKey(kind, value string) string {
+	return kind + ":" + value
+}
+
+// parseCIDRs lit une liste de réseaux CIDR
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if cidr = strings.TrimSpace(cidr); cidr == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// contains indique si une adresse appartient à l'un des réseaux
+func contains(networks []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,273 @@
+package abuse
+
+import (
+	"context"
+	"errors"
+	"gateway/internal/config"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const (
+	testIP     = "203.0.113.7"
+	testUserID = "0a1b2c3d-4e5f-4a6b-8c7d-8e9f0a1b2c3d"
+)
+
+// newTestDetector détecteur sans NATS : un client est bloqué au deuxième signal d'authentification
+func newTestDetector(t *testing.T) *Detector {
+	t.Helper()
+
+	detector, err := NewDetector(&config.AbuseConfig{
+		AllowCIDRs:        []string{"10.0.0.0/8"},
+		DenyCIDRs:         []string{"198.51.100.0/24", " "},
+		ExemptPaths:       []string{"/health/"},
+		BlockThreshold:    10,
+		ScoreHalfLife:     time.Hour,
+		Window:            time.Minute,
+		BlockDuration:     time.Minute,
+		MaxBlockDuration:  3 * time.Minute,
+		RateMinRequests:   5,
+		RateFactor:        3,
+		RateWeight:        4,
+		AuthFailureWeight: 6,
+		EnumerationWeight: 2,
+		ReplayWindow:      time.Minute,
+		ReplayWeight:      3,
+		MaxClients:        100,
+		CleanupInterval:   time.Minute,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewDetector: %v", err)
+	}
+	return detector
+}
+
+// observe envoie une requête terminée au détecteur
+func observe(d *Detector, ip, userID, path string, status int) {
+	d.Observe(context.Background(), &Observation{IP: ip, UserID: userID, Path: path, Status: status})
+}
+
+func TestNewDetectorInvalidCIDR(t *testing.T) {
+	if _, err := NewDetector(&config.AbuseConfig{DenyCIDRs: []string{"not-a-network"}}, nil); err == nil {
+		t.Fatal("invalid deny list accepted")
+	}
+}
+
+func TestDetectorLists(t *testing.T) {
+	detector := newTestDetector(t)
+
+	if verdict := detector.Check("198.51.100.20", ""); verdict == nil || verdict.Reason != ReasonDenied {
+		t.Errorf("denied IP verdict = %+v, want denied", verdict)
+	}
+	if verdict := detector.Check(testIP, ""); verdict != nil {
+		t.Errorf("unknown IP verdict = %+v, want nil", verdict)
+	}
+
+	// Les IP de la liste allow ne sont jamais scorées
+	for range 5 {
+		observe(detector, "10.1.2.3", "", "/api/v1/login", http.StatusUnauthorized)
+	}
+	if verdict := detector.Check("10.1.2.3", ""); verdict != nil {
+		t.Errorf("allowed IP verdict = %+v, want nil", verdict)
+	}
+	if _, tracked := detector.Client(KindIP, "10.1.2.3"); tracked {
+		t.Error("allowed IP tracked")
+	}
+}
+
+func TestDetectorExempt(t *testing.T) {
+	detector := newTestDetector(t)
+
+	for path, want := range map[string]bool{"/health": true, "/health/ready": true, "/healthz": false, "/api": false} {
+		if got := detector.Exempt(path); got != want {
+			t.Errorf("Exempt(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+// TestDetectorBlocksAuthFailures les échecs d'authentification d'une IP anonyme mènent au blocage
+func TestDetectorBlocksAuthFailures(t *testing.T) {
+	detector := newTestDetector(t)
+
+	observe(detector, testIP, "", "/api/v1/login", http.StatusUnauthorized)
+	if verdict := detector.Check(testIP, ""); verdict != nil {
+		t.Fatalf("blocked after one failure: %+v", verdict)
+	}
+	observe(detector, testIP, "", "/api/v1/login", http.StatusForbidden)
+
+	verdict := detector.Check(testIP, "")
+	if verdict == nil || verdict.Reason != ReasonBlocked {
+		t.Fatalf("verdict = %+v, want blocked", verdict)
+	}
+	block := verdict.Block
+	if block.Kind != KindIP || block.Reason != SignalAuthFailure || block.Strike != 1 || block.Signals[SignalAuthFailure] != 2 {
+		t.Errorf("block = %+v", block)
+	}
+	if retryAfter := block.RetryAfter(block.CreatedAt); retryAfter != 60 {
+		t.Errorf("RetryAfter = %d, want 60", retryAfter)
+	}
+
+	// Le score est remis à zéro par le blocage
+	state, _ := detector.Client(KindIP, testIP)
+	if state.Score != 0 || state.Block == nil || len(state.Signals) != 0 {
+		t.Errorf("client state after block = %+v", state)
+	}
+}
+
+// TestDetectorScoresUsers les requêtes authentifiées sont comptées par utilisateur, pas par IP
+func TestDetectorScoresUsers(t *testing.T) {
+	detector := newTestDetector(t)
+
+	for _, path := range []string{"/items/1", "/items/2", "/items/3", "/items/3", "/items/4", "/items/5"} {
+		observe(detector, testIP, testUserID, path, http.StatusNotFound)
+	}
+
+	verdict := detector.Check("192.0.2.1", testUserID)
+	if verdict == nil || verdict.Block.Kind != KindUser || verdict.Block.Reason != SignalEnumeration {
+		t.Fatalf("verdict = %+v, want enumeration block of the user", verdict)
+	}
+	if verdict.Block.Signals[SignalEnumeration] != 5 {
+		t.Errorf("enumeration signals = %d, want 5 (repeated path counted once)", verdict.Block.Signals[SignalEnumeration])
+	}
+	if detector.Check(testIP, "") != nil {
+		t.Error("IP of the blocked user refused for anonymous requests")
+	}
+}
+
+func TestDetectorReplay(t *testing.T) {
+	detector := newTestDetector(t)
+	signature := Signature(http.MethodPost, "/api/v1/trade?x=1", []byte(`{"gold":100}`))
+
+	for range 4 {
+		detector.Observe(context.Background(), &Observation{IP: testIP, Path: "/api/v1/trade", Status: http.StatusOK, Signature: signature})
+	}
+
+	state, _ := detector.Client(KindIP, testIP)
+	if state.Signals[SignalReplay] != 3 {
+		t.Errorf("replay signals = %d, want 3", state.Signals[SignalReplay])
+	}
+	if Signature(http.MethodPost, "/api/v1/trade?x=2", []byte(`{"gold":100}`)) == signature {
+		t.Error("signature ignores the query string")
+	}
+}
+
+func TestDetectorRateAnomaly(t *testing.T) {
+	detector := newTestDetector(t)
+
+	for range 20 {
+		observe(detector, testIP, "", "/api/v1/zones", http.StatusOK)
+	}
+
+	// Une seule anomalie de débit par fenêtre
+	state, _ := detector.Client(KindIP, testIP)
+	if state.Signals[SignalRateAnomaly] != 1 || state.Requests != 20 {
+		t.Errorf("client state = %+v, want 1 rate anomaly over 20 requests", state)
+	}
+}
+
+// TestClientBaseline le débit habituel apprend des fenêtres écoulées, plafonnées à la limite
+func TestClientBaseline(t *testing.T) {
+	start := time.Now()
+	cl := newClient(start)
+
+	cl.requests = 100
+	cl.roll(start.Add(time.Minute), time.Minute, 5, 3)
+	if want := baselineSmoothing * 5; math.Abs(cl.baseline-want) > 1e-9 {
+		t.Errorf("baseline = %v, want %v (capped at the limit)", cl.baseline, want)
+	}
+	if cl.requests != 0 || !cl.windowStart.Equal(start.Add(time.Minute)) {
+		t.Errorf("window not rolled: requests=%d start=%v", cl.requests, cl.windowStart)
+	}
+
+	// Fenêtres sans requête : comptées pour zéro
+	before := cl.baseline
+	cl.roll(start.Add(4*time.Minute), time.Minute, 5, 3)
+	if want := before * math.Pow(1-baselineSmoothing, 3); math.Abs(cl.baseline-want) > 1e-9 {
+		t.Errorf("baseline after idle windows = %v, want %v", cl.baseline, want)
+	}
+}
+
+func TestClientDecay(t *testing.T) {
+	now := time.Now()
+	cl := newClient(now)
+	cl.score = 8
+
+	if got := cl.decayedScore(now.Add(2*time.Hour), time.Hour); math.Abs(got-2) > 1e-9 {
+		t.Errorf("score after two half-lives = %v, want 2", got)
+	}
+	if got := cl.decayedScore(now.Add(-time.Minute), time.Hour); got != 8 {
+		t.Errorf("score before last update = %v, want 8", got)
+	}
+}
+
+// TestDetectorBlockDuration la durée double à chaque récidive, jusqu'au maximum
+func TestDetectorBlockDuration(t *testing.T) {
+	detector := newTestDetector(t)
+	now := time.Now()
+	cl := newClient(now)
+
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		detector.mu.Lock()
+		block := detector.block(KindIP, testIP, cl, now)
+		detector.mu.Unlock()
+		if got := block.ExpiresAt.Sub(block.CreatedAt); got != want {
+			t.Errorf("strike %d: duration = %s, want %s", block.Strike, got, want)
+		}
+	}
+}
+
+func TestDetectorUnblock(t *testing.T) {
+	detector := newTestDetector(t)
+
+	if err := detector.Unblock(context.Background(), KindIP, testIP); !errors.Is(err, ErrBlockNotFound) {
+		t.Fatalf("Unblock without block error = %v, want ErrBlockNotFound", err)
+	}
+
+	observe(detector, testIP, "", "/api/v1/login", http.StatusUnauthorized)
+	observe(detector, testIP, "", "/api/v1/login", http.StatusUnauthorized)
+	if len(detector.Blocks()) != 1 {
+		t.Fatalf("Blocks = %d, want 1", len(detector.Blocks()))
+	}
+
+	if err := detector.Unblock(context.Background(), KindIP, testIP); err != nil {
+		t.Fatalf("Unblock: %v", err)
+	}
+	if verdict := detector.Check(testIP, ""); verdict != nil {
+		t.Errorf("verdict after unblock = %+v, want nil", verdict)
+	}
+	if len(detector.Blocks()) != 0 {
+		t.Errorf("Blocks after unblock = %d, want 0", len(detector.Blocks()))
+	}
+}
+
+// TestDetectorCleanup les blocages expirés et les clients inactifs sont oubliés
+// Un client bloqué est gardé tant qu'une récidive allongerait son prochain blocage.
+func TestDetectorCleanup(t *testing.T) {
+	detector := newTestDetector(t)
+
+	observe(detector, testIP, "", "/api/v1/login", http.StatusUnauthorized)
+	observe(detector, testIP, "", "/api/v1/login", http.StatusUnauthorized)
+	observe(detector, "192.0.2.1", "", "/api/v1/zones", http.StatusOK)
+
+	detector.cleanup(time.Now().Add(2 * time.Minute))
+	if _, tracked := detector.Client(KindIP, "192.0.2.1"); tracked {
+		t.Error("idle client kept")
+	}
+	detector.mu.Lock()
+	_, blocked := detector.blocks[clientKey(KindIP, testIP)]
+	_, tracked := detector.clients[clientKey(KindIP, testIP)]
+	detector.mu.Unlock()
+	if blocked {
+		t.Error("expired block kept")
+	}
+	if !tracked {
+		t.Error("recently blocked client forgotten")
+	}
+
+	detector.cleanup(time.Now().Add(5 * time.Minute))
+	if _, tracked := detector.Client(KindIP, testIP); tracked {
+		t.Error("client kept after its block expired")
+	}
+}
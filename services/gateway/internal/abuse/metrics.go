@@ -0,0 +1,53 @@
+package abuse
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Métriques Prometheus de la détection des abus
+var (
+	signalsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_abuse_signals_total",
+			Help: "Total number of abuse signals by type (rate_anomaly, auth_failure, enumeration, replay)",
+		},
+		[]string{"signal"},
+	)
+
+	blocksTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_abuse_blocks_total",
+			Help: "Total number of temporary blocks by client kind (ip, user) and origin (local, peer)",
+		},
+		[]string{"kind", "origin"},
+	)
+
+	rejectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_abuse_rejections_total",
+			Help: "Total number of requests rejected by abuse detection by reason (blocked, denied)",
+		},
+		[]string{"reason"},
+	)
+
+	activeBlocks = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gateway_abuse_active_blocks",
+			Help: "Number of temporary blocks in progress",
+		},
+	)
+
+	trackedClients = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gateway_abuse_tracked_clients",
+			Help: "Number of clients (IPs and users) tracked by abuse detection",
+		},
+	)
+)
+
+// InitMetrics initialize les métriques Prometheus de la détection des abus
+func InitMetrics() {
+	prometheus.MustRegister(signalsTotal)
+	prometheus.MustRegister(blocksTotal)
+	prometheus.MustRegister(rejectionsTotal)
+	prometheus.MustRegister(activeBlocks)
+	prometheus.MustRegister(trackedClients)
+}
@@ -0,0 +1,360 @@
+package aggregate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gateway/internal/balancer"
+	"gateway/internal/config"
+	"gateway/internal/maintenance"
+	"gateway/internal/proxy"
+	"gateway/internal/traffic"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Résultats d'une section (métriques)
+const (
+	resultOK      = "ok"
+	resultEmpty   = "empty"   // valeur de dépendance absente (ex. joueur sans guilde)
+	resultSkipped = "skipped" // personnage non demandé
+	resultError   = "error"
+)
+
+// errValueMissing une valeur de dépendance est nulle ou absente
+var errValueMissing = errors.New("value missing")
+
+// Request identité et paramètres d'une agrégation
+type Request struct {
+	UserID      string
+	CharacterID string      // vide : les sections du personnage sont ignorées
+	Header      http.Header // headers transmis aux services (authentification, identité)
+	Build       string      // version imposée par le client (vide : répartition des règles canary)
+	Staff       bool        // compte staff : les services en maintenance sont appelés quand même
+}
+
+// SectionError erreur d'une section dans le document
+type SectionError struct {
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error"`
+}
+
+// Result document agrégé
+type Result struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors map[string]SectionError    `json:"errors,omitempty"`
+}
+
+// section section compilée
+type section struct {
+	name         string
+	service      string
+	path         string
+	timeout      time.Duration
+	dependencies []string
+	character    bool // le chemin utilise {character}
+	referenced   bool // une autre section lit des valeurs de son résultat
+}
+
+// outcome résultat d'une section, attendu par les sections qui en dépendent
+type outcome struct {
+	done   chan struct{}
+	body   []byte
+	parsed interface{}
+	err    *SectionError
+	result string
+}
+
+// Aggregator appelle les services en parallèle et assemble leurs réponses
+type Aggregator struct {
+	config   *config.BootstrapConfig
+	sections []*section
+	balancer *balancer.Balancer
+	proxy    *proxy.ServiceProxy
+	traffic  *traffic.Splitter
+
+	maintenance *maintenance.Scheduler
+}
+
+// NewAggregator crée l'agrégateur des sections configurées
+func NewAggregator(
+	cfg *config.BootstrapConfig,
+	lb *balancer.Balancer,
+	serviceProxy *proxy.ServiceProxy,
+	splitter *traffic.Splitter,
+	scheduler *maintenance.Scheduler,
+) *Aggregator {
+	a := &Aggregator{
+		config:      cfg,
+		balancer:    lb,
+		proxy:       serviceProxy,
+		traffic:     splitter,
+		maintenance: scheduler,
+	}
+
+	for _, sectionCfg := range cfg.Sections {
+		s := &section{
+			name:    sectionCfg.Name,
+			service: sectionCfg.Service,
+			path:    sectionCfg.Path,
+			timeout: sectionCfg.Timeout,
+		}
+		if s.timeout <= 0 {
+			s.timeout = cfg.SectionTimeout
+		}
+		for _, placeholder := range config.PathPlaceholders(s.path) {
+			if dependency, _, isReference := strings.Cut(placeholder, "."); isReference {
+				s.dependencies = append(s.dependencies, dependency)
+			} else if placeholder == "character" {
+				s.character = true
+			}
+		}
+		a.sections = append(a.sections, s)
+	}
+
+	byName := make(map[string]*section, len(a.sections))
+	for _, s := range a.sections {
+		byName[s.name] = s
+		for _, dependency := range s.dependencies {
+			byName[dependency].referenced = true
+		}
+	}
+
+	return a
+}
+
+// Aggregate appelle toutes les sections et retourne le document, partiel si des
+// services ont échoué ; ok est faux si aucune section n'a pu être chargée
+func (a *Aggregator) Aggregate(ctx context.Context, req Request) (result *Result, ok bool) {
+	ctx, cancel := context.WithTimeout(ctx, a.config.Timeout)
+	defer cancel()
+
+	outcomes := make(map[string]*outcome, len(a.sections))
+	for _, s := range a.sections {
+		outcomes[s.name] = &outcome{done: make(chan struct{})}
+	}
+
+	var wg sync.WaitGroup
+	for _, s := range a.sections {
+		wg.Add(1)
+		go func(s *section) {
+			defer wg.Done()
+			defer close(outcomes[s.name].done)
+			a.run(ctx, s, req, outcomes)
+		}(s)
+	}
+	wg.Wait()
+
+	result = &Result{
+		Data:   make(map[string]json.RawMessage),
+		Errors: make(map[string]SectionError),
+	}
+	for _, s := range a.sections {
+		out := outcomes[s.name]
+		sectionResults.WithLabelValues(s.name, out.result).Inc()
+
+		switch out.result {
+		case resultOK:
+			result.Data[s.name] = out.body
+			ok = true
+		case resultEmpty:
+			result.Data[s.name] = json.RawMessage("null")
+		case resultError:
+			result.Errors[s.name] = *out.err
+		}
+	}
+
+	return result, ok
+}
+
+// run charge une section après ses dépendances
+func (a *Aggregator) run(ctx context.Context, s *section, req Request, outcomes map[string]*outcome) {
+	out := outcomes[s.name]
+
+	if s.character && req.CharacterID == "" {
+		out.result = resultSkipped
+		return
+	}
+
+	if !req.Staff && a.maintenance.Active(s.service, time.Now()) != nil {
+		out.fail(http.StatusServiceUnavailable, "service under maintenance")
+		return
+	}
+
+	for _, dependency := range s.dependencies {
+		select {
+		case <-outcomes[dependency].done:
+		case <-ctx.Done():
+			out.fail(0, fmt.Sprintf("timed out waiting for %s", dependency))
+			return
+		}
+		if outcomes[dependency].result != resultOK {
+			// Dépendance vide (ex. pas de guilde) : la section l'est aussi
+			if outcomes[dependency].result == resultEmpty || outcomes[dependency].result == resultSkipped {
+				out.result = outcomes[dependency].result
+				return
+			}
+			out.fail(0, fmt.Sprintf("%s unavailable", dependency))
+			return
+		}
+	}
+
+	path, err := resolvePath(s.path, req, outcomes)
+	if errors.Is(err, errValueMissing) {
+		out.result = resultEmpty
+		return
+	}
+	if err != nil {
+		out.fail(0, err.Error())
+		return
+	}
+
+	start := time.Now()
+	status, body, err := a.fetch(ctx, s, path, req)
+	sectionDuration.WithLabelValues(s.name).Observe(time.Since(start).Seconds())
+
+	switch {
+	case err != nil:
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"section": s.name,
+			"service": s.service,
+			"user_id": req.UserID,
+		}).Warn("Bootstrap section failed")
+		out.fail(status, failureMessage(s, err))
+	case status >= http.StatusBadRequest:
+		out.fail(status, fmt.Sprintf("service %s returned status %d", s.service, status))
+	case !json.Valid(body):
+		out.fail(status, fmt.Sprintf("service %s returned an invalid JSON document", s.service))
+	default:
+		out.body = body
+		out.result = resultOK
+		if s.referenced {
+			// Décodé avant de signaler la fin : les sections dépendantes le lisent en parallèle
+			_ = json.Unmarshal(body, &out.parsed)
+		}
+	}
+}
+
+// fetch appelle le service d'une section avec son délai
+func (a *Aggregator) fetch(ctx context.Context, s *section, path string, req Request) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	target, err := a.pick(s.service, req)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Pas de retry : le délai d'une section est trop court pour attendre un backoff
+	endpoint := target.Endpoint
+	endpoint.Retries = 0
+
+	start := time.Now()
+	status, body, err := a.proxy.Fetch(ctx, s.service, endpoint, path, req.Header, a.config.MaxResponseSize)
+	if errors.Is(err, proxy.ErrCircuitOpen) {
+		target.Release()
+		return 0, nil, err
+	}
+	target.Done(err)
+	a.traffic.Record(s.service, target.Version, status, err, time.Since(start))
+	return status, body, err
+}
+
+// pick choisit une instance du service dans la version attribuée à l'utilisateur
+// (toutes les versions sans instance disponible dans celle-ci, comme pour le proxy)
+func (a *Aggregator) pick(service string, req Request) (*balancer.Target, error) {
+	version := a.traffic.Version(service, req.UserID, req.Build)
+	if version != "" {
+		target, err := a.balancer.Pick(service, req.UserID, version)
+		if !errors.Is(err, balancer.ErrNoInstance) {
+			return target, err
+		}
+	}
+	return a.balancer.Pick(service, req.UserID, "")
+}
+
+// failureMessage décrit l'échec d'un appel au client, sans détail interne (adresses des instances)
+func failureMessage(s *section, err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("service %s did not respond within %s", s.service, s.timeout)
+	case errors.Is(err, balancer.ErrNoInstance):
+		return fmt.Sprintf("service %s not available", s.service)
+	case errors.Is(err, proxy.ErrCircuitOpen):
+		return fmt.Sprintf("service %s temporarily unavailable", s.service)
+	case errors.Is(err, proxy.ErrResponseTooLarge):
+		return fmt.Sprintf("service %s response is too large", s.service)
+	default:
+		return fmt.Sprintf("service %s request failed", s.service)
+	}
+}
+
+// fail enregistre l'erreur d'une section
+func (o *outcome) fail(status int, message string) {
+	o.err = &SectionError{Status: status, Error: message}
+	o.result = resultError
+}
+
+// resolvePath remplace les paramètres du chemin d'une section
+func resolvePath(path string, req Request, outcomes map[string]*outcome) (string, error) {
+	for _, placeholder := range config.PathPlaceholders(path) {
+		var value string
+		switch placeholder {
+		case "user_id":
+			value = req.UserID
+		case "character":
+			value = req.CharacterID
+		default:
+			dependency, field, _ := strings.Cut(placeholder, ".")
+			resolved, err := outcomes[dependency].lookup(field)
+			if err != nil {
+				return "", err
+			}
+			value = resolved
+		}
+		path = strings.Replace(path, "{"+placeholder+"}", url.PathEscape(value), 1)
+	}
+	return path, nil
+}
+
+// lookup lit un champ (chemin pointé, index pour les tableaux) dans le résultat d'une section
+func (o *outcome) lookup(field string) (string, error) {
+	current := o.parsed
+	for _, key := range strings.Split(field, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current = node[key]
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", errValueMissing
+			}
+			current = node[index]
+		default:
+			return "", errValueMissing
+		}
+	}
+
+	switch value := current.(type) {
+	case string:
+		if value == "" {
+			return "", errValueMissing
+		}
+		return value, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case nil:
+		return "", errValueMissing
+	default:
+		return "", fmt.Errorf("field %s is not a scalar value", field)
+	}
+}
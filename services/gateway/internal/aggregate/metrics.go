@@ -0,0 +1,29 @@
+package aggregate
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Métriques Prometheus des agrégations
+var (
+	sectionResults = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_bootstrap_sections_total",
+			Help: "Total number of bootstrap sections by result (ok, empty, skipped, error)",
+		},
+		[]string{"section", "result"},
+	)
+
+	sectionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "gateway_bootstrap_section_duration_seconds",
+			Help:    "Duration of the service call of a bootstrap section",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"section"},
+	)
+)
+
+// InitMetrics initialize les métriques Prometheus des agrégations
+func InitMetrics() {
+	prometheus.MustRegister(sectionResults)
+	prometheus.MustRegister(sectionDuration)
+}
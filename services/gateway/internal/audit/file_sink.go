@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// filePermissions droits des fichiers du journal
+const filePermissions = 0o600
+
+// FileSink écrit les enregistrements en JSON, un par ligne, avec rotation par taille
+// Le fichier courant est renommé en .1 (le .1 en .2, etc.) quand il dépasse maxSize ;
+// au-delà de maxBackups, les plus anciens sont supprimés.
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.RWMutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink ouvre (ou crée) le fichier du journal
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	sink := &FileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// Write ajoute l'enregistrement au fichier courant
+func (s *FileSink) Write(record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size > 0 && s.size+int64(len(data)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	written, err := s.file.Write(data)
+	s.size += int64(written)
+	if err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// Query recherche dans le fichier courant et les fichiers archivés, les plus récents d'abord
+func (s *FileSink) Query(filter *Filter) ([]*Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]*Record, 0, filter.Limit)
+	for index := 0; index <= s.maxBackups && len(records) < filter.Limit; index++ {
+		matches, err := s.scan(s.backupPath(index), filter)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, matches...)
+	}
+
+	if len(records) > filter.Limit {
+		records = records[:filter.Limit]
+	}
+	return records, nil
+}
+
+// Close ferme le fichier courant
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+// open ouvre le fichier courant en ajout
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, filePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat audit file: %w", err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate archive le fichier courant et en ouvre un nouveau
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit file: %w", err)
+	}
+
+	if s.maxBackups == 0 {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove audit file: %w", err)
+		}
+		return s.open()
+	}
+
+	if err := os.Remove(s.backupPath(s.maxBackups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove oldest audit file: %w", err)
+	}
+	for index := s.maxBackups - 1; index >= 0; index-- {
+		if err := os.Rename(s.backupPath(index), s.backupPath(index+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate audit file: %w", err)
+		}
+	}
+	return s.open()
+}
+
+// backupPath chemin d'un fichier (0 : fichier courant)
+func (s *FileSink) backupPath(index int) string {
+	if index == 0 {
+		return s.path
+	}
+	return s.path + "." + strconv.Itoa(index)
+}
+
+// scan retourne les enregistrements d'un fichier qui correspondent au filtre, les plus récents d'abord
+func (s *FileSink) scan(path string, filter *Filter) ([]*Record, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var records []*Record
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var record Record
+			// Une ligne illisible (arrêt brutal pendant l'écriture) est ignorée
+			if json.Unmarshal(line, &record) == nil && filter.Matches(&record) {
+				records = append(records, &record)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit file: %w", err)
+		}
+	}
+
+	// Les enregistrements sont ajoutés dans l'ordre d'arrivée
+	slices.Reverse(records)
+	return records, nil
+}
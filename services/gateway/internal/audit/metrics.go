@@ -0,0 +1,17 @@
+package audit
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Métriques Prometheus du journal d'audit
+var records = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gateway_audit_records_total",
+		Help: "Total number of audit records by result (written, failed, dropped)",
+	},
+	[]string{"result"},
+)
+
+// InitMetrics initialize les métriques Prometheus du journal d'audit
+func InitMetrics() {
+	prometheus.MustRegister(records)
+}
@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Record écriture passée par le gateway
+type Record struct {
+	ID          string          `json:"id"`
+	Time        time.Time       `json:"time"`
+	RequestID   string          `json:"request_id,omitempty"`
+	Method      string          `json:"method"`
+	Route       string          `json:"route"` // route Gin (ex. /api/v1/player/characters/:id), le chemin si aucune route
+	Path        string          `json:"path"`
+	Service     string          `json:"service,omitempty"`
+	Status      int             `json:"status"`
+	LatencyMs   float64         `json:"latency_ms"`
+	UserID      string          `json:"user_id,omitempty"`
+	Username    string          `json:"username,omitempty"`
+	Role        string          `json:"role,omitempty"`
+	CharacterID string          `json:"character_id,omitempty"`
+	ClientIP    string          `json:"client_ip"`
+	Request     json.RawMessage `json:"request,omitempty"`  // corps masqué (routes configurées)
+	Response    json.RawMessage `json:"response,omitempty"` // corps masqué (routes configurées)
+}
+
+// Filter critères de recherche des enregistrements (champs vides : pas de critère)
+type Filter struct {
+	UserID      string
+	CharacterID string
+	Method      string
+	Route       string
+	Status      int
+	From        time.Time
+	To          time.Time
+	Limit       int
+}
+
+// Matches indique si un enregistrement correspond aux critères
+func (f *Filter) Matches(record *Record) bool {
+	switch {
+	case f.UserID != "" && !strings.EqualFold(record.UserID, f.UserID):
+		return false
+	case f.CharacterID != "" && !strings.EqualFold(record.CharacterID, f.CharacterID):
+		return false
+	case f.Method != "" && !strings.EqualFold(record.Method, f.Method):
+		return false
+	case f.Route != "" && record.Route != f.Route:
+		return false
+	case f.Status != 0 && record.Status != f.Status:
+		return false
+	case !f.From.IsZero() && record.Time.Before(f.From):
+		return false
+	case !f.To.IsZero() && !record.Time.Before(f.To):
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,181 @@
+package audit
+
+import (
+	"gateway/internal/config"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Résultats d'écriture (label des métriques)
+const (
+	resultWritten = "written"
+	resultFailed  = "failed"
+	resultDropped = "dropped" // file d'attente pleine
+)
+
+// Recorder enregistre les écritures sans ralentir les réponses
+// Les enregistrements sont écrits par une goroutine ; si la destination ne suit pas et que
+// la file d'attente est pleine, ils sont perdus (métrique gateway_audit_records_total).
+type Recorder struct {
+	config     *config.AuditConfig
+	sink       Sink
+	redactor   *Redactor
+	methods    map[string]bool
+	bodyRoutes map[string]bool // "METHOD /route"
+
+	queue chan *Record
+	done  chan struct{}
+	wg    sync.WaitGroup
+	once  sync.Once
+
+	mu     sync.RWMutex
+	recent []*Record // tampon circulaire des derniers enregistrements
+	next   int
+}
+
+// NewRecorder crée le journal d'audit
+func NewRecorder(cfg *config.AuditConfig, sink Sink) *Recorder {
+	r := &Recorder{
+		config:     cfg,
+		sink:       sink,
+		redactor:   NewRedactor(cfg.RedactPaths),
+		methods:    make(map[string]bool, len(cfg.Methods)),
+		bodyRoutes: make(map[string]bool, len(cfg.BodyRoutes)),
+		queue:      make(chan *Record, cfg.QueueSize),
+		done:       make(chan struct{}),
+		recent:     make([]*Record, 0, cfg.RecentRecords),
+	}
+
+	for _, method := range cfg.Methods {
+		r.methods[strings.ToUpper(strings.TrimSpace(method))] = true
+	}
+	for _, route := range cfg.BodyRoutes {
+		if fields := strings.Fields(route); len(fields) == 2 {
+			r.bodyRoutes[strings.ToUpper(fields[0])+" "+fields[1]] = true
+		}
+	}
+	return r
+}
+
+// Start lance l'écriture des enregistrements
+func (r *Recorder) Start() {
+	r.wg.Add(1)
+	go r.writeLoop()
+}
+
+// Close écrit les enregistrements en attente et ferme la destination
+func (r *Recorder) Close() {
+	r.once.Do(func() {
+		close(r.done)
+		r.wg.Wait()
+
+		if err := r.sink.Close(); err != nil {
+			logrus.WithError(err).Warn("Failed to close audit sink")
+		}
+	})
+}
+
+// Audited indique si une requête est enregistrée
+func (r *Recorder) Audited(method string) bool {
+	return r.methods[method]
+}
+
+// KeepsBodies indique si les corps d'une route sont gardés
+func (r *Recorder) KeepsBodies(method, route string) bool {
+	return r.config.MaxBodySize > 0 && r.bodyRoutes[method+" "+route]
+}
+
+// MaxBodySize taille maximale d'un corps gardé
+func (r *Recorder) MaxBodySize() int64 {
+	return r.config.MaxBodySize
+}
+
+// Redact masque les chemins configurés d'un corps JSON (nil si le corps n'est pas du JSON)
+func (r *Recorder) Redact(body []byte) []byte {
+	redacted, ok := r.redactor.Redact(body)
+	if !ok {
+		return nil
+	}
+	return redacted
+}
+
+// Record met un enregistrement en file d'attente
+func (r *Recorder) Record(record *Record) {
+	select {
+	case <-r.done:
+		records.WithLabelValues(resultDropped).Inc()
+	case r.queue <- record:
+	default:
+		records.WithLabelValues(resultDropped).Inc()
+		logrus.WithField("route", record.Route).Warn("Audit queue full, record dropped")
+	}
+}
+
+// Query recherche les enregistrements, les plus récents d'abord
+// Le sink file cherche dans tout le journal ; les autres sinks ne permettent de chercher que
+// dans les derniers enregistrements du réplica.
+func (r *Recorder) Query(filter *Filter) ([]*Record, error) {
+	if querier, ok := r.sink.(Querier); ok {
+		return querier.Query(filter)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := make([]*Record, 0, filter.Limit)
+	for offset := 1; offset <= len(r.recent) && len(matches) < filter.Limit; offset++ {
+		record := r.recent[(r.next-offset+len(r.recent))%len(r.recent)]
+		if filter.Matches(record) {
+			matches = append(matches, record)
+		}
+	}
+	return matches, nil
+}
+
+// writeLoop écrit les enregistrements jusqu'à la fermeture, puis vide la file d'attente
+func (r *Recorder) writeLoop() {
+	defer r.wg.Done()
+
+	for {
+		select {
+		case record := <-r.queue:
+			r.write(record)
+		case <-r.done:
+			for {
+				select {
+				case record := <-r.queue:
+					r.write(record)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// write écrit un enregistrement et le garde parmi les derniers
+func (r *Recorder) write(record *Record) {
+	if err := r.sink.Write(record); err != nil {
+		records.WithLabelValues(resultFailed).Inc()
+		logrus.WithError(err).WithField("route", record.Route).Warn("Failed to write audit record")
+	} else {
+		records.WithLabelValues(resultWritten).Inc()
+	}
+
+	if r.config.RecentRecords == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.recent) < r.config.RecentRecords {
+		r.recent = append(r.recent, record)
+		r.next = len(r.recent) % r.config.RecentRecords
+		return
+	}
+	r.recent[r.next] = record
+	r.next = (r.next + 1) % r.config.RecentRecords
+}
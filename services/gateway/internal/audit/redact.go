@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Masquage des valeurs sensibles
+const (
+	redactedValue = "[REDACTED]"
+	anyKey        = "*"  // une clé ou un indice quelconque
+	anyDepth      = "**" // zéro ou plusieurs niveaux
+)
+
+// Redactor masque des chemins JSON dans les corps gardés
+// Les clés sont comparées sans tenir compte de la casse.
+type Redactor struct {
+	paths [][]string
+}
+
+// NewRedactor compile les chemins à masquer ("**.password", "user.email", "items.*.token")
+func NewRedactor(paths []string) *Redactor {
+	redactor := &Redactor{}
+	for _, path := range paths {
+		if path = strings.TrimSpace(path); path != "" {
+			redactor.paths = append(redactor.paths, strings.Split(path, "."))
+		}
+	}
+	return redactor
+}
+
+// Redact retourne le corps JSON avec les chemins masqués
+// Un corps qui n'est pas du JSON n'est pas gardé : ok vaut false.
+func (r *Redactor) Redact(body []byte) (json.RawMessage, bool) {
+	if len(body) == 0 {
+		return nil, false
+	}
+
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, false
+	}
+	for _, path := range r.paths {
+		document = redact(document, path)
+	}
+
+	redacted, err := json.Marshal(document)
+	if err != nil {
+		return nil, false
+	}
+	return redacted, true
+}
+
+// redact masque les valeurs d'un nœud qui correspondent au chemin
+func redact(node any, path []string) any {
+	if len(path) == 0 {
+		return redactedValue
+	}
+
+	segment, rest := path[0], path[1:]
+	if segment == anyDepth {
+		// Zéro niveau, puis le même chemin sous chaque enfant
+		node = redact(node, rest)
+		return redactChildren(node, func(child any) any { return redact(child, path) })
+	}
+
+	switch value := node.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if segment == anyKey || strings.EqualFold(key, segment) {
+				value[key] = redact(child, rest)
+			}
+		}
+	case []any:
+		for index, child := range value {
+			if segment == anyKey || segment == strconv.Itoa(index) {
+				value[index] = redact(child, rest)
+			}
+		}
+	}
+	return node
+}
+
+// redactChildren applique fn à chaque enfant d'un objet ou d'un tableau
+func redactChildren(node any, fn func(any) any) any {
+	switch value := node.(type) {
+	case map[string]any:
+		for key, child := range value {
+			value[key] = fn(child)
+		}
+	case []any:
+		for index, child := range value {
+			value[index] = fn(child)
+		}
+	}
+	return node
+}
@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gateway/internal/config"
+	"gateway/internal/tracing"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+)
+
+// analyticsEventsPath route du service analytics qui enregistre les événements
+const analyticsEventsPath = "/api/v1/events/"
+
+// analyticsEventType type des événements d'audit dans l'analytics
+const analyticsEventType = "gateway_audit"
+
+// ErrNATSUnavailable le sink nats est configuré sans connexion NATS
+var ErrNATSUnavailable = errors.New("audit nats sink requires a NATS connection")
+
+// Sink destination des enregistrements d'audit
+type Sink interface {
+	Write(record *Record) error
+	Close() error
+}
+
+// Querier sink capable de rechercher dans les enregistrements écrits
+type Querier interface {
+	Query(filter *Filter) ([]*Record, error)
+}
+
+// NewSink crée la destination configurée
+func NewSink(cfg *config.AuditConfig, natsConn *nats.Conn, analyticsURL string) (Sink, error) {
+	switch cfg.Sink {
+	case config.AuditSinkNATS:
+		if natsConn == nil {
+			return nil, ErrNATSUnavailable
+		}
+		return &NATSSink{natsConn: natsConn, subject: cfg.Subject}, nil
+	case config.AuditSinkAnalytics:
+		return NewAnalyticsSink(analyticsURL, cfg.AnalyticsTimeout), nil
+	default:
+		return NewFileSink(cfg.File, cfg.MaxFileSize, cfg.MaxBackups)
+	}
+}
+
+// NATSSink publie chaque enregistrement sur un sujet NATS
+type NATSSink struct {
+	natsConn *nats.Conn
+	subject  string
+}
+
+// Write publie l'enregistrement
+func (s *NATSSink) Write(record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	return tracing.Publish(context.Background(), s.natsConn, s.subject, data)
+}
+
+// Close implémente Sink (la connexion NATS appartient au gateway)
+func (s *NATSSink) Close() error {
+	return nil
+}
+
+// analyticsEvent événement du service analytics (models.CreateEventRequest)
+type analyticsEvent struct {
+	Type      string    `json:"type"`
+	PlayerID  *string   `json:"player_id,omitempty"`
+	Payload   string    `json:"payload"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AnalyticsSink envoie chaque enregistrement au service analytics comme événement
+type AnalyticsSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewAnalyticsSink crée le client du service analytics
+func NewAnalyticsSink(analyticsURL string, timeout time.Duration) *AnalyticsSink {
+	return &AnalyticsSink{
+		url:    strings.TrimRight(analyticsURL, "/") + analyticsEventsPath,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Write enregistre l'événement dans l'analytics
+func (s *AnalyticsSink) Write(record *Record) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	event := analyticsEvent{Type: analyticsEventType, Payload: string(payload), Timestamp: record.Time}
+	if _, err := uuid.Parse(record.UserID); err == nil {
+		event.PlayerID = &record.UserID
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode analytics event: %w", err)
+	}
+
+	ctx := context.Background()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create analytics request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, span := tracing.StartClientSpan(ctx, "analytics", req)
+	resp, err := s.client.Do(req.WithContext(ctx))
+	tracing.EndClientSpan(span, resp, err)
+	if err != nil {
+		return fmt.Errorf("analytics request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("analytics service returned status %d for audit event", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close implémente Sink
+func (s *AnalyticsSink) Close() error {
+	return nil
+}
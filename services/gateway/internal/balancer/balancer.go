@@ -0,0 +1,573 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gateway/internal/config"
+	"gateway/internal/registry"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Statuts des instances
+const (
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+	StatusUnknown   = "unknown" // pas encore vérifiée, reçoit du trafic
+	StatusEjected   = "ejected"
+	StatusOpen      = "circuit_open" // circuit breaker du proxy ouvert
+	StatusDraining  = "draining"     // retirée de la répartition, requêtes en cours
+	StatusDrained   = "drained"      // retirée de la répartition, plus aucune requête
+
+	// Statuts agrégés des services
+	ServiceUp       = "up"
+	ServiceDegraded = "degraded"
+	ServiceDown     = "down"
+)
+
+// ErrNoInstance aucune instance disponible pour le service
+var ErrNoInstance = errors.New("no available instance")
+
+// instanceState état de santé et de charge d'une instance
+type instanceState struct {
+	service string
+	id      string
+	url     string
+	version string
+
+	active   int64 // requêtes en cours (accès atomique)
+	draining bool  // retirée de la répartition (maintenance)
+
+	// Health checks actifs
+	healthy   bool
+	checked   bool
+	successes int
+	failures  int
+	lastCheck time.Time
+	lastError string
+
+	// Health checks passifs et éjection
+	passiveFailures int
+	ejections       int
+	ejectedUntil    time.Time
+}
+
+// available indique si l'instance peut recevoir du trafic
+func (s *instanceState) available(now time.Time) bool {
+	return s.healthy && !s.draining && !now.Before(s.ejectedUntil)
+}
+
+// InstanceFilter indique si une instance peut recevoir du trafic (circuit breaker du proxy)
+type InstanceFilter func(service, instanceURL string) bool
+
+// Target instance choisie pour une requête
+// Done doit être appelé une fois la requête terminée.
+type Target struct {
+	Service    string
+	InstanceID string
+	Version    string
+	Endpoint   config.ServiceEndpoint
+
+	balancer *Balancer
+	state    *instanceState
+	once     sync.Once
+}
+
+// Done libère la connexion et enregistre le résultat (health check passif)
+func (t *Target) Done(err error) {
+	t.once.Do(func() {
+		atomic.AddInt64(&t.state.active, -1)
+		t.balancer.recordResult(t.state, err)
+	})
+}
+
+// Release libère la connexion sans enregistrer de résultat (requête jamais envoyée)
+func (t *Target) Release() {
+	t.once.Do(func() {
+		atomic.AddInt64(&t.state.active, -1)
+	})
+}
+
+// Balancer répartit les requêtes entre les instances du registre
+type Balancer struct {
+	config   *config.LoadBalancingConfig
+	registry *registry.Registry
+	filter   InstanceFilter
+	client   *http.Client
+
+	mu       sync.RWMutex
+	states   map[string]*instanceState
+	counters map[string]*uint64
+	rings    map[string]*hashRing
+
+	stop   chan struct{}
+	closed sync.Once
+}
+
+// NewBalancer crée le répartiteur de charge
+// filter peut être nil ; sinon les instances qu'il refuse sont écartées.
+func NewBalancer(cfg *config.Config, serviceRegistry *registry.Registry, filter InstanceFilter) *Balancer {
+	if filter == nil {
+		filter = func(string, string) bool { return true }
+	}
+
+	return &Balancer{
+		config:   &cfg.LoadBalancing,
+		registry: serviceRegistry,
+		filter:   filter,
+		client:   &http.Client{Timeout: cfg.LoadBalancing.HealthCheckTimeout},
+		states:   make(map[string]*instanceState),
+		counters: make(map[string]*uint64),
+		rings:    make(map[string]*hashRing),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start démarre les health checks actifs
+func (b *Balancer) Start() {
+	go func() {
+		ticker := time.NewTicker(b.config.HealthCheckInterval)
+		defer ticker.Stop()
+
+		b.checkAll()
+		for {
+			select {
+			case <-b.stop:
+				return
+			case <-ticker.C:
+				b.checkAll()
+			}
+		}
+	}()
+}
+
+// Close arrête les health checks
+func (b *Balancer) Close() {
+	b.closed.Do(func() { close(b.stop) })
+}
+
+// Pick choisit une instance disponible du service
+// key sert au hachage cohérent (ID utilisateur) ; vide, la stratégie tourniquet est utilisée.
+// version restreint le choix aux instances de cette version ; vide, toutes les versions.
+func (b *Balancer) Pick(serviceName, key, version string) (*Target, error) {
+	service, exists := b.registry.Get(serviceName)
+	if !exists {
+		return nil, fmt.Errorf("service %s: %w", serviceName, ErrNoInstance)
+	}
+
+	now := time.Now()
+	states := b.statesFor(service)
+
+	b.mu.RLock()
+	candidates := make([]*instanceState, 0, len(states))
+	for _, state := range states {
+		if state.available(now) && (version == "" || state.version == version) {
+			candidates = append(candidates, state)
+		}
+	}
+	b.mu.RUnlock()
+
+	// Écarter les instances dont le circuit est ouvert (hors verrou)
+	eligible := candidates[:0]
+	for _, candidate := range candidates {
+		if b.filter(serviceName, candidate.url) {
+			eligible = append(eligible, candidate)
+		}
+	}
+	candidates = eligible
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("service %s: %w", serviceName, ErrNoInstance)
+	}
+
+	var chosen *instanceState
+	switch b.strategyFor(service) {
+	case config.StrategyLeastConnections:
+		chosen = b.pickLeastConnections(serviceName, candidates)
+	case config.StrategyConsistentHash:
+		if key != "" {
+			chosen = b.pickConsistentHash(serviceName, states, candidates, key)
+		}
+	}
+	if chosen == nil {
+		chosen = candidates[b.nextIndex(serviceName, len(candidates))]
+	}
+
+	atomic.AddInt64(&chosen.active, 1)
+	return &Target{
+		Service:    serviceName,
+		InstanceID: chosen.id,
+		Version:    chosen.version,
+		Endpoint: config.ServiceEndpoint{
+			URL:     chosen.url,
+			Timeout: service.Timeout,
+			Retries: service.Retries,
+		},
+		balancer: b,
+		state:    chosen,
+	}, nil
+}
+
+// Drain retire une instance de la répartition sans interrompre ses requêtes en cours
+// Retourne le nombre de requêtes encore en cours vers l'instance.
+func (b *Balancer) Drain(serviceName, id string) (int64, error) {
+	return b.setDraining(serviceName, id, true)
+}
+
+// Resume remet une instance drainée dans la répartition
+func (b *Balancer) Resume(serviceName, id string) error {
+	_, err := b.setDraining(serviceName, id, false)
+	return err
+}
+
+// WaitDrained attend que les requêtes en cours vers une instance soient terminées
+// Retourne le nombre de requêtes encore en cours quand ctx expire (0 : instance drainée).
+func (b *Balancer) WaitDrained(ctx context.Context, serviceName, id string, pollInterval time.Duration) (int64, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		state, err := b.instanceState(serviceName, id)
+		if err != nil {
+			return 0, err
+		}
+		active := atomic.LoadInt64(&state.active)
+		if active == 0 {
+			return 0, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return active, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// InFlight retourne le nombre de requêtes en cours vers les instances d'un service
+// (tous les services si serviceName est vide)
+func (b *Balancer) InFlight(serviceName string) int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var total int64
+	for _, state := range b.states {
+		if serviceName == "" || state.service == serviceName {
+			total += atomic.LoadInt64(&state.active)
+		}
+	}
+	return total
+}
+
+// Méthodes privées
+
+// instanceState retourne l'état d'une instance du registre
+func (b *Balancer) instanceState(serviceName, id string) (*instanceState, error) {
+	service, exists := b.registry.Get(serviceName)
+	if !exists {
+		return nil, registry.ErrServiceNotFound
+	}
+
+	for i, state := range b.statesFor(service) {
+		if service.Instances[i].ID == id {
+			return state, nil
+		}
+	}
+	return nil, registry.ErrInstanceNotFound
+}
+
+// setDraining retire ou remet une instance dans la répartition
+func (b *Balancer) setDraining(serviceName, id string, draining bool) (int64, error) {
+	state, err := b.instanceState(serviceName, id)
+	if err != nil {
+		return 0, err
+	}
+
+	b.mu.Lock()
+	changed := state.draining != draining
+	state.draining = draining
+	b.mu.Unlock()
+
+	active := atomic.LoadInt64(&state.active)
+	if changed {
+		fields := logrus.Fields{
+			"service":  serviceName,
+			"instance": id,
+			"url":      state.url,
+		}
+		if draining {
+			logrus.WithFields(fields).WithField("in_flight", active).Info("Instance draining")
+		} else {
+			logrus.WithFields(fields).Info("Instance resumed after drain")
+		}
+	}
+	return active, nil
+}
+
+// strategyFor retourne la stratégie effective d'un service (registre puis configuration)
+func (b *Balancer) strategyFor(service *registry.Service) string {
+	if service.Strategy != "" {
+		return service.Strategy
+	}
+	return b.config.StrategyFor(service.Name)
+}
+
+// statesFor retourne l'état de chaque instance du service, dans l'ordre du registre
+// Une instance déplacée (nouvelle URL) repart d'un état neuf.
+func (b *Balancer) statesFor(service *registry.Service) []*instanceState {
+	states := make([]*instanceState, len(service.Instances))
+
+	b.mu.RLock()
+	missing := false
+	for i, instance := range service.Instances {
+		states[i] = b.states[stateKey(service.Name, instance)]
+		missing = missing || states[i] == nil
+	}
+	b.mu.RUnlock()
+
+	if !missing {
+		return states
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, instance := range service.Instances {
+		key := stateKey(service.Name, instance)
+		state, exists := b.states[key]
+		if !exists {
+			// Optimiste : une nouvelle instance reçoit du trafic avant son premier health check
+			state = &instanceState{
+				service: service.Name,
+				id:      instance.ID,
+				url:     instance.URL,
+				version: instance.Version,
+				healthy: true,
+			}
+			b.states[key] = state
+		}
+		states[i] = state
+	}
+	return states
+}
+
+// nextIndex retourne l'index suivant du tourniquet d'un service
+func (b *Balancer) nextIndex(serviceName string, n int) int {
+	b.mu.RLock()
+	counter, exists := b.counters[serviceName]
+	b.mu.RUnlock()
+
+	if !exists {
+		b.mu.Lock()
+		if counter, exists = b.counters[serviceName]; !exists {
+			counter = new(uint64)
+			b.counters[serviceName] = counter
+		}
+		b.mu.Unlock()
+	}
+
+	return int(atomic.AddUint64(counter, 1) % uint64(n))
+}
+
+// pickLeastConnections choisit l'instance avec le moins de requêtes en cours
+// Le point de départ tourne pour répartir les égalités.
+func (b *Balancer) pickLeastConnections(serviceName string, candidates []*instanceState) *instanceState {
+	start := b.nextIndex(serviceName, len(candidates))
+
+	var chosen *instanceState
+	var minActive int64
+	for i := range candidates {
+		candidate := candidates[(start+i)%len(candidates)]
+		active := atomic.LoadInt64(&candidate.active)
+		if chosen == nil || active < minActive {
+			chosen, minActive = candidate, active
+		}
+	}
+	return chosen
+}
+
+// pickConsistentHash choisit l'instance associée à la clé sur l'anneau du service
+// L'anneau contient toutes les instances : quand une instance est indisponible, seules
+// ses clés sont redistribuées et elles lui reviennent à son rétablissement.
+func (b *Balancer) pickConsistentHash(serviceName string, states, candidates []*instanceState, key string) *instanceState {
+	available := make(map[*instanceState]bool, len(candidates))
+	for _, candidate := range candidates {
+		available[candidate] = true
+	}
+
+	ring := b.ringFor(serviceName, states)
+	return ring.lookup(key, func(state *instanceState) bool { return available[state] })
+}
+
+// ringFor retourne l'anneau du service, reconstruit si ses instances ont changé
+func (b *Balancer) ringFor(serviceName string, states []*instanceState) *hashRing {
+	b.mu.RLock()
+	ring, exists := b.rings[serviceName]
+	b.mu.RUnlock()
+
+	if exists && ring.matches(states) {
+		return ring
+	}
+
+	ring = newHashRing(states, b.config.HashVirtualNodes)
+	b.mu.Lock()
+	b.rings[serviceName] = ring
+	b.mu.Unlock()
+	return ring
+}
+
+// recordResult applique le résultat d'une requête proxiée (health check passif)
+// Après ConsecutiveFailures échecs, l'instance est éjectée pour une durée croissante,
+// sans jamais éjecter plus de MaxEjectionPercent des instances du service.
+func (b *Balancer) recordResult(state *instanceState, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		state.passiveFailures = 0
+		return
+	}
+
+	state.passiveFailures++
+	if state.passiveFailures < b.config.ConsecutiveFailures {
+		return
+	}
+
+	now := time.Now()
+	if now.Before(state.ejectedUntil) {
+		return
+	}
+
+	total, ejected := 0, 0
+	for _, other := range b.states {
+		if other.service != state.service {
+			continue
+		}
+		total++
+		if now.Before(other.ejectedUntil) {
+			ejected++
+		}
+	}
+	if (ejected+1)*config.MaxPercent > total*b.config.MaxEjectionPercent {
+		logrus.WithFields(logrus.Fields{
+			"service":  state.service,
+			"instance": state.id,
+			"ejected":  ejected,
+			"total":    total,
+		}).Warn("Outlier detected but max ejection percent reached")
+		return
+	}
+
+	state.ejections++
+	duration := b.config.BaseEjectionTime * time.Duration(state.ejections)
+	if duration > b.config.MaxEjectionTime {
+		duration = b.config.MaxEjectionTime
+	}
+	state.ejectedUntil = now.Add(duration)
+	state.passiveFailures = 0
+
+	logrus.WithFields(logrus.Fields{
+		"service":   state.service,
+		"instance":  state.id,
+		"url":       state.url,
+		"ejections": state.ejections,
+		"duration":  duration.String(),
+		"error":     err.Error(),
+	}).Warn("Instance ejected after consecutive failures")
+}
+
+// checkAll exécute les health checks actifs et oublie les instances retirées du registre
+func (b *Balancer) checkAll() {
+	services := b.registry.Services()
+
+	known := make(map[string]bool)
+	var wg sync.WaitGroup
+	for _, service := range services {
+		for i, state := range b.statesFor(service) {
+			known[stateKey(service.Name, service.Instances[i])] = true
+			wg.Add(1)
+			go func(state *instanceState) {
+				defer wg.Done()
+				b.checkInstance(state)
+			}(state)
+		}
+	}
+	wg.Wait()
+
+	b.mu.Lock()
+	for key := range b.states {
+		if !known[key] {
+			delete(b.states, key)
+		}
+	}
+	b.mu.Unlock()
+}
+
+// checkInstance interroge l'endpoint de santé d'une instance
+func (b *Balancer) checkInstance(state *instanceState) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.config.HealthCheckTimeout)
+	defer cancel()
+
+	err := func() error {
+		req, err := http.NewRequestWithContext(ctx, "GET", state.url+b.config.HealthCheckPath, http.NoBody)
+		if err != nil {
+			return err
+		}
+		resp, err := b.client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("health check returned status %d", resp.StatusCode)
+		}
+		return nil
+	}()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state.checked = true
+	state.lastCheck = time.Now()
+
+	if err != nil {
+		state.lastError = err.Error()
+		state.successes = 0
+		state.failures++
+		if state.healthy && state.failures >= b.config.UnhealthyThreshold {
+			state.healthy = false
+			logrus.WithFields(logrus.Fields{
+				"service":  state.service,
+				"instance": state.id,
+				"url":      state.url,
+				"error":    err.Error(),
+			}).Warn("Instance marked unhealthy")
+		}
+		return
+	}
+
+	state.lastError = ""
+	state.failures = 0
+	state.successes++
+	if !state.healthy && state.successes >= b.config.HealthyThreshold {
+		state.healthy = true
+		logrus.WithFields(logrus.Fields{
+			"service":  state.service,
+			"instance": state.id,
+			"url":      state.url,
+		}).Info("Instance marked healthy")
+	}
+	// Une instance stable (hors éjection) retrouve une durée d'éjection initiale
+	if state.successes >= b.config.HealthyThreshold && !time.Now().Before(state.ejectedUntil) {
+		state.ejections = 0
+	}
+}
+
+// stateKey identifie l'état d'une instance (service, ID, URL et version)
+func stateKey(serviceName string, instance *registry.Instance) string {
+	return serviceName + "|" + instance.ID + "|" + instance.URL + "|" + instance.Version
+}
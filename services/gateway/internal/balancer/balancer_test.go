@@ -0,0 +1,274 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"gateway/internal/config"
+	"gateway/internal/registry"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testService = "auction"
+
+// newTestBalancer répartiteur sur les instances du service auction, enregistrées via l'API
+// versions : instance ID -> version
+func newTestBalancer(t *testing.T, strategy string, versions map[string]string, filter InstanceFilter) *Balancer {
+	t.Helper()
+
+	cfg := &config.Config{
+		Registry: config.RegistryConfig{HeartbeatTTL: time.Minute, CleanupInterval: time.Minute},
+		LoadBalancing: config.LoadBalancingConfig{
+			Strategy:            strategy,
+			HashVirtualNodes:    50,
+			HealthCheckPath:     "/health",
+			HealthCheckTimeout:  time.Second,
+			HealthyThreshold:    2,
+			UnhealthyThreshold:  2,
+			ConsecutiveFailures: 2,
+			BaseEjectionTime:    time.Minute,
+			MaxEjectionTime:     5 * time.Minute,
+			MaxEjectionPercent:  50,
+		},
+	}
+	serviceRegistry, err := registry.NewRegistry(cfg)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	t.Cleanup(serviceRegistry.Close)
+
+	for id, version := range versions {
+		reg := &registry.Registration{Service: testService, ID: id, URL: "http://" + id + ":8090", Version: version}
+		if _, err := serviceRegistry.Register(reg); err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+	}
+
+	b := NewBalancer(cfg, serviceRegistry, filter)
+	t.Cleanup(b.Close)
+	return b
+}
+
+// threeInstances trois instances stables
+func threeInstances() map[string]string {
+	return map[string]string{"a": "", "b": "", "c": ""}
+}
+
+// pick choisit une instance et libère aussitôt la connexion
+func pick(t *testing.T, b *Balancer, key, version string) string {
+	t.Helper()
+
+	target, err := b.Pick(testService, key, version)
+	if err != nil {
+		t.Fatalf("Pick: %v", err)
+	}
+	target.Done(nil)
+	return target.InstanceID
+}
+
+func TestRoundRobin(t *testing.T) {
+	b := newTestBalancer(t, config.StrategyRoundRobin, threeInstances(), nil)
+
+	counts := make(map[string]int)
+	for range 30 {
+		counts[pick(t, b, "user-1", "")]++
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if counts[id] != 10 {
+			t.Errorf("instance %s picked %d times, want 10: %v", id, counts[id], counts)
+		}
+	}
+}
+
+func TestLeastConnections(t *testing.T) {
+	b := newTestBalancer(t, config.StrategyLeastConnections, threeInstances(), nil)
+
+	// Deux requêtes en cours : la troisième va à l'instance libre
+	first, _ := b.Pick(testService, "", "")
+	second, _ := b.Pick(testService, "", "")
+	if first.InstanceID == second.InstanceID {
+		t.Fatalf("both requests sent to %s", first.InstanceID)
+	}
+
+	third, err := b.Pick(testService, "", "")
+	if err != nil {
+		t.Fatalf("Pick: %v", err)
+	}
+	if third.InstanceID == first.InstanceID || third.InstanceID == second.InstanceID {
+		t.Errorf("third request sent to busy instance %s", third.InstanceID)
+	}
+	if inFlight := b.InFlight(testService); inFlight != 3 {
+		t.Errorf("in flight = %d, want 3", inFlight)
+	}
+
+	for _, target := range []*Target{first, second, third} {
+		target.Done(nil)
+		target.Done(nil) // sans effet
+	}
+	if inFlight := b.InFlight(""); inFlight != 0 {
+		t.Errorf("in flight after Done = %d, want 0", inFlight)
+	}
+}
+
+// TestConsistentHash une clé reste sur la même instance et y revient après son retour
+func TestConsistentHash(t *testing.T) {
+	b := newTestBalancer(t, config.StrategyConsistentHash, threeInstances(), nil)
+
+	owner := pick(t, b, "user-42", "")
+	for range 5 {
+		if id := pick(t, b, "user-42", ""); id != owner {
+			t.Fatalf("user-42 moved from %s to %s", owner, id)
+		}
+	}
+
+	if _, err := b.Drain(testService, owner); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+	if id := pick(t, b, "user-42", ""); id == owner {
+		t.Fatalf("user-42 still sent to drained instance %s", owner)
+	}
+
+	if err := b.Resume(testService, owner); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if id := pick(t, b, "user-42", ""); id != owner {
+		t.Errorf("user-42 sent to %s after resume, want %s", id, owner)
+	}
+}
+
+func TestPickVersionAndFilter(t *testing.T) {
+	versions := map[string]string{"a": "", "b": "", "canary": "v2"}
+	b := newTestBalancer(t, config.StrategyRoundRobin, versions, func(_, instanceURL string) bool {
+		return instanceURL != "http://a:8090"
+	})
+
+	for range 5 {
+		if id := pick(t, b, "", "v2"); id != "canary" {
+			t.Fatalf("v2 request sent to %s", id)
+		}
+		if id := pick(t, b, "", registry.DefaultVersion); id != "b" {
+			t.Fatalf("stable request sent to %s, want b (a is filtered out)", id)
+		}
+	}
+
+	if _, err := b.Pick(testService, "", "v3"); !errors.Is(err, ErrNoInstance) {
+		t.Errorf("Pick(v3) = %v, want ErrNoInstance", err)
+	}
+	if _, err := b.Pick("unknown", "", ""); !errors.Is(err, ErrNoInstance) {
+		t.Errorf("Pick(unknown) = %v, want ErrNoInstance", err)
+	}
+}
+
+// TestPassiveEjection éjection après ConsecutiveFailures échecs, limitée à MaxEjectionPercent
+func TestPassiveEjection(t *testing.T) {
+	b := newTestBalancer(t, config.StrategyRoundRobin, threeInstances(), nil)
+	failure := errors.New("connection refused")
+
+	fail := func(id string) {
+		t.Helper()
+		for range 2 {
+			target, err := b.Pick(testService, "", "")
+			for err == nil && target.InstanceID != id {
+				target.Release()
+				target, err = b.Pick(testService, "", "")
+			}
+			if err != nil {
+				t.Fatalf("Pick(%s): %v", id, err)
+			}
+			target.Done(failure)
+		}
+	}
+
+	fail("a")
+	for range 10 {
+		if id := pick(t, b, "", ""); id == "a" {
+			t.Fatal("ejected instance still picked")
+		}
+	}
+
+	// Une deuxième éjection dépasserait 50 % des instances
+	fail("b")
+	picked := make(map[string]bool)
+	for range 10 {
+		picked[pick(t, b, "", "")] = true
+	}
+	if !picked["b"] {
+		t.Error("second instance ejected beyond max ejection percent")
+	}
+}
+
+func TestActiveHealthCheck(t *testing.T) {
+	b := newTestBalancer(t, config.StrategyRoundRobin, map[string]string{"a": ""}, nil)
+
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			t.Errorf("health check path = %s", r.URL.Path)
+		}
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	state, err := b.instanceState(testService, "a")
+	if err != nil {
+		t.Fatalf("instanceState: %v", err)
+	}
+	state.url = server.URL
+
+	// UnhealthyThreshold échecs avant de retirer l'instance
+	b.checkInstance(state)
+	if _, err := b.Pick(testService, "", ""); err != nil {
+		t.Fatalf("instance removed after a single failed check: %v", err)
+	}
+	b.checkInstance(state)
+	if _, err := b.Pick(testService, "", ""); !errors.Is(err, ErrNoInstance) {
+		t.Fatalf("Pick on unhealthy instance = %v, want ErrNoInstance", err)
+	}
+
+	// HealthyThreshold succès avant de la remettre
+	status.Store(http.StatusOK)
+	b.checkInstance(state)
+	if _, err := b.Pick(testService, "", ""); !errors.Is(err, ErrNoInstance) {
+		t.Fatal("instance back after a single successful check")
+	}
+	b.checkInstance(state)
+	if _, err := b.Pick(testService, "", ""); err != nil {
+		t.Errorf("healthy instance not picked: %v", err)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	b := newTestBalancer(t, config.StrategyRoundRobin, map[string]string{"a": ""}, nil)
+
+	target, err := b.Pick(testService, "", "")
+	if err != nil {
+		t.Fatalf("Pick: %v", err)
+	}
+
+	inFlight, err := b.Drain(testService, "a")
+	if err != nil || inFlight != 1 {
+		t.Fatalf("Drain = %d, %v; want 1 request in flight", inFlight, err)
+	}
+	if _, err := b.Pick(testService, "", ""); !errors.Is(err, ErrNoInstance) {
+		t.Errorf("Pick on drained instance = %v, want ErrNoInstance", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if remaining, _ := b.WaitDrained(ctx, testService, "a", time.Millisecond); remaining != 1 {
+		t.Errorf("WaitDrained before Done = %d, want 1", remaining)
+	}
+
+	target.Done(nil)
+	if remaining, _ := b.WaitDrained(context.Background(), testService, "a", time.Millisecond); remaining != 0 {
+		t.Errorf("WaitDrained after Done = %d, want 0", remaining)
+	}
+
+	if _, err := b.Drain(testService, "unknown"); !errors.Is(err, registry.ErrInstanceNotFound) {
+		t.Errorf("Drain(unknown) = %v, want ErrInstanceNotFound", err)
+	}
+}
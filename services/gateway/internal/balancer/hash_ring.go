@@ -0,0 +1,76 @@
+package balancer
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// hashRing anneau de hachage cohérent avec nœuds virtuels
+type hashRing struct {
+	hashes []uint32
+	owners map[uint32]*instanceState
+	states []*instanceState
+}
+
+// newHashRing construit l'anneau à partir des instances d'un service
+func newHashRing(states []*instanceState, virtualNodes int) *hashRing {
+	ring := &hashRing{
+		hashes: make([]uint32, 0, len(states)*virtualNodes),
+		owners: make(map[uint32]*instanceState, len(states)*virtualNodes),
+		states: states,
+	}
+
+	for _, state := range states {
+		for v := 0; v < virtualNodes; v++ {
+			// Les nœuds dépendent de l'ID de l'instance : une instance déplacée garde ses clés
+			hash := hashKey(state.id + "#" + strconv.Itoa(v))
+			if _, exists := ring.owners[hash]; exists {
+				continue
+			}
+			ring.owners[hash] = state
+			ring.hashes = append(ring.hashes, hash)
+		}
+	}
+
+	sort.Slice(ring.hashes, func(i, j int) bool { return ring.hashes[i] < ring.hashes[j] })
+	return ring
+}
+
+// matches indique si l'anneau a été construit pour exactement ces instances
+func (r *hashRing) matches(states []*instanceState) bool {
+	if len(r.states) != len(states) {
+		return false
+	}
+	for i := range states {
+		if r.states[i] != states[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup parcourt l'anneau dans le sens horaire depuis la clé jusqu'à une instance acceptée
+func (r *hashRing) lookup(key string, accept func(*instanceState) bool) *instanceState {
+	if len(r.hashes) == 0 {
+		return nil
+	}
+
+	hash := hashKey(key)
+	start := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= hash })
+
+	for i := 0; i < len(r.hashes); i++ {
+		owner := r.owners[r.hashes[(start+i)%len(r.hashes)]]
+		if accept(owner) {
+			return owner
+		}
+	}
+	return nil
+}
+
+// hashKey hache une clé (FNV-1a 32 bits)
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum32()
+}
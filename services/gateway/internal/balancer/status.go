@@ -0,0 +1,109 @@
+package balancer
+
+import (
+	"gateway/internal/registry"
+	"sync/atomic"
+	"time"
+)
+
+// InstanceStatus état exposé d'une instance
+type InstanceStatus struct {
+	*registry.Instance
+	Status            string     `json:"status"`
+	ActiveConnections int64      `json:"active_connections"`
+	Ejections         int        `json:"ejections"`
+	EjectedUntil      *time.Time `json:"ejected_until,omitempty"`
+	LastCheck         *time.Time `json:"last_check,omitempty"`
+	LastError         string     `json:"last_error,omitempty"`
+}
+
+// ServiceStatus état exposé d'un service et de ses instances
+type ServiceStatus struct {
+	Name      string           `json:"name"`
+	Strategy  string           `json:"strategy"`
+	Timeout   string           `json:"timeout"`
+	Retries   int              `json:"retries"`
+	Status    string           `json:"status"` // ServiceUp, ServiceDegraded, ServiceDown
+	Available int              `json:"available"`
+	Instances []InstanceStatus `json:"instances"`
+}
+
+// Snapshot retourne l'état vivant du registre enrichi de la santé des instances
+func (b *Balancer) Snapshot() []ServiceStatus {
+	services := b.registry.Services()
+	statuses := make([]ServiceStatus, len(services))
+	now := time.Now()
+
+	for i, service := range services {
+		states := b.statesFor(service)
+
+		status := ServiceStatus{
+			Name:      service.Name,
+			Strategy:  b.strategyFor(service),
+			Timeout:   service.Timeout.String(),
+			Retries:   service.Retries,
+			Instances: make([]InstanceStatus, len(service.Instances)),
+		}
+
+		circuitClosed := make([]bool, len(states))
+		for j, state := range states {
+			circuitClosed[j] = b.filter(service.Name, state.url)
+		}
+
+		b.mu.RLock()
+		for j, state := range states {
+			instance := InstanceStatus{
+				Instance:          service.Instances[j],
+				Status:            instanceStatus(state, circuitClosed[j], now),
+				ActiveConnections: atomic.LoadInt64(&state.active),
+				Ejections:         state.ejections,
+				LastError:         state.lastError,
+			}
+			if now.Before(state.ejectedUntil) {
+				ejectedUntil := state.ejectedUntil
+				instance.EjectedUntil = &ejectedUntil
+			}
+			if state.checked {
+				lastCheck := state.lastCheck
+				instance.LastCheck = &lastCheck
+			}
+			if state.available(now) && circuitClosed[j] {
+				status.Available++
+			}
+			status.Instances[j] = instance
+		}
+		b.mu.RUnlock()
+
+		switch {
+		case status.Available == len(states):
+			status.Status = ServiceUp
+		case status.Available > 0:
+			status.Status = ServiceDegraded
+		default:
+			status.Status = ServiceDown
+		}
+		statuses[i] = status
+	}
+
+	return statuses
+}
+
+// instanceStatus calcule le statut affiché d'une instance (verrou de lecture requis)
+func instanceStatus(state *instanceState, circuitClosed bool, now time.Time) string {
+	switch {
+	case state.draining && atomic.LoadInt64(&state.active) > 0:
+		return StatusDraining
+	case state.draining:
+		return StatusDrained
+	case now.Before(state.ejectedUntil):
+		return StatusEjected
+	case !circuitClosed:
+		return StatusOpen
+	case !state.checked:
+		return StatusUnknown
+	case state.healthy:
+		return StatusHealthy
+	default:
+		return StatusUnhealthy
+	}
+}
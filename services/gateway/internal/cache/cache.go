@@ -0,0 +1,283 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"gateway/internal/config"
+	"gateway/internal/tracing"
+	"mmorpg/pkg/cacheinvalidation"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+// Constantes du cache
+const (
+	// purgeTag tag d'invalidation qui vide tout le cache
+	purgeTag = "all"
+
+	// etagHashLength caractères hexadécimaux du hash utilisé comme ETag généré
+	etagHashLength = 32
+)
+
+// Résultats d'une consultation du cache
+const (
+	ResultHit         = "hit"         // servie depuis le cache
+	ResultRevalidated = "revalidated" // entrée expirée confirmée par le backend (304)
+	ResultMiss        = "miss"        // réponse du backend mise en cache
+	ResultBypass      = "bypass"      // réponse du backend non cachable
+)
+
+// Sources d'invalidation
+const (
+	sourceNATS  = "nats"
+	sourceWrite = "write"
+)
+
+// Policy politique de cache d'une route GET
+type Policy struct {
+	Name       string
+	TTL        time.Duration
+	VaryByUser bool
+
+	tags    []string
+	lookups atomic.Uint64
+	hits    atomic.Uint64
+}
+
+// Cache cache LRU des réponses, invalidé par tags via NATS
+type Cache struct {
+	config      *config.CacheConfig
+	policies    map[string]*Policy  // par route Gin
+	writes      map[string][]string // "METHOD route" -> tags à invalider
+	varyHeaders []string
+	store       *lru
+	natsConn    *nats.Conn
+
+	mu           sync.Mutex
+	subscription *nats.Subscription
+}
+
+// NewCache crée le cache (natsConn optionnel : sans NATS, les invalidations restent locales)
+func NewCache(cfg *config.CacheConfig, natsConn *nats.Conn) *Cache {
+	c := &Cache{
+		config:      cfg,
+		policies:    make(map[string]*Policy),
+		writes:      make(map[string][]string),
+		varyHeaders: cfg.VaryHeaders,
+		store:       newLRU(cfg.MaxEntries, cfg.MaxSize),
+		natsConn:    natsConn,
+	}
+
+	for _, policyCfg := range cfg.Policies {
+		c.policies[policyCfg.Path] = &Policy{
+			Name:       policyCfg.Name,
+			TTL:        policyCfg.TTL,
+			VaryByUser: policyCfg.VaryByUser,
+			tags:       append([]string{policyCfg.Name}, policyCfg.Tags...),
+		}
+		for _, route := range policyCfg.InvalidateOn {
+			fields := strings.Fields(route)
+			write := strings.ToUpper(fields[0]) + " " + fields[1]
+			c.writes[write] = append(c.writes[write], policyCfg.Tags...)
+		}
+	}
+
+	return c
+}
+
+// Start s'abonne aux invalidations publiées sur NATS
+func (c *Cache) Start() error {
+	if !c.config.Enabled || c.natsConn == nil {
+		return nil
+	}
+
+	prefix := c.config.InvalidationPrefix + "."
+	subscription, err := c.natsConn.Subscribe(prefix+">", func(msg *nats.Msg) {
+		ctx, span := tracing.StartConsumerSpan(msg)
+		defer span.End()
+
+		tag := strings.TrimPrefix(msg.Subject, prefix)
+		count := c.invalidate(tag)
+		invalidatedEntries.WithLabelValues(sourceNATS).Add(float64(count))
+		logrus.WithContext(ctx).WithFields(logrus.Fields{
+			"tag":     tag,
+			"entries": count,
+		}).Debug("Cache invalidated")
+	})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.subscription = subscription
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Close arrête les invalidations
+func (c *Cache) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.subscription != nil {
+		if err := c.subscription.Unsubscribe(); err != nil {
+			logrus.WithError(err).Debug("Failed to unsubscribe from cache invalidations")
+		}
+		c.subscription = nil
+	}
+}
+
+// Policy retourne la politique d'une route, nil si la route n'est pas mise en cache
+func (c *Cache) Policy(route string) *Policy {
+	if !c.config.Enabled {
+		return nil
+	}
+	return c.policies[route]
+}
+
+// MaxEntrySize taille maximale d'une réponse mise en cache
+func (c *Cache) MaxEntrySize() int64 {
+	return c.config.MaxEntrySize
+}
+
+// Key construit la clé d'une requête : chemin, paramètres triés, utilisateur si la
+// politique le demande et headers de négociation
+func (c *Cache) Key(p *Policy, r *http.Request, userID string) string {
+	var key strings.Builder
+	key.WriteString(p.Name)
+	key.WriteByte('|')
+	key.WriteString(r.URL.Path)
+	key.WriteByte('?')
+	key.WriteString(r.URL.Query().Encode())
+	if p.VaryByUser {
+		key.WriteString("|user=")
+		key.WriteString(userID)
+	}
+	for _, header := range c.varyHeaders {
+		key.WriteByte('|')
+		key.WriteString(r.Header.Get(header))
+	}
+	return key.String()
+}
+
+// Tags retourne les tags d'invalidation d'une entrée (param : paramètres de la route)
+func (p *Policy) Tags(param func(string) string) []string {
+	return cacheinvalidation.ResolveTags(p.tags, param)
+}
+
+// Get retourne l'entrée d'une clé, fraîche ou encore revalidable
+func (c *Cache) Get(key string) (*Entry, bool) {
+	return c.store.get(key, time.Now())
+}
+
+// Store met une entrée en cache
+func (c *Cache) Store(key string, entry *Entry, tags []string) {
+	evicted := c.store.set(key, entry, tags, entry.Expires.Add(c.config.StaleTTL))
+	evictions.Add(float64(evicted))
+	c.updateSizeMetrics()
+}
+
+// Refresh prolonge une entrée confirmée par le backend
+func (c *Cache) Refresh(p *Policy, key string, entry *Entry, tags []string) *Entry {
+	now := time.Now()
+	refreshed := *entry
+	refreshed.StoredAt = now
+	refreshed.Expires = now.Add(p.TTL)
+	c.Store(key, &refreshed, tags)
+	return &refreshed
+}
+
+// Record compte le résultat d'une consultation et met à jour le taux de hit
+func (c *Cache) Record(p *Policy, result string) {
+	lookups.WithLabelValues(p.Name, result).Inc()
+
+	total := p.lookups.Add(1)
+	hits := p.hits.Load()
+	if result == ResultHit || result == ResultRevalidated {
+		hits = p.hits.Add(1)
+	}
+	hitRatio.WithLabelValues(p.Name).Set(float64(hits) / float64(total))
+}
+
+// InvalidateWrite invalide les entrées touchées par une écriture réussie
+// L'invalidation est immédiate sur ce réplica et publiée sur NATS pour les autres.
+func (c *Cache) InvalidateWrite(ctx context.Context, method, route string, param func(string) string) {
+	if !c.config.Enabled {
+		return
+	}
+	templates, exists := c.writes[method+" "+route]
+	if !exists {
+		return
+	}
+
+	for _, tag := range cacheinvalidation.ResolveTags(templates, param) {
+		invalidatedEntries.WithLabelValues(sourceWrite).Add(float64(c.invalidate(tag)))
+		if c.natsConn == nil || !c.natsConn.IsConnected() {
+			continue
+		}
+		if err := tracing.Publish(ctx, c.natsConn, c.config.InvalidationPrefix+"."+tag, nil); err != nil {
+			logrus.WithContext(ctx).WithError(err).WithField("tag", tag).Warn("Failed to publish cache invalidation")
+		}
+	}
+}
+
+// invalidate supprime les entrées d'un tag ("all" vide le cache)
+func (c *Cache) invalidate(tag string) int {
+	var count int
+	if tag == purgeTag {
+		count = c.store.purge()
+	} else {
+		count = c.store.invalidate(tag)
+	}
+	c.updateSizeMetrics()
+	return count
+}
+
+// updateSizeMetrics met à jour les jauges d'occupation
+func (c *Cache) updateSizeMetrics() {
+	count, size := c.store.stats()
+	entries.Set(float64(count))
+	sizeBytes.Set(float64(size))
+}
+
+// NewEntry prépare une réponse à mettre en cache
+// L'ETag du backend est conservé ; à défaut, il est calculé sur le corps.
+func NewEntry(status int, header http.Header, body []byte, ttl time.Duration) *Entry {
+	now := time.Now()
+	entry := &Entry{
+		Status:   status,
+		Header:   header,
+		Body:     body,
+		ETag:     header.Get("ETag"),
+		StoredAt: now,
+		Expires:  now.Add(ttl),
+	}
+	if entry.ETag == "" {
+		sum := sha256.Sum256(body)
+		entry.ETag = `"` + hex.EncodeToString(sum[:])[:etagHashLength] + `"`
+	}
+	return entry
+}
+
+// Matches indique si un header If-None-Match désigne l'entrée (comparaison faible)
+func (e *Entry) Matches(ifNoneMatch string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag := strings.TrimPrefix(e.ETag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
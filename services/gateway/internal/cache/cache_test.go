@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"context"
+	"gateway/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestCache cache sans NATS avec une politique par inventaire, invalidée par l'ajout d'objets
+func newTestCache(maxEntries int, maxSize int64) *Cache {
+	return NewCache(&config.CacheConfig{
+		Enabled:            true,
+		MaxEntries:         maxEntries,
+		MaxSize:            maxSize,
+		MaxEntrySize:       maxSize,
+		StaleTTL:           time.Minute,
+		VaryHeaders:        []string{"Accept"},
+		InvalidationPrefix: "cache.invalidate",
+		Policies: []config.CachePolicy{{
+			Name:         "inventory",
+			Path:         "/api/v1/inventory/:characterId",
+			TTL:          time.Minute,
+			VaryByUser:   true,
+			Tags:         []string{"inventory.{characterId}"},
+			InvalidateOn: []string{"post /api/v1/inventory/:characterId/items"},
+		}},
+	}, nil)
+}
+
+// storeInventory met en cache l'inventaire d'un personnage et retourne sa clé
+func storeInventory(t *testing.T, c *Cache, characterID string) string {
+	t.Helper()
+
+	policy := c.Policy("/api/v1/inventory/:characterId")
+	if policy == nil {
+		t.Fatal("inventory policy not found")
+	}
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/inventory/"+characterID, http.NoBody)
+	key := c.Key(policy, r, "user-1")
+	tags := policy.Tags(func(string) string { return characterID })
+	c.Store(key, NewEntry(http.StatusOK, http.Header{}, []byte(`{"items":[]}`), policy.TTL), tags)
+	return key
+}
+
+func TestInvalidateWrite(t *testing.T) {
+	c := newTestCache(10, 1<<20)
+	key1 := storeInventory(t, c, "c1")
+	key2 := storeInventory(t, c, "c2")
+
+	param := func(string) string { return "c1" }
+	c.InvalidateWrite(context.Background(), http.MethodPost, "/api/v1/inventory/:characterId/items", param)
+
+	if _, found := c.Get(key1); found {
+		t.Error("c1 inventory still cached after write")
+	}
+	if _, found := c.Get(key2); !found {
+		t.Error("c2 inventory invalidated by c1 write")
+	}
+
+	// Une écriture hors invalidate_on ne touche pas au cache
+	c.InvalidateWrite(context.Background(), http.MethodPut, "/api/v1/inventory/:characterId/items/:itemId", param)
+	if _, found := c.Get(key2); !found {
+		t.Error("c2 inventory invalidated by unrelated write")
+	}
+}
+
+// TestInvalidateTag tags reçus sur NATS : tag de la politique, tag paramétré et purge
+func TestInvalidateTag(t *testing.T) {
+	c := newTestCache(10, 1<<20)
+	key1 := storeInventory(t, c, "c1")
+	key2 := storeInventory(t, c, "c2")
+
+	if count := c.invalidate("inventory.c2"); count != 1 {
+		t.Errorf("invalidate(inventory.c2) = %d, want 1", count)
+	}
+	if _, found := c.Get(key2); found {
+		t.Error("c2 inventory still cached")
+	}
+
+	storeInventory(t, c, "c2")
+	if count := c.invalidate("inventory"); count != 2 {
+		t.Errorf("invalidate(inventory) = %d, want 2", count)
+	}
+
+	storeInventory(t, c, "c1")
+	storeInventory(t, c, "c2")
+	if count := c.invalidate(purgeTag); count != 2 {
+		t.Errorf("invalidate(all) = %d, want 2", count)
+	}
+	if _, found := c.Get(key1); found {
+		t.Error("cache not purged")
+	}
+}
+
+func TestKeyVaries(t *testing.T) {
+	c := newTestCache(10, 1<<20)
+	policy := c.Policy("/api/v1/inventory/:characterId")
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/inventory/c1?b=2&a=1", http.NoBody)
+	base := c.Key(policy, r, "user-1")
+
+	if other := c.Key(policy, r, "user-2"); other == base {
+		t.Error("key does not vary by user")
+	}
+
+	reordered := httptest.NewRequest(http.MethodGet, "/api/v1/inventory/c1?a=1&b=2", http.NoBody)
+	if other := c.Key(policy, reordered, "user-1"); other != base {
+		t.Errorf("key depends on query order: %q != %q", other, base)
+	}
+
+	r.Header.Set("Accept", "application/x-protobuf")
+	if other := c.Key(policy, r, "user-1"); other == base {
+		t.Error("key does not vary by Accept")
+	}
+}
+
+func TestEviction(t *testing.T) {
+	c := newTestCache(2, 1<<20)
+	key1 := storeInventory(t, c, "c1")
+	key2 := storeInventory(t, c, "c2")
+
+	// c1 est lue, c2 devient la moins récente et est évincée
+	if _, found := c.Get(key1); !found {
+		t.Fatal("c1 inventory not cached")
+	}
+	storeInventory(t, c, "c3")
+
+	if _, found := c.Get(key2); found {
+		t.Error("least recently used entry not evicted")
+	}
+	if _, found := c.Get(key1); !found {
+		t.Error("recently used entry evicted")
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	c := newTestCache(10, 1<<20)
+	c.config.Enabled = false
+
+	if policy := c.Policy("/api/v1/inventory/:characterId"); policy != nil {
+		t.Error("policy returned while cache disabled")
+	}
+}
+
+func TestEntryMatches(t *testing.T) {
+	entry := NewEntry(http.StatusOK, http.Header{}, []byte("body"), time.Minute)
+
+	tests := []struct {
+		ifNoneMatch string
+		want        bool
+	}{
+		{"", false},
+		{entry.ETag, true},
+		{"W/" + entry.ETag, true},
+		{`"other", ` + entry.ETag, true},
+		{"*", true},
+		{`"other"`, false},
+	}
+	for _, tt := range tests {
+		if got := entry.Matches(tt.ifNoneMatch); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.ifNoneMatch, got, tt.want)
+		}
+	}
+
+	backend := NewEntry(http.StatusOK, http.Header{"Etag": []string{`"v1"`}}, []byte("body"), time.Minute)
+	if backend.ETag != `"v1"` {
+		t.Errorf("backend ETag = %q, want \"v1\"", backend.ETag)
+	}
+}
+
+func TestEntryFresh(t *testing.T) {
+	entry := NewEntry(http.StatusOK, http.Header{}, nil, time.Minute)
+
+	if !entry.Fresh(time.Now()) {
+		t.Error("new entry not fresh")
+	}
+	if entry.Fresh(time.Now().Add(2 * time.Minute)) {
+		t.Error("expired entry still fresh")
+	}
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Entry réponse mise en cache
+type Entry struct {
+	Status   int
+	Header   http.Header
+	Body     []byte
+	ETag     string
+	StoredAt time.Time
+	Expires  time.Time // fin de fraîcheur ; l'entrée reste revalidable ensuite
+}
+
+// Fresh indique si l'entrée peut être servie sans consulter le backend
+func (e *Entry) Fresh(now time.Time) bool {
+	return now.Before(e.Expires)
+}
+
+// size estime la place occupée par l'entrée
+func (e *Entry) size(key string) int64 {
+	size := int64(len(key) + len(e.Body) + len(e.ETag))
+	for name, values := range e.Header {
+		size += int64(len(name))
+		for _, value := range values {
+			size += int64(len(value))
+		}
+	}
+	return size
+}
+
+// lruItem élément de la liste LRU
+type lruItem struct {
+	key      string
+	entry    *Entry
+	tags     []string
+	size     int64
+	deadline time.Time // au-delà, l'entrée n'est plus revalidable
+}
+
+// lru cache LRU borné en entrées et en octets, avec index des tags d'invalidation
+type lru struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxSize    int64
+	size       int64
+	order      *list.List // plus récemment utilisée en tête
+	items      map[string]*list.Element
+	tags       map[string]map[string]struct{}
+}
+
+// newLRU crée un LRU vide
+func newLRU(maxEntries int, maxSize int64) *lru {
+	return &lru{
+		maxEntries: maxEntries,
+		maxSize:    maxSize,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+		tags:       make(map[string]map[string]struct{}),
+	}
+}
+
+// get retourne une entrée encore revalidable et la marque comme récente
+func (l *lru) get(key string, now time.Time) (*Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, exists := l.items[key]
+	if !exists {
+		return nil, false
+	}
+	item := element.Value.(*lruItem)
+	if now.After(item.deadline) {
+		l.remove(element)
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	return item.entry, true
+}
+
+// set ajoute ou remplace une entrée et retourne le nombre d'entrées évincées
+func (l *lru) set(key string, entry *Entry, tags []string, deadline time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, exists := l.items[key]; exists {
+		l.remove(element)
+	}
+
+	item := &lruItem{key: key, entry: entry, tags: tags, size: entry.size(key), deadline: deadline}
+	l.items[key] = l.order.PushFront(item)
+	l.size += item.size
+	for _, tag := range tags {
+		keys, exists := l.tags[tag]
+		if !exists {
+			keys = make(map[string]struct{})
+			l.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	evicted := 0
+	for l.order.Len() > l.maxEntries || l.size > l.maxSize {
+		l.remove(l.order.Back())
+		evicted++
+	}
+	return evicted
+}
+
+// invalidate supprime les entrées d'un tag et retourne leur nombre
+func (l *lru) invalidate(tag string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	keys := l.tags[tag]
+	count := len(keys)
+	for key := range keys {
+		l.remove(l.items[key])
+	}
+	return count
+}
+
+// purge vide le cache et retourne le nombre d'entrées supprimées
+func (l *lru) purge() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	count := l.order.Len()
+	l.order.Init()
+	l.items = make(map[string]*list.Element)
+	l.tags = make(map[string]map[string]struct{})
+	l.size = 0
+	return count
+}
+
+// stats retourne le nombre d'entrées et la taille occupée
+func (l *lru) stats() (int, int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len(), l.size
+}
+
+// remove supprime un élément et ses références de tags (verrou requis)
+func (l *lru) remove(element *list.Element) {
+	item := element.Value.(*lruItem)
+	l.order.Remove(element)
+	delete(l.items, item.key)
+	l.size -= item.size
+
+	for _, tag := range item.tags {
+		keys := l.tags[tag]
+		delete(keys, item.key)
+		if len(keys) == 0 {
+			delete(l.tags, tag)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Métriques Prometheus du cache des réponses
+var (
+	lookups = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_cache_lookups_total",
+			Help: "Total number of cacheable requests by policy and result (hit, revalidated, miss, bypass)",
+		},
+		[]string{"policy", "result"},
+	)
+
+	hitRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gateway_cache_hit_ratio",
+			Help: "Share of cacheable requests served from the cache since startup",
+		},
+		[]string{"policy"},
+	)
+
+	entries = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gateway_cache_entries",
+			Help: "Number of responses in the cache",
+		},
+	)
+
+	sizeBytes = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gateway_cache_size_bytes",
+			Help: "Estimated memory used by cached responses",
+		},
+	)
+
+	evictions = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "gateway_cache_evictions_total",
+			Help: "Total number of responses evicted to respect the cache size limits",
+		},
+	)
+
+	invalidatedEntries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_cache_invalidated_entries_total",
+			Help: "Total number of cached responses removed by invalidations",
+		},
+		[]string{"source"},
+	)
+)
+
+// InitMetrics initialize les métriques Prometheus du cache
+func InitMetrics() {
+	prometheus.MustRegister(lookups)
+	prometheus.MustRegister(hitRatio)
+	prometheus.MustRegister(entries)
+	prometheus.MustRegister(sizeBytes)
+	prometheus.MustRegister(evictions)
+	prometheus.MustRegister(invalidatedEntries)
+}
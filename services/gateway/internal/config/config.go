@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"mmorpg/pkg/cacheinvalidation"
+	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -27,9 +30,16 @@ const (
 	DefaultNATSReconnectDelay = 2
 
 	// Rate Limiting par défaut
-	DefaultRPM             = 1000
-	DefaultBurstSize       = 100
-	DefaultCleanupInterval = 5 // minutes
+	DefaultRPM                   = 1000
+	DefaultCleanupInterval       = 5  // minutes
+	DefaultRateLimitStoreTimeout = 50 // millisecondes
+	DefaultAuthRateLimit         = 20 // par minute
+	DefaultCombatRateLimit       = 20 // par fenêtre de 10s
+	DefaultChatRateLimit         = 5  // par fenêtre de 10s
+	DefaultProfileReadRateLimit  = 300
+	DefaultStaffRateLimit        = 50 // modérateurs et admins sur les routes sensibles
+	DefaultServiceRateLimit      = 5000
+	DefaultShortRateLimitWindow  = 10 // secondes
 
 	// Retry et Health
 	DefaultRetries          = 3
@@ -37,18 +47,190 @@ const (
 	DefaultSingleRetry      = 1
 	DefaultNATSMaxReconnect = 10
 
-	// JWT
-	MinJWTSecretLength = 32
+	// Registre des services (en secondes)
+	DefaultRegistryWatchInterval   = 5
+	DefaultRegistryHeartbeatTTL    = 30
+	DefaultRegistryCleanupInterval = 10
+
+	// Répartition de charge et santé des instances
+	DefaultLBHashVirtualNodes    = 100
+	DefaultLBHealthCheckInterval = 10 // secondes
+	DefaultLBHealthCheckTimeout  = 2  // secondes
+	DefaultLBHealthyThreshold    = 2
+	DefaultLBUnhealthyThreshold  = 3
+	DefaultLBConsecutiveFailures = 5
+	DefaultLBBaseEjectionTime    = 30 // secondes
+	DefaultLBMaxEjectionTime     = 300
+	DefaultLBMaxEjectionPercent  = 50
+	MaxPercent                   = 100
+
+	// Circuit breakers par instance et budget de retries
+	DefaultCBWindow              = 10 // secondes
+	DefaultCBMinRequests         = 20
+	DefaultCBFailureRate         = 0.5
+	DefaultCBSlowCallThreshold   = 2000 // millisecondes
+	DefaultCBSlowCallRate        = 0.8
+	DefaultCBOpenTimeout         = 30 // secondes
+	DefaultCBHalfOpenProbes      = 3
+	DefaultRetryBudgetRatio      = 0.2
+	DefaultRetryBudgetMinRetries = 10
+
+	// Hub WebSocket temps réel
+	DefaultWSSendQueueSize    = 256
+	DefaultWSPingInterval     = 25 // secondes
+	DefaultWSPongTimeout      = 60 // secondes
+	DefaultWSWriteTimeout     = 10 // secondes
+	DefaultWSMaxMessageSize   = 64 * 1024
+	DefaultWSMaxSubscriptions = 50
+	DefaultWSResumeWindow     = 120 // secondes
+	DefaultWSResumeBufferSize = 128
+
+	// Appartenance aux topics temps réel (chat, zone, party, guild, combat)
+	DefaultWSMembershipTimeout = 500 // millisecondes
+	DefaultWSMembershipTTL     = 30  // secondes
+
+	// Cache des réponses
+	DefaultCacheMaxEntries   = 10000
+	DefaultCacheMaxSize      = 64 * 1024 * 1024
+	DefaultCacheMaxEntrySize = 1024 * 1024
+	DefaultCacheZonesTTL     = 30  // secondes
+	DefaultCacheGuildTTL     = 60  // secondes
+	DefaultCacheInventoryTTL = 15  // secondes
+	DefaultCacheStaleTTL     = 300 // secondes
+
+	// Agrégation du bootstrap client
+	DefaultBootstrapTimeout         = 3000 // millisecondes
+	DefaultBootstrapSectionTimeout  = 1000 // millisecondes
+	DefaultBootstrapMaxResponseSize = 1024 * 1024
+
+	// Validation des requêtes contre les contrats OpenAPI
+	DefaultOpenAPIMaxBodySize = 1024 * 1024
+
+	// Tracing distribué
+	DefaultTracingSampleRatio = 1.0
+
+	// Clés d'idempotence des écritures
+	DefaultIdempotencyTTL             = 24  // heures
+	DefaultIdempotencyLockTimeout     = 60  // secondes
+	DefaultIdempotencyStoreTimeout    = 100 // millisecondes
+	DefaultIdempotencyCleanupInterval = 1   // minutes
+	DefaultIdempotencyMaxKeyLength    = 255
+	DefaultIdempotencyMaxBodySize     = 1024 * 1024
+	DefaultIdempotencyMaxResponseSize = 256 * 1024 // une réponse gardée tient dans un message NATS
+
+	// Révocation des tokens d'accès
+	DefaultRevocationTokenLifetime        = 15  // minutes, durée de vie des tokens d'accès émis par auth
+	DefaultRevocationIntrospectionTimeout = 500 // millisecondes
+	DefaultRevocationIntrospectionTTL     = 30  // secondes
+	DefaultRevocationCleanupInterval      = 1   // minutes
+
+	// Fenêtres de maintenance et drain des instances
+	DefaultMaintenanceMaxDuration       = 24  // heures
+	DefaultMaintenanceTickInterval      = 1   // secondes
+	DefaultMaintenanceSyncTimeout       = 500 // millisecondes
+	DefaultMaintenanceDrainPollInterval = 100 // millisecondes
+	DefaultMaintenanceMaxDrainWait      = 5   // minutes
+	DefaultMaintenanceNoticeFirst       = 15  // minutes avant le début
+	DefaultMaintenanceNoticeSecond      = 5   // minutes avant le début
+	DefaultMaintenanceNoticeLast        = 1   // minutes avant le début
+
+	// Détection des abus
+	DefaultAbuseBlockThreshold    = 100.0
+	DefaultAbuseScoreHalfLife     = 10  // minutes
+	DefaultAbuseWindow            = 1   // minutes
+	DefaultAbuseBlockDuration     = 15  // minutes, doublée à chaque récidive
+	DefaultAbuseMaxBlockDuration  = 24  // heures
+	DefaultAbuseRateMinRequests   = 300 // requêtes par fenêtre avant qu'une anomalie soit possible
+	DefaultAbuseRateFactor        = 4.0 // multiple du débit habituel du client
+	DefaultAbuseRateWeight        = 40.0
+	DefaultAbuseAuthFailureWeight = 10.0
+	DefaultAbuseEnumerationWeight = 5.0
+	DefaultAbuseReplayWindow      = 10 // secondes
+	DefaultAbuseReplayWeight      = 10.0
+	DefaultAbuseMaxBodySize       = 64 * 1024
+	DefaultAbuseMaxClients        = 100000
+	DefaultAbuseCleanupInterval   = 1 // minutes
+
+	// Journal d'audit des écritures
+	DefaultAuditMaxBodySize      = 16 * 1024
+	DefaultAuditMaxFileSize      = 50 * 1024 * 1024
+	DefaultAuditMaxBackups       = 5
+	DefaultAuditQueueSize        = 1024
+	DefaultAuditRecentRecords    = 1000
+	DefaultAuditAnalyticsTimeout = 2 // secondes
+	DefaultAuditQueryLimit       = 100
+	DefaultAuditMaxQueryLimit    = 1000
+
+	// JWT : clés publiques du service auth
+	DefaultJWKSRefresh = 5 // minutes
+	JWKSPath           = "/.well-known/jwks.json"
+)
+
+// Exporteurs de traces
+const (
+	TracingExporterOTLP   = "otlp"   // collecteur OTLP/HTTP (Jaeger, Tempo, collector)
+	TracingExporterStdout = "stdout" // développement local
+	TracingExporterFile   = "file"   // JSON, un span par ligne
+)
+
+// Tiers de rate limiting
+const (
+	TierAnonymous = "anonymous"
+	TierPlayer    = "player"
+	TierModerator = "moderator"
+	TierAdmin     = "admin"
+	TierService   = "service"
+)
+
+// Stores de rate limiting
+const (
+	RateLimitStoreMemory = "memory" // local à chaque réplica
+	RateLimitStoreNATS   = "nats"   // JetStream KV partagé entre réplicas
+)
+
+// Stores des clés d'idempotence
+const (
+	IdempotencyStoreMemory = "memory" // local à chaque réplica
+	IdempotencyStoreNATS   = "nats"   // JetStream KV partagé entre réplicas
+)
+
+// Destinations du journal d'audit
+const (
+	AuditSinkFile      = "file"      // fichier JSON (un enregistrement par ligne) avec rotation
+	AuditSinkNATS      = "nats"      // publié sur NATS
+	AuditSinkAnalytics = "analytics" // événements du service analytics
+)
+
+// Stratégies de répartition de charge
+const (
+	StrategyRoundRobin       = "round_robin"
+	StrategyLeastConnections = "least_connections"
+	StrategyConsistentHash   = "consistent_hash" // par ID utilisateur (sessions de combat)
 )
 
 // Config représente la configuration du Gateway
 type Config struct {
-	Server     ServerConfig     `mapstructure:"server"`
-	JWT        JWTConfig        `mapstructure:"jwt"`
-	Services   ServicesConfig   `mapstructure:"services"`
-	RateLimit  RateLimitConfig  `mapstructure:"rate_limit"`
-	Monitoring MonitoringConfig `mapstructure:"monitoring"`
-	NATS       NATSConfig       `mapstructure:"nats"`
+	Server         ServerConfig         `mapstructure:"server"`
+	JWT            JWTConfig            `mapstructure:"jwt"`
+	Services       ServicesConfig       `mapstructure:"services"`
+	RateLimit      RateLimitConfig      `mapstructure:"rate_limit"`
+	Monitoring     MonitoringConfig     `mapstructure:"monitoring"`
+	NATS           NATSConfig           `mapstructure:"nats"`
+	Registry       RegistryConfig       `mapstructure:"registry"`
+	LoadBalancing  LoadBalancingConfig  `mapstructure:"load_balancing"`
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	Realtime       RealtimeConfig       `mapstructure:"realtime"`
+	Cache          CacheConfig          `mapstructure:"cache"`
+	Bootstrap      BootstrapConfig      `mapstructure:"bootstrap"`
+	OpenAPI        OpenAPIConfig        `mapstructure:"openapi"`
+	Tracing        TracingConfig        `mapstructure:"tracing"`
+	Traffic        TrafficConfig        `mapstructure:"traffic"`
+	Idempotency    IdempotencyConfig    `mapstructure:"idempotency"`
+	InternalRoutes InternalRoutesConfig `mapstructure:"internal_routes"`
+	Revocation     RevocationConfig     `mapstructure:"revocation"`
+	Maintenance    MaintenanceConfig    `mapstructure:"maintenance"`
+	Abuse          AbuseConfig          `mapstructure:"abuse"`
+	Audit          AuditConfig          `mapstructure:"audit"`
 }
 
 // ServerConfig configuration du serveur Gateway
@@ -59,12 +241,21 @@ type ServerConfig struct {
 	Debug        bool          `mapstructure:"debug"`
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// TrustedProxies IP ou CIDR des load balancers devant le gateway ; X-Forwarded-For n'est lu
+	// que pour les requêtes venant de ces adresses (vide : l'adresse de la connexion fait foi)
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 // JWTConfig configuration JWT
+// Les tokens sont vérifiés avec les clés publiques du service auth (JWKS), gardées en cache
+// et rechargées toutes les JWKSRefresh ; sans URL, le JWKS est lu sur le service auth.
 type JWTConfig struct {
-	Secret         string        `mapstructure:"secret"`
+	JWKSURL        string        `mapstructure:"jwks_url"`
+	JWKSRefresh    time.Duration `mapstructure:"jwks_refresh"`
 	ExpirationTime time.Duration `mapstructure:"expiration_time"`
+	// StaffTwoFactor exige une session 2FA (claim mfa) des comptes staff sur les routes
+	// d'administration et pour passer une fenêtre de maintenance
+	StaffTwoFactor bool `mapstructure:"staff_two_factor"`
 }
 
 // ServicesConfig configuration des services backend
@@ -79,6 +270,20 @@ type ServicesConfig struct {
 	Analytics ServiceEndpoint `mapstructure:"analytics"`
 }
 
+// Endpoints retourne les services configurés indexés par nom
+func (s ServicesConfig) Endpoints() map[string]ServiceEndpoint {
+	return map[string]ServiceEndpoint{
+		"auth":      s.Auth,
+		"player":    s.Player,
+		"world":     s.World,
+		"combat":    s.Combat,
+		"inventory": s.Inventory,
+		"guild":     s.Guild,
+		"chat":      s.Chat,
+		"analytics": s.Analytics,
+	}
+}
+
 // ServiceEndpoint représente un endpoint de service
 type ServiceEndpoint struct {
 	URL     string        `mapstructure:"url"`
@@ -88,9 +293,35 @@ type ServiceEndpoint struct {
 
 // RateLimitConfig configuration du rate limiting
 type RateLimitConfig struct {
+	// Politique par défaut (routes sans politique dédiée), par minute
 	RequestsPerMinute int           `mapstructure:"requests_per_minute"`
-	BurstSize         int           `mapstructure:"burst_size"`
 	CleanupInterval   time.Duration `mapstructure:"cleanup_interval"`
+
+	// Store des compteurs ; en cas d'erreur du store partagé, repli sur le store local
+	Store        string        `mapstructure:"store"`
+	Bucket       string        `mapstructure:"bucket"`
+	StoreTimeout time.Duration `mapstructure:"store_timeout"`
+
+	// Politiques évaluées dans l'ordre, la première qui correspond s'applique
+	Policies  []RateLimitPolicy   `mapstructure:"policies"`
+	Overrides []RateLimitOverride `mapstructure:"overrides"`
+}
+
+// RateLimitPolicy limites d'un motif de route par tier d'utilisateur
+type RateLimitPolicy struct {
+	Name    string   `mapstructure:"name"`
+	Methods []string `mapstructure:"methods"` // vide : toutes les méthodes
+	// Route Gin (ex. /api/v1/chat/messages) ; un "*" final correspond à un préfixe
+	Path   string        `mapstructure:"path"`
+	Window time.Duration `mapstructure:"window"`
+	// Requêtes par fenêtre et par tier ; un tier absent utilise la limite "player"
+	Limits map[string]int `mapstructure:"limits"`
+}
+
+// RateLimitOverride limites spécifiques d'un utilisateur (ex. comptes de test de charge)
+type RateLimitOverride struct {
+	UserID     string  `mapstructure:"user_id"`
+	Multiplier float64 `mapstructure:"multiplier"` // 0 : aucune limite
 }
 
 // MonitoringConfig configuration du monitoring
@@ -110,6 +341,344 @@ type NATSConfig struct {
 	MaxReconnectAttempts int           `mapstructure:"max_reconnect_attempts"`
 }
 
+// RegistryConfig configuration du registre dynamique des services
+type RegistryConfig struct {
+	// File fichier (yaml/json) surveillé décrivant les instances des services
+	File            string        `mapstructure:"file"`
+	WatchInterval   time.Duration `mapstructure:"watch_interval"`
+	HeartbeatTTL    time.Duration `mapstructure:"heartbeat_ttl"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+	// Token partagé exigé par l'API d'enregistrement (header X-Registry-Token)
+	Token string `mapstructure:"token"`
+}
+
+// LoadBalancingConfig configuration de la répartition entre instances
+type LoadBalancingConfig struct {
+	Strategy          string            `mapstructure:"strategy"`
+	ServiceStrategies map[string]string `mapstructure:"service_strategies"`
+	HashVirtualNodes  int               `mapstructure:"hash_virtual_nodes"`
+
+	// Health checks actifs
+	HealthCheckPath     string        `mapstructure:"health_check_path"`
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"`
+	HealthCheckTimeout  time.Duration `mapstructure:"health_check_timeout"`
+	HealthyThreshold    int           `mapstructure:"healthy_threshold"`
+	UnhealthyThreshold  int           `mapstructure:"unhealthy_threshold"`
+
+	// Éjection des instances défaillantes (health checks passifs)
+	ConsecutiveFailures int           `mapstructure:"consecutive_failures"`
+	BaseEjectionTime    time.Duration `mapstructure:"base_ejection_time"`
+	MaxEjectionTime     time.Duration `mapstructure:"max_ejection_time"`
+	MaxEjectionPercent  int           `mapstructure:"max_ejection_percent"`
+}
+
+// CircuitBreakerConfig configuration des circuit breakers par instance du proxy
+type CircuitBreakerConfig struct {
+	// Fenêtre glissante d'observation (arrondie à la seconde)
+	Window      time.Duration `mapstructure:"window"`
+	MinRequests int           `mapstructure:"min_requests"`
+
+	// Seuils d'ouverture : taux d'échec ou taux d'appels lents sur la fenêtre
+	FailureRateThreshold  float64       `mapstructure:"failure_rate_threshold"`
+	SlowCallThreshold     time.Duration `mapstructure:"slow_call_threshold"`
+	SlowCallRateThreshold float64       `mapstructure:"slow_call_rate_threshold"`
+
+	// Durée d'ouverture avant les requêtes de test (half-open)
+	OpenTimeout    time.Duration `mapstructure:"open_timeout"`
+	HalfOpenProbes int           `mapstructure:"half_open_probes"`
+
+	// Budget de retries par service : MinRetries + RetryBudgetRatio * requêtes sur la fenêtre
+	RetryBudgetRatio float64 `mapstructure:"retry_budget_ratio"`
+	MinRetries       int     `mapstructure:"min_retries"`
+}
+
+// RealtimeConfig configuration du hub WebSocket temps réel
+type RealtimeConfig struct {
+	// Préfixe des sujets NATS diffusés aux clients (<prefix>.<type>.<id>)
+	SubjectPrefix string `mapstructure:"subject_prefix"`
+
+	// File d'envoi par connexion : un client qui ne suit pas est déconnecté
+	SendQueueSize  int           `mapstructure:"send_queue_size"`
+	WriteTimeout   time.Duration `mapstructure:"write_timeout"`
+	MaxMessageSize int64         `mapstructure:"max_message_size"`
+
+	// Heartbeats : ping du serveur, connexion fermée sans pong dans le délai
+	PingInterval time.Duration `mapstructure:"ping_interval"`
+	PongTimeout  time.Duration `mapstructure:"pong_timeout"`
+
+	MaxSubscriptions int `mapstructure:"max_subscriptions"`
+
+	// Reprise de session : événements gardés pour un client qui se reconnecte
+	ResumeWindow     time.Duration `mapstructure:"resume_window"`
+	ResumeBufferSize int           `mapstructure:"resume_buffer_size"`
+
+	// Appartenance aux topics, demandée aux services player, chat, world, guild et combat
+	// (token de service du gateway) puis gardée en cache
+	MembershipTimeout time.Duration `mapstructure:"membership_timeout"`
+	MembershipTTL     time.Duration `mapstructure:"membership_ttl"`
+}
+
+// CacheConfig configuration du cache des réponses GET
+type CacheConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Taille du LRU en mémoire : nombre d'entrées et octets (corps + headers)
+	MaxEntries   int   `mapstructure:"max_entries"`
+	MaxSize      int64 `mapstructure:"max_size"`
+	MaxEntrySize int64 `mapstructure:"max_entry_size"`
+
+	// Durée pendant laquelle une entrée expirée reste revalidable par ETag auprès du backend
+	StaleTTL time.Duration `mapstructure:"stale_ttl"`
+
+	// Headers de la requête qui font varier la clé (négociation de contenu)
+	VaryHeaders []string `mapstructure:"vary_headers"`
+
+	// Préfixe des sujets NATS d'invalidation (<prefix>.<tag>, "all" vide le cache), celui utilisé
+	// par les services inventory, guild et world pour publier leurs écritures
+	InvalidationPrefix string `mapstructure:"invalidation_prefix"`
+
+	Policies []CachePolicy `mapstructure:"policies"`
+}
+
+// CachePolicy mise en cache d'une route GET
+// Les tags et les routes d'écriture acceptent les paramètres de la route entre accolades
+// (ex. guild.{id}) : une écriture réussie sur une de ces routes invalide les tags.
+type CachePolicy struct {
+	Name         string        `mapstructure:"name"`
+	Path         string        `mapstructure:"path"` // route Gin exacte
+	TTL          time.Duration `mapstructure:"ttl"`
+	VaryByUser   bool          `mapstructure:"vary_by_user"`
+	Tags         []string      `mapstructure:"tags"`
+	InvalidateOn []string      `mapstructure:"invalidate_on"` // "METHOD /route"
+}
+
+// BootstrapConfig configuration de l'endpoint d'agrégation GET /api/v1/me/bootstrap
+type BootstrapConfig struct {
+	// Délai global de l'agrégation et délai par défaut d'un appel
+	Timeout        time.Duration `mapstructure:"timeout"`
+	SectionTimeout time.Duration `mapstructure:"section_timeout"`
+
+	MaxResponseSize int64 `mapstructure:"max_response_size"` // par section
+
+	// Sections appelées en parallèle, dans l'ordre des dépendances
+	Sections []BootstrapSection `mapstructure:"sections"`
+}
+
+// BootstrapSection appel d'un service dont le résultat forme une section du document
+// Le chemin accepte {user_id}, {character} et {<section>.<champ>} : une valeur lue dans
+// le résultat d'une section précédente (ex. {profile.player.guild_id}).
+type BootstrapSection struct {
+	Name    string        `mapstructure:"name"`
+	Service string        `mapstructure:"service"`
+	Path    string        `mapstructure:"path"`
+	Timeout time.Duration `mapstructure:"timeout"` // 0 : SectionTimeout
+}
+
+// OpenAPIConfig configuration des contrats OpenAPI des services (api/openapi)
+type OpenAPIConfig struct {
+	Enabled bool `mapstructure:"enabled"` // publication sur /gateway/openapi.json
+
+	// Rejet par le gateway des requêtes non conformes au contrat du service
+	Validate    bool  `mapstructure:"validate"`
+	MaxBodySize int64 `mapstructure:"max_body_size"` // corps lu pour la validation
+}
+
+// TracingConfig configuration du tracing distribué (OpenTelemetry)
+// Le contexte de trace est propagé même si l'export est désactivé.
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter"` // otlp, stdout ou file
+	Endpoint    string  `mapstructure:"endpoint"` // host:port du collecteur OTLP/HTTP
+	Insecure    bool    `mapstructure:"insecure"`
+	File        string  `mapstructure:"file"`
+	SampleRatio float64 `mapstructure:"sample_ratio"` // part des nouvelles traces conservées
+}
+
+// TrafficConfig répartition du trafic entre les versions d'un service (canary)
+type TrafficConfig struct {
+	// Header et cookie qui imposent une version (builds de QA), pour tous les services
+	Header string `mapstructure:"header"`
+	Cookie string `mapstructure:"cookie"`
+
+	// Sujet NATS des règles modifiées via l'API d'administration (diffusées aux réplicas)
+	Subject string `mapstructure:"subject"`
+
+	Rules map[string]TrafficRule `mapstructure:"rules"` // par service
+}
+
+// TrafficRule poids des versions d'un service
+// La version de référence reçoit le reste des poids (100 - somme des canaries).
+type TrafficRule struct {
+	Baseline string         `mapstructure:"baseline" json:"baseline"`
+	Weights  map[string]int `mapstructure:"weights" json:"weights"` // version -> pourcentage
+}
+
+// IdempotencyConfig rejeu des écritures retentées par les clients (header Idempotency-Key)
+type IdempotencyConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Header  string   `mapstructure:"header"`
+	Methods []string `mapstructure:"methods"`
+
+	MaxKeyLength    int   `mapstructure:"max_key_length"`
+	MaxBodySize     int64 `mapstructure:"max_body_size"`     // corps de requête lu pour reconnaître une réutilisation de clé
+	MaxResponseSize int64 `mapstructure:"max_response_size"` // au-delà, la réponse n'est pas gardée
+
+	// Durée pendant laquelle la première réponse est rejouée
+	TTL time.Duration `mapstructure:"ttl"`
+	// Durée maximale d'une requête en cours : au-delà, sa clé est considérée abandonnée
+	LockTimeout time.Duration `mapstructure:"lock_timeout"`
+
+	// Store des réponses ; en cas d'erreur du store partagé, repli sur le store local
+	Store           string        `mapstructure:"store"`
+	Bucket          string        `mapstructure:"bucket"`
+	StoreTimeout    time.Duration `mapstructure:"store_timeout"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// InternalRoutesConfig routes réservées aux appels entre services, jamais exposées aux clients
+// Les services vérifient aussi un token de service sur ces routes ; le gateway les refuse
+// en amont pour qu'elles ne soient pas joignables depuis l'extérieur.
+type InternalRoutesConfig struct {
+	BlockedPrefixes []string `mapstructure:"blocked_prefixes"`
+}
+
+// RevocationConfig refus des tokens d'accès révoqués avant leur expiration
+// Le service auth publie les révocations (logout, révocation de session, suspension, ban)
+// sur NATS ; tant que le cache n'a pas écouté pendant toute la durée de vie d'un token
+// (démarrage, reconnexion), l'état des tokens est demandé au service auth par introspection.
+type RevocationConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Subject string `mapstructure:"subject"`
+
+	// Durée de vie maximale d'un token d'accès : durée de rétention des révocations
+	// et d'écoute nécessaire pour que le cache soit complet
+	TokenLifetime time.Duration `mapstructure:"token_lifetime"`
+
+	// Introspection (POST /api/v1/services/introspect) avec un token de service du gateway
+	IntrospectionTimeout time.Duration `mapstructure:"introspection_timeout"`
+	IntrospectionTTL     time.Duration `mapstructure:"introspection_ttl"` // durée de cache d'une réponse
+	ClientID             string        `mapstructure:"client_id"`
+	ClientSecret         string        `mapstructure:"client_secret"`
+	// Service auth injoignable : laisser passer les tokens valides (true) ou répondre 503
+	FailOpen bool `mapstructure:"fail_open"`
+
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// MaintenanceConfig fenêtres de maintenance planifiées et drain des instances
+// Pendant une fenêtre, les requêtes vers la plateforme (ou vers un service) reçoivent 503
+// avec Retry-After, sauf celles des comptes staff ; les clients WebSocket sont prévenus
+// avant le début de la fenêtre.
+type MaintenanceConfig struct {
+	// Sujet NATS des fenêtres planifiées via l'API d'administration (diffusées aux réplicas)
+	Subject     string        `mapstructure:"subject"`
+	SyncTimeout time.Duration `mapstructure:"sync_timeout"` // demande des fenêtres aux autres réplicas au démarrage
+
+	// Préavis envoyés aux clients WebSocket avant le début d'une fenêtre
+	NoticeLeadTimes []time.Duration `mapstructure:"notice_lead_times"`
+
+	// Comptes staff qui gardent l'accès pendant une fenêtre (rôles ou IDs utilisateur)
+	StaffRoles   []string `mapstructure:"staff_roles"`
+	StaffUserIDs []string `mapstructure:"staff_user_ids"`
+	// Routes toujours accessibles (administration du gateway, connexion du staff)
+	ExemptPaths []string `mapstructure:"exempt_paths"`
+
+	MaxDuration  time.Duration `mapstructure:"max_duration"`
+	TickInterval time.Duration `mapstructure:"tick_interval"`
+
+	// Drain d'une instance : attente des requêtes en cours
+	DrainPollInterval time.Duration `mapstructure:"drain_poll_interval"`
+	MaxDrainWait      time.Duration `mapstructure:"max_drain_wait"`
+}
+
+// AbuseConfig détection des abus : score des clients et blocages temporaires
+// Chaque client (IP anonyme ou utilisateur authentifié) accumule un score qui décroît avec
+// le temps ; au-delà du seuil, il est bloqué temporairement. Les décisions sont publiées
+// sur NATS pour l'analytics et appliquées par les autres réplicas.
+type AbuseConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Subject string `mapstructure:"subject"`
+
+	// Listes CIDR : jamais bloqués (allow) ou toujours refusés (deny)
+	AllowCIDRs []string `mapstructure:"allow_cidrs"`
+	DenyCIDRs  []string `mapstructure:"deny_cidrs"`
+	// Routes jamais observées (santé, métriques)
+	ExemptPaths []string `mapstructure:"exempt_paths"`
+
+	BlockThreshold   float64       `mapstructure:"block_threshold"`
+	ScoreHalfLife    time.Duration `mapstructure:"score_half_life"`
+	Window           time.Duration `mapstructure:"window"` // fenêtre d'observation du débit et des 404
+	BlockDuration    time.Duration `mapstructure:"block_duration"`
+	MaxBlockDuration time.Duration `mapstructure:"max_block_duration"`
+
+	// Débit anormal : plus de RateFactor fois le débit habituel du client, et au moins RateMinRequests
+	RateMinRequests int     `mapstructure:"rate_min_requests"`
+	RateFactor      float64 `mapstructure:"rate_factor"`
+	RateWeight      float64 `mapstructure:"rate_weight"`
+	// Rafales d'échecs d'authentification (réponses 401 et 403)
+	AuthFailureWeight float64 `mapstructure:"auth_failure_weight"`
+	// Énumération : chaque chemin distinct en 404 dans la fenêtre
+	EnumerationWeight float64 `mapstructure:"enumeration_weight"`
+	// Rejeu : écriture identique (méthode, chemin, corps) sans clé d'idempotence
+	ReplayWindow time.Duration `mapstructure:"replay_window"`
+	ReplayWeight float64       `mapstructure:"replay_weight"`
+	MaxBodySize  int64         `mapstructure:"max_body_size"` // au-delà, le corps n'entre pas dans la signature
+
+	MaxClients      int           `mapstructure:"max_clients"` // clients suivis en mémoire
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// AuditConfig journal d'audit des requêtes d'écriture
+// Chaque écriture est enregistrée (méthode, route, utilisateur, personnage, statut, latence) ;
+// pour les routes de BodyRoutes, les corps JSON de la requête et de la réponse sont gardés
+// après masquage des chemins de RedactPaths.
+type AuditConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Methods []string `mapstructure:"methods"`
+
+	// Routes dont les corps sont gardés, au format "METHOD /route" (route Gin, ex. "PUT /api/v1/player/characters/:id")
+	BodyRoutes  []string `mapstructure:"body_routes"`
+	MaxBodySize int64    `mapstructure:"max_body_size"` // au-delà, le corps n'est pas gardé
+	// Chemins JSON masqués : clés séparées par des points, "*" pour un niveau quelconque,
+	// "**" pour zéro ou plusieurs niveaux (ex. "**.password", "user.email", "items.*.token")
+	RedactPaths []string `mapstructure:"redact_paths"`
+
+	Sink string `mapstructure:"sink"` // file, nats ou analytics
+
+	// Sink file : rotation par taille
+	File        string `mapstructure:"file"`
+	MaxFileSize int64  `mapstructure:"max_file_size"`
+	MaxBackups  int    `mapstructure:"max_backups"`
+	// Sink nats
+	Subject string `mapstructure:"subject"`
+	// Sink analytics (POST /api/v1/events/ du service analytics)
+	AnalyticsTimeout time.Duration `mapstructure:"analytics_timeout"`
+
+	QueueSize int `mapstructure:"queue_size"` // enregistrements en attente d'écriture, au-delà ils sont perdus
+	// Derniers enregistrements gardés en mémoire pour l'API d'administration (sinks nats et analytics)
+	RecentRecords     int `mapstructure:"recent_records"`
+	DefaultQueryLimit int `mapstructure:"default_query_limit"`
+	MaxQueryLimit     int `mapstructure:"max_query_limit"`
+}
+
+// StrategyFor retourne la stratégie de répartition d'un service
+func (lb LoadBalancingConfig) StrategyFor(service string) string {
+	if strategy, exists := lb.ServiceStrategies[service]; exists {
+		return strategy
+	}
+	return lb.Strategy
+}
+
+// IsValidLBStrategy vérifie qu'une stratégie de répartition est connue
+func IsValidLBStrategy(strategy string) bool {
+	switch strategy {
+	case StrategyRoundRobin, StrategyLeastConnections, StrategyConsistentHash:
+		return true
+	default:
+		return false
+	}
+}
+
 // LoadConfig charge la configuration depuis les variables d'environnement et fichiers
 func LoadConfig() (*Config, error) {
 	// Configuration par défaut - LOCALHOST pour développement
@@ -123,8 +692,9 @@ func LoadConfig() (*Config, error) {
 			WriteTimeout: DefaultServerTimeout * time.Second,
 		},
 		JWT: JWTConfig{
-			Secret:         "your-super-secret-jwt-key-change-in-production-minimum-64-characters",
+			JWKSRefresh:    DefaultJWKSRefresh * time.Minute,
 			ExpirationTime: DefaultJWTExpiration * time.Hour,
+			StaffTwoFactor: true,
 		},
 		Services: ServicesConfig{
 			Auth: ServiceEndpoint{
@@ -170,8 +740,11 @@ func LoadConfig() (*Config, error) {
 		},
 		RateLimit: RateLimitConfig{
 			RequestsPerMinute: DefaultRPM,
-			BurstSize:         DefaultBurstSize,
 			CleanupInterval:   DefaultCleanupInterval * time.Minute,
+			Store:             RateLimitStoreMemory,
+			Bucket:            "gateway_rate_limits",
+			StoreTimeout:      DefaultRateLimitStoreTimeout * time.Millisecond,
+			Policies:          defaultRateLimitPolicies(),
 		},
 		Monitoring: MonitoringConfig{
 			PrometheusPort: DefaultPrometheusPort,
@@ -186,6 +759,179 @@ func LoadConfig() (*Config, error) {
 			ReconnectDelay:       DefaultNATSReconnectDelay * time.Second,
 			MaxReconnectAttempts: DefaultNATSMaxReconnect,
 		},
+		Registry: RegistryConfig{
+			WatchInterval:   DefaultRegistryWatchInterval * time.Second,
+			HeartbeatTTL:    DefaultRegistryHeartbeatTTL * time.Second,
+			CleanupInterval: DefaultRegistryCleanupInterval * time.Second,
+		},
+		LoadBalancing: LoadBalancingConfig{
+			Strategy: StrategyRoundRobin,
+			ServiceStrategies: map[string]string{
+				"combat": StrategyConsistentHash,
+			},
+			HashVirtualNodes:    DefaultLBHashVirtualNodes,
+			HealthCheckPath:     "/health",
+			HealthCheckInterval: DefaultLBHealthCheckInterval * time.Second,
+			HealthCheckTimeout:  DefaultLBHealthCheckTimeout * time.Second,
+			HealthyThreshold:    DefaultLBHealthyThreshold,
+			UnhealthyThreshold:  DefaultLBUnhealthyThreshold,
+			ConsecutiveFailures: DefaultLBConsecutiveFailures,
+			BaseEjectionTime:    DefaultLBBaseEjectionTime * time.Second,
+			MaxEjectionTime:     DefaultLBMaxEjectionTime * time.Second,
+			MaxEjectionPercent:  DefaultLBMaxEjectionPercent,
+		},
+		CircuitBreaker: CircuitBreakerConfig{
+			Window:                DefaultCBWindow * time.Second,
+			MinRequests:           DefaultCBMinRequests,
+			FailureRateThreshold:  DefaultCBFailureRate,
+			SlowCallThreshold:     DefaultCBSlowCallThreshold * time.Millisecond,
+			SlowCallRateThreshold: DefaultCBSlowCallRate,
+			OpenTimeout:           DefaultCBOpenTimeout * time.Second,
+			HalfOpenProbes:        DefaultCBHalfOpenProbes,
+			RetryBudgetRatio:      DefaultRetryBudgetRatio,
+			MinRetries:            DefaultRetryBudgetMinRetries,
+		},
+		Realtime: RealtimeConfig{
+			SubjectPrefix:    "realtime",
+			SendQueueSize:    DefaultWSSendQueueSize,
+			WriteTimeout:     DefaultWSWriteTimeout * time.Second,
+			MaxMessageSize:   DefaultWSMaxMessageSize,
+			PingInterval:     DefaultWSPingInterval * time.Second,
+			PongTimeout:      DefaultWSPongTimeout * time.Second,
+			MaxSubscriptions: DefaultWSMaxSubscriptions,
+			ResumeWindow:     DefaultWSResumeWindow * time.Second,
+			ResumeBufferSize: DefaultWSResumeBufferSize,
+
+			MembershipTimeout: DefaultWSMembershipTimeout * time.Millisecond,
+			MembershipTTL:     DefaultWSMembershipTTL * time.Second,
+		},
+		Cache: CacheConfig{
+			Enabled:            true,
+			MaxEntries:         DefaultCacheMaxEntries,
+			MaxSize:            DefaultCacheMaxSize,
+			MaxEntrySize:       DefaultCacheMaxEntrySize,
+			StaleTTL:           DefaultCacheStaleTTL * time.Second,
+			VaryHeaders:        []string{"Accept", "Accept-Encoding", "Accept-Language", "X-Client-Build"},
+			InvalidationPrefix: cacheinvalidation.DefaultPrefix,
+			Policies:           defaultCachePolicies(),
+		},
+		Bootstrap: BootstrapConfig{
+			Timeout:         DefaultBootstrapTimeout * time.Millisecond,
+			SectionTimeout:  DefaultBootstrapSectionTimeout * time.Millisecond,
+			MaxResponseSize: DefaultBootstrapMaxResponseSize,
+			Sections:        defaultBootstrapSections(),
+		},
+		OpenAPI: OpenAPIConfig{
+			Enabled:     true,
+			Validate:    true,
+			MaxBodySize: DefaultOpenAPIMaxBodySize,
+		},
+		Tracing: TracingConfig{
+			Enabled:     false,
+			Exporter:    TracingExporterOTLP,
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			File:        "traces.json",
+			SampleRatio: DefaultTracingSampleRatio,
+		},
+		Traffic: TrafficConfig{
+			Header:  "X-Client-Build",
+			Cookie:  "client_build",
+			Subject: "gateway.traffic.rules",
+		},
+		Idempotency: IdempotencyConfig{
+			Enabled:         true,
+			Header:          "Idempotency-Key",
+			Methods:         []string{"POST", "PUT", "PATCH", "DELETE"},
+			MaxKeyLength:    DefaultIdempotencyMaxKeyLength,
+			MaxBodySize:     DefaultIdempotencyMaxBodySize,
+			MaxResponseSize: DefaultIdempotencyMaxResponseSize,
+			TTL:             DefaultIdempotencyTTL * time.Hour,
+			LockTimeout:     DefaultIdempotencyLockTimeout * time.Second,
+			Store:           IdempotencyStoreMemory,
+			Bucket:          "gateway_idempotency",
+			StoreTimeout:    DefaultIdempotencyStoreTimeout * time.Millisecond,
+			CleanupInterval: DefaultIdempotencyCleanupInterval * time.Minute,
+		},
+		InternalRoutes: InternalRoutesConfig{
+			BlockedPrefixes: []string{"/api/v1/services", "/services"},
+		},
+		Revocation: RevocationConfig{
+			Enabled:              true,
+			Subject:              "auth.sessions.revoked",
+			TokenLifetime:        DefaultRevocationTokenLifetime * time.Minute,
+			IntrospectionTimeout: DefaultRevocationIntrospectionTimeout * time.Millisecond,
+			IntrospectionTTL:     DefaultRevocationIntrospectionTTL * time.Second,
+			ClientID:             "gateway",
+			ClientSecret:         "gateway-dev-client-secret",
+			FailOpen:             true,
+			CleanupInterval:      DefaultRevocationCleanupInterval * time.Minute,
+		},
+		Maintenance: MaintenanceConfig{
+			Subject:     "gateway.maintenance",
+			SyncTimeout: DefaultMaintenanceSyncTimeout * time.Millisecond,
+			NoticeLeadTimes: []time.Duration{
+				DefaultMaintenanceNoticeFirst * time.Minute,
+				DefaultMaintenanceNoticeSecond * time.Minute,
+				DefaultMaintenanceNoticeLast * time.Minute,
+			},
+			StaffRoles: []string{"admin", "moderator", "superuser"},
+			ExemptPaths: []string{
+				"/gateway", "/health", "/metrics",
+				"/auth/login", "/auth/refresh", "/api/v1/auth/login", "/api/v1/auth/refresh",
+			},
+			MaxDuration:       DefaultMaintenanceMaxDuration * time.Hour,
+			TickInterval:      DefaultMaintenanceTickInterval * time.Second,
+			DrainPollInterval: DefaultMaintenanceDrainPollInterval * time.Millisecond,
+			MaxDrainWait:      DefaultMaintenanceMaxDrainWait * time.Minute,
+		},
+		Abuse: AbuseConfig{
+			Enabled:           true,
+			Subject:           "analytics.abuse.decisions",
+			ExemptPaths:       []string{"/health", "/metrics"},
+			BlockThreshold:    DefaultAbuseBlockThreshold,
+			ScoreHalfLife:     DefaultAbuseScoreHalfLife * time.Minute,
+			Window:            DefaultAbuseWindow * time.Minute,
+			BlockDuration:     DefaultAbuseBlockDuration * time.Minute,
+			MaxBlockDuration:  DefaultAbuseMaxBlockDuration * time.Hour,
+			RateMinRequests:   DefaultAbuseRateMinRequests,
+			RateFactor:        DefaultAbuseRateFactor,
+			RateWeight:        DefaultAbuseRateWeight,
+			AuthFailureWeight: DefaultAbuseAuthFailureWeight,
+			EnumerationWeight: DefaultAbuseEnumerationWeight,
+			ReplayWindow:      DefaultAbuseReplayWindow * time.Second,
+			ReplayWeight:      DefaultAbuseReplayWeight,
+			MaxBodySize:       DefaultAbuseMaxBodySize,
+			MaxClients:        DefaultAbuseMaxClients,
+			CleanupInterval:   DefaultAbuseCleanupInterval * time.Minute,
+		},
+		Audit: AuditConfig{
+			Enabled: true,
+			Methods: []string{"POST", "PUT", "PATCH", "DELETE"},
+			BodyRoutes: []string{
+				"POST /auth/register", "POST /auth/login", "POST /auth/login/2fa",
+				"POST /api/v1/auth/register", "POST /api/v1/auth/login", "POST /api/v1/auth/login/2fa",
+				"PUT /api/v1/user/profile", "POST /api/v1/user/change-password",
+				"POST /api/v1/player/characters", "PUT /api/v1/player/characters/:id",
+				"POST /api/v1/inventory/:characterId/trade",
+			},
+			MaxBodySize: DefaultAuditMaxBodySize,
+			RedactPaths: []string{
+				"**.password", "**.new_password", "**.current_password", "**.old_password",
+				"**.token", "**.access_token", "**.refresh_token", "**.email",
+				"**.code", "**.two_factor_code", "**.challenge_token",
+			},
+			Sink:              AuditSinkFile,
+			File:              "audit.log",
+			MaxFileSize:       DefaultAuditMaxFileSize,
+			MaxBackups:        DefaultAuditMaxBackups,
+			Subject:           "gateway.audit",
+			AnalyticsTimeout:  DefaultAuditAnalyticsTimeout * time.Second,
+			QueueSize:         DefaultAuditQueueSize,
+			RecentRecords:     DefaultAuditRecentRecords,
+			DefaultQueryLimit: DefaultAuditQueryLimit,
+			MaxQueryLimit:     DefaultAuditMaxQueryLimit,
+		},
 	}
 
 	// Charger depuis les variables d'environnement
@@ -210,6 +956,10 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	if config.JWT.JWKSURL == "" {
+		config.JWT.JWKSURL = strings.TrimSuffix(config.Services.Auth.URL, "/") + JWKSPath
+	}
+
 	// Validation de la configuration
 	if err := validateConfig(config); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
@@ -218,6 +968,132 @@ func LoadConfig() (*Config, error) {
 	return config, nil
 }
 
+// defaultRateLimitPolicies politiques par défaut : actions de combat et envoi de messages
+// bien plus limités que les lectures
+func defaultRateLimitPolicies() []RateLimitPolicy {
+	return []RateLimitPolicy{
+		{
+			Name:    "auth",
+			Methods: []string{"POST"},
+			Path:    "/api/v1/auth/*",
+			Window:  time.Minute,
+			Limits:  map[string]int{TierPlayer: DefaultAuthRateLimit, TierService: DefaultServiceRateLimit},
+		},
+		{
+			Name:    "auth-direct",
+			Methods: []string{"POST"},
+			Path:    "/auth/*",
+			Window:  time.Minute,
+			Limits:  map[string]int{TierPlayer: DefaultAuthRateLimit, TierService: DefaultServiceRateLimit},
+		},
+		{
+			Name:    "combat-action",
+			Methods: []string{"POST"},
+			Path:    "/api/v1/combat/*",
+			Window:  DefaultShortRateLimitWindow * time.Second,
+			Limits: map[string]int{
+				TierPlayer:    DefaultCombatRateLimit,
+				TierModerator: DefaultCombatRateLimit,
+				TierAdmin:     DefaultStaffRateLimit,
+				TierService:   DefaultServiceRateLimit,
+			},
+		},
+		{
+			Name:    "chat-send",
+			Methods: []string{"POST"},
+			Path:    "/api/v1/chat/messages",
+			Window:  DefaultShortRateLimitWindow * time.Second,
+			Limits: map[string]int{
+				TierPlayer:    DefaultChatRateLimit,
+				TierModerator: DefaultStaffRateLimit,
+				TierAdmin:     DefaultStaffRateLimit,
+				TierService:   DefaultServiceRateLimit,
+			},
+		},
+		{
+			Name:    "profile-read",
+			Methods: []string{"GET"},
+			Path:    "/api/v1/player/*",
+			Window:  time.Minute,
+			Limits:  map[string]int{TierPlayer: DefaultProfileReadRateLimit, TierService: DefaultServiceRateLimit},
+		},
+	}
+}
+
+// defaultCachePolicies routes de lecture mises en cache : zones, guildes et inventaires
+func defaultCachePolicies() []CachePolicy {
+	return []CachePolicy{
+		{
+			Name: "zones",
+			Path: "/api/v1/world/zones",
+			TTL:  DefaultCacheZonesTTL * time.Second,
+			Tags: []string{"world.zones"},
+		},
+		{
+			Name: "zone",
+			Path: "/api/v1/world/zones/:id",
+			TTL:  DefaultCacheZonesTTL * time.Second,
+			Tags: []string{"world.zone.{id}"},
+		},
+		{
+			Name:         "guilds",
+			Path:         "/api/v1/guild/",
+			TTL:          DefaultCacheGuildTTL * time.Second,
+			Tags:         []string{"guild.list"},
+			InvalidateOn: []string{"POST /api/v1/guild/"},
+		},
+		{
+			Name: "guild",
+			Path: "/api/v1/guild/:id",
+			TTL:  DefaultCacheGuildTTL * time.Second,
+			Tags: []string{"guild.{id}"},
+			InvalidateOn: []string{
+				"POST /api/v1/guild/:id/join",
+				"POST /api/v1/guild/:id/leave",
+			},
+		},
+		{
+			Name: "guild-members",
+			Path: "/api/v1/guild/:id/members",
+			TTL:  DefaultCacheGuildTTL * time.Second,
+			Tags: []string{"guild.{id}"},
+			InvalidateOn: []string{
+				"POST /api/v1/guild/:id/join",
+				"POST /api/v1/guild/:id/leave",
+			},
+		},
+		{
+			Name:       "inventory",
+			Path:       "/api/v1/inventory/:characterId",
+			TTL:        DefaultCacheInventoryTTL * time.Second,
+			VaryByUser: true,
+			Tags:       []string{"inventory.{characterId}"},
+			InvalidateOn: []string{
+				"POST /api/v1/inventory/:characterId/items",
+				"PUT /api/v1/inventory/:characterId/items/:itemId",
+				"DELETE /api/v1/inventory/:characterId/items/:itemId",
+				"POST /api/v1/inventory/:characterId/trade",
+			},
+		},
+	}
+}
+
+// defaultBootstrapSections données chargées par le client à la connexion
+func defaultBootstrapSections() []BootstrapSection {
+	return []BootstrapSection{
+		{Name: "profile", Service: "player", Path: "/api/v1/player/profile"},
+		{Name: "characters", Service: "player", Path: "/api/v1/characters/"},
+		{Name: "character", Service: "player", Path: "/api/v1/characters/{character}"},
+		{Name: "inventory", Service: "inventory", Path: "/api/v1/inventory/{character}"},
+		{Name: "equipment", Service: "inventory", Path: "/api/v1/inventory/{character}/equipment/stats"},
+		{Name: "guild", Service: "guild", Path: "/api/v1/guilds/{profile.player.guild_id}"},
+		{Name: "chat_channels", Service: "chat", Path: "/api/v1/channels"},
+		{Name: "position", Service: "world", Path: "/api/v1/positions/character/{character}"},
+		{Name: "zone", Service: "world", Path: "/api/v1/zones/{position.position.zone_id}"},
+		{Name: "weather", Service: "world", Path: "/api/v1/weather/zone/{position.position.zone_id}"},
+	}
+}
+
 // loadFromEnv charge la configuration depuis les variables d'environnement
 func loadFromEnv(config *Config) {
 	loadServerConfigFromEnv(config)
@@ -225,6 +1101,9 @@ func loadFromEnv(config *Config) {
 	loadServicesConfigFromEnv(config)
 	loadNATSConfigFromEnv(config)
 	loadRateLimitConfigFromEnv(config)
+	loadRegistryConfigFromEnv(config)
+	loadLoadBalancingConfigFromEnv(config)
+	loadTracingConfigFromEnv(config)
 }
 
 // loadServerConfigFromEnv charge la configuration du serveur
@@ -243,12 +1122,28 @@ func loadServerConfigFromEnv(config *Config) {
 	if debug := os.Getenv("DEBUG"); debug != "" {
 		config.Server.Debug = debug == "true"
 	}
+	if proxies := os.Getenv("GATEWAY_TRUSTED_PROXIES"); proxies != "" {
+		config.Server.TrustedProxies = nil
+		for _, proxy := range strings.Split(proxies, ",") {
+			config.Server.TrustedProxies = append(config.Server.TrustedProxies, strings.TrimSpace(proxy))
+		}
+	}
 }
 
 // loadJWTConfigFromEnv charge la configuration JWT
 func loadJWTConfigFromEnv(config *Config) {
-	if secret := os.Getenv("JWT_SECRET"); secret != "" {
-		config.JWT.Secret = secret
+	if jwksURL := os.Getenv("AUTH_JWKS_URL"); jwksURL != "" {
+		config.JWT.JWKSURL = jwksURL
+	}
+	if refresh := os.Getenv("AUTH_JWKS_REFRESH"); refresh != "" {
+		if d, err := time.ParseDuration(refresh); err == nil {
+			config.JWT.JWKSRefresh = d
+		}
+	}
+	if required := os.Getenv("GATEWAY_STAFF_TWO_FACTOR_REQUIRED"); required != "" {
+		if b, err := strconv.ParseBool(required); err == nil {
+			config.JWT.StaffTwoFactor = b
+		}
 	}
 }
 
@@ -295,6 +1190,159 @@ func loadRateLimitConfigFromEnv(config *Config) {
 			config.RateLimit.RequestsPerMinute = r
 		}
 	}
+	if store := os.Getenv("GATEWAY_RATE_LIMIT_STORE"); store != "" {
+		config.RateLimit.Store = store
+	}
+	if enabled := os.Getenv("GATEWAY_CACHE_ENABLED"); enabled != "" {
+		if b, err := strconv.ParseBool(enabled); err == nil {
+			config.Cache.Enabled = b
+		}
+	}
+	if enabled := os.Getenv("GATEWAY_IDEMPOTENCY_ENABLED"); enabled != "" {
+		if b, err := strconv.ParseBool(enabled); err == nil {
+			config.Idempotency.Enabled = b
+		}
+	}
+	if store := os.Getenv("GATEWAY_IDEMPOTENCY_STORE"); store != "" {
+		config.Idempotency.Store = store
+	}
+	if ttl := os.Getenv("GATEWAY_IDEMPOTENCY_TTL"); ttl != "" {
+		if d, err := time.ParseDuration(ttl); err == nil {
+			config.Idempotency.TTL = d
+		}
+	}
+	if enabled := os.Getenv("GATEWAY_REVOCATION_ENABLED"); enabled != "" {
+		if b, err := strconv.ParseBool(enabled); err == nil {
+			config.Revocation.Enabled = b
+		}
+	}
+	if failOpen := os.Getenv("GATEWAY_REVOCATION_FAIL_OPEN"); failOpen != "" {
+		if b, err := strconv.ParseBool(failOpen); err == nil {
+			config.Revocation.FailOpen = b
+		}
+	}
+	if lifetime := os.Getenv("GATEWAY_REVOCATION_TOKEN_LIFETIME"); lifetime != "" {
+		if d, err := time.ParseDuration(lifetime); err == nil {
+			config.Revocation.TokenLifetime = d
+		}
+	}
+	if clientID := os.Getenv("SERVICE_CLIENT_ID"); clientID != "" {
+		config.Revocation.ClientID = clientID
+	}
+	if clientSecret := os.Getenv("SERVICE_CLIENT_SECRET"); clientSecret != "" {
+		config.Revocation.ClientSecret = clientSecret
+	}
+	if roles := os.Getenv("GATEWAY_MAINTENANCE_STAFF_ROLES"); roles != "" {
+		config.Maintenance.StaffRoles = strings.Split(roles, ",")
+	}
+	if users := os.Getenv("GATEWAY_MAINTENANCE_STAFF_USERS"); users != "" {
+		config.Maintenance.StaffUserIDs = strings.Split(users, ",")
+	}
+	if notices := os.Getenv("GATEWAY_MAINTENANCE_NOTICES"); notices != "" {
+		leadTimes := make([]time.Duration, 0)
+		for _, notice := range strings.Split(notices, ",") {
+			if d, err := time.ParseDuration(strings.TrimSpace(notice)); err == nil {
+				leadTimes = append(leadTimes, d)
+			}
+		}
+		config.Maintenance.NoticeLeadTimes = leadTimes
+	}
+	if enabled := os.Getenv("GATEWAY_ABUSE_ENABLED"); enabled != "" {
+		if b, err := strconv.ParseBool(enabled); err == nil {
+			config.Abuse.Enabled = b
+		}
+	}
+	if allow := os.Getenv("GATEWAY_ABUSE_ALLOW_CIDRS"); allow != "" {
+		config.Abuse.AllowCIDRs = strings.Split(allow, ",")
+	}
+	if deny := os.Getenv("GATEWAY_ABUSE_DENY_CIDRS"); deny != "" {
+		config.Abuse.DenyCIDRs = strings.Split(deny, ",")
+	}
+	if threshold := os.Getenv("GATEWAY_ABUSE_BLOCK_THRESHOLD"); threshold != "" {
+		if f, err := strconv.ParseFloat(threshold, 64); err == nil {
+			config.Abuse.BlockThreshold = f
+		}
+	}
+	if duration := os.Getenv("GATEWAY_ABUSE_BLOCK_DURATION"); duration != "" {
+		if d, err := time.ParseDuration(duration); err == nil {
+			config.Abuse.BlockDuration = d
+		}
+	}
+	if enabled := os.Getenv("GATEWAY_AUDIT_ENABLED"); enabled != "" {
+		if b, err := strconv.ParseBool(enabled); err == nil {
+			config.Audit.Enabled = b
+		}
+	}
+	if sink := os.Getenv("GATEWAY_AUDIT_SINK"); sink != "" {
+		config.Audit.Sink = sink
+	}
+	if file := os.Getenv("GATEWAY_AUDIT_FILE"); file != "" {
+		config.Audit.File = file
+	}
+	if routes := os.Getenv("GATEWAY_AUDIT_BODY_ROUTES"); routes != "" {
+		config.Audit.BodyRoutes = strings.Split(routes, ",")
+	}
+	if paths := os.Getenv("GATEWAY_AUDIT_REDACT_PATHS"); paths != "" {
+		config.Audit.RedactPaths = strings.Split(paths, ",")
+	}
+	if prefixes := os.Getenv("GATEWAY_INTERNAL_PREFIXES"); prefixes != "" {
+		config.InternalRoutes.BlockedPrefixes = strings.Split(prefixes, ",")
+	}
+	if validate := os.Getenv("GATEWAY_OPENAPI_VALIDATE"); validate != "" {
+		if b, err := strconv.ParseBool(validate); err == nil {
+			config.OpenAPI.Validate = b
+		}
+	}
+}
+
+// loadRegistryConfigFromEnv charge la configuration du registre des services
+func loadRegistryConfigFromEnv(config *Config) {
+	if file := os.Getenv("GATEWAY_REGISTRY_FILE"); file != "" {
+		config.Registry.File = file
+	}
+	if ttl := os.Getenv("GATEWAY_REGISTRY_TTL"); ttl != "" {
+		if d, err := time.ParseDuration(ttl); err == nil {
+			config.Registry.HeartbeatTTL = d
+		}
+	}
+	if token := os.Getenv("GATEWAY_REGISTRY_TOKEN"); token != "" {
+		config.Registry.Token = token
+	}
+}
+
+// loadLoadBalancingConfigFromEnv charge la configuration de la répartition de charge
+func loadLoadBalancingConfigFromEnv(config *Config) {
+	if strategy := os.Getenv("GATEWAY_LB_STRATEGY"); strategy != "" {
+		config.LoadBalancing.Strategy = strategy
+	}
+	if interval := os.Getenv("GATEWAY_LB_HEALTH_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil {
+			config.LoadBalancing.HealthCheckInterval = d
+		}
+	}
+}
+
+// loadTracingConfigFromEnv charge la configuration du tracing (variables communes aux services)
+func loadTracingConfigFromEnv(config *Config) {
+	if enabled := os.Getenv("TRACING_ENABLED"); enabled != "" {
+		if b, err := strconv.ParseBool(enabled); err == nil {
+			config.Tracing.Enabled = b
+		}
+	}
+	if exporter := os.Getenv("TRACING_EXPORTER"); exporter != "" {
+		config.Tracing.Exporter = exporter
+	}
+	if endpoint := os.Getenv("TRACING_ENDPOINT"); endpoint != "" {
+		config.Tracing.Endpoint = endpoint
+	}
+	if file := os.Getenv("TRACING_FILE"); file != "" {
+		config.Tracing.File = file
+	}
+	if ratio := os.Getenv("TRACING_SAMPLE_RATIO"); ratio != "" {
+		if r, err := strconv.ParseFloat(ratio, 64); err == nil {
+			config.Tracing.SampleRatio = r
+		}
+	}
 }
 
 // validateConfig valide la configuration
@@ -303,25 +1351,19 @@ func validateConfig(config *Config) error {
 	if config.Server.Port < 1 || config.Server.Port > 65535 {
 		return fmt.Errorf("invalid server port: %d", config.Server.Port)
 	}
+	for _, proxy := range config.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("trusted proxy %q is neither an IP nor a CIDR", proxy)
+		}
+	}
 
 	// Validation JWT
-	if len(config.JWT.Secret) < MinJWTSecretLength {
-		return fmt.Errorf("JWT secret must be at least %d characters long", MinJWTSecretLength)
+	if config.JWT.JWKSURL == "" || config.JWT.JWKSRefresh <= 0 {
+		return fmt.Errorf("JWKS URL and a positive refresh interval are required")
 	}
 
 	// Validation des services
-	services := map[string]ServiceEndpoint{
-		"auth":      config.Services.Auth,
-		"player":    config.Services.Player,
-		"world":     config.Services.World,
-		"combat":    config.Services.Combat,
-		"inventory": config.Services.Inventory,
-		"guild":     config.Services.Guild,
-		"chat":      config.Services.Chat,
-		"analytics": config.Services.Analytics,
-	}
-
-	for name, service := range services {
+	for name, service := range config.Services.Endpoints() {
 		if service.URL == "" {
 			return fmt.Errorf("service %s URL is required", name)
 		}
@@ -339,9 +1381,467 @@ func validateConfig(config *Config) error {
 	}
 
 	// Validation rate limiting
-	if config.RateLimit.RequestsPerMinute <= 0 {
+	if err := validateRateLimitConfig(&config.RateLimit); err != nil {
+		return err
+	}
+
+	// Validation du registre
+	if config.Registry.WatchInterval <= 0 || config.Registry.HeartbeatTTL <= 0 || config.Registry.CleanupInterval <= 0 {
+		return fmt.Errorf("registry intervals and heartbeat TTL must be positive")
+	}
+
+	if err := validateLoadBalancingConfig(&config.LoadBalancing); err != nil {
+		return err
+	}
+
+	if err := validateCircuitBreakerConfig(&config.CircuitBreaker); err != nil {
+		return err
+	}
+
+	if err := validateRealtimeConfig(&config.Realtime); err != nil {
+		return err
+	}
+
+	if err := validateCacheConfig(&config.Cache); err != nil {
+		return err
+	}
+
+	if err := validateBootstrapConfig(&config.Bootstrap, config.Services.Endpoints()); err != nil {
+		return err
+	}
+
+	// Validation des contrats OpenAPI
+	if config.OpenAPI.Validate && config.OpenAPI.MaxBodySize <= 0 {
+		return fmt.Errorf("openapi max body size must be positive")
+	}
+
+	if err := validateTrafficConfig(&config.Traffic); err != nil {
+		return err
+	}
+
+	if err := validateIdempotencyConfig(&config.Idempotency); err != nil {
+		return err
+	}
+
+	if err := validateRevocationConfig(&config.Revocation); err != nil {
+		return err
+	}
+
+	for _, prefix := range config.InternalRoutes.BlockedPrefixes {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("internal route prefix %q must start with /", prefix)
+		}
+	}
+
+	if err := validateMaintenanceConfig(&config.Maintenance); err != nil {
+		return err
+	}
+
+	if err := validateAbuseConfig(&config.Abuse); err != nil {
+		return err
+	}
+
+	if err := validateAuditConfig(&config.Audit); err != nil {
+		return err
+	}
+
+	return validateTracingConfig(&config.Tracing)
+}
+
+// validateTrafficConfig valide les règles de répartition entre versions
+func validateTrafficConfig(tc *TrafficConfig) error {
+	for service, rule := range tc.Rules {
+		if err := ValidateTrafficRule(&rule); err != nil {
+			return fmt.Errorf("traffic rule %s: %w", service, err)
+		}
+	}
+	return nil
+}
+
+// ValidateTrafficRule vérifie la version de référence et les poids d'une règle
+func ValidateTrafficRule(rule *TrafficRule) error {
+	if rule.Baseline == "" {
+		return fmt.Errorf("baseline version is required")
+	}
+	total := 0
+	for version, weight := range rule.Weights {
+		if version == "" || version == rule.Baseline {
+			return fmt.Errorf("weights must name canary versions other than the baseline")
+		}
+		if weight < 0 || weight > MaxPercent {
+			return fmt.Errorf("weight of version %s must be between 0 and %d", version, MaxPercent)
+		}
+		total += weight
+	}
+	if total > MaxPercent {
+		return fmt.Errorf("canary weights add up to %d%%, more than %d%%", total, MaxPercent)
+	}
+	return nil
+}
+
+// validateTracingConfig valide l'exporteur et l'échantillonnage des traces
+func validateTracingConfig(tc *TracingConfig) error {
+	if !tc.Enabled {
+		return nil
+	}
+	switch tc.Exporter {
+	case TracingExporterOTLP:
+		if tc.Endpoint == "" {
+			return fmt.Errorf("tracing endpoint is required for the otlp exporter")
+		}
+	case TracingExporterFile:
+		if tc.File == "" {
+			return fmt.Errorf("tracing file is required for the file exporter")
+		}
+	case TracingExporterStdout:
+	default:
+		return fmt.Errorf("invalid tracing exporter: %s", tc.Exporter)
+	}
+	if tc.SampleRatio < 0 || tc.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be between 0 and 1")
+	}
+	return nil
+}
+
+// validateBootstrapConfig valide les sections du bootstrap
+// Une section ne peut dépendre que d'une section déclarée avant elle.
+func validateBootstrapConfig(bc *BootstrapConfig, services map[string]ServiceEndpoint) error {
+	if bc.Timeout <= 0 || bc.SectionTimeout <= 0 || bc.MaxResponseSize <= 0 {
+		return fmt.Errorf("bootstrap timeouts and response size must be positive")
+	}
+
+	declared := make(map[string]bool)
+	for _, section := range bc.Sections {
+		if section.Name == "" || section.Path == "" {
+			return fmt.Errorf("bootstrap section name and path are required")
+		}
+		if declared[section.Name] {
+			return fmt.Errorf("bootstrap section %s is declared twice", section.Name)
+		}
+		if _, exists := services[section.Service]; !exists {
+			return fmt.Errorf("bootstrap section %s: unknown service %q", section.Name, section.Service)
+		}
+		for _, placeholder := range PathPlaceholders(section.Path) {
+			dependency, _, isReference := strings.Cut(placeholder, ".")
+			if isReference && !declared[dependency] {
+				return fmt.Errorf("bootstrap section %s depends on %s, which must be declared before it", section.Name, dependency)
+			}
+			if !isReference && placeholder != "user_id" && placeholder != "character" {
+				return fmt.Errorf("bootstrap section %s: unknown placeholder {%s}", section.Name, placeholder)
+			}
+		}
+		declared[section.Name] = true
+	}
+
+	return nil
+}
+
+// PathPlaceholders retourne les noms entre accolades d'un chemin
+func PathPlaceholders(path string) []string {
+	var placeholders []string
+	for {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			return placeholders
+		}
+		end := strings.IndexByte(path[start:], '}')
+		if end < 0 {
+			return placeholders
+		}
+		placeholders = append(placeholders, path[start+1:start+end])
+		path = path[start+end+1:]
+	}
+}
+
+// validateCacheConfig valide la configuration du cache des réponses
+func validateCacheConfig(cc *CacheConfig) error {
+	if !cc.Enabled {
+		return nil
+	}
+	if cc.MaxEntries <= 0 || cc.MaxSize <= 0 || cc.MaxEntrySize <= 0 || cc.MaxEntrySize > cc.MaxSize {
+		return fmt.Errorf("cache sizes must be positive and an entry must fit in the cache")
+	}
+	if cc.StaleTTL < 0 {
+		return fmt.Errorf("cache stale TTL must be non-negative")
+	}
+	if cc.InvalidationPrefix == "" {
+		return fmt.Errorf("cache invalidation prefix is required")
+	}
+	for _, policy := range cc.Policies {
+		if policy.Name == "" || policy.Path == "" {
+			return fmt.Errorf("cache policy name and path are required")
+		}
+		if policy.TTL <= 0 {
+			return fmt.Errorf("cache policy %s TTL must be positive", policy.Name)
+		}
+		for _, route := range policy.InvalidateOn {
+			if len(strings.Fields(route)) != 2 {
+				return fmt.Errorf("cache policy %s: invalid write route %q (expected \"METHOD /route\")", policy.Name, route)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateRevocationConfig valide la configuration de la révocation des tokens
+func validateRevocationConfig(rc *RevocationConfig) error {
+	if !rc.Enabled {
+		return nil
+	}
+	if rc.TokenLifetime <= 0 || rc.IntrospectionTimeout <= 0 || rc.IntrospectionTTL <= 0 || rc.CleanupInterval <= 0 {
+		return fmt.Errorf("revocation durations must be positive")
+	}
+	if rc.ClientID == "" || rc.ClientSecret == "" {
+		return fmt.Errorf("revocation introspection requires a service client ID and secret")
+	}
+	return nil
+}
+
+// validateMaintenanceConfig valide la configuration des fenêtres de maintenance
+func validateMaintenanceConfig(mc *MaintenanceConfig) error {
+	if mc.MaxDuration <= 0 || mc.TickInterval <= 0 || mc.SyncTimeout <= 0 {
+		return fmt.Errorf("maintenance durations must be positive")
+	}
+	if mc.DrainPollInterval <= 0 || mc.MaxDrainWait <= 0 {
+		return fmt.Errorf("maintenance drain durations must be positive")
+	}
+	for _, leadTime := range mc.NoticeLeadTimes {
+		if leadTime <= 0 {
+			return fmt.Errorf("maintenance notice lead time %s must be positive", leadTime)
+		}
+	}
+	for _, path := range mc.ExemptPaths {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("maintenance exempt path %q must start with /", path)
+		}
+	}
+	return nil
+}
+
+// validateAbuseConfig valide la configuration de la détection des abus
+func validateAbuseConfig(ac *AbuseConfig) error {
+	if !ac.Enabled {
+		return nil
+	}
+	if ac.BlockThreshold <= 0 || ac.RateFactor <= 0 || ac.RateMinRequests <= 0 {
+		return fmt.Errorf("abuse threshold, rate factor and rate minimum must be positive")
+	}
+	if ac.ScoreHalfLife <= 0 || ac.Window <= 0 || ac.ReplayWindow <= 0 || ac.CleanupInterval <= 0 {
+		return fmt.Errorf("abuse durations must be positive")
+	}
+	if ac.BlockDuration <= 0 || ac.MaxBlockDuration < ac.BlockDuration {
+		return fmt.Errorf("abuse block duration must be positive and at most the max block duration")
+	}
+	if ac.RateWeight < 0 || ac.AuthFailureWeight < 0 || ac.EnumerationWeight < 0 || ac.ReplayWeight < 0 {
+		return fmt.Errorf("abuse signal weights must not be negative")
+	}
+	if ac.MaxBodySize < 0 || ac.MaxClients <= 0 {
+		return fmt.Errorf("abuse max body size and max clients must be positive")
+	}
+	for _, cidrs := range [][]string{ac.AllowCIDRs, ac.DenyCIDRs} {
+		for _, cidr := range cidrs {
+			if _, _, err := net.ParseCIDR(strings.TrimSpace(cidr)); err != nil {
+				return fmt.Errorf("abuse CIDR %q: %w", cidr, err)
+			}
+		}
+	}
+	for _, path := range ac.ExemptPaths {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("abuse exempt path %q must start with /", path)
+		}
+	}
+	return nil
+}
+
+// validateAuditConfig valide la configuration du journal d'audit
+func validateAuditConfig(ac *AuditConfig) error {
+	if !ac.Enabled {
+		return nil
+	}
+	if len(ac.Methods) == 0 {
+		return fmt.Errorf("audit methods are required")
+	}
+	switch ac.Sink {
+	case AuditSinkFile:
+		if ac.File == "" || ac.MaxFileSize <= 0 || ac.MaxBackups < 0 {
+			return fmt.Errorf("audit file sink requires a file, a positive max file size and non-negative backups")
+		}
+	case AuditSinkNATS:
+		if ac.Subject == "" {
+			return fmt.Errorf("audit nats sink requires a subject")
+		}
+	case AuditSinkAnalytics:
+		if ac.AnalyticsTimeout <= 0 {
+			return fmt.Errorf("audit analytics timeout must be positive")
+		}
+	default:
+		return fmt.Errorf("invalid audit sink %q (expected %s, %s or %s)", ac.Sink, AuditSinkFile, AuditSinkNATS, AuditSinkAnalytics)
+	}
+	if ac.MaxBodySize < 0 || ac.QueueSize <= 0 || ac.RecentRecords < 0 {
+		return fmt.Errorf("audit body size, queue size and recent records must be positive")
+	}
+	if ac.DefaultQueryLimit <= 0 || ac.MaxQueryLimit < ac.DefaultQueryLimit {
+		return fmt.Errorf("audit query limits must be positive, with the max at least the default")
+	}
+	for _, route := range ac.BodyRoutes {
+		if len(strings.Fields(route)) != 2 {
+			return fmt.Errorf("audit body route %q: expected \"METHOD /route\"", route)
+		}
+	}
+	for _, path := range ac.RedactPaths {
+		if path = strings.TrimSpace(path); path == "" || path == "**" || strings.Contains(path, "..") {
+			return fmt.Errorf("invalid audit redact path %q", path)
+		}
+	}
+	return nil
+}
+
+// validateIdempotencyConfig valide la configuration des clés d'idempotence
+func validateIdempotencyConfig(ic *IdempotencyConfig) error {
+	if !ic.Enabled {
+		return nil
+	}
+	if ic.Header == "" || len(ic.Methods) == 0 {
+		return fmt.Errorf("idempotency header and methods are required")
+	}
+	if ic.Store != IdempotencyStoreMemory && ic.Store != IdempotencyStoreNATS {
+		return fmt.Errorf("unknown idempotency store %q", ic.Store)
+	}
+	if ic.MaxKeyLength <= 0 || ic.MaxBodySize <= 0 || ic.MaxResponseSize <= 0 {
+		return fmt.Errorf("idempotency key length, body size and response size must be positive")
+	}
+	// Une clé abandonnée doit pouvoir être reprise avant l'expiration de la réponse
+	if ic.LockTimeout <= 0 || ic.TTL < ic.LockTimeout {
+		return fmt.Errorf("idempotency TTL must be at least the lock timeout")
+	}
+	if ic.StoreTimeout <= 0 || ic.CleanupInterval <= 0 {
+		return fmt.Errorf("idempotency store timeout and cleanup interval must be positive")
+	}
+
+	return nil
+}
+
+// validateRealtimeConfig valide la configuration du hub WebSocket
+func validateRealtimeConfig(rt *RealtimeConfig) error {
+	if rt.SubjectPrefix == "" {
+		return fmt.Errorf("realtime subject prefix is required")
+	}
+	if rt.SendQueueSize <= 0 || rt.MaxMessageSize <= 0 || rt.MaxSubscriptions <= 0 {
+		return fmt.Errorf("realtime queue size, message size and subscriptions must be positive")
+	}
+	if rt.PingInterval <= 0 || rt.PongTimeout <= rt.PingInterval || rt.WriteTimeout <= 0 {
+		return fmt.Errorf("realtime pong timeout must be longer than the ping interval")
+	}
+	if rt.ResumeWindow <= 0 || rt.ResumeBufferSize <= 0 {
+		return fmt.Errorf("realtime resume window and buffer size must be positive")
+	}
+	// Les événements rejoués à la reprise doivent tenir dans la file d'envoi
+	if rt.ResumeBufferSize >= rt.SendQueueSize {
+		return fmt.Errorf("realtime resume buffer must be smaller than the send queue")
+	}
+	if rt.MembershipTimeout <= 0 || rt.MembershipTTL <= 0 {
+		return fmt.Errorf("realtime membership timeout and ttl must be positive")
+	}
+
+	return nil
+}
+
+// validateRateLimitConfig valide la configuration du rate limiting
+func validateRateLimitConfig(rl *RateLimitConfig) error {
+	if rl.RequestsPerMinute <= 0 {
 		return fmt.Errorf("rate limit requests per minute must be positive")
 	}
+	if rl.Store != RateLimitStoreMemory && rl.Store != RateLimitStoreNATS {
+		return fmt.Errorf("unknown rate limit store %q", rl.Store)
+	}
+	if rl.StoreTimeout <= 0 || rl.CleanupInterval <= 0 {
+		return fmt.Errorf("rate limit store timeout and cleanup interval must be positive")
+	}
+
+	for _, policy := range rl.Policies {
+		if policy.Name == "" || policy.Path == "" {
+			return fmt.Errorf("rate limit policies require a name and a path")
+		}
+		if policy.Window < time.Second {
+			return fmt.Errorf("rate limit policy %s window must be at least 1s", policy.Name)
+		}
+		if policy.Limits[TierPlayer] <= 0 {
+			return fmt.Errorf("rate limit policy %s requires a positive %s limit", policy.Name, TierPlayer)
+		}
+		for tier, limit := range policy.Limits {
+			if !IsValidRateLimitTier(tier) || limit <= 0 {
+				return fmt.Errorf("rate limit policy %s has an invalid limit for tier %q", policy.Name, tier)
+			}
+		}
+	}
+
+	for _, override := range rl.Overrides {
+		if override.UserID == "" || override.Multiplier < 0 {
+			return fmt.Errorf("rate limit overrides require a user ID and a non-negative multiplier")
+		}
+	}
+
+	return nil
+}
+
+// IsValidRateLimitTier indique si un tier de rate limiting est connu
+func IsValidRateLimitTier(tier string) bool {
+	switch tier {
+	case TierAnonymous, TierPlayer, TierModerator, TierAdmin, TierService:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateCircuitBreakerConfig valide la configuration des circuit breakers
+func validateCircuitBreakerConfig(cb *CircuitBreakerConfig) error {
+	if cb.Window < time.Second {
+		return fmt.Errorf("circuit breaker window must be at least 1s")
+	}
+	if cb.MinRequests <= 0 || cb.HalfOpenProbes <= 0 {
+		return fmt.Errorf("circuit breaker min requests and half-open probes must be positive")
+	}
+	if cb.FailureRateThreshold <= 0 || cb.FailureRateThreshold > 1 ||
+		cb.SlowCallRateThreshold <= 0 || cb.SlowCallRateThreshold > 1 {
+		return fmt.Errorf("circuit breaker rate thresholds must be in (0, 1]")
+	}
+	if cb.SlowCallThreshold <= 0 || cb.OpenTimeout <= 0 {
+		return fmt.Errorf("circuit breaker slow call threshold and open timeout must be positive")
+	}
+	if cb.RetryBudgetRatio < 0 || cb.MinRetries < 0 {
+		return fmt.Errorf("retry budget must be non-negative")
+	}
+
+	return nil
+}
+
+// validateLoadBalancingConfig valide la configuration de la répartition de charge
+func validateLoadBalancingConfig(lb *LoadBalancingConfig) error {
+	if !IsValidLBStrategy(lb.Strategy) {
+		return fmt.Errorf("unknown load balancing strategy %q", lb.Strategy)
+	}
+	for service, strategy := range lb.ServiceStrategies {
+		if !IsValidLBStrategy(strategy) {
+			return fmt.Errorf("unknown load balancing strategy %q for service %s", strategy, service)
+		}
+	}
+	if lb.HashVirtualNodes <= 0 {
+		return fmt.Errorf("hash virtual nodes must be positive")
+	}
+	if lb.HealthCheckInterval <= 0 || lb.HealthCheckTimeout <= 0 {
+		return fmt.Errorf("health check interval and timeout must be positive")
+	}
+	if lb.HealthyThreshold <= 0 || lb.UnhealthyThreshold <= 0 || lb.ConsecutiveFailures <= 0 {
+		return fmt.Errorf("health thresholds must be positive")
+	}
+	if lb.BaseEjectionTime <= 0 || lb.MaxEjectionTime < lb.BaseEjectionTime {
+		return fmt.Errorf("ejection times must be positive and max ejection time >= base ejection time")
+	}
+	if lb.MaxEjectionPercent < 0 || lb.MaxEjectionPercent > MaxPercent {
+		return fmt.Errorf("max ejection percent must be between 0 and %d", MaxPercent)
+	}
 
 	return nil
 }
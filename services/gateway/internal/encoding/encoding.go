@@ -0,0 +1,64 @@
+// Package encoding encodages binaires du chemin critique : MessagePack et Protobuf (schéma
+// partagé api/proto/game.proto)
+// Sur HTTP, le gateway transmet Accept et Content-Type aux services qui négocient eux-mêmes ;
+// le hub WebSocket encode ses messages dans l'encodage choisi à la connexion.
+package encoding
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ugorji/go/codec"
+)
+
+// Format encodage d'un message
+type Format string
+
+// Encodages supportés
+const (
+	FormatJSON     Format = "json"
+	FormatMsgPack  Format = "msgpack"
+	FormatProtobuf Format = "protobuf"
+)
+
+// Formats encodages supportés, dans l'ordre de préférence du serveur
+var Formats = []Format{FormatJSON, FormatMsgPack, FormatProtobuf}
+
+// ParseFormat reconnaît le nom d'un encodage
+func ParseFormat(name string) (Format, bool) {
+	for _, format := range Formats {
+		if string(format) == name {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// msgpackHandle encodage MessagePack : noms des tags json, chaînes et binaires distincts
+// (spécification actuelle), horodatages en extension timestamp, UUID en binaire de 16 octets
+var msgpackHandle = newMsgPackHandle()
+
+// newMsgPackHandle configure l'encodage MessagePack (identique dans le gateway et les services)
+func newMsgPackHandle() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{WriteExt: true}
+	h.RawToString = true
+	h.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	return h
+}
+
+// MarshalMsgPack encode une valeur en MessagePack
+func MarshalMsgPack(v interface{}) ([]byte, error) {
+	var data []byte
+	if err := codec.NewEncoderBytes(&data, msgpackHandle).Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode msgpack: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalMsgPack décode une valeur MessagePack
+func UnmarshalMsgPack(data []byte, v interface{}) error {
+	if err := codec.NewDecoderBytes(data, msgpackHandle).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode msgpack: %w", err)
+	}
+	return nil
+}
@@ -1,13 +1,22 @@
 package gateway
 
 import (
-	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"gateway/internal/balancer"
 	"gateway/internal/config"
+	"gateway/internal/encoding"
+	"gateway/internal/maintenance"
+	"gateway/internal/middleware"
+	"gateway/internal/openapi"
 	"gateway/internal/proxy"
+	"gateway/internal/realtime"
+	"gateway/internal/registry"
+	"gateway/internal/traffic"
+	"mmorpg/pkg/jwks"
+	"mmorpg/pkg/serviceauth"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -27,53 +36,50 @@ const (
 	WebSocketReadBufferSize  = 1024
 	WebSocketWriteBufferSize = 1024
 
-	// Monitoring et Health Check
-	HealthCheckInterval      = 30 // secondes
-	ServiceHealthTimeout     = 5  // secondes
-	MaxServiceErrors         = 5
-	MaxCriticalServiceErrors = 3
+	// Part minimale de services disponibles pour un état global sain
+	HealthyServicesRatio = 0.8
 )
 
 // Server reprÃ©sente le serveur Gateway
 type Server struct {
-	config        *config.Config
-	proxy         *proxy.ServiceProxy
-	natsConn      *nats.Conn
-	upgrader      websocket.Upgrader
-	wsClients     map[*websocket.Conn]bool
-	wsMutex       sync.RWMutex
-	serviceHealth map[string]ServiceHealth
-	healthMutex   sync.RWMutex
-}
+	config   *config.Config
+	proxy    *proxy.ServiceProxy
+	registry *registry.Registry
+	balancer *balancer.Balancer
+	natsConn *nats.Conn
+	upgrader websocket.Upgrader
+	hub      *realtime.Hub
 
-// ServiceHealth reprÃ©sente l'Ã©tat de santÃ© d'un service
-type ServiceHealth struct {
-	Name         string    `json:"name"`
-	URL          string    `json:"url"`
-	Status       string    `json:"status"` // StatusHealthy, StatusUnhealthy, StatusUnknown
-	LastCheck    time.Time `json:"last_check"`
-	ResponseTime int64     `json:"response_time_ms"`
-	ErrorCount   int       `json:"error_count"`
-}
+	// Contrats OpenAPI des services (nil : requêtes transmises sans validation)
+	validator *openapi.Validator
 
-// NewServer crÃ©e une nouvelle instance du serveur Gateway
-func NewServer(cfg *config.Config) (*Server, error) {
-	// Initialisation du proxy de services
-	serviceProxy, err := proxy.NewServiceProxy(cfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create service proxy: %w", err)
-	}
+	// Répartition du trafic entre les versions des services (canary)
+	traffic *traffic.Splitter
 
-	// connection Ã  NATS
-	natsConn, err := connectToNATS(cfg.NATS)
-	if err != nil {
-		logrus.Warn("Failed to connect to NATS, continuing without messaging: ", err)
-	}
+	// Fenêtres de maintenance (503 pendant la fenêtre, annonces aux clients WebSocket)
+	maintenance *maintenance.Scheduler
+
+	// Clés publiques du service auth (identification du staff pendant une maintenance)
+	keys *jwks.KeySet
+}
 
+// NewServer crÃ©e une nouvelle instance du serveur Gateway
+func NewServer(
+	cfg *config.Config,
+	natsConn *nats.Conn,
+	serviceProxy *proxy.ServiceProxy,
+	serviceRegistry *registry.Registry,
+	lb *balancer.Balancer,
+	validator *openapi.Validator,
+	splitter *traffic.Splitter,
+	scheduler *maintenance.Scheduler,
+	keys *jwks.KeySet,
+) (*Server, error) {
 	// Configuration du WebSocket upgrader
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:  WebSocketReadBufferSize,
 		WriteBufferSize: WebSocketWriteBufferSize,
+		Subprotocols:    realtime.Subprotocols(),
 		CheckOrigin: func(r *http.Request) bool {
 			// En production, vÃ©rifier l'origine
 			if cfg.Server.Environment == EnvProduction {
@@ -84,17 +90,31 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		},
 	}
 
+	// Appartenance aux topics, avec l'identité de service du gateway
+	membershipTokens := serviceauth.NewTokenSource(
+		cfg.Services.Auth.URL, cfg.Revocation.ClientID, cfg.Revocation.ClientSecret, cfg.Realtime.MembershipTimeout,
+	)
+	membership := realtime.NewMembershipChecker(&cfg.Realtime, &cfg.Services, membershipTokens)
+
 	server := &Server{
-		config:        cfg,
-		proxy:         serviceProxy,
-		natsConn:      natsConn,
-		upgrader:      upgrader,
-		wsClients:     make(map[*websocket.Conn]bool),
-		serviceHealth: make(map[string]ServiceHealth),
+		config:   cfg,
+		proxy:    serviceProxy,
+		registry: serviceRegistry,
+		balancer: lb,
+		natsConn: natsConn,
+		upgrader: upgrader,
+		hub:      realtime.NewHub(&cfg.Realtime, natsConn, membership),
+
+		validator:   validator,
+		traffic:     splitter,
+		maintenance: scheduler,
+		keys:        keys,
 	}
 
-	// initializer le monitoring des services
-	server.initServiceHealthMonitoring()
+	if err := server.hub.Start(); err != nil {
+		return nil, err
+	}
+	scheduler.OnNotice(server.announceMaintenance)
 
 	logrus.Info("Gateway server initialized successfully")
 	return server, nil
@@ -103,38 +123,103 @@ func NewServer(cfg *config.Config) (*Server, error) {
 // ProxyTo retourne un handler Gin qui proxie vers un service spÃ©cifique
 func (s *Server) ProxyTo(serviceName string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// RÃ©cupÃ©rer l'endpoint du service
-		endpoint, exists := s.getServiceEndpoint(serviceName)
-		if !exists {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"error": fmt.Sprintf("Service %s not available", serviceName),
-			})
+		// Service en maintenance : 503 jusqu'à la fin de la fenêtre, sauf pour le staff
+		if window := s.maintenance.Active(serviceName, time.Now()); window != nil &&
+			!middleware.MaintenanceExempt(c, s.maintenance, s.keys, s.config.JWT.StaffTwoFactor) {
+			middleware.AbortMaintenance(c, window)
 			return
 		}
 
-		// VÃ©rifier la santÃ© du service
-		if !s.isServiceHealthy(serviceName) {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"error": fmt.Sprintf("Service %s is unhealthy", serviceName),
+		// Rejeter les requêtes non conformes au contrat avant d'occuper une instance
+		if !s.validateRequest(c, serviceName) {
+			return
+		}
+
+		// Choisir une instance disponible du service, dans la version attribuée au client
+		target, err := s.pickTarget(c, serviceName)
+		if err != nil {
+			status := http.StatusServiceUnavailable
+			message := fmt.Sprintf("Service %s not available", serviceName)
+			if !errors.Is(err, balancer.ErrNoInstance) {
+				status = http.StatusInternalServerError
+			}
+			middleware.MarkNotForwarded(c)
+			c.JSON(status, gin.H{
+				"error":      message,
+				"request_id": c.GetHeader("X-Request-ID"),
 			})
 			return
 		}
 
 		// Proxier la requÃªte
-		if err := s.proxy.Forward(c, endpoint); err != nil {
-			logrus.WithError(err).WithField("service", serviceName).Error("Proxy request failed")
-
-			// Marquer le service comme potentiellement dÃ©faillant
-			s.incrementServiceError(serviceName)
+		c.Header("X-Service-Version", target.Version)
+		start := time.Now()
+		err = s.proxy.Forward(c, serviceName, target.Endpoint)
+		if errors.Is(err, proxy.ErrCircuitOpen) {
+			// Requête jamais envoyée : le circuit breaker protège déjà l'instance
+			target.Release()
+			middleware.MarkNotForwarded(c)
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":      fmt.Sprintf("Service %s temporarily unavailable", serviceName),
+				"message":    "Circuit breaker is open",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+		// Une erreur du service transmise au client compte comme un échec de l'instance
+		result := err
+		if result == nil && c.Writer.Status() >= proxy.ServerErrorThreshold {
+			result = fmt.Errorf("service returned status %d", c.Writer.Status())
+		}
+		target.Done(result)
+		s.traffic.Record(serviceName, target.Version, c.Writer.Status(), err, time.Since(start))
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"service":  serviceName,
+				"instance": target.InstanceID,
+			}).Error("Proxy request failed")
 
+			if errors.Is(err, proxy.ErrNotSent) {
+				middleware.MarkNotForwarded(c)
+			}
 			c.JSON(http.StatusBadGateway, gin.H{
-				"error": "Service request failed",
+				"error":      "Service request failed",
+				"request_id": c.GetHeader("X-Request-ID"),
 			})
 			return
 		}
 	}
 }
 
+// validateRequest valide la requête contre le contrat OpenAPI du service
+// Retourne false après avoir répondu 400 si la requête n'est pas conforme.
+func (s *Server) validateRequest(c *gin.Context, serviceName string) bool {
+	if s.validator == nil || !s.validator.HasService(serviceName) {
+		return true
+	}
+
+	targetPath := s.proxy.TargetPath(c.Request.URL.Path)
+	violations, err := s.validator.ValidateRequest(serviceName, c.Request, targetPath, s.config.OpenAPI.MaxBodySize)
+	if err != nil {
+		logrus.WithError(err).WithField("service", serviceName).Warn("Failed to read request for validation")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Invalid request body",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return false
+	}
+	if len(violations) == 0 {
+		return true
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":      "Request does not match the service contract",
+		"details":    violations,
+		"request_id": c.GetHeader("X-Request-ID"),
+	})
+	return false
+}
+
 // HealthCheck endpoint de santÃ© du gateway
 func (s *Server) HealthCheck(c *gin.Context) {
 	healthStatus := s.getOverallHealth()
@@ -147,52 +232,80 @@ func (s *Server) HealthCheck(c *gin.Context) {
 	}
 }
 
-// HandleWebSocket gÃ¨re les connections WebSocket
+// HandleWebSocket ouvre le canal de push temps réel d'un utilisateur authentifié
+// Le token est validé par JWTAuth avant l'upgrade ; resume_token et last_seq permettent
+// de reprendre la session précédente après une reconnexion.
 func (s *Server) HandleWebSocket(c *gin.Context) {
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":      "Authentication required",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	var lastSeq uint64
+	if value := c.Query("last_seq"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "Invalid last_seq",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+		lastSeq = parsed
+	}
+
+	// Encodage des messages : sous-protocole WebSocket, à défaut ?encoding=, JSON sinon
+	format := encoding.FormatJSON
+	if value := c.Query("encoding"); value != "" {
+		parsed, valid := encoding.ParseFormat(value)
+		if !valid {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "Invalid encoding (expected json, msgpack or protobuf)",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+		format = parsed
+	}
+
+	identity := realtime.Identity{UserID: userID.String()}
+	identity.Username, _ = middleware.GetUsernameFromContext(c)
+	identity.Role, _ = middleware.GetUserRoleFromContext(c)
+
 	// Upgrade de la connection HTTP vers WebSocket
 	conn, err := s.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to upgrade WebSocket connection")
 		return
 	}
-	defer conn.Close()
-
-	// Ajouter le client Ã  la liste
-	s.wsMutex.Lock()
-	s.wsClients[conn] = true
-	clientCount := len(s.wsClients)
-	s.wsMutex.Unlock()
-
-	logrus.WithField("client_count", clientCount).Info("WebSocket client connected")
 
-	// Envoyer un message de bienvenue
-	welcomeMsg := map[string]interface{}{
-		"type":    "welcome",
-		"message": "Connected to MMORPG Gateway",
-		"time":    time.Now().Unix(),
+	if negotiated, valid := encoding.ParseFormat(conn.Subprotocol()); valid {
+		format = negotiated
 	}
-	s.sendWebSocketMessage(conn, welcomeMsg)
 
-	// GÃ©rer les messages du client
-	for {
-		var message map[string]interface{}
-		err := conn.ReadJSON(&message)
-		if err != nil {
-			logrus.WithError(err).Debug("WebSocket read error")
-			break
-		}
+	s.hub.Serve(conn, identity, format, c.Query("resume_token"), lastSeq)
+}
 
-		// Traiter le message
-		s.handleWebSocketMessage(conn, message)
-	}
+// announceMaintenance prévient les clients WebSocket d'une fenêtre de maintenance
+// Au début d'une fenêtre de toute la plateforme, les connexions hors staff sont fermées.
+func (s *Server) announceMaintenance(notice *maintenance.Notice) {
+	s.hub.Broadcast(notice)
 
-	// Nettoyer lors de la dÃ©connection
-	s.wsMutex.Lock()
-	delete(s.wsClients, conn)
-	clientCount = len(s.wsClients)
-	s.wsMutex.Unlock()
+	if notice.Event != maintenance.EventStarted || !notice.Window.Platform() {
+		return
+	}
+	closed := s.hub.Disconnect(func(identity realtime.Identity) bool {
+		return s.maintenance.Exempt(identity.UserID, identity.Role)
+	}, "maintenance")
 
-	logrus.WithField("client_count", clientCount).Info("WebSocket client disconnected")
+	logrus.WithFields(logrus.Fields{
+		"window_id": notice.Window.ID,
+		"closed":    closed,
+	}).Info("WebSocket clients disconnected for maintenance")
 }
 
 // ListRoutes affiche toutes les routes disponibles (debug)
@@ -235,6 +348,7 @@ func (s *Server) ShowConfig(c *gin.Context) {
 			"environment": s.config.Server.Environment,
 		},
 		"services":   s.config.Services,
+		"registry":   s.registry.Services(),
 		"rate_limit": s.config.RateLimit,
 		"monitoring": s.config.Monitoring,
 	}
@@ -242,14 +356,9 @@ func (s *Server) ShowConfig(c *gin.Context) {
 	c.JSON(http.StatusOK, safeConfig)
 }
 
-// ServiceStatus affiche l'Ã©tat de tous les services
+// ServiceStatus affiche l'état de tous les services et de leurs instances
 func (s *Server) ServiceStatus(c *gin.Context) {
-	s.healthMutex.RLock()
-	services := make([]ServiceHealth, 0, len(s.serviceHealth))
-	for _, health := range s.serviceHealth {
-		services = append(services, health)
-	}
-	s.healthMutex.RUnlock()
+	services := s.balancer.Snapshot()
 
 	c.JSON(http.StatusOK, gin.H{
 		"services": services,
@@ -262,12 +371,7 @@ func (s *Server) Close() error {
 	var errors []error
 
 	// Fermer les connections WebSocket
-	s.wsMutex.Lock()
-	for conn := range s.wsClients {
-		conn.Close()
-	}
-	s.wsClients = make(map[*websocket.Conn]bool)
-	s.wsMutex.Unlock()
+	s.hub.Close()
 
 	// Fermer la connection NATS
 	if s.natsConn != nil {
@@ -291,73 +395,53 @@ func (s *Server) Close() error {
 
 // MÃ©thodes privÃ©es
 
-// getServiceEndpoint rÃ©cupÃ¨re l'endpoint d'un service
-func (s *Server) getServiceEndpoint(serviceName string) (config.ServiceEndpoint, bool) {
-	switch serviceName {
-	case "auth":
-		return s.config.Services.Auth, true
-	case "player":
-		return s.config.Services.Player, true
-	case "world":
-		return s.config.Services.World, true
-	case "combat":
-		return s.config.Services.Combat, true
-	case "inventory":
-		return s.config.Services.Inventory, true
-	case "guild":
-		return s.config.Services.Guild, true
-	case "chat":
-		return s.config.Services.Chat, true
-	case "analytics":
-		return s.config.Services.Analytics, true
-	default:
-		return config.ServiceEndpoint{}, false
+// balancingKey retourne la clé de hachage cohérent d'une requête
+// (ID utilisateur authentifié, sinon l'IP du client)
+func balancingKey(c *gin.Context) string {
+	if userID, ok := middleware.GetUserIDFromContext(c); ok {
+		return userID.String()
 	}
-}
-
-// isServiceHealthy vÃ©rifie si un service est en bonne santÃ©
-func (s *Server) isServiceHealthy(serviceName string) bool {
-	s.healthMutex.RLock()
-	defer s.healthMutex.RUnlock()
-
-	health, exists := s.serviceHealth[serviceName]
-	if !exists {
-		return true // Si pas de donnÃ©es, on assume que c'est sain
+	if userID := c.GetHeader("X-User-ID"); userID != "" {
+		return userID
 	}
-
-	return health.Status == StatusHealthy
+	return c.ClientIP()
 }
 
-// incrementServiceError incrÃ©mente le compteur d'erreurs d'un service
-func (s *Server) incrementServiceError(serviceName string) {
-	s.healthMutex.Lock()
-	defer s.healthMutex.Unlock()
+// pickTarget choisit une instance du service dans la version attribuée au client
+// Sans instance disponible dans cette version, toutes les versions sont éligibles.
+func (s *Server) pickTarget(c *gin.Context, serviceName string) (*balancer.Target, error) {
+	key := balancingKey(c)
+	version := s.traffic.Version(serviceName, key, s.traffic.Override(c.Request))
+	if version == "" {
+		return s.balancer.Pick(serviceName, key, "")
+	}
 
-	if health, exists := s.serviceHealth[serviceName]; exists {
-		health.ErrorCount++
-		if health.ErrorCount > MaxServiceErrors {
-			health.Status = StatusUnhealthy
-		}
-		s.serviceHealth[serviceName] = health
+	target, err := s.balancer.Pick(serviceName, key, version)
+	if errors.Is(err, balancer.ErrNoInstance) {
+		logrus.WithFields(logrus.Fields{
+			"service": serviceName,
+			"version": version,
+		}).Debug("No instance available for assigned version, falling back to any version")
+		return s.balancer.Pick(serviceName, key, "")
 	}
+	return target, err
 }
 
 // getOverallHealth calcule l'Ã©tat de santÃ© global
 func (s *Server) getOverallHealth() map[string]interface{} {
-	s.healthMutex.RLock()
-	defer s.healthMutex.RUnlock()
+	services := s.balancer.Snapshot()
 
-	totalServices := len(s.serviceHealth)
+	totalServices := len(services)
 	healthyServices := 0
 
-	for _, health := range s.serviceHealth {
-		if health.Status == StatusHealthy {
+	for _, service := range services {
+		if service.Status != balancer.ServiceDown {
 			healthyServices++
 		}
 	}
 
 	status := StatusHealthy
-	if totalServices > 0 && float64(healthyServices)/float64(totalServices) < 0.8 {
+	if totalServices > 0 && float64(healthyServices)/float64(totalServices) < HealthyServicesRatio {
 		status = "degraded"
 	}
 	if healthyServices == 0 && totalServices > 0 {
@@ -369,96 +453,13 @@ func (s *Server) getOverallHealth() map[string]interface{} {
 		"timestamp":         time.Now().Unix(),
 		"services_total":    totalServices,
 		"services_healthy":  healthyServices,
-		"websocket_clients": len(s.wsClients),
+		"websocket_clients": s.hub.ClientCount(),
 		"version":           "1.0.0",
 	}
 }
 
-// sendWebSocketMessage envoie un message WebSocket avec gestion d'erreur
-func (s *Server) sendWebSocketMessage(conn *websocket.Conn, message map[string]interface{}) {
-	if err := conn.WriteJSON(message); err != nil {
-		logrus.WithError(err).Debug("Failed to send WebSocket message")
-		// Fermer la connection si l'écriture échoue
-		s.wsMutex.Lock()
-		delete(s.wsClients, conn)
-		s.wsMutex.Unlock()
-		conn.Close()
-	}
-}
-
-// handleWebSocketMessage traite les messages WebSocket
-func (s *Server) handleWebSocketMessage(conn *websocket.Conn, message map[string]interface{}) {
-	messageType, ok := message["type"].(string)
-	if !ok {
-		s.sendWebSocketMessage(conn, map[string]interface{}{
-			"type":  "error",
-			"error": "Message type required",
-		})
-		return
-	}
-
-	switch messageType {
-	case "ping":
-		s.sendWebSocketMessage(conn, map[string]interface{}{
-			"type": "pong",
-			"time": time.Now().Unix(),
-		})
-	case "join_channel":
-		// Logique pour rejoindre un channel de chat
-		s.handleJoinChannel(conn, message)
-	case "chat_message":
-		// Relayer vers le service de chat via NATS
-		s.handleChatMessage(message)
-	default:
-		s.sendWebSocketMessage(conn, map[string]interface{}{
-			"type":  "error",
-			"error": "Unknown message type",
-		})
-	}
-}
-
-// handleJoinChannel gÃ¨re l'adhÃ©sion Ã  un channel
-func (s *Server) handleJoinChannel(conn *websocket.Conn, message map[string]interface{}) {
-	channel, ok := message["channel"].(string)
-	if !ok {
-		s.sendWebSocketMessage(conn, map[string]interface{}{
-			"type":  "error",
-			"error": "Channel name required",
-		})
-		return
-	}
-
-	// Envoyer confirmation
-	s.sendWebSocketMessage(conn, map[string]interface{}{
-		"type":    "channel_joined",
-		"channel": channel,
-		"time":    time.Now().Unix(),
-	})
-
-	logrus.WithField("channel", channel).Debug("Client joined channel")
-}
-
-// handleChatMessage gÃ¨re les messages de chat
-func (s *Server) handleChatMessage(message map[string]interface{}) {
-	if s.natsConn == nil {
-		return
-	}
-
-	// Publier le message sur NATS pour le service de chat
-	data, err := json.Marshal(message)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to marshal chat message")
-		return
-	}
-
-	err = s.natsConn.Publish("chat.message", data)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to publish chat message")
-	}
-}
-
-// connectToNATS Ã©tablit la connection NATS
-func connectToNATS(cfg config.NATSConfig) (*nats.Conn, error) {
+// ConnectNATS établit la connection NATS partagée par le gateway
+func ConnectNATS(cfg config.NATSConfig) (*nats.Conn, error) {
 	opts := []nats.Option{
 		nats.Name(cfg.ClientID),
 		nats.Timeout(cfg.ConnectTimeout),
@@ -480,106 +481,3 @@ func connectToNATS(cfg config.NATSConfig) (*nats.Conn, error) {
 	logrus.WithField("url", cfg.URL).Info("Connected to NATS")
 	return nc, nil
 }
-
-// initServiceHealthMonitoring initialize le monitoring des services
-func (s *Server) initServiceHealthMonitoring() {
-	services := map[string]config.ServiceEndpoint{
-		"auth":      s.config.Services.Auth,
-		"player":    s.config.Services.Player,
-		"world":     s.config.Services.World,
-		"combat":    s.config.Services.Combat,
-		"inventory": s.config.Services.Inventory,
-		"guild":     s.config.Services.Guild,
-		"chat":      s.config.Services.Chat,
-		"analytics": s.config.Services.Analytics,
-	}
-
-	// initializer l'Ã©tat de santÃ©
-	s.healthMutex.Lock()
-	for name, endpoint := range services {
-		s.serviceHealth[name] = ServiceHealth{
-			Name:         name,
-			URL:          endpoint.URL,
-			Status:       "unknown",
-			LastCheck:    time.Now(),
-			ResponseTime: 0,
-			ErrorCount:   0,
-		}
-	}
-	s.healthMutex.Unlock()
-
-	// DÃ©marrer le monitoring pÃ©riodique
-	go s.monitorServicesHealth()
-}
-
-// monitorServicesHealth surveille périodiquement la santé des services
-func (s *Server) monitorServicesHealth() {
-	ticker := time.NewTicker(HealthCheckInterval * time.Second)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		s.checkAllServicesHealth()
-	}
-}
-
-// checkAllServicesHealth vÃ©rifie la santÃ© de tous les services
-func (s *Server) checkAllServicesHealth() {
-	s.healthMutex.RLock()
-	services := make(map[string]ServiceHealth)
-	for k, v := range s.serviceHealth {
-		services[k] = v
-	}
-	s.healthMutex.RUnlock()
-
-	for name, health := range services {
-		go s.checkServiceHealth(name, &health)
-	}
-}
-
-// checkServiceHealth vérifie la santé d'un service spécifique
-func (s *Server) checkServiceHealth(name string, health *ServiceHealth) {
-	start := time.Now()
-
-	// Créer un contexte avec timeout
-	ctx, cancel := context.WithTimeout(context.Background(), ServiceHealthTimeout*time.Second)
-	defer cancel()
-
-	// Faire un ping simple au service
-	client := &http.Client{Timeout: ServiceHealthTimeout * time.Second}
-	req, err := http.NewRequestWithContext(ctx, "GET", health.URL+"/health", http.NoBody)
-	if err != nil {
-		responseTime := time.Since(start).Milliseconds()
-		s.updateHealthStatus(name, health, err, nil, responseTime)
-		return
-	}
-
-	resp, err := client.Do(req)
-	responseTime := time.Since(start).Milliseconds()
-	s.updateHealthStatus(name, health, err, resp, responseTime)
-}
-
-// updateHealthStatus met à jour le statut de santé d'un service
-func (s *Server) updateHealthStatus(name string, health *ServiceHealth, err error, resp *http.Response, responseTime int64) {
-	s.healthMutex.Lock()
-	defer s.healthMutex.Unlock()
-
-	updatedHealth := *health
-	updatedHealth.LastCheck = time.Now()
-	updatedHealth.ResponseTime = responseTime
-
-	if err != nil || resp.StatusCode != http.StatusOK {
-		updatedHealth.ErrorCount++
-		if updatedHealth.ErrorCount > MaxCriticalServiceErrors {
-			updatedHealth.Status = StatusUnhealthy
-		}
-	} else {
-		updatedHealth.ErrorCount = 0
-		updatedHealth.Status = StatusHealthy
-	}
-
-	if resp != nil {
-		resp.Body.Close()
-	}
-
-	s.serviceHealth[name] = updatedHealth
-}
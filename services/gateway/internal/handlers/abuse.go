@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"gateway/internal/abuse"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AbuseHandler inspection et levée des blocages de la détection des abus
+type AbuseHandler struct {
+	Detector *abuse.Detector
+}
+
+func NewAbuseHandler(detector *abuse.Detector) *AbuseHandler {
+	return &AbuseHandler{Detector: detector}
+}
+
+// GET /gateway/abuse/blocks
+func (h *AbuseHandler) Blocks(c *gin.Context) {
+	blocks := h.Detector.Blocks()
+
+	c.JSON(http.StatusOK, gin.H{
+		"blocks": blocks,
+		"count":  len(blocks),
+		"time":   time.Now().Unix(),
+	})
+}
+
+// GET /gateway/abuse/clients/:kind/:value
+// Score, signaux et blocage en cours d'une IP (kind=ip) ou d'un utilisateur (kind=user).
+func (h *AbuseHandler) Client(c *gin.Context) {
+	kind, value := c.Param("kind"), c.Param("value")
+	if !abuse.ValidKind(kind) {
+		respondInvalidKind(c)
+		return
+	}
+
+	state, exists := h.Detector.Client(kind, value)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":      "Client not tracked",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"client": state})
+}
+
+// DELETE /gateway/abuse/blocks/:kind/:value
+// Lève le blocage sur tous les réplicas et remet le score du client à zéro.
+func (h *AbuseHandler) Unblock(c *gin.Context) {
+	kind, value := c.Param("kind"), c.Param("value")
+	if !abuse.ValidKind(kind) {
+		respondInvalidKind(c)
+		return
+	}
+
+	if err := h.Detector.Unblock(c.Request.Context(), kind, value); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, abuse.ErrBlockNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error":      err.Error(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Block lifted"})
+}
+
+// respondInvalidKind répond 400 pour un type de client inconnu
+func respondInvalidKind(c *gin.Context) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":      "Invalid client kind (expected ip or user)",
+		"request_id": c.GetHeader("X-Request-ID"),
+	})
+}
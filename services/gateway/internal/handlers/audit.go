@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"gateway/internal/audit"
+	"gateway/internal/config"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler recherche dans le journal d'audit
+type AuditHandler struct {
+	Recorder *audit.Recorder
+	Config   *config.AuditConfig
+}
+
+func NewAuditHandler(recorder *audit.Recorder, cfg *config.AuditConfig) *AuditHandler {
+	return &AuditHandler{Recorder: recorder, Config: cfg}
+}
+
+// GET /gateway/audit?user_id=...&character_id=...&method=POST&route=/api/v1/player/characters&status=201&from=...&to=...&limit=100
+// from et to au format RFC 3339 ; les enregistrements les plus récents d'abord.
+func (h *AuditHandler) Query(c *gin.Context) {
+	filter := &audit.Filter{
+		UserID:      c.Query("user_id"),
+		CharacterID: c.Query("character_id"),
+		Method:      c.Query("method"),
+		Route:       c.Query("route"),
+		Limit:       h.Config.DefaultQueryLimit,
+	}
+
+	if value := c.Query("status"); value != "" {
+		status, err := strconv.Atoi(value)
+		if err != nil {
+			respondInvalidAuditQuery(c, "Invalid status")
+			return
+		}
+		filter.Status = status
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			respondInvalidAuditQuery(c, "Invalid limit")
+			return
+		}
+		filter.Limit = min(limit, h.Config.MaxQueryLimit)
+	}
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				respondInvalidAuditQuery(c, "Invalid "+name+" time (expected RFC 3339)")
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	records, err := h.Recorder.Query(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "Failed to query audit records",
+			"message":    err.Error(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"records": records,
+		"count":   len(records),
+		"sink":    h.Config.Sink,
+	})
+}
+
+// respondInvalidAuditQuery répond 400 à une recherche invalide
+func respondInvalidAuditQuery(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":      message,
+		"request_id": c.GetHeader("X-Request-ID"),
+	})
+}
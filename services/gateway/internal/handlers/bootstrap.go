@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"gateway/internal/aggregate"
+	"gateway/internal/maintenance"
+	"gateway/internal/middleware"
+	"gateway/internal/traffic"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Headers de la requête du client transmis aux services agrégés
+var bootstrapForwardedHeaders = []string{
+	"Authorization",
+	"Accept-Language",
+	"X-Request-ID",
+	"X-Client-Version",
+}
+
+// BootstrapHandler document chargé par le client à la connexion
+type BootstrapHandler struct {
+	Aggregator  *aggregate.Aggregator
+	Traffic     *traffic.Splitter
+	Maintenance *maintenance.Scheduler
+}
+
+func NewBootstrapHandler(aggregator *aggregate.Aggregator, splitter *traffic.Splitter, scheduler *maintenance.Scheduler) *BootstrapHandler {
+	return &BootstrapHandler{Aggregator: aggregator, Traffic: splitter, Maintenance: scheduler}
+}
+
+// GET /api/v1/me/bootstrap?character=<id>
+// Les sections sont chargées en parallèle ; celles dont le service a échoué sont
+// listées dans "errors" et la réponse reste 200 tant qu'une section a pu être chargée.
+func (h *BootstrapHandler) Bootstrap(c *gin.Context) {
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":      "Authentication required",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	characterID := c.Query("character")
+	if characterID != "" {
+		if _, err := uuid.Parse(characterID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "Invalid character ID",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+	}
+
+	header := make(http.Header)
+	for _, name := range bootstrapForwardedHeaders {
+		if value := c.GetHeader(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	header.Set("X-User-ID", userID.String())
+	header.Set("X-Username", c.GetString("username"))
+	header.Set("X-User-Role", c.GetString("user_role"))
+	header.Set("X-Forwarded-For", c.ClientIP())
+
+	result, loaded := h.Aggregator.Aggregate(c.Request.Context(), aggregate.Request{
+		UserID:      userID.String(),
+		CharacterID: characterID,
+		Header:      header,
+		Build:       h.Traffic.Override(c.Request),
+		Staff:       h.Maintenance.Exempt(userID.String(), c.GetString("user_role")),
+	})
+
+	status := http.StatusOK
+	if !loaded {
+		status = http.StatusBadGateway
+	}
+	c.JSON(status, gin.H{
+		"user_id":      userID,
+		"character_id": characterID,
+		"data":         result.Data,
+		"errors":       result.Errors,
+		"partial":      len(result.Errors) > 0,
+		"request_id":   c.GetHeader("X-Request-ID"),
+	})
+}
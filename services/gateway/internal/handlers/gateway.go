@@ -1,7 +1,9 @@
 package handlers
 
 import (
-	"context"
+	"gateway/internal/balancer"
+	"gateway/internal/proxy"
+	"gateway/internal/registry"
 	"net/http"
 	"os"
 	"runtime"
@@ -9,19 +11,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-)
-
-// Constantes pour les status de service
-const (
-	StatusUp      = "up"
-	StatusDown    = "down"
-	StatusUnknown = "unknown"
-
-	// Codes de statut HTTP
-	HTTPStatusOK = 200
-
-	// Timeouts
-	HealthCheckTimeout = 1 // seconde
+	"github.com/sirupsen/logrus"
 )
 
 var (
@@ -29,22 +19,25 @@ var (
 	reloadCount int32
 )
 
-type ServiceStatus struct {
-	Name   string `json:"name"`
-	URL    string `json:"url"`
-	Status string `json:"status"`
-}
-
 type GatewayHandler struct {
-	Services map[string]string // nom -> url
+	Registry *registry.Registry
+	Balancer *balancer.Balancer
+	Proxy    *proxy.ServiceProxy
 	Version  string
 	Commit   string
 	Build    string
 }
 
-func NewGatewayHandler(services map[string]string, version, commit, build string) *GatewayHandler {
+func NewGatewayHandler(
+	serviceRegistry *registry.Registry,
+	lb *balancer.Balancer,
+	serviceProxy *proxy.ServiceProxy,
+	version, commit, build string,
+) *GatewayHandler {
 	return &GatewayHandler{
-		Services: services,
+		Registry: serviceRegistry,
+		Balancer: lb,
+		Proxy:    serviceProxy,
 		Version:  version,
 		Commit:   commit,
 		Build:    build,
@@ -55,49 +48,22 @@ func NewGatewayHandler(services map[string]string, version, commit, build string
 func (h *GatewayHandler) Status(c *gin.Context) {
 	uptime := time.Since(startTime)
 	c.JSON(http.StatusOK, gin.H{
-		"status":        "ok",
-		"uptime":        uptime.String(),
-		"version":       h.Version,
-		"commit":        h.Commit,
-		"build":         h.Build,
-		"go_version":    runtime.Version(),
-		"num_goroutine": runtime.NumGoroutine(),
-		"reloads":       atomic.LoadInt32(&reloadCount),
-		"services":      len(h.Services),
+		"status":           "ok",
+		"uptime":           uptime.String(),
+		"version":          h.Version,
+		"commit":           h.Commit,
+		"build":            h.Build,
+		"go_version":       runtime.Version(),
+		"num_goroutine":    runtime.NumGoroutine(),
+		"reloads":          atomic.LoadInt32(&reloadCount),
+		"services":         len(h.Registry.Services()),
+		"circuit_breakers": h.Proxy.BreakerStatuses(),
 	})
 }
 
 // /gateway/services
 func (h *GatewayHandler) ServicesList(c *gin.Context) {
-	statuses := make([]ServiceStatus, 0, len(h.Services))
-	for name, url := range h.Services {
-		var status string
-
-		// Créer un contexte avec timeout
-		ctx, cancel := context.WithTimeout(context.Background(), HealthCheckTimeout*time.Second)
-
-		client := http.Client{Timeout: HealthCheckTimeout * time.Second}
-		req, err := http.NewRequestWithContext(ctx, "GET", url+"/health", http.NoBody)
-		if err != nil {
-			status = StatusDown
-		} else {
-			resp, err := client.Do(req)
-			if err == nil {
-				// Fermer immédiatement pour éviter les fuites de ressources
-				resp.Body.Close()
-				if resp.StatusCode == HTTPStatusOK {
-					status = StatusUp
-				} else {
-					status = StatusDown
-				}
-			} else {
-				status = StatusDown
-			}
-		}
-		cancel() // Libérer immédiatement le contexte
-		statuses = append(statuses, ServiceStatus{Name: name, URL: url, Status: status})
-	}
-	c.JSON(http.StatusOK, statuses)
+	c.JSON(http.StatusOK, h.Balancer.Snapshot())
 }
 
 // /gateway/version
@@ -123,37 +89,30 @@ func (h *GatewayHandler) Info(c *gin.Context) {
 
 // /gateway/health/all
 func (h *GatewayHandler) HealthAll(c *gin.Context) {
-	results := make(map[string]string)
-	for name, url := range h.Services {
-		// Créer un contexte avec timeout
-		ctx, cancel := context.WithTimeout(context.Background(), HealthCheckTimeout*time.Second)
-
-		client := http.Client{Timeout: HealthCheckTimeout * time.Second}
-		req, err := http.NewRequestWithContext(ctx, "GET", url+"/health", http.NoBody)
-		if err != nil {
-			results[name] = StatusDown
-		} else {
-			resp, err := client.Do(req)
-			if err == nil {
-				// Fermer immédiatement pour éviter les fuites de ressources
-				resp.Body.Close()
-				if resp.StatusCode == HTTPStatusOK {
-					results[name] = StatusUp
-				} else {
-					results[name] = StatusDown
-				}
-			} else {
-				results[name] = StatusDown
-			}
-		}
-		cancel() // Libérer immédiatement le contexte
+	services := h.Balancer.Snapshot()
+	results := make(map[string]string, len(services))
+	for _, service := range services {
+		results[service.Name] = service.Status
 	}
 	c.JSON(http.StatusOK, results)
 }
 
 // /gateway/reload
 func (h *GatewayHandler) Reload(c *gin.Context) {
+	if err := h.Registry.Reload(); err != nil {
+		logrus.WithError(err).Error("Service registry reload failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":      "Reload failed",
+			"message":    err.Error(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
 	atomic.AddInt32(&reloadCount, 1)
-	// Ici, on pourrait recharger la config dynamiquement
-	c.JSON(http.StatusOK, gin.H{"message": "Reload effectué", "reloads": atomic.LoadInt32(&reloadCount)})
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Reload effectué",
+		"reloads":  atomic.LoadInt32(&reloadCount),
+		"services": len(h.Registry.Services()),
+	})
 }
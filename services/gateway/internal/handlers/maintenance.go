@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"gateway/internal/balancer"
+	"gateway/internal/config"
+	"gateway/internal/maintenance"
+	"gateway/internal/registry"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceHandler fenêtres de maintenance et drain des instances
+type MaintenanceHandler struct {
+	Maintenance *maintenance.Scheduler
+	Registry    *registry.Registry
+	Balancer    *balancer.Balancer
+	Config      *config.MaintenanceConfig
+}
+
+// scheduleRequest demande de fenêtre de maintenance
+// starts_at vide : la fenêtre commence immédiatement ; la fin est donnée par ends_at ou duration.
+type scheduleRequest struct {
+	Service  string     `json:"service"` // vide : toute la plateforme
+	Message  string     `json:"message"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	Duration string     `json:"duration"` // ex. "30m"
+}
+
+func NewMaintenanceHandler(
+	scheduler *maintenance.Scheduler,
+	serviceRegistry *registry.Registry,
+	lb *balancer.Balancer,
+	cfg *config.MaintenanceConfig,
+) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		Maintenance: scheduler,
+		Registry:    serviceRegistry,
+		Balancer:    lb,
+		Config:      cfg,
+	}
+}
+
+// GET /gateway/maintenance
+// Fenêtres planifiées et en cours, pour les bannières des clients (route publique).
+func (h *MaintenanceHandler) List(c *gin.Context) {
+	now := time.Now()
+	windows := h.Maintenance.Windows()
+
+	c.JSON(http.StatusOK, gin.H{
+		"windows": windows,
+		"active":  h.Maintenance.Active("", now) != nil,
+		"time":    now.Unix(),
+	})
+}
+
+// POST /gateway/maintenance
+// Body : {"service": "combat", "message": "...", "starts_at": "2024-06-01T02:00:00Z", "duration": "30m"}
+func (h *MaintenanceHandler) Schedule(c *gin.Context) {
+	var req scheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidWindow(c, err)
+		return
+	}
+
+	if req.Service != "" {
+		if _, exists := h.Registry.Get(req.Service); !exists {
+			respondInvalidWindow(c, registry.ErrServiceNotFound)
+			return
+		}
+	}
+
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+
+	var endsAt time.Time
+	switch {
+	case req.EndsAt != nil && req.Duration != "":
+		respondInvalidWindow(c, errors.New("ends_at and duration are mutually exclusive"))
+		return
+	case req.EndsAt != nil:
+		endsAt = *req.EndsAt
+	case req.Duration != "":
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			respondInvalidWindow(c, err)
+			return
+		}
+		endsAt = startsAt.Add(duration)
+	default:
+		respondInvalidWindow(c, errors.New("ends_at or duration is required"))
+		return
+	}
+
+	window, err := h.Maintenance.Schedule(c.Request.Context(), req.Service, req.Message, startsAt, endsAt)
+	if err != nil {
+		respondInvalidWindow(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"window": window})
+}
+
+// GET /gateway/maintenance/:id
+// in_flight : requêtes encore en cours vers les services couverts (0 : maintenance possible).
+func (h *MaintenanceHandler) Get(c *gin.Context) {
+	window, exists := h.Maintenance.Get(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":      maintenance.ErrWindowNotFound.Error(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"window":    window,
+		"active":    window.Active(time.Now()),
+		"in_flight": h.Balancer.InFlight(window.Service),
+	})
+}
+
+// DELETE /gateway/maintenance/:id
+// Annule une fenêtre planifiée ou termine une fenêtre en cours.
+func (h *MaintenanceHandler) Cancel(c *gin.Context) {
+	if err := h.Maintenance.Cancel(c.Request.Context(), c.Param("id")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, maintenance.ErrWindowNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error":      err.Error(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Maintenance window cancelled"})
+}
+
+// POST /gateway/registry/instances/:service/:id/drain?wait=30s
+// L'instance ne reçoit plus de nouvelles requêtes, sur tous les réplicas ; avec wait, la
+// réponse attend la fin des requêtes en cours de ce réplica (au plus MaxDrainWait).
+func (h *MaintenanceHandler) DrainInstance(c *gin.Context) {
+	service, id := c.Param("service"), c.Param("id")
+
+	var wait time.Duration
+	if value := c.Query("wait"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "Invalid wait duration",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+		wait = min(parsed, h.Config.MaxDrainWait)
+	}
+
+	inFlight, err := h.Balancer.Drain(service, id)
+	if err != nil {
+		respondRegistryError(c, err)
+		return
+	}
+	h.Maintenance.SetDraining(c.Request.Context(), service, id, true)
+
+	if wait > 0 && inFlight > 0 {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), wait)
+		defer cancel()
+
+		inFlight, err = h.Balancer.WaitDrained(ctx, service, id, h.Config.DrainPollInterval)
+		if err != nil {
+			respondRegistryError(c, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"service":   service,
+		"instance":  id,
+		"drained":   inFlight == 0,
+		"in_flight": inFlight,
+	})
+}
+
+// DELETE /gateway/registry/instances/:service/:id/drain
+// L'instance reçoit à nouveau du trafic, sur tous les réplicas.
+func (h *MaintenanceHandler) ResumeInstance(c *gin.Context) {
+	service, id := c.Param("service"), c.Param("id")
+	if err := h.Balancer.Resume(service, id); err != nil {
+		respondRegistryError(c, err)
+		return
+	}
+	h.Maintenance.SetDraining(c.Request.Context(), service, id, false)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Instance resumed"})
+}
+
+// respondInvalidWindow répond 400 à une demande de fenêtre invalide
+func respondInvalidWindow(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":      "Invalid maintenance window",
+		"message":    err.Error(),
+		"request_id": c.GetHeader("X-Request-ID"),
+	})
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"gateway/internal/openapi"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIHandler publication des contrats OpenAPI
+type OpenAPIHandler struct {
+	Documents map[string]*openapi.Document
+	Public    *openapi.Document
+}
+
+// NewOpenAPIHandler assemble le document de l'API publique à partir des contrats des services
+func NewOpenAPIHandler(docs map[string]*openapi.Document, version string) *OpenAPIHandler {
+	info := openapi.Info{
+		Title:       "MMORPG API",
+		Version:     version,
+		Description: "API publique exposée par le gateway",
+	}
+	return &OpenAPIHandler{
+		Documents: docs,
+		Public:    openapi.Merge(docs, info, []openapi.Server{{URL: "/"}}),
+	}
+}
+
+// GET /gateway/openapi.json
+func (h *OpenAPIHandler) Spec(c *gin.Context) {
+	c.JSON(http.StatusOK, h.Public)
+}
+
+// GET /gateway/openapi/:service (ex. /gateway/openapi/auth.json)
+func (h *OpenAPIHandler) ServiceSpec(c *gin.Context) {
+	service := strings.TrimSuffix(c.Param("service"), ".json")
+	doc, exists := h.Documents[service]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":      "Unknown service contract",
+			"service":    service,
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, doc)
+}
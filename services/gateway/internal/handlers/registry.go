@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"gateway/internal/registry"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POST /gateway/registry/instances
+func (h *GatewayHandler) RegisterInstance(c *gin.Context) {
+	var req registry.Registration
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Invalid registration",
+			"message":    err.Error(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	instance, err := h.Registry.Register(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Invalid registration",
+			"message":    err.Error(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"service":  req.Service,
+		"instance": instance,
+	})
+}
+
+// PUT /gateway/registry/instances/:service/:id/heartbeat
+func (h *GatewayHandler) HeartbeatInstance(c *gin.Context) {
+	instance, err := h.Registry.Heartbeat(c.Param("service"), c.Param("id"))
+	if err != nil {
+		respondRegistryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"service":  c.Param("service"),
+		"instance": instance,
+	})
+}
+
+// DELETE /gateway/registry/instances/:service/:id
+func (h *GatewayHandler) DeregisterInstance(c *gin.Context) {
+	if err := h.Registry.Deregister(c.Param("service"), c.Param("id")); err != nil {
+		respondRegistryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Instance deregistered"})
+}
+
+// respondRegistryError traduit une erreur du registre en réponse HTTP
+// Un 404 sur heartbeat indique à l'instance qu'elle doit se réenregistrer.
+func respondRegistryError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, registry.ErrServiceNotFound) || errors.Is(err, registry.ErrInstanceNotFound) {
+		status = http.StatusNotFound
+	}
+
+	c.JSON(status, gin.H{
+		"error":      err.Error(),
+		"request_id": c.GetHeader("X-Request-ID"),
+	})
+}
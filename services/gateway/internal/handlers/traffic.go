@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"gateway/internal/config"
+	"gateway/internal/traffic"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrafficHandler administration de la répartition du trafic entre versions (canary)
+type TrafficHandler struct {
+	Traffic *traffic.Splitter
+	Config  *config.TrafficConfig
+}
+
+func NewTrafficHandler(splitter *traffic.Splitter, cfg *config.TrafficConfig) *TrafficHandler {
+	return &TrafficHandler{Traffic: splitter, Config: cfg}
+}
+
+// GET /gateway/traffic
+// Règles actives et, par version, trafic et taux d'erreur depuis le dernier changement de règle.
+func (h *TrafficHandler) Status(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"override": gin.H{
+			"header": h.Config.Header,
+			"cookie": h.Config.Cookie,
+		},
+		"services": h.Traffic.Status(),
+	})
+}
+
+// PUT /gateway/traffic/:service
+// Body : {"baseline": "stable", "weights": {"v2": 10}}, la référence reçoit le reste du trafic.
+func (h *TrafficHandler) SetRule(c *gin.Context) {
+	var req config.TrafficRule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Invalid traffic rule",
+			"message":    err.Error(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	rule, err := h.Traffic.SetRule(c.Request.Context(), c.Param("service"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Invalid traffic rule",
+			"message":    err.Error(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"service": c.Param("service"),
+		"rule":    rule,
+	})
+}
+
+// DELETE /gateway/traffic/:service
+// Toutes les instances du service reçoivent à nouveau du trafic, quelle que soit leur version.
+func (h *TrafficHandler) DeleteRule(c *gin.Context) {
+	if err := h.Traffic.DeleteRule(c.Request.Context(), c.Param("service")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, traffic.ErrRuleNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error":      err.Error(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Traffic rule deleted"})
+}
@@ -0,0 +1,214 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"gateway/internal/config"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+// storeOpenTimeoutFactor délai d'ouverture du bucket partagé, en multiples du timeout du store
+const storeOpenTimeoutFactor = 40
+
+// Résultats d'une requête portant une clé (métriques)
+const (
+	ResultStored   = "stored"      // première requête, réponse gardée
+	ResultReplayed = "replayed"    // réponse de la première requête rejouée
+	ResultInFlight = "in_progress" // première requête encore en cours (409)
+	ResultMismatch = "mismatch"    // clé réutilisée pour une autre requête (422)
+	ResultReleased = "released"    // réponse non gardée (non transmise, trop grande), la clé est libérée
+	ResultUnknown  = "unknown"     // 5xx après envoi au service : réponse gardée, la clé reste prise
+)
+
+// Guard garde la première réponse de chaque clé d'idempotence d'un utilisateur
+type Guard struct {
+	config  *config.IdempotencyConfig
+	methods map[string]bool
+	store   Store
+	local   *MemoryStore // store par défaut et repli si le store partagé échoue
+	stop    chan struct{}
+}
+
+// NewGuard crée le garde et son store (natsConn requis pour le store "nats")
+// Si le store partagé ne peut pas être ouvert, chaque réplica garde ses propres clés.
+func NewGuard(cfg *config.IdempotencyConfig, natsConn *nats.Conn) *Guard {
+	g := &Guard{
+		config:  cfg,
+		methods: make(map[string]bool),
+		local:   NewMemoryStore(),
+		stop:    make(chan struct{}),
+	}
+	g.store = g.local
+
+	for _, method := range cfg.Methods {
+		g.methods[strings.ToUpper(method)] = true
+	}
+
+	if cfg.Enabled && cfg.Store == config.IdempotencyStoreNATS {
+		if store, err := g.openNATSStore(natsConn); err != nil {
+			logrus.WithError(err).Warn("Shared idempotency store unavailable, keeping keys per replica")
+		} else {
+			g.store = store
+			logrus.WithField("bucket", cfg.Bucket).Info("Idempotency keys shared through NATS KV")
+		}
+	}
+
+	return g
+}
+
+// openNATSStore ouvre le bucket partagé des clés
+func (g *Guard) openNATSStore(natsConn *nats.Conn) (Store, error) {
+	if natsConn == nil {
+		return nil, fmt.Errorf("NATS is not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.config.StoreTimeout*storeOpenTimeoutFactor)
+	defer cancel()
+
+	return NewNATSStore(ctx, natsConn, g.config.Bucket, g.config.TTL)
+}
+
+// Start démarre le nettoyage des clés locales expirées
+func (g *Guard) Start() {
+	go func() {
+		ticker := time.NewTicker(g.config.CleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-g.stop:
+				return
+			case <-ticker.C:
+				g.local.Cleanup()
+			}
+		}
+	}()
+}
+
+// Close arrête le nettoyage des clés
+func (g *Guard) Close() {
+	close(g.stop)
+}
+
+// Applies indique si une requête est concernée : garde actif et méthode d'écriture
+func (g *Guard) Applies(method string) bool {
+	return g.config.Enabled && g.methods[method]
+}
+
+// Header nom du header de la clé
+func (g *Guard) Header() string {
+	return g.config.Header
+}
+
+// MaxKeyLength longueur maximale d'une clé
+func (g *Guard) MaxKeyLength() int {
+	return g.config.MaxKeyLength
+}
+
+// MaxBodySize taille maximale du corps d'une requête portant une clé
+func (g *Guard) MaxBodySize() int64 {
+	return g.config.MaxBodySize
+}
+
+// MaxResponseSize taille maximale d'une réponse gardée
+func (g *Guard) MaxResponseSize() int64 {
+	return g.config.MaxResponseSize
+}
+
+// Fingerprint empreinte d'une requête : une clé ne peut être rejouée que pour la même requête
+func Fingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Begin réserve la clé d'un utilisateur pour une requête
+// Retourne la réservation si la requête doit être exécutée, sinon l'enregistrement existant
+// (requête en cours ou réponse à rejouer).
+func (g *Guard) Begin(ctx context.Context, userID, key, fingerprint string) (*Reservation, *Record, error) {
+	now := time.Now()
+	record := &Record{
+		Fingerprint: fingerprint,
+		LockedUntil: now.Add(g.config.LockTimeout),
+		CreatedAt:   now,
+	}
+	storeKey := recordKey(userID, key)
+
+	ctx, cancel := context.WithTimeout(ctx, g.config.StoreTimeout)
+	defer cancel()
+
+	store := g.store
+	existing, err := store.Reserve(ctx, storeKey, record)
+	if err != nil && store != Store(g.local) {
+		// Store partagé indisponible : ne protéger que contre les retries vers ce réplica
+		storeErrors.Inc()
+		logrus.WithError(err).Debug("Idempotency store error, falling back to local keys")
+		store = g.local
+		existing, err = store.Reserve(ctx, storeKey, record)
+	}
+	if err != nil {
+		storeErrors.Inc()
+		return nil, nil, err
+	}
+	if existing != nil {
+		return nil, existing, nil
+	}
+
+	return &Reservation{guard: g, store: store, key: storeKey, record: record}, nil, nil
+}
+
+// Reservation clé réservée par une requête en cours
+type Reservation struct {
+	guard  *Guard
+	store  Store
+	key    string
+	record *Record
+}
+
+// Complete garde la réponse de la requête pour la rejouer aux requêtes suivantes
+func (r *Reservation) Complete(ctx context.Context, status int, header http.Header, body []byte) {
+	record := *r.record
+	record.Completed = true
+	record.Status = status
+	record.Header = header
+	record.Body = body
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.guard.config.StoreTimeout)
+	defer cancel()
+
+	if err := r.store.Complete(ctx, r.key, &record, r.guard.config.TTL); err != nil {
+		// La clé reste réservée jusqu'à LockTimeout, puis la requête peut être rejouée
+		storeErrors.Inc()
+		logrus.WithContext(ctx).WithError(err).Warn("Failed to store idempotent response")
+	}
+}
+
+// Release libère la clé : la requête n'a pas abouti et peut être retentée
+func (r *Reservation) Release(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.guard.config.StoreTimeout)
+	defer cancel()
+
+	if err := r.store.Release(ctx, r.key); err != nil {
+		storeErrors.Inc()
+		logrus.WithContext(ctx).WithError(err).Warn("Failed to release idempotency key")
+	}
+}
+
+// Record compte le résultat d'une requête portant une clé
+func (g *Guard) Record(result string) {
+	requests.WithLabelValues(result).Inc()
+}
+
+// recordKey clé du store pour un utilisateur (caractères acceptés par NATS KV)
+func recordKey(userID, key string) string {
+	hash := sha256.Sum256([]byte(userID + "\n" + key))
+	return "key." + hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,111 @@
+package idempotency
+
+import (
+	"context"
+	"gateway/internal/config"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTestGuard garde en mémoire, sans nettoyage périodique
+func newTestGuard(lockTimeout time.Duration) *Guard {
+	return NewGuard(&config.IdempotencyConfig{
+		Enabled:         true,
+		Header:          "Idempotency-Key",
+		Methods:         []string{http.MethodPost},
+		MaxKeyLength:    64,
+		MaxBodySize:     1024,
+		MaxResponseSize: 1024,
+		TTL:             time.Hour,
+		LockTimeout:     lockTimeout,
+		StoreTimeout:    time.Second,
+	}, nil)
+}
+
+func TestGuardReplaysCompletedResponse(t *testing.T) {
+	guard := newTestGuard(time.Minute)
+	ctx := context.Background()
+	fingerprint := Fingerprint(http.MethodPost, "/api/v1/items", []byte(`{"id":1}`))
+
+	reservation, existing, err := guard.Begin(ctx, "user-1", "key", fingerprint)
+	if err != nil || reservation == nil || existing != nil {
+		t.Fatalf("first Begin = %v, %v, %v; want a reservation", reservation, existing, err)
+	}
+
+	_, existing, err = guard.Begin(ctx, "user-1", "key", fingerprint)
+	if err != nil || existing == nil || existing.Completed {
+		t.Fatalf("Begin during the request = %+v, %v; want an in-flight record", existing, err)
+	}
+
+	reservation.Complete(ctx, http.StatusCreated, http.Header{"Location": {"/items/1"}}, []byte("created"))
+
+	_, existing, err = guard.Begin(ctx, "user-1", "key", fingerprint)
+	if err != nil || existing == nil || !existing.Completed {
+		t.Fatalf("Begin after completion = %+v, %v; want the stored response", existing, err)
+	}
+	if existing.Status != http.StatusCreated || string(existing.Body) != "created" || existing.Header.Get("Location") != "/items/1" {
+		t.Errorf("stored response = %d %q %v", existing.Status, existing.Body, existing.Header)
+	}
+}
+
+func TestGuardKeysArePerUser(t *testing.T) {
+	guard := newTestGuard(time.Minute)
+	ctx := context.Background()
+
+	if _, _, err := guard.Begin(ctx, "user-1", "key", "a"); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	reservation, existing, err := guard.Begin(ctx, "user-2", "key", "a")
+	if err != nil || reservation == nil || existing != nil {
+		t.Fatalf("same key for another user = %v, %v, %v; want a reservation", reservation, existing, err)
+	}
+}
+
+func TestGuardReleaseFreesKey(t *testing.T) {
+	guard := newTestGuard(time.Minute)
+	ctx := context.Background()
+
+	reservation, _, err := guard.Begin(ctx, "user-1", "key", "a")
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	reservation.Release(ctx)
+
+	reservation, existing, err := guard.Begin(ctx, "user-1", "key", "a")
+	if err != nil || reservation == nil || existing != nil {
+		t.Fatalf("Begin after release = %v, %v, %v; want a reservation", reservation, existing, err)
+	}
+}
+
+// TestGuardTakesOverAbandonedKey une requête qui ne termine jamais ne bloque pas la clé au-delà du verrou
+func TestGuardTakesOverAbandonedKey(t *testing.T) {
+	guard := newTestGuard(time.Millisecond)
+	ctx := context.Background()
+
+	if _, _, err := guard.Begin(ctx, "user-1", "key", "a"); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	reservation, existing, err := guard.Begin(ctx, "user-1", "key", "a")
+	if err != nil || reservation == nil || existing != nil {
+		t.Fatalf("Begin after the lock timeout = %v, %v, %v; want a reservation", reservation, existing, err)
+	}
+}
+
+func TestFingerprintDependsOnRequest(t *testing.T) {
+	base := Fingerprint(http.MethodPost, "/items", []byte("a"))
+	for _, other := range []string{
+		Fingerprint(http.MethodPut, "/items", []byte("a")),
+		Fingerprint(http.MethodPost, "/items/1", []byte("a")),
+		Fingerprint(http.MethodPost, "/items", []byte("b")),
+	} {
+		if other == base {
+			t.Errorf("different requests share fingerprint %s", base)
+		}
+	}
+	if Fingerprint(http.MethodPost, "/items", []byte("a")) != base {
+		t.Error("fingerprint is not stable")
+	}
+}
@@ -0,0 +1,27 @@
+package idempotency
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Métriques Prometheus des clés d'idempotence
+var (
+	requests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_idempotency_requests_total",
+			Help: "Total number of requests carrying an idempotency key by result (stored, replayed, in_progress, mismatch, released)",
+		},
+		[]string{"result"},
+	)
+
+	storeErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "gateway_idempotency_store_errors_total",
+			Help: "Total number of idempotency store errors",
+		},
+	)
+)
+
+// InitMetrics initialize les métriques Prometheus des clés d'idempotence
+func InitMetrics() {
+	prometheus.MustRegister(requests)
+	prometheus.MustRegister(storeErrors)
+}
@@ -0,0 +1,198 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// maxCASAttempts tentatives de reprise concurrente d'une clé abandonnée
+const maxCASAttempts = 5
+
+// ErrStoreContention la clé est reprise trop souvent en parallèle
+var ErrStoreContention = errors.New("idempotency key contention")
+
+// Record état d'une clé : requête en cours, puis réponse à rejouer
+type Record struct {
+	Fingerprint string      `json:"fingerprint"` // méthode, route et corps de la première requête
+	Completed   bool        `json:"completed"`
+	LockedUntil time.Time   `json:"locked_until"` // requête en cours : abandonnée au-delà
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// abandoned indique si la requête qui détient la clé n'a jamais terminé
+func (r *Record) abandoned(now time.Time) bool {
+	return !r.Completed && now.After(r.LockedUntil)
+}
+
+// Store enregistrements des clés d'idempotence
+type Store interface {
+	// Reserve crée l'enregistrement d'une requête en cours si la clé est libre (ou abandonnée)
+	// et retourne nil ; sinon il retourne l'enregistrement existant.
+	Reserve(ctx context.Context, key string, record *Record) (*Record, error)
+	// Complete remplace l'enregistrement par la réponse à rejouer pendant ttl
+	Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error
+	// Release libère la clé : la prochaine requête sera exécutée
+	Release(ctx context.Context, key string) error
+}
+
+// memoryRecord enregistrement en mémoire et son expiration
+type memoryRecord struct {
+	record  *Record
+	expires time.Time
+}
+
+// MemoryStore enregistrements locaux au réplica
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*memoryRecord
+}
+
+// NewMemoryStore crée un store en mémoire
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*memoryRecord)}
+}
+
+// Reserve implémente Store
+func (s *MemoryStore) Reserve(_ context.Context, key string, record *Record) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if existing, exists := s.records[key]; exists && now.Before(existing.expires) && !existing.record.abandoned(now) {
+		return existing.record, nil
+	}
+
+	s.records[key] = &memoryRecord{record: record, expires: record.LockedUntil}
+	return nil, nil
+}
+
+// Complete implémente Store
+func (s *MemoryStore) Complete(_ context.Context, key string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = &memoryRecord{record: record, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+// Release implémente Store
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// Cleanup supprime les enregistrements expirés
+func (s *MemoryStore) Cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, record := range s.records {
+		if now.After(record.expires) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// NATSStore enregistrements partagés entre réplicas dans un bucket JetStream KV
+// La réservation est une création de clé (échoue si un autre réplica l'a déjà faite) ;
+// le TTL du bucket fait expirer les réponses.
+type NATSStore struct {
+	kv jetstream.KeyValue
+}
+
+// NewNATSStore ouvre (ou crée) le bucket des clés d'idempotence
+func NewNATSStore(ctx context.Context, nc *nats.Conn, bucket string, ttl time.Duration) (*NATSStore, error) {
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      bucket,
+		Description: "Gateway idempotency keys",
+		History:     1,
+		TTL:         ttl,
+		Storage:     jetstream.FileStorage, // les réponses survivent à un redémarrage de NATS
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open idempotency bucket %s: %w", bucket, err)
+	}
+
+	return &NATSStore{kv: kv}, nil
+}
+
+// Reserve implémente Store
+func (s *NATSStore) Reserve(ctx context.Context, key string, record *Record) (*Record, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode idempotency record: %w", err)
+	}
+
+	for attempt := 0; attempt < maxCASAttempts; attempt++ {
+		if _, err := s.kv.Create(ctx, key, data); err == nil {
+			return nil, nil
+		} else if !errors.Is(err, jetstream.ErrKeyExists) {
+			return nil, err
+		}
+
+		entry, err := s.kv.Get(ctx, key)
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			// Libérée entre-temps
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var existing Record
+		if err := json.Unmarshal(entry.Value(), &existing); err != nil {
+			return nil, fmt.Errorf("invalid idempotency record %s: %w", key, err)
+		}
+		if !existing.abandoned(time.Now()) {
+			return &existing, nil
+		}
+
+		// Requête abandonnée (réplica arrêté pendant la requête) : reprendre la clé
+		if _, err := s.kv.Update(ctx, key, data, entry.Revision()); err == nil {
+			return nil, nil
+		} else if !errors.Is(err, jetstream.ErrKeyExists) {
+			return nil, err
+		}
+	}
+
+	return nil, ErrStoreContention
+}
+
+// Complete implémente Store (durée de vie : TTL du bucket)
+func (s *NATSStore) Complete(ctx context.Context, key string, record *Record, _ time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency record: %w", err)
+	}
+	_, err = s.kv.Put(ctx, key, data)
+	return err
+}
+
+// Release implémente Store
+func (s *NATSStore) Release(ctx context.Context, key string) error {
+	err := s.kv.Delete(ctx, key)
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package maintenance
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Drain instance retirée de la répartition (Draining) ou remise en service
+// Diffusé aux autres réplicas pour qu'aucun ne continue à lui envoyer du trafic.
+type Drain struct {
+	Service  string `json:"service"`
+	Instance string `json:"instance"`
+	Draining bool   `json:"draining"`
+}
+
+// key clé du drain dans le planificateur
+func (d Drain) key() string {
+	return d.Service + "/" + d.Instance
+}
+
+// DrainListener applique un drain reçu d'un autre réplica à la répartition locale
+type DrainListener func(service, instance string, draining bool) error
+
+// OnDrain enregistre la fonction qui applique les drains des autres réplicas (à appeler avant Start)
+func (s *Scheduler) OnDrain(listener DrainListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.drainListeners = append(s.drainListeners, listener)
+}
+
+// SetDraining enregistre le drain ou la remise en service d'une instance, déjà appliqué
+// par ce réplica, et le diffuse aux autres
+func (s *Scheduler) SetDraining(ctx context.Context, service, instance string, draining bool) {
+	drain := Drain{Service: service, Instance: instance, Draining: draining}
+	s.recordDrain(drain)
+	s.publish(ctx, windowUpdate{Origin: s.origin, Drain: &drain})
+}
+
+// Drains retourne les instances drainées, triées par service et instance
+func (s *Scheduler) Drains() []Drain {
+	s.mu.RLock()
+	drains := make([]Drain, 0, len(s.drains))
+	for _, drain := range s.drains {
+		drains = append(drains, drain)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(drains, func(i, j int) bool { return drains[i].key() < drains[j].key() })
+	return drains
+}
+
+// recordDrain garde les instances drainées, transmises aux réplicas qui démarrent
+func (s *Scheduler) recordDrain(drain Drain) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if drain.Draining {
+		s.drains[drain.key()] = drain
+	} else {
+		delete(s.drains, drain.key())
+	}
+}
+
+// applyDrain applique un drain décidé sur un autre réplica
+// Une instance inconnue de ce réplica (pas encore enregistrée ici) est ignorée.
+func (s *Scheduler) applyDrain(drain Drain) {
+	s.recordDrain(drain)
+
+	s.mu.RLock()
+	listeners := s.drainListeners
+	s.mu.RUnlock()
+
+	for _, listener := range listeners {
+		if err := listener(drain.Service, drain.Instance, drain.Draining); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"service":  drain.Service,
+				"instance": drain.Instance,
+				"draining": drain.Draining,
+			}).Warn("Failed to apply instance drain from another gateway replica")
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package maintenance
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Métriques Prometheus des fenêtres de maintenance
+var (
+	notices = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_maintenance_notices_total",
+			Help: "Total number of maintenance notices sent to WebSocket clients by event (scheduled, countdown, started, ended, cancelled)",
+		},
+		[]string{"event"},
+	)
+
+	activeWindows = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gateway_maintenance_active_windows",
+			Help: "Number of maintenance windows in progress",
+		},
+	)
+)
+
+// InitMetrics initialize les métriques Prometheus des fenêtres de maintenance
+func InitMetrics() {
+	prometheus.MustRegister(notices)
+	prometheus.MustRegister(activeWindows)
+}
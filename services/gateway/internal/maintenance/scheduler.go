@@ -0,0 +1,461 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gateway/internal/config"
+	"gateway/internal/tracing"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrWindowNotFound aucune fenêtre avec cet ID
+	ErrWindowNotFound = errors.New("maintenance window not found")
+	// ErrInvalidWindow dates ou durée de la fenêtre invalides
+	ErrInvalidWindow = errors.New("invalid maintenance window")
+)
+
+// windowUpdate fenêtre planifiée ou annulée, ou instance drainée, diffusée aux autres
+// réplicas du gateway
+type windowUpdate struct {
+	Origin    string  `json:"origin"`
+	Window    *Window `json:"window,omitempty"`
+	Cancelled string  `json:"cancelled,omitempty"` // ID de la fenêtre annulée
+	Drain     *Drain  `json:"drain,omitempty"`
+}
+
+// syncRequest demande des fenêtres en cours par un réplica qui démarre
+type syncRequest struct {
+	Origin string `json:"origin"`
+}
+
+// syncReply fenêtres et instances drainées connues d'un réplica
+type syncReply struct {
+	Windows []*Window `json:"windows"`
+	Drains  []Drain   `json:"drains,omitempty"`
+}
+
+// scheduled fenêtre suivie par le planificateur
+type scheduled struct {
+	window   *Window
+	notified int  // préavis déjà envoyés (index dans leadTimes)
+	started  bool // événement de début envoyé
+}
+
+// Scheduler planifie les fenêtres de maintenance et prévient les clients
+// Les fenêtres sont gardées en mémoire et diffusées aux autres réplicas par NATS ; un
+// réplica qui démarre demande les fenêtres en cours aux autres. Chaque réplica envoie
+// les préavis à ses propres clients WebSocket. Les drains d'instances suivent le même chemin.
+type Scheduler struct {
+	config     *config.MaintenanceConfig
+	natsConn   *nats.Conn
+	origin     string
+	leadTimes  []time.Duration // décroissants
+	staffRoles map[string]bool
+	staffUsers map[string]bool
+
+	mu        sync.RWMutex
+	windows   map[string]*scheduled
+	listeners []func(*Notice)
+
+	drains         map[string]Drain // instances drainées, par service et instance
+	drainListeners []DrainListener
+
+	subscriptions []*nats.Subscription
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+// NewScheduler crée le planificateur des fenêtres de maintenance
+// natsConn est optionnel : sans NATS, les fenêtres restent locales au réplica.
+func NewScheduler(cfg *config.MaintenanceConfig, natsConn *nats.Conn) *Scheduler {
+	s := &Scheduler{
+		config:     cfg,
+		natsConn:   natsConn,
+		origin:     uuid.New().String(),
+		leadTimes:  append([]time.Duration(nil), cfg.NoticeLeadTimes...),
+		staffRoles: make(map[string]bool, len(cfg.StaffRoles)),
+		staffUsers: make(map[string]bool, len(cfg.StaffUserIDs)),
+		windows:    make(map[string]*scheduled),
+		drains:     make(map[string]Drain),
+		done:       make(chan struct{}),
+	}
+
+	sort.Slice(s.leadTimes, func(i, j int) bool { return s.leadTimes[i] > s.leadTimes[j] })
+	for _, role := range cfg.StaffRoles {
+		if role = strings.TrimSpace(role); role != "" {
+			s.staffRoles[role] = true
+		}
+	}
+	for _, userID := range cfg.StaffUserIDs {
+		if userID = strings.TrimSpace(userID); userID != "" {
+			s.staffUsers[strings.ToLower(userID)] = true
+		}
+	}
+
+	return s
+}
+
+// OnNotice enregistre une fonction appelée pour chaque événement de fenêtre (à appeler avant Start)
+func (s *Scheduler) OnNotice(listener func(*Notice)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+// Start s'abonne aux fenêtres des autres réplicas, récupère celles en cours et lance
+// l'envoi des préavis
+func (s *Scheduler) Start() error {
+	go s.loop()
+
+	if s.natsConn == nil || s.config.Subject == "" {
+		return nil
+	}
+
+	updates, err := s.natsConn.Subscribe(s.config.Subject, s.handleUpdate)
+	if err != nil {
+		return err
+	}
+	syncs, err := s.natsConn.Subscribe(s.syncSubject(), s.handleSync)
+	if err != nil {
+		if unsubscribeErr := updates.Unsubscribe(); unsubscribeErr != nil {
+			logrus.WithError(unsubscribeErr).Debug("Failed to unsubscribe from maintenance updates")
+		}
+		return err
+	}
+
+	s.mu.Lock()
+	s.subscriptions = []*nats.Subscription{updates, syncs}
+	s.mu.Unlock()
+
+	s.syncFromPeers()
+	return nil
+}
+
+// Close arrête l'envoi des préavis et la réception des fenêtres
+func (s *Scheduler) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for _, subscription := range s.subscriptions {
+			if err := subscription.Unsubscribe(); err != nil {
+				logrus.WithError(err).Debug("Failed to unsubscribe from maintenance updates")
+			}
+		}
+		s.subscriptions = nil
+	})
+}
+
+// Schedule planifie une fenêtre (service vide : toute la plateforme) et la diffuse aux autres réplicas
+func (s *Scheduler) Schedule(ctx context.Context, service, message string, startsAt, endsAt time.Time) (*Window, error) {
+	now := time.Now()
+	if !endsAt.After(startsAt) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidWindow)
+	}
+	if !endsAt.After(now) {
+		return nil, fmt.Errorf("%w: ends_at is in the past", ErrInvalidWindow)
+	}
+	if endsAt.Sub(startsAt) > s.config.MaxDuration {
+		return nil, fmt.Errorf("%w: longer than %s", ErrInvalidWindow, s.config.MaxDuration)
+	}
+
+	window := &Window{
+		ID:        uuid.New().String(),
+		Service:   service,
+		Message:   message,
+		StartsAt:  startsAt.UTC(),
+		EndsAt:    endsAt.UTC(),
+		CreatedAt: now.UTC(),
+	}
+	s.add(window, now)
+	s.publish(ctx, windowUpdate{Origin: s.origin, Window: window})
+
+	logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"window_id": window.ID,
+		"service":   window.Service,
+		"starts_at": window.StartsAt,
+		"ends_at":   window.EndsAt,
+	}).Info("Maintenance window scheduled")
+
+	return window, nil
+}
+
+// Cancel annule une fenêtre, planifiée ou en cours, et diffuse l'annulation aux autres réplicas
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	if !s.remove(id, time.Now()) {
+		return ErrWindowNotFound
+	}
+	s.publish(ctx, windowUpdate{Origin: s.origin, Cancelled: id})
+
+	logrus.WithContext(ctx).WithField("window_id", id).Info("Maintenance window cancelled")
+	return nil
+}
+
+// Windows retourne les fenêtres planifiées et en cours, triées par date de début
+func (s *Scheduler) Windows() []*Window {
+	s.mu.RLock()
+	windows := make([]*Window, 0, len(s.windows))
+	for _, entry := range s.windows {
+		windows = append(windows, entry.window)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(windows, func(i, j int) bool { return windows[i].StartsAt.Before(windows[j].StartsAt) })
+	return windows
+}
+
+// Get retourne une fenêtre par son ID
+func (s *Scheduler) Get(id string) (*Window, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, exists := s.windows[id]
+	if !exists {
+		return nil, false
+	}
+	return entry.window, true
+}
+
+// Active retourne la fenêtre en cours du service, nil sinon
+// service vide : fenêtres de toute la plateforme, appliquées avant le routage avec leurs
+// routes exemptées. Quand plusieurs fenêtres se chevauchent, celle qui finit le plus tard
+// est retournée.
+func (s *Scheduler) Active(service string, now time.Time) *Window {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var active *Window
+	for _, entry := range s.windows {
+		window := entry.window
+		if !window.Active(now) || window.Service != service {
+			continue
+		}
+		if active == nil || window.EndsAt.After(active.EndsAt) {
+			active = window
+		}
+	}
+	return active
+}
+
+// Exempt indique si un compte staff garde l'accès pendant les fenêtres
+func (s *Scheduler) Exempt(userID, role string) bool {
+	return s.staffRoles[role] || (userID != "" && s.staffUsers[strings.ToLower(userID)])
+}
+
+// Méthodes privées
+
+// add enregistre une fenêtre et prévient les clients
+// Les préavis dont l'échéance est déjà passée ne sont pas envoyés : l'annonce les remplace.
+func (s *Scheduler) add(window *Window, now time.Time) {
+	entry := &scheduled{window: window}
+	for entry.notified < len(s.leadTimes) && !now.Before(window.StartsAt.Add(-s.leadTimes[entry.notified])) {
+		entry.notified++
+	}
+
+	s.mu.Lock()
+	s.windows[window.ID] = entry
+	s.mu.Unlock()
+
+	s.notify(newNotice(EventScheduled, window, now))
+	s.tick(now)
+}
+
+// remove retire une fenêtre et prévient les clients, false si elle n'existait pas
+func (s *Scheduler) remove(id string, now time.Time) bool {
+	s.mu.Lock()
+	entry, exists := s.windows[id]
+	delete(s.windows, id)
+	s.mu.Unlock()
+
+	if !exists {
+		return false
+	}
+	s.notify(newNotice(EventCancelled, entry.window, now))
+	return true
+}
+
+// loop envoie les préavis et les événements de début et de fin des fenêtres
+func (s *Scheduler) loop() {
+	ticker := time.NewTicker(s.config.TickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.tick(now)
+		}
+	}
+}
+
+// tick fait avancer chaque fenêtre : préavis, début, fin
+// Un seul préavis est envoyé quand plusieurs échéances sont franchies d'un coup.
+func (s *Scheduler) tick(now time.Time) {
+	var pending []*Notice
+	active := 0
+
+	s.mu.Lock()
+	for id, entry := range s.windows {
+		window := entry.window
+		switch {
+		case !now.Before(window.EndsAt):
+			delete(s.windows, id)
+			pending = append(pending, newNotice(EventEnded, window, now))
+		case !now.Before(window.StartsAt):
+			active++
+			if !entry.started {
+				entry.started = true
+				pending = append(pending, newNotice(EventStarted, window, now))
+			}
+		default:
+			due := entry.notified
+			for due < len(s.leadTimes) && !now.Before(window.StartsAt.Add(-s.leadTimes[due])) {
+				due++
+			}
+			if due > entry.notified {
+				entry.notified = due
+				pending = append(pending, newNotice(EventCountdown, window, now))
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	activeWindows.Set(float64(active))
+	for _, notice := range pending {
+		s.notify(notice)
+	}
+}
+
+// notify transmet un événement aux fonctions enregistrées
+func (s *Scheduler) notify(notice *Notice) {
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+
+	notices.WithLabelValues(notice.Event).Inc()
+	logrus.WithFields(logrus.Fields{
+		"window_id": notice.Window.ID,
+		"service":   notice.Window.Service,
+		"event":     notice.Event,
+		"starts_in": notice.StartsIn,
+	}).Info("Maintenance notice")
+
+	for _, listener := range listeners {
+		listener(notice)
+	}
+}
+
+// handleUpdate applique une fenêtre planifiée ou annulée sur un autre réplica
+func (s *Scheduler) handleUpdate(msg *nats.Msg) {
+	ctx, span := tracing.StartConsumerSpan(msg)
+	defer span.End()
+
+	var update windowUpdate
+	if err := json.Unmarshal(msg.Data, &update); err != nil {
+		logrus.WithContext(ctx).WithError(err).Warn("Invalid maintenance window update")
+		return
+	}
+	if update.Origin == s.origin {
+		return
+	}
+
+	now := time.Now()
+	switch {
+	case update.Window != nil:
+		s.add(update.Window, now)
+	case update.Cancelled != "":
+		s.remove(update.Cancelled, now)
+	case update.Drain != nil:
+		s.applyDrain(*update.Drain)
+	default:
+		return
+	}
+	logrus.WithContext(ctx).Info("Maintenance windows updated by another gateway replica")
+}
+
+// handleSync répond à un réplica qui démarre avec les fenêtres connues
+func (s *Scheduler) handleSync(msg *nats.Msg) {
+	var request syncRequest
+	if err := json.Unmarshal(msg.Data, &request); err != nil || request.Origin == s.origin {
+		return
+	}
+
+	data, err := json.Marshal(syncReply{Windows: s.Windows(), Drains: s.Drains()})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode maintenance windows")
+		return
+	}
+	if err := msg.Respond(data); err != nil {
+		logrus.WithError(err).Debug("Failed to answer maintenance windows sync")
+	}
+}
+
+// syncFromPeers récupère les fenêtres en cours auprès d'un autre réplica
+// Sans réponse (premier réplica, NATS indisponible), le réplica démarre sans fenêtre.
+func (s *Scheduler) syncFromPeers() {
+	data, err := json.Marshal(syncRequest{Origin: s.origin})
+	if err != nil {
+		return
+	}
+
+	msg, err := s.natsConn.Request(s.syncSubject(), data, s.config.SyncTimeout)
+	if err != nil {
+		logrus.WithError(err).Debug("No maintenance windows received from other gateway replicas")
+		return
+	}
+
+	var reply syncReply
+	if err := json.Unmarshal(msg.Data, &reply); err != nil {
+		logrus.WithError(err).Warn("Invalid maintenance windows sync reply")
+		return
+	}
+
+	now := time.Now()
+	for _, window := range reply.Windows {
+		if window.EndsAt.After(now) {
+			s.add(window, now)
+		}
+	}
+	for _, drain := range reply.Drains {
+		s.applyDrain(drain)
+	}
+	logrus.WithFields(logrus.Fields{
+		"windows": len(reply.Windows),
+		"drains":  len(reply.Drains),
+	}).Info("Maintenance windows received from another gateway replica")
+}
+
+// syncSubject sujet NATS des demandes de fenêtres en cours
+func (s *Scheduler) syncSubject() string {
+	return s.config.Subject + ".sync"
+}
+
+// publish diffuse une fenêtre planifiée ou annulée (sans NATS, le changement reste local)
+func (s *Scheduler) publish(ctx context.Context, update windowUpdate) {
+	if s.natsConn == nil || s.config.Subject == "" {
+		return
+	}
+
+	data, err := json.Marshal(update)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode maintenance window update")
+		return
+	}
+	if err := tracing.Publish(ctx, s.natsConn, s.config.Subject, data); err != nil {
+		logrus.WithContext(ctx).WithError(err).WithField("subject", s.config.Subject).Warn("Failed to publish maintenance window update")
+	}
+}
@@ -0,0 +1,241 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"gateway/internal/config"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestScheduler planificateur sans NATS dont les événements sont enregistrés
+// La boucle de préavis n'est pas lancée : les tests appellent tick eux-mêmes.
+func newTestScheduler(t *testing.T) (*Scheduler, func() []string) {
+	t.Helper()
+
+	scheduler := NewScheduler(&config.MaintenanceConfig{
+		NoticeLeadTimes: []time.Duration{5 * time.Minute, time.Hour, 15 * time.Minute},
+		StaffRoles:      []string{"admin", " "},
+		StaffUserIDs:    []string{"0A1B2C3D-4E5F-4A6B-8C7D-8E9F0A1B2C3D"},
+		MaxDuration:     4 * time.Hour,
+		TickInterval:    time.Second,
+	}, nil)
+
+	var mu sync.Mutex
+	var events []string
+	scheduler.OnNotice(func(notice *Notice) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, notice.Event)
+	})
+
+	return scheduler, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		recorded := events
+		events = nil
+		return recorded
+	}
+}
+
+func equalEvents(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestScheduleValidation(t *testing.T) {
+	scheduler, _ := newTestScheduler(t)
+	now := time.Now()
+
+	tests := []struct {
+		name             string
+		startsAt, endsAt time.Time
+	}{
+		{"ends before start", now.Add(time.Hour), now.Add(time.Minute)},
+		{"ends in the past", now.Add(-time.Hour), now.Add(-time.Minute)},
+		{"too long", now, now.Add(5 * time.Hour)},
+	}
+	for _, tt := range tests {
+		if _, err := scheduler.Schedule(context.Background(), "", "", tt.startsAt, tt.endsAt); !errors.Is(err, ErrInvalidWindow) {
+			t.Errorf("%s: error = %v, want ErrInvalidWindow", tt.name, err)
+		}
+	}
+	if len(scheduler.Windows()) != 0 {
+		t.Errorf("invalid windows kept: %d", len(scheduler.Windows()))
+	}
+}
+
+// TestSchedulerLifecycle annonce, préavis dans l'ordre décroissant, début puis fin de la fenêtre
+func TestSchedulerLifecycle(t *testing.T) {
+	scheduler, events := newTestScheduler(t)
+	now := time.Now()
+	startsAt := now.Add(2 * time.Hour)
+
+	window, err := scheduler.Schedule(context.Background(), "combat", "Patch 1.2", startsAt, startsAt.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if got := events(); !equalEvents(got, []string{EventScheduled}) {
+		t.Fatalf("events after schedule = %v", got)
+	}
+
+	steps := []struct {
+		at   time.Time
+		want []string
+	}{
+		{startsAt.Add(-90 * time.Minute), nil},
+		{startsAt.Add(-time.Hour), []string{EventCountdown}},
+		{startsAt.Add(-30 * time.Minute), nil},
+		// Deux échéances franchies d'un coup : un seul préavis
+		{startsAt.Add(-time.Minute), []string{EventCountdown}},
+		{startsAt, []string{EventStarted}},
+		{startsAt.Add(time.Minute), nil},
+		{window.EndsAt, []string{EventEnded}},
+	}
+	for _, step := range steps {
+		scheduler.tick(step.at)
+		if got := events(); !equalEvents(got, step.want) {
+			t.Errorf("tick at %s: events = %v, want %v", step.at.Sub(startsAt), got, step.want)
+		}
+	}
+
+	if _, exists := scheduler.Get(window.ID); exists {
+		t.Error("ended window still scheduled")
+	}
+}
+
+// TestScheduleSkipsPastLeadTimes une fenêtre planifiée tardivement n'envoie pas les préavis échus
+func TestScheduleSkipsPastLeadTimes(t *testing.T) {
+	scheduler, events := newTestScheduler(t)
+	startsAt := time.Now().Add(10 * time.Minute)
+
+	if _, err := scheduler.Schedule(context.Background(), "", "", startsAt, startsAt.Add(time.Hour)); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	events()
+
+	scheduler.tick(startsAt.Add(-6 * time.Minute))
+	if got := events(); len(got) != 0 {
+		t.Errorf("events before the 5 minute notice = %v, want none", got)
+	}
+	scheduler.tick(startsAt.Add(-5 * time.Minute))
+	if got := events(); !equalEvents(got, []string{EventCountdown}) {
+		t.Errorf("events at the 5 minute notice = %v", got)
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	scheduler, events := newTestScheduler(t)
+	now := time.Now()
+
+	window, err := scheduler.Schedule(context.Background(), "", "", now, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	events()
+
+	if err := scheduler.Cancel(context.Background(), window.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if got := events(); !equalEvents(got, []string{EventCancelled}) {
+		t.Errorf("events after cancel = %v", got)
+	}
+	if scheduler.Active("", now.Add(time.Minute)) != nil {
+		t.Error("cancelled window still active")
+	}
+	if err := scheduler.Cancel(context.Background(), window.ID); !errors.Is(err, ErrWindowNotFound) {
+		t.Errorf("second cancel error = %v, want ErrWindowNotFound", err)
+	}
+}
+
+// TestSchedulerActive les fenêtres d'un service ne couvrent pas la plateforme ; la plus longue l'emporte
+func TestSchedulerActive(t *testing.T) {
+	scheduler, _ := newTestScheduler(t)
+	now := time.Now()
+
+	short, _ := scheduler.Schedule(context.Background(), "", "", now, now.Add(time.Hour))
+	long, _ := scheduler.Schedule(context.Background(), "", "", now, now.Add(2*time.Hour))
+	service, _ := scheduler.Schedule(context.Background(), "chat", "", now, now.Add(time.Hour))
+
+	at := now.Add(time.Minute)
+	if active := scheduler.Active("", at); active == nil || active.ID != long.ID {
+		t.Errorf("platform window = %v, want %s (not %s)", active, long.ID, short.ID)
+	}
+	if active := scheduler.Active("chat", at); active == nil || active.ID != service.ID {
+		t.Errorf("chat window = %v, want %s", active, service.ID)
+	}
+	if active := scheduler.Active("combat", at); active != nil {
+		t.Errorf("combat window = %s, want none", active.ID)
+	}
+	if active := scheduler.Active("", now.Add(-time.Minute)); active != nil {
+		t.Errorf("window active before its start: %s", active.ID)
+	}
+}
+
+func TestSchedulerExempt(t *testing.T) {
+	scheduler, _ := newTestScheduler(t)
+
+	tests := []struct {
+		userID, role string
+		want         bool
+	}{
+		{"", "admin", true},
+		{"0a1b2c3d-4e5f-4a6b-8c7d-8e9f0a1b2c3d", "player", true},
+		{"9f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a", "player", false},
+		{"", "", false},
+		{"", " ", false},
+	}
+	for _, tt := range tests {
+		if got := scheduler.Exempt(tt.userID, tt.role); got != tt.want {
+			t.Errorf("Exempt(%q, %q) = %v, want %v", tt.userID, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestWindowRetryAfter(t *testing.T) {
+	now := time.Now()
+	window := &Window{StartsAt: now, EndsAt: now.Add(90*time.Second + time.Millisecond)}
+
+	if got := window.RetryAfter(now); got != 91 {
+		t.Errorf("RetryAfter = %d, want 91", got)
+	}
+	if got := window.RetryAfter(window.EndsAt.Add(time.Minute)); got != 1 {
+		t.Errorf("RetryAfter after the end = %d, want 1", got)
+	}
+}
+
+// TestDrains les drains reçus des autres réplicas sont gardés et appliqués localement
+func TestDrains(t *testing.T) {
+	scheduler, _ := newTestScheduler(t)
+
+	var applied []Drain
+	scheduler.OnDrain(func(service, instance string, draining bool) error {
+		applied = append(applied, Drain{Service: service, Instance: instance, Draining: draining})
+		return errors.New("unknown instance")
+	})
+
+	scheduler.SetDraining(context.Background(), "combat", "combat-2", true)
+	scheduler.applyDrain(Drain{Service: "chat", Instance: "chat-1", Draining: true})
+
+	drains := scheduler.Drains()
+	if len(drains) != 2 || drains[0].key() != "chat/chat-1" || drains[1].key() != "combat/combat-2" {
+		t.Fatalf("Drains = %v", drains)
+	}
+	// SetDraining est déjà appliqué par l'appelant : seul le drain reçu passe par les listeners
+	if len(applied) != 1 || applied[0].Instance != "chat-1" {
+		t.Errorf("drains applied = %v", applied)
+	}
+
+	scheduler.applyDrain(Drain{Service: "chat", Instance: "chat-1"})
+	if drains = scheduler.Drains(); len(drains) != 1 || drains[0].Instance != "combat-2" {
+		t.Errorf("Drains after resume = %v", drains)
+	}
+}
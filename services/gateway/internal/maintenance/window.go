@@ -0,0 +1,70 @@
+package maintenance
+
+import (
+	"math"
+	"time"
+)
+
+// Événements des fenêtres, diffusés aux clients WebSocket
+const (
+	EventScheduled = "scheduled"
+	EventCountdown = "countdown" // préavis avant le début
+	EventStarted   = "started"
+	EventEnded     = "ended"
+	EventCancelled = "cancelled"
+
+	noticeType = "maintenance"
+)
+
+// Window fenêtre de maintenance planifiée
+// Sans service, la fenêtre couvre toute la plateforme.
+type Window struct {
+	ID        string    `json:"id"`
+	Service   string    `json:"service,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Platform indique une fenêtre qui couvre toute la plateforme
+func (w *Window) Platform() bool {
+	return w.Service == ""
+}
+
+// Active indique si la fenêtre est en cours
+func (w *Window) Active(now time.Time) bool {
+	return !now.Before(w.StartsAt) && now.Before(w.EndsAt)
+}
+
+// RetryAfter secondes restantes avant la fin de la fenêtre (au moins 1), pour l'en-tête Retry-After
+func (w *Window) RetryAfter(now time.Time) int {
+	seconds := int(math.Ceil(w.EndsAt.Sub(now).Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// Notice message envoyé aux clients WebSocket (bannières de maintenance)
+type Notice struct {
+	Type     string  `json:"type"` // toujours "maintenance"
+	Event    string  `json:"event"`
+	Window   *Window `json:"window"`
+	StartsIn int     `json:"starts_in,omitempty"` // secondes avant le début (préavis)
+	Time     int64   `json:"time"`
+}
+
+// newNotice prépare le message d'un événement de fenêtre
+func newNotice(event string, window *Window, now time.Time) *Notice {
+	notice := &Notice{
+		Type:   noticeType,
+		Event:  event,
+		Window: window,
+		Time:   now.Unix(),
+	}
+	if now.Before(window.StartsAt) {
+		notice.StartsIn = int(math.Ceil(window.StartsAt.Sub(now).Seconds()))
+	}
+	return notice
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"bytes"
+	"gateway/internal/abuse"
+	"io"
+	"mmorpg/pkg/jwks"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// replayBody corps de requête remis en place après lecture de son début
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// Abuse middleware de détection des abus
+// Les IP de la liste deny et les clients bloqués sont refusés (403) ; les autres requêtes
+// sont analysées une fois la réponse connue (statut, chemin, écritures rejouées).
+func Abuse(detector *abuse.Detector, keys *jwks.KeySet, idempotencyHeader string, maxBodySize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if detector.Exempt(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		ip := c.ClientIP()
+		var userID string
+		if claims, ok := claimsFromRequest(c, keys); ok {
+			userID = claims.UserID.String()
+		}
+
+		if verdict := detector.Check(ip, userID); verdict != nil {
+			abortAbuse(c, verdict, ip, userID)
+			return
+		}
+
+		signature := replaySignature(c, idempotencyHeader, maxBodySize)
+
+		c.Next()
+
+		detector.Observe(c.Request.Context(), &abuse.Observation{
+			IP:        ip,
+			UserID:    userID,
+			Path:      c.Request.URL.Path,
+			Status:    c.Writer.Status(),
+			Signature: signature,
+		})
+	}
+}
+
+// abortAbuse refuse la requête d'un client bloqué ou d'une IP de la liste deny
+func abortAbuse(c *gin.Context, verdict *abuse.Verdict, ip, userID string) {
+	logrus.WithFields(logrus.Fields{
+		"reason":     verdict.Reason,
+		"client_ip":  ip,
+		"user_id":    userID,
+		"path":       c.Request.URL.Path,
+		"request_id": c.GetHeader("X-Request-ID"),
+	}).Debug("Request rejected by abuse detection")
+
+	if verdict.Block == nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":      "Access denied",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	retryAfter := verdict.Block.RetryAfter(time.Now())
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error":       "Access temporarily blocked",
+		"message":     "Suspicious activity detected, please try again later",
+		"retry_after": retryAfter,
+		"request_id":  c.GetHeader("X-Request-ID"),
+	})
+}
+
+// replaySignature empreinte d'une écriture sans clé d'idempotence, vide sinon
+// Une écriture avec clé d'idempotence est une reprise légitime, gérée par Idempotency. Au-delà
+// de maxBodySize, la requête n'a pas d'empreinte.
+func replaySignature(c *gin.Context, idempotencyHeader string, maxBodySize int64) string {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ""
+	}
+	if idempotencyHeader != "" && c.GetHeader(idempotencyHeader) != "" {
+		return ""
+	}
+
+	body, ok := peekBody(c, maxBodySize)
+	if !ok {
+		return ""
+	}
+	return abuse.Signature(c.Request.Method, c.Request.URL.RequestURI(), body)
+}
+
+// peekBody lit le corps de la requête jusqu'à maxBodySize et le remet en place
+// ok vaut false si le corps dépasse maxBodySize (il n'est alors lu qu'en partie, puis remis en place).
+func peekBody(c *gin.Context, maxBodySize int64) (body []byte, ok bool) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil, true
+	}
+	if c.Request.ContentLength > maxBodySize {
+		return nil, false
+	}
+
+	read, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize+1))
+	c.Request.Body = replayBody{Reader: io.MultiReader(bytes.NewReader(read), c.Request.Body), Closer: c.Request.Body}
+	if err != nil || int64(len(read)) > maxBodySize {
+		return nil, false
+	}
+	return read, true
+}
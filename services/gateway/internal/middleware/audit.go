@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"gateway/internal/audit"
+	"mmorpg/pkg/jwks"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// auditCharacterHeader personnage actif envoyé par le client, quand la route ne le nomme pas
+const auditCharacterHeader = "X-Character-ID"
+
+// Audit middleware du journal d'audit des écritures
+// L'utilisateur est lu après le traitement (posé par JWTAuth sur les routes protégées), à
+// défaut depuis le token de la requête. Pour les routes configurées, les corps JSON de la
+// requête et de la réponse sont gardés après masquage.
+func Audit(recorder *audit.Recorder, keys *jwks.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !recorder.Audited(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		var requestBody []byte
+		var writer *auditWriter
+		if recorder.KeepsBodies(c.Request.Method, route) {
+			requestBody, _ = peekBody(c, recorder.MaxBodySize())
+			writer = &auditWriter{ResponseWriter: c.Writer, limit: recorder.MaxBodySize()}
+			c.Writer = writer
+		}
+
+		c.Next()
+
+		record := &audit.Record{
+			ID:          uuid.New().String(),
+			Time:        start.UTC(),
+			RequestID:   c.GetHeader("X-Request-ID"),
+			Method:      c.Request.Method,
+			Route:       route,
+			Path:        c.Request.URL.Path,
+			Service:     extractServiceFromPath(c.Request.URL.Path),
+			Status:      c.Writer.Status(),
+			LatencyMs:   float64(time.Since(start)) / float64(time.Millisecond),
+			CharacterID: auditCharacterID(c),
+			ClientIP:    c.ClientIP(),
+		}
+		auditUser(c, record, keys)
+
+		if requestBody != nil {
+			record.Request = recorder.Redact(requestBody)
+		}
+		if writer != nil && !writer.overflow {
+			record.Response = recorder.Redact(writer.body.Bytes())
+		}
+
+		recorder.Record(record)
+	}
+}
+
+// auditUser renseigne l'utilisateur de la requête
+func auditUser(c *gin.Context, record *audit.Record, keys *jwks.KeySet) {
+	if userID, ok := GetUserIDFromContext(c); ok {
+		record.UserID = userID.String()
+		record.Username = c.GetString("username")
+		record.Role = c.GetString("user_role")
+		return
+	}
+
+	if claims, ok := claimsFromRequest(c, keys); ok {
+		record.UserID = claims.UserID.String()
+		record.Username = claims.Username
+		record.Role = claims.Role
+	}
+}
+
+// auditCharacterID personnage concerné : paramètre de route, ?character= ou header X-Character-ID
+func auditCharacterID(c *gin.Context) string {
+	if characterID := c.Param("characterId"); characterID != "" {
+		return characterID
+	}
+	if strings.Contains(c.FullPath(), "/characters/:id") {
+		return c.Param("id")
+	}
+	if characterID := c.Query("character"); characterID != "" {
+		return characterID
+	}
+	return c.GetHeader(auditCharacterHeader)
+}
+
+// auditWriter transmet la réponse au client et en garde une copie pour le journal
+type auditWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int64
+	overflow bool // réponse trop grande pour être gardée
+}
+
+// Write transmet le corps et en garde une copie tant qu'il tient dans la limite
+func (w *auditWriter) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implémente gin.ResponseWriter
+func (w *auditWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// keep garde une partie du corps
+func (w *auditWriter) keep(data []byte) {
+	if w.overflow {
+		return
+	}
+	if int64(w.body.Len()+len(data)) > w.limit {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
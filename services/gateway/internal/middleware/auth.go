@@ -1,7 +1,11 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"errors"
+	"gateway/internal/proxy"
+	"gateway/internal/revocation"
+	"mmorpg/pkg/jwks"
 	"net/http"
 	"strings"
 
@@ -16,21 +20,32 @@ const (
 	RoleAdmin     = "admin"
 	RoleModerator = "moderator"
 	RoleUser      = "user"
+	RoleService   = "service"
+	RoleSuperUser = "superuser"
 )
 
+// tokenTypeAccess type des tokens d'accès des joueurs
+// Les refresh tokens, challenges 2FA et tokens de service, signés par les mêmes clés, sont refusés.
+const tokenTypeAccess = "access"
+
 // JWTClaims reprÃ©sente les claims du JWT
 type JWTClaims struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Username string    `json:"username"`
-	Role     string    `json:"role"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	SessionID uuid.UUID `json:"session_id"`
+	TokenType string    `json:"token_type"`
+	MFA       bool      `json:"mfa"` // session ouverte avec un code 2FA
 	jwt.RegisteredClaims
 }
 
 // JWTAuth middleware d'authentification JWT pour le gateway
-func JWTAuth(jwtSecret string) gin.HandlerFunc {
+// Les tokens sont vérifiés avec les clés publiques du service auth (keys).
+// revocations est optionnel : sans lui, seules la signature et l'expiration sont vérifiées.
+func JWTAuth(keys *jwks.KeySet, revocations *revocation.Checker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// RÃ©cupÃ©rer le token depuis l'en-tÃªte Authorization
-		authHeader := c.GetHeader("Authorization")
+		authHeader := authorizationHeader(c)
 		if authHeader == "" {
 			logrus.WithFields(logrus.Fields{
 				"path":       c.Request.URL.Path,
@@ -69,13 +84,7 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 		tokenString := tokenParts[1]
 
 		// Parser et valider le token
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			// VÃ©rifier la mÃ©thode de signature
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(jwtSecret), nil
-		})
+		token, err := parseToken(tokenString, keys)
 		if err != nil {
 			logrus.WithFields(logrus.Fields{
 				"error":      err.Error(),
@@ -107,6 +116,25 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 
 		// VÃ©rifier les claims
 		if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+			// Seuls les tokens d'accès ouvrent les routes protégées
+			if claims.TokenType != tokenTypeAccess {
+				logrus.WithFields(logrus.Fields{
+					"token_type": claims.TokenType,
+					"user_id":    claims.UserID,
+					"path":       c.Request.URL.Path,
+					"client_ip":  c.ClientIP(),
+					"request_id": c.GetHeader("X-Request-ID"),
+				}).Warn("Non-access token rejected")
+
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error":      "Invalid token claims",
+					"message":    "An access token is required",
+					"request_id": c.GetHeader("X-Request-ID"),
+				})
+				c.Abort()
+				return
+			}
+
 			// Validation supplÃ©mentaire des claims
 			if claims.UserID == uuid.Nil {
 				logrus.WithFields(logrus.Fields{
@@ -142,10 +170,16 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 				return
 			}
 
+			// Refuser les tokens révoqués (logout, session révoquée, compte suspendu ou banni)
+			if revocations != nil && !checkRevocation(c, revocations, tokenString, claims) {
+				return
+			}
+
 			// Ajouter les informations utilisateur au contexte
 			c.Set("user_id", claims.UserID)
 			c.Set("username", claims.Username)
 			c.Set("user_role", claims.Role)
+			c.Set("two_factor", claims.MFA)
 
 			// Ajouter des en-tÃªtes pour les services downstream
 			c.Header("X-User-ID", claims.UserID.String())
@@ -184,8 +218,89 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 	}
 }
 
+// checkRevocation vérifie que le token n'a pas été révoqué ; répond et retourne false sinon
+func checkRevocation(c *gin.Context, revocations *revocation.Checker, tokenString string, claims *JWTClaims) bool {
+	token := &revocation.Token{
+		Raw:       tokenString,
+		UserID:    claims.UserID,
+		SessionID: claims.SessionID,
+	}
+	if claims.IssuedAt != nil {
+		token.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		token.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	revoked, err := revocations.Revoked(c.Request.Context(), token)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":      "Authentication temporarily unavailable",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return false
+	}
+	if revoked {
+		logrus.WithFields(logrus.Fields{
+			"user_id":    claims.UserID,
+			"session_id": claims.SessionID,
+			"path":       c.Request.URL.Path,
+			"client_ip":  c.ClientIP(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		}).Warn("Revoked token rejected")
+
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error":      "Token validation failed",
+			"message":    "Token has been revoked, please login again",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return false
+	}
+	return true
+}
+
+// authorizationHeader retourne l'en-tête Authorization de la requête
+// Les navigateurs ne pouvant pas poser d'en-tête sur un upgrade WebSocket, le token
+// est alors accepté dans le paramètre access_token.
+func authorizationHeader(c *gin.Context) string {
+	if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+		return authHeader
+	}
+	if proxy.IsWebSocketUpgrade(c.Request) {
+		if token := c.Query("access_token"); token != "" {
+			return "Bearer " + token
+		}
+	}
+	return ""
+}
+
+// parseToken valide un token JWT signé par une clé publiée par le service auth
+// Seules les signatures asymétriques (RS256, EdDSA) sont acceptées.
+func parseToken(tokenString string, keys *jwks.KeySet) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, &JWTClaims{}, keys.Keyfunc, jwt.WithValidMethods(jwks.Algorithms()))
+}
+
+// claimsFromRequest retourne les claims d'un token valide présent dans la requête
+// Sans token, ou avec un token invalide, la requête est considérée comme anonyme.
+func claimsFromRequest(c *gin.Context, keys *jwks.KeySet) (*JWTClaims, bool) {
+	tokenString, found := strings.CutPrefix(authorizationHeader(c), "Bearer ")
+	if !found || tokenString == "" {
+		return nil, false
+	}
+
+	token, err := parseToken(tokenString, keys)
+	if err != nil {
+		return nil, false
+	}
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid || claims.TokenType != tokenTypeAccess || claims.UserID == uuid.Nil {
+		return nil, false
+	}
+	return claims, true
+}
+
 // OptionalJWTAuth middleware d'authentification JWT optionnelle
-func OptionalJWTAuth(jwtSecret string) gin.HandlerFunc {
+func OptionalJWTAuth(keys *jwks.KeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -210,19 +325,15 @@ func OptionalJWTAuth(jwtSecret string) gin.HandlerFunc {
 
 		tokenString := tokenParts[1]
 
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(jwtSecret), nil
-		})
+		token, err := parseToken(tokenString, keys)
 
 		if err == nil {
-			if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+			if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid && claims.TokenType == tokenTypeAccess {
 				// Token valide, ajouter les infos au contexte
 				c.Set("user_id", claims.UserID)
 				c.Set("username", claims.Username)
 				c.Set("user_role", claims.Role)
+				c.Set("two_factor", claims.MFA)
 
 				// Headers pour les services downstream
 				c.Header("X-User-ID", claims.UserID.String())
@@ -289,6 +400,11 @@ func IsAdmin(c *gin.Context) bool {
 	return exists && role == RoleAdmin
 }
 
+// IsStaffRole indique si le rôle est un rôle staff (modération ou administration)
+func IsStaffRole(role string) bool {
+	return role == RoleModerator || role == RoleAdmin || role == RoleSuperUser
+}
+
 // IsModerator vérifie si l'utilisateur connecté est un modérateur ou admin
 func IsModerator(c *gin.Context) bool {
 	role, exists := GetUserRoleFromContext(c)
@@ -353,3 +469,37 @@ func RequireOwnership(getResourceOwnerID func(*gin.Context) (uuid.UUID, error))
 		c.Next()
 	}
 }
+
+// RegistryAuth protège l'API du registre des services par un token partagé (header X-Registry-Token)
+// Sans token configuré, l'API est désactivée, quel que soit l'environnement.
+func RegistryAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":      "Registry API disabled",
+				"message":    "No registry token configured",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			c.Abort()
+			return
+		}
+
+		provided := c.GetHeader("X-Registry-Token")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			logrus.WithFields(logrus.Fields{
+				"path":       c.Request.URL.Path,
+				"client_ip":  c.ClientIP(),
+				"request_id": c.GetHeader("X-Request-ID"),
+			}).Warn("Invalid registry token")
+
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":      "Invalid registry token",
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"bytes"
+	"gateway/internal/cache"
+	"gateway/internal/proxy"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// cachedHeaders headers de la réponse du backend conservés avec l'entrée
+// Les autres (rate limiting, CORS, request ID...) sont propres à chaque requête.
+var cachedHeaders = []string{
+	"Content-Type",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Disposition",
+	"Cache-Control",
+	"Last-Modified",
+	"ETag",
+	"Vary",
+	"X-Gateway-Service",
+}
+
+// ResponseCache middleware de cache des réponses GET configurées
+// À placer après JWTAuth : une entrée n'est servie qu'à une requête authentifiée, et la
+// clé des routes propres à chaque utilisateur contient son ID. Les requêtes conditionnelles
+// (If-None-Match) sont traitées par le gateway ; une entrée expirée est revalidée auprès
+// du backend avec son ETag. Les écritures réussies invalident les entrées déclarées.
+func ResponseCache(responseCache *cache.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			if c.Writer.Status() < http.StatusBadRequest {
+				responseCache.InvalidateWrite(c.Request.Context(), c.Request.Method, route, c.Param)
+			}
+			return
+		}
+
+		policy := responseCache.Policy(route)
+		if policy == nil || proxy.IsWebSocketUpgrade(c.Request) {
+			c.Next()
+			return
+		}
+
+		userID, authenticated := GetUserIDFromContext(c)
+		if policy.VaryByUser && !authenticated {
+			c.Next()
+			return
+		}
+
+		key := responseCache.Key(policy, c.Request, userID.String())
+		clientETag := c.GetHeader("If-None-Match")
+
+		entry, found := responseCache.Get(key)
+		if found && entry.Fresh(time.Now()) {
+			responseCache.Record(policy, cache.ResultHit)
+			writeCachedResponse(c, policy, entry, clientETag, cache.ResultHit)
+			c.Abort()
+			return
+		}
+
+		// Le backend doit renvoyer la réponse complète, sauf pour revalider l'entrée expirée
+		c.Request.Header.Del("If-None-Match")
+		c.Request.Header.Del("If-Modified-Since")
+		if found {
+			c.Request.Header.Set("If-None-Match", entry.ETag)
+		}
+
+		writer := &cacheWriter{
+			ResponseWriter: c.Writer,
+			status:         http.StatusOK,
+			limit:          responseCache.MaxEntrySize(),
+		}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.passthrough {
+			// Réponse trop grande, déjà transmise au client
+			responseCache.Record(policy, cache.ResultBypass)
+			return
+		}
+
+		tags := policy.Tags(c.Param)
+		switch {
+		case found && writer.status == http.StatusNotModified:
+			entry = responseCache.Refresh(policy, key, entry, tags)
+			responseCache.Record(policy, cache.ResultRevalidated)
+			writeCachedResponse(c, policy, entry, clientETag, cache.ResultRevalidated)
+		case isCacheable(writer, policy):
+			entry = cache.NewEntry(writer.status, storedHeaders(writer.Header()), writer.body.Bytes(), policy.TTL)
+			responseCache.Store(key, entry, tags)
+			responseCache.Record(policy, cache.ResultMiss)
+			writeCachedResponse(c, policy, entry, clientETag, cache.ResultMiss)
+		default:
+			responseCache.Record(policy, cache.ResultBypass)
+			c.Header("X-Cache", strings.ToUpper(cache.ResultBypass))
+			if err := writer.commit(); err != nil {
+				logrus.WithError(err).Debug("Failed to write uncached response")
+			}
+		}
+	}
+}
+
+// isCacheable indique si la réponse du backend peut être mise en cache
+func isCacheable(writer *cacheWriter, policy *cache.Policy) bool {
+	if writer.status != http.StatusOK || writer.Header().Get("Set-Cookie") != "" {
+		return false
+	}
+	cacheControl := strings.ToLower(writer.Header().Get("Cache-Control"))
+	if strings.Contains(cacheControl, "no-store") {
+		return false
+	}
+	// Une réponse privée n'est partagée qu'entre les requêtes du même utilisateur
+	return policy.VaryByUser || !strings.Contains(cacheControl, "private")
+}
+
+// storedHeaders copie les headers de la réponse gardés dans le cache
+func storedHeaders(header http.Header) http.Header {
+	stored := make(http.Header)
+	for _, name := range cachedHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			stored[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		}
+	}
+	return stored
+}
+
+// writeCachedResponse envoie une entrée du cache, ou 304 si le client a déjà cette version
+func writeCachedResponse(c *gin.Context, policy *cache.Policy, entry *cache.Entry, ifNoneMatch, result string) {
+	header := c.Writer.Header()
+	header.Del("Content-Length")
+	for name, values := range entry.Header {
+		header[name] = append([]string(nil), values...)
+	}
+	header.Set("ETag", entry.ETag)
+	if header.Get("Cache-Control") == "" {
+		// Le client garde la réponse mais la revalide à chaque utilisation
+		if policy.VaryByUser {
+			header.Set("Cache-Control", "private, no-cache")
+		} else {
+			header.Set("Cache-Control", "no-cache")
+		}
+	}
+	header.Set("Age", strconv.Itoa(int(time.Since(entry.StoredAt).Seconds())))
+	header.Set("X-Cache", strings.ToUpper(result))
+
+	if entry.Matches(ifNoneMatch) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(entry.Body)))
+	c.Status(entry.Status)
+	c.Writer.WriteHeaderNow()
+	_, _ = c.Writer.Write(entry.Body)
+}
+
+// cacheWriter garde la réponse du backend en mémoire pour la mettre en cache
+// Au-delà de la limite, la réponse est transmise au client au fil de l'eau.
+type cacheWriter struct {
+	gin.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	limit       int64
+	passthrough bool
+}
+
+// WriteHeader retient le statut jusqu'à l'envoi de la réponse
+func (w *cacheWriter) WriteHeader(code int) {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code > 0 {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow n'envoie les headers qu'en transmission directe
+func (w *cacheWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Write garde le corps, ou le transmet s'il dépasse la taille d'une entrée
+func (w *cacheWriter) Write(data []byte) (int, error) {
+	if !w.passthrough && int64(w.body.Len()+len(data)) > w.limit {
+		if err := w.commit(); err != nil {
+			return 0, err
+		}
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+// WriteString implémente gin.ResponseWriter
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Status retourne le statut retenu
+func (w *cacheWriter) Status() int {
+	return w.status
+}
+
+// Written indique si une réponse a commencé
+func (w *cacheWriter) Written() bool {
+	return w.passthrough || w.body.Len() > 0
+}
+
+// Flush ne transmet rien tant que la réponse est gardée
+func (w *cacheWriter) Flush() {
+	if w.passthrough {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// commit passe en transmission directe et envoie ce qui a été gardé
+func (w *cacheWriter) commit() error {
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	_, err := w.ResponseWriter.Write(w.body.Bytes())
+	w.body.Reset()
+	return err
+}
@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"gateway/internal/idempotency"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// inFlightRetryAfter délai conseillé (secondes) avant de retenter une requête encore en cours
+const inFlightRetryAfter = 1
+
+// notForwardedKey marque une requête refusée par le gateway avant d'être transmise au service
+const notForwardedKey = "idempotency_not_forwarded"
+
+// replayedHeaders headers de la première réponse rejoués avec elle
+var replayedHeaders = []string{
+	"Content-Type",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Disposition",
+	"Location",
+	"ETag",
+	"Last-Modified",
+}
+
+// Idempotency middleware des clés d'idempotence sur les écritures (header Idempotency-Key)
+// À placer après JWTAuth : les clés sont propres à chaque utilisateur. La première réponse
+// est gardée et rejouée aux requêtes suivantes avec la même clé ; une requête encore en cours
+// reçoit 409, une clé réutilisée pour une autre requête 422. Une réponse 5xx n'est pas gardée
+// si la requête n'a pas été transmise au service (MarkNotForwarded) : la clé est libérée pour
+// que le client puisse réessayer. Sinon l'écriture a pu être appliquée : la réponse est gardée
+// comme les autres et la requête n'est jamais rejouée vers le service.
+func Idempotency(guard *idempotency.Guard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(guard.Header())
+		if key == "" || !guard.Applies(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		userID, authenticated := GetUserIDFromContext(c)
+		if !authenticated {
+			c.Next()
+			return
+		}
+
+		if len(key) > guard.MaxKeyLength() {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":      fmt.Sprintf("%s must be at most %d characters", guard.Header(), guard.MaxKeyLength()),
+				"request_id": c.GetHeader("X-Request-ID"),
+			})
+			return
+		}
+
+		body, ok := readIdempotentBody(c, guard.MaxBodySize())
+		if !ok {
+			return
+		}
+
+		fingerprint := idempotency.Fingerprint(c.Request.Method, c.Request.URL.Path, body)
+		reservation, existing, err := guard.Begin(c.Request.Context(), userID.String(), key, fingerprint)
+		if err != nil {
+			// Store indisponible : la requête passe sans protection plutôt que d'être refusée
+			logrus.WithError(err).Warn("Idempotency store unavailable, processing request without key")
+			c.Next()
+			return
+		}
+
+		if existing != nil {
+			respondExisting(c, guard, existing, fingerprint)
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer, limit: guard.MaxResponseSize()}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		status := writer.Status()
+		switch {
+		case status >= http.StatusInternalServerError && c.GetBool(notForwardedKey):
+			reservation.Release(c.Request.Context())
+			guard.Record(idempotency.ResultReleased)
+		case status >= http.StatusInternalServerError:
+			// Issue inconnue (erreur ou timeout après envoi) : la clé reste prise
+			reservation.Complete(c.Request.Context(), status, replayableHeaders(writer.Header()), writer.body.Bytes())
+			guard.Record(idempotency.ResultUnknown)
+		case writer.overflow:
+			reservation.Release(c.Request.Context())
+			guard.Record(idempotency.ResultReleased)
+		default:
+			reservation.Complete(c.Request.Context(), status, replayableHeaders(writer.Header()), writer.body.Bytes())
+			guard.Record(idempotency.ResultStored)
+		}
+	}
+}
+
+// MarkNotForwarded signale que la requête n'a pas été transmise au service (maintenance, aucune
+// instance, circuit ouvert, connexion impossible) : son éventuelle clé d'idempotence est libérée
+func MarkNotForwarded(c *gin.Context) {
+	c.Set(notForwardedKey, true)
+}
+
+// readIdempotentBody lit le corps de la requête pour son empreinte et le remet en place
+// Retourne false après avoir répondu 413 si le corps est trop grand.
+func readIdempotentBody(c *gin.Context, maxBodySize int64) ([]byte, bool) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil, true
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize+1))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error":      "Invalid request body",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return nil, false
+	}
+	if int64(len(body)) > maxBodySize {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":      fmt.Sprintf("Request body is too large for an idempotent request (max %d bytes)", maxBodySize),
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return nil, false
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	return body, true
+}
+
+// respondExisting répond à une requête dont la clé est déjà connue
+func respondExisting(c *gin.Context, guard *idempotency.Guard, existing *idempotency.Record, fingerprint string) {
+	switch {
+	case existing.Fingerprint != fingerprint:
+		guard.Record(idempotency.ResultMismatch)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"error":      fmt.Sprintf("%s was already used for a different request", guard.Header()),
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+	case !existing.Completed:
+		guard.Record(idempotency.ResultInFlight)
+		c.Header("Retry-After", strconv.Itoa(inFlightRetryAfter))
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"error":      "A request with this idempotency key is still in progress",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+	default:
+		guard.Record(idempotency.ResultReplayed)
+		header := c.Writer.Header()
+		for name, values := range existing.Header {
+			header[name] = append([]string(nil), values...)
+		}
+		header.Set("Idempotent-Replayed", "true")
+		header.Set("Content-Length", strconv.Itoa(len(existing.Body)))
+		c.Status(existing.Status)
+		c.Writer.WriteHeaderNow()
+		_, _ = c.Writer.Write(existing.Body)
+		c.Abort()
+	}
+}
+
+// replayableHeaders copie les headers de la réponse rejoués avec elle
+func replayableHeaders(header http.Header) http.Header {
+	stored := make(http.Header)
+	for _, name := range replayedHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			stored[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		}
+	}
+	return stored
+}
+
+// idempotencyWriter transmet la réponse au client et en garde une copie pour la rejouer
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int64
+	overflow bool // réponse trop grande pour être gardée
+}
+
+// Write transmet le corps et en garde une copie tant qu'il tient dans la limite
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implémente gin.ResponseWriter
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// keep garde une partie du corps
+func (w *idempotencyWriter) keep(data []byte) {
+	if w.overflow {
+		return
+	}
+	if int64(w.body.Len()+len(data)) > w.limit {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"gateway/internal/config"
+	"gateway/internal/idempotency"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// idempotencyRouter route POST /orders derrière le middleware ; handler répond à la place du service
+func idempotencyRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	guard := idempotency.NewGuard(&config.IdempotencyConfig{
+		Enabled:         true,
+		Header:          "Idempotency-Key",
+		Methods:         []string{http.MethodPost},
+		MaxKeyLength:    64,
+		MaxBodySize:     1024,
+		MaxResponseSize: 1024,
+		TTL:             time.Hour,
+		LockTimeout:     time.Minute,
+		StoreTimeout:    time.Second,
+	}, nil)
+
+	userID := uuid.New()
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", userID) })
+	router.POST("/orders", Idempotency(guard), handler)
+	return router
+}
+
+// postOrder envoie deux fois la même requête et retourne les deux réponses
+func postOrder(router *gin.Engine) (*httptest.ResponseRecorder, *httptest.ResponseRecorder) {
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"item":"potion"}`))
+		req.Header.Set("Idempotency-Key", "order-1")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	return send(), send()
+}
+
+func TestIdempotencyReplaysStoredResponse(t *testing.T) {
+	calls := 0
+	router := idempotencyRouter(func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"order": calls})
+	})
+
+	first, second := postOrder(router)
+	if calls != 1 {
+		t.Fatalf("service called %d times, want 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replayed response not marked")
+	}
+}
+
+// TestIdempotencyKeepsKeyOnUnknownOutcome un 5xx après transmission au service n'est pas rejoué vers
+// le service : l'écriture a pu être appliquée
+func TestIdempotencyKeepsKeyOnUnknownOutcome(t *testing.T) {
+	calls := 0
+	router := idempotencyRouter(func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Service timeout"})
+	})
+
+	_, second := postOrder(router)
+	if calls != 1 {
+		t.Fatalf("service called %d times, want 1", calls)
+	}
+	if second.Code != http.StatusGatewayTimeout || second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("second request = %d, want the replayed 504", second.Code)
+	}
+}
+
+// TestIdempotencyReleasesKeyWhenNotForwarded un refus du gateway avant transmission libère la clé
+func TestIdempotencyReleasesKeyWhenNotForwarded(t *testing.T) {
+	calls := 0
+	router := idempotencyRouter(func(c *gin.Context) {
+		calls++
+		MarkNotForwarded(c)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Service unavailable"})
+	})
+
+	_, second := postOrder(router)
+	if calls != 2 {
+		t.Fatalf("handler called %d times, want 2", calls)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("response of a request that was not forwarded was replayed")
+	}
+}
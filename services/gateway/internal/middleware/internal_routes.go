@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// BlockInternalRoutes refuse les routes réservées aux appels entre services (/services/*)
+// Le chemin est nettoyé (., .., doubles /) et comparé sans tenir compte de la casse,
+// pour qu'une variante d'écriture ne contourne pas la règle.
+func BlockInternalRoutes(prefixes []string) gin.HandlerFunc {
+	blocked := normalizePrefixes(prefixes)
+
+	return func(c *gin.Context) {
+		if !matchesPrefix(c.Request.URL.Path, blocked) {
+			c.Next()
+			return
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"path":       c.Request.URL.Path,
+			"ip_address": c.ClientIP(),
+			"request_id": c.GetHeader("X-Request-ID"),
+		}).Warn("Blocked external access to internal service route")
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":      "Internal service route",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+	}
+}
+
+// normalizePrefixes prépare des préfixes de chemin pour matchesPrefix (minuscules, sans / final)
+func normalizePrefixes(prefixes []string) []string {
+	normalized := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		prefix = strings.TrimRight(strings.ToLower(strings.TrimSpace(prefix)), "/")
+		if prefix != "" {
+			normalized = append(normalized, prefix)
+		}
+	}
+	return normalized
+}
+
+// matchesPrefix indique si le chemin, nettoyé et en minuscules, est l'un des préfixes
+// ou se trouve sous l'un d'eux
+func matchesPrefix(requestPath string, prefixes []string) bool {
+	requestPath = strings.ToLower(path.Clean("/" + requestPath))
+	for _, prefix := range prefixes {
+		if requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"gateway/internal/maintenance"
+	"mmorpg/pkg/jwks"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// platformLabel label des métriques pour les fenêtres de toute la plateforme
+const platformLabel = "platform"
+
+// Maintenance refuse les requêtes pendant une fenêtre de maintenance de toute la plateforme
+// Les comptes staff (token vérifié ici, avant JWTAuth) et les routes exemptées restent
+// accessibles ; les fenêtres propres à un service sont appliquées par le proxy.
+// staffTwoFactor : seul le staff connecté avec un code 2FA passe pendant la fenêtre.
+func Maintenance(scheduler *maintenance.Scheduler, exemptPaths []string, keys *jwks.KeySet, staffTwoFactor bool) gin.HandlerFunc {
+	exempt := normalizePrefixes(exemptPaths)
+
+	return func(c *gin.Context) {
+		window := scheduler.Active("", time.Now())
+		if window == nil || matchesPrefix(c.Request.URL.Path, exempt) || MaintenanceExempt(c, scheduler, keys, staffTwoFactor) {
+			c.Next()
+			return
+		}
+
+		AbortMaintenance(c, window)
+	}
+}
+
+// MaintenanceExempt indique si la requête vient d'un compte staff
+// L'identité posée par JWTAuth est utilisée si elle existe, sinon le token est vérifié.
+// Avec staffTwoFactor, une session ouverte sans code 2FA n'est pas exemptée.
+func MaintenanceExempt(c *gin.Context, scheduler *maintenance.Scheduler, keys *jwks.KeySet, staffTwoFactor bool) bool {
+	if userID, ok := GetUserIDFromContext(c); ok {
+		role, _ := GetUserRoleFromContext(c)
+		return scheduler.Exempt(userID.String(), role) && (!staffTwoFactor || c.GetBool("two_factor"))
+	}
+
+	claims, ok := claimsFromRequest(c, keys)
+	return ok && scheduler.Exempt(claims.UserID.String(), claims.Role) && (!staffTwoFactor || claims.MFA)
+}
+
+// AbortMaintenance répond 503 avec Retry-After (fin de la fenêtre) pendant une maintenance
+func AbortMaintenance(c *gin.Context, window *maintenance.Window) {
+	retryAfter := window.RetryAfter(time.Now())
+
+	label := window.Service
+	if window.Platform() {
+		label = platformLabel
+	}
+	maintenanceRejections.WithLabelValues(label).Inc()
+
+	MarkNotForwarded(c)
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+		"error":       "Service under maintenance",
+		"message":     window.Message,
+		"maintenance": window,
+		"retry_after": retryAfter,
+		"request_id":  c.GetHeader("X-Request-ID"),
+	})
+}
@@ -3,9 +3,12 @@ package middleware
 import (
 	"fmt"
 	"gateway/internal/config"
+	"gateway/internal/ratelimit"
+	"math"
+	"mmorpg/pkg/jwks"
 	"net/http"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -13,23 +16,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/time/rate"
 )
 
 // Constantes de configuration middleware
 const (
-	HighPerformanceTimeout  = 3 // secondes
-	CircuitBreakerThreshold = 5
-	CircuitBreakerTimeout   = 30 // secondes
-	SecurityCleanupInterval = 10 // minutes
-	MaxLimitersCount        = 10000
-	ServerErrorStatusCode   = 500
+	HighPerformanceTimeout = 3 // secondes
 
 	// Configuration CORS et sécurité
-	CORSMaxAge           = 12 // heures
-	RateLimitRetryAfter  = 60 // secondes
-	SuspiciousThreshold  = 10
-	LimitersCleanupRatio = 2
+	CORSMaxAge = 12 // heures
 )
 
 // MÃ©triques Prometheus
@@ -63,7 +57,15 @@ var (
 			Name: "gateway_rate_limit_hits_total",
 			Help: "Total number of rate limit hits",
 		},
-		[]string{"client_ip"},
+		[]string{"policy", "tier"},
+	)
+
+	maintenanceRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_maintenance_rejections_total",
+			Help: "Total number of requests rejected during a maintenance window",
+		},
+		[]string{"service"},
 	)
 )
 
@@ -73,13 +75,14 @@ func InitMetrics() {
 	prometheus.MustRegister(requestDuration)
 	prometheus.MustRegister(activeConnections)
 	prometheus.MustRegister(rateLimitHits)
+	prometheus.MustRegister(maintenanceRejections)
 }
 
 // Logger middleware personnalisÃ© pour le gateway
 func Logger() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		// Log structurÃ© pour le gateway
-		logrus.WithFields(logrus.Fields{
+		// Log structuré pour le gateway (trace_id et span_id via le contexte)
+		logrus.WithContext(param.Request.Context()).WithFields(logrus.Fields{
 			"timestamp":   param.TimeStamp.Format(time.RFC3339),
 			"client_ip":   param.ClientIP,
 			"method":      param.Method,
@@ -134,11 +137,13 @@ func CORS() gin.HandlerFunc {
 			"X-Request-ID",
 			"X-Client-Version",
 			"X-Game-Session",
+			"Idempotency-Key",
 		},
 		ExposeHeaders: []string{
 			"Content-Length",
 			"X-Request-ID",
 			"X-Rate-Limit-Remaining",
+			"Idempotent-Replayed",
 		},
 		AllowCredentials: true,
 		MaxAge:           CORSMaxAge * time.Hour,
@@ -155,122 +160,92 @@ func RequestID() gin.HandlerFunc {
 			requestID = uuid.New().String()
 		}
 
+		// Aussi sur la requête : transmis aux services par le proxy
+		c.Request.Header.Set("X-Request-ID", requestID)
 		c.Header("X-Request-ID", requestID)
 		c.Set("request_id", requestID)
 		c.Next()
 	}
 }
 
-// RateLimiter avec support distribuÃ© pour gaming
-type RateLimiter struct {
-	limiters map[string]*rate.Limiter
-	mutex    sync.RWMutex
-	rpm      int
-	burst    int
-}
-
-// NewRateLimiter crÃ©e un nouveau rate limiter
-func NewRateLimiter(rpm, burst int) *RateLimiter {
-	return &RateLimiter{
-		limiters: make(map[string]*rate.Limiter),
-		rpm:      rpm,
-		burst:    burst,
-	}
-}
-
-// GetLimiter rÃ©cupÃ¨re ou crÃ©e un limiteur pour un client
-func (rl *RateLimiter) GetLimiter(clientID string) *rate.Limiter {
-	rl.mutex.RLock()
-	limiter, exists := rl.limiters[clientID]
-	rl.mutex.RUnlock()
-
-	if !exists {
-		rl.mutex.Lock()
-		limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(rl.rpm)), rl.burst)
-		rl.limiters[clientID] = limiter
-		rl.mutex.Unlock()
-	}
-
-	return limiter
-}
-
-// CleanupOldLimiters nettoie les anciens limiteurs
-func (rl *RateLimiter) CleanupOldLimiters() {
-	rl.mutex.Lock()
-	defer rl.mutex.Unlock()
-
-	// Nettoyage simple : limiter le nombre total de limiteurs pour éviter la fuite mémoire
-
-	if len(rl.limiters) > MaxLimitersCount {
-		// Supprimer la moitié des limiteurs (strategy simple)
-		count := 0
-		target := len(rl.limiters) / LimitersCleanupRatio
-
-		for ip := range rl.limiters {
-			if count >= target {
-				break
-			}
-			delete(rl.limiters, ip)
-			count++
-		}
-	}
-}
-
-// RateLimit middleware avec diffÃ©rents niveaux pour gaming
-func RateLimit(cfg config.RateLimitConfig) gin.HandlerFunc {
-	limiter := NewRateLimiter(cfg.RequestsPerMinute, cfg.BurstSize)
+// RateLimit middleware de rate limiting par utilisateur, rôle et route
+// Les requêtes authentifiées sont comptées par ID utilisateur (le token est vérifié ici,
+// avant JWTAuth, pour couvrir toutes les routes), les autres par IP. Les en-têtes
+// RateLimit-* décrivent la politique appliquée.
+func RateLimit(limiter *ratelimit.Limiter, keys *jwks.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subject := rateLimitSubject(c, keys)
 
-	// Nettoyage pÃ©riodique
-	go func() {
-		ticker := time.NewTicker(cfg.CleanupInterval)
-		defer ticker.Stop()
-		for range ticker.C {
-			limiter.CleanupOldLimiters()
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
 		}
-	}()
 
-	return func(c *gin.Context) {
-		// Identifier le client (IP + User ID si authentifiÃ©)
-		clientID := c.ClientIP()
-		if userID := c.GetHeader("X-User-ID"); userID != "" {
-			clientID = fmt.Sprintf("%s:%s", clientID, userID)
+		decision := limiter.Allow(c.Request.Context(), c.Request.Method, route, subject)
+		if decision.Unlimited {
+			c.Next()
+			return
 		}
 
-		// Obtenir le limiteur pour ce client
-		clientLimiter := limiter.GetLimiter(clientID)
+		resetSeconds := int(math.Ceil(decision.Reset.Seconds()))
+		c.Header("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(resetSeconds))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", decision.Limit, int(decision.Window.Seconds())))
 
-		// VÃ©rifier la limite
-		if !clientLimiter.Allow() {
+		if !decision.Allowed {
 			// IncrÃ©menter la mÃ©trique
-			rateLimitHits.WithLabelValues(c.ClientIP()).Inc()
+			rateLimitHits.WithLabelValues(decision.Policy, decision.Tier).Inc()
 
 			logrus.WithFields(logrus.Fields{
-				"client_id":  clientID,
+				"policy":     decision.Policy,
+				"tier":       decision.Tier,
+				"user_id":    subject.UserID,
 				"client_ip":  c.ClientIP(),
 				"path":       c.Request.URL.Path,
 				"method":     c.Request.Method,
 				"request_id": c.GetHeader("X-Request-ID"),
 			}).Warn("Rate limit exceeded")
 
-			c.Header("X-Rate-Limit-Remaining", "0")
+			c.Header("Retry-After", strconv.Itoa(resetSeconds))
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":       "Rate limit exceeded",
 				"message":     "Too many requests, please slow down",
-				"retry_after": RateLimitRetryAfter,
+				"policy":      decision.Policy,
+				"retry_after": resetSeconds,
 				"request_id":  c.GetHeader("X-Request-ID"),
 			})
 			c.Abort()
 			return
 		}
 
-		// Ajouter les headers de rate limiting
-		remaining := int(clientLimiter.Tokens())
-		c.Header("X-Rate-Limit-Remaining", fmt.Sprintf("%d", remaining))
-
 		c.Next()
 	}
 }
 
+// rateLimitSubject identifie le client d'une requête et son tier
+func rateLimitSubject(c *gin.Context, keys *jwks.KeySet) ratelimit.Subject {
+	subject := ratelimit.Subject{IP: c.ClientIP(), Tier: config.TierAnonymous}
+
+	claims, ok := claimsFromRequest(c, keys)
+	if !ok {
+		return subject
+	}
+
+	subject.UserID = claims.UserID.String()
+	switch claims.Role {
+	case RoleAdmin:
+		subject.Tier = config.TierAdmin
+	case RoleModerator:
+		subject.Tier = config.TierModerator
+	case RoleService:
+		subject.Tier = config.TierService
+	default:
+		subject.Tier = config.TierPlayer
+	}
+	return subject
+}
+
 // Metrics middleware pour Prometheus
 func Metrics() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -366,6 +341,32 @@ func RequireRole(requiredRoles ...string) gin.HandlerFunc {
 	}
 }
 
+// RequireTwoFactor refuse aux comptes staff connectés sans 2FA (claim mfa) l'accès aux routes
+// protégées, quand la 2FA leur est exigée (GATEWAY_STAFF_TWO_FACTOR_REQUIRED)
+func RequireTwoFactor(required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("user_role")
+		if !required || !IsStaffRole(role) || c.GetBool("two_factor") {
+			c.Next()
+			return
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"user_id":    c.Value("user_id"),
+			"user_role":  role,
+			"path":       c.Request.URL.Path,
+			"request_id": c.GetHeader("X-Request-ID"),
+		}).Warn("Access denied: two-factor authentication required")
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":      "Two-factor authentication required",
+			"message":    "Enable two-factor authentication and log in again to access this resource",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		c.Abort()
+	}
+}
+
 // GameClientValidator valide les clients de jeu authentiques
 func GameClientValidator() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -417,110 +418,6 @@ func GameClientValidator() gin.HandlerFunc {
 	}
 }
 
-// CircuitBreaker middleware pour gÃ©rer les pannes de service
-type CircuitBreakerState int
-
-const (
-	CircuitBreakerClosed CircuitBreakerState = iota
-	CircuitBreakerOpen
-	CircuitBreakerHalfOpen
-)
-
-type CircuitBreaker struct {
-	state        CircuitBreakerState
-	failureCount int
-	lastFailTime time.Time
-	timeout      time.Duration
-	threshold    int
-	mutex        sync.RWMutex
-}
-
-func NewCircuitBreaker(threshold int, timeout time.Duration) *CircuitBreaker {
-	return &CircuitBreaker{
-		state:     CircuitBreakerClosed,
-		threshold: threshold,
-		timeout:   timeout,
-	}
-}
-
-func (cb *CircuitBreaker) CanExecute() bool {
-	cb.mutex.RLock()
-	defer cb.mutex.RUnlock()
-
-	switch cb.state {
-	case CircuitBreakerClosed:
-		return true
-	case CircuitBreakerOpen:
-		if time.Since(cb.lastFailTime) > cb.timeout {
-			cb.mutex.RUnlock()
-			cb.mutex.Lock()
-			cb.state = CircuitBreakerHalfOpen
-			cb.mutex.Unlock()
-			cb.mutex.RLock()
-			return true
-		}
-		return false
-	case CircuitBreakerHalfOpen:
-		return true
-	default:
-		return false
-	}
-}
-
-func (cb *CircuitBreaker) OnSuccess() {
-	cb.mutex.Lock()
-	defer cb.mutex.Unlock()
-
-	cb.failureCount = 0
-	cb.state = CircuitBreakerClosed
-}
-
-func (cb *CircuitBreaker) OnFailure() {
-	cb.mutex.Lock()
-	defer cb.mutex.Unlock()
-
-	cb.failureCount++
-	cb.lastFailTime = time.Now()
-
-	if cb.failureCount >= cb.threshold {
-		cb.state = CircuitBreakerOpen
-	}
-}
-
-// CircuitBreakerMiddleware crÃ©e un middleware circuit breaker
-func CircuitBreakerMiddleware(serviceName string) gin.HandlerFunc {
-	breaker := NewCircuitBreaker(CircuitBreakerThreshold, CircuitBreakerTimeout*time.Second)
-
-	return func(c *gin.Context) {
-		if !breaker.CanExecute() {
-			logrus.WithFields(logrus.Fields{
-				"service":    serviceName,
-				"state":      "open",
-				"path":       c.Request.URL.Path,
-				"request_id": c.GetHeader("X-Request-ID"),
-			}).Warn("Circuit breaker open, rejecting request")
-
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"error":      "Service temporarily unavailable",
-				"service":    serviceName,
-				"message":    "Circuit breaker is open",
-				"request_id": c.GetHeader("X-Request-ID"),
-			})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-
-		// VÃ©rifier le statut de la rÃ©ponse
-		if c.Writer.Status() >= ServerErrorStatusCode {
-			breaker.OnFailure()
-		} else {
-			breaker.OnSuccess()
-		}
-	}
-}
-
 // SecurityHeaders ajoute les en-tÃªtes de sÃ©curitÃ©
 func SecurityHeaders() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -548,69 +445,6 @@ func SecurityHeaders() gin.HandlerFunc {
 	}
 }
 
-// AntiCheat middleware basique pour dÃ©tecter les comportements suspects
-func AntiCheat() gin.HandlerFunc {
-	suspiciousIPs := make(map[string]int)
-	var mutex sync.RWMutex
-
-	// Nettoyage pÃ©riodique
-	go func() {
-		ticker := time.NewTicker(SecurityCleanupInterval * time.Minute)
-		defer ticker.Stop()
-		for range ticker.C {
-			mutex.Lock()
-			suspiciousIPs = make(map[string]int) // Reset
-			mutex.Unlock()
-		}
-	}()
-
-	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		userAgent := c.GetHeader("User-Agent")
-
-		// DÃ©tecter les requÃªtes sans User-Agent (bots basiques)
-		if userAgent == "" {
-			mutex.Lock()
-			suspiciousIPs[clientIP]++
-			suspiciousCount := suspiciousIPs[clientIP]
-			mutex.Unlock()
-
-			logrus.WithFields(logrus.Fields{
-				"client_ip":        clientIP,
-				"suspicious_count": suspiciousCount,
-				"path":             c.Request.URL.Path,
-				"request_id":       c.GetHeader("X-Request-ID"),
-			}).Warn("Request without User-Agent detected")
-
-			if suspiciousCount > SuspiciousThreshold {
-				c.JSON(http.StatusTooManyRequests, gin.H{
-					"error":      "Suspicious activity detected",
-					"message":    "Please use a valid game client",
-					"request_id": c.GetHeader("X-Request-ID"),
-				})
-				c.Abort()
-				return
-			}
-		}
-
-		// DÃ©tecter les User-Agents suspects
-		suspiciousAgents := []string{"curl", "wget", "python", "bot", "crawler"}
-		for _, suspicious := range suspiciousAgents {
-			if contains(userAgent, suspicious) {
-				logrus.WithFields(logrus.Fields{
-					"client_ip":  clientIP,
-					"user_agent": userAgent,
-					"path":       c.Request.URL.Path,
-					"request_id": c.GetHeader("X-Request-ID"),
-				}).Warn("Suspicious User-Agent detected")
-				break
-			}
-		}
-
-		c.Next()
-	}
-}
-
 // Fonctions utilitaires
 
 // extractServiceFromPath extrait le nom du service depuis le chemin de l'URL
@@ -630,8 +464,3 @@ func extractServiceFromPath(path string) string {
 
 	return "unknown"
 }
-
-// contains vÃ©rifie si une chaÃ®ne contient une sous-chaÃ®ne (case insensitive)
-func contains(s, substr string) bool {
-	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
-}
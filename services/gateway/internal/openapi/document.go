@@ -0,0 +1,370 @@
+package openapi
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Méthodes HTTP décrites par un PathItem, dans l'ordre de la spécification
+var operationMethods = []string{"GET", "PUT", "POST", "DELETE", "PATCH"}
+
+// Document document OpenAPI 3 (sous-ensemble utilisé par les services du projet)
+type Document struct {
+	OpenAPI    string               `yaml:"openapi" json:"openapi"`
+	Info       Info                 `yaml:"info" json:"info"`
+	Servers    []Server             `yaml:"servers,omitempty" json:"servers,omitempty"`
+	Tags       []Tag                `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Paths      map[string]*PathItem `yaml:"paths" json:"paths"`
+	Components *Components          `yaml:"components,omitempty" json:"components,omitempty"`
+
+	// Router fichier Go qui déclare les routes du service, relatif au dossier services/
+	// (vérifié par la commande de contract test)
+	Router string `yaml:"x-router,omitempty" json:"-"`
+}
+
+// Info informations générales du document
+type Info struct {
+	Title       string `yaml:"title" json:"title"`
+	Version     string `yaml:"version" json:"version"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// Server URL de base de l'API
+type Server struct {
+	URL         string `yaml:"url" json:"url"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// Tag regroupement d'opérations
+type Tag struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// PathItem opérations d'un chemin
+type PathItem struct {
+	Parameters []*Parameter `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	Get        *Operation   `yaml:"get,omitempty" json:"get,omitempty"`
+	Put        *Operation   `yaml:"put,omitempty" json:"put,omitempty"`
+	Post       *Operation   `yaml:"post,omitempty" json:"post,omitempty"`
+	Delete     *Operation   `yaml:"delete,omitempty" json:"delete,omitempty"`
+	Patch      *Operation   `yaml:"patch,omitempty" json:"patch,omitempty"`
+}
+
+// Operation opération d'un chemin
+type Operation struct {
+	OperationID string                `yaml:"operationId,omitempty" json:"operationId,omitempty"`
+	Summary     string                `yaml:"summary,omitempty" json:"summary,omitempty"`
+	Tags        []string              `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Security    []map[string][]string `yaml:"security,omitempty" json:"security,omitempty"`
+	Parameters  []*Parameter          `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	RequestBody *RequestBody          `yaml:"requestBody,omitempty" json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `yaml:"responses" json:"responses"`
+}
+
+// Parameter paramètre de chemin ou de requête
+type Parameter struct {
+	Ref         string  `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Name        string  `yaml:"name" json:"name"`
+	In          string  `yaml:"in" json:"in"`
+	Required    bool    `yaml:"required,omitempty" json:"required,omitempty"`
+	Description string  `yaml:"description,omitempty" json:"description,omitempty"`
+	Schema      *Schema `yaml:"schema,omitempty" json:"schema,omitempty"`
+}
+
+// RequestBody corps attendu par une opération
+type RequestBody struct {
+	Required bool                  `yaml:"required,omitempty" json:"required,omitempty"`
+	Content  map[string]*MediaType `yaml:"content" json:"content"`
+}
+
+// Response réponse d'une opération
+type Response struct {
+	Description string                `yaml:"description" json:"description"`
+	Content     map[string]*MediaType `yaml:"content,omitempty" json:"content,omitempty"`
+}
+
+// MediaType schéma d'un type de contenu
+type MediaType struct {
+	Schema *Schema `yaml:"schema,omitempty" json:"schema,omitempty"`
+}
+
+// Components éléments réutilisables
+type Components struct {
+	Schemas         map[string]*Schema         `yaml:"schemas,omitempty" json:"schemas,omitempty"`
+	Parameters      map[string]*Parameter      `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `yaml:"securitySchemes,omitempty" json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme schéma d'authentification
+type SecurityScheme struct {
+	Type         string `yaml:"type" json:"type"`
+	Scheme       string `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+	BearerFormat string `yaml:"bearerFormat,omitempty" json:"bearerFormat,omitempty"`
+}
+
+// Schema schéma JSON (mots-clés pris en charge par le validateur)
+type Schema struct {
+	Ref         string             `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Type        string             `yaml:"type,omitempty" json:"type,omitempty"`
+	Format      string             `yaml:"format,omitempty" json:"format,omitempty"`
+	Description string             `yaml:"description,omitempty" json:"description,omitempty"`
+	Nullable    bool               `yaml:"nullable,omitempty" json:"nullable,omitempty"`
+	Enum        []interface{}      `yaml:"enum,omitempty" json:"enum,omitempty"`
+	Properties  map[string]*Schema `yaml:"properties,omitempty" json:"properties,omitempty"`
+	Required    []string           `yaml:"required,omitempty" json:"required,omitempty"`
+	Items       *Schema            `yaml:"items,omitempty" json:"items,omitempty"`
+	MinLength   *int               `yaml:"minLength,omitempty" json:"minLength,omitempty"`
+	MaxLength   *int               `yaml:"maxLength,omitempty" json:"maxLength,omitempty"`
+	Pattern     string             `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Minimum     *float64           `yaml:"minimum,omitempty" json:"minimum,omitempty"`
+	Maximum     *float64           `yaml:"maximum,omitempty" json:"maximum,omitempty"`
+	MinItems    *int               `yaml:"minItems,omitempty" json:"minItems,omitempty"`
+	MaxItems    *int               `yaml:"maxItems,omitempty" json:"maxItems,omitempty"`
+
+	// AdditionalProperties false refuse les champs non déclarés
+	AdditionalProperties *bool `yaml:"additionalProperties,omitempty" json:"additionalProperties,omitempty"`
+}
+
+// Operations retourne les opérations du chemin par méthode
+func (p *PathItem) Operations() map[string]*Operation {
+	operations := make(map[string]*Operation)
+	for _, method := range operationMethods {
+		if operation := p.operation(method); operation != nil {
+			operations[method] = operation
+		}
+	}
+	return operations
+}
+
+// operation retourne l'opération d'une méthode
+func (p *PathItem) operation(method string) *Operation {
+	switch method {
+	case "GET":
+		return p.Get
+	case "PUT":
+		return p.Put
+	case "POST":
+		return p.Post
+	case "DELETE":
+		return p.Delete
+	case "PATCH":
+		return p.Patch
+	default:
+		return nil
+	}
+}
+
+// setOperation ajoute l'opération d'une méthode
+func (p *PathItem) setOperation(method string, operation *Operation) {
+	switch method {
+	case "GET":
+		p.Get = operation
+	case "PUT":
+		p.Put = operation
+	case "POST":
+		p.Post = operation
+	case "DELETE":
+		p.Delete = operation
+	case "PATCH":
+		p.Patch = operation
+	}
+}
+
+// Parse lit un document YAML (ou JSON) et vérifie ses références
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", doc.OpenAPI)
+	}
+	if err := doc.resolveParameters(); err != nil {
+		return nil, err
+	}
+	if err := doc.checkReferences(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// resolveParameters remplace les références de paramètres par leur définition
+// (components/parameters), pour que le validateur et le document fusionné n'aient
+// pas à les suivre
+func (d *Document) resolveParameters() error {
+	resolve := func(parameters []*Parameter) error {
+		for i, parameter := range parameters {
+			if parameter.Ref == "" {
+				continue
+			}
+			name, ok := strings.CutPrefix(parameter.Ref, componentParameterPrefix)
+			if !ok || d.Components == nil || d.Components.Parameters[name] == nil {
+				return fmt.Errorf("unresolved parameter reference %q", parameter.Ref)
+			}
+			parameters[i] = d.Components.Parameters[name]
+		}
+		return nil
+	}
+
+	for route, item := range d.Paths {
+		if err := resolve(item.Parameters); err != nil {
+			return fmt.Errorf("%s: %w", route, err)
+		}
+		for method, operation := range item.Operations() {
+			if err := resolve(operation.Parameters); err != nil {
+				return fmt.Errorf("%s %s: %w", method, route, err)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadAll lit les documents *.yaml d'un système de fichiers, indexés par service
+// (nom du fichier sans extension)
+func LoadAll(fsys fs.FS) (map[string]*Document, error) {
+	files, err := fs.Glob(fsys, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make(map[string]*Document, len(files))
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		doc, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		docs[strings.TrimSuffix(path.Base(file), ".yaml")] = doc
+	}
+	return docs, nil
+}
+
+// Merge assemble les documents des services en un document de l'API publique
+// Seuls les chemins /api/ sont repris ; les opérations sont étiquetées par service.
+func Merge(docs map[string]*Document, info Info, servers []Server) *Document {
+	merged := &Document{
+		OpenAPI:    "3.0.3",
+		Info:       info,
+		Servers:    servers,
+		Paths:      make(map[string]*PathItem),
+		Components: &Components{Schemas: make(map[string]*Schema), SecuritySchemes: make(map[string]*SecurityScheme)},
+	}
+
+	services := make([]string, 0, len(docs))
+	for service := range docs {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	for _, service := range services {
+		doc := docs[service]
+		merged.Tags = append(merged.Tags, Tag{Name: service, Description: doc.Info.Title})
+		prefix := schemaPrefix(service)
+
+		for route, item := range doc.Paths {
+			if !strings.HasPrefix(route, "/api/") {
+				continue
+			}
+			target, exists := merged.Paths[route]
+			if !exists {
+				target = &PathItem{Parameters: item.Parameters}
+				merged.Paths[route] = target
+			}
+			for method, operation := range item.Operations() {
+				if target.operation(method) != nil {
+					continue // déjà décrite par un autre service
+				}
+				copied := *operation
+				copied.Tags = []string{service}
+				renameRefs(&copied, prefix)
+				target.setOperation(method, &copied)
+			}
+		}
+
+		if doc.Components == nil {
+			continue
+		}
+		for name, schema := range doc.Components.Schemas {
+			merged.Components.Schemas[prefix+name] = renameSchemaRefs(schema, prefix)
+		}
+		for name, scheme := range doc.Components.SecuritySchemes {
+			merged.Components.SecuritySchemes[name] = scheme
+		}
+	}
+
+	return merged
+}
+
+// schemaPrefix préfixe des schémas d'un service dans le document fusionné (player -> Player)
+func schemaPrefix(service string) string {
+	var prefix strings.Builder
+	for _, part := range strings.FieldsFunc(service, func(r rune) bool { return r == '-' || r == '_' }) {
+		prefix.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return prefix.String()
+}
+
+// renameRefs préfixe les références de schémas d'une opération
+func renameRefs(operation *Operation, prefix string) {
+	parameters := make([]*Parameter, len(operation.Parameters))
+	for i, parameter := range operation.Parameters {
+		copied := *parameter
+		copied.Schema = renameSchemaRefs(parameter.Schema, prefix)
+		parameters[i] = &copied
+	}
+	operation.Parameters = parameters
+
+	if operation.RequestBody != nil {
+		body := *operation.RequestBody
+		body.Content = renameContentRefs(body.Content, prefix)
+		operation.RequestBody = &body
+	}
+
+	responses := make(map[string]*Response, len(operation.Responses))
+	for status, response := range operation.Responses {
+		copied := *response
+		copied.Content = renameContentRefs(response.Content, prefix)
+		responses[status] = &copied
+	}
+	operation.Responses = responses
+}
+
+// renameContentRefs préfixe les références des schémas d'un contenu
+func renameContentRefs(content map[string]*MediaType, prefix string) map[string]*MediaType {
+	if content == nil {
+		return nil
+	}
+	renamed := make(map[string]*MediaType, len(content))
+	for mediaType, media := range content {
+		renamed[mediaType] = &MediaType{Schema: renameSchemaRefs(media.Schema, prefix)}
+	}
+	return renamed
+}
+
+// renameSchemaRefs copie un schéma en préfixant ses références
+func renameSchemaRefs(schema *Schema, prefix string) *Schema {
+	if schema == nil {
+		return nil
+	}
+	copied := *schema
+	if name, ok := strings.CutPrefix(schema.Ref, componentSchemaPrefix); ok {
+		copied.Ref = componentSchemaPrefix + prefix + name
+	}
+	copied.Items = renameSchemaRefs(schema.Items, prefix)
+	if schema.Properties != nil {
+		copied.Properties = make(map[string]*Schema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			copied.Properties[name] = renameSchemaRefs(property, prefix)
+		}
+	}
+	return &copied
+}
@@ -0,0 +1,520 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Constantes du validateur
+const (
+	componentSchemaPrefix    = "#/components/schemas/"
+	componentParameterPrefix = "#/components/parameters/"
+
+	// MaxValidationErrors erreurs retournées au plus pour une requête
+	MaxValidationErrors = 20
+
+	jsonMediaType = "application/json"
+)
+
+// Emplacements d'une erreur de validation
+const (
+	LocationPath  = "path"
+	LocationQuery = "query"
+	LocationBody  = "body"
+)
+
+// ValidationError écart entre une requête et le contrat du service
+type ValidationError struct {
+	Location string `json:"location"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+// route opération compilée pour la correspondance des chemins
+type route struct {
+	segments   []string // littéraux ou {param}
+	literals   int
+	parameters []*Parameter
+	operation  *Operation
+	doc        *Document
+}
+
+// Validator valide les requêtes entrantes contre les documents des services
+type Validator struct {
+	routes   map[string]map[string][]*route // service -> méthode -> routes
+	patterns sync.Map                       // pattern -> *regexp.Regexp
+}
+
+// NewValidator compile les opérations des documents
+func NewValidator(docs map[string]*Document) *Validator {
+	v := &Validator{routes: make(map[string]map[string][]*route)}
+
+	for service, doc := range docs {
+		byMethod := make(map[string][]*route)
+		for path, item := range doc.Paths {
+			segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+			literals := 0
+			for _, segment := range segments {
+				if !isTemplateSegment(segment) {
+					literals++
+				}
+			}
+			for method, operation := range item.Operations() {
+				byMethod[method] = append(byMethod[method], &route{
+					segments:   segments,
+					literals:   literals,
+					parameters: append(append([]*Parameter(nil), item.Parameters...), operation.Parameters...),
+					operation:  operation,
+					doc:        doc,
+				})
+			}
+		}
+		v.routes[service] = byMethod
+	}
+
+	return v
+}
+
+// HasService indique si un document décrit le service
+func (v *Validator) HasService(service string) bool {
+	_, exists := v.routes[service]
+	return exists
+}
+
+// ValidateRequest valide une requête destinée à un service (path : chemin côté service)
+// Le corps JSON est lu (au plus maxBodySize octets) puis remis en place pour le proxy.
+// Une requête sans opération correspondante n'est pas validée.
+func (v *Validator) ValidateRequest(service string, r *http.Request, path string, maxBodySize int64) ([]ValidationError, error) {
+	rt, params := v.match(service, r.Method, path)
+	if rt == nil {
+		return nil, nil
+	}
+
+	var errs []ValidationError
+	errs = append(errs, v.validateParameters(rt, params, r)...)
+
+	body := rt.operation.RequestBody
+	if body == nil || r.ContentLength > maxBodySize {
+		return limitErrors(errs), nil
+	}
+
+	// Sans Content-Type, les services lisent le corps comme du JSON (ShouldBindJSON)
+	mediaType := jsonMediaType
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, _ = mime.ParseMediaType(contentType)
+	}
+	if r.ContentLength == 0 || r.Body == nil || r.Body == http.NoBody {
+		if body.Required {
+			errs = append(errs, ValidationError{Location: LocationBody, Message: "request body is required"})
+		}
+		return limitErrors(errs), nil
+	}
+
+	content, declared := body.Content[mediaType]
+	if !declared {
+		errs = append(errs, ValidationError{Location: LocationBody, Message: fmt.Sprintf("unsupported content type %q", mediaType)})
+		return limitErrors(errs), nil
+	}
+	if mediaType != jsonMediaType || content.Schema == nil {
+		return limitErrors(errs), nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBodySize {
+		// Taille inconnue et trop grande : transmise sans validation
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), r.Body))
+		return limitErrors(errs), nil
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	r.ContentLength = int64(len(data))
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		errs = append(errs, ValidationError{Location: LocationBody, Message: "malformed JSON document"})
+		return limitErrors(errs), nil
+	}
+	v.validateSchema(rt.doc, content.Schema, value, "", &errs)
+
+	return limitErrors(errs), nil
+}
+
+// match trouve l'opération d'une requête ; les segments littéraux l'emportent sur les paramètres
+func (v *Validator) match(service, method, path string) (*route, map[string]string) {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	var best *route
+	for _, candidate := range v.routes[service][method] {
+		if len(candidate.segments) != len(segments) || (best != nil && candidate.literals <= best.literals) {
+			continue
+		}
+		if matchSegments(candidate.segments, segments) {
+			best = candidate
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+
+	params := make(map[string]string)
+	for i, segment := range best.segments {
+		if isTemplateSegment(segment) {
+			params[segment[1:len(segment)-1]] = segments[i]
+		}
+	}
+	return best, params
+}
+
+// matchSegments compare les segments d'un chemin à ceux d'une route
+func matchSegments(template, segments []string) bool {
+	for i, segment := range template {
+		if isTemplateSegment(segment) {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isTemplateSegment indique un segment {param}
+func isTemplateSegment(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// validateParameters valide les paramètres de chemin et de requête
+func (v *Validator) validateParameters(rt *route, params map[string]string, r *http.Request) []ValidationError {
+	var errs []ValidationError
+	query := r.URL.Query()
+
+	for _, parameter := range rt.parameters {
+		var raw string
+		var present bool
+		switch parameter.In {
+		case LocationPath:
+			raw, present = params[parameter.Name]
+		case LocationQuery:
+			present = query.Has(parameter.Name)
+			raw = query.Get(parameter.Name)
+		default:
+			continue
+		}
+
+		if !present {
+			if parameter.Required {
+				errs = append(errs, ValidationError{Location: parameter.In, Field: parameter.Name, Message: "is required"})
+			}
+			continue
+		}
+		if parameter.Schema == nil {
+			continue
+		}
+
+		value, ok := convertParameter(raw, v.resolve(rt.doc, parameter.Schema).Type)
+		if !ok {
+			errs = append(errs, ValidationError{
+				Location: parameter.In,
+				Field:    parameter.Name,
+				Message:  "must be of type " + v.resolve(rt.doc, parameter.Schema).Type,
+			})
+			continue
+		}
+
+		var paramErrs []ValidationError
+		v.validateSchema(rt.doc, parameter.Schema, value, parameter.Name, &paramErrs)
+		for _, paramErr := range paramErrs {
+			paramErr.Location = parameter.In
+			errs = append(errs, paramErr)
+		}
+	}
+
+	return errs
+}
+
+// convertParameter convertit la valeur texte d'un paramètre selon son type
+func convertParameter(raw, schemaType string) (interface{}, bool) {
+	switch schemaType {
+	case "integer", "number":
+		value, err := strconv.ParseFloat(raw, 64)
+		return value, err == nil
+	case "boolean":
+		value, err := strconv.ParseBool(raw)
+		return value, err == nil
+	default:
+		return raw, true
+	}
+}
+
+// validateSchema valide une valeur JSON décodée
+func (v *Validator) validateSchema(doc *Document, schema *Schema, value interface{}, field string, errs *[]ValidationError) {
+	if len(*errs) >= MaxValidationErrors {
+		return
+	}
+	schema = v.resolve(doc, schema)
+
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Location: LocationBody, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			fail("must not be null")
+		}
+		return
+	}
+	if !matchesType(schema.Type, value) {
+		fail("must be of type %s", schema.Type)
+		return
+	}
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		fail("must be one of %s", formatEnum(schema.Enum))
+		return
+	}
+
+	switch typed := value.(type) {
+	case string:
+		v.validateString(schema, typed, fail)
+	case float64:
+		validateNumber(schema, typed, fail)
+	case []interface{}:
+		if schema.MinItems != nil && len(typed) < *schema.MinItems {
+			fail("must contain at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(typed) > *schema.MaxItems {
+			fail("must contain at most %d items", *schema.MaxItems)
+		}
+		if schema.Items != nil {
+			for i, item := range typed {
+				v.validateSchema(doc, schema.Items, item, fmt.Sprintf("%s[%d]", field, i), errs)
+			}
+		}
+	case map[string]interface{}:
+		v.validateObject(doc, schema, typed, field, errs)
+	}
+}
+
+// validateObject valide les propriétés d'un objet
+func (v *Validator) validateObject(doc *Document, schema *Schema, object map[string]interface{}, field string, errs *[]ValidationError) {
+	for _, name := range schema.Required {
+		if _, exists := object[name]; !exists {
+			*errs = append(*errs, ValidationError{Location: LocationBody, Field: joinField(field, name), Message: "is required"})
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, declared := schema.Properties[name]
+		if !declared {
+			if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+				*errs = append(*errs, ValidationError{Location: LocationBody, Field: joinField(field, name), Message: "is not allowed"})
+			}
+			continue
+		}
+		v.validateSchema(doc, property, object[name], joinField(field, name), errs)
+	}
+}
+
+// validateString valide longueur, motif et format d'une chaîne
+func (v *Validator) validateString(schema *Schema, value string, fail func(string, ...interface{})) {
+	length := len([]rune(value))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		fail("must be at least %d characters long", *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		fail("must be at most %d characters long", *schema.MaxLength)
+	}
+	if schema.Pattern != "" {
+		if pattern := v.pattern(schema.Pattern); pattern != nil && !pattern.MatchString(value) {
+			fail("must match pattern %s", schema.Pattern)
+		}
+	}
+
+	switch schema.Format {
+	case "uuid":
+		if _, err := uuid.Parse(value); err != nil {
+			fail("must be a valid UUID")
+		}
+	case "email":
+		if _, err := mail.ParseAddress(value); err != nil {
+			fail("must be a valid email address")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			fail("must be an RFC 3339 date-time")
+		}
+	}
+}
+
+// validateNumber valide les bornes d'un nombre
+func validateNumber(schema *Schema, value float64, fail func(string, ...interface{})) {
+	if schema.Minimum != nil && value < *schema.Minimum {
+		fail("must be greater than or equal to %v", *schema.Minimum)
+	}
+	if schema.Maximum != nil && value > *schema.Maximum {
+		fail("must be less than or equal to %v", *schema.Maximum)
+	}
+}
+
+// pattern compile (une seule fois) le motif d'un schéma
+func (v *Validator) pattern(expr string) *regexp.Regexp {
+	if cached, exists := v.patterns.Load(expr); exists {
+		return cached.(*regexp.Regexp)
+	}
+	compiled, err := regexp.Compile(expr)
+	if err != nil {
+		return nil
+	}
+	v.patterns.Store(expr, compiled)
+	return compiled
+}
+
+// resolve suit la référence d'un schéma ($ref vers components/schemas)
+func (v *Validator) resolve(doc *Document, schema *Schema) *Schema {
+	for schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, componentSchemaPrefix)
+		schema = doc.Components.Schemas[name]
+	}
+	return schema
+}
+
+// matchesType vérifie le type JSON d'une valeur
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "":
+		return true
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	default:
+		return false
+	}
+}
+
+// inEnum indique si une valeur fait partie d'une énumération
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatEnum liste les valeurs d'une énumération
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, allowed := range enum {
+		values[i] = fmt.Sprint(allowed)
+	}
+	return strings.Join(values, ", ")
+}
+
+// joinField construit le chemin d'un champ imbriqué
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// limitErrors borne le nombre d'erreurs retournées
+func limitErrors(errs []ValidationError) []ValidationError {
+	if len(errs) > MaxValidationErrors {
+		return errs[:MaxValidationErrors]
+	}
+	return errs
+}
+
+// checkReferences vérifie que les références de schémas du document existent
+func (d *Document) checkReferences() error {
+	var check func(schema *Schema, seen map[*Schema]bool) error
+	check = func(schema *Schema, seen map[*Schema]bool) error {
+		if schema == nil || seen[schema] {
+			return nil
+		}
+		seen[schema] = true
+		if schema.Ref != "" {
+			name, ok := strings.CutPrefix(schema.Ref, componentSchemaPrefix)
+			if !ok || d.Components == nil || d.Components.Schemas[name] == nil {
+				return fmt.Errorf("unresolved schema reference %q", schema.Ref)
+			}
+		}
+		if err := check(schema.Items, seen); err != nil {
+			return err
+		}
+		for _, property := range schema.Properties {
+			if err := check(property, seen); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	seen := make(map[*Schema]bool)
+	if d.Components != nil {
+		for _, schema := range d.Components.Schemas {
+			if err := check(schema, seen); err != nil {
+				return err
+			}
+		}
+	}
+	for path, item := range d.Paths {
+		for method, operation := range item.Operations() {
+			parameters := append(append([]*Parameter(nil), item.Parameters...), operation.Parameters...)
+			for _, parameter := range parameters {
+				if err := check(parameter.Schema, seen); err != nil {
+					return fmt.Errorf("%s %s: %w", method, path, err)
+				}
+			}
+			if operation.RequestBody == nil {
+				continue
+			}
+			for _, media := range operation.RequestBody.Content {
+				if err := check(media.Schema, seen); err != nil {
+					return fmt.Errorf("%s %s: %w", method, path, err)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,338 @@
+package proxy
+
+import (
+	"errors"
+	"gateway/internal/config"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrCircuitOpen le circuit de l'instance est ouvert, la requête n'est pas envoyée
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState état d'un circuit breaker
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+// String retourne le nom de l'état
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half_open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// outcome résultat d'un appel vu par le circuit breaker
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	outcomeIgnored // annulé par le client : ne compte ni en succès ni en échec
+)
+
+// breakerBucket compteurs d'une seconde de la fenêtre glissante
+type breakerBucket struct {
+	second   int64
+	requests int
+	failures int
+	slow     int
+}
+
+// CircuitBreaker protège une instance backend
+// Fermé, il observe le taux d'échec et d'appels lents sur une fenêtre glissante et
+// s'ouvre au-delà des seuils. Ouvert, il rejette les appels pendant OpenTimeout, puis
+// laisse passer HalfOpenProbes requêtes de test : toutes doivent réussir pour le refermer.
+type CircuitBreaker struct {
+	service  string
+	instance string
+	config   *config.CircuitBreakerConfig
+
+	mu             sync.Mutex
+	state          BreakerState
+	openedAt       time.Time
+	changedAt      time.Time
+	buckets        []breakerBucket
+	probes         int
+	probeSuccesses int
+}
+
+// BreakerStatus état exposé d'un circuit breaker
+type BreakerStatus struct {
+	Service     string    `json:"service"`
+	Instance    string    `json:"instance"`
+	State       string    `json:"state"`
+	Requests    int       `json:"requests"`
+	FailureRate float64   `json:"failure_rate"`
+	SlowRate    float64   `json:"slow_rate"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+// newCircuitBreaker crée un circuit breaker fermé
+func newCircuitBreaker(service, instance string, cfg *config.CircuitBreakerConfig) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		service:   service,
+		instance:  instance,
+		config:    cfg,
+		changedAt: time.Now(),
+		buckets:   make([]breakerBucket, int(cfg.Window/time.Second)),
+	}
+	breakerState.WithLabelValues(service, instance).Set(float64(BreakerClosed))
+	return cb
+}
+
+// Allow indique si un appel peut être envoyé à l'instance
+// Chaque appel autorisé doit être suivi d'un appel à record.
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	if cb.state == BreakerOpen && now.Sub(cb.openedAt) >= cb.config.OpenTimeout {
+		cb.transition(BreakerHalfOpen, now)
+	}
+
+	switch cb.state {
+	case BreakerClosed:
+		return nil
+	case BreakerHalfOpen:
+		if cb.probes < cb.config.HalfOpenProbes {
+			cb.probes++
+			return nil
+		}
+	}
+
+	breakerRejections.WithLabelValues(cb.service).Inc()
+	return ErrCircuitOpen
+}
+
+// Available indique, sans réserver d'appel, si l'instance peut recevoir du trafic
+func (cb *CircuitBreaker) Available() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case BreakerOpen:
+		return time.Since(cb.openedAt) >= cb.config.OpenTimeout
+	case BreakerHalfOpen:
+		return cb.probes < cb.config.HalfOpenProbes
+	default:
+		return true
+	}
+}
+
+// record enregistre le résultat d'un appel autorisé
+func (cb *CircuitBreaker) record(result outcome, latency time.Duration) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	slow := latency > cb.config.SlowCallThreshold
+
+	switch cb.state {
+	case BreakerHalfOpen:
+		cb.probes--
+		switch {
+		case result == outcomeIgnored:
+		case result == outcomeFailure || slow:
+			cb.transition(BreakerOpen, now)
+		default:
+			cb.probeSuccesses++
+			if cb.probeSuccesses >= cb.config.HalfOpenProbes {
+				cb.transition(BreakerClosed, now)
+			}
+		}
+	case BreakerClosed:
+		if result == outcomeIgnored {
+			return
+		}
+		bucket := cb.bucket(now)
+		bucket.requests++
+		if result == outcomeFailure {
+			bucket.failures++
+		}
+		if slow {
+			bucket.slow++
+		}
+
+		requests, failures, slowCalls := cb.totals(now)
+		if requests < cb.config.MinRequests {
+			return
+		}
+		failureRate := float64(failures) / float64(requests)
+		slowRate := float64(slowCalls) / float64(requests)
+		if failureRate >= cb.config.FailureRateThreshold || slowRate >= cb.config.SlowCallRateThreshold {
+			logrus.WithFields(logrus.Fields{
+				"service":      cb.service,
+				"instance":     cb.instance,
+				"requests":     requests,
+				"failure_rate": failureRate,
+				"slow_rate":    slowRate,
+			}).Warn("Circuit breaker opened")
+			cb.transition(BreakerOpen, now)
+		}
+	}
+}
+
+// status retourne l'état exposé du circuit breaker
+func (cb *CircuitBreaker) status() BreakerStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	status := BreakerStatus{
+		Service:   cb.service,
+		Instance:  cb.instance,
+		State:     cb.state.String(),
+		ChangedAt: cb.changedAt,
+	}
+	if cb.state == BreakerOpen && now.Sub(cb.openedAt) >= cb.config.OpenTimeout {
+		// Prochain appel en test
+		status.State = BreakerHalfOpen.String()
+	}
+
+	requests, failures, slow := cb.totals(now)
+	status.Requests = requests
+	if requests > 0 {
+		status.FailureRate = float64(failures) / float64(requests)
+		status.SlowRate = float64(slow) / float64(requests)
+	}
+	return status
+}
+
+// transition change l'état du circuit (verrou requis)
+func (cb *CircuitBreaker) transition(state BreakerState, now time.Time) {
+	if cb.state == state {
+		return
+	}
+
+	cb.state = state
+	cb.changedAt = now
+	cb.probes = 0
+	cb.probeSuccesses = 0
+
+	switch state {
+	case BreakerOpen:
+		cb.openedAt = now
+	case BreakerClosed:
+		// Repartir d'une fenêtre vide après rétablissement
+		for i := range cb.buckets {
+			cb.buckets[i] = breakerBucket{}
+		}
+	}
+
+	breakerState.WithLabelValues(cb.service, cb.instance).Set(float64(state))
+	breakerTransitions.WithLabelValues(cb.service, cb.instance, state.String()).Inc()
+
+	if state != BreakerOpen {
+		logrus.WithFields(logrus.Fields{
+			"service":  cb.service,
+			"instance": cb.instance,
+			"state":    state.String(),
+		}).Info("Circuit breaker state changed")
+	}
+}
+
+// bucket retourne le compteur de la seconde courante (verrou requis)
+func (cb *CircuitBreaker) bucket(now time.Time) *breakerBucket {
+	second := now.Unix()
+	bucket := &cb.buckets[second%int64(len(cb.buckets))]
+	if bucket.second != second {
+		*bucket = breakerBucket{second: second}
+	}
+	return bucket
+}
+
+// totals somme les compteurs de la fenêtre (verrou requis)
+func (cb *CircuitBreaker) totals(now time.Time) (requests, failures, slow int) {
+	oldest := now.Unix() - int64(len(cb.buckets))
+	for _, bucket := range cb.buckets {
+		if bucket.second > oldest {
+			requests += bucket.requests
+			failures += bucket.failures
+			slow += bucket.slow
+		}
+	}
+	return requests, failures, slow
+}
+
+// breakerSet circuit breakers indexés par service et instance
+type breakerSet struct {
+	config   *config.CircuitBreakerConfig
+	mu       sync.RWMutex
+	breakers map[string]*CircuitBreaker
+}
+
+// newBreakerSet crée l'ensemble des circuit breakers du proxy
+func newBreakerSet(cfg *config.CircuitBreakerConfig) *breakerSet {
+	return &breakerSet{
+		config:   cfg,
+		breakers: make(map[string]*CircuitBreaker),
+	}
+}
+
+// get retourne le circuit breaker d'une instance, créé au premier appel
+func (bs *breakerSet) get(service, instance string) *CircuitBreaker {
+	key := service + "|" + instance
+
+	bs.mu.RLock()
+	cb, exists := bs.breakers[key]
+	bs.mu.RUnlock()
+	if exists {
+		return cb
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if cb, exists = bs.breakers[key]; !exists {
+		cb = newCircuitBreaker(service, instance, bs.config)
+		bs.breakers[key] = cb
+	}
+	return cb
+}
+
+// lookup retourne le circuit breaker d'une instance s'il existe
+func (bs *breakerSet) lookup(service, instance string) (*CircuitBreaker, bool) {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	cb, exists := bs.breakers[service+"|"+instance]
+	return cb, exists
+}
+
+// statuses retourne l'état de tous les circuit breakers, triés par service et instance
+func (bs *breakerSet) statuses() []BreakerStatus {
+	bs.mu.RLock()
+	breakers := make([]*CircuitBreaker, 0, len(bs.breakers))
+	for _, cb := range bs.breakers {
+		breakers = append(breakers, cb)
+	}
+	bs.mu.RUnlock()
+
+	statuses := make([]BreakerStatus, len(breakers))
+	for i, cb := range breakers {
+		statuses[i] = cb.status()
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Service != statuses[j].Service {
+			return statuses[i].Service < statuses[j].Service
+		}
+		return statuses[i].Instance < statuses[j].Instance
+	})
+	return statuses
+}
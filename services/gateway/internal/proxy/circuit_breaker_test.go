@@ -0,0 +1,172 @@
+package proxy
+
+import (
+	"errors"
+	"gateway/internal/config"
+	"testing"
+	"time"
+)
+
+func newTestBreakerConfig() *config.CircuitBreakerConfig {
+	return &config.CircuitBreakerConfig{
+		Window:                10 * time.Second,
+		MinRequests:           4,
+		FailureRateThreshold:  0.5,
+		SlowCallThreshold:     time.Second,
+		SlowCallRateThreshold: 0.8,
+		OpenTimeout:           time.Minute,
+		HalfOpenProbes:        2,
+		RetryBudgetRatio:      0.2,
+		MinRetries:            2,
+	}
+}
+
+// call réserve un appel et enregistre son résultat
+func call(t *testing.T, cb *CircuitBreaker, result outcome, latency time.Duration) {
+	t.Helper()
+
+	if err := cb.Allow(); err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	cb.record(result, latency)
+}
+
+// expireOpenTimeout simule la fin de la durée d'ouverture
+func expireOpenTimeout(cb *CircuitBreaker) {
+	cb.mu.Lock()
+	cb.openedAt = time.Now().Add(-2 * cb.config.OpenTimeout)
+	cb.mu.Unlock()
+}
+
+func TestBreakerOpensOnFailureRate(t *testing.T) {
+	cb := newCircuitBreaker("combat", "http://combat-1:8085", newTestBreakerConfig())
+
+	// Sous MinRequests, même 100 % d'échecs ne l'ouvrent pas
+	for range 3 {
+		call(t, cb, outcomeFailure, 0)
+	}
+	if cb.status().State != "closed" {
+		t.Fatalf("opened before min requests: %+v", cb.status())
+	}
+
+	// Les appels annulés par le client ne comptent pas
+	call(t, cb, outcomeIgnored, 0)
+	if status := cb.status(); status.State != "closed" || status.Requests != 3 {
+		t.Fatalf("ignored call counted: %+v", status)
+	}
+
+	call(t, cb, outcomeSuccess, 0)
+	status := cb.status()
+	if status.State != "open" || status.FailureRate != 0.75 {
+		t.Fatalf("status = %+v, want open at 75%% failures", status)
+	}
+	if err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Allow on open circuit = %v, want ErrCircuitOpen", err)
+	}
+	if cb.Available() {
+		t.Error("open circuit available")
+	}
+}
+
+func TestBreakerOpensOnSlowCalls(t *testing.T) {
+	cb := newCircuitBreaker("combat", "http://combat-1:8085", newTestBreakerConfig())
+
+	for range 4 {
+		call(t, cb, outcomeSuccess, 2*time.Second)
+	}
+	if status := cb.status(); status.State != "open" || status.SlowRate != 1 {
+		t.Fatalf("status = %+v, want open on slow calls", status)
+	}
+}
+
+// TestBreakerHalfOpen après OpenTimeout, HalfOpenProbes appels de test doivent réussir
+func TestBreakerHalfOpen(t *testing.T) {
+	cb := newCircuitBreaker("combat", "http://combat-1:8085", newTestBreakerConfig())
+	for range 4 {
+		call(t, cb, outcomeFailure, 0)
+	}
+
+	expireOpenTimeout(cb)
+	if !cb.Available() || cb.status().State != "half_open" {
+		t.Fatalf("circuit not half open after timeout: %+v", cb.status())
+	}
+
+	// Seuls HalfOpenProbes appels passent en même temps
+	for range 2 {
+		if err := cb.Allow(); err != nil {
+			t.Fatalf("probe refused: %v", err)
+		}
+	}
+	if err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("extra probe = %v, want ErrCircuitOpen", err)
+	}
+
+	// Un appel de test en échec rouvre le circuit
+	cb.record(outcomeSuccess, 0)
+	cb.record(outcomeFailure, 0)
+	if cb.status().State != "open" {
+		t.Fatalf("failed probe did not reopen: %+v", cb.status())
+	}
+
+	expireOpenTimeout(cb)
+	call(t, cb, outcomeSuccess, 0)
+	call(t, cb, outcomeSuccess, 0)
+	status := cb.status()
+	if status.State != "closed" || status.Requests != 0 {
+		t.Errorf("status = %+v, want closed with an empty window", status)
+	}
+}
+
+func TestBreakerSet(t *testing.T) {
+	bs := newBreakerSet(newTestBreakerConfig())
+
+	first := bs.get("world", "http://world-1:8083")
+	if bs.get("world", "http://world-1:8083") != first {
+		t.Error("breaker recreated for the same instance")
+	}
+	bs.get("combat", "http://combat-2:8085")
+	bs.get("combat", "http://combat-1:8085")
+
+	if _, exists := bs.lookup("world", "http://world-2:8083"); exists {
+		t.Error("lookup created a breaker")
+	}
+
+	statuses := bs.statuses()
+	want := []string{"http://combat-1:8085", "http://combat-2:8085", "http://world-1:8083"}
+	if len(statuses) != len(want) {
+		t.Fatalf("statuses = %+v", statuses)
+	}
+	for i, status := range statuses {
+		if status.Instance != want[i] {
+			t.Errorf("statuses[%d] = %s, want %s", i, status.Instance, want[i])
+		}
+	}
+}
+
+// TestRetryBudget MinRetries + RetryBudgetRatio * requêtes de la fenêtre
+func TestRetryBudget(t *testing.T) {
+	rb := newRetryBudget("combat", newTestBreakerConfig())
+
+	// Sans trafic, seuls MinRetries retries sont permis
+	for i := range 2 {
+		if !rb.tryRetry() {
+			t.Fatalf("retry %d refused within min retries", i+1)
+		}
+	}
+	if rb.tryRetry() {
+		t.Fatal("retry allowed beyond min retries")
+	}
+
+	// 10 requêtes à 20 % : deux retries de plus
+	for range 10 {
+		rb.recordRequest()
+	}
+	for i := range 2 {
+		if !rb.tryRetry() {
+			t.Fatalf("retry %d refused within budget", i+1)
+		}
+	}
+	if rb.tryRetry() {
+		t.Error("retry allowed beyond budget")
+	}
+}
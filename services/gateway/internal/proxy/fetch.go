@@ -0,0 +1,51 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gateway/internal/config"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrResponseTooLarge la réponse d'un appel interne dépasse la taille autorisée
+var ErrResponseTooLarge = errors.New("response too large")
+
+// Fetch exécute un GET du gateway vers un service et retourne le statut et le corps
+// Utilisé par les agrégations : l'appel passe par le circuit breaker de l'instance et
+// le budget de retries du service comme une requête proxifiée. path peut contenir une
+// query string ; header porte l'identité et l'authentification à transmettre.
+func (sp *ServiceProxy) Fetch(
+	ctx context.Context,
+	service string,
+	endpoint config.ServiceEndpoint,
+	path string,
+	header http.Header,
+	maxSize int64,
+) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint.URL, "/")+path, http.NoBody)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header = header.Clone()
+	req.Header.Set("X-Gateway-Version", "1.0.0")
+
+	resp, release, err := sp.executeWithRetry(ctx, req, &requestBody{buffered: []byte{}}, service, endpoint)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer release()
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(body)) > maxSize {
+		return resp.StatusCode, nil, ErrResponseTooLarge
+	}
+
+	return resp.StatusCode, body, nil
+}
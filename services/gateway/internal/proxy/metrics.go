@@ -0,0 +1,46 @@
+package proxy
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Métriques Prometheus des circuit breakers
+var (
+	breakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gateway_circuit_breaker_state",
+			Help: "Circuit breaker state per backend instance (0=closed, 1=half_open, 2=open)",
+		},
+		[]string{"service", "instance"},
+	)
+
+	breakerTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_circuit_breaker_transitions_total",
+			Help: "Total number of circuit breaker state changes",
+		},
+		[]string{"service", "instance", "state"},
+	)
+
+	breakerRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_circuit_breaker_rejections_total",
+			Help: "Total number of requests rejected by an open circuit breaker",
+		},
+		[]string{"service"},
+	)
+
+	retryBudgetExhausted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_retry_budget_exhausted_total",
+			Help: "Total number of retries skipped because the retry budget was exhausted",
+		},
+		[]string{"service"},
+	)
+)
+
+// InitMetrics initialize les métriques Prometheus du proxy
+func InitMetrics() {
+	prometheus.MustRegister(breakerState)
+	prometheus.MustRegister(breakerTransitions)
+	prometheus.MustRegister(breakerRejections)
+	prometheus.MustRegister(retryBudgetExhausted)
+}
@@ -2,16 +2,24 @@ package proxy
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"gateway/internal/config"
+	"gateway/internal/tracing"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Constantes pour le proxy
@@ -22,19 +30,35 @@ const (
 	DefaultIdleConnTimeout = 90  // secondes
 	DefaultRetryDelay      = 500 // millisecondes
 	ServerErrorThreshold   = 500 // codes d'erreur >= 500
+
+	// Streaming
+	MaxReplayableBodySize = 64 * 1024 // octets mis en mémoire au plus pour rejouer un corps
+	StreamBufferSize      = 32 * 1024
 )
 
+// ErrNotSent aucune tentative n'a obtenu de connexion au service : la requête ne lui est pas parvenue
+var ErrNotSent = errors.New("request not sent to the service")
+
+// Headers d'identité posés par le gateway après authentification
+// (jamais repris de la requête du client)
+var identityHeaders = []string{"X-User-ID", "X-Username", "X-User-Role"}
+
 // ServiceProxy gère le proxy vers les microservices
 type ServiceProxy struct {
-	config *config.Config
-	client *http.Client
+	config   *config.Config
+	client   *http.Client
+	breakers *breakerSet
+
+	budgetsMu sync.Mutex
+	budgets   map[string]*retryBudget
 }
 
 // NewServiceProxy crée une nouvelle instance du proxy
 func NewServiceProxy(cfg *config.Config) (*ServiceProxy, error) {
 	// Client HTTP optimisé pour les microservices
+	// Pas de timeout global : le timeout de chaque service borne l'attente des headers,
+	// le corps de la réponse est ensuite diffusé sans limite (SSE, long polling)
 	client := &http.Client{
-		Timeout: DefaultProxyTimeout * time.Second,
 		Transport: &http.Transport{
 			MaxIdleConns:        DefaultMaxIdleConns,
 			MaxIdleConnsPerHost: DefaultMaxIdlePerHost,
@@ -43,13 +67,28 @@ func NewServiceProxy(cfg *config.Config) (*ServiceProxy, error) {
 	}
 
 	return &ServiceProxy{
-		config: cfg,
-		client: client,
+		config:   cfg,
+		client:   client,
+		breakers: newBreakerSet(&cfg.CircuitBreaker),
+		budgets:  make(map[string]*retryBudget),
 	}, nil
 }
 
-// Forward proxie une requête vers un service backend
-func (sp *ServiceProxy) Forward(c *gin.Context, endpoint config.ServiceEndpoint) error {
+// Available indique si le circuit breaker d'une instance laisse passer le trafic
+func (sp *ServiceProxy) Available(service, instanceURL string) bool {
+	cb, exists := sp.breakers.lookup(service, instanceURL)
+	return !exists || cb.Available()
+}
+
+// BreakerStatuses retourne l'état des circuit breakers de toutes les instances
+func (sp *ServiceProxy) BreakerStatuses() []BreakerStatus {
+	return sp.breakers.statuses()
+}
+
+// Forward proxie une requête vers une instance d'un service backend
+// Les corps de requête et de réponse sont diffusés sans mise en mémoire, sauf un petit
+// corps de requête gardé pour pouvoir être rejoué. Les upgrades WebSocket sont relayés.
+func (sp *ServiceProxy) Forward(c *gin.Context, service string, endpoint config.ServiceEndpoint) error {
 	// Construire l'URL de destination
 	targetURL, err := url.Parse(endpoint.URL)
 	if err != nil {
@@ -64,21 +103,26 @@ func (sp *ServiceProxy) Forward(c *gin.Context, endpoint config.ServiceEndpoint)
 	targetURL.Path = targetPath
 	targetURL.RawQuery = c.Request.URL.RawQuery
 
-	// Lire le body de la requête
-	var body []byte
-	if c.Request.Body != nil {
-		body, err = io.ReadAll(c.Request.Body)
-		if err != nil {
-			return fmt.Errorf("failed to read request body: %w", err)
-		}
+	if IsWebSocketUpgrade(c.Request) {
+		// Le token de l'upgrade a été validé par le gateway, il ne doit pas fuiter vers le backend
+		query := targetURL.Query()
+		query.Del("access_token")
+		targetURL.RawQuery = query.Encode()
+		return sp.forwardWebSocket(c, service, endpoint, targetURL)
 	}
 
-	// Créer la nouvelle requête
+	// Préparer le corps : rejouable s'il est petit et que des retries sont possibles
+	body, err := prepareBody(c.Request, endpoint.Retries)
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	// Créer la nouvelle requête (le corps est attaché à chaque tentative)
 	req, err := http.NewRequestWithContext(
 		c.Request.Context(),
 		c.Request.Method,
 		targetURL.String(),
-		bytes.NewReader(body),
+		http.NoBody,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -86,54 +130,71 @@ func (sp *ServiceProxy) Forward(c *gin.Context, endpoint config.ServiceEndpoint)
 
 	// Copier les headers importants
 	sp.copyHeaders(c.Request, req)
-
-	// Ajouter des headers spécifiques au proxy
-	req.Header.Set("X-Forwarded-For", c.ClientIP())
-	req.Header.Set("X-Forwarded-Proto", "http")
-	req.Header.Set("X-Gateway-Version", "1.0.0")
+	sp.setForwardingHeaders(c, req)
 
 	// Log de la requête proxy
-	logrus.WithFields(logrus.Fields{
+	logrus.WithContext(c.Request.Context()).WithFields(logrus.Fields{
 		"method":         req.Method,
 		"original_path":  originalPath,
 		"target_path":    targetPath,
 		"target_url":     targetURL.String(),
 		"client_ip":      c.ClientIP(),
 		"request_id":     c.GetHeader("X-Request-ID"),
-		"user_id":        c.GetHeader("X-User-ID"),
-		"content_length": len(body),
+		"user_id":        req.Header.Get("X-User-ID"),
+		"content_length": c.Request.ContentLength,
+		"replayable":     body.replayable(),
 	}).Debug("Proxying request to service")
 
 	// Exécuter la requête avec retry
-	resp, err := sp.executeWithRetry(req, endpoint.Retries, endpoint.Timeout)
+	resp, release, err := sp.executeWithRetry(c.Request.Context(), req, body, service, endpoint)
 	if err != nil {
 		return fmt.Errorf("service request failed: %w", err)
 	}
+	defer release()
 	defer resp.Body.Close()
 
-	// Lire la réponse
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
-
 	// Copier les headers de réponse
 	sp.copyResponseHeaders(resp, c)
+	if resp.ContentLength >= 0 {
+		c.Header("Content-Length", strconv.FormatInt(resp.ContentLength, 10))
+	}
+
+	// Server-Sent Events : pas de tampon ni de write timeout, chaque événement est transmis aussitôt
+	eventStream := isEventStream(resp)
+	if eventStream {
+		c.Header("X-Accel-Buffering", "no")
+		if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+			logrus.WithError(err).Debug("Failed to clear write deadline for event stream")
+		}
+	}
+
+	// Envoyer la réponse au client au fil de l'eau
+	c.Status(resp.StatusCode)
+	c.Writer.WriteHeaderNow()
+	written, err := streamBody(c.Writer, resp.Body, eventStream)
 
 	// Log de la réponse
-	logrus.WithFields(logrus.Fields{
+	fields := logrus.Fields{
 		"status_code":     resp.StatusCode,
-		"response_length": len(responseBody),
+		"response_length": written,
 		"target_url":      targetURL.String(),
 		"request_id":      c.GetHeader("X-Request-ID"),
-	}).Debug("Service response received")
-
-	// Envoyer la réponse au client
-	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), responseBody)
+	}
+	if err != nil {
+		// Les headers sont partis : l'erreur ne peut plus être renvoyée au client
+		logrus.WithFields(fields).WithError(err).Warn("Response stream interrupted")
+		return nil
+	}
+	logrus.WithFields(fields).Debug("Service response received")
 
 	return nil
 }
 
+// TargetPath chemin de la requête côté service (contrats OpenAPI des services)
+func (sp *ServiceProxy) TargetPath(originalPath string) string {
+	return sp.transformPath(originalPath)
+}
+
 // transformPath transforms le path de la requête pour le service de destination
 func (sp *ServiceProxy) transformPath(originalPath string) string {
 	// Mapping des préfixes Gateway vers les paths des services
@@ -190,35 +251,65 @@ func (sp *ServiceProxy) transformPath(originalPath string) string {
 }
 
 // executeWithRetry exécute une requête avec retry automatique
-func (sp *ServiceProxy) executeWithRetry(req *http.Request, maxRetries int, timeout time.Duration) (*http.Response, error) {
-	// Sauvegarder le body original pour les retries
-	var originalBody []byte
-	if req.Body != nil {
-		var err error
-		originalBody, err = io.ReadAll(req.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read request body for retry: %w", err)
-		}
-		req.Body.Close()
+// Chaque tentative passe par le circuit breaker de l'instance, et les retries sont
+// limités par le budget du service pour ne pas amplifier une panne. Un corps diffusé
+// n'étant pas rejouable, une seule tentative est faite dans ce cas. Une écriture n'est
+// renvoyée que si la tentative précédente n'a obtenu aucune connexion : le service a pu
+// l'appliquer dès qu'un octet lui est parvenu, même sans réponse, clé d'idempotence ou non.
+// Si toutes les tentatives reçoivent une erreur du service, sa dernière réponse est
+// retournée (ex. 503 avec Retry-After) ; si aucune n'a été envoyée, l'erreur enveloppe
+// ErrNotSent. release doit être appelé une fois le corps de la réponse consommé.
+func (sp *ServiceProxy) executeWithRetry(
+	clientCtx context.Context,
+	req *http.Request,
+	body *requestBody,
+	service string,
+	endpoint config.ServiceEndpoint,
+) (resp *http.Response, release func(), err error) {
+	breaker := sp.breakers.get(service, endpoint.URL)
+	budget := sp.retryBudget(service)
+	budget.recordRequest()
+
+	maxRetries := endpoint.Retries
+	if !body.replayable() {
+		maxRetries = 0
 	}
+	resendable := safeMethod(req)
+	var sent atomic.Bool // une tentative a obtenu une connexion au service
 
 	var lastErr error
-
-	// Créer un client avec timeout spécifique
-	client := &http.Client{
-		Timeout:   timeout,
-		Transport: sp.client.Transport,
+	var lastResp *http.Response // dernière réponse 5xx, gardée ouverte jusqu'à la tentative suivante
+	var lastCancel context.CancelFunc
+	discardLast := func() {
+		if lastResp != nil {
+			lastResp.Body.Close()
+			lastCancel()
+			lastResp, lastCancel = nil, nil
+		}
 	}
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		// Restaurer le body pour chaque tentative
-		if originalBody != nil {
-			req.Body = io.NopCloser(bytes.NewReader(originalBody))
-		}
-
-		// Log de la tentative
 		if attempt > 0 {
-			logrus.WithFields(logrus.Fields{
+			if !budget.tryRetry() {
+				logrus.WithContext(clientCtx).WithFields(logrus.Fields{
+					"service":    service,
+					"url":        req.URL.String(),
+					"request_id": req.Header.Get("X-Request-ID"),
+				}).Warn("Retry budget exhausted, not retrying")
+				break
+			}
+
+			// Attendre avant de retry (backoff linéaire), sauf si le client abandonne
+			backoff := time.NewTimer(time.Duration(attempt) * DefaultRetryDelay * time.Millisecond)
+			select {
+			case <-clientCtx.Done():
+				backoff.Stop()
+				discardLast()
+				return nil, nil, clientCtx.Err()
+			case <-backoff.C:
+			}
+
+			logrus.WithContext(clientCtx).WithFields(logrus.Fields{
 				"attempt":     attempt + 1,
 				"max_retries": maxRetries + 1,
 				"url":         req.URL.String(),
@@ -226,36 +317,93 @@ func (sp *ServiceProxy) executeWithRetry(req *http.Request, maxRetries int, time
 			}).Warn("Retrying service request")
 		}
 
+		if err := breaker.Allow(); err != nil {
+			if lastResp != nil {
+				return lastResp, lastCancel, nil
+			}
+			if lastErr == nil {
+				return nil, nil, err
+			}
+			return nil, nil, fmt.Errorf("%w after error: %v", err, lastErr)
+		}
+		discardLast()
+
+		// Le timeout du service borne l'attente des headers, pas la lecture du corps
+		attemptCtx, cancel := context.WithCancel(clientCtx)
+		timer := time.AfterFunc(endpoint.Timeout, cancel)
+
+		attemptReq := req.WithContext(attemptCtx)
+		attemptReq.Body, attemptReq.ContentLength = body.attach()
+
+		// Span de la tentative, dont le contexte est transmis au service
+		spanCtx, span := tracing.StartClientSpan(attemptCtx, service, attemptReq)
+		span.SetAttributes(attribute.Int("http.request.resend_count", attempt))
+		attemptReq = attemptReq.WithContext(httptrace.WithClientTrace(spanCtx, &httptrace.ClientTrace{
+			GotConn: func(httptrace.GotConnInfo) { sent.Store(true) },
+		}))
+
 		// Exécuter la requête
-		resp, err := client.Do(req)
+		start := time.Now()
+		resp, err := sp.client.Do(attemptReq)
+		latency := time.Since(start)
+		timedOut := !timer.Stop()
+		tracing.EndClientSpan(span, resp, err)
+
 		if err != nil {
+			cancel()
+			// Requête annulée par le client : l'instance n'est pas en cause
+			if clientCtx.Err() != nil {
+				breaker.record(outcomeIgnored, latency)
+				return nil, nil, err
+			}
+			if timedOut {
+				err = fmt.Errorf("no response after %s: %w", endpoint.Timeout, err)
+			}
+			breaker.record(outcomeFailure, latency)
 			lastErr = err
-
-			// Attendre avant de retry (backoff exponential)
-			if attempt < maxRetries {
-				waitTime := time.Duration(attempt+1) * DefaultRetryDelay * time.Millisecond
-				time.Sleep(waitTime)
+			if !resendable && sent.Load() {
+				// L'écriture a pu parvenir au service : la renvoyer risquerait de l'appliquer deux fois
+				break
 			}
 			continue
 		}
 
 		// Vérifier si la réponse indique une erreur de service
 		if resp.StatusCode >= ServerErrorThreshold {
-			resp.Body.Close()
-			lastErr = fmt.Errorf("service returned status %d", resp.StatusCode)
-
-			if attempt < maxRetries {
-				waitTime := time.Duration(attempt+1) * DefaultRetryDelay * time.Millisecond
-				time.Sleep(waitTime)
+			breaker.record(outcomeFailure, latency)
+			if !resendable {
+				return resp, cancel, nil
 			}
+			lastResp, lastCancel = resp, cancel
+			lastErr = fmt.Errorf("service returned status %d", resp.StatusCode)
 			continue
 		}
 
 		// Succès
-		return resp, nil
+		breaker.record(outcomeSuccess, latency)
+		return resp, cancel, nil
+	}
+
+	if lastResp != nil {
+		return lastResp, lastCancel, nil
 	}
+	if !sent.Load() {
+		return nil, nil, fmt.Errorf("%w: all retry attempts failed, last error: %w", ErrNotSent, lastErr)
+	}
+	return nil, nil, fmt.Errorf("all retry attempts failed, last error: %w", lastErr)
+}
 
-	return nil, fmt.Errorf("all retry attempts failed, last error: %w", lastErr)
+// retryBudget retourne le budget de retries d'un service
+func (sp *ServiceProxy) retryBudget(service string) *retryBudget {
+	sp.budgetsMu.Lock()
+	defer sp.budgetsMu.Unlock()
+
+	budget, exists := sp.budgets[service]
+	if !exists {
+		budget = newRetryBudget(service, &sp.config.CircuitBreaker)
+		sp.budgets[service] = budget
+	}
+	return budget
 }
 
 // copyHeaders copie les headers importants de la requête originale
@@ -267,14 +415,14 @@ func (sp *ServiceProxy) copyHeaders(originalReq *http.Request, newReq *http.Requ
 		"Accept",
 		"Accept-Encoding",
 		"Accept-Language",
+		"If-None-Match",
+		"If-Modified-Since",
 		"User-Agent",
 		"X-Request-ID",
-		"X-User-ID",
-		"X-Username",
-		"X-User-Role",
 		"X-Client-Version",
 		"X-Game-Session",
 		"Authorization",
+		sp.config.Idempotency.Header,
 	}
 
 	for _, header := range headersToProxy {
@@ -285,12 +433,33 @@ func (sp *ServiceProxy) copyHeaders(originalReq *http.Request, newReq *http.Requ
 
 	// Copier tous les headers personnalisés commençant par X-
 	for name, values := range originalReq.Header {
-		if len(name) > 2 && name[:2] == "X-" {
+		if len(name) > 2 && name[:2] == "X-" && newReq.Header.Get(name) == "" {
 			for _, value := range values {
 				newReq.Header.Add(name, value)
 			}
 		}
 	}
+
+	// L'identité vient uniquement du token validé par le gateway
+	for _, header := range identityHeaders {
+		newReq.Header.Del(header)
+	}
+
+	// Contexte de trace de la requête (traceparent) : le service continue la trace du gateway
+	tracing.InjectHeaders(originalReq.Context(), newReq.Header)
+}
+
+// setForwardingHeaders ajoute les headers du proxy et l'identité de l'utilisateur authentifié
+func (sp *ServiceProxy) setForwardingHeaders(c *gin.Context, req *http.Request) {
+	req.Header.Set("X-Forwarded-For", c.ClientIP())
+	req.Header.Set("X-Forwarded-Proto", "http")
+	req.Header.Set("X-Gateway-Version", "1.0.0")
+
+	if userID, exists := c.Get("user_id"); exists {
+		req.Header.Set("X-User-ID", fmt.Sprint(userID))
+		req.Header.Set("X-Username", c.GetString("username"))
+		req.Header.Set("X-User-Role", c.GetString("user_role"))
+	}
 }
 
 // copyResponseHeaders copie les headers de réponse appropriés
@@ -302,6 +471,11 @@ func (sp *ServiceProxy) copyResponseHeaders(resp *http.Response, c *gin.Context)
 		"Expires",
 		"Last-Modified",
 		"ETag",
+		"Vary",
+		"Content-Encoding",
+		"Content-Disposition",
+		"Location",
+		"Retry-After",
 		"X-Rate-Limit-Remaining",
 		"X-Request-ID",
 	}
@@ -336,3 +510,98 @@ func extractServiceFromURL(urlStr string) string {
 	}
 	return "unknown"
 }
+
+// requestBody corps d'une requête proxifiée
+// Un petit corps est gardé en mémoire pour pouvoir être rejoué, un corps plus gros
+// (ou sans retry possible) est diffusé directement vers le backend.
+type requestBody struct {
+	buffered []byte
+	stream   io.Reader
+	length   int64
+}
+
+// prepareBody prépare le corps de la requête du client
+func prepareBody(r *http.Request, retries int) (*requestBody, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return &requestBody{buffered: []byte{}}, nil
+	}
+
+	if retries == 0 || r.ContentLength > MaxReplayableBodySize {
+		return &requestBody{stream: r.Body, length: r.ContentLength}, nil
+	}
+
+	// Taille connue et petite, ou inconnue : lire au plus la limite + 1 octet
+	head, err := io.ReadAll(io.LimitReader(r.Body, MaxReplayableBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(head) <= MaxReplayableBodySize {
+		return &requestBody{buffered: head, length: int64(len(head))}, nil
+	}
+
+	// Trop gros pour être rejoué : diffuser ce qui a été lu puis le reste
+	return &requestBody{
+		stream: io.MultiReader(bytes.NewReader(head), r.Body),
+		length: r.ContentLength,
+	}, nil
+}
+
+// replayable indique si le corps peut être renvoyé lors d'un retry
+func (rb *requestBody) replayable() bool {
+	return rb.stream == nil
+}
+
+// attach retourne le corps et sa taille pour une tentative (-1 si inconnue)
+func (rb *requestBody) attach() (io.ReadCloser, int64) {
+	if rb.stream != nil {
+		return io.NopCloser(rb.stream), rb.length
+	}
+	if len(rb.buffered) == 0 {
+		return http.NoBody, 0
+	}
+	return io.NopCloser(bytes.NewReader(rb.buffered)), rb.length
+}
+
+// safeMethod indique si une requête peut être renvoyée après être parvenue au service : lecture
+// (GET, HEAD, OPTIONS). Les services ne dédupliquent pas les écritures, même avec une clé
+// d'idempotence (gardée par le gateway, pas par eux) ; PUT et DELETE ne sont pas rejoués non plus.
+func safeMethod(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// isEventStream indique si la réponse est un flux Server-Sent Events
+func isEventStream(resp *http.Response) bool {
+	return strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream")
+}
+
+// streamBody copie le corps de la réponse vers le client
+// Pour un flux d'événements, chaque écriture est envoyée immédiatement.
+func streamBody(w gin.ResponseWriter, body io.Reader, flush bool) (int64, error) {
+	buf := make([]byte, StreamBufferSize)
+	var written int64
+
+	for {
+		n, readErr := body.Read(buf)
+		if n > 0 {
+			m, err := w.Write(buf[:n])
+			written += int64(m)
+			if err != nil {
+				return written, err
+			}
+			if flush {
+				w.Flush()
+			}
+		}
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, readErr
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"gateway/internal/config"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestProxy(t *testing.T) *ServiceProxy {
+	t.Helper()
+
+	sp, err := NewServiceProxy(&config.Config{
+		CircuitBreaker: config.CircuitBreakerConfig{
+			Window:               time.Minute,
+			MinRequests:          100,
+			FailureRateThreshold: 0.5,
+			OpenTimeout:          time.Minute,
+			HalfOpenProbes:       1,
+			RetryBudgetRatio:     1,
+			MinRetries:           10,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewServiceProxy: %v", err)
+	}
+	return sp
+}
+
+// send exécute une requête vers url avec une tentative de retry au plus
+func send(t *testing.T, sp *ServiceProxy, method, url string) (*http.Response, error) {
+	t.Helper()
+
+	incoming := httptest.NewRequest(method, url, strings.NewReader(`{"item_id":"sword"}`))
+	incoming.Header.Set("Idempotency-Key", "key-1")
+	body, err := prepareBody(incoming, 1)
+	if err != nil {
+		t.Fatalf("prepareBody: %v", err)
+	}
+	req, err := http.NewRequestWithContext(context.Background(), method, url, http.NoBody)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header = incoming.Header.Clone()
+
+	endpoint := config.ServiceEndpoint{URL: url, Timeout: time.Second, Retries: 1}
+	resp, release, err := sp.executeWithRetry(context.Background(), req, body, "inventory", endpoint)
+	if err == nil {
+		t.Cleanup(func() {
+			resp.Body.Close()
+			release()
+		})
+	}
+	return resp, err
+}
+
+// flakyServer répond 500 à la première requête puis 200, et compte les requêtes reçues
+func flakyServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestExecuteWithRetryRetriesReads(t *testing.T) {
+	server, calls := flakyServer(t)
+
+	resp, err := send(t, newTestProxy(t), http.MethodGet, server.URL)
+	if err != nil {
+		t.Fatalf("executeWithRetry: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Fatalf("status %d after %d calls, want 200 after 2", resp.StatusCode, calls.Load())
+	}
+}
+
+// TestExecuteWithRetryDoesNotResendWrites une écriture qui a reçu une erreur n'est pas renvoyée,
+// même avec une clé d'idempotence
+func TestExecuteWithRetryDoesNotResendWrites(t *testing.T) {
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		server, calls := flakyServer(t)
+
+		resp, err := send(t, newTestProxy(t), method, server.URL)
+		if err != nil {
+			t.Fatalf("%s: executeWithRetry: %v", method, err)
+		}
+		if resp.StatusCode != http.StatusInternalServerError || calls.Load() != 1 {
+			t.Errorf("%s: status %d after %d calls, want the 500 after 1", method, resp.StatusCode, calls.Load())
+		}
+	}
+}
+
+// TestExecuteWithRetryConnectionLostAfterSend une connexion coupée après l'envoi d'une écriture
+// n'entraîne pas de nouvelle tentative, et l'erreur ne la présente pas comme non envoyée
+func TestExecuteWithRetryConnectionLostAfterSend(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	_, err := send(t, newTestProxy(t), http.MethodPost, server.URL)
+	if err == nil {
+		t.Fatal("lost connection not reported")
+	}
+	if errors.Is(err, ErrNotSent) {
+		t.Errorf("write that reached the service reported as not sent: %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("write sent %d times, want 1", calls.Load())
+	}
+}
+
+// TestExecuteWithRetryConnectionRefused sans connexion, l'écriture n'est pas parvenue au service
+func TestExecuteWithRetryConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	url := "http://" + listener.Addr().String()
+	listener.Close()
+
+	_, err = send(t, newTestProxy(t), http.MethodPost, url)
+	if !errors.Is(err, ErrNotSent) {
+		t.Fatalf("error = %v, want ErrNotSent", err)
+	}
+}
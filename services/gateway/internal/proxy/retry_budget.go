@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"gateway/internal/config"
+	"sync"
+	"time"
+)
+
+// budgetBucket compteurs d'une seconde du budget de retries
+type budgetBucket struct {
+	second   int64
+	requests int
+	retries  int
+}
+
+// retryBudget limite les retries d'un service à une fraction de son trafic
+// Pendant une panne, les retries ne peuvent donc pas multiplier la charge sur les backends.
+type retryBudget struct {
+	service string
+	config  *config.CircuitBreakerConfig
+	mu      sync.Mutex
+	buckets []budgetBucket
+}
+
+// newRetryBudget crée le budget de retries d'un service
+func newRetryBudget(service string, cfg *config.CircuitBreakerConfig) *retryBudget {
+	return &retryBudget{
+		service: service,
+		config:  cfg,
+		buckets: make([]budgetBucket, int(cfg.Window/time.Second)),
+	}
+}
+
+// recordRequest comptabilise une requête initiale
+func (rb *retryBudget) recordRequest() {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	rb.bucket(time.Now()).requests++
+}
+
+// tryRetry réserve un retry si le budget le permet
+func (rb *retryBudget) tryRetry() bool {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	now := time.Now()
+	oldest := now.Unix() - int64(len(rb.buckets))
+	requests, retries := 0, 0
+	for _, bucket := range rb.buckets {
+		if bucket.second > oldest {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+
+	allowed := float64(rb.config.MinRetries) + rb.config.RetryBudgetRatio*float64(requests)
+	if float64(retries) >= allowed {
+		retryBudgetExhausted.WithLabelValues(rb.service).Inc()
+		return false
+	}
+
+	rb.bucket(now).retries++
+	return true
+}
+
+// bucket retourne le compteur de la seconde courante (verrou requis)
+func (rb *retryBudget) bucket(now time.Time) *budgetBucket {
+	second := now.Unix()
+	bucket := &rb.buckets[second%int64(len(rb.buckets))]
+	if bucket.second != second {
+		*bucket = budgetBucket{second: second}
+	}
+	return bucket
+}
@@ -0,0 +1,222 @@
+package proxy
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"gateway/internal/config"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Headers de la poignée de main WebSocket transmis au backend
+var webSocketHeaders = []string{
+	"Origin",
+	"Sec-WebSocket-Key",
+	"Sec-WebSocket-Version",
+	"Sec-WebSocket-Protocol",
+	"Sec-WebSocket-Extensions",
+}
+
+// IsWebSocketUpgrade indique si la requête demande un upgrade WebSocket
+func IsWebSocketUpgrade(r *http.Request) bool {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+	for _, value := range r.Header.Values("Connection") {
+		for _, token := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// forwardWebSocket relaie un upgrade WebSocket vers une instance backend
+// La poignée de main est rejouée vers le backend avec l'identité de l'utilisateur. Si le
+// backend accepte (101), la connexion du client est détournée et les trames sont copiées
+// dans les deux sens jusqu'à la fermeture d'un des côtés. L'authentification a déjà été
+// faite par le gateway avant d'arriver ici.
+func (sp *ServiceProxy) forwardWebSocket(
+	c *gin.Context,
+	service string,
+	endpoint config.ServiceEndpoint,
+	targetURL *url.URL,
+) error {
+	breaker := sp.breakers.get(service, endpoint.URL)
+	if err := breaker.Allow(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	backendConn, err := dialBackend(targetURL, endpoint.Timeout)
+	if err != nil {
+		breaker.record(outcomeFailure, time.Since(start))
+		return fmt.Errorf("failed to connect to service: %w", err)
+	}
+
+	// Poignée de main avec le backend, bornée par le timeout du service
+	if err := backendConn.SetDeadline(time.Now().Add(endpoint.Timeout)); err != nil {
+		backendConn.Close()
+		breaker.record(outcomeFailure, time.Since(start))
+		return fmt.Errorf("failed to set handshake deadline: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, targetURL.String(), http.NoBody)
+	if err != nil {
+		backendConn.Close()
+		breaker.record(outcomeIgnored, time.Since(start))
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	sp.copyHeaders(c.Request, req)
+	sp.setForwardingHeaders(c, req)
+	for _, header := range webSocketHeaders {
+		if value := c.Request.Header.Get(header); value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+
+	backendReader := bufio.NewReader(backendConn)
+	resp, err := writeHandshake(backendConn, backendReader, req)
+	if err != nil {
+		backendConn.Close()
+		breaker.record(outcomeFailure, time.Since(start))
+		return fmt.Errorf("websocket handshake with service failed: %w", err)
+	}
+
+	// Refus du backend : la réponse est transmise telle quelle
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer backendConn.Close()
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= ServerErrorThreshold {
+			breaker.record(outcomeFailure, time.Since(start))
+		} else {
+			breaker.record(outcomeSuccess, time.Since(start))
+		}
+		sp.copyResponseHeaders(resp, c)
+		c.Status(resp.StatusCode)
+		c.Writer.WriteHeaderNow()
+		if _, err := streamBody(c.Writer, resp.Body, false); err != nil {
+			logrus.WithError(err).Warn("Failed to relay websocket handshake response")
+		}
+		return nil
+	}
+	breaker.record(outcomeSuccess, time.Since(start))
+
+	// Détourner la connexion du client
+	c.Writer.WriteHeader(http.StatusSwitchingProtocols)
+	clientConn, clientBuf, err := c.Writer.Hijack()
+	if err != nil {
+		backendConn.Close()
+		return fmt.Errorf("failed to hijack client connection: %w", err)
+	}
+
+	fields := logrus.Fields{
+		"service":    service,
+		"target_url": targetURL.String(),
+		"user_id":    req.Header.Get("X-User-ID"),
+		"request_id": c.GetHeader("X-Request-ID"),
+	}
+
+	if err := relayHandshake(clientConn, clientBuf, backendConn, resp); err != nil {
+		logrus.WithFields(fields).WithError(err).Warn("Failed to complete websocket handshake with client")
+		clientConn.Close()
+		backendConn.Close()
+		return nil
+	}
+
+	logrus.WithFields(fields).Debug("WebSocket connection established")
+
+	// Copier les trames dans les deux sens, en reprenant les octets déjà lus par les tampons
+	errc := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(backendConn, clientBuf.Reader)
+		errc <- err
+	}()
+	go func() {
+		_, err := io.Copy(clientConn, backendReader)
+		errc <- err
+	}()
+
+	err = <-errc
+	clientConn.Close()
+	backendConn.Close()
+	<-errc
+
+	fields["duration"] = time.Since(start).String()
+	if err != nil && !isClosedConnError(err) {
+		logrus.WithFields(fields).WithError(err).Debug("WebSocket connection interrupted")
+	} else {
+		logrus.WithFields(fields).Debug("WebSocket connection closed")
+	}
+
+	return nil
+}
+
+// dialBackend ouvre une connexion TCP (ou TLS pour https/wss) vers le backend
+func dialBackend(targetURL *url.URL, timeout time.Duration) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	host := targetURL.Host
+
+	switch targetURL.Scheme {
+	case "https", "wss":
+		if targetURL.Port() == "" {
+			host = net.JoinHostPort(targetURL.Hostname(), "443")
+		}
+		return tls.DialWithDialer(dialer, "tcp", host, &tls.Config{
+			ServerName: targetURL.Hostname(),
+			MinVersion: tls.VersionTLS12,
+		})
+	default:
+		if targetURL.Port() == "" {
+			host = net.JoinHostPort(targetURL.Hostname(), "80")
+		}
+		return dialer.Dial("tcp", host)
+	}
+}
+
+// writeHandshake envoie la requête d'upgrade et lit la réponse du backend
+func writeHandshake(conn net.Conn, reader *bufio.Reader, req *http.Request) (*http.Response, error) {
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+	return http.ReadResponse(reader, req)
+}
+
+// relayHandshake renvoie au client la réponse 101 du backend puis lève les deadlines
+func relayHandshake(clientConn net.Conn, clientBuf *bufio.ReadWriter, backendConn net.Conn, resp *http.Response) error {
+	if err := clientConn.SetDeadline(time.Time{}); err != nil {
+		return err
+	}
+	if err := backendConn.SetDeadline(time.Time{}); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(clientBuf, "HTTP/1.1 %s\r\n", resp.Status); err != nil {
+		return err
+	}
+	if err := resp.Header.Write(clientBuf); err != nil {
+		return err
+	}
+	if _, err := clientBuf.WriteString("\r\n"); err != nil {
+		return err
+	}
+	return clientBuf.Flush()
+}
+
+// isClosedConnError indique une fermeture normale d'une des deux connexions
+func isClosedConnError(err error) bool {
+	return errors.Is(err, net.ErrClosed)
+}
@@ -0,0 +1,263 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"gateway/internal/config"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+// Constantes du limiteur
+const (
+	// defaultPolicyName politique appliquée aux routes sans politique dédiée
+	defaultPolicyName = "default"
+
+	// storeOpenTimeoutFactor délai d'ouverture du bucket partagé, en multiples du timeout du store
+	storeOpenTimeoutFactor = 40
+
+	// bucketTTLFactor durée de vie des compteurs partagés, en multiples de la plus longue fenêtre
+	bucketTTLFactor = 2
+)
+
+// Subject client limité : utilisateur authentifié ou adresse IP
+type Subject struct {
+	UserID string // vide si anonyme
+	IP     string
+	Tier   string
+}
+
+// key retourne l'identifiant du client dans les compteurs
+func (s Subject) key() string {
+	if s.UserID != "" {
+		return "user." + s.UserID
+	}
+	return "ip." + s.IP
+}
+
+// Decision résultat du rate limiting d'une requête
+type Decision struct {
+	Policy    string
+	Tier      string
+	Unlimited bool
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Window    time.Duration
+	Reset     time.Duration // avant le début de la prochaine fenêtre
+}
+
+// policy politique compilée
+type policy struct {
+	name    string
+	methods map[string]bool
+	path    string
+	prefix  bool
+	window  time.Duration
+	limits  map[string]int
+}
+
+// matches indique si la politique s'applique à une route
+func (p *policy) matches(method, route string) bool {
+	if len(p.methods) > 0 && !p.methods[method] {
+		return false
+	}
+	if p.prefix {
+		return strings.HasPrefix(route, p.path)
+	}
+	return route == p.path
+}
+
+// limit retourne la limite d'un tier (celle des joueurs si le tier n'est pas déclaré)
+func (p *policy) limit(tier string) int {
+	if limit, exists := p.limits[tier]; exists {
+		return limit
+	}
+	return p.limits[config.TierPlayer]
+}
+
+// Limiter applique les politiques de rate limiting
+type Limiter struct {
+	config    *config.RateLimitConfig
+	policies  []*policy
+	overrides map[string]float64
+	store     Store
+	local     *MemoryStore // store par défaut et repli si le store partagé échoue
+	stop      chan struct{}
+}
+
+// NewLimiter crée le limiteur et son store (natsConn requis pour le store "nats")
+// Si le store partagé ne peut pas être ouvert, chaque réplica compte localement.
+func NewLimiter(cfg *config.RateLimitConfig, natsConn *nats.Conn) *Limiter {
+	l := &Limiter{
+		config:    cfg,
+		overrides: make(map[string]float64),
+		local:     NewMemoryStore(),
+		stop:      make(chan struct{}),
+	}
+	l.store = l.local
+
+	maxWindow := time.Minute
+	for i := range cfg.Policies {
+		l.policies = append(l.policies, compilePolicy(&cfg.Policies[i]))
+		if cfg.Policies[i].Window > maxWindow {
+			maxWindow = cfg.Policies[i].Window
+		}
+	}
+	l.policies = append(l.policies, &policy{
+		name:   defaultPolicyName,
+		prefix: true,
+		window: time.Minute,
+		limits: map[string]int{config.TierPlayer: cfg.RequestsPerMinute},
+	})
+
+	for _, override := range cfg.Overrides {
+		l.overrides[override.UserID] = override.Multiplier
+	}
+
+	if cfg.Store == config.RateLimitStoreNATS {
+		if store, err := l.openNATSStore(natsConn, maxWindow); err != nil {
+			logrus.WithError(err).Warn("Shared rate limit store unavailable, counting per replica")
+		} else {
+			l.store = store
+			logrus.WithField("bucket", cfg.Bucket).Info("Rate limits shared through NATS KV")
+		}
+	}
+
+	return l
+}
+
+// openNATSStore ouvre le bucket partagé des compteurs
+func (l *Limiter) openNATSStore(natsConn *nats.Conn, maxWindow time.Duration) (Store, error) {
+	if natsConn == nil {
+		return nil, fmt.Errorf("NATS is not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.config.StoreTimeout*storeOpenTimeoutFactor)
+	defer cancel()
+
+	// Les clés contiennent le début de la fenêtre : le TTL fait disparaître les anciennes
+	return NewNATSStore(ctx, natsConn, l.config.Bucket, bucketTTLFactor*maxWindow)
+}
+
+// compilePolicy prépare une politique de la configuration
+func compilePolicy(cfg *config.RateLimitPolicy) *policy {
+	p := &policy{
+		name:    cfg.Name,
+		methods: make(map[string]bool),
+		path:    cfg.Path,
+		window:  cfg.Window,
+		limits:  cfg.Limits,
+	}
+	for _, method := range cfg.Methods {
+		p.methods[strings.ToUpper(method)] = true
+	}
+	if strings.HasSuffix(p.path, "*") {
+		p.prefix = true
+		p.path = strings.TrimSuffix(p.path, "*")
+	}
+	return p
+}
+
+// Start démarre le nettoyage des compteurs locaux
+func (l *Limiter) Start() {
+	go func() {
+		ticker := time.NewTicker(l.config.CleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ticker.C:
+				l.local.Cleanup()
+			}
+		}
+	}()
+}
+
+// Close arrête le nettoyage des compteurs
+func (l *Limiter) Close() {
+	close(l.stop)
+}
+
+// Allow compte une requête et décide si elle peut passer
+// route est le motif de route Gin (ou le chemin si la route est inconnue).
+func (l *Limiter) Allow(ctx context.Context, method, route string, subject Subject) Decision {
+	p := l.match(method, route)
+	decision := Decision{
+		Policy: p.name,
+		Tier:   subject.Tier,
+		Window: p.window,
+		Limit:  p.limit(subject.Tier),
+	}
+
+	if multiplier, exists := l.overrides[subject.UserID]; exists {
+		if multiplier == 0 {
+			decision.Unlimited = true
+			decision.Allowed = true
+			return decision
+		}
+		decision.Limit = int(float64(decision.Limit) * multiplier)
+		if decision.Limit < 1 {
+			decision.Limit = 1
+		}
+	}
+
+	// Fenêtres fixes alignées : identiques sur tous les réplicas
+	now := time.Now()
+	windowStart := now.Truncate(p.window)
+	decision.Reset = windowStart.Add(p.window).Sub(now)
+	key := counterKey(p.name, subject.key(), windowStart)
+
+	ctx, cancel := context.WithTimeout(ctx, l.config.StoreTimeout)
+	defer cancel()
+
+	count, allowed, err := l.store.Take(ctx, key, decision.Limit, decision.Reset)
+	if err != nil && l.store != Store(l.local) {
+		// Store partagé indisponible : ne pas bloquer le trafic, compter localement
+		storeErrors.Inc()
+		logrus.WithError(err).Debug("Rate limit store error, falling back to local counters")
+		count, allowed, err = l.local.Take(ctx, key, decision.Limit, decision.Reset)
+	}
+	if err != nil {
+		storeErrors.Inc()
+		decision.Allowed = true
+		decision.Remaining = decision.Limit
+		return decision
+	}
+
+	decision.Allowed = allowed
+	decision.Remaining = decision.Limit - count
+	if decision.Remaining < 0 {
+		decision.Remaining = 0
+	}
+	return decision
+}
+
+// match retourne la première politique qui s'applique à la route
+func (l *Limiter) match(method, route string) *policy {
+	for _, p := range l.policies {
+		if p.matches(method, route) {
+			return p
+		}
+	}
+	return l.policies[len(l.policies)-1]
+}
+
+// counterKey construit la clé d'un compteur (caractères acceptés par NATS KV)
+func counterKey(policyName, subjectKey string, windowStart time.Time) string {
+	key := policyName + "." + subjectKey + "." + strconv.FormatInt(windowStart.Unix(), 10)
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
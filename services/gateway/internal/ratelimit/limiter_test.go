@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"gateway/internal/config"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+func newTestLimiter() *Limiter {
+	return NewLimiter(&config.RateLimitConfig{
+		RequestsPerMinute: 3,
+		CleanupInterval:   time.Minute,
+		Store:             config.RateLimitStoreMemory,
+		StoreTimeout:      time.Second,
+		Policies: []config.RateLimitPolicy{
+			{
+				Name:    "chat-send",
+				Methods: []string{"post"},
+				Path:    "/api/v1/chat/messages",
+				Window:  time.Minute,
+				Limits:  map[string]int{config.TierPlayer: 2, config.TierAdmin: 5},
+			},
+			{
+				Name:   "profile-read",
+				Path:   "/api/v1/player/*",
+				Window: time.Minute,
+				Limits: map[string]int{config.TierPlayer: 4},
+			},
+		},
+		Overrides: []config.RateLimitOverride{
+			{UserID: "load-test", Multiplier: 0},
+			{UserID: "partner", Multiplier: 2},
+		},
+	}, nil)
+}
+
+// allowN envoie n requêtes et retourne la dernière décision
+func allowN(l *Limiter, n int, method, route string, subject Subject) Decision {
+	var decision Decision
+	for range n {
+		decision = l.Allow(context.Background(), method, route, subject)
+	}
+	return decision
+}
+
+func TestLimiterPolicies(t *testing.T) {
+	l := newTestLimiter()
+	player := Subject{UserID: "u1", Tier: config.TierPlayer}
+
+	tests := []struct {
+		method, route, policy string
+		limit                 int
+	}{
+		{"POST", "/api/v1/chat/messages", "chat-send", 2},
+		{"GET", "/api/v1/chat/messages", defaultPolicyName, 3},
+		{"GET", "/api/v1/player/:id", "profile-read", 4},
+		{"GET", "/api/v1/world/zones", defaultPolicyName, 3},
+	}
+	for _, tt := range tests {
+		decision := l.Allow(context.Background(), tt.method, tt.route, player)
+		if decision.Policy != tt.policy || decision.Limit != tt.limit {
+			t.Errorf("%s %s: policy %s limit %d, want %s limit %d",
+				tt.method, tt.route, decision.Policy, decision.Limit, tt.policy, tt.limit)
+		}
+	}
+
+	decision := allowN(l, 2, "POST", "/api/v1/chat/messages", player)
+	if decision.Allowed || decision.Remaining != 0 {
+		t.Errorf("third chat message = %+v, want refused", decision)
+	}
+	if decision.Reset <= 0 || decision.Reset > time.Minute {
+		t.Errorf("reset = %v, want within the window", decision.Reset)
+	}
+
+	// Tier admin : limite dédiée ; tier sans limite déclarée : limite des joueurs
+	admin := Subject{UserID: "a1", Tier: config.TierAdmin}
+	if decision := allowN(l, 5, "POST", "/api/v1/chat/messages", admin); !decision.Allowed {
+		t.Errorf("admin refused within its limit: %+v", decision)
+	}
+	moderator := Subject{UserID: "m1", Tier: config.TierModerator}
+	if decision := l.Allow(context.Background(), "POST", "/api/v1/chat/messages", moderator); decision.Limit != 2 {
+		t.Errorf("moderator limit = %d, want the player limit", decision.Limit)
+	}
+}
+
+// TestLimiterSubjects utilisateurs comptés par ID, anonymes par adresse IP
+func TestLimiterSubjects(t *testing.T) {
+	l := newTestLimiter()
+
+	allowN(l, 3, "GET", "/api/v1/world/zones", Subject{UserID: "u1", IP: "10.0.0.1"})
+	if decision := l.Allow(context.Background(), "GET", "/api/v1/world/zones", Subject{UserID: "u2", IP: "10.0.0.1"}); !decision.Allowed {
+		t.Error("user limited by another user on the same IP")
+	}
+	if decision := l.Allow(context.Background(), "GET", "/api/v1/world/zones", Subject{IP: "10.0.0.1"}); !decision.Allowed {
+		t.Error("anonymous client limited by a user on the same IP")
+	}
+	if decision := l.Allow(context.Background(), "GET", "/api/v1/world/zones", Subject{UserID: "u1", IP: "10.0.0.2"}); decision.Allowed {
+		t.Error("user not limited after changing IP")
+	}
+}
+
+func TestLimiterOverrides(t *testing.T) {
+	l := newTestLimiter()
+
+	if decision := allowN(l, 10, "GET", "/api/v1/world/zones", Subject{UserID: "load-test"}); !decision.Unlimited || !decision.Allowed {
+		t.Errorf("unlimited override = %+v", decision)
+	}
+	if decision := l.Allow(context.Background(), "GET", "/api/v1/world/zones", Subject{UserID: "partner"}); decision.Limit != 6 {
+		t.Errorf("partner limit = %d, want 6", decision.Limit)
+	}
+}
+
+// failingStore store partagé indisponible
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, int, time.Duration) (int, bool, error) {
+	return 0, false, nats.ErrTimeout
+}
+
+// TestLimiterFallback une erreur du store partagé bascule sur les compteurs locaux
+func TestLimiterFallback(t *testing.T) {
+	l := newTestLimiter()
+	l.store = failingStore{}
+	subject := Subject{UserID: "u1", Tier: config.TierPlayer}
+
+	decision := allowN(l, 4, "GET", "/api/v1/world/zones", subject)
+	if decision.Allowed {
+		t.Error("limit not enforced by local fallback")
+	}
+	if len(l.local.counters) != 1 {
+		t.Errorf("local counters = %d, want 1", len(l.local.counters))
+	}
+}
+
+func TestCounterKey(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	if key := counterKey("chat-send", "ip.2001:db8::1", start); key != "chat-send.ip.2001_db8__1.1700000000" {
+		t.Errorf("counterKey = %q", key)
+	}
+}
@@ -0,0 +1,16 @@
+package ratelimit
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Métriques Prometheus du rate limiting
+var storeErrors = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "gateway_rate_limit_store_errors_total",
+		Help: "Total number of rate limit store errors (requests counted locally instead)",
+	},
+)
+
+// InitMetrics initialize les métriques Prometheus du rate limiting
+func InitMetrics() {
+	prometheus.MustRegister(storeErrors)
+}
@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// maxCASAttempts tentatives d'écriture concurrente d'un compteur partagé
+const maxCASAttempts = 5
+
+// ErrStoreContention le compteur partagé est modifié trop souvent en parallèle
+var ErrStoreContention = errors.New("rate limit counter contention")
+
+// Store compteurs de requêtes par fenêtre
+type Store interface {
+	// Take compte une requête pour key si le compteur est sous limit et retourne
+	// la valeur du compteur (expiresIn : durée de vie restante de la fenêtre)
+	Take(ctx context.Context, key string, limit int, expiresIn time.Duration) (count int, allowed bool, err error)
+}
+
+// memoryCounter compteur d'une fenêtre en mémoire
+type memoryCounter struct {
+	count   int
+	expires time.Time
+}
+
+// MemoryStore compteurs locaux au réplica
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]*memoryCounter
+}
+
+// NewMemoryStore crée un store en mémoire
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]*memoryCounter)}
+}
+
+// Take implémente Store
+func (s *MemoryStore) Take(_ context.Context, key string, limit int, expiresIn time.Duration) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	counter, exists := s.counters[key]
+	if !exists || now.After(counter.expires) {
+		counter = &memoryCounter{expires: now.Add(expiresIn)}
+		s.counters[key] = counter
+	}
+
+	if counter.count >= limit {
+		return counter.count, false, nil
+	}
+	counter.count++
+	return counter.count, true, nil
+}
+
+// Cleanup supprime les compteurs des fenêtres terminées
+func (s *MemoryStore) Cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, counter := range s.counters {
+		if now.After(counter.expires) {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// NATSStore compteurs partagés entre réplicas dans un bucket JetStream KV
+// Les incréments sont faits par compare-and-swap sur la révision de la clé ; le TTL
+// du bucket fait expirer les compteurs des fenêtres terminées.
+type NATSStore struct {
+	kv jetstream.KeyValue
+}
+
+// NewNATSStore ouvre (ou crée) le bucket des compteurs
+func NewNATSStore(ctx context.Context, nc *nats.Conn, bucket string, ttl time.Duration) (*NATSStore, error) {
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      bucket,
+		Description: "Gateway rate limit counters",
+		History:     1,
+		TTL:         ttl,
+		Storage:     jetstream.MemoryStorage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rate limit bucket %s: %w", bucket, err)
+	}
+
+	return &NATSStore{kv: kv}, nil
+}
+
+// Take implémente Store
+func (s *NATSStore) Take(ctx context.Context, key string, limit int, _ time.Duration) (int, bool, error) {
+	for attempt := 0; attempt < maxCASAttempts; attempt++ {
+		entry, err := s.kv.Get(ctx, key)
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			if _, err := s.kv.Create(ctx, key, []byte("1")); err == nil {
+				return 1, true, nil
+			} else if !errors.Is(err, jetstream.ErrKeyExists) {
+				return 0, false, err
+			}
+			// Créée entre-temps par un autre réplica
+			continue
+		}
+		if err != nil {
+			return 0, false, err
+		}
+
+		count, err := strconv.Atoi(string(entry.Value()))
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid rate limit counter %s: %w", key, err)
+		}
+		if count >= limit {
+			return count, false, nil
+		}
+
+		_, err = s.kv.Update(ctx, key, []byte(strconv.Itoa(count+1)), entry.Revision())
+		if err == nil {
+			return count + 1, true, nil
+		}
+		if !errors.Is(err, jetstream.ErrKeyExists) {
+			return 0, false, err
+		}
+	}
+
+	return 0, false, ErrStoreContention
+}
@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// fakeEntry valeur d'une clé du bucket
+type fakeEntry struct {
+	jetstream.KeyValueEntry
+
+	value    []byte
+	revision uint64
+}
+
+func (e *fakeEntry) Value() []byte    { return e.value }
+func (e *fakeEntry) Revision() uint64 { return e.revision }
+
+// fakeKV bucket JetStream KV en mémoire avec révisions
+// conflicts écritures concurrentes simulées avant chaque Update
+type fakeKV struct {
+	jetstream.KeyValue
+
+	mu        sync.Mutex
+	entries   map[string]*fakeEntry
+	revision  uint64
+	conflicts int
+	err       error
+}
+
+func newFakeKV() *fakeKV {
+	return &fakeKV{entries: make(map[string]*fakeEntry)}
+}
+
+func (kv *fakeKV) Get(_ context.Context, key string) (jetstream.KeyValueEntry, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	if kv.err != nil {
+		return nil, kv.err
+	}
+	entry, exists := kv.entries[key]
+	if !exists {
+		return nil, jetstream.ErrKeyNotFound
+	}
+	copied := *entry
+	return &copied, nil
+}
+
+func (kv *fakeKV) Create(_ context.Context, key string, value []byte, _ ...jetstream.KVCreateOpt) (uint64, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	if _, exists := kv.entries[key]; exists {
+		return 0, jetstream.ErrKeyExists
+	}
+	kv.revision++
+	kv.entries[key] = &fakeEntry{value: value, revision: kv.revision}
+	return kv.revision, nil
+}
+
+func (kv *fakeKV) Update(_ context.Context, key string, value []byte, last uint64) (uint64, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	entry := kv.entries[key]
+	if kv.conflicts > 0 {
+		// Un autre réplica incrémente le compteur entre Get et Update
+		kv.conflicts--
+		count, _ := strconv.Atoi(string(entry.value))
+		kv.revision++
+		kv.entries[key] = &fakeEntry{value: []byte(strconv.Itoa(count + 1)), revision: kv.revision}
+		return 0, jetstream.ErrKeyExists
+	}
+	if entry.revision != last {
+		return 0, jetstream.ErrKeyExists
+	}
+	kv.revision++
+	kv.entries[key] = &fakeEntry{value: value, revision: kv.revision}
+	return kv.revision, nil
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		count, allowed, err := store.Take(ctx, "k", 3, time.Minute)
+		if err != nil || !allowed || count != i {
+			t.Fatalf("Take %d = %d, %v, %v", i, count, allowed, err)
+		}
+	}
+	if count, allowed, _ := store.Take(ctx, "k", 3, time.Minute); allowed || count != 3 {
+		t.Fatalf("Take over limit = %d, %v; want 3, refused", count, allowed)
+	}
+	if _, allowed, _ := store.Take(ctx, "other", 3, time.Minute); !allowed {
+		t.Error("counters shared between keys")
+	}
+
+	// Fenêtre terminée : le compteur repart de zéro, Cleanup le supprime
+	if _, allowed, _ := store.Take(ctx, "short", 1, -time.Second); !allowed {
+		t.Fatal("first request refused")
+	}
+	if count, allowed, _ := store.Take(ctx, "short", 1, time.Minute); !allowed || count != 1 {
+		t.Errorf("Take after window = %d, %v; want a new window", count, allowed)
+	}
+	store.counters["expired"] = &memoryCounter{count: 1, expires: time.Now().Add(-time.Second)}
+	store.Cleanup()
+	if _, exists := store.counters["expired"]; exists {
+		t.Error("expired counter not cleaned up")
+	}
+	if _, exists := store.counters["k"]; !exists {
+		t.Error("current counter cleaned up")
+	}
+}
+
+func TestNATSStore(t *testing.T) {
+	kv := newFakeKV()
+	store := &NATSStore{kv: kv}
+	ctx := context.Background()
+
+	for i := 1; i <= 2; i++ {
+		count, allowed, err := store.Take(ctx, "k", 2, time.Minute)
+		if err != nil || !allowed || count != i {
+			t.Fatalf("Take %d = %d, %v, %v", i, count, allowed, err)
+		}
+	}
+	if count, allowed, err := store.Take(ctx, "k", 2, time.Minute); err != nil || allowed || count != 2 {
+		t.Fatalf("Take over limit = %d, %v, %v; want 2, refused", count, allowed, err)
+	}
+}
+
+// TestNATSStoreContention les incréments concurrents des autres réplicas sont comptés
+func TestNATSStoreContention(t *testing.T) {
+	kv := newFakeKV()
+	store := &NATSStore{kv: kv}
+	ctx := context.Background()
+
+	if _, _, err := store.Take(ctx, "k", 10, time.Minute); err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+
+	kv.conflicts = 2
+	count, allowed, err := store.Take(ctx, "k", 10, time.Minute)
+	if err != nil || !allowed || count != 4 {
+		t.Fatalf("Take after conflicts = %d, %v, %v; want 4", count, allowed, err)
+	}
+
+	kv.conflicts = maxCASAttempts
+	if _, _, err := store.Take(ctx, "k", 100, time.Minute); !errors.Is(err, ErrStoreContention) {
+		t.Errorf("Take under contention = %v, want ErrStoreContention", err)
+	}
+}
+
+func TestNATSStoreInvalidCounter(t *testing.T) {
+	kv := newFakeKV()
+	kv.entries["k"] = &fakeEntry{value: []byte("x"), revision: 1}
+
+	if _, _, err := (&NATSStore{kv: kv}).Take(context.Background(), "k", 10, time.Minute); err == nil {
+		t.Error("invalid counter accepted")
+	}
+}
@@ -0,0 +1,135 @@
+package realtime
+
+import (
+	"gateway/internal/config"
+	"gateway/internal/encoding"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// client connexion WebSocket d'une session
+// Tous les messages passent par une file bornée écrite par une seule goroutine : un
+// client trop lent ne bloque jamais la diffusion, il est déconnecté et pourra reprendre
+// sa session.
+type client struct {
+	conn   *websocket.Conn
+	config *config.RealtimeConfig
+	format encoding.Format
+	send   chan []byte
+
+	done        chan struct{}
+	finished    chan struct{}
+	closeOnce   sync.Once
+	closeCode   int
+	closeReason string
+}
+
+// newClient crée le client d'une connexion dans l'encodage négocié
+func newClient(conn *websocket.Conn, cfg *config.RealtimeConfig, format encoding.Format) *client {
+	return &client{
+		conn:     conn,
+		config:   cfg,
+		format:   format,
+		send:     make(chan []byte, cfg.SendQueueSize),
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+}
+
+// enqueue ajoute un message à la file d'envoi sans jamais bloquer
+func (c *client) enqueue(payload []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+
+	select {
+	case c.send <- payload:
+		return true
+	default:
+		slowConsumers.Inc()
+		c.close(websocket.CloseTryAgainLater, "send queue full")
+		return false
+	}
+}
+
+// close demande la fermeture de la connexion avec un code WebSocket
+func (c *client) close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeReason = reason
+		close(c.done)
+	})
+}
+
+// writePump écrit la file d'envoi et les pings sur la connexion
+func (c *client) writePump() {
+	ticker := time.NewTicker(c.config.PingInterval)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+		close(c.finished)
+	}()
+
+	for {
+		select {
+		case payload := <-c.send:
+			if err := c.write(c.messageType(), payload); err != nil {
+				logrus.WithError(err).Debug("WebSocket write failed")
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+			messagesSent.Inc()
+		case <-ticker.C:
+			if err := c.write(websocket.PingMessage, nil); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-c.done:
+			if c.closeCode != websocket.CloseAbnormalClosure {
+				c.flush()
+				message := websocket.FormatCloseMessage(c.closeCode, c.closeReason)
+				if err := c.write(websocket.CloseMessage, message); err != nil {
+					logrus.WithError(err).Debug("Failed to send WebSocket close frame")
+				}
+			}
+			return
+		}
+	}
+}
+
+// flush écrit les messages encore en file avant la fermeture (ex. annonce de maintenance)
+// La première erreur d'écriture arrête l'envoi : un client lent n'attend qu'un timeout.
+func (c *client) flush() {
+	for {
+		select {
+		case payload := <-c.send:
+			if err := c.write(c.messageType(), payload); err != nil {
+				return
+			}
+			messagesSent.Inc()
+		default:
+			return
+		}
+	}
+}
+
+// messageType type des frames de données : texte en JSON, binaire sinon
+func (c *client) messageType() int {
+	if c.format == encoding.FormatJSON {
+		return websocket.TextMessage
+	}
+	return websocket.BinaryMessage
+}
+
+// write écrit un message avec le timeout d'écriture
+func (c *client) write(messageType int, payload []byte) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout)); err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(messageType, payload)
+}
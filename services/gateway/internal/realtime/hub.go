@@ -0,0 +1,524 @@
+package realtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gateway/internal/config"
+	"gateway/internal/encoding"
+	"gateway/internal/tracing"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+// Constantes du hub
+const (
+	resumeTokenBytes = 32
+	chatSubject      = "chat.message"
+)
+
+// ErrHubClosed le hub est arrêté et n'accepte plus de connexion
+var ErrHubClosed = errors.New("realtime hub closed")
+
+// Identity identité de l'utilisateur authentifié lors de l'upgrade
+type Identity struct {
+	UserID   string
+	Username string
+	Role     string
+}
+
+// clientMessage message envoyé par un client
+type clientMessage struct {
+	Type    string   `json:"type"`
+	ID      string   `json:"id,omitempty"` // repris dans la réponse
+	Topics  []string `json:"topics,omitempty"`
+	Channel string   `json:"channel,omitempty"` // join_channel (ancien protocole)
+	Content string   `json:"content,omitempty"` // chat_message
+}
+
+// Hub canal de push unique des clients de jeu
+// Chaque connexion authentifiée ouvre (ou reprend) une session abonnée à des topics ;
+// les services publient sur NATS (<prefix>.<type>.<id>) et le hub diffuse l'événement
+// aux sessions abonnées au topic <type>:<id>.
+type Hub struct {
+	config     *config.RealtimeConfig
+	nats       *nats.Conn
+	sub        *nats.Subscription
+	membership *MembershipChecker
+
+	mu       sync.RWMutex
+	sessions map[string]*session              // par resume token
+	topics   map[string]map[*session]struct{} // abonnés par topic
+	closed   bool
+
+	clients   int64
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewHub crée le hub temps réel (natsConn peut être nil : pas d'événements des services)
+// membership vérifie l'appartenance aux topics ; nil les refuse.
+func NewHub(cfg *config.RealtimeConfig, natsConn *nats.Conn, membership *MembershipChecker) *Hub {
+	return &Hub{
+		config:     cfg,
+		nats:       natsConn,
+		membership: membership,
+		sessions:   make(map[string]*session),
+		topics:     make(map[string]map[*session]struct{}),
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start s'abonne aux événements NATS et démarre le nettoyage des sessions expirées
+func (h *Hub) Start() error {
+	if h.nats == nil {
+		logrus.Warn("NATS unavailable, realtime hub will not receive service events")
+	} else {
+		sub, err := h.nats.Subscribe(h.config.SubjectPrefix+".>", h.handleEvent)
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to realtime events: %w", err)
+		}
+		h.sub = sub
+	}
+
+	go h.cleanupLoop()
+
+	logrus.WithField("subject", h.config.SubjectPrefix+".>").Info("Realtime hub started")
+	return nil
+}
+
+// Close arrête le hub et ferme toutes les connexions
+func (h *Hub) Close() {
+	h.closeOnce.Do(func() {
+		close(h.stop)
+
+		if h.sub != nil {
+			if err := h.sub.Unsubscribe(); err != nil {
+				logrus.WithError(err).Warn("Failed to unsubscribe realtime events")
+			}
+		}
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		h.closed = true
+		for _, sess := range h.sessions {
+			sess.closeClient(websocket.CloseGoingAway, "server shutdown")
+		}
+	})
+}
+
+// ClientCount retourne le nombre de connexions ouvertes
+func (h *Hub) ClientCount() int {
+	return int(atomic.LoadInt64(&h.clients))
+}
+
+// Serve gère une connexion WebSocket jusqu'à sa fermeture
+// Un resumeToken valide pour le même utilisateur reprend la session (abonnements et
+// événements postérieurs à lastSeq), sinon une nouvelle session est ouverte. Les messages
+// de la connexion sont encodés dans format (JSON en frames texte, sinon frames binaires).
+func (h *Hub) Serve(conn *websocket.Conn, identity Identity, format encoding.Format, resumeToken string, lastSeq uint64) {
+	sess, resumed, err := h.openSession(identity, resumeToken)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to open realtime session")
+		message := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "session unavailable")
+		if err := conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(h.config.WriteTimeout)); err != nil {
+			logrus.WithError(err).Debug("Failed to send WebSocket close frame")
+		}
+		conn.Close()
+		return
+	}
+
+	c := newClient(conn, h.config, format)
+	connections.WithLabelValues(string(format)).Inc()
+	if previous := sess.attach(c, resumed, lastSeq, h.config.PingInterval); previous != nil {
+		previous.close(websocket.CloseNormalClosure, "session resumed on another connection")
+	}
+
+	clientCount := atomic.AddInt64(&h.clients, 1)
+	connectedClients.Inc()
+
+	fields := logrus.Fields{
+		"session_id": sess.id,
+		"user_id":    identity.UserID,
+		"resumed":    resumed,
+		"encoding":   format,
+	}
+	logrus.WithFields(fields).WithField("client_count", clientCount).Info("WebSocket client connected")
+
+	go c.writePump()
+	h.readPump(sess, c)
+
+	c.close(websocket.CloseNormalClosure, "")
+	<-c.finished
+	sess.detach(c)
+
+	clientCount = atomic.AddInt64(&h.clients, -1)
+	connectedClients.Dec()
+	logrus.WithFields(fields).WithField("client_count", clientCount).Info("WebSocket client disconnected")
+}
+
+// Broadcast envoie un message hors séquence à tous les clients connectés (annonces)
+// Le message n'est pas rejoué aux clients qui se reconnectent.
+func (h *Hub) Broadcast(message interface{}) {
+	// Message encodé une seule fois par encodage utilisé
+	payloads := make(map[encoding.Format][]byte, len(encoding.Formats))
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, sess := range h.sessions {
+		sess.mu.Lock()
+		if sess.client != nil {
+			payload, encoded := payloads[sess.client.format]
+			if !encoded {
+				var err error
+				if payload, err = encodeMessage(sess.client.format, message); err != nil {
+					logrus.WithError(err).WithField("encoding", sess.client.format).Error("Failed to encode broadcast message")
+				}
+				payloads[sess.client.format] = payload
+			}
+			if payload != nil {
+				sess.client.enqueue(payload)
+			}
+		}
+		sess.mu.Unlock()
+	}
+}
+
+// Disconnect ferme les connexions dont l'utilisateur n'est pas retenu par keep
+// Les clients sont invités à se reconnecter plus tard (close 1013) ; leurs sessions
+// restent disponibles pour la reprise. Retourne le nombre de connexions fermées.
+func (h *Hub) Disconnect(keep func(Identity) bool, reason string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	closed := 0
+	for _, sess := range h.sessions {
+		if keep(sess.identity) {
+			continue
+		}
+		if sess.closeClient(websocket.CloseTryAgainLater, reason) {
+			closed++
+		}
+	}
+	return closed
+}
+
+// openSession reprend la session d'un resume token ou en crée une nouvelle
+// Le resume token change à chaque connexion : un token déjà utilisé ne sert plus.
+func (h *Hub) openSession(identity Identity, resumeToken string) (*session, bool, error) {
+	token, err := newResumeToken()
+	if err != nil {
+		return nil, false, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, false, ErrHubClosed
+	}
+
+	if resumeToken != "" {
+		sess, exists := h.sessions[resumeToken]
+		if exists && sess.identity.UserID == identity.UserID && !sess.expired(time.Now(), h.config.ResumeWindow) {
+			delete(h.sessions, resumeToken)
+			sess.mu.Lock()
+			sess.token = token
+			sess.mu.Unlock()
+			h.sessions[token] = sess
+			resumes.WithLabelValues("resumed").Inc()
+			return sess, true, nil
+		}
+		resumes.WithLabelValues("rejected").Inc()
+	}
+
+	sess := newSession(uuid.New().String(), identity, h.config.ResumeBufferSize)
+	sess.token = token
+	h.sessions[token] = sess
+
+	// Topic personnel : notifications destinées au seul utilisateur
+	personal := userTopic(identity.UserID)
+	sess.topics[personal] = struct{}{}
+	h.addSubscriber(personal, sess)
+
+	return sess, false, nil
+}
+
+// readPump lit les messages du client et surveille les heartbeats
+func (h *Hub) readPump(sess *session, c *client) {
+	conn := c.conn
+	conn.SetReadLimit(h.config.MaxMessageSize)
+
+	extendDeadline := func() error {
+		return conn.SetReadDeadline(time.Now().Add(h.config.PongTimeout))
+	}
+	if err := extendDeadline(); err != nil {
+		return
+	}
+	conn.SetPongHandler(func(string) error {
+		return extendDeadline()
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logrus.WithError(err).WithField("session_id", sess.id).Debug("WebSocket read error")
+			}
+			return
+		}
+		if err := extendDeadline(); err != nil {
+			return
+		}
+
+		h.handleMessage(sess, c.format, data)
+	}
+}
+
+// handleMessage traite un message du client, dans l'encodage de sa connexion
+func (h *Hub) handleMessage(sess *session, format encoding.Format, data []byte) {
+	message, err := decodeClientMessage(format, data)
+	if err != nil || message.Type == "" {
+		sess.send(errorMessage("", "Message type required"))
+		return
+	}
+
+	switch message.Type {
+	case "ping":
+		sess.send(map[string]interface{}{
+			"type": "pong",
+			"id":   message.ID,
+			"time": time.Now().Unix(),
+		})
+	case "subscribe":
+		h.handleSubscribe(sess, message)
+	case "unsubscribe":
+		h.handleUnsubscribe(sess, message)
+	case "join_channel":
+		// Ancien protocole : équivalent à un abonnement au topic chat:<channel>
+		message.Topics = []string{TopicChat + ":" + message.Channel}
+		h.handleSubscribe(sess, message)
+	case "chat_message":
+		h.publishChatMessage(sess, format, message, data)
+	default:
+		sess.send(errorMessage(message.ID, "Unknown message type"))
+	}
+}
+
+// handleSubscribe abonne la session aux topics demandés
+func (h *Hub) handleSubscribe(sess *session, message *clientMessage) {
+	subscribed := make([]string, 0, len(message.Topics))
+	failures := make(map[string]string)
+
+	for _, topic := range message.Topics {
+		if err := authorizeTopic(context.Background(), topic, sess.identity, h.membership); err != nil {
+			failures[topic] = err.Error()
+			continue
+		}
+		if err := h.subscribe(sess, topic); err != nil {
+			failures[topic] = err.Error()
+			continue
+		}
+		subscribed = append(subscribed, topic)
+	}
+
+	response := map[string]interface{}{
+		"type":   "subscribed",
+		"id":     message.ID,
+		"topics": subscribed,
+	}
+	if len(failures) > 0 {
+		response["errors"] = failures
+	}
+	sess.send(response)
+}
+
+// handleUnsubscribe désabonne la session des topics demandés
+func (h *Hub) handleUnsubscribe(sess *session, message *clientMessage) {
+	h.mu.Lock()
+	sess.mu.Lock()
+	for _, topic := range message.Topics {
+		if _, exists := sess.topics[topic]; exists {
+			delete(sess.topics, topic)
+			h.removeSubscriber(topic, sess)
+		}
+	}
+	sess.mu.Unlock()
+	h.mu.Unlock()
+
+	sess.send(map[string]interface{}{
+		"type":   "unsubscribed",
+		"id":     message.ID,
+		"topics": message.Topics,
+	})
+}
+
+// subscribe ajoute un topic à la session (verrou du hub puis de la session)
+func (h *Hub) subscribe(sess *session, topic string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if _, exists := sess.topics[topic]; exists {
+		return nil
+	}
+	if len(sess.topics) >= h.config.MaxSubscriptions {
+		return ErrTooManySubscriptions
+	}
+
+	sess.topics[topic] = struct{}{}
+	h.addSubscriber(topic, sess)
+	return nil
+}
+
+// publishChatMessage relaie un message de chat vers le service de chat via NATS
+// L'auteur est toujours l'utilisateur authentifié de la session.
+func (h *Hub) publishChatMessage(sess *session, format encoding.Format, message *clientMessage, data []byte) {
+	if h.nats == nil {
+		sess.send(errorMessage(message.ID, "Chat unavailable"))
+		return
+	}
+
+	chatMessage, err := decodeChatMessage(format, message, data)
+	if err != nil {
+		sess.send(errorMessage(message.ID, "Invalid chat message"))
+		return
+	}
+	chatMessage["user_id"] = sess.identity.UserID
+	chatMessage["username"] = sess.identity.Username
+	chatMessage["session_id"] = sess.id
+
+	payload, err := json.Marshal(chatMessage)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal chat message")
+		return
+	}
+	if err = tracing.Publish(context.Background(), h.nats, chatSubject, payload); err != nil {
+		logrus.WithError(err).Error("Failed to publish chat message")
+		sess.send(errorMessage(message.ID, "Chat unavailable"))
+	}
+}
+
+// handleEvent diffuse un événement NATS aux sessions abonnées à son topic
+func (h *Hub) handleEvent(msg *nats.Msg) {
+	_, span := tracing.StartConsumerSpan(msg)
+	defer span.End()
+
+	topic, ok := topicFromSubject(h.config.SubjectPrefix, msg.Subject)
+	if !ok {
+		logrus.WithField("subject", msg.Subject).Debug("Ignoring realtime event with invalid subject")
+		return
+	}
+
+	h.mu.RLock()
+	subscribers := make([]*session, 0, len(h.topics[topic]))
+	for sess := range h.topics[topic] {
+		subscribers = append(subscribers, sess)
+	}
+	h.mu.RUnlock()
+
+	if len(subscribers) == 0 {
+		return
+	}
+
+	e, err := newEvent(topic, msg.Data)
+	if err != nil {
+		logrus.WithError(err).WithField("topic", topic).Warn("Failed to encode realtime event")
+		return
+	}
+
+	kind, _, _ := parseTopic(topic)
+	eventsReceived.WithLabelValues(kind).Inc()
+
+	for _, sess := range subscribers {
+		sess.deliver(e)
+	}
+}
+
+// cleanupLoop supprime les sessions détachées au-delà de la fenêtre de reprise
+func (h *Hub) cleanupLoop() {
+	ticker := time.NewTicker(h.config.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			h.removeExpiredSessions()
+			if h.membership != nil {
+				h.membership.Cleanup()
+			}
+		}
+	}
+}
+
+// removeExpiredSessions supprime les sessions expirées et leurs abonnements
+func (h *Hub) removeExpiredSessions() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for token, sess := range h.sessions {
+		if !sess.expired(now, h.config.ResumeWindow) {
+			continue
+		}
+
+		sess.mu.Lock()
+		for topic := range sess.topics {
+			h.removeSubscriber(topic, sess)
+		}
+		sess.mu.Unlock()
+
+		delete(h.sessions, token)
+	}
+}
+
+// addSubscriber indexe une session sur un topic (verrou du hub requis)
+func (h *Hub) addSubscriber(topic string, sess *session) {
+	subscribers, exists := h.topics[topic]
+	if !exists {
+		subscribers = make(map[*session]struct{})
+		h.topics[topic] = subscribers
+	}
+	subscribers[sess] = struct{}{}
+}
+
+// removeSubscriber retire une session d'un topic (verrou du hub requis)
+func (h *Hub) removeSubscriber(topic string, sess *session) {
+	subscribers := h.topics[topic]
+	delete(subscribers, sess)
+	if len(subscribers) == 0 {
+		delete(h.topics, topic)
+	}
+}
+
+// errorMessage construit un message d'erreur pour le client
+func errorMessage(id, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":  "error",
+		"id":    id,
+		"error": message,
+	}
+}
+
+// newResumeToken génère un resume token aléatoire
+func newResumeToken() (string, error) {
+	token := make([]byte, resumeTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate resume token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
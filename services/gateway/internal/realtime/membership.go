@@ -0,0 +1,236 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gateway/internal/config"
+	"gateway/internal/tracing"
+	"mmorpg/pkg/serviceauth"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Routes internes des services donnant l'appartenance d'un utilisateur au groupe d'un topic
+// (token de service du gateway). Les routes de vérification répondent 200 si membre, 404 sinon.
+const (
+	playerSummaryPath = "/api/v1/services/player/%s"              // player_id de l'utilisateur, scope player.read
+	guildMemberPath   = "/api/v1/services/guild-members/%s/%s"    // guilde et player_id, scope guild.read
+	channelMemberPath = "/api/v1/services/channels/%s/members/%s" // scope chat.read
+	partyMemberPath   = "/api/v1/services/parties/%s/members/%s"  // channel de la party, scope chat.read
+	zonePresencePath  = "/api/v1/services/zones/%s/users/%s"      // personnage en ligne dans la zone, scope world.read
+	combatStatusPath  = "/api/v1/services/combat/%s/status"       // participants, scope combat.read
+)
+
+// ErrMembershipUnavailable l'appartenance ne peut pas être vérifiée (service injoignable)
+var ErrMembershipUnavailable = errors.New("topic membership unavailable")
+
+// combatStatusResponse réponse du service combat
+type combatStatusResponse struct {
+	UserIDs []string `json:"user_ids"`
+}
+
+// playerSummaryResponse réponse du service player
+type playerSummaryResponse struct {
+	PlayerSummary struct {
+		PlayerID string `json:"player_id"`
+	} `json:"player_summary"`
+}
+
+// membershipResult réponse gardée en cache
+type membershipResult struct {
+	member    bool
+	expiresAt time.Time
+}
+
+// MembershipChecker demande aux services chat, world, guild et combat si un utilisateur
+// appartient au groupe d'un topic et garde les réponses quelques secondes
+type MembershipChecker struct {
+	playerURL string
+	guildURL  string
+	chatURL   string
+	worldURL  string
+	combatURL string
+	ttl       time.Duration
+	tokens    *serviceauth.TokenSource
+	client    *http.Client
+
+	mu      sync.Mutex
+	results map[string]membershipResult // topic et utilisateur -> réponse
+}
+
+// NewMembershipChecker crée le client d'appartenance aux topics
+func NewMembershipChecker(cfg *config.RealtimeConfig, services *config.ServicesConfig, tokens *serviceauth.TokenSource) *MembershipChecker {
+	return &MembershipChecker{
+		playerURL: strings.TrimRight(services.Player.URL, "/"),
+		guildURL:  strings.TrimRight(services.Guild.URL, "/"),
+		chatURL:   strings.TrimRight(services.Chat.URL, "/"),
+		worldURL:  strings.TrimRight(services.World.URL, "/"),
+		combatURL: strings.TrimRight(services.Combat.URL, "/"),
+		ttl:       cfg.MembershipTTL,
+		tokens:    tokens,
+		client:    &http.Client{Timeout: cfg.MembershipTimeout},
+		results:   make(map[string]membershipResult),
+	}
+}
+
+// IsMember indique si l'utilisateur appartient au channel, à la party, à la zone, à la guilde
+// ou au combat du topic. Les autres types de topics n'ont pas de membres : la réponse est
+// toujours négative.
+func (m *MembershipChecker) IsMember(ctx context.Context, kind, id, userID string) (bool, error) {
+	key := kind + ":" + id + "/" + userID
+
+	m.mu.Lock()
+	result, exists := m.results[key]
+	m.mu.Unlock()
+	if exists && time.Now().Before(result.expiresAt) {
+		return result.member, nil
+	}
+
+	var member bool
+	var err error
+	switch kind {
+	case TopicChat:
+		member, err = m.check(ctx, "chat", fmt.Sprintf(m.chatURL+channelMemberPath, id, userID))
+	case TopicParty:
+		member, err = m.check(ctx, "chat", fmt.Sprintf(m.chatURL+partyMemberPath, id, userID))
+	case TopicZone:
+		member, err = m.check(ctx, "world", fmt.Sprintf(m.worldURL+zonePresencePath, id, userID))
+	case TopicGuild:
+		member, err = m.guildMember(ctx, id, userID)
+	case TopicCombat:
+		member, err = m.combatParticipant(ctx, id, userID)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	m.results[key] = membershipResult{member: member, expiresAt: time.Now().Add(m.ttl)}
+	m.mu.Unlock()
+
+	return member, nil
+}
+
+// Cleanup oublie les réponses expirées
+func (m *MembershipChecker) Cleanup() {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, result := range m.results {
+		if now.After(result.expiresAt) {
+			delete(m.results, key)
+		}
+	}
+}
+
+// guildMember demande au service guild si le joueur de l'utilisateur est membre de la guilde
+// Le service guild connaît les joueurs par player_id : il est demandé au service player.
+func (m *MembershipChecker) guildMember(ctx context.Context, guildID, userID string) (bool, error) {
+	playerID, err := m.playerID(ctx, userID)
+	if err != nil || playerID == "" {
+		return false, err
+	}
+	return m.check(ctx, "guild", fmt.Sprintf(m.guildURL+guildMemberPath, guildID, playerID))
+}
+
+// playerID retourne le player_id d'un utilisateur ("" s'il n'a pas de profil joueur)
+func (m *MembershipChecker) playerID(ctx context.Context, userID string) (string, error) {
+	resp, err := m.get(ctx, "player", fmt.Sprintf(m.playerURL+playerSummaryPath, userID))
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	found, err := m.found(resp, "player")
+	if err != nil || !found {
+		return "", err
+	}
+
+	var summary playerSummaryResponse
+	if err = json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return "", fmt.Errorf("failed to decode player summary: %w", err)
+	}
+	return summary.PlayerSummary.PlayerID, nil
+}
+
+// check appelle une route de vérification : 200 si membre, 404 sinon
+func (m *MembershipChecker) check(ctx context.Context, service, url string) (bool, error) {
+	resp, err := m.get(ctx, service, url)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return m.found(resp, service)
+}
+
+// combatParticipant demande au service combat si l'utilisateur participe au combat
+func (m *MembershipChecker) combatParticipant(ctx context.Context, combatID, userID string) (bool, error) {
+	resp, err := m.get(ctx, "combat", fmt.Sprintf(m.combatURL+combatStatusPath, combatID))
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	found, err := m.found(resp, "combat")
+	if err != nil || !found {
+		return false, err
+	}
+
+	var status combatStatusResponse
+	if err = json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return false, fmt.Errorf("failed to decode combat status: %w", err)
+	}
+	for _, participant := range status.UserIDs {
+		if participant == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// found interprète le statut d'une réponse : 200 trouvé, 404 ou 400 absent, sinon erreur
+func (m *MembershipChecker) found(resp *http.Response, service string) (bool, error) {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound, http.StatusBadRequest:
+		return false, nil
+	case http.StatusUnauthorized:
+		// Token de service refusé (secret renouvelé) : un nouveau sera demandé au prochain appel
+		m.tokens.Invalidate()
+		return false, fmt.Errorf("%s service rejected the service token", service)
+	default:
+		return false, fmt.Errorf("%s service returned status %d for membership", service, resp.StatusCode)
+	}
+}
+
+// get appelle un service avec le token de service du gateway
+func (m *MembershipChecker) get(ctx context.Context, service, url string) (*http.Response, error) {
+	serviceToken, err := m.tokens.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s membership request: %w", service, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+serviceToken)
+
+	ctx, span := tracing.StartClientSpan(ctx, service, req)
+	resp, err := m.client.Do(req.WithContext(ctx))
+	tracing.EndClientSpan(span, resp, err)
+	if err != nil {
+		return nil, fmt.Errorf("%s membership request failed: %w", service, err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,61 @@
+package realtime
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Métriques Prometheus du hub WebSocket
+var (
+	connectedClients = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gateway_ws_connections",
+			Help: "Number of connected WebSocket clients",
+		},
+	)
+
+	connections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_ws_connections_total",
+			Help: "Total number of WebSocket connections by negotiated encoding",
+		},
+		[]string{"encoding"},
+	)
+
+	eventsReceived = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_ws_events_total",
+			Help: "Total number of NATS events fanned out to WebSocket subscribers",
+		},
+		[]string{"topic_type"},
+	)
+
+	messagesSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "gateway_ws_messages_sent_total",
+			Help: "Total number of messages written to WebSocket clients",
+		},
+	)
+
+	slowConsumers = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "gateway_ws_slow_consumers_total",
+			Help: "Total number of clients disconnected because their send queue was full",
+		},
+	)
+
+	resumes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_ws_resumes_total",
+			Help: "Total number of session resume attempts by result",
+		},
+		[]string{"result"},
+	)
+)
+
+// InitMetrics initialize les métriques Prometheus du hub
+func InitMetrics() {
+	prometheus.MustRegister(connectedClients)
+	prometheus.MustRegister(connections)
+	prometheus.MustRegister(eventsReceived)
+	prometheus.MustRegister(messagesSent)
+	prometheus.MustRegister(slowConsumers)
+	prometheus.MustRegister(resumes)
+}
@@ -0,0 +1,253 @@
+package realtime
+
+import (
+	"encoding/json"
+	"gateway/internal/encoding"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Encodage des numéros de séquence
+const (
+	maxSeqDigits       = 20 // chiffres d'un uint64
+	decimalBase        = 10
+	maxMsgPackUintSize = 9  // uint64 MessagePack
+	maxProtoSeqSize    = 11 // tag et varint d'un uint64
+)
+
+// event événement d'un topic, encodé une seule fois par encodage pour tous les abonnés
+// Seul le numéro de séquence, propre à chaque session, est ajouté à l'envoi. Les débuts
+// MessagePack et protobuf ne sont calculés qu'au premier abonné qui les utilise.
+type event struct {
+	topic  string
+	data   []byte // document JSON
+	prefix []byte // {"type":"event","topic":...,"data":...,"seq":
+
+	msgpackOnce   sync.Once
+	msgpackPrefix []byte // nil si les données ne s'encodent pas en MessagePack
+	protoOnce     sync.Once
+	protoPrefix   []byte
+}
+
+// newEvent prépare un événement à diffuser
+func newEvent(topic string, data []byte) (*event, error) {
+	if !json.Valid(data) {
+		// Contenu non JSON : transmis comme chaîne
+		quoted, err := json.Marshal(string(data))
+		if err != nil {
+			return nil, err
+		}
+		data = quoted
+	}
+	quotedTopic, err := json.Marshal(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, 0, len(data)+len(quotedTopic)+len(`{"type":"event","topic":,"data":,"seq":`))
+	prefix = append(prefix, `{"type":"event","topic":`...)
+	prefix = append(prefix, quotedTopic...)
+	prefix = append(prefix, `,"data":`...)
+	prefix = append(prefix, data...)
+	prefix = append(prefix, `,"seq":`...)
+	return &event{topic: topic, data: data, prefix: prefix}, nil
+}
+
+// encode retourne le message de l'événement pour un encodage et un numéro de séquence
+// (nil si l'événement ne peut pas être encodé)
+func (e *event) encode(format encoding.Format, seq uint64) []byte {
+	switch format {
+	case encoding.FormatMsgPack:
+		e.msgpackOnce.Do(func() {
+			prefix, err := msgpackEventPrefix(e.topic, e.data)
+			if err != nil {
+				logrus.WithError(err).WithField("topic", e.topic).Warn("Failed to encode realtime event as msgpack")
+				return
+			}
+			e.msgpackPrefix = prefix
+		})
+		if e.msgpackPrefix == nil {
+			return nil
+		}
+		payload := make([]byte, 0, len(e.msgpackPrefix)+maxMsgPackUintSize)
+		payload = append(payload, e.msgpackPrefix...)
+		return appendMsgPackUint(payload, seq)
+	case encoding.FormatProtobuf:
+		e.protoOnce.Do(func() {
+			e.protoPrefix = protoEventPrefix(e.topic, e.data)
+		})
+		payload := make([]byte, 0, len(e.protoPrefix)+maxProtoSeqSize)
+		payload = append(payload, e.protoPrefix...)
+		return appendProtoSeq(payload, seq)
+	default:
+		payload := make([]byte, 0, len(e.prefix)+maxSeqDigits+1)
+		payload = append(payload, e.prefix...)
+		payload = strconv.AppendUint(payload, seq, decimalBase)
+		return append(payload, '}')
+	}
+}
+
+// bufferedEvent événement gardé pour la reprise de session
+// L'événement est gardé plutôt que son encodage : la reprise peut changer d'encodage.
+type bufferedEvent struct {
+	seq   uint64
+	event *event
+}
+
+// session abonnements et événements d'un utilisateur, indépendants de la connexion
+// Une session survit à la déconnexion pendant la fenêtre de reprise : les événements
+// continuent d'être numérotés et gardés, puis rejoués au client qui se reconnecte avec
+// son resume token.
+type session struct {
+	id       string
+	identity Identity
+
+	mu         sync.Mutex
+	token      string // resume token courant
+	topics     map[string]struct{}
+	seq        uint64
+	buffer     []bufferedEvent
+	bufferSize int
+	client     *client
+	detachedAt time.Time
+}
+
+// newSession crée une session sans connexion
+func newSession(id string, identity Identity, bufferSize int) *session {
+	return &session{
+		id:         id,
+		identity:   identity,
+		topics:     make(map[string]struct{}),
+		buffer:     make([]bufferedEvent, 0, bufferSize),
+		bufferSize: bufferSize,
+		detachedAt: time.Now(),
+	}
+}
+
+// deliver numérote un événement, le garde pour la reprise et l'envoie au client connecté
+func (s *session) deliver(e *event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+
+	if len(s.buffer) == s.bufferSize {
+		copy(s.buffer, s.buffer[1:])
+		s.buffer = s.buffer[:len(s.buffer)-1]
+	}
+	s.buffer = append(s.buffer, bufferedEvent{seq: s.seq, event: e})
+
+	if s.client != nil {
+		if payload := e.encode(s.client.format, s.seq); payload != nil {
+			s.client.enqueue(payload)
+		}
+	}
+}
+
+// send envoie un message hors séquence (réponse, erreur) au client connecté
+func (s *session) send(message interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == nil {
+		return
+	}
+	payload, err := encodeMessage(s.client.format, message)
+	if err != nil {
+		logrus.WithError(err).WithField("session_id", s.id).Warn("Failed to encode realtime message")
+		return
+	}
+	s.client.enqueue(payload)
+}
+
+// attach rattache une connexion à la session et lui envoie l'accueil
+// En reprise, les événements postérieurs à lastSeq sont rejoués avant tout nouvel
+// événement ; si certains ont déjà quitté le tampon, le client est prévenu qu'il doit
+// resynchroniser son état. Retourne la connexion précédente à fermer, s'il y en avait une.
+func (s *session) attach(c *client, resumed bool, lastSeq uint64, pingInterval time.Duration) *client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.client
+	s.client = c
+
+	var replay []bufferedEvent
+	resyncRequired := false
+	if resumed {
+		for _, buffered := range s.buffer {
+			if buffered.seq > lastSeq {
+				replay = append(replay, buffered)
+			}
+		}
+		// Séquence inconnue ou événements manquants déjà sortis du tampon
+		resyncRequired = lastSeq > s.seq ||
+			(lastSeq < s.seq && (len(replay) == 0 || replay[0].seq != lastSeq+1))
+	}
+
+	welcome, err := encodeMessage(c.format, map[string]interface{}{
+		"type":               "welcome",
+		"session_id":         s.id,
+		"resume_token":       s.token,
+		"resumed":            resumed,
+		"resync_required":    resyncRequired,
+		"seq":                s.seq,
+		"topics":             s.topicList(),
+		"heartbeat_interval": int(pingInterval / time.Second),
+		"time":               time.Now().Unix(),
+	})
+	if err == nil {
+		c.enqueue(welcome)
+	}
+	for _, buffered := range replay {
+		if payload := buffered.event.encode(c.format, buffered.seq); payload != nil {
+			c.enqueue(payload)
+		}
+	}
+
+	return previous
+}
+
+// detach détache une connexion fermée ; la session reste disponible pour la reprise
+func (s *session) detach(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == c {
+		s.client = nil
+		s.detachedAt = time.Now()
+	}
+}
+
+// closeClient ferme la connexion rattachée, s'il y en a une
+func (s *session) closeClient(code int, reason string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == nil {
+		return false
+	}
+	s.client.close(code, reason)
+	return true
+}
+
+// expired indique une session détachée depuis plus que la fenêtre de reprise
+func (s *session) expired(now time.Time, window time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.client == nil && now.Sub(s.detachedAt) > window
+}
+
+// topicList retourne les topics de la session triés (verrou requis)
+func (s *session) topicList() []string {
+	topics := make([]string, 0, len(s.topics))
+	for topic := range s.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
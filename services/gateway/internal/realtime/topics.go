@@ -0,0 +1,113 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Types de topics auxquels un client peut s'abonner (<type>:<id>)
+const (
+	TopicChat   = "chat"
+	TopicZone   = "zone"
+	TopicGuild  = "guild"
+	TopicParty  = "party"
+	TopicCombat = "combat"
+	TopicUser   = "user" // topic personnel, abonnement automatique
+
+	maxTopicIDLength = 64
+)
+
+var (
+	// ErrInvalidTopic le topic n'a pas la forme <type>:<id>
+	ErrInvalidTopic = errors.New("invalid topic")
+	// ErrTopicForbidden le client n'a pas accès au topic
+	ErrTopicForbidden = errors.New("topic not allowed")
+	// ErrTooManySubscriptions le client a atteint sa limite d'abonnements
+	ErrTooManySubscriptions = errors.New("too many subscriptions")
+)
+
+var topicKinds = map[string]bool{
+	TopicChat:   true,
+	TopicZone:   true,
+	TopicGuild:  true,
+	TopicParty:  true,
+	TopicCombat: true,
+	TopicUser:   true,
+}
+
+// parseTopic découpe un topic en type et identifiant
+func parseTopic(topic string) (kind, id string, err error) {
+	kind, id, found := strings.Cut(topic, ":")
+	if !found || !topicKinds[kind] || !validTopicID(id) {
+		return "", "", ErrInvalidTopic
+	}
+	return kind, id, nil
+}
+
+// authorizeTopic vérifie qu'un utilisateur peut s'abonner à un topic
+// Le topic personnel n'est accessible qu'à son propriétaire. Les autres topics sont réservés
+// aux membres du channel, de la party, de la guilde ou du combat, ou aux utilisateurs ayant un
+// personnage en ligne dans la zone, vérifiés auprès des services (nil : topics refusés).
+func authorizeTopic(ctx context.Context, topic string, identity Identity, membership *MembershipChecker) error {
+	kind, id, err := parseTopic(topic)
+	if err != nil {
+		return err
+	}
+
+	if kind == TopicUser {
+		if id != identity.UserID {
+			return ErrTopicForbidden
+		}
+		return nil
+	}
+
+	if membership == nil {
+		return ErrTopicForbidden
+	}
+	member, err := membership.IsMember(ctx, kind, id, identity.UserID)
+	if err != nil {
+		logrus.WithError(err).WithField("topic", topic).Warn("Failed to check realtime topic membership")
+		return ErrMembershipUnavailable
+	}
+	if !member {
+		return ErrTopicForbidden
+	}
+	return nil
+}
+
+// userTopic retourne le topic personnel d'un utilisateur
+func userTopic(userID string) string {
+	return TopicUser + ":" + userID
+}
+
+// topicFromSubject convertit un sujet NATS <prefix>.<type>.<id> en topic
+func topicFromSubject(prefix, subject string) (string, bool) {
+	rest, found := strings.CutPrefix(subject, prefix+".")
+	if !found {
+		return "", false
+	}
+	kind, id, found := strings.Cut(rest, ".")
+	if !found || !topicKinds[kind] || !validTopicID(id) {
+		return "", false
+	}
+	return kind + ":" + id, true
+}
+
+// validTopicID accepte les identifiants alphanumériques, '-' et '_'
+// (ils doivent tenir dans un seul token de sujet NATS)
+func validTopicID(id string) bool {
+	if id == "" || len(id) > maxTopicIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
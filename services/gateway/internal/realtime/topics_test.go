@@ -0,0 +1,156 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"gateway/internal/config"
+	"mmorpg/pkg/serviceauth"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testMemberID   = "0a1b2c3d-4e5f-4a6b-8c7d-8e9f0a1b2c3d"
+	testOutsiderID = "9f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a"
+	testPlayerID   = "5d6e7f80-91a2-4b3c-8d4e-5f6a7b8c9d0e"
+	testServiceJWT = "service-token"
+)
+
+// newTestMembership vérificateur dont tous les services (auth compris) sont un même serveur de test
+// Seul testMemberID appartient aux groupes ; le service guild ne connaît que son player_id.
+func newTestMembership(t *testing.T) (*MembershipChecker, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/services/token", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"access_token":"` + testServiceJWT + `","expires_in":300}}`))
+	})
+	member := func(pattern string) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+	member("GET /api/v1/services/channels/general/members/" + testMemberID)
+	member("GET /api/v1/services/parties/p1/members/" + testMemberID)
+	member("GET /api/v1/services/zones/z42/users/" + testMemberID)
+	member("GET /api/v1/services/guild-members/g1/" + testPlayerID)
+	mux.HandleFunc("GET /api/v1/services/player/"+testMemberID, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"player_summary":{"player_id":"` + testPlayerID + `"}}`))
+	})
+	mux.HandleFunc("GET /api/v1/services/combat/c1/status", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"user_ids":["` + testMemberID + `"]}`))
+	})
+	mux.HandleFunc("GET /api/v1/services/channels/broken/members/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/services/token" {
+			calls.Add(1)
+			if r.Header.Get("Authorization") != "Bearer "+testServiceJWT {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	endpoint := config.ServiceEndpoint{URL: server.URL}
+	services := &config.ServicesConfig{
+		Auth: endpoint, Player: endpoint, Guild: endpoint, Chat: endpoint, World: endpoint, Combat: endpoint,
+	}
+	tokens := serviceauth.NewTokenSource(server.URL, "gateway", "secret", time.Second)
+	rt := &config.RealtimeConfig{MembershipTimeout: time.Second, MembershipTTL: time.Minute}
+	return NewMembershipChecker(rt, services, tokens), &calls
+}
+
+func TestAuthorizeTopicMembers(t *testing.T) {
+	membership, _ := newTestMembership(t)
+	topics := []string{"chat:general", "party:p1", "zone:z42", "guild:g1", "combat:c1"}
+
+	for _, topic := range topics {
+		member := Identity{UserID: testMemberID}
+		if err := authorizeTopic(context.Background(), topic, member, membership); err != nil {
+			t.Errorf("%s: member refused: %v", topic, err)
+		}
+
+		outsider := Identity{UserID: testOutsiderID}
+		if err := authorizeTopic(context.Background(), topic, outsider, membership); !errors.Is(err, ErrTopicForbidden) {
+			t.Errorf("%s: outsider error = %v, want ErrTopicForbidden", topic, err)
+		}
+	}
+}
+
+// TestAuthorizeTopicGuildUsesPlayerID la guilde est vérifiée avec le player_id, pas l'user_id
+func TestAuthorizeTopicGuildUsesPlayerID(t *testing.T) {
+	membership, _ := newTestMembership(t)
+
+	member, err := membership.IsMember(context.Background(), TopicGuild, "g1", testMemberID)
+	if err != nil || !member {
+		t.Fatalf("IsMember = %v, %v; want true", member, err)
+	}
+
+	// Utilisateur sans profil joueur : pas membre, sans erreur
+	member, err = membership.IsMember(context.Background(), TopicGuild, "g1", testOutsiderID)
+	if err != nil || member {
+		t.Fatalf("IsMember without player = %v, %v; want false", member, err)
+	}
+}
+
+func TestAuthorizeTopicUser(t *testing.T) {
+	identity := Identity{UserID: testMemberID}
+
+	if err := authorizeTopic(context.Background(), userTopic(testMemberID), identity, nil); err != nil {
+		t.Errorf("own topic refused: %v", err)
+	}
+	if err := authorizeTopic(context.Background(), userTopic(testOutsiderID), identity, nil); !errors.Is(err, ErrTopicForbidden) {
+		t.Errorf("other user topic error = %v, want ErrTopicForbidden", err)
+	}
+}
+
+func TestAuthorizeTopicWithoutMembership(t *testing.T) {
+	identity := Identity{UserID: testMemberID}
+
+	for _, topic := range []string{"chat:general", "zone:z42", "party:p1"} {
+		if err := authorizeTopic(context.Background(), topic, identity, nil); !errors.Is(err, ErrTopicForbidden) {
+			t.Errorf("%s without membership checker: error = %v, want ErrTopicForbidden", topic, err)
+		}
+	}
+}
+
+func TestAuthorizeTopicServiceError(t *testing.T) {
+	membership, _ := newTestMembership(t)
+
+	err := authorizeTopic(context.Background(), "chat:broken", Identity{UserID: testMemberID}, membership)
+	if !errors.Is(err, ErrMembershipUnavailable) {
+		t.Fatalf("error = %v, want ErrMembershipUnavailable", err)
+	}
+}
+
+func TestAuthorizeTopicInvalid(t *testing.T) {
+	for _, topic := range []string{"chat", "unknown:1", "chat:", "chat:a.b", "zone:" + strings.Repeat("a", maxTopicIDLength+1)} {
+		if err := authorizeTopic(context.Background(), topic, Identity{UserID: testMemberID}, nil); !errors.Is(err, ErrInvalidTopic) {
+			t.Errorf("%q: error = %v, want ErrInvalidTopic", topic, err)
+		}
+	}
+}
+
+// TestMembershipCached les réponses sont gardées pendant MembershipTTL
+func TestMembershipCached(t *testing.T) {
+	membership, calls := newTestMembership(t)
+
+	for range 3 {
+		if _, err := membership.IsMember(context.Background(), TopicChat, "general", testMemberID); err != nil {
+			t.Fatalf("IsMember: %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("chat service called %d times, want 1", calls.Load())
+	}
+}
@@ -0,0 +1,237 @@
+package realtime
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"gateway/internal/encoding"
+	"math"
+	"mmorpg/pkg/protocodec"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Numéros de champs protobuf de ServerMessage (api/proto/game.proto)
+const (
+	serverMessageType protowire.Number = iota + 1
+	serverMessageTopic
+	serverMessageSeq
+	serverMessageChatEvent
+	serverMessagePayload
+)
+
+// Numéros de champs protobuf de ClientMessage
+const (
+	clientMessageType protowire.Number = iota + 1
+	clientMessageID
+	clientMessageTopics
+	clientMessageChannel
+	clientMessageContent
+)
+
+// Numéros de champs protobuf de ChatEvent
+const (
+	chatEventType protowire.Number = iota + 1
+	chatEventChannelID
+	chatEventUserID
+	chatEventData
+	chatEventTimestamp
+)
+
+// En-têtes MessagePack (spécification MessagePack)
+const (
+	msgpackFixMap4    = 0x84 // carte de 4 entrées (type, topic, data, seq)
+	msgpackMaxFixUint = 0x7f
+	msgpackUint8      = 0xcc
+	msgpackUint16     = 0xcd
+	msgpackUint32     = 0xce
+	msgpackUint64     = 0xcf
+)
+
+// chatEvent événement publié sur un topic chat (models.ChatEvent du service chat)
+type chatEvent struct {
+	Type      string          `json:"type"`
+	ChannelID uuid.UUID       `json:"channel_id"`
+	UserID    uuid.UUID       `json:"user_id"`
+	Data      json.RawMessage `json:"data"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// MarshalProto encode l'événement en protobuf
+func (e *chatEvent) MarshalProto() []byte {
+	var b []byte
+	b = protocodec.AppendString(b, chatEventType, e.Type)
+	b = protocodec.AppendUUID(b, chatEventChannelID, e.ChannelID)
+	b = protocodec.AppendUUID(b, chatEventUserID, e.UserID)
+	if string(e.Data) != "null" {
+		b = protocodec.AppendBytes(b, chatEventData, e.Data)
+	}
+	return protocodec.AppendTime(b, chatEventTimestamp, e.Timestamp)
+}
+
+// UnmarshalProto décode un message client protobuf
+func (m *clientMessage) UnmarshalProto(data []byte) error {
+	return protocodec.Read(data, func(f *protocodec.Field) error {
+		switch f.Number {
+		case clientMessageType:
+			m.Type = f.String()
+		case clientMessageID:
+			m.ID = f.String()
+		case clientMessageTopics:
+			m.Topics = append(m.Topics, f.String())
+		case clientMessageChannel:
+			m.Channel = f.String()
+		case clientMessageContent:
+			m.Content = f.String()
+		}
+		return nil
+	})
+}
+
+// decodeClientMessage décode un message client dans l'encodage de la connexion
+func decodeClientMessage(format encoding.Format, data []byte) (*clientMessage, error) {
+	message := &clientMessage{}
+	var err error
+	switch format {
+	case encoding.FormatMsgPack:
+		err = encoding.UnmarshalMsgPack(data, message)
+	case encoding.FormatProtobuf:
+		err = message.UnmarshalProto(data)
+	default:
+		err = json.Unmarshal(data, message)
+	}
+	return message, err
+}
+
+// decodeChatMessage champs d'un message chat_message, relayés au service de chat en JSON
+func decodeChatMessage(format encoding.Format, message *clientMessage, data []byte) (map[string]interface{}, error) {
+	var fields map[string]interface{}
+	switch format {
+	case encoding.FormatMsgPack:
+		if err := encoding.UnmarshalMsgPack(data, &fields); err != nil {
+			return nil, err
+		}
+	case encoding.FormatProtobuf:
+		// Le schéma protobuf ne porte que les champs connus du message
+		fields = map[string]interface{}{
+			"type":    message.Type,
+			"id":      message.ID,
+			"channel": message.Channel,
+			"content": message.Content,
+		}
+	default:
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+// encodeMessage encode un message hors séquence (réponse, erreur, annonce)
+// En protobuf, le contenu JSON du message est porté par ServerMessage.payload.
+func encodeMessage(format encoding.Format, message interface{}) ([]byte, error) {
+	switch format {
+	case encoding.FormatMsgPack:
+		return encoding.MarshalMsgPack(message)
+	case encoding.FormatProtobuf:
+		payload, err := json.Marshal(message)
+		if err != nil {
+			return nil, err
+		}
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err = json.Unmarshal(payload, &header); err != nil {
+			return nil, err
+		}
+		b := protocodec.AppendString(nil, serverMessageType, header.Type)
+		return protocodec.AppendBytes(b, serverMessagePayload, payload), nil
+	default:
+		return json.Marshal(message)
+	}
+}
+
+// msgpackEventPrefix début MessagePack d'un événement : {"type":"event","topic":...,"data":...,"seq":
+// Les clés et valeurs sont encodées d'un bloc sous forme de tableau, dont l'en-tête est
+// remplacé par celui de la carte.
+func msgpackEventPrefix(topic string, data []byte) ([]byte, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	parts, err := encoding.MarshalMsgPack([]interface{}{"type", "event", "topic", topic, "data", value, "seq"})
+	if err != nil {
+		return nil, err
+	}
+	parts[0] = msgpackFixMap4 // en-tête d'un tableau de 7 éléments, sur un octet
+	return parts, nil
+}
+
+// protoEventPrefix ServerMessage d'un événement sans son numéro de séquence
+// Les événements des topics chat conformes à ChatEvent sont encodés selon le schéma ; les
+// autres gardent leurs données JSON dans payload.
+func protoEventPrefix(topic string, data []byte) []byte {
+	b := protocodec.AppendString(nil, serverMessageType, "event")
+	b = protocodec.AppendString(b, serverMessageTopic, topic)
+
+	if kind, _, err := parseTopic(topic); err == nil && kind == TopicChat {
+		var event chatEvent
+		if json.Unmarshal(data, &event) == nil && event.Type != "" {
+			return protocodec.AppendMessage(b, serverMessageChatEvent, &event)
+		}
+	}
+	return protocodec.AppendBytes(b, serverMessagePayload, data)
+}
+
+// appendMsgPackUint ajoute un entier non signé MessagePack dans sa forme la plus courte
+func appendMsgPackUint(b []byte, value uint64) []byte {
+	switch {
+	case value <= msgpackMaxFixUint:
+		return append(b, byte(value))
+	case value <= math.MaxUint8:
+		return append(b, msgpackUint8, byte(value))
+	case value <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, msgpackUint16), uint16(value))
+	case value <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, msgpackUint32), uint32(value))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, msgpackUint64), value)
+	}
+}
+
+// appendProtoSeq ajoute le numéro de séquence d'un ServerMessage
+func appendProtoSeq(b []byte, seq uint64) []byte {
+	b = protowire.AppendTag(b, serverMessageSeq, protowire.VarintType)
+	return protowire.AppendVarint(b, seq)
+}
+
+// Subprotocols sous-protocoles WebSocket acceptés, un par encodage (json, msgpack, protobuf)
+func Subprotocols() []string {
+	protocols := make([]string, 0, len(encoding.Formats))
+	for _, format := range encoding.Formats {
+		protocols = append(protocols, string(format))
+	}
+	return protocols
+}
+
+// EncodeEvent encode un événement de topic tel qu'envoyé à des abonnés de numéros de
+// séquence seqs (outils de mesure) : l'événement est préparé une fois pour tous
+func EncodeEvent(format encoding.Format, topic string, data []byte, seqs ...uint64) ([][]byte, error) {
+	e, err := newEvent(topic, data)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make([][]byte, 0, len(seqs))
+	for _, seq := range seqs {
+		payload := e.encode(format, seq)
+		if payload == nil {
+			return nil, fmt.Errorf("failed to encode event as %s", format)
+		}
+		frames = append(frames, payload)
+	}
+	return frames, nil
+}
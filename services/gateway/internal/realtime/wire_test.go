@@ -0,0 +1,282 @@
+package realtime
+
+import (
+	"encoding/json"
+	"gateway/internal/encoding"
+	"mmorpg/pkg/protocodec"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const testChatTopic = "chat:3f1c2d3e-4b5a-4c6d-8e7f-9a0b1c2d3e4f"
+
+// newTestChatEvent événement chat tel que publié par le service chat
+func newTestChatEvent() *chatEvent {
+	return &chatEvent{
+		Type:      "message",
+		ChannelID: uuid.MustParse("3f1c2d3e-4b5a-4c6d-8e7f-9a0b1c2d3e4f"),
+		UserID:    uuid.MustParse("0a1b2c3d-4e5f-4a6b-8c7d-8e9f0a1b2c3d"),
+		Data:      json.RawMessage(`{"content":"hello","username":"Aldric"}`),
+		Timestamp: time.UnixMilli(1700000000123).UTC(),
+	}
+}
+
+// newTestClientMessage message client avec tous les champs du schéma renseignés
+func newTestClientMessage() *clientMessage {
+	return &clientMessage{
+		Type:    "subscribe",
+		ID:      "req-42",
+		Topics:  []string{testChatTopic, "zone:starter_plains"},
+		Channel: "general",
+		Content: "hello",
+	}
+}
+
+// protoClientMessage encode un message client avec les numéros de game.proto
+func protoClientMessage(m *clientMessage) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, m.Type)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, m.ID)
+	for _, topic := range m.Topics {
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendString(b, topic)
+	}
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	b = protowire.AppendString(b, m.Channel)
+	b = protowire.AppendTag(b, 5, protowire.BytesType)
+	return protowire.AppendString(b, m.Content)
+}
+
+// readServerMessage relit un ServerMessage avec les numéros de game.proto
+func readServerMessage(t testing.TB, data []byte) (fields map[protowire.Number]interface{}, event []byte) {
+	t.Helper()
+
+	fields = map[protowire.Number]interface{}{}
+	err := protocodec.Read(data, func(f *protocodec.Field) error {
+		switch f.Number {
+		case 1, 2, 5:
+			fields[f.Number] = f.String()
+		case 3:
+			fields[f.Number] = f.Uint64()
+		case 4:
+			event = append([]byte(nil), f.Bytes()...)
+		default:
+			t.Errorf("unexpected ServerMessage field %d", f.Number)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadProto: %v", err)
+	}
+	return fields, event
+}
+
+// TestDecodeClientMessageFormats décode le même message dans les trois encodages
+func TestDecodeClientMessageFormats(t *testing.T) {
+	want := newTestClientMessage()
+
+	jsonData, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	msgpackData, err := encoding.MarshalMsgPack(want)
+	if err != nil {
+		t.Fatalf("MarshalMsgPack: %v", err)
+	}
+
+	for format, data := range map[encoding.Format][]byte{
+		encoding.FormatJSON:     jsonData,
+		encoding.FormatMsgPack:  msgpackData,
+		encoding.FormatProtobuf: protoClientMessage(want),
+	} {
+		message, decodeErr := decodeClientMessage(format, data)
+		if decodeErr != nil {
+			t.Fatalf("%s: decodeClientMessage: %v", format, decodeErr)
+		}
+		if !reflect.DeepEqual(message, want) {
+			t.Errorf("%s: decoded %+v, want %+v", format, message, want)
+		}
+	}
+}
+
+func TestDecodeClientMessageProtoInvalid(t *testing.T) {
+	if _, err := decodeClientMessage(encoding.FormatProtobuf, []byte{0x0a, 0x05, 'a'}); err == nil {
+		t.Fatal("expected an error for a truncated message")
+	}
+}
+
+// TestEncodeEventProtoChatEvent les événements chat sont encodés selon ChatEvent
+func TestEncodeEventProtoChatEvent(t *testing.T) {
+	event := newTestChatEvent()
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	frames, err := EncodeEvent(encoding.FormatProtobuf, testChatTopic, data, 7)
+	if err != nil {
+		t.Fatalf("EncodeEvent: %v", err)
+	}
+
+	fields, chatEventData := readServerMessage(t, frames[0])
+	wantFields := map[protowire.Number]interface{}{1: "event", 2: testChatTopic, 3: uint64(7)}
+	if !reflect.DeepEqual(fields, wantFields) {
+		t.Errorf("server message fields = %v, want %v", fields, wantFields)
+	}
+
+	decoded := map[protowire.Number]interface{}{}
+	err = protocodec.Read(chatEventData, func(f *protocodec.Field) error {
+		switch f.Number {
+		case 1, 4:
+			decoded[f.Number] = f.String()
+		case 2, 3:
+			id, idErr := f.UUID()
+			decoded[f.Number] = id
+			return idErr
+		case 5:
+			decoded[f.Number] = f.Time()
+		default:
+			t.Errorf("unexpected ChatEvent field %d", f.Number)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadProto(chat_event): %v", err)
+	}
+	wantEvent := map[protowire.Number]interface{}{
+		1: event.Type, 2: event.ChannelID, 3: event.UserID, 4: string(event.Data), 5: event.Timestamp,
+	}
+	if !reflect.DeepEqual(decoded, wantEvent) {
+		t.Errorf("chat event fields = %v, want %v", decoded, wantEvent)
+	}
+}
+
+// TestEncodeEventProtoPayload les autres événements gardent leurs données JSON dans payload
+func TestEncodeEventProtoPayload(t *testing.T) {
+	data := []byte(`{"zone_id":"starter_plains","players":3}`)
+
+	frames, err := EncodeEvent(encoding.FormatProtobuf, "zone:starter_plains", data, 0, 300)
+	if err != nil {
+		t.Fatalf("EncodeEvent: %v", err)
+	}
+
+	for i, seq := range []uint64{0, 300} {
+		fields, chatEventData := readServerMessage(t, frames[i])
+		if chatEventData != nil {
+			t.Errorf("seq %d: unexpected chat_event for a zone topic", seq)
+		}
+		want := map[protowire.Number]interface{}{1: "event", 2: "zone:starter_plains", 3: seq, 5: string(data)}
+		if !reflect.DeepEqual(fields, want) {
+			t.Errorf("seq %d: server message fields = %v, want %v", seq, fields, want)
+		}
+	}
+}
+
+// TestEncodeEventRoundTrip relit l'événement en JSON et en MessagePack, pour des numéros de
+// séquence couvrant toutes les tailles d'entier MessagePack
+func TestEncodeEventRoundTrip(t *testing.T) {
+	data := []byte(`{"zone_id":"starter_plains","players":3}`)
+	seqs := []uint64{0, 127, 128, 255, 256, 65535, 65536, 1<<32 - 1, 1 << 32}
+
+	type wireEvent struct {
+		Type  string                 `json:"type"`
+		Topic string                 `json:"topic"`
+		Data  map[string]interface{} `json:"data"`
+		Seq   uint64                 `json:"seq"`
+	}
+
+	for _, format := range []encoding.Format{encoding.FormatJSON, encoding.FormatMsgPack} {
+		frames, err := EncodeEvent(format, "zone:starter_plains", data, seqs...)
+		if err != nil {
+			t.Fatalf("%s: EncodeEvent: %v", format, err)
+		}
+
+		for i, seq := range seqs {
+			var decoded wireEvent
+			if format == encoding.FormatMsgPack {
+				err = encoding.UnmarshalMsgPack(frames[i], &decoded)
+			} else {
+				err = json.Unmarshal(frames[i], &decoded)
+			}
+			if err != nil {
+				t.Fatalf("%s seq %d: decode: %v", format, seq, err)
+			}
+			if decoded.Type != "event" || decoded.Topic != "zone:starter_plains" || decoded.Seq != seq {
+				t.Errorf("%s seq %d: decoded %+v", format, seq, decoded)
+			}
+			if decoded.Data["zone_id"] != "starter_plains" {
+				t.Errorf("%s seq %d: data = %v", format, seq, decoded.Data)
+			}
+		}
+	}
+}
+
+// TestEncodeMessageProto les messages hors séquence portent leur JSON dans payload
+func TestEncodeMessageProto(t *testing.T) {
+	message := map[string]interface{}{"type": "error", "message": "invalid topic"}
+
+	data, err := encodeMessage(encoding.FormatProtobuf, message)
+	if err != nil {
+		t.Fatalf("encodeMessage: %v", err)
+	}
+
+	fields, _ := readServerMessage(t, data)
+	if fields[1] != "error" {
+		t.Errorf("type = %v, want error", fields[1])
+	}
+	var payload map[string]interface{}
+	if err = json.Unmarshal([]byte(fields[5].(string)), &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if !reflect.DeepEqual(payload, message) {
+		t.Errorf("payload = %v, want %v", payload, message)
+	}
+}
+
+func benchmarkEncodeEvent(b *testing.B, format encoding.Format, topic string, data []byte) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := EncodeEvent(format, topic, data, uint64(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeEventJSON(b *testing.B) {
+	data, _ := json.Marshal(newTestChatEvent())
+	benchmarkEncodeEvent(b, encoding.FormatJSON, testChatTopic, data)
+}
+
+func BenchmarkEncodeEventMsgPack(b *testing.B) {
+	data, _ := json.Marshal(newTestChatEvent())
+	benchmarkEncodeEvent(b, encoding.FormatMsgPack, testChatTopic, data)
+}
+
+func BenchmarkEncodeEventProtobuf(b *testing.B) {
+	data, _ := json.Marshal(newTestChatEvent())
+	benchmarkEncodeEvent(b, encoding.FormatProtobuf, testChatTopic, data)
+}
+
+func BenchmarkChatEventMarshalProto(b *testing.B) {
+	event := newTestChatEvent()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = event.MarshalProto()
+	}
+}
+
+func BenchmarkDecodeClientMessageProtobuf(b *testing.B) {
+	data := protoClientMessage(newTestClientMessage())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := decodeClientMessage(encoding.FormatProtobuf, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
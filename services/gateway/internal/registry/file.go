@@ -0,0 +1,135 @@
+package registry
+
+import (
+	"fmt"
+	"gateway/internal/config"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// fileService décrit un service dans le fichier de registre
+//
+//	services:
+//	  combat:
+//	    timeout: 3s
+//	    retries: 1
+//	    strategy: consistent_hash
+//	    instances:
+//	      - id: combat-1
+//	        url: http://combat-1:8084
+//	      - id: combat-canary
+//	        url: http://combat-canary:8084
+//	        version: v2
+type fileService struct {
+	Timeout   time.Duration  `mapstructure:"timeout"`
+	Retries   int            `mapstructure:"retries"`
+	Strategy  string         `mapstructure:"strategy"`
+	Instances []fileInstance `mapstructure:"instances"`
+	source    Source
+}
+
+// fileInstance décrit une instance dans le fichier de registre
+type fileInstance struct {
+	ID       string            `mapstructure:"id"`
+	URL      string            `mapstructure:"url"`
+	Version  string            `mapstructure:"version"`
+	Metadata map[string]string `mapstructure:"metadata"`
+}
+
+// registryFile structure racine du fichier de registre
+type registryFile struct {
+	Services map[string]*fileService `mapstructure:"services"`
+}
+
+// fileWatcher détecte les modifications du fichier de registre par sa date et sa taille
+// (fonctionne aussi avec les remplacements atomiques et les ConfigMaps Kubernetes)
+type fileWatcher struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// newFileWatcher crée un watcher pour le fichier de registre
+func newFileWatcher(path string) *fileWatcher {
+	return &fileWatcher{path: path}
+}
+
+// changed indique si le fichier a été modifié depuis le dernier chargement
+func (w *fileWatcher) changed() (bool, error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false, err
+	}
+
+	return !info.ModTime().Equal(w.modTime) || info.Size() != w.size, nil
+}
+
+// load lit et valide le fichier de registre (yaml ou json selon l'extension)
+func (w *fileWatcher) load() (map[string]*fileService, error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	v.SetConfigFile(w.path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	var file registryFile
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, fmt.Errorf("error unmarshalling registry file: %w", err)
+	}
+
+	for name, service := range file.Services {
+		if err := validateFileService(name, service); err != nil {
+			return nil, err
+		}
+		service.source = SourceFile
+	}
+
+	// Mémoriser l'état du fichier seulement après un chargement valide
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+
+	return file.Services, nil
+}
+
+// validateFileService valide un service du fichier et complète ses valeurs par défaut
+func validateFileService(name string, service *fileService) error {
+	if service == nil || len(service.Instances) == 0 {
+		return fmt.Errorf("service %s must declare at least one instance", name)
+	}
+	if service.Timeout < 0 {
+		return fmt.Errorf("service %s timeout must be positive", name)
+	}
+	if service.Timeout == 0 {
+		service.Timeout = config.DefaultServiceTimeout * time.Second
+	}
+	if service.Retries < 0 {
+		return fmt.Errorf("service %s retries must be non-negative", name)
+	}
+	if service.Strategy != "" && !config.IsValidLBStrategy(service.Strategy) {
+		return fmt.Errorf("service %s: unknown load balancing strategy %q", name, service.Strategy)
+	}
+
+	seen := make(map[string]bool, len(service.Instances))
+	for i := range service.Instances {
+		instance := &service.Instances[i]
+		if err := validateURL(instance.URL); err != nil {
+			return fmt.Errorf("service %s: %w", name, err)
+		}
+		if instance.ID == "" {
+			instance.ID = instance.URL
+		}
+		if seen[instance.ID] {
+			return fmt.Errorf("service %s: duplicate instance id %q", name, instance.ID)
+		}
+		seen[instance.ID] = true
+	}
+
+	return nil
+}
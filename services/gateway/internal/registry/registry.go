@@ -0,0 +1,524 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"gateway/internal/config"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Constantes du registre
+const (
+	MaxHeartbeatTTL = 3600 // secondes
+
+	// DefaultVersion version des instances qui n'en déclarent pas
+	DefaultVersion = "stable"
+)
+
+// Source indique l'origine d'une instance
+type Source string
+
+const (
+	SourceConfig Source = "config" // configuration statique (env, config.yaml)
+	SourceFile   Source = "file"   // fichier de registre surveillé
+	SourceAPI    Source = "api"    // API d'enregistrement avec heartbeat
+)
+
+// Erreurs du registre
+var (
+	ErrServiceNotFound  = errors.New("service not found")
+	ErrInstanceNotFound = errors.New("instance not found")
+)
+
+// Instance représente une instance d'un service backend
+type Instance struct {
+	ID            string            `json:"id"`
+	URL           string            `json:"url"`
+	Version       string            `json:"version"` // build du service (répartition canary)
+	Source        Source            `json:"source"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	RegisteredAt  time.Time         `json:"registered_at"`
+	LastHeartbeat *time.Time        `json:"last_heartbeat,omitempty"`
+	ExpiresAt     *time.Time        `json:"expires_at,omitempty"`
+	ttl           time.Duration
+}
+
+// expired indique si une instance enregistrée par l'API a dépassé son TTL
+func (i *Instance) expired(now time.Time) bool {
+	return i.ExpiresAt != nil && now.After(*i.ExpiresAt)
+}
+
+// Service représente un service et ses instances connues
+type Service struct {
+	Name      string        `json:"name"`
+	Timeout   time.Duration `json:"timeout"`
+	Retries   int           `json:"retries"`
+	Strategy  string        `json:"strategy,omitempty"` // stratégie de répartition propre au service
+	Instances []*Instance   `json:"instances"`
+}
+
+// Registration représente une demande d'enregistrement via l'API
+type Registration struct {
+	Service  string            `json:"service" binding:"required"`
+	ID       string            `json:"id"`
+	URL      string            `json:"url" binding:"required"`
+	Version  string            `json:"version"`
+	TTL      int               `json:"ttl_seconds"`
+	Timeout  int               `json:"timeout_seconds"`
+	Retries  *int              `json:"retries"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// serviceEntry état interne d'un service
+// Les instances statiques (config ou fichier) et celles de l'API sont séparées :
+// un rechargement du fichier ne touche jamais aux enregistrements de l'API.
+type serviceEntry struct {
+	timeout  time.Duration
+	retries  int
+	strategy string
+	static   map[string]*Instance
+	dynamic  map[string]*Instance
+	explicit bool // timeout/retries définis par la config ou le fichier
+}
+
+// Registry maintient la liste vivante des services et de leurs instances
+type Registry struct {
+	config   *config.Config
+	mu       sync.RWMutex
+	services map[string]*serviceEntry
+	watcher  *fileWatcher
+	reloadMu sync.Mutex
+	stop     chan struct{}
+	closed   sync.Once
+}
+
+// NewRegistry crée le registre à partir de la configuration statique et du fichier surveillé
+func NewRegistry(cfg *config.Config) (*Registry, error) {
+	r := &Registry{
+		config:   cfg,
+		services: make(map[string]*serviceEntry),
+		stop:     make(chan struct{}),
+	}
+
+	r.applyStatic(r.configServices())
+
+	if cfg.Registry.File != "" {
+		r.watcher = newFileWatcher(cfg.Registry.File)
+		if err := r.Reload(); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Start démarre la surveillance du fichier et l'expiration des enregistrements
+func (r *Registry) Start() {
+	go r.cleanupLoop()
+
+	if r.watcher != nil {
+		go r.watchLoop()
+	}
+}
+
+// Close arrête les routines du registre
+func (r *Registry) Close() {
+	r.closed.Do(func() { close(r.stop) })
+}
+
+// Reload relit le fichier de registre et remplace les instances statiques
+// Sans fichier configuré, seule la configuration statique est utilisée.
+func (r *Registry) Reload() error {
+	if r.watcher == nil {
+		return nil
+	}
+
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	services, err := r.watcher.load()
+	if err != nil {
+		return fmt.Errorf("failed to load registry file: %w", err)
+	}
+
+	// Le fichier complète la configuration statique : un service décrit dans le
+	// fichier remplace entièrement les instances issues de la configuration
+	static := r.configServices()
+	for name, service := range services {
+		static[name] = service
+	}
+
+	r.applyStatic(static)
+
+	logrus.WithFields(logrus.Fields{
+		"file":     r.config.Registry.File,
+		"services": len(services),
+	}).Info("Service registry reloaded")
+
+	return nil
+}
+
+// Register enregistre ou met à jour une instance via l'API
+// Réenregistrer un ID existant avec une autre URL déplace l'instance.
+func (r *Registry) Register(reg *Registration) (*Instance, error) {
+	if err := validateURL(reg.URL); err != nil {
+		return nil, err
+	}
+
+	ttl := r.config.Registry.HeartbeatTTL
+	if reg.TTL != 0 {
+		if reg.TTL < 0 || reg.TTL > MaxHeartbeatTTL {
+			return nil, fmt.Errorf("ttl_seconds must be between 1 and %d", MaxHeartbeatTTL)
+		}
+		ttl = time.Duration(reg.TTL) * time.Second
+	}
+	if reg.Timeout < 0 {
+		return nil, fmt.Errorf("timeout_seconds must be positive")
+	}
+	if reg.Retries != nil && *reg.Retries < 0 {
+		return nil, fmt.Errorf("retries must be non-negative")
+	}
+
+	id := reg.ID
+	if id == "" {
+		id = uuid.New().String()
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	instance := &Instance{
+		ID:            id,
+		URL:           reg.URL,
+		Version:       versionOrDefault(reg.Version),
+		Source:        SourceAPI,
+		Metadata:      reg.Metadata,
+		RegisteredAt:  now,
+		LastHeartbeat: &now,
+		ExpiresAt:     &expiresAt,
+		ttl:           ttl,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := r.entry(reg.Service)
+	if !entry.explicit {
+		// Service inconnu de la configuration : les paramètres de l'enregistrement s'appliquent
+		if reg.Timeout > 0 {
+			entry.timeout = time.Duration(reg.Timeout) * time.Second
+		}
+		if reg.Retries != nil {
+			entry.retries = *reg.Retries
+		}
+	}
+
+	previous, exists := entry.dynamic[id]
+	if exists {
+		instance.RegisteredAt = previous.RegisteredAt
+	}
+	entry.dynamic[id] = instance
+
+	logrus.WithFields(logrus.Fields{
+		"service":  reg.Service,
+		"instance": id,
+		"url":      reg.URL,
+		"ttl":      ttl.String(),
+		"updated":  exists,
+	}).Info("Service instance registered")
+
+	copied := *instance
+	return &copied, nil
+}
+
+// Heartbeat prolonge le TTL d'une instance enregistrée via l'API
+func (r *Registry) Heartbeat(serviceName, id string) (*Instance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, exists := r.services[serviceName]
+	if !exists {
+		return nil, ErrServiceNotFound
+	}
+
+	// Une instance expirée doit se réenregistrer
+	now := time.Now()
+	instance, exists := entry.dynamic[id]
+	if !exists || instance.expired(now) {
+		return nil, ErrInstanceNotFound
+	}
+
+	expiresAt := now.Add(instance.ttl)
+	instance.LastHeartbeat = &now
+	instance.ExpiresAt = &expiresAt
+
+	copied := *instance
+	return &copied, nil
+}
+
+// Deregister retire une instance enregistrée via l'API
+func (r *Registry) Deregister(serviceName, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, exists := r.services[serviceName]
+	if !exists {
+		return ErrServiceNotFound
+	}
+
+	if _, exists := entry.dynamic[id]; !exists {
+		return ErrInstanceNotFound
+	}
+
+	delete(entry.dynamic, id)
+	r.pruneLocked(serviceName)
+
+	logrus.WithFields(logrus.Fields{
+		"service":  serviceName,
+		"instance": id,
+	}).Info("Service instance deregistered")
+
+	return nil
+}
+
+// Get retourne une copie d'un service et de ses instances actives
+func (r *Registry) Get(serviceName string) (*Service, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, exists := r.services[serviceName]
+	if !exists {
+		return nil, false
+	}
+
+	service := entry.snapshot(serviceName, time.Now())
+	if len(service.Instances) == 0 {
+		return nil, false
+	}
+	return service, true
+}
+
+// Services retourne une copie de l'état du registre, triée par nom
+func (r *Registry) Services() []*Service {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	services := make([]*Service, 0, len(r.services))
+	for name, entry := range r.services {
+		service := entry.snapshot(name, now)
+		if len(service.Instances) == 0 {
+			// Enregistrements expirés, pas encore retirés par le nettoyage
+			continue
+		}
+		services = append(services, service)
+	}
+
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services
+}
+
+// Méthodes privées
+
+// entry retourne l'entrée d'un service en la créant si besoin (verrou d'écriture requis)
+func (r *Registry) entry(name string) *serviceEntry {
+	entry, exists := r.services[name]
+	if !exists {
+		entry = &serviceEntry{
+			timeout: config.DefaultServiceTimeout * time.Second,
+			retries: config.DefaultRetries,
+			static:  make(map[string]*Instance),
+			dynamic: make(map[string]*Instance),
+		}
+		r.services[name] = entry
+	}
+	return entry
+}
+
+// configServices convertit les services de la configuration statique
+func (r *Registry) configServices() map[string]*fileService {
+	services := make(map[string]*fileService)
+	for name, endpoint := range r.config.Services.Endpoints() {
+		services[name] = &fileService{
+			Timeout:   endpoint.Timeout,
+			Retries:   endpoint.Retries,
+			Instances: []fileInstance{{ID: name, URL: endpoint.URL}},
+			source:    SourceConfig,
+		}
+	}
+	return services
+}
+
+// applyStatic remplace les instances statiques de tous les services
+func (r *Registry) applyStatic(services map[string]*fileService) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, entry := range r.services {
+		if _, exists := services[name]; !exists {
+			entry.static = make(map[string]*Instance)
+			entry.strategy = ""
+			entry.explicit = false
+			r.pruneLocked(name)
+		}
+	}
+
+	for name, service := range services {
+		entry := r.entry(name)
+		entry.timeout = service.Timeout
+		entry.retries = service.Retries
+		entry.strategy = service.Strategy
+		entry.explicit = true
+
+		static := make(map[string]*Instance, len(service.Instances))
+		for _, fi := range service.Instances {
+			instance := &Instance{
+				ID:           fi.ID,
+				URL:          fi.URL,
+				Version:      versionOrDefault(fi.Version),
+				Source:       service.source,
+				Metadata:     fi.Metadata,
+				RegisteredAt: now,
+			}
+			// Conserver la date d'origine d'une instance inchangée
+			if previous, exists := entry.static[fi.ID]; exists && previous.URL == fi.URL && previous.Version == instance.Version {
+				instance.RegisteredAt = previous.RegisteredAt
+			}
+			static[fi.ID] = instance
+		}
+		entry.static = static
+	}
+}
+
+// pruneLocked supprime un service qui n'a plus aucune instance (verrou d'écriture requis)
+func (r *Registry) pruneLocked(name string) {
+	if entry, exists := r.services[name]; exists && len(entry.static) == 0 && len(entry.dynamic) == 0 {
+		delete(r.services, name)
+	}
+}
+
+// cleanupLoop retire périodiquement les enregistrements expirés
+func (r *Registry) cleanupLoop() {
+	ticker := time.NewTicker(r.config.Registry.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.removeExpired()
+		}
+	}
+}
+
+// removeExpired retire les instances dont le heartbeat a expiré
+func (r *Registry) removeExpired() {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, entry := range r.services {
+		for id, instance := range entry.dynamic {
+			if instance.expired(now) {
+				delete(entry.dynamic, id)
+				logrus.WithFields(logrus.Fields{
+					"service":  name,
+					"instance": id,
+					"url":      instance.URL,
+				}).Warn("Service instance expired (missed heartbeats)")
+			}
+		}
+		r.pruneLocked(name)
+	}
+}
+
+// watchLoop recharge le fichier de registre lorsqu'il change
+func (r *Registry) watchLoop() {
+	ticker := time.NewTicker(r.config.Registry.WatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.reloadMu.Lock()
+			changed, err := r.watcher.changed()
+			r.reloadMu.Unlock()
+			if err != nil {
+				logrus.WithError(err).Warn("Failed to stat registry file, keeping current registry")
+				continue
+			}
+			if !changed {
+				continue
+			}
+			// En cas d'erreur, le registre courant est conservé
+			if err := r.Reload(); err != nil {
+				logrus.WithError(err).Error("Invalid registry file, keeping current registry")
+			}
+		}
+	}
+}
+
+// snapshot copie l'état d'un service et de ses instances actives
+func (e *serviceEntry) snapshot(name string, now time.Time) *Service {
+	active := e.activeInstances(now)
+	instances := make([]*Instance, len(active))
+	for i, instance := range active {
+		copied := *instance
+		instances[i] = &copied
+	}
+
+	return &Service{
+		Name:      name,
+		Timeout:   e.timeout,
+		Retries:   e.retries,
+		Strategy:  e.strategy,
+		Instances: instances,
+	}
+}
+
+// activeInstances retourne les instances non expirées, triées par ID pour un ordre stable
+func (e *serviceEntry) activeInstances(now time.Time) []*Instance {
+	instances := make([]*Instance, 0, len(e.static)+len(e.dynamic))
+	for _, instance := range e.static {
+		instances = append(instances, instance)
+	}
+	for _, instance := range e.dynamic {
+		if !instance.expired(now) {
+			instances = append(instances, instance)
+		}
+	}
+
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+	return instances
+}
+
+// versionOrDefault retourne la version déclarée d'une instance, DefaultVersion sinon
+func versionOrDefault(version string) string {
+	if version == "" {
+		return DefaultVersion
+	}
+	return version
+}
+
+// validateURL vérifie qu'une URL d'instance est exploitable par le proxy
+func validateURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid instance URL: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid instance URL %q: scheme http(s) and host are required", rawURL)
+	}
+	return nil
+}
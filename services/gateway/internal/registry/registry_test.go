@@ -0,0 +1,253 @@
+package registry
+
+import (
+	"errors"
+	"gateway/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestConfig configuration statique avec une instance combat et une instance guild
+func newTestConfig(file string) *config.Config {
+	endpoint := func(url string) config.ServiceEndpoint {
+		return config.ServiceEndpoint{URL: url, Timeout: time.Second, Retries: 1}
+	}
+	return &config.Config{
+		Services: config.ServicesConfig{
+			Combat: endpoint("http://combat:8085"),
+			Guild:  endpoint("http://guild:8086"),
+		},
+		Registry: config.RegistryConfig{
+			File:            file,
+			WatchInterval:   time.Second,
+			HeartbeatTTL:    time.Minute,
+			CleanupInterval: time.Minute,
+		},
+	}
+}
+
+func newTestRegistry(t *testing.T, file string) *Registry {
+	t.Helper()
+
+	r, err := NewRegistry(newTestConfig(file))
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	t.Cleanup(r.Close)
+	return r
+}
+
+// instanceURLs URLs des instances actives d'un service
+func instanceURLs(t *testing.T, r *Registry, name string) []string {
+	t.Helper()
+
+	service, found := r.Get(name)
+	if !found {
+		return nil
+	}
+	urls := make([]string, len(service.Instances))
+	for i, instance := range service.Instances {
+		urls[i] = instance.URL
+	}
+	return urls
+}
+
+func TestRegisterAndHeartbeat(t *testing.T) {
+	r := newTestRegistry(t, "")
+
+	retries := 0
+	instance, err := r.Register(&Registration{Service: "combat", ID: "combat-2", URL: "http://combat-2:8085", TTL: 30, Retries: &retries})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if instance.Version != DefaultVersion || instance.Source != SourceAPI {
+		t.Errorf("instance = %+v, want default version from the API", instance)
+	}
+
+	service, _ := r.Get("combat")
+	if len(service.Instances) != 2 {
+		t.Fatalf("combat instances = %d, want 2", len(service.Instances))
+	}
+	// Service de la configuration : ses paramètres priment sur l'enregistrement
+	if service.Retries != 1 {
+		t.Errorf("combat retries = %d, want 1 from config", service.Retries)
+	}
+
+	// Réenregistrer le même ID déplace l'instance sans changer sa date d'enregistrement
+	moved, err := r.Register(&Registration{Service: "combat", ID: "combat-2", URL: "http://combat-3:8085"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if !moved.RegisteredAt.Equal(instance.RegisteredAt) {
+		t.Error("registration date changed on update")
+	}
+	if urls := instanceURLs(t, r, "combat"); len(urls) != 2 || urls[1] != "http://combat-3:8085" {
+		t.Errorf("combat instances = %v", urls)
+	}
+
+	beat, err := r.Heartbeat("combat", "combat-2")
+	if err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+	if !beat.ExpiresAt.After(*instance.ExpiresAt) {
+		t.Error("heartbeat did not extend the TTL")
+	}
+	if _, err := r.Heartbeat("combat", "unknown"); !errors.Is(err, ErrInstanceNotFound) {
+		t.Errorf("Heartbeat(unknown instance) = %v, want ErrInstanceNotFound", err)
+	}
+	if _, err := r.Heartbeat("unknown", "combat-2"); !errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("Heartbeat(unknown service) = %v, want ErrServiceNotFound", err)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	r := newTestRegistry(t, "")
+	negative := -1
+
+	registrations := []*Registration{
+		{Service: "combat", URL: "combat:8085"},
+		{Service: "combat", URL: "ftp://combat:8085"},
+		{Service: "combat", URL: "http://combat:8085", TTL: MaxHeartbeatTTL + 1},
+		{Service: "combat", URL: "http://combat:8085", TTL: -1},
+		{Service: "combat", URL: "http://combat:8085", Timeout: -1},
+		{Service: "combat", URL: "http://combat:8085", Retries: &negative},
+	}
+	for _, reg := range registrations {
+		if _, err := r.Register(reg); err == nil {
+			t.Errorf("Register(%+v) accepted", reg)
+		}
+	}
+}
+
+// TestRegisterNewService un service absent de la configuration prend les paramètres de l'enregistrement
+func TestRegisterNewService(t *testing.T) {
+	r := newTestRegistry(t, "")
+	retries := 3
+
+	instance, err := r.Register(&Registration{Service: "auction", URL: "http://auction:8090", Timeout: 7, Retries: &retries})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	service, found := r.Get("auction")
+	if !found || service.Timeout != 7*time.Second || service.Retries != 3 {
+		t.Fatalf("auction = %+v, %v", service, found)
+	}
+
+	if err := r.Deregister("auction", instance.ID); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+	if _, found := r.Get("auction"); found {
+		t.Error("service kept without instances")
+	}
+	if err := r.Deregister("auction", instance.ID); !errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("second Deregister = %v, want ErrServiceNotFound", err)
+	}
+}
+
+func TestExpiredInstances(t *testing.T) {
+	r := newTestRegistry(t, "")
+
+	if _, err := r.Register(&Registration{Service: "auction", ID: "auction-1", URL: "http://auction:8090"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	past := time.Now().Add(-time.Second)
+	r.services["auction"].dynamic["auction-1"].ExpiresAt = &past
+
+	if _, found := r.Get("auction"); found {
+		t.Error("expired instance still served")
+	}
+	if _, err := r.Heartbeat("auction", "auction-1"); !errors.Is(err, ErrInstanceNotFound) {
+		t.Errorf("Heartbeat(expired) = %v, want ErrInstanceNotFound", err)
+	}
+
+	r.removeExpired()
+	if _, exists := r.services["auction"]; exists {
+		t.Error("expired service not removed")
+	}
+}
+
+func TestFileReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "registry.yaml")
+	writeFile := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(`services:
+  combat:
+    strategy: consistent_hash
+    instances:
+      - id: combat-1
+        url: http://combat-1:8085
+      - id: combat-canary
+        url: http://combat-canary:8085
+        version: v2
+`)
+	r := newTestRegistry(t, file)
+
+	// Le fichier remplace les instances de la configuration pour combat, guild est inchangé
+	if urls := instanceURLs(t, r, "combat"); len(urls) != 2 || urls[0] != "http://combat-1:8085" {
+		t.Fatalf("combat instances = %v", urls)
+	}
+	service, _ := r.Get("combat")
+	if service.Strategy != "consistent_hash" || service.Timeout != config.DefaultServiceTimeout*time.Second {
+		t.Errorf("combat = %+v", service)
+	}
+	if service.Instances[1].Version != "v2" || service.Instances[1].Source != SourceFile {
+		t.Errorf("canary = %+v", service.Instances[1])
+	}
+	if urls := instanceURLs(t, r, "guild"); len(urls) != 1 || urls[0] != "http://guild:8086" {
+		t.Errorf("guild instances = %v", urls)
+	}
+
+	// Un rechargement ne touche pas aux enregistrements de l'API
+	if _, err := r.Register(&Registration{Service: "combat", ID: "combat-api", URL: "http://combat-api:8085"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	writeFile(`services: {}`)
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if urls := instanceURLs(t, r, "combat"); len(urls) != 2 || urls[0] != "http://combat:8085" || urls[1] != "http://combat-api:8085" {
+		t.Errorf("combat instances after reload = %v", urls)
+	}
+
+	// Un fichier invalide est refusé et le registre courant conservé
+	writeFile(`services:
+  combat:
+    instances:
+      - url: not-a-url
+`)
+	if err := r.Reload(); err == nil {
+		t.Error("invalid registry file accepted")
+	}
+	if urls := instanceURLs(t, r, "combat"); len(urls) != 2 {
+		t.Errorf("combat instances after invalid reload = %v", urls)
+	}
+}
+
+func TestValidateFileService(t *testing.T) {
+	services := map[string]*fileService{
+		"no instances": {},
+		"bad strategy": {Strategy: "random", Instances: []fileInstance{{URL: "http://a:1"}}},
+		"duplicate":    {Instances: []fileInstance{{ID: "a", URL: "http://a:1"}, {ID: "a", URL: "http://b:1"}}},
+		"negative":     {Retries: -1, Instances: []fileInstance{{URL: "http://a:1"}}},
+	}
+	for name, service := range services {
+		if err := validateFileService(name, service); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	service := &fileService{Instances: []fileInstance{{URL: "http://a:1"}}}
+	if err := validateFileService("combat", service); err != nil {
+		t.Fatalf("valid service refused: %v", err)
+	}
+	if service.Instances[0].ID != "http://a:1" {
+		t.Errorf("default instance ID = %q, want its URL", service.Instances[0].ID)
+	}
+}
@@ -0,0 +1,105 @@
+package revocation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event révocation publiée par le service auth
+// Avec SessionID, seule cette session est révoquée ; sans, tous les tokens de l'utilisateur
+// émis au plus tard à NotBefore le sont.
+type Event struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	SessionID *uuid.UUID `json:"session_id,omitempty"`
+	NotBefore time.Time  `json:"not_before"`
+	Reason    string     `json:"reason"`
+}
+
+// Token token d'accès dont la signature a été vérifiée
+type Token struct {
+	Raw       string
+	UserID    uuid.UUID
+	SessionID uuid.UUID // uuid.Nil pour les tokens sans session
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// userRevocation tokens d'un utilisateur révoqués jusqu'à notBefore
+type userRevocation struct {
+	notBefore time.Time
+	expiresAt time.Time
+}
+
+// Cache révocations reçues, gardées tant qu'un token concerné peut encore être valide
+type Cache struct {
+	retention time.Duration
+
+	mu       sync.RWMutex
+	sessions map[uuid.UUID]time.Time // session -> fin de rétention
+	users    map[uuid.UUID]userRevocation
+}
+
+// NewCache crée un cache gardant chaque révocation pendant retention
+func NewCache(retention time.Duration) *Cache {
+	return &Cache{
+		retention: retention,
+		sessions:  make(map[uuid.UUID]time.Time),
+		users:     make(map[uuid.UUID]userRevocation),
+	}
+}
+
+// Apply enregistre une révocation
+func (c *Cache) Apply(event *Event) {
+	expiresAt := time.Now().Add(c.retention)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if event.SessionID != nil {
+		c.sessions[*event.SessionID] = expiresAt
+		return
+	}
+
+	// La révocation la plus récente d'un utilisateur couvre les précédentes
+	if current, exists := c.users[event.UserID]; exists && current.notBefore.After(event.NotBefore) {
+		return
+	}
+	c.users[event.UserID] = userRevocation{notBefore: event.NotBefore, expiresAt: expiresAt}
+}
+
+// Revoked indique si le token est couvert par une révocation connue
+// iat étant à la seconde, un token émis dans la seconde de la révocation est refusé.
+func (c *Cache) Revoked(token *Token) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if token.SessionID != uuid.Nil {
+		if _, exists := c.sessions[token.SessionID]; exists {
+			return true
+		}
+	}
+
+	revocation, exists := c.users[token.UserID]
+	return exists && !token.IssuedAt.After(revocation.notBefore.Truncate(time.Second))
+}
+
+// Cleanup oublie les révocations dont tous les tokens concernés ont expiré
+func (c *Cache) Cleanup() {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for sessionID, expiresAt := range c.sessions {
+		if now.After(expiresAt) {
+			delete(c.sessions, sessionID)
+		}
+	}
+	for userID, revocation := range c.users {
+		if now.After(revocation.expiresAt) {
+			delete(c.users, userID)
+		}
+	}
+}
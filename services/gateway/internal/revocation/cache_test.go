@@ -0,0 +1,76 @@
+package revocation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCacheSessionRevocation(t *testing.T) {
+	cache := NewCache(time.Hour)
+	userID := uuid.New()
+	revoked := uuid.New()
+	cache.Apply(&Event{UserID: userID, SessionID: &revoked, NotBefore: time.Now(), Reason: "logout"})
+
+	if !cache.Revoked(&Token{UserID: userID, SessionID: revoked, IssuedAt: time.Now()}) {
+		t.Error("token of the revoked session accepted")
+	}
+	// Les autres sessions de l'utilisateur restent valides
+	if cache.Revoked(&Token{UserID: userID, SessionID: uuid.New(), IssuedAt: time.Now().Add(-time.Minute)}) {
+		t.Error("token of another session refused")
+	}
+}
+
+func TestCacheUserRevocation(t *testing.T) {
+	cache := NewCache(time.Hour)
+	userID := uuid.New()
+	notBefore := time.Now().Truncate(time.Second)
+	cache.Apply(&Event{UserID: userID, NotBefore: notBefore, Reason: "ban"})
+
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		want     bool
+	}{
+		{"issued before", notBefore.Add(-time.Minute), true},
+		{"issued in the same second", notBefore, true},
+		{"issued after", notBefore.Add(time.Second), false},
+	}
+	for _, tt := range tests {
+		if got := cache.Revoked(&Token{UserID: userID, IssuedAt: tt.issuedAt}); got != tt.want {
+			t.Errorf("%s: Revoked = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if cache.Revoked(&Token{UserID: uuid.New(), IssuedAt: notBefore.Add(-time.Minute)}) {
+		t.Error("token of another user refused")
+	}
+}
+
+// TestCacheKeepsLatestUserRevocation un événement plus ancien reçu en retard ne recule pas NotBefore
+func TestCacheKeepsLatestUserRevocation(t *testing.T) {
+	cache := NewCache(time.Hour)
+	userID := uuid.New()
+	latest := time.Now().Truncate(time.Second)
+	cache.Apply(&Event{UserID: userID, NotBefore: latest})
+	cache.Apply(&Event{UserID: userID, NotBefore: latest.Add(-time.Hour)})
+
+	if !cache.Revoked(&Token{UserID: userID, IssuedAt: latest.Add(-time.Minute)}) {
+		t.Error("older event overrode the latest revocation")
+	}
+}
+
+func TestCacheCleanup(t *testing.T) {
+	cache := NewCache(-time.Second)
+	userID := uuid.New()
+	sessionID := uuid.New()
+	cache.Apply(&Event{UserID: userID, SessionID: &sessionID, NotBefore: time.Now()})
+	cache.Apply(&Event{UserID: userID, NotBefore: time.Now()})
+
+	cache.Cleanup()
+
+	if cache.Revoked(&Token{UserID: userID, SessionID: sessionID, IssuedAt: time.Now().Add(-time.Minute)}) {
+		t.Error("expired revocations kept after cleanup")
+	}
+}
@@ -0,0 +1,193 @@
+package revocation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gateway/internal/config"
+	"gateway/internal/tracing"
+	"mmorpg/pkg/serviceauth"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+// Résultats des vérifications (label des métriques)
+const (
+	ResultRevoked             = "revoked"
+	ResultAccepted            = "accepted"
+	ResultIntrospectedActive  = "introspected_active"
+	ResultIntrospectedRevoked = "introspected_revoked"
+	ResultIntrospectionError  = "introspection_error"
+)
+
+// ErrUnavailable l'état du token ne peut pas être vérifié (service auth injoignable)
+var ErrUnavailable = errors.New("token revocation status unavailable")
+
+// Checker refuse les tokens d'accès révoqués avant leur expiration
+// Les révocations publiées par le service auth sont gardées en cache. Le cache n'est complet
+// qu'après avoir écouté NATS pendant toute la durée de vie d'un token : avant (démarrage,
+// reconnexion, NATS absent), les tokens inconnus du cache sont vérifiés par introspection.
+type Checker struct {
+	config       *config.RevocationConfig
+	natsConn     *nats.Conn
+	cache        *Cache
+	introspector *Introspector
+
+	mu           sync.Mutex
+	subscription *nats.Subscription
+	listenSince  time.Time // début de l'écoute ininterrompue, zéro si pas d'écoute
+	reconnects   uint64
+
+	done chan struct{}
+}
+
+// NewChecker crée le vérificateur de révocations
+// natsConn est optionnel : sans NATS, chaque token est vérifié par introspection (avec cache).
+func NewChecker(cfg *config.RevocationConfig, authURL string, natsConn *nats.Conn) *Checker {
+	tokens := serviceauth.NewTokenSource(authURL, cfg.ClientID, cfg.ClientSecret, cfg.IntrospectionTimeout)
+
+	return &Checker{
+		config:       cfg,
+		natsConn:     natsConn,
+		cache:        NewCache(cfg.TokenLifetime),
+		introspector: NewIntrospector(authURL, cfg.IntrospectionTTL, cfg.IntrospectionTimeout, tokens),
+		done:         make(chan struct{}),
+	}
+}
+
+// Start s'abonne aux révocations du service auth et lance le nettoyage périodique
+func (c *Checker) Start() error {
+	go c.cleanupLoop()
+
+	if c.natsConn == nil || c.config.Subject == "" {
+		logrus.Info("NATS not available, token revocations are checked by introspection")
+		return nil
+	}
+
+	subscription, err := c.natsConn.Subscribe(c.config.Subject, c.handleEvent)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.subscription = subscription
+	c.listenSince = time.Now()
+	c.reconnects = c.natsConn.Stats().Reconnects
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Close arrête l'écoute des révocations
+func (c *Checker) Close() {
+	close(c.done)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.subscription != nil {
+		if err := c.subscription.Unsubscribe(); err != nil {
+			logrus.WithError(err).Debug("Failed to unsubscribe from token revocations")
+		}
+		c.subscription = nil
+	}
+}
+
+// Revoked indique si un token d'accès a été révoqué
+// Si le service auth est injoignable pendant que le cache est froid, le token est accepté
+// (fail open) ou ErrUnavailable est retournée, selon la configuration.
+func (c *Checker) Revoked(ctx context.Context, token *Token) (bool, error) {
+	if c.cache.Revoked(token) {
+		checks.WithLabelValues(ResultRevoked).Inc()
+		return true, nil
+	}
+
+	if c.warm() {
+		checks.WithLabelValues(ResultAccepted).Inc()
+		return false, nil
+	}
+
+	active, cached, err := c.introspector.Active(ctx, token)
+	if err != nil {
+		checks.WithLabelValues(ResultIntrospectionError).Inc()
+		logrus.WithContext(ctx).WithError(err).WithField("user_id", token.UserID).Warn("Token introspection failed")
+		if c.config.FailOpen {
+			return false, nil
+		}
+		return false, ErrUnavailable
+	}
+
+	if !cached {
+		if active {
+			checks.WithLabelValues(ResultIntrospectedActive).Inc()
+		} else {
+			checks.WithLabelValues(ResultIntrospectedRevoked).Inc()
+		}
+	}
+	return !active, nil
+}
+
+// warm indique si le cache a reçu toutes les révocations encore utiles
+// Une déconnexion de NATS a pu faire perdre des événements : l'écoute recommence à zéro.
+func (c *Checker) warm() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.subscription == nil {
+		return false
+	}
+
+	if !c.natsConn.IsConnected() {
+		c.listenSince = time.Time{}
+		return false
+	}
+	if reconnects := c.natsConn.Stats().Reconnects; c.listenSince.IsZero() || reconnects != c.reconnects {
+		c.listenSince = time.Now()
+		c.reconnects = reconnects
+		return false
+	}
+
+	return time.Since(c.listenSince) >= c.config.TokenLifetime
+}
+
+// handleEvent enregistre une révocation reçue du service auth
+func (c *Checker) handleEvent(msg *nats.Msg) {
+	ctx, span := tracing.StartConsumerSpan(msg)
+	defer span.End()
+
+	var event Event
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		logrus.WithContext(ctx).WithError(err).Warn("Invalid token revocation event")
+		return
+	}
+
+	c.cache.Apply(&event)
+
+	scope := "user"
+	fields := logrus.Fields{"user_id": event.UserID, "reason": event.Reason}
+	if event.SessionID != nil {
+		scope = "session"
+		fields["session_id"] = *event.SessionID
+	}
+	events.WithLabelValues(scope).Inc()
+	logrus.WithContext(ctx).WithFields(fields).Info("Token revocation received")
+}
+
+// cleanupLoop oublie périodiquement les révocations et réponses expirées
+func (c *Checker) cleanupLoop() {
+	ticker := time.NewTicker(c.config.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.cache.Cleanup()
+			c.introspector.Cleanup()
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package revocation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gateway/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testServiceJWT = "service-token"
+
+// newTestAuth serveur auth de test : émet les tokens de service et répond aux introspections
+// Seul le token "active" est actif ; status force le code des réponses d'introspection.
+func newTestAuth(t *testing.T, status *atomic.Int32) (string, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/services/token", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"access_token":"` + testServiceJWT + `","expires_in":300}}`))
+	})
+	mux.HandleFunc("POST "+introspectPath, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("Authorization") != "Bearer "+testServiceJWT {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if code := status.Load(); code != 0 {
+			w.WriteHeader(int(code))
+			return
+		}
+		var body struct {
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]bool{"active": body.Token == "active"}})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server.URL, &calls
+}
+
+// newTestChecker vérificateur sans NATS : le cache reste froid, l'introspection est toujours utilisée
+func newTestChecker(t *testing.T, failOpen bool) (*Checker, *atomic.Int32, *atomic.Int32) {
+	t.Helper()
+
+	var status atomic.Int32
+	authURL, calls := newTestAuth(t, &status)
+	cfg := &config.RevocationConfig{
+		TokenLifetime:        time.Hour,
+		IntrospectionTimeout: time.Second,
+		IntrospectionTTL:     time.Minute,
+		ClientID:             "gateway",
+		ClientSecret:         "secret",
+		FailOpen:             failOpen,
+		CleanupInterval:      time.Minute,
+	}
+	return NewChecker(cfg, authURL, nil), calls, &status
+}
+
+func TestCheckerIntrospectsWhenCold(t *testing.T) {
+	checker, calls, _ := newTestChecker(t, false)
+
+	revoked, err := checker.Revoked(context.Background(), &Token{Raw: "active", UserID: uuid.New()})
+	if err != nil || revoked {
+		t.Fatalf("active token: Revoked = %v, %v; want false", revoked, err)
+	}
+	revoked, err = checker.Revoked(context.Background(), &Token{Raw: "revoked", UserID: uuid.New()})
+	if err != nil || !revoked {
+		t.Fatalf("revoked token: Revoked = %v, %v; want true", revoked, err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("introspection called %d times, want 2", calls.Load())
+	}
+}
+
+// TestCheckerIntrospectionCached les réponses sont gardées pendant IntrospectionTTL
+func TestCheckerIntrospectionCached(t *testing.T) {
+	checker, calls, _ := newTestChecker(t, false)
+	token := &Token{Raw: "active", UserID: uuid.New()}
+
+	for range 3 {
+		if _, err := checker.Revoked(context.Background(), token); err != nil {
+			t.Fatalf("Revoked: %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("introspection called %d times, want 1", calls.Load())
+	}
+}
+
+// TestCheckerIntrospectionBoundedByExpiry une réponse n'est pas gardée après l'expiration du token
+func TestCheckerIntrospectionBoundedByExpiry(t *testing.T) {
+	checker, calls, _ := newTestChecker(t, false)
+	token := &Token{Raw: "active", UserID: uuid.New(), ExpiresAt: time.Now().Add(-time.Second)}
+
+	for range 2 {
+		if _, err := checker.Revoked(context.Background(), token); err != nil {
+			t.Fatalf("Revoked: %v", err)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Errorf("introspection called %d times, want 2", calls.Load())
+	}
+}
+
+func TestCheckerCacheRevocationSkipsIntrospection(t *testing.T) {
+	checker, calls, _ := newTestChecker(t, false)
+	userID := uuid.New()
+	checker.cache.Apply(&Event{UserID: userID, NotBefore: time.Now()})
+
+	revoked, err := checker.Revoked(context.Background(), &Token{Raw: "active", UserID: userID, IssuedAt: time.Now().Add(-time.Minute)})
+	if err != nil || !revoked {
+		t.Fatalf("Revoked = %v, %v; want true", revoked, err)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("introspection called %d times, want 0", calls.Load())
+	}
+}
+
+func TestCheckerAuthUnavailable(t *testing.T) {
+	token := &Token{Raw: "active", UserID: uuid.New()}
+
+	checker, _, status := newTestChecker(t, false)
+	status.Store(http.StatusServiceUnavailable)
+	if _, err := checker.Revoked(context.Background(), token); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("fail closed: error = %v, want ErrUnavailable", err)
+	}
+
+	checker, _, status = newTestChecker(t, true)
+	status.Store(http.StatusServiceUnavailable)
+	revoked, err := checker.Revoked(context.Background(), token)
+	if err != nil || revoked {
+		t.Errorf("fail open: Revoked = %v, %v; want false", revoked, err)
+	}
+}
@@ -0,0 +1,146 @@
+package revocation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gateway/internal/tracing"
+	"mmorpg/pkg/serviceauth"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// introspectPath route interne du service auth donnant l'état d'un token d'accès
+const introspectPath = "/api/v1/services/introspect"
+
+// introspectionResponse réponse du service auth
+type introspectionResponse struct {
+	Data struct {
+		Active bool `json:"active"`
+	} `json:"data"`
+}
+
+// introspectionResult réponse gardée en cache
+type introspectionResult struct {
+	active    bool
+	expiresAt time.Time
+}
+
+// Introspector demande l'état des tokens d'accès au service auth et garde les réponses
+// quelques secondes
+type Introspector struct {
+	url    string
+	ttl    time.Duration
+	tokens *serviceauth.TokenSource
+	client *http.Client
+
+	mu      sync.Mutex
+	results map[string]introspectionResult // empreinte du token -> réponse
+}
+
+// NewIntrospector crée le client d'introspection du service auth
+func NewIntrospector(authURL string, ttl, timeout time.Duration, tokens *serviceauth.TokenSource) *Introspector {
+	return &Introspector{
+		url:     strings.TrimRight(authURL, "/") + introspectPath,
+		ttl:     ttl,
+		tokens:  tokens,
+		client:  &http.Client{Timeout: timeout},
+		results: make(map[string]introspectionResult),
+	}
+}
+
+// Active indique si le token est encore actif ; cached vaut true si la réponse vient du cache
+func (i *Introspector) Active(ctx context.Context, token *Token) (active, cached bool, err error) {
+	key := tokenKey(token.Raw)
+
+	i.mu.Lock()
+	result, exists := i.results[key]
+	i.mu.Unlock()
+	if exists && time.Now().Before(result.expiresAt) {
+		return result.active, true, nil
+	}
+
+	active, err = i.introspect(ctx, token.Raw)
+	if err != nil {
+		return false, false, err
+	}
+
+	// Une réponse n'est pas gardée au-delà de l'expiration du token
+	expiresAt := time.Now().Add(i.ttl)
+	if !token.ExpiresAt.IsZero() && token.ExpiresAt.Before(expiresAt) {
+		expiresAt = token.ExpiresAt
+	}
+
+	i.mu.Lock()
+	i.results[key] = introspectionResult{active: active, expiresAt: expiresAt}
+	i.mu.Unlock()
+
+	return active, false, nil
+}
+
+// Cleanup oublie les réponses expirées
+func (i *Introspector) Cleanup() {
+	now := time.Now()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for key, result := range i.results {
+		if now.After(result.expiresAt) {
+			delete(i.results, key)
+		}
+	}
+}
+
+// introspect appelle le service auth
+func (i *Introspector) introspect(ctx context.Context, rawToken string) (bool, error) {
+	serviceToken, err := i.tokens.Token(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get service token: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]string{"token": rawToken})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode introspection request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+serviceToken)
+
+	ctx, span := tracing.StartClientSpan(ctx, "auth", req)
+	resp, err := i.client.Do(req.WithContext(ctx))
+	tracing.EndClientSpan(span, resp, err)
+	if err != nil {
+		return false, fmt.Errorf("introspection request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// Token de service refusé (secret renouvelé) : un nouveau sera demandé au prochain appel
+		i.tokens.Invalidate()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("auth service returned status %d for introspection", resp.StatusCode)
+	}
+
+	var result introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+	return result.Data.Active, nil
+}
+
+// tokenKey empreinte d'un token : les tokens eux-mêmes ne sont pas gardés en mémoire
+func tokenKey(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,28 @@
+package revocation
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Métriques Prometheus de la révocation des tokens
+var (
+	events = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_revocation_events_total",
+			Help: "Total number of revocation events received from the auth service by scope (session, user)",
+		},
+		[]string{"scope"},
+	)
+
+	checks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_revocation_checks_total",
+			Help: "Total number of access token revocation checks by result (revoked, accepted, introspected_active, introspected_revoked, introspection_error)",
+		},
+		[]string{"result"},
+	)
+)
+
+// InitMetrics initialize les métriques Prometheus de la révocation des tokens
+func InitMetrics() {
+	prometheus.MustRegister(events)
+	prometheus.MustRegister(checks)
+}
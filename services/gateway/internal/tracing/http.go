@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Header de réponse portant l'ID de trace (à joindre aux rapports de bug)
+const TraceIDHeader = "X-Trace-ID"
+
+// Statut HTTP à partir duquel un span est en erreur
+const serverErrorStatus = 500
+
+// Middleware ouvre le span serveur de chaque requête HTTP
+// Un traceparent reçu est repris : la trace d'un client instrumenté continue dans le gateway.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		ctx, span := Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+				attribute.String("request.id", c.GetHeader("X-Request-ID")),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		if spanContext := span.SpanContext(); spanContext.IsValid() {
+			c.Header(TraceIDHeader, spanContext.TraceID().String())
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if userID, exists := c.Get("user_id"); exists {
+			span.SetAttributes(attribute.String("enduser.id", toString(userID)))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+		if status >= serverErrorStatus {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// StartClientSpan ouvre le span d'un appel vers un service et injecte son contexte
+// dans les headers de la requête
+func StartClientSpan(ctx context.Context, service string, req *http.Request) (context.Context, trace.Span) {
+	ctx, span := Tracer().Start(ctx, req.Method+" "+service,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.String()),
+			semconv.ServerAddress(req.URL.Hostname()),
+			attribute.String("peer.service", service),
+		),
+	)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return ctx, span
+}
+
+// EndClientSpan termine le span d'un appel vers un service
+func EndClientSpan(span trace.Span, resp *http.Response, err error) {
+	defer span.End()
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= serverErrorStatus {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+}
+
+// InjectHeaders ajoute le contexte de trace de ctx aux headers d'une requête sortante
+func InjectHeaders(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// toString formate une valeur du contexte Gin (uuid.UUID, string)
+func toString(value interface{}) string {
+	if stringer, ok := value.(interface{ String() string }); ok {
+		return stringer.String()
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribut messaging.system des spans NATS
+var natsSystem = attribute.String("messaging.system", "nats")
+
+// Publish publie un message NATS avec le contexte de trace dans ses headers
+// Sans support des headers par le serveur, le message part sans contexte.
+func Publish(ctx context.Context, nc *nats.Conn, subject string, data []byte) error {
+	ctx, span := Tracer().Start(ctx, "publish "+operationSubject(subject),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(natsSystem, semconv.MessagingDestinationName(subject)),
+	)
+	defer span.End()
+
+	msg := &nats.Msg{Subject: subject, Data: data}
+	if nc.HeadersSupported() {
+		msg.Header = nats.Header{}
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(msg.Header))
+	}
+
+	if err := nc.PublishMsg(msg); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+// StartConsumerSpan ouvre le span de traitement d'un message NATS reçu
+// Le span est l'enfant de celui du producteur quand le message porte un traceparent.
+func StartConsumerSpan(msg *nats.Msg) (context.Context, trace.Span) {
+	ctx := context.Background()
+	if msg.Header != nil {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(msg.Header))
+	}
+	return Tracer().Start(ctx, "process "+operationSubject(msg.Subject),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(natsSystem, semconv.MessagingDestinationName(msg.Subject)),
+	)
+}
+
+// operationSubject deux premiers tokens du sujet : noms de spans à faible cardinalité
+// (cache.invalidate.guild.<id> -> cache.invalidate)
+func operationSubject(subject string) string {
+	dots := 0
+	for i := 0; i < len(subject); i++ {
+		if subject[i] == '.' {
+			dots++
+			if dots == 2 {
+				return subject[:i]
+			}
+		}
+	}
+	return subject
+}
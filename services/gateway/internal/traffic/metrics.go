@@ -0,0 +1,35 @@
+package traffic
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Résultats d'une requête (métriques)
+const (
+	outcomeSuccess = "success"
+	outcomeError   = "error" // erreur réseau ou 5xx
+)
+
+// Métriques Prometheus par version de service
+var (
+	versionRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_version_requests_total",
+			Help: "Total number of proxied requests per service version",
+		},
+		[]string{"service", "version", "outcome"},
+	)
+
+	versionLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "gateway_version_request_duration_seconds",
+			Help:    "Proxied request duration per service version",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"service", "version"},
+	)
+)
+
+// InitMetrics initialize les métriques Prometheus de la répartition entre versions
+func InitMetrics() {
+	prometheus.MustRegister(versionRequests)
+	prometheus.MustRegister(versionLatency)
+}
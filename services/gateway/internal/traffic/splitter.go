@@ -0,0 +1,319 @@
+package traffic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gateway/internal/config"
+	"gateway/internal/tracing"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrRuleNotFound aucune règle pour le service
+var ErrRuleNotFound = errors.New("traffic rule not found")
+
+// Rule répartition active d'un service entre ses versions
+type Rule struct {
+	config.TrafficRule
+	UpdatedAt time.Time `json:"updated_at"`
+
+	canaries []string // versions canary triées : ordre des tranches d'affectation
+}
+
+// newRule compile une règle validée
+func newRule(rule config.TrafficRule, updatedAt time.Time) *Rule {
+	compiled := &Rule{TrafficRule: rule, UpdatedAt: updatedAt}
+	for version := range rule.Weights {
+		compiled.canaries = append(compiled.canaries, version)
+	}
+	sort.Strings(compiled.canaries)
+	return compiled
+}
+
+// Weight poids d'une version dans la règle (la référence reçoit le reste)
+func (r *Rule) Weight(version string) int {
+	if version != r.Baseline {
+		return r.Weights[version]
+	}
+	weight := config.MaxPercent
+	for _, canaryWeight := range r.Weights {
+		weight -= canaryWeight
+	}
+	return weight
+}
+
+// assign retourne la version d'un client
+// Le client est placé dans une tranche de 0 à 99 par hachage de sa clé : il garde sa version
+// tant que les poids ne changent pas. Les canaries occupent les tranches hautes, si bien
+// qu'augmenter le poids d'un canary n'y fait basculer que des clients de la référence.
+func (r *Rule) assign(service, key string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(service + "|" + key))
+	bucket := int(hash.Sum32() % config.MaxPercent)
+
+	threshold := config.MaxPercent
+	for _, version := range r.canaries {
+		threshold -= r.Weights[version]
+		if bucket >= threshold {
+			return version
+		}
+	}
+	return r.Baseline
+}
+
+// ruleUpdate changement de règle diffusé aux autres réplicas du gateway
+type ruleUpdate struct {
+	Origin  string              `json:"origin"`
+	Service string              `json:"service"`
+	Rule    *config.TrafficRule `json:"rule,omitempty"` // nil : règle supprimée
+}
+
+// Splitter répartit le trafic des services entre leurs versions (canary)
+type Splitter struct {
+	config   *config.TrafficConfig
+	natsConn *nats.Conn
+	origin   string
+
+	mu    sync.RWMutex
+	rules map[string]*Rule
+	stats map[string]*serviceStats
+
+	subscription *nats.Subscription
+}
+
+// NewSplitter crée le répartiteur à partir des règles de la configuration
+// natsConn est optionnel : sans NATS, les changements de règles restent locaux au réplica.
+func NewSplitter(cfg *config.TrafficConfig, natsConn *nats.Conn) *Splitter {
+	s := &Splitter{
+		config:   cfg,
+		natsConn: natsConn,
+		origin:   uuid.New().String(),
+		rules:    make(map[string]*Rule),
+		stats:    make(map[string]*serviceStats),
+	}
+
+	now := time.Now()
+	for service, rule := range cfg.Rules {
+		s.rules[service] = newRule(rule, now)
+		s.stats[service] = newServiceStats(now)
+	}
+
+	return s
+}
+
+// Start s'abonne aux changements de règles faits sur les autres réplicas
+func (s *Splitter) Start() error {
+	if s.natsConn == nil || s.config.Subject == "" {
+		return nil
+	}
+
+	subscription, err := s.natsConn.Subscribe(s.config.Subject, func(msg *nats.Msg) {
+		ctx, span := tracing.StartConsumerSpan(msg)
+		defer span.End()
+
+		var update ruleUpdate
+		if err := json.Unmarshal(msg.Data, &update); err != nil {
+			logrus.WithContext(ctx).WithError(err).Warn("Invalid traffic rule update")
+			return
+		}
+		if update.Origin == s.origin {
+			return
+		}
+		if update.Rule == nil {
+			s.deleteRule(update.Service)
+		} else if err := config.ValidateTrafficRule(update.Rule); err != nil {
+			logrus.WithContext(ctx).WithError(err).WithField("service", update.Service).Warn("Invalid traffic rule update")
+			return
+		} else {
+			s.applyRule(update.Service, *update.Rule)
+		}
+
+		logrus.WithContext(ctx).WithField("service", update.Service).Info("Traffic rule updated by another gateway replica")
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.subscription = subscription
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Close arrête la réception des changements de règles
+func (s *Splitter) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subscription != nil {
+		if err := s.subscription.Unsubscribe(); err != nil {
+			logrus.WithError(err).Debug("Failed to unsubscribe from traffic rule updates")
+		}
+		s.subscription = nil
+	}
+}
+
+// Override retourne la version imposée par le client (header, sinon cookie), vide sinon
+func (s *Splitter) Override(r *http.Request) string {
+	if s.config.Header != "" {
+		if version := r.Header.Get(s.config.Header); version != "" {
+			return version
+		}
+	}
+	if s.config.Cookie != "" {
+		if cookie, err := r.Cookie(s.config.Cookie); err == nil {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// Version retourne la version d'un service vers laquelle envoyer une requête
+// override : version imposée par le client ; key : ID utilisateur, sinon IP du client.
+// Vide : pas de règle pour le service, toutes les instances reçoivent du trafic.
+func (s *Splitter) Version(service, key, override string) string {
+	if override != "" {
+		return override
+	}
+
+	s.mu.RLock()
+	rule, exists := s.rules[service]
+	s.mu.RUnlock()
+
+	if !exists {
+		return ""
+	}
+	return rule.assign(service, key)
+}
+
+// SetRule remplace la règle d'un service et la diffuse aux autres réplicas
+func (s *Splitter) SetRule(ctx context.Context, service string, rule config.TrafficRule) (*Rule, error) {
+	if err := config.ValidateTrafficRule(&rule); err != nil {
+		return nil, err
+	}
+
+	applied := s.applyRule(service, rule)
+	s.publish(ctx, ruleUpdate{Origin: s.origin, Service: service, Rule: &rule})
+
+	logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"service":  service,
+		"baseline": rule.Baseline,
+		"weights":  rule.Weights,
+	}).Info("Traffic rule updated")
+
+	return applied, nil
+}
+
+// DeleteRule supprime la règle d'un service : toutes ses instances reçoivent à nouveau du trafic
+func (s *Splitter) DeleteRule(ctx context.Context, service string) error {
+	if !s.deleteRule(service) {
+		return ErrRuleNotFound
+	}
+	s.publish(ctx, ruleUpdate{Origin: s.origin, Service: service})
+
+	logrus.WithContext(ctx).WithField("service", service).Info("Traffic rule deleted")
+	return nil
+}
+
+// Record enregistre le résultat d'une requête servie par une version
+func (s *Splitter) Record(service, version string, status int, err error, latency time.Duration) {
+	failed := err != nil || status >= http.StatusInternalServerError
+
+	outcome := outcomeSuccess
+	if failed {
+		outcome = outcomeError
+	}
+	versionRequests.WithLabelValues(service, version, outcome).Inc()
+	versionLatency.WithLabelValues(service, version).Observe(latency.Seconds())
+
+	s.serviceStats(service).record(version, failed, latency)
+}
+
+// Status retourne les règles et les statistiques par version de chaque service, triés par nom
+func (s *Splitter) Status() []ServiceStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]ServiceStatus, 0, len(s.stats))
+	for service, stats := range s.stats {
+		statuses = append(statuses, stats.status(service, s.rules[service]))
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Service < statuses[j].Service })
+	return statuses
+}
+
+// Méthodes privées
+
+// applyRule installe une règle et remet à zéro les statistiques du service
+func (s *Splitter) applyRule(service string, rule config.TrafficRule) *Rule {
+	now := time.Now()
+	compiled := newRule(rule, now)
+
+	s.mu.Lock()
+	s.rules[service] = compiled
+	s.stats[service] = newServiceStats(now)
+	s.mu.Unlock()
+
+	return compiled
+}
+
+// deleteRule supprime la règle d'un service, false si elle n'existait pas
+func (s *Splitter) deleteRule(service string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.rules[service]; !exists {
+		return false
+	}
+	delete(s.rules, service)
+	s.stats[service] = newServiceStats(time.Now())
+	return true
+}
+
+// serviceStats retourne les statistiques d'un service en les créant si besoin
+func (s *Splitter) serviceStats(service string) *serviceStats {
+	s.mu.RLock()
+	stats, exists := s.stats[service]
+	s.mu.RUnlock()
+
+	if exists {
+		return stats
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stats, exists = s.stats[service]; !exists {
+		stats = newServiceStats(time.Now())
+		s.stats[service] = stats
+	}
+	return stats
+}
+
+// publish diffuse un changement de règle (sans NATS, le changement reste local)
+func (s *Splitter) publish(ctx context.Context, update ruleUpdate) {
+	if s.natsConn == nil || s.config.Subject == "" {
+		return
+	}
+
+	data, err := json.Marshal(update)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode traffic rule update")
+		return
+	}
+	if err := tracing.Publish(ctx, s.natsConn, s.config.Subject, data); err != nil {
+		logrus.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"service": update.Service,
+			"subject": s.config.Subject,
+		}).Warn("Failed to publish traffic rule update")
+	}
+}
@@ -0,0 +1,129 @@
+package traffic
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// millisecondsPerSecond conversion des latences moyennes exposées
+const millisecondsPerSecond = 1000
+
+// VersionStatus trafic et erreurs d'une version depuis le dernier changement de règle
+type VersionStatus struct {
+	Version      string  `json:"version"`
+	Weight       *int    `json:"weight,omitempty"` // poids de la règle, absent sans règle
+	Requests     uint64  `json:"requests"`
+	Errors       uint64  `json:"errors"`
+	ErrorRate    float64 `json:"error_rate"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+// ServiceStatus règle et versions d'un service
+type ServiceStatus struct {
+	Service  string          `json:"service"`
+	Rule     *Rule           `json:"rule,omitempty"`
+	Since    time.Time       `json:"since"`
+	Versions []VersionStatus `json:"versions"`
+}
+
+// versionStats compteurs d'une version (accès atomiques)
+type versionStats struct {
+	requests atomic.Uint64
+	errors   atomic.Uint64
+	latency  atomic.Int64 // nanosecondes cumulées
+}
+
+// serviceStats compteurs des versions d'un service, remis à zéro à chaque changement de règle
+type serviceStats struct {
+	since time.Time
+
+	mu       sync.RWMutex
+	versions map[string]*versionStats
+}
+
+// newServiceStats crée des compteurs vides
+func newServiceStats(since time.Time) *serviceStats {
+	return &serviceStats{
+		since:    since,
+		versions: make(map[string]*versionStats),
+	}
+}
+
+// record compte une requête servie par une version
+func (s *serviceStats) record(version string, failed bool, latency time.Duration) {
+	stats := s.version(version)
+	stats.requests.Add(1)
+	stats.latency.Add(int64(latency))
+	if failed {
+		stats.errors.Add(1)
+	}
+}
+
+// version retourne les compteurs d'une version en les créant si besoin
+func (s *serviceStats) version(version string) *versionStats {
+	s.mu.RLock()
+	stats, exists := s.versions[version]
+	s.mu.RUnlock()
+
+	if exists {
+		return stats
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stats, exists = s.versions[version]; !exists {
+		stats = &versionStats{}
+		s.versions[version] = stats
+	}
+	return stats
+}
+
+// status expose les compteurs, avec les versions de la règle même sans trafic
+func (s *serviceStats) status(service string, rule *Rule) ServiceStatus {
+	status := ServiceStatus{
+		Service: service,
+		Rule:    rule,
+		Since:   s.since,
+	}
+
+	s.mu.RLock()
+	versions := make(map[string]*versionStats, len(s.versions))
+	for version, stats := range s.versions {
+		versions[version] = stats
+	}
+	s.mu.RUnlock()
+
+	if rule != nil {
+		if _, exists := versions[rule.Baseline]; !exists {
+			versions[rule.Baseline] = &versionStats{}
+		}
+		for _, version := range rule.canaries {
+			if _, exists := versions[version]; !exists {
+				versions[version] = &versionStats{}
+			}
+		}
+	}
+
+	for version, stats := range versions {
+		versionStatus := VersionStatus{
+			Version:  version,
+			Requests: stats.requests.Load(),
+			Errors:   stats.errors.Load(),
+		}
+		if versionStatus.Requests > 0 {
+			requests := float64(versionStatus.Requests)
+			versionStatus.ErrorRate = float64(versionStatus.Errors) / requests
+			versionStatus.AvgLatencyMs = time.Duration(stats.latency.Load()).Seconds() * millisecondsPerSecond / requests
+		}
+		if rule != nil {
+			weight := rule.Weight(version)
+			versionStatus.Weight = &weight
+		}
+		status.Versions = append(status.Versions, versionStatus)
+	}
+
+	sort.Slice(status.Versions, func(i, j int) bool { return status.Versions[i].Version < status.Versions[j].Version })
+	return status
+}
@@ -18,6 +18,10 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
+	"mmorpg/pkg/cacheinvalidation"
+	"mmorpg/pkg/jwks"
+	"mmorpg/pkg/serviceauth"
+	"mmorpg/pkg/tracing"
 )
 
 const (
@@ -64,7 +68,8 @@ func setupDatabase(cfg *config.Config) (*sql.DB, error) {
 		cfg.Database.Host, cfg.Database.Port, cfg.Database.User,
 		cfg.Database.Password, cfg.Database.DBName, cfg.Database.SSLMode)
 
-	db, err := sql.Open("postgres", dsn)
+	// Requêtes SQL tracées dans le span de la requête HTTP
+	db, err := tracing.OpenDB("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("erreur lors de l'ouverture de la base de données: %w", err)
 	}
@@ -86,9 +91,15 @@ func setupDatabase(cfg *config.Config) (*sql.DB, error) {
 func setupRouter(
 	guildHandler *handlers.GuildHandler,
 	guildMemberHandler *handlers.GuildMemberHandler,
+	keys *jwks.KeySet,
+	invalidations *cacheinvalidation.Publisher,
+	cfg *config.Config,
 ) *gin.Engine {
 	router := gin.Default()
 
+	// Span de chaque requête, enfant de celui du gateway
+	router.Use(tracing.Middleware())
+
 	// Middleware de métriques
 	router.Use(func(c *gin.Context) {
 		start := time.Now()
@@ -131,6 +142,7 @@ func setupRouter(
 	{
 		// Routes des guildes
 		guilds := v1.Group("/guilds")
+		guilds.Use(cacheinvalidation.OnWrite(invalidations, "guild.list", "guild.{id}"))
 		{
 			guilds.POST("/", guildHandler.CreateGuild)
 			guilds.GET("/", guildHandler.ListGuilds)
@@ -143,6 +155,7 @@ func setupRouter(
 
 		// Routes des membres de guilde (séparées pour éviter les conflits)
 		members := v1.Group("/guild-members")
+		members.Use(cacheinvalidation.OnWrite(invalidations, "guild.{guild_id}"))
 		{
 			members.POST("/:guild_id/join", guildMemberHandler.JoinGuild)
 			members.GET("/:guild_id", guildMemberHandler.GetMembers)
@@ -151,11 +164,46 @@ func setupRouter(
 			members.DELETE("/:guild_id/:player_id", guildMemberHandler.KickMember)
 			members.DELETE("/:guild_id/leave", guildMemberHandler.LeaveGuild)
 		}
+
+		// Routes pour les autres services (appels internes)
+		services := v1.Group("/services")
+		services.Use(serviceauth.Middleware(serviceauth.NewVerifier(keys, cfg.Auth.ServiceTokenIssuer)))
+		{
+			services.GET("/guild-members/:guild_id/:player_id", serviceauth.RequireScope(serviceauth.ScopeGuildRead), guildMemberHandler.GetMember)
+		}
 	}
 
 	return router
 }
 
+// setupKeySet charge les clés publiques du service auth et les recharge jusqu'à l'arrêt
+func setupKeySet(lifecycle fx.Lifecycle, cfg *config.Config) *jwks.KeySet {
+	keys := jwks.NewKeySet(cfg.Auth.JWKSURL, cfg.Auth.JWKSRefresh)
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			keys.Start()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			keys.Close()
+			return nil
+		},
+	})
+	return keys
+}
+
+// setupInvalidations publie les invalidations du cache du gateway jusqu'à l'arrêt
+func setupInvalidations(lifecycle fx.Lifecycle, cfg *config.Config) *cacheinvalidation.Publisher {
+	publisher := cacheinvalidation.NewPublisher(cfg.NATS.URL, "guild")
+	lifecycle.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			publisher.Close()
+			return nil
+		},
+	})
+	return publisher
+}
+
 // startServer démarre le serveur HTTP
 func startServer(lifecycle fx.Lifecycle, router *gin.Engine, cfg *config.Config, logger *logrus.Logger) {
 	server := &http.Server{
@@ -196,12 +244,21 @@ func main() {
 	logger.SetOutput(os.Stdout)
 	logger.SetLevel(logrus.InfoLevel)
 
+	// Tracing distribué (contexte reçu du gateway, export OTLP ou local)
+	shutdownTracing, err := tracing.Init(&cfg.Tracing, "guild", "1.0.0", "")
+	if err != nil {
+		log.Fatalf("Erreur lors de l'initialisation du tracing: %v", err)
+	}
+	logger.AddHook(tracing.LogHook{})
+
 	// Créer l'application FX
 	app := fx.New(
 		fx.Provide(
 			func() *config.Config { return cfg },
 			func() *logrus.Logger { return logger },
 			setupDatabase,
+			setupKeySet,
+			setupInvalidations,
 			// Repositories
 			repository.NewGuildRepository,
 			repository.NewGuildMemberRepository,
@@ -225,6 +282,9 @@ func main() {
 			setupRouter,
 		),
 		fx.Invoke(startServer),
+		fx.Invoke(func(lifecycle fx.Lifecycle) {
+			lifecycle.Append(fx.Hook{OnStop: shutdownTracing})
+		}),
 	)
 
 	// Démarrer l'application
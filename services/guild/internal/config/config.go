@@ -2,13 +2,25 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
+
+	"mmorpg/pkg/tracing"
 )
 
+// DefaultTracingSampleRatio part des nouvelles traces conservées par défaut
+const DefaultTracingSampleRatio = 1.0
+
+// DefaultJWKSRefresh intervalle de rechargement des clés publiques du service auth
+const DefaultJWKSRefresh = 5 * time.Minute
+
 // Config contient la configuration du service guild
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	Auth     AuthConfig
+	NATS     NATSConfig
+	Tracing  TracingConfig
 }
 
 // ServerConfig contient la configuration du serveur
@@ -30,8 +42,20 @@ type DatabaseConfig struct {
 // AuthConfig contient la configuration d'authentification
 type AuthConfig struct {
 	JWTSecret string
+	// Clés publiques du service auth, pour vérifier les tokens de service des routes /services
+	JWKSURL            string
+	JWKSRefresh        time.Duration
+	ServiceTokenIssuer string
 }
 
+// NATSConfig contient la configuration de la publication des invalidations du cache du gateway
+type NATSConfig struct {
+	URL string
+}
+
+// TracingConfig configuration du tracing distribué, commune à tous les services
+type TracingConfig = tracing.Config
+
 // Load charge la configuration depuis les variables d'environnement
 func Load() *Config {
 	return &Config{
@@ -48,7 +72,21 @@ func Load() *Config {
 			SSLMode:  getEnv("GUILD_DB_SSLMODE", "disable"),
 		},
 		Auth: AuthConfig{
-			JWTSecret: getEnv("JWT_SECRET", "your-secret-key"),
+			JWTSecret:          getEnv("JWT_SECRET", "your-secret-key"),
+			JWKSURL:            getEnv("AUTH_JWKS_URL", "http://localhost:8081/.well-known/jwks.json"),
+			JWKSRefresh:        getEnvDuration("AUTH_JWKS_REFRESH", DefaultJWKSRefresh),
+			ServiceTokenIssuer: getEnv("SERVICE_TOKEN_ISSUER", "mmo-auth-service"),
+		},
+		NATS: NATSConfig{
+			URL: getEnv("NATS_URL", "nats://localhost:4222"),
+		},
+		Tracing: TracingConfig{
+			Enabled:     getEnvBool("TRACING_ENABLED", false),
+			Exporter:    getEnv("TRACING_EXPORTER", "otlp"),
+			Endpoint:    getEnv("TRACING_ENDPOINT", "localhost:4318"),
+			Insecure:    getEnvBool("TRACING_INSECURE", true),
+			File:        getEnv("TRACING_FILE", "traces.json"),
+			SampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", DefaultTracingSampleRatio),
 		},
 	}
 }
@@ -60,3 +98,27 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvBool récupère une variable d'environnement booléenne avec une valeur par défaut
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvFloat récupère une variable d'environnement décimale avec une valeur par défaut
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvDuration récupère une variable d'environnement de durée avec une valeur par défaut
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -17,6 +17,10 @@ import (
 	"inventory/internal/handlers"
 	"inventory/internal/repository"
 	"inventory/internal/service"
+	"mmorpg/pkg/cacheinvalidation"
+	"mmorpg/pkg/jwks"
+	"mmorpg/pkg/serviceauth"
+	"mmorpg/pkg/tracing"
 )
 
 // Constantes pour les timeouts du serveur
@@ -38,6 +42,12 @@ func main() {
 		logrus.Fatal("Failed to load configuration: ", err)
 	}
 
+	// Distributed tracing (context received from the gateway, OTLP or local export)
+	shutdownTracing, err := tracing.Init(&cfg.Tracing, "inventory", "1.0.0", cfg.Server.Environment)
+	if err != nil {
+		logrus.Fatal("Failed to initialize tracing: ", err)
+	}
+
 	// Database connection
 	db, err := database.NewConnection(&cfg.Database)
 	if err != nil {
@@ -53,12 +63,23 @@ func main() {
 	// Initialize repositories
 	itemRepo := repository.NewItemRepository(db.DB)
 	inventoryRepo := repository.NewInventoryRepository(db.DB)
+	equipmentRepo := repository.NewEquipmentRepository(db.DB)
 
 	// Initialize services
 	inventoryService := service.NewInventoryService(inventoryRepo, itemRepo)
 
+	// Auth service public keys used to verify service tokens
+	keys := jwks.NewKeySet(cfg.JWT.JWKSURL, cfg.JWT.JWKSRefresh)
+	keys.Start()
+	defer keys.Close()
+
+	// Gateway cache invalidations, published after each successful inventory write
+	invalidations := cacheinvalidation.NewPublisher(cfg.NATS.URL, "inventory")
+	defer invalidations.Close()
+
 	// Initialize handlers
 	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+	equipmentHandler := handlers.NewEquipmentHandler(equipmentRepo)
 	healthHandler := handlers.NewHealthHandler("development")
 
 	// Configure Gin mode
@@ -70,6 +91,7 @@ func main() {
 	router := gin.New()
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(tracing.Middleware())
 
 	// CORS configuration for development
 	router.Use(func(c *gin.Context) {
@@ -95,6 +117,7 @@ func main() {
 	{
 		// Inventory routes
 		inventory := apiV1.Group("/inventory")
+		inventory.Use(cacheinvalidation.OnWrite(invalidations, "inventory.{characterId}"))
 		{
 			inventory.GET("/:characterId", inventoryHandler.GetInventory)
 
@@ -104,6 +127,9 @@ func main() {
 			inventory.PUT("/:characterId/items/:itemId", inventoryHandler.UpdateItem)
 			inventory.GET("/:characterId/items", inventoryHandler.ListItems)
 
+			// Equipment
+			inventory.GET("/:characterId/equipment/stats", equipmentHandler.GetEquipmentStats)
+
 			// Slot operations
 			inventory.POST("/:characterId/move", inventoryHandler.MoveItem)
 			inventory.POST("/:characterId/split", inventoryHandler.SplitStack)
@@ -112,6 +138,13 @@ func main() {
 			inventory.POST("/:characterId/items/bulk/add", inventoryHandler.AddBulkItems)
 			inventory.POST("/:characterId/items/bulk/remove", inventoryHandler.RemoveBulkItems)
 		}
+
+		// Internal routes for other services
+		services := apiV1.Group("/services")
+		services.Use(serviceauth.Middleware(serviceauth.NewVerifier(keys, cfg.JWT.ServiceTokenIssuer)))
+		{
+			services.GET("/equipment/:characterId/stats", serviceauth.RequireScope(serviceauth.ScopeInventoryRead), equipmentHandler.GetEquipmentStats)
+		}
 	}
 
 	// Server startup
@@ -150,6 +183,11 @@ func main() {
 		logrus.Fatal("Server forced to shutdown: ", err)
 	}
 
+	// Flush pending spans
+	if err := shutdownTracing(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to flush traces")
+	}
+
 	logrus.Info("Server exiting")
 }
 
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/spf13/viper"
+
+	"mmorpg/pkg/tracing"
 )
 
 // Default value constants
@@ -22,6 +24,9 @@ const (
 	DefaultBurstSize      = 20
 )
 
+// DefaultTracingSampleRatio share of new traces kept by default
+const DefaultTracingSampleRatio = 1.0
+
 // Config represents the complete inventory service configuration
 type Config struct {
 	Server     ServerConfig     `mapstructure:"server"`
@@ -31,6 +36,8 @@ type Config struct {
 	Inventory  InventoryConfig  `mapstructure:"inventory"`
 	RateLimit  RateLimitConfig  `mapstructure:"rate_limit"`
 	Monitoring MonitoringConfig `mapstructure:"monitoring"`
+	NATS       NATSConfig       `mapstructure:"nats"`
+	Tracing    TracingConfig    `mapstructure:"tracing"`
 }
 
 // ServerConfig represents HTTP server configuration
@@ -66,10 +73,15 @@ type RedisConfig struct {
 }
 
 // JWTConfig represents JWT configuration
+// Tokens are verified with the auth service public keys (JWKS), cached and reloaded
+// every JWKSRefresh: the service never holds a signing key.
 type JWTConfig struct {
-	Secret         string        `mapstructure:"secret"`
+	JWKSURL        string        `mapstructure:"jwks_url"`
+	JWKSRefresh    time.Duration `mapstructure:"jwks_refresh"`
 	ExpirationTime time.Duration `mapstructure:"expiration_time"`
 	RefreshTime    time.Duration `mapstructure:"refresh_time"`
+	// Issuer of the tokens issued by the auth service to other services (internal /services routes)
+	ServiceTokenIssuer string `mapstructure:"service_token_issuer"`
 }
 
 // InventoryConfig represents inventory-specific configuration
@@ -95,6 +107,14 @@ type MonitoringConfig struct {
 	HealthPath  string `mapstructure:"health_path"`
 }
 
+// NATSConfig represents the configuration used to publish gateway cache invalidations
+type NATSConfig struct {
+	URL string `mapstructure:"url"`
+}
+
+// TracingConfig represents distributed tracing configuration, shared by all services
+type TracingConfig = tracing.Config
+
 // GetDSN returns PostgreSQL connection string
 func (d *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf(
@@ -129,9 +149,11 @@ func Load() (*Config, error) {
 	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", DefaultRedisDB)
 
-	viper.SetDefault("jwt.secret", "inventory-secret-key")
+	viper.SetDefault("jwt.jwks_url", "http://localhost:8081/.well-known/jwks.json")
+	viper.SetDefault("jwt.jwks_refresh", "5m")
 	viper.SetDefault("jwt.expiration_time", "24h")
 	viper.SetDefault("jwt.refresh_time", "168h")
+	viper.SetDefault("jwt.service_token_issuer", "mmo-auth-service")
 
 	viper.SetDefault("inventory.default_slots", DefaultInventorySlots)
 	viper.SetDefault("inventory.max_slots", MaxInventorySlots)
@@ -147,6 +169,15 @@ func Load() (*Config, error) {
 	viper.SetDefault("monitoring.metrics_path", "/metrics")
 	viper.SetDefault("monitoring.health_path", "/health")
 
+	viper.SetDefault("nats.url", "nats://localhost:4222")
+
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.exporter", "otlp")
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.file", "traces.json")
+	viper.SetDefault("tracing.sample_ratio", DefaultTracingSampleRatio)
+
 	// Read environment variables
 	viper.AutomaticEnv()
 	viper.SetEnvPrefix("INVENTORY")
@@ -177,6 +208,39 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to bind database.ssl_mode env: %w", err)
 	}
 
+	// Token verification variables shared by all services
+	if err := viper.BindEnv("jwt.jwks_url", "AUTH_JWKS_URL"); err != nil {
+		return nil, fmt.Errorf("failed to bind jwt.jwks_url env: %w", err)
+	}
+	if err := viper.BindEnv("jwt.jwks_refresh", "AUTH_JWKS_REFRESH"); err != nil {
+		return nil, fmt.Errorf("failed to bind jwt.jwks_refresh env: %w", err)
+	}
+	if err := viper.BindEnv("jwt.service_token_issuer", "SERVICE_TOKEN_ISSUER"); err != nil {
+		return nil, fmt.Errorf("failed to bind jwt.service_token_issuer env: %w", err)
+	}
+
+	// Gateway cache invalidations
+	if err := viper.BindEnv("nats.url", "NATS_URL"); err != nil {
+		return nil, fmt.Errorf("failed to bind nats.url env: %w", err)
+	}
+
+	// Tracing variables shared by all services
+	if err := viper.BindEnv("tracing.enabled", "TRACING_ENABLED"); err != nil {
+		return nil, fmt.Errorf("failed to bind tracing.enabled env: %w", err)
+	}
+	if err := viper.BindEnv("tracing.exporter", "TRACING_EXPORTER"); err != nil {
+		return nil, fmt.Errorf("failed to bind tracing.exporter env: %w", err)
+	}
+	if err := viper.BindEnv("tracing.endpoint", "TRACING_ENDPOINT"); err != nil {
+		return nil, fmt.Errorf("failed to bind tracing.endpoint env: %w", err)
+	}
+	if err := viper.BindEnv("tracing.file", "TRACING_FILE"); err != nil {
+		return nil, fmt.Errorf("failed to bind tracing.file env: %w", err)
+	}
+	if err := viper.BindEnv("tracing.sample_ratio", "TRACING_SAMPLE_RATIO"); err != nil {
+		return nil, fmt.Errorf("failed to bind tracing.sample_ratio env: %w", err)
+	}
+
 	// Read configuration file (optional)
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -10,6 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"inventory/internal/config"
+	"mmorpg/pkg/tracing"
 )
 
 // Timeout constants
@@ -24,11 +25,12 @@ type DB struct {
 
 // NewConnection creates a new database connection
 func NewConnection(cfg *config.DatabaseConfig) (*DB, error) {
-	// Connect to PostgreSQL
-	db, err := sqlx.Connect("postgres", cfg.GetDSN())
+	// Connect to PostgreSQL, queries are traced within the HTTP request span
+	sqlDB, err := tracing.OpenDB("postgres", cfg.GetDSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	db := sqlx.NewDb(sqlDB, "postgres")
 
 	// Configure connection pool
 	db.SetMaxOpenConns(cfg.MaxOpenConns)
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"inventory/internal/models"
+	"inventory/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type EquipmentHandler struct {
+	equipmentRepo repository.EquipmentRepository
+}
+
+func NewEquipmentHandler(equipmentRepo repository.EquipmentRepository) *EquipmentHandler {
+	return &EquipmentHandler{
+		equipmentRepo: equipmentRepo,
+	}
+}
+
+// GetEquipmentStats returns the stats granted by a character's equipped items
+// GET /inventory/:characterId/equipment/stats (client) and GET /services/equipment/:characterId/stats (internal)
+func (h *EquipmentHandler) GetEquipmentStats(c *gin.Context) {
+	characterID, err := uuid.Parse(c.Param("characterId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("invalid_character_id", "Invalid character ID format", ""))
+		return
+	}
+
+	equipmentSet, err := h.equipmentRepo.GetByCharacterID(c.Request.Context(), characterID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("internal_error", "Failed to get equipment", err.Error()))
+		return
+	}
+
+	// Set bonuses must be known before the totals are summed
+	equipmentSet.CalculateSetBonuses()
+	equipmentSet.CalculateTotalStats()
+
+	response := models.EquipmentStatsResponse{
+		CharacterID: characterID,
+		TotalStats:  equipmentSet.TotalStats,
+		SetBonuses:  equipmentSet.SetBonuses,
+		Items:       make([]models.EquippedItemSummary, 0, len(equipmentSet.Equipment)),
+	}
+
+	for slot, equipment := range equipmentSet.Equipment {
+		if equipment.Item == nil {
+			continue
+		}
+		response.Items = append(response.Items, models.EquippedItemSummary{
+			Slot:     slot,
+			ItemID:   equipment.Item.ID,
+			Name:     equipment.Item.Name,
+			ItemType: equipment.Item.ItemType,
+			Stats:    equipment.Item.Stats,
+			Metadata: equipment.Item.Metadata,
+		})
+		if equipment.Item.Stats != nil {
+			response.CriticalChance += equipment.Item.Stats.CriticalChance
+		}
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(response))
+}
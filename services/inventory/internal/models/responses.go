@@ -86,6 +86,25 @@ type EquipItemResponse struct {
 	Message        string         `json:"message,omitempty"`
 }
 
+// EquipmentStatsResponse is returned to other services (combat) that need the
+// stats granted by the equipment a character is wearing
+type EquipmentStatsResponse struct {
+	CharacterID    uuid.UUID             `json:"character_id"`
+	TotalStats     map[string]int        `json:"total_stats"`
+	CriticalChance float64               `json:"critical_chance"`
+	SetBonuses     []SetBonus            `json:"set_bonuses"`
+	Items          []EquippedItemSummary `json:"items"`
+}
+
+type EquippedItemSummary struct {
+	Slot     EquipmentSlot          `json:"slot"`
+	ItemID   uuid.UUID              `json:"item_id"`
+	Name     string                 `json:"name"`
+	ItemType ItemType               `json:"item_type"`
+	Stats    *ItemStats             `json:"stats,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
 // Trade responses
 type TradeResponse struct {
 	Trade *Trade `json:"trade"`
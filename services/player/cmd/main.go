@@ -12,6 +12,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 
+	"mmorpg/pkg/jwks"
+	"mmorpg/pkg/serviceauth"
+	"mmorpg/pkg/tracing"
 	"player/internal/config"
 	"player/internal/database"
 	"player/internal/handlers"
@@ -30,6 +33,12 @@ func main() {
 		logrus.Fatal("Failed to load config: ", err)
 	}
 
+	// Tracing distribué (contexte reçu du gateway, export OTLP ou local)
+	shutdownTracing, err := tracing.Init(&cfg.Tracing, "player", "1.0.0", cfg.Server.Environment)
+	if err != nil {
+		logrus.Fatal("Failed to initialize tracing: ", err)
+	}
+
 	// connection à la base de données
 	db, err := database.NewConnection(cfg.Database)
 	if err != nil {
@@ -50,13 +59,17 @@ func main() {
 	playerService := service.NewPlayerService(playerRepo, characterRepo, cfg)
 	characterService := service.NewCharacterService(characterRepo, playerRepo, cfg)
 
+	// Clés publiques du service auth pour vérifier les tokens des joueurs et des services
+	keys := jwks.NewKeySet(cfg.Auth.JWKSURL, cfg.Auth.JWKSRefresh)
+	keys.Start()
+
 	// Configuration du mode Gin
 	if cfg.Server.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	// Configuration des routes
-	router := setupRoutes(playerService, characterService, cfg, db)
+	router := setupRoutes(playerService, characterService, keys, cfg, db)
 
 	// Configuration du serveur HTTP
 	server := &http.Server{
@@ -83,13 +96,14 @@ func main() {
 	go startPeriodicCleanup(characterService)
 
 	// Gestion gracieuse de l'arrêt
-	gracefulShutdown(server, playerService, characterService)
+	gracefulShutdown(server, shutdownTracing, keys, playerService, characterService)
 }
 
 // setupRoutes configure toutes les routes du service Player
 func setupRoutes(
 	playerService *service.PlayerService,
 	characterService *service.CharacterService,
+	keys *jwks.KeySet,
 	cfg *config.Config,
 	db *database.DB,
 ) *gin.Engine {
@@ -100,12 +114,14 @@ func setupRoutes(
 	router.Use(middleware.Recovery())
 	router.Use(middleware.CORS())
 	router.Use(middleware.RequestID())
+	router.Use(tracing.Middleware())
 	router.Use(middleware.SecurityHeaders())
 
 	// Handlers
 	healthHandler := handlers.NewHealthHandler(cfg, db)
 	playerHandler := handlers.NewPlayerHandler(playerService, cfg)
 	characterHandler := handlers.NewCharacterHandler(characterService, cfg)
+	serviceVerifier := serviceauth.NewVerifier(keys, cfg.Auth.ServiceTokenIssuer)
 
 	// Routes de santé et monitoring
 	router.GET(cfg.Monitoring.HealthPath, healthHandler.HealthCheck)
@@ -130,7 +146,7 @@ func setupRoutes(
 
 		// Routes protégées (authentification JWT requise)
 		protected := v1.Group("/")
-		protected.Use(middleware.JWTAuth(cfg.Auth.JWTSecret))
+		protected.Use(middleware.JWTAuth(keys))
 		{
 			// Routes joueur
 			player := protected.Group("/player")
@@ -171,11 +187,14 @@ func setupRoutes(
 
 		// Routes pour les autres services (validation interne)
 		services := v1.Group("/services")
+		services.Use(serviceauth.Middleware(serviceVerifier))
 		{
-			services.GET("/player/:userID", playerHandler.GetPlayerSummary)
-			services.GET("/player/:userID/characters", playerHandler.GetPlayerCharactersSummary)
-			services.POST("/validate/display-name", playerHandler.ValidateDisplayName)
-			services.GET("/online-players", playerHandler.GetOnlinePlayers)
+			read := serviceauth.RequireScope(serviceauth.ScopePlayerRead)
+			services.GET("/player/:userID", read, playerHandler.GetPlayerSummary)
+			services.GET("/player/:userID/characters", read, playerHandler.GetPlayerCharactersSummary)
+			services.GET("/character/:characterID/combat-profile", read, characterHandler.GetCharacterCombatProfile)
+			services.POST("/validate/display-name", serviceauth.RequireScope(serviceauth.ScopePlayerValidate), playerHandler.ValidateDisplayName)
+			services.GET("/online-players", read, playerHandler.GetOnlinePlayers)
 		}
 	}
 
@@ -231,7 +250,13 @@ func initLogger() {
 }
 
 // gracefulShutdown gère l'arrêt gracieux du serveur
-func gracefulShutdown(server *http.Server, playerService *service.PlayerService, characterService *service.CharacterService) {
+func gracefulShutdown(
+	server *http.Server,
+	shutdownTracing func(context.Context) error,
+	keys *jwks.KeySet,
+	playerService *service.PlayerService,
+	characterService *service.CharacterService,
+) {
 	// Canal pour capturer les signaux système
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -250,6 +275,8 @@ func gracefulShutdown(server *http.Server, playerService *service.PlayerService,
 	}
 
 	// Fermer les services
+	keys.Close()
+
 	if err := playerService.Close(); err != nil {
 		logrus.Error("Error closing player service:", err)
 	}
@@ -258,5 +285,10 @@ func gracefulShutdown(server *http.Server, playerService *service.PlayerService,
 		logrus.Error("Error closing character service:", err)
 	}
 
+	// Exporter les derniers spans
+	if err := shutdownTracing(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to flush traces")
+	}
+
 	logrus.Info("✅ Player Service stopped")
 }
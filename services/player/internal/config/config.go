@@ -4,11 +4,17 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+
+	"mmorpg/pkg/tracing"
 )
 
+// Tracing distribué
+const DefaultTracingSampleRatio = 1.0
+
 // Config représente la configuration du service Player
 type Config struct {
 	Server     ServerConfig     `mapstructure:"server"`
@@ -17,6 +23,7 @@ type Config struct {
 	RateLimit  RateLimitConfig  `mapstructure:"rate_limit"`
 	Monitoring MonitoringConfig `mapstructure:"monitoring"`
 	Game       GameConfig       `mapstructure:"game"`
+	Tracing    TracingConfig    `mapstructure:"tracing"`
 }
 
 // ServerConfig configuration du serveur Player
@@ -42,11 +49,18 @@ type DatabaseConfig struct {
 }
 
 // AuthConfig configuration pour la communication avec le service Auth
+// Les tokens sont vérifiés avec les clés publiques du service auth (JWKS) : sans URL,
+// le JWKS est lu sur ServiceURL.
 type AuthConfig struct {
-	ServiceURL string `mapstructure:"service_url"`
-	JWTSecret  string `mapstructure:"jwt_secret"`
+	ServiceURL         string        `mapstructure:"service_url"`
+	JWKSURL            string        `mapstructure:"jwks_url"`
+	JWKSRefresh        time.Duration `mapstructure:"jwks_refresh"`
+	ServiceTokenIssuer string        `mapstructure:"service_token_issuer"` // tokens des autres services (routes /services)
 }
 
+// JWKSPath chemin du JWKS publié par le service auth
+const JWKSPath = "/.well-known/jwks.json"
+
 // RateLimitConfig configuration rate limiting
 type RateLimitConfig struct {
 	RequestsPerMinute int           `mapstructure:"requests_per_minute"`
@@ -81,6 +95,9 @@ type StatConfig struct {
 	Vitality     int `mapstructure:"vitality"`
 }
 
+// TracingConfig configuration du tracing distribué, commune à tous les services
+type TracingConfig = tracing.Config
+
 // LoadConfig charge la configuration
 func LoadConfig() (*Config, error) {
 	// Configuration par défaut
@@ -104,8 +121,9 @@ func LoadConfig() (*Config, error) {
 			MaxIdleConns: 5,
 		},
 		Auth: AuthConfig{
-			ServiceURL: "http://localhost:8081",
-			JWTSecret:  "your-super-secret-jwt-key-change-in-production-minimum-64-characters",
+			ServiceURL:         "http://localhost:8081",
+			JWKSRefresh:        5 * time.Minute,
+			ServiceTokenIssuer: "mmo-auth-service",
 		},
 		RateLimit: RateLimitConfig{
 			RequestsPerMinute: 100,
@@ -132,6 +150,14 @@ func LoadConfig() (*Config, error) {
 				Vitality:     10,
 			},
 		},
+		Tracing: TracingConfig{
+			Enabled:     false,
+			Exporter:    "otlp",
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			File:        "traces.json",
+			SampleRatio: DefaultTracingSampleRatio,
+		},
 	}
 
 	// Charger depuis les variables d'environnement
@@ -154,6 +180,10 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	if config.Auth.JWKSURL == "" {
+		config.Auth.JWKSURL = strings.TrimSuffix(config.Auth.ServiceURL, "/") + JWKSPath
+	}
+
 	// Validation
 	if err := validateConfig(config); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
@@ -200,8 +230,42 @@ func loadFromEnv(config *Config) {
 	if authURL := os.Getenv("AUTH_SERVICE_URL"); authURL != "" {
 		config.Auth.ServiceURL = authURL
 	}
-	if secret := os.Getenv("JWT_SECRET"); secret != "" {
-		config.Auth.JWTSecret = secret
+	if jwksURL := os.Getenv("AUTH_JWKS_URL"); jwksURL != "" {
+		config.Auth.JWKSURL = jwksURL
+	}
+	if refresh := os.Getenv("AUTH_JWKS_REFRESH"); refresh != "" {
+		if d, err := time.ParseDuration(refresh); err == nil {
+			config.Auth.JWKSRefresh = d
+		}
+	}
+	if issuer := os.Getenv("SERVICE_TOKEN_ISSUER"); issuer != "" {
+		config.Auth.ServiceTokenIssuer = issuer
+	}
+
+	// Tracing
+	loadTracingEnv(config)
+}
+
+// loadTracingEnv charge la configuration du tracing (variables communes aux services)
+func loadTracingEnv(config *Config) {
+	if enabled := os.Getenv("TRACING_ENABLED"); enabled != "" {
+		if b, err := strconv.ParseBool(enabled); err == nil {
+			config.Tracing.Enabled = b
+		}
+	}
+	if exporter := os.Getenv("TRACING_EXPORTER"); exporter != "" {
+		config.Tracing.Exporter = exporter
+	}
+	if endpoint := os.Getenv("TRACING_ENDPOINT"); endpoint != "" {
+		config.Tracing.Endpoint = endpoint
+	}
+	if file := os.Getenv("TRACING_FILE"); file != "" {
+		config.Tracing.File = file
+	}
+	if ratio := os.Getenv("TRACING_SAMPLE_RATIO"); ratio != "" {
+		if r, err := strconv.ParseFloat(ratio, 64); err == nil {
+			config.Tracing.SampleRatio = r
+		}
 	}
 }
 
@@ -227,8 +291,8 @@ func validateConfig(config *Config) error {
 	if config.Auth.ServiceURL == "" {
 		return fmt.Errorf("auth service URL is required")
 	}
-	if len(config.Auth.JWTSecret) < 32 {
-		return fmt.Errorf("JWT secret must be at least 32 characters long")
+	if config.Auth.JWKSRefresh <= 0 {
+		return fmt.Errorf("JWKS refresh interval must be positive")
 	}
 
 	// Validation Game
@@ -9,6 +9,7 @@ import (
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 
+	"mmorpg/pkg/tracing"
 	"player/internal/config"
 )
 
@@ -23,11 +24,12 @@ func NewConnection(cfg config.DatabaseConfig) (*DB, error) {
 	// Construction de l'URL de connection
 	dsn := cfg.GetDatabaseURL()
 
-	// connection à la base de données
-	db, err := sqlx.Connect("postgres", dsn)
+	// connection à la base de données, requêtes tracées dans le span de la requête HTTP
+	sqlDB, err := tracing.OpenDB("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	db := sqlx.NewDb(sqlDB, "postgres")
 
 	// Configuration de la pool de connections
 	db.SetMaxOpenConns(cfg.MaxOpenConns)
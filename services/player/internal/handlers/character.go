@@ -387,6 +387,39 @@ func (h *CharacterHandler) GetCharacterStats(c *gin.Context) {
 	})
 }
 
+// GetCharacterCombatProfile endpoint pour récupérer les stats d'un personnage (utilisé par le service combat)
+func (h *CharacterHandler) GetCharacterCombatProfile(c *gin.Context) {
+	characterID, err := uuid.Parse(c.Param("characterID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Invalid character ID",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	profile, err := h.characterService.GetCharacterCombatProfile(characterID)
+	if err != nil {
+		logrus.WithError(err).WithField("character_id", characterID).Error("Failed to get character combat profile")
+
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "character not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, gin.H{
+			"error":      "Failed to get character combat profile",
+			"request_id": c.GetHeader("X-Request-ID"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"profile":    profile,
+		"request_id": c.GetHeader("X-Request-ID"),
+	})
+}
+
 // UpdateCharacterStats godoc
 // @Summary      Mise à jour des statistiques
 // @Description  Met à jour les statistiques d'un personnage (dépense de points)
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -15,9 +14,13 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 
+	"mmorpg/pkg/jwks"
 	"player/internal/config"
 )
 
+// tokenTypeAccess type des tokens d'accès des joueurs (les tokens de service sont refusés)
+const tokenTypeAccess = "access"
+
 // JWTClaims représente les claims du JWT (compatible avec le service Auth)
 type JWTClaims struct {
 	UserID      uuid.UUID `json:"user_id"`
@@ -33,7 +36,8 @@ type JWTClaims struct {
 // Logger middleware personnalisé pour le service Player
 func Logger() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		logrus.WithFields(logrus.Fields{
+		// trace_id et span_id ajoutés depuis le contexte de la requête
+		logrus.WithContext(param.Request.Context()).WithFields(logrus.Fields{
 			"timestamp":   param.TimeStamp.Format(time.RFC3339),
 			"client_ip":   param.ClientIP,
 			"method":      param.Method,
@@ -203,8 +207,8 @@ func RateLimit(cfg config.RateLimitConfig) gin.HandlerFunc {
 	}
 }
 
-// JWTAuth middleware d'authentification JWT (compatible avec le service Auth)
-func JWTAuth(jwtSecret string) gin.HandlerFunc {
+// JWTAuth middleware d'authentification JWT (clés publiques du service Auth)
+func JWTAuth(keys *jwks.KeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Récupérer le token depuis l'en-tête Authorization
 		authHeader := c.GetHeader("Authorization")
@@ -231,12 +235,7 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 		tokenString := tokenParts[1]
 
 		// Parser et valider le token
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(jwtSecret), nil
-		})
+		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, keys.Keyfunc, jwt.WithValidMethods(jwks.Algorithms()))
 
 		if err != nil {
 			logrus.WithFields(logrus.Fields{
@@ -255,7 +254,7 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 		}
 
 		// Vérifier les claims
-		if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid && claims.TokenType == tokenTypeAccess && claims.UserID != uuid.Nil {
 			// Ajouter les informations utilisateur au contexte
 			c.Set("user_id", claims.UserID)
 			c.Set("username", claims.Username)
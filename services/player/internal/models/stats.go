@@ -80,6 +80,18 @@ type StatsResponse struct {
 	Modifiers   []*StatModifier `json:"modifiers,omitempty"` // Changé pour utiliser des pointeurs
 }
 
+// CharacterCombatProfile représente les données nécessaires au service combat
+// pour calculer les statistiques d'un personnage côté serveur
+type CharacterCombatProfile struct {
+	CharacterID uuid.UUID       `json:"character_id"`
+	PlayerID    uuid.UUID       `json:"player_id"`
+	Level       int             `json:"level"`
+	Class       string          `json:"class"`
+	Race        string          `json:"race"`
+	BaseStats   *CharacterStats `json:"base_stats"`
+	Modifiers   []*StatModifier `json:"modifiers"`
+}
+
 // StatModifier représente un modificateur temporaire de statistiques
 type StatModifier struct {
 	ID          uuid.UUID  `json:"id" db:"id"`
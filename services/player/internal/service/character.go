@@ -225,6 +225,38 @@ func (s *CharacterService) GetCharacterStats(characterID uuid.UUID, userID uuid.
 	}, nil
 }
 
+// GetCharacterCombatProfile récupère les stats et modificateurs actifs d'un personnage
+// sans vérification de propriété (réservé aux appels inter-services)
+func (s *CharacterService) GetCharacterCombatProfile(characterID uuid.UUID) (*models.CharacterCombatProfile, error) {
+	character, err := s.characterRepo.GetByID(characterID)
+	if err != nil {
+		return nil, fmt.Errorf("character not found")
+	}
+
+	baseStats, err := s.characterRepo.GetStats(characterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get character stats: %w", err)
+	}
+
+	modifiers, err := s.characterRepo.GetActiveModifiers(characterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active modifiers: %w", err)
+	}
+	if modifiers == nil {
+		modifiers = []*models.StatModifier{}
+	}
+
+	return &models.CharacterCombatProfile{
+		CharacterID: character.ID,
+		PlayerID:    character.PlayerID,
+		Level:       character.Level,
+		Class:       character.Class,
+		Race:        character.Race,
+		BaseStats:   baseStats,
+		Modifiers:   modifiers,
+	}, nil
+}
+
 // UpdateCharacterStats met à jour les statistiques d'un personnage
 func (s *CharacterService) UpdateCharacterStats(characterID uuid.UUID, userID uuid.UUID, req models.UpdateStatsRequest) (*models.CharacterStats, error) {
 	// Vérifier la propriété
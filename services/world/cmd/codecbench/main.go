@@ -0,0 +1,182 @@
+// Commande de benchmark des encodages du chemin critique : taille sur le réseau et coût CPU
+// de la mise à jour de position en JSON, MessagePack et Protobuf
+//
+// Usage (depuis services/world) :
+//
+//	go run ./cmd/codecbench
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"world/internal/encoding"
+	"world/internal/models"
+)
+
+// Valeurs d'exemple : un joueur en mouvement
+const (
+	sampleX         = 1523.75
+	sampleY         = -842.5
+	sampleZ         = 64.125
+	sampleRotation  = 187.5
+	sampleVelocityX = 3.2
+	sampleVelocityY = -1.1
+	sampleLevel     = 42
+	percent         = 100
+)
+
+// codec encodage mesuré d'un message
+type codec struct {
+	format encoding.Format
+	encode func() ([]byte, error)
+	decode func(data []byte) error // nil : message seulement encodé par le service
+}
+
+// result mesure d'un encodage
+type result struct {
+	size     int
+	encodeNs int64
+	decodeNs int64
+	allocs   int64
+}
+
+func main() {
+	request := &models.UpdatePositionRequest{
+		ZoneID:    "starter_zone",
+		X:         sampleX,
+		Y:         sampleY,
+		Z:         sampleZ,
+		Rotation:  sampleRotation,
+		VelocityX: sampleVelocityX,
+		VelocityY: sampleVelocityY,
+		IsMoving:  true,
+	}
+	position := &models.PlayerPosition{
+		CharacterID:    uuid.New(),
+		UserID:         uuid.New(),
+		ZoneID:         request.ZoneID,
+		X:              request.X,
+		Y:              request.Y,
+		Z:              request.Z,
+		Rotation:       request.Rotation,
+		VelocityX:      request.VelocityX,
+		VelocityY:      request.VelocityY,
+		IsMoving:       true,
+		IsOnline:       true,
+		LastUpdate:     time.Now().UTC(),
+		CharacterName:  "Aldric",
+		CharacterLevel: sampleLevel,
+	}
+	// Réponse telle qu'écrite par le handler (le protobuf ne porte que la position)
+	response := gin.H{"position": position, "message": "Position updated successfully"}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "message\tformat\tbytes\tvs json\tencode ns/op\tdecode ns/op\tcpu vs json\tallocs/op\t")
+
+	report(writer, "UpdatePositionRequest", []codec{
+		{
+			format: encoding.FormatJSON,
+			encode: func() ([]byte, error) { return json.Marshal(request) },
+			decode: func(data []byte) error { return json.Unmarshal(data, &models.UpdatePositionRequest{}) },
+		},
+		{
+			format: encoding.FormatMsgPack,
+			encode: func() ([]byte, error) { return encoding.MarshalMsgPack(request) },
+			decode: func(data []byte) error { return encoding.UnmarshalMsgPack(data, &models.UpdatePositionRequest{}) },
+		},
+		{
+			format: encoding.FormatProtobuf,
+			encode: func() ([]byte, error) { return request.MarshalProto(), nil },
+			decode: func(data []byte) error { return (&models.UpdatePositionRequest{}).UnmarshalProto(data) },
+		},
+	})
+	report(writer, "PlayerPosition (réponse)", []codec{
+		{format: encoding.FormatJSON, encode: func() ([]byte, error) { return json.Marshal(response) }},
+		{format: encoding.FormatMsgPack, encode: func() ([]byte, error) { return encoding.MarshalMsgPack(response) }},
+		{format: encoding.FormatProtobuf, encode: func() ([]byte, error) { return position.MarshalProto(), nil }},
+	})
+
+	if err := writer.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// report mesure les encodages d'un message et écrit une ligne par encodage (JSON en premier)
+func report(writer *tabwriter.Writer, name string, codecs []codec) {
+	var baseline result
+	for i, c := range codecs {
+		measured, err := measure(c)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s: %v\n", name, c.format, err)
+			os.Exit(1)
+		}
+		if i == 0 {
+			baseline = measured
+		}
+
+		decode := "-"
+		if c.decode != nil {
+			decode = fmt.Sprint(measured.decodeNs)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%s\t%d\t%s\t%s\t%d\t\n",
+			name, c.format, measured.size,
+			saving(int64(measured.size), int64(baseline.size)),
+			measured.encodeNs, decode,
+			saving(measured.encodeNs+measured.decodeNs, baseline.encodeNs+baseline.decodeNs),
+			measured.allocs)
+	}
+}
+
+// measure benchmarke l'encodage puis le décodage d'un message
+func measure(c codec) (result, error) {
+	data, err := c.encode()
+	if err != nil {
+		return result{}, err
+	}
+	if c.decode != nil {
+		if err = c.decode(data); err != nil {
+			return result{}, err
+		}
+	}
+
+	encoded := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, encodeErr := c.encode(); encodeErr != nil {
+				b.Fatal(encodeErr)
+			}
+		}
+	})
+	measured := result{size: len(data), encodeNs: encoded.NsPerOp(), allocs: encoded.AllocsPerOp()}
+
+	if c.decode != nil {
+		decoded := testing.Benchmark(func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				if decodeErr := c.decode(data); decodeErr != nil {
+					b.Fatal(decodeErr)
+				}
+			}
+		})
+		measured.decodeNs = decoded.NsPerOp()
+		measured.allocs += decoded.AllocsPerOp()
+	}
+	return measured, nil
+}
+
+// saving écart relatif à JSON
+func saving(value, baseline int64) string {
+	if baseline == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.0f%%", float64(value-baseline)*percent/float64(baseline))
+}
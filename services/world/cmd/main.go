@@ -13,6 +13,10 @@ import (
 	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
 
+	"mmorpg/pkg/cacheinvalidation"
+	"mmorpg/pkg/jwks"
+	"mmorpg/pkg/serviceauth"
+	"mmorpg/pkg/tracing"
 	"world/internal/config"
 	"world/internal/database"
 	"world/internal/handlers"
@@ -55,10 +59,17 @@ func main() {
 		fx.Provide(handlers.NewWorldEventHandler),
 		fx.Provide(handlers.NewWeatherHandler),
 
+		// Clés publiques du service auth
+		fx.Provide(NewKeySet),
+
+		// Invalidations du cache du gateway
+		fx.Provide(NewInvalidationPublisher),
+
 		// HTTP Server
 		fx.Provide(NewHTTPServer),
 
 		// Lifecycle
+		fx.Invoke(SetupTracing),
 		fx.Invoke(RunServer),
 	)
 
@@ -68,6 +79,8 @@ func main() {
 // NewHTTPServer crée et configure le serveur HTTP
 func NewHTTPServer(
 	cfg *config.Config,
+	keys *jwks.KeySet,
+	invalidations *cacheinvalidation.Publisher,
 	zoneHandler *handlers.ZoneHandler,
 	npcHandler *handlers.NPCHandler,
 	positionHandler *handlers.PlayerPositionHandler,
@@ -86,6 +99,7 @@ func NewHTTPServer(
 	router.Use(middleware.Recovery())
 	router.Use(middleware.CORS())
 	router.Use(middleware.RequestID())
+	router.Use(tracing.Middleware())
 	router.Use(middleware.RateLimit(cfg.RateLimit))
 	router.Use(middleware.Metrics())
 
@@ -105,7 +119,7 @@ func NewHTTPServer(
 	{
 		// Routes des zones
 		zones := api.Group("/zones")
-		zones.Use(middleware.JWTAuth(cfg.JWT.Secret))
+		zones.Use(middleware.JWTAuth(keys))
 		{
 			zones.GET("", zoneHandler.ListZones)
 			zones.GET("/:id", zoneHandler.GetZone)
@@ -117,7 +131,7 @@ func NewHTTPServer(
 
 		// Routes des NPCs
 		npcs := api.Group("/npcs")
-		npcs.Use(middleware.JWTAuth(cfg.JWT.Secret))
+		npcs.Use(middleware.JWTAuth(keys))
 		{
 			npcs.GET("", npcHandler.ListNPCs)
 			npcs.GET("/:id", npcHandler.GetNPC)
@@ -127,16 +141,25 @@ func NewHTTPServer(
 
 		// Routes des positions
 		positions := api.Group("/positions")
-		positions.Use(middleware.JWTAuth(cfg.JWT.Secret))
+		positions.Use(middleware.JWTAuth(keys))
 		{
 			positions.GET("/character/:characterId", positionHandler.GetCharacterPosition)
 			positions.PUT("/character/:characterId", positionHandler.UpdateCharacterPosition)
 			positions.GET("/zone/:zoneId", positionHandler.GetZonePositions)
 		}
 
+		// Routes pour les autres services (appels internes)
+		services := api.Group("/services")
+		services.Use(serviceauth.Middleware(serviceauth.NewVerifier(keys, cfg.JWT.ServiceTokenIssuer)))
+		{
+			services.GET("/character/:characterId/location", serviceauth.RequireScope(serviceauth.ScopeWorldRead), positionHandler.GetCharacterLocation)
+			services.GET("/npcs/:id/cc-immunities", serviceauth.RequireScope(serviceauth.ScopeWorldRead), npcHandler.GetNPCCCImmunities)
+			services.GET("/zones/:id/users/:userId", serviceauth.RequireScope(serviceauth.ScopeWorldRead), positionHandler.GetUserZonePresence)
+		}
+
 		// Routes des événements du monde
 		events := api.Group("/events")
-		events.Use(middleware.JWTAuth(cfg.JWT.Secret))
+		events.Use(middleware.JWTAuth(keys))
 		{
 			events.GET("", eventHandler.ListEvents)
 			events.GET("/active", eventHandler.GetActiveEvents)
@@ -146,7 +169,7 @@ func NewHTTPServer(
 
 		// Routes de la météo
 		weather := api.Group("/weather")
-		weather.Use(middleware.JWTAuth(cfg.JWT.Secret))
+		weather.Use(middleware.JWTAuth(keys))
 		{
 			weather.GET("/zone/:zoneId", weatherHandler.GetZoneWeather)
 			weather.GET("/forecast/:zoneId", weatherHandler.GetWeatherForecast)
@@ -154,12 +177,13 @@ func NewHTTPServer(
 
 		// Routes administratives
 		admin := api.Group("/admin")
-		admin.Use(middleware.JWTAuth(cfg.JWT.Secret))
+		admin.Use(middleware.JWTAuth(keys))
 		admin.Use(middleware.RequireRole("admin"))
 		{
-			admin.POST("/zones", zoneHandler.CreateZone)
-			admin.PUT("/zones/:id", zoneHandler.UpdateZone)
-			admin.DELETE("/zones/:id", zoneHandler.DeleteZone)
+			zoneChanged := cacheinvalidation.OnWrite(invalidations, "world.zones", "world.zone.{id}")
+			admin.POST("/zones", zoneChanged, zoneHandler.CreateZone)
+			admin.PUT("/zones/:id", zoneChanged, zoneHandler.UpdateZone)
+			admin.DELETE("/zones/:id", zoneChanged, zoneHandler.DeleteZone)
 
 			admin.POST("/npcs", npcHandler.CreateNPC)
 			admin.PUT("/npcs/:id", npcHandler.UpdateNPC)
@@ -195,6 +219,44 @@ func NewHTTPServer(
 	return router
 }
 
+// NewKeySet crée le cache des clés de vérification des tokens, rechargé tant que le service tourne
+func NewKeySet(lc fx.Lifecycle, cfg *config.Config) *jwks.KeySet {
+	keys := jwks.NewKeySet(cfg.JWT.JWKSURL, cfg.JWT.JWKSRefresh)
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			keys.Start()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			keys.Close()
+			return nil
+		},
+	})
+	return keys
+}
+
+// NewInvalidationPublisher publie les invalidations du cache du gateway jusqu'à l'arrêt du service
+func NewInvalidationPublisher(lc fx.Lifecycle, cfg *config.Config) *cacheinvalidation.Publisher {
+	publisher := cacheinvalidation.NewPublisher(cfg.NATS.URL, "world")
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			publisher.Close()
+			return nil
+		},
+	})
+	return publisher
+}
+
+// SetupTracing active le tracing distribué et vide les spans en attente à l'arrêt
+func SetupTracing(lc fx.Lifecycle, cfg *config.Config) error {
+	shutdownTracing, err := tracing.Init(&cfg.Tracing, "world", "1.0.0", cfg.Server.Environment)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	lc.Append(fx.Hook{OnStop: shutdownTracing})
+	return nil
+}
+
 // RunServer démarre le serveur HTTP
 func RunServer(lc fx.Lifecycle, cfg *config.Config, router *gin.Engine, db *database.DB) {
 	server := &http.Server{
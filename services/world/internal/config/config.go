@@ -5,8 +5,13 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"mmorpg/pkg/tracing"
 )
 
+// DefaultTracingSampleRatio part des nouvelles traces conservées par défaut
+const DefaultTracingSampleRatio = 1.0
+
 type Config struct {
 	Server     ServerConfig
 	Database   DatabaseConfig
@@ -14,6 +19,8 @@ type Config struct {
 	Game       GameConfig
 	RateLimit  interface{}
 	Monitoring MonitoringConfig
+	NATS       NATSConfig
+	Tracing    TracingConfig
 }
 
 type ServerConfig struct {
@@ -35,8 +42,14 @@ type DatabaseConfig struct {
 	MaxIdleConns int
 }
 
+// JWTConfig vérification des tokens avec les clés publiques du service auth (JWKS)
+// Le service ne détient aucune clé de signature : le JWKS est gardé en cache et rechargé
+// toutes les JWKSRefresh.
 type JWTConfig struct {
-	Secret string
+	JWKSURL     string
+	JWKSRefresh time.Duration
+	// Émetteur des tokens des autres services pour les routes internes (/services)
+	ServiceTokenIssuer string
 }
 
 type GameConfig struct {
@@ -48,6 +61,14 @@ type MonitoringConfig struct {
 	MetricsPath string
 }
 
+// NATSConfig publication des invalidations du cache du gateway
+type NATSConfig struct {
+	URL string
+}
+
+// TracingConfig configuration du tracing distribué, commune à tous les services
+type TracingConfig = tracing.Config
+
 // GetDatabaseURL construit l'URL de connection à la base de données
 func (d *DatabaseConfig) GetDatabaseURL() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
@@ -72,6 +93,30 @@ func getEnvIntOrDefault(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvBoolOrDefault récupère une variable d'environnement booléenne ou retourne une valeur par défaut
+func getEnvBoolOrDefault(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvFloatOrDefault récupère une variable d'environnement décimale ou retourne une valeur par défaut
+func getEnvFloatOrDefault(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvDurationOrDefault récupère une variable d'environnement durée ou retourne une valeur par défaut
+func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 func LoadConfig() (*Config, error) {
 	return &Config{
 		Server: ServerConfig{
@@ -92,7 +137,9 @@ func LoadConfig() (*Config, error) {
 			MaxIdleConns: getEnvIntOrDefault("WORLD_DB_MAX_IDLE_CONNS", 5),
 		},
 		JWT: JWTConfig{
-			Secret: getEnvOrDefault("JWT_SECRET", "your-super-secret-jwt-key-change-in-production-minimum-64-characters"),
+			JWKSURL:            getEnvOrDefault("AUTH_JWKS_URL", "http://localhost:8081/.well-known/jwks.json"),
+			JWKSRefresh:        getEnvDurationOrDefault("AUTH_JWKS_REFRESH", 5*time.Minute),
+			ServiceTokenIssuer: getEnvOrDefault("SERVICE_TOKEN_ISSUER", "mmo-auth-service"),
 		},
 		Game: GameConfig{
 			MaxRenderDistance: 100.0,
@@ -101,5 +148,16 @@ func LoadConfig() (*Config, error) {
 			HealthPath:  "/health",
 			MetricsPath: "/metrics",
 		},
+		NATS: NATSConfig{
+			URL: getEnvOrDefault("NATS_URL", "nats://localhost:4222"),
+		},
+		Tracing: TracingConfig{
+			Enabled:     getEnvBoolOrDefault("TRACING_ENABLED", false),
+			Exporter:    getEnvOrDefault("TRACING_EXPORTER", "otlp"),
+			Endpoint:    getEnvOrDefault("TRACING_ENDPOINT", "localhost:4318"),
+			Insecure:    getEnvBoolOrDefault("TRACING_INSECURE", true),
+			File:        getEnvOrDefault("TRACING_FILE", "traces.json"),
+			SampleRatio: getEnvFloatOrDefault("TRACING_SAMPLE_RATIO", DefaultTracingSampleRatio),
+		},
 	}, nil
 }
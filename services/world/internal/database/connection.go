@@ -9,6 +9,7 @@ import (
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 
+	"mmorpg/pkg/tracing"
 	"world/internal/config"
 )
 
@@ -20,10 +21,12 @@ type DB struct {
 func NewConnection(cfg *config.Config) (*DB, error) {
 	dsn := cfg.Database.GetDatabaseURL()
 
-	db, err := sqlx.Connect("postgres", dsn)
+	// Requêtes SQL tracées dans le span de la requête HTTP
+	sqlDB, err := tracing.OpenDB("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	db := sqlx.NewDb(sqlDB, "postgres")
 
 	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
 	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
@@ -0,0 +1,182 @@
+// Package encoding négocie l'encodage des corps du chemin critique : JSON, MessagePack ou
+// Protobuf (schéma partagé services/gateway/api/proto/game.proto)
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mmorpg/pkg/protocodec"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/sirupsen/logrus"
+	"github.com/ugorji/go/codec"
+)
+
+// Format encodage d'un corps
+type Format string
+
+// Encodages supportés
+const (
+	FormatJSON     Format = "json"
+	FormatMsgPack  Format = "msgpack"
+	FormatProtobuf Format = "protobuf"
+)
+
+// Types de contenu
+const (
+	MIMEJSON           = "application/json"
+	MIMEMsgPack        = "application/msgpack"
+	MIMEMsgPackLegacy  = "application/x-msgpack"
+	MIMEProtobuf       = "application/x-protobuf"
+	MIMEProtobufLegacy = "application/protobuf"
+)
+
+// ErrUnsupportedMediaType corps protobuf pour un type sans schéma protobuf
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// mediaTypes encodage de chaque type de contenu reconnu
+var mediaTypes = map[string]Format{
+	MIMEJSON:           FormatJSON,
+	MIMEMsgPack:        FormatMsgPack,
+	MIMEMsgPackLegacy:  FormatMsgPack,
+	MIMEProtobuf:       FormatProtobuf,
+	MIMEProtobufLegacy: FormatProtobuf,
+}
+
+// msgpackHandle encodage MessagePack : noms des tags json, chaînes et binaires distincts
+// (spécification actuelle), horodatages en extension timestamp, UUID en binaire de 16 octets
+var msgpackHandle = newMsgPackHandle()
+
+// newMsgPackHandle configure l'encodage MessagePack (identique dans le gateway et les services)
+func newMsgPackHandle() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{WriteExt: true}
+	h.RawToString = true
+	h.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	return h
+}
+
+// MarshalMsgPack encode une valeur en MessagePack
+func MarshalMsgPack(v interface{}) ([]byte, error) {
+	var data []byte
+	if err := codec.NewEncoderBytes(&data, msgpackHandle).Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode msgpack: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalMsgPack décode une valeur MessagePack
+func UnmarshalMsgPack(data []byte, v interface{}) error {
+	if err := codec.NewDecoderBytes(data, msgpackHandle).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode msgpack: %w", err)
+	}
+	return nil
+}
+
+// FormatOf encodage d'un type de contenu ; JSON pour un type absent ou inconnu
+func FormatOf(contentType string) Format {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return FormatJSON
+	}
+	if format, ok := mediaTypes[mediaType]; ok {
+		return format
+	}
+	return FormatJSON
+}
+
+// Negotiate choisit l'encodage de la réponse selon l'en-tête Accept
+// Le type supporté de plus haute qualité l'emporte, le premier cité à qualité égale ;
+// JSON sans préférence exprimée pour un autre encodage.
+func Negotiate(accept string) Format {
+	best, bestQuality := FormatJSON, 0.0
+	for _, accepted := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		format, ok := mediaTypes[mediaType]
+		if !ok {
+			continue
+		}
+		quality := 1.0
+		if value, exists := params["q"]; exists {
+			if quality, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = format, quality
+		}
+	}
+	return best
+}
+
+// Bind décode le corps de la requête selon son Content-Type, puis le valide (tags binding)
+// Un corps protobuf n'est accepté que si obj implémente protocodec.Unmarshaler.
+func Bind(c *gin.Context, obj interface{}) error {
+	switch FormatOf(c.GetHeader("Content-Type")) {
+	case FormatMsgPack:
+		if err := codec.NewDecoder(c.Request.Body, msgpackHandle).Decode(obj); err != nil {
+			return fmt.Errorf("failed to decode msgpack: %w", err)
+		}
+	case FormatProtobuf:
+		message, ok := obj.(protocodec.Unmarshaler)
+		if !ok {
+			return ErrUnsupportedMediaType
+		}
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		if err = message.UnmarshalProto(data); err != nil {
+			return err
+		}
+	default:
+		return c.ShouldBindJSON(obj)
+	}
+
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// Render écrit la réponse dans l'encodage négocié
+// obj est encodé en JSON ou MessagePack ; message est la réponse protobuf (nil : pas de
+// schéma, la réponse reste en JSON).
+func Render(c *gin.Context, status int, obj interface{}, message protocodec.Marshaler) {
+	c.Writer.Header().Add("Vary", "Accept")
+
+	switch Negotiate(c.GetHeader("Accept")) {
+	case FormatMsgPack:
+		data, err := MarshalMsgPack(obj)
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to encode msgpack response, falling back to JSON")
+			break
+		}
+		c.Data(status, MIMEMsgPack, data)
+		return
+	case FormatProtobuf:
+		if message != nil {
+			c.Data(status, MIMEProtobuf, message.MarshalProto())
+			return
+		}
+	}
+
+	c.JSON(status, obj)
+}
+
+// StatusOf statut HTTP d'une erreur de Bind
+func StatusOf(err error) int {
+	if errors.Is(err, ErrUnsupportedMediaType) {
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusBadRequest
+}
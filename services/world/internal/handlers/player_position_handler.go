@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
+	"world/internal/encoding"
 	"world/internal/models"
 	"world/internal/service"
 )
@@ -78,8 +79,8 @@ func (h *PlayerPositionHandler) GetCharacterPosition(c *gin.Context) {
 // @Description Update the position of a specific character
 // @Tags positions
 // @Security BearerAuth
-// @Accept json
-// @Produce json
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
 // @Param characterId path string true "Character ID"
 // @Param position body models.UpdatePositionRequest true "Position data"
 // @Success 200 {object} models.PlayerPosition
@@ -112,8 +113,8 @@ func (h *PlayerPositionHandler) UpdateCharacterPosition(c *gin.Context) {
 
 	// Bind de la requête
 	var req models.UpdatePositionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := encoding.Bind(c, &req); err != nil {
+		c.JSON(encoding.StatusOf(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -137,12 +138,69 @@ func (h *PlayerPositionHandler) UpdateCharacterPosition(c *gin.Context) {
 		}
 	}()
 
-	c.JSON(http.StatusOK, gin.H{
+	encoding.Render(c, http.StatusOK, gin.H{
 		"position": position,
 		"message":  "Position updated successfully",
+	}, position)
+}
+
+// GetCharacterLocation récupère la position et les règles de zone d'un personnage (usage interne)
+// @Summary Get character location
+// @Description Get position and zone rules of a character, used by other services
+// @Tags services
+// @Produce json
+// @Param characterId path string true "Character ID"
+// @Success 200 {object} models.CharacterLocation
+// @Router /services/character/{characterId}/location [get]
+func (h *PlayerPositionHandler) GetCharacterLocation(c *gin.Context) {
+	characterID, err := uuid.Parse(c.Param("characterId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID format"})
+		return
+	}
+
+	location, err := h.positionService.GetCharacterLocation(characterID)
+	if err != nil {
+		logrus.WithError(err).WithField("character_id", characterID).Debug("Character location not found")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Character location not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"location": location,
 	})
 }
 
+// GetUserZonePresence indique si un utilisateur a un personnage en ligne dans une zone (usage interne)
+// @Summary Check user zone presence
+// @Description Return 200 if one of the user's online characters is in the zone, 404 otherwise
+// @Tags services
+// @Produce json
+// @Param id path string true "Zone ID"
+// @Param userId path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /services/zones/{id}/users/{userId} [get]
+func (h *PlayerPositionHandler) GetUserZonePresence(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	inZone, err := h.positionService.IsUserInZone(userID, c.Param("id"))
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error("Failed to check zone presence")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check zone presence"})
+		return
+	}
+	if !inZone {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not in this zone"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"in_zone": true})
+}
+
 // GetZonePositions récupère toutes les positions dans une zone
 // @Summary Get zone positions
 // @Description Get all player positions in a specific zone
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"world/internal/models"
+	"world/internal/repository"
 	"world/internal/service"
 )
 
@@ -103,6 +105,41 @@ func (h *NPCHandler) GetNPC(c *gin.Context) {
 	c.JSON(http.StatusNotImplemented, gin.H{"error": "Get single NPC not implemented yet"})
 }
 
+// GetNPCCCImmunities récupère les immunités aux contrôles d'un NPC (usage interne)
+// @Summary Get NPC crowd-control immunities
+// @Description Get the crowd-control categories a NPC is immune to, used by the combat service
+// @Tags services
+// @Produce json
+// @Param id path string true "NPC ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /services/npcs/{id}/cc-immunities [get]
+func (h *NPCHandler) GetNPCCCImmunities(c *gin.Context) {
+	npcID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid NPC ID format"})
+		return
+	}
+
+	immunities, err := h.npcService.GetNPCCCImmunities(c.Request.Context(), npcID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNPCNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "NPC not found"})
+			return
+		}
+		logrus.WithError(err).WithField("npc_id", npcID).Error("Failed to get NPC immunities")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve NPC immunities"})
+		return
+	}
+
+	if immunities == nil {
+		immunities = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"npc_id":        npcID,
+		"cc_immunities": immunities,
+	})
+}
+
 func (h *NPCHandler) InteractWithNPC(c *gin.Context) {
 	npcID := c.Param("id")
 	if npcID == "" {
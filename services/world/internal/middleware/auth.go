@@ -9,17 +9,23 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+
+	"mmorpg/pkg/jwks"
 )
 
+// tokenTypeAccess type des tokens d'accès des joueurs (les tokens de service sont refusés)
+const tokenTypeAccess = "access"
+
 // JWTClaims représente les claims du JWT
 type JWTClaims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Role   string    `json:"role"`
+	UserID    uuid.UUID `json:"user_id"`
+	Role      string    `json:"role"`
+	TokenType string    `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
-// JWTAuth middleware d'authentification JWT
-func JWTAuth(secretKey string) gin.HandlerFunc {
+// JWTAuth middleware d'authentification JWT (clés publiques du service auth)
+func JWTAuth(keys *jwks.KeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Récupérer le token depuis l'en-tête Authorization
 		authHeader := c.GetHeader("Authorization")
@@ -41,9 +47,7 @@ func JWTAuth(secretKey string) gin.HandlerFunc {
 
 		// Parser et valider le token
 		claims := &JWTClaims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secretKey), nil
-		})
+		token, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc, jwt.WithValidMethods(jwks.Algorithms()))
 
 		if err != nil {
 			logrus.WithError(err).Warn("JWT parsing failed")
@@ -52,7 +56,7 @@ func JWTAuth(secretKey string) gin.HandlerFunc {
 			return
 		}
 
-		if !token.Valid {
+		if !token.Valid || claims.TokenType != tokenTypeAccess || claims.UserID == uuid.Nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
@@ -96,7 +100,8 @@ func RequireRole(requiredRole string) gin.HandlerFunc {
 // Logger middleware de logging
 func Logger() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		logrus.WithFields(logrus.Fields{
+		// trace_id et span_id ajoutés depuis le contexte de la requête
+		logrus.WithContext(param.Request.Context()).WithFields(logrus.Fields{
 			"client_ip":   param.ClientIP,
 			"method":      param.Method,
 			"path":        param.Path,
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"mmorpg/pkg/protocodec"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Numéros de champs protobuf de UpdatePositionRequest (api/proto/game.proto du gateway)
+const (
+	updatePositionZoneID protowire.Number = iota + 1
+	updatePositionX
+	updatePositionY
+	updatePositionZ
+	updatePositionRotation
+	updatePositionVelocityX
+	updatePositionVelocityY
+	updatePositionVelocityZ
+	updatePositionIsMoving
+)
+
+// Numéros de champs protobuf de PlayerPosition
+const (
+	playerPositionCharacterID protowire.Number = iota + 1
+	playerPositionUserID
+	playerPositionZoneID
+	playerPositionX
+	playerPositionY
+	playerPositionZ
+	playerPositionRotation
+	playerPositionVelocityX
+	playerPositionVelocityY
+	playerPositionVelocityZ
+	playerPositionIsMoving
+	playerPositionIsOnline
+	playerPositionLastUpdate
+	playerPositionCharacterName
+	playerPositionCharacterLevel
+)
+
+// MarshalProto encode la requête en protobuf
+func (r *UpdatePositionRequest) MarshalProto() []byte {
+	var b []byte
+	b = protocodec.AppendString(b, updatePositionZoneID, r.ZoneID)
+	b = protocodec.AppendDouble(b, updatePositionX, r.X)
+	b = protocodec.AppendDouble(b, updatePositionY, r.Y)
+	b = protocodec.AppendDouble(b, updatePositionZ, r.Z)
+	b = protocodec.AppendDouble(b, updatePositionRotation, r.Rotation)
+	b = protocodec.AppendDouble(b, updatePositionVelocityX, r.VelocityX)
+	b = protocodec.AppendDouble(b, updatePositionVelocityY, r.VelocityY)
+	b = protocodec.AppendDouble(b, updatePositionVelocityZ, r.VelocityZ)
+	return protocodec.AppendBool(b, updatePositionIsMoving, r.IsMoving)
+}
+
+// UnmarshalProto décode la requête depuis le protobuf
+func (r *UpdatePositionRequest) UnmarshalProto(data []byte) error {
+	return protocodec.Read(data, func(f *protocodec.Field) error {
+		switch f.Number {
+		case updatePositionZoneID:
+			r.ZoneID = f.String()
+		case updatePositionX:
+			r.X = f.Double()
+		case updatePositionY:
+			r.Y = f.Double()
+		case updatePositionZ:
+			r.Z = f.Double()
+		case updatePositionRotation:
+			r.Rotation = f.Double()
+		case updatePositionVelocityX:
+			r.VelocityX = f.Double()
+		case updatePositionVelocityY:
+			r.VelocityY = f.Double()
+		case updatePositionVelocityZ:
+			r.VelocityZ = f.Double()
+		case updatePositionIsMoving:
+			r.IsMoving = f.Bool()
+		}
+		return nil
+	})
+}
+
+// MarshalProto encode la position en protobuf
+func (p *PlayerPosition) MarshalProto() []byte {
+	var b []byte
+	b = protocodec.AppendUUID(b, playerPositionCharacterID, p.CharacterID)
+	b = protocodec.AppendUUID(b, playerPositionUserID, p.UserID)
+	b = protocodec.AppendString(b, playerPositionZoneID, p.ZoneID)
+	b = protocodec.AppendDouble(b, playerPositionX, p.X)
+	b = protocodec.AppendDouble(b, playerPositionY, p.Y)
+	b = protocodec.AppendDouble(b, playerPositionZ, p.Z)
+	b = protocodec.AppendDouble(b, playerPositionRotation, p.Rotation)
+	b = protocodec.AppendDouble(b, playerPositionVelocityX, p.VelocityX)
+	b = protocodec.AppendDouble(b, playerPositionVelocityY, p.VelocityY)
+	b = protocodec.AppendDouble(b, playerPositionVelocityZ, p.VelocityZ)
+	b = protocodec.AppendBool(b, playerPositionIsMoving, p.IsMoving)
+	b = protocodec.AppendBool(b, playerPositionIsOnline, p.IsOnline)
+	b = protocodec.AppendTime(b, playerPositionLastUpdate, p.LastUpdate)
+	b = protocodec.AppendString(b, playerPositionCharacterName, p.CharacterName)
+	return protocodec.AppendInt64(b, playerPositionCharacterLevel, int64(p.CharacterLevel))
+}
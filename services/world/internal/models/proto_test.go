@@ -0,0 +1,167 @@
+package models
+
+import (
+	"math"
+	"mmorpg/pkg/protocodec"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// newTestUpdatePositionRequest requête de déplacement avec tous les champs renseignés
+func newTestUpdatePositionRequest() *UpdatePositionRequest {
+	return &UpdatePositionRequest{
+		ZoneID:    "starter_plains",
+		X:         102.5,
+		Y:         -7.25,
+		Z:         3,
+		Rotation:  1.5707,
+		VelocityX: 0.5,
+		VelocityY: -0.25,
+		VelocityZ: 0.125,
+		IsMoving:  true,
+	}
+}
+
+// newTestPlayerPosition position de joueur avec tous les champs renseignés
+func newTestPlayerPosition() *PlayerPosition {
+	return &PlayerPosition{
+		CharacterID:    uuid.MustParse("0a1b2c3d-4e5f-4a6b-8c7d-8e9f0a1b2c3d"),
+		UserID:         uuid.MustParse("1b2c3d4e-5f6a-4b7c-9d8e-9f0a1b2c3d4e"),
+		ZoneID:         "starter_plains",
+		X:              102.5,
+		Y:              -7.25,
+		Z:              3,
+		Rotation:       1.5707,
+		VelocityX:      0.5,
+		VelocityY:      -0.25,
+		VelocityZ:      0.125,
+		IsMoving:       true,
+		IsOnline:       true,
+		LastUpdate:     time.UnixMilli(1700000000123).UTC(),
+		CharacterName:  "Aldric",
+		CharacterLevel: 27,
+	}
+}
+
+func TestUpdatePositionRequestProtoRoundTrip(t *testing.T) {
+	request := newTestUpdatePositionRequest()
+
+	var decoded UpdatePositionRequest
+	if err := decoded.UnmarshalProto(request.MarshalProto()); err != nil {
+		t.Fatalf("UnmarshalProto: %v", err)
+	}
+	if decoded != *request {
+		t.Fatalf("round trip mismatch:\n got  %+v\n want %+v", decoded, *request)
+	}
+}
+
+func TestUpdatePositionRequestProtoOmitsDefaults(t *testing.T) {
+	if b := (&UpdatePositionRequest{}).MarshalProto(); len(b) != 0 {
+		t.Fatalf("empty request encoded to %d bytes, want 0", len(b))
+	}
+}
+
+// TestUpdatePositionRequestProtoSchema décode un message construit avec les numéros de game.proto
+func TestUpdatePositionRequestProtoSchema(t *testing.T) {
+	want := newTestUpdatePositionRequest()
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, want.ZoneID)
+	for number, value := range []float64{want.X, want.Y, want.Z, want.Rotation, want.VelocityX, want.VelocityY, want.VelocityZ} {
+		b = protowire.AppendTag(b, protowire.Number(number+2), protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(value))
+	}
+	b = protowire.AppendTag(b, 9, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1)
+	// Champ inconnu d'une version plus récente du schéma : ignoré
+	b = protowire.AppendTag(b, 99, protowire.BytesType)
+	b = protowire.AppendString(b, "ignored")
+
+	var decoded UpdatePositionRequest
+	if err := decoded.UnmarshalProto(b); err != nil {
+		t.Fatalf("UnmarshalProto: %v", err)
+	}
+	if decoded != *want {
+		t.Fatalf("schema mismatch:\n got  %+v\n want %+v", decoded, *want)
+	}
+}
+
+func TestUpdatePositionRequestProtoInvalid(t *testing.T) {
+	var decoded UpdatePositionRequest
+	if err := decoded.UnmarshalProto([]byte{0x0a, 0x05, 'a'}); err == nil {
+		t.Fatal("expected an error for a truncated message")
+	}
+}
+
+// TestPlayerPositionProtoSchema relit la position encodée avec les numéros de game.proto
+func TestPlayerPositionProtoSchema(t *testing.T) {
+	position := newTestPlayerPosition()
+
+	fields := map[protowire.Number]interface{}{}
+	err := protocodec.Read(position.MarshalProto(), func(f *protocodec.Field) error {
+		switch f.Number {
+		case 1, 2:
+			id, idErr := f.UUID()
+			fields[f.Number] = id
+			return idErr
+		case 3, 14:
+			fields[f.Number] = f.String()
+		case 4, 5, 6, 7, 8, 9, 10:
+			fields[f.Number] = f.Double()
+		case 11, 12:
+			fields[f.Number] = f.Bool()
+		case 13:
+			fields[f.Number] = f.Time()
+		case 15:
+			fields[f.Number] = f.Int64()
+		default:
+			t.Errorf("unexpected PlayerPosition field %d", f.Number)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadProto: %v", err)
+	}
+
+	want := map[protowire.Number]interface{}{
+		1: position.CharacterID, 2: position.UserID, 3: position.ZoneID,
+		4: position.X, 5: position.Y, 6: position.Z, 7: position.Rotation,
+		8: position.VelocityX, 9: position.VelocityY, 10: position.VelocityZ,
+		11: true, 12: true, 13: position.LastUpdate, 14: position.CharacterName, 15: int64(27),
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("player position fields = %v, want %v", fields, want)
+	}
+}
+
+func BenchmarkUpdatePositionRequestMarshalProto(b *testing.B) {
+	request := newTestUpdatePositionRequest()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = request.MarshalProto()
+	}
+}
+
+func BenchmarkUpdatePositionRequestUnmarshalProto(b *testing.B) {
+	data := newTestUpdatePositionRequest().MarshalProto()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var request UpdatePositionRequest
+		if err := request.UnmarshalProto(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPlayerPositionMarshalProto(b *testing.B) {
+	position := newTestPlayerPosition()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = position.MarshalProto()
+	}
+}
@@ -102,6 +102,9 @@ type NPCBehavior struct {
 	RespawnTime     int       `json:"respawn_time"` // en secondes
 	IsHostile       bool      `json:"is_hostile"`
 	Faction         string    `json:"faction"`
+
+	// Catégories de contrôle auxquelles le NPC est insensible (ex: boss), "all" pour toutes
+	CCImmunities []string `json:"cc_immunities,omitempty"`
 }
 
 // PlayerPosition position d'un joueur dans le monde
@@ -131,6 +134,20 @@ type PlayerPosition struct {
 	CharacterLevel int    `json:"character_level,omitempty" db:"-"`
 }
 
+// CharacterLocation position d'un personnage et règles de sa zone (utilisé par d'autres services)
+type CharacterLocation struct {
+	CharacterID uuid.UUID `json:"character_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	ZoneID      string    `json:"zone_id"`
+	X           float64   `json:"x"`
+	Y           float64   `json:"y"`
+	Z           float64   `json:"z"`
+	IsOnline    bool      `json:"is_online"`
+	LastUpdate  time.Time `json:"last_update"`
+	IsPvPZone   bool      `json:"is_pvp_zone"`
+	IsSafeZone  bool      `json:"is_safe_zone"`
+}
+
 // WorldEvent événement du monde
 type WorldEvent struct {
 	ID          uuid.UUID `json:"id" db:"id"`
@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,6 +13,9 @@ import (
 	"world/internal/models"
 )
 
+// ErrNPCNotFound aucun NPC ne correspond à l'identifiant demandé
+var ErrNPCNotFound = errors.New("NPC not found")
+
 // NPCRepositoryInterface définit les méthodes du repository NPC
 type NPCRepositoryInterface interface {
 	// Méthodes de base CRUD
@@ -92,7 +96,7 @@ func (r *NPCRepository) GetByID(id string) (*models.NPC, error) {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("NPC not found")
+			return nil, ErrNPCNotFound
 		}
 		return nil, fmt.Errorf("failed to get NPC by ID: %w", err)
 	}
@@ -125,6 +125,47 @@ func (s *PlayerPositionService) GetCharacterPosition(characterID uuid.UUID) (*mo
 	return position, nil
 }
 
+// GetCharacterLocation récupère la position d'un personnage avec les règles de sa zone
+func (s *PlayerPositionService) GetCharacterLocation(characterID uuid.UUID) (*models.CharacterLocation, error) {
+	position, err := s.positionRepo.GetByCharacterID(characterID)
+	if err != nil {
+		return nil, fmt.Errorf("position not found: %w", err)
+	}
+
+	zone, err := s.zoneRepo.GetByID(position.ZoneID)
+	if err != nil {
+		return nil, fmt.Errorf("zone not found: %w", err)
+	}
+
+	return &models.CharacterLocation{
+		CharacterID: position.CharacterID,
+		UserID:      position.UserID,
+		ZoneID:      position.ZoneID,
+		X:           position.X,
+		Y:           position.Y,
+		Z:           position.Z,
+		IsOnline:    position.IsOnline,
+		LastUpdate:  position.LastUpdate,
+		IsPvPZone:   zone.IsPvP,
+		IsSafeZone:  zone.IsSafeZone,
+	}, nil
+}
+
+// IsUserInZone indique si un personnage en ligne de l'utilisateur se trouve dans la zone
+func (s *PlayerPositionService) IsUserInZone(userID uuid.UUID, zoneID string) (bool, error) {
+	positions, err := s.positionRepo.GetByUserID(userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user positions: %w", err)
+	}
+
+	for _, position := range positions {
+		if position.ZoneID == zoneID && position.IsOnline {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // GetZonePositions récupère toutes les positions dans une zone
 func (s *PlayerPositionService) GetZonePositions(zoneID string) ([]*models.PlayerPosition, error) {
 	// Vérifier que la zone existe
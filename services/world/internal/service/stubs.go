@@ -138,6 +138,16 @@ func (s *NPCService) UpdateNPCHealth(ctx context.Context, npcID uuid.UUID, healt
 	return s.npcRepo.Update(npc)
 }
 
+// GetNPCCCImmunities récupère les immunités aux contrôles déclarées dans le modèle d'un NPC
+func (s *NPCService) GetNPCCCImmunities(ctx context.Context, npcID uuid.UUID) ([]string, error) {
+	npc, err := s.npcRepo.GetByID(npcID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return npc.Behavior.CCImmunities, nil
+}
+
 // RespawnNPC fait réapparaître un NPC mort
 func (s *NPCService) RespawnNPC(ctx context.Context, npcID uuid.UUID) error {
 	npc, err := s.npcRepo.GetByID(npcID.String())